@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/llm"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/tui"
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Inspect and test the TUI agent",
+}
+
+var agentToolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "List the tools the agent can call",
+	Long:  "List the tools the TUI agent can call, with their parameters and whether the agent asks before running them. The list comes from the agent itself, so it always matches this build.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		readOnly, _ := cmd.Flags().GetBool("read-only")
+		return runAgentTools(readOnly)
+	},
+}
+
+// agentTool is one tool in 'deer agent tools' output. Approval is when the
+// agent asks the human first: always, network, or never; or denied when the
+// tool_approval policy refuses the tool.
+type agentTool struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Parameters  llm.ParameterSchema `json:"parameters"`
+	ReadOnly    bool                `json:"read_only"`
+	Approval    string              `json:"approval"`
+}
+
+// agentToolList is the --output payload of 'deer agent tools'.
+type agentToolList []agentTool
+
+func (l agentToolList) tableHeader() []string {
+	return []string{"NAME", "READ-ONLY", "APPROVAL", "PARAMETERS"}
+}
+
+func (l agentToolList) tableRows() [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, t := range l {
+		readOnly := "no"
+		if t.ReadOnly {
+			readOnly = "yes"
+		}
+		rows = append(rows, []string{t.Name, readOnly, t.Approval, orDash(strings.Join(paramNames(t.Parameters), ", "))})
+	}
+	return rows
+}
+
+// agentTools lists the agent's tools, or only the read-only ones, with their
+// approval under policy.
+func agentTools(readOnly bool, policy config.ToolApprovalPolicy) agentToolList {
+	readOnlyNames := map[string]bool{}
+	for _, t := range llm.GetReadOnlyTools() {
+		readOnlyNames[t.Function.Name] = true
+	}
+	tools := llm.GetTools()
+	if readOnly {
+		tools = llm.GetReadOnlyTools()
+	}
+	list := make(agentToolList, 0, len(tools))
+	for _, t := range tools {
+		list = append(list, agentTool{
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			Parameters:  t.Function.Parameters,
+			ReadOnly:    readOnlyNames[t.Function.Name],
+			Approval:    tui.ToolApproval(policy, t.Function.Name),
+		})
+	}
+	return list
+}
+
+// paramNames returns a schema's parameter names in order, required ones
+// marked with a trailing "*".
+func paramNames(schema llm.ParameterSchema) []string {
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if required[name] {
+			names[i] += "*"
+		}
+	}
+	return names
+}
+
+func runAgentTools(readOnly bool) error {
+	// Without a readable config the defaults apply, as they would for the
+	// agent itself.
+	loadedCfg := &config.Config{}
+	if configPath, err := resolveConfigPath(); err == nil {
+		if cfg, err := config.Load(configPath); err == nil {
+			loadedCfg = cfg
+		}
+	}
+
+	tools := agentTools(readOnly, loadedCfg.ToolApproval)
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, tools)
+	}
+	printAgentTools(os.Stdout, tools, loadedCfg.NetworkPolicy)
+	return nil
+}
+
+// printAgentTools writes each tool with its description and parameters,
+// then how network approval is decided under policy.
+func printAgentTools(w io.Writer, tools agentToolList, policy config.NetworkPolicyConfig) {
+	for _, t := range tools {
+		var tags []string
+		if t.ReadOnly {
+			tags = append(tags, "read-only")
+		}
+		switch t.Approval {
+		case "denied":
+			tags = append(tags, "denied by policy")
+		case "always":
+			tags = append(tags, "asks every time")
+		case "network":
+			tags = append(tags, "asks on network access")
+		}
+		header := "  " + t.Name
+		if len(tags) > 0 {
+			header += "  [" + strings.Join(tags, ", ") + "]"
+		}
+		_, _ = fmt.Fprintln(w, header)
+		_, _ = fmt.Fprintf(w, "      %s\n", t.Description)
+		required := map[string]bool{}
+		for _, name := range t.Parameters.Required {
+			required[name] = true
+		}
+		for _, name := range paramNames(t.Parameters) {
+			name = strings.TrimSuffix(name, "*")
+			p := t.Parameters.Properties[name]
+			kind := p.Type
+			if required[name] {
+				kind += ", required"
+			}
+			_, _ = fmt.Fprintf(w, "      - %s (%s): %s\n", name, kind, p.Description)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	if len(policy.AllowHosts) > 0 {
+		_, _ = fmt.Fprintf(w, "  Network access to %s is approved without asking.\n", strings.Join(policy.AllowHosts, ", "))
+	}
+	if len(policy.DenyHosts) > 0 {
+		_, _ = fmt.Fprintf(w, "  Network access to %s is denied without asking.\n", strings.Join(policy.DenyHosts, ", "))
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/llm"
+)
+
+func TestAgentTools(t *testing.T) {
+	all := agentTools(false, nil)
+	if len(all) != len(llm.GetTools()) {
+		t.Fatalf("listed %d tools, want %d", len(all), len(llm.GetTools()))
+	}
+	byName := map[string]agentTool{}
+	for _, tool := range all {
+		byName[tool.Name] = tool
+	}
+	if got := byName["request_source_access"].Approval; got != "always" {
+		t.Errorf("request_source_access approval = %q, want always", got)
+	}
+	if got := byName["run_command"]; got.Approval != "network" || got.ReadOnly {
+		t.Errorf("run_command = %+v, want network approval and not read-only", got)
+	}
+	if !byName["list_sandboxes"].ReadOnly {
+		t.Error("list_sandboxes should be read-only")
+	}
+	if got := byName["destroy_sandbox"].Approval; got != "always" {
+		t.Errorf("destroy_sandbox approval = %q, want always by default", got)
+	}
+
+	policy := config.ToolApprovalPolicy{"destroy_sandbox": "auto", "run_command": "deny", "create_sandbox": "confirm"}
+	for _, tool := range agentTools(false, policy) {
+		byName[tool.Name] = tool
+	}
+	for name, want := range map[string]string{"destroy_sandbox": "never", "run_command": "denied", "create_sandbox": "always"} {
+		if got := byName[name].Approval; got != want {
+			t.Errorf("%s approval under policy = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, tool := range agentTools(true, nil) {
+		if !tool.ReadOnly {
+			t.Errorf("--read-only listed %s", tool.Name)
+		}
+	}
+}
+
+func TestPrintAgentTools(t *testing.T) {
+	var buf bytes.Buffer
+	printAgentTools(&buf, agentTools(false, config.ToolApprovalPolicy{"edit_file": "deny"}), config.NetworkPolicyConfig{AllowHosts: []string{"pypi.org"}})
+	out := buf.String()
+	for _, want := range []string{
+		"run_command  [asks on network access]",
+		"- sandbox_id (string, required):",
+		"list_sandboxes  [read-only]",
+		"edit_file  [denied by policy]",
+		"Network access to pypi.org is approved without asking.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/chatlog"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/tui"
+)
+
+var agentReplayCmd = &cobra.Command{
+	Use:   "replay <session.json> --against <source-vm>",
+	Short: "Replay a recorded agent session against a fresh sandbox",
+	Long: `Re-issue the user turns of a recorded session to the live agent in a new
+sandbox created from the source VM, then compare what the agent did with the
+recorded run: which tools it called, whether they failed, and the exit code
+of every command. Use it to turn real sessions into regression tests for
+prompt and tool changes.
+
+The session is the JSON written by 'deer --prompt' or a chat log from the
+chats directory. Approvals are asked on stderr as in --prompt mode. The
+sandbox is kept afterwards so it can be inspected. Exits non-zero when the
+replay diverged.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		against, _ := cmd.Flags().GetString("against")
+		if against == "" {
+			return fmt.Errorf("--against <source-vm> is required")
+		}
+		return runAgentReplay(args[0], against)
+	},
+}
+
+// sessionCall is one tool call from a session, reduced to what a replay
+// compares. Command and ExitCode are only set for run_command.
+type sessionCall struct {
+	Tool     string `json:"tool"`
+	Command  string `json:"command,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// sessionTurn is one user message and the tool calls the agent made
+// answering it.
+type sessionTurn struct {
+	Prompt string        `json:"prompt"`
+	Calls  []sessionCall `json:"calls"`
+}
+
+// agentDivergence is one way a replayed turn differs from the recorded one.
+type agentDivergence struct {
+	Turn   int    `json:"turn"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// agentReplayReport is the --output payload of 'deer agent replay'.
+type agentReplayReport struct {
+	Session     string            `json:"session"`
+	SourceVM    string            `json:"source_vm"`
+	SandboxID   string            `json:"sandbox_id"`
+	Turns       int               `json:"turns"`
+	Divergences []agentDivergence `json:"divergences"`
+}
+
+// loadSession reads a recorded session: either the JSON array 'deer
+// --prompt' prints or a JSONL chat log.
+func loadSession(path string) ([]chatlog.Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read session: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	var events []chatlog.Event
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &events); err != nil {
+			return nil, fmt.Errorf("parse session %s: %w", path, err)
+		}
+		return events, nil
+	}
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e chatlog.Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("parse session %s line %d: %w", path, i+1, err)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// sessionTurns groups a session's tool calls under the user message they
+// answered. Calls before the first user message are dropped.
+func sessionTurns(events []chatlog.Event) []sessionTurn {
+	var turns []sessionTurn
+	for _, e := range events {
+		switch e.Type {
+		case chatlog.TypeUserMessage:
+			turns = append(turns, sessionTurn{Prompt: e.Content})
+		case chatlog.TypeToolCall:
+			if len(turns) == 0 {
+				continue
+			}
+			last := &turns[len(turns)-1]
+			last.Calls = append(last.Calls, sessionCallFromEvent(e))
+		}
+	}
+	return turns
+}
+
+func sessionCallFromEvent(e chatlog.Event) sessionCall {
+	c := sessionCall{Tool: e.Tool, Error: e.Error}
+	if e.Tool != "run_command" {
+		return c
+	}
+	c.Command, _ = e.Args["command"].(string)
+	if result, ok := e.Result.(map[string]any); ok {
+		if code, ok := result["exit_code"].(float64); ok {
+			exit := int(code)
+			c.ExitCode = &exit
+		}
+		if msg, ok := result["error"].(string); ok && c.Error == "" {
+			c.Error = msg
+		}
+	}
+	return c
+}
+
+// compareTurns reports how replayed differs from recorded, turn by turn:
+// tools called a different number of times, recorded commands that were not
+// run again or exited differently, and tools that failed only in the replay.
+func compareTurns(recorded, replayed []sessionTurn) []agentDivergence {
+	var out []agentDivergence
+	for i, rec := range recorded {
+		turn := i + 1
+		if i >= len(replayed) {
+			out = append(out, agentDivergence{Turn: turn, Kind: "missing_turn", Detail: "the replay stopped before this turn"})
+			continue
+		}
+		rep := replayed[i]
+
+		recCount, repCount := toolCounts(rec.Calls), toolCounts(rep.Calls)
+		tools := make([]string, 0, len(recCount)+len(repCount))
+		for t := range recCount {
+			tools = append(tools, t)
+		}
+		for t := range repCount {
+			if _, ok := recCount[t]; !ok {
+				tools = append(tools, t)
+			}
+		}
+		slices.Sort(tools)
+		for _, t := range tools {
+			if recCount[t] != repCount[t] {
+				out = append(out, agentDivergence{Turn: turn, Kind: "tool_calls", Detail: fmt.Sprintf("%s called %d times, was %d", t, repCount[t], recCount[t])})
+			}
+		}
+
+		for _, rc := range rec.Calls {
+			if rc.Tool != "run_command" || rc.ExitCode == nil {
+				continue
+			}
+			idx := slices.IndexFunc(rep.Calls, func(c sessionCall) bool { return c.Tool == "run_command" && c.Command == rc.Command })
+			if idx < 0 {
+				out = append(out, agentDivergence{Turn: turn, Kind: "command_missing", Detail: fmt.Sprintf("$ %s was not run", rc.Command)})
+				continue
+			}
+			pc := rep.Calls[idx]
+			if pc.ExitCode == nil || *pc.ExitCode != *rc.ExitCode {
+				got := "no exit code"
+				if pc.ExitCode != nil {
+					got = fmt.Sprintf("exit %d", *pc.ExitCode)
+				}
+				out = append(out, agentDivergence{Turn: turn, Kind: "exit_code", Detail: fmt.Sprintf("$ %s: %s, was exit %d", rc.Command, got, *rc.ExitCode)})
+			}
+		}
+
+		for _, pc := range rep.Calls {
+			if pc.Error == "" || pc.Tool == "run_command" {
+				continue
+			}
+			failedBefore := slices.ContainsFunc(rec.Calls, func(c sessionCall) bool { return c.Tool == pc.Tool && c.Error != "" })
+			if !failedBefore {
+				out = append(out, agentDivergence{Turn: turn, Kind: "tool_error", Detail: fmt.Sprintf("%s failed: %s", pc.Tool, pc.Error)})
+			}
+		}
+	}
+	return out
+}
+
+func toolCounts(calls []sessionCall) map[string]int {
+	counts := make(map[string]int, len(calls))
+	for _, c := range calls {
+		counts[c.Tool]++
+	}
+	return counts
+}
+
+// replayPreamble tells the agent about the sandbox the replay created, so
+// it works there instead of creating its own.
+func replayPreamble(sandboxID, sourceVM string) string {
+	return fmt.Sprintf("Sandbox %s has already been created from %s for this session. Use it for any sandbox work; do not create another sandbox.\n\n", sandboxID, sourceVM)
+}
+
+func runAgentReplay(sessionPath, against string) error {
+	events, err := loadSession(sessionPath)
+	if err != nil {
+		return err
+	}
+	recorded := sessionTurns(events)
+	if len(recorded) == 0 {
+		return fmt.Errorf("session %s has no user messages to replay", sessionPath)
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+	cfg, err = tui.EnsureConfigExists(configPath)
+	if err != nil {
+		return fmt.Errorf("ensure config: %w", err)
+	}
+	if cfg.ChatsDir == "" {
+		return fmt.Errorf("chats_dir not configured")
+	}
+
+	fileLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	core, err := initCoreServices(cfg, fileLogger)
+	if err != nil {
+		return fmt.Errorf("init core services: %w", err)
+	}
+	defer func() { _ = core.store.Close() }()
+	defer core.telemetry.Close()
+	if core.auditLog != nil {
+		defer func() { _ = core.auditLog.Close() }()
+	}
+
+	svc := initSandboxService(cfg, fileLogger)
+	defer func() { _ = svc.Close() }()
+
+	ctx := sandbox.WithActor(context.Background(), sandbox.ActorHumanCLI)
+	sb, err := svc.CreateSandbox(ctx, sandbox.CreateRequest{SourceVM: against, AgentID: "cli"})
+	if err != nil {
+		return fmt.Errorf("create sandbox: %w", err)
+	}
+
+	text := outputFormat == ""
+	if text {
+		fmt.Printf("  Replaying %d turns from %s in new sandbox %s (%s)\n\n", len(recorded), sessionPath, sb.ID, against)
+	}
+
+	chatLogger, _, err := chatlog.New(cfg.ChatsDir)
+	if err != nil {
+		return fmt.Errorf("open chat log: %w", err)
+	}
+	chatLogger.LogSessionStart(cfg.AIAgent.Model)
+
+	agent := tui.NewDeerAgent(cfg, core.store, svc, core.source, core.telemetry, core.redactor, core.auditLog, chatLogger, fileLogger)
+	agent.SetRequireApproval(requireApproval)
+	agent.SetCurrentSandbox(sb.ID, sb.HostID)
+	answerApprovalsOnStdin(agent)
+
+	var agentErr error
+	for i, turn := range recorded {
+		prompt := turn.Prompt
+		if i == 0 {
+			prompt = replayPreamble(sb.ID, against) + prompt
+		}
+		if text {
+			fmt.Printf("  turn %d: %s\n", i+1, firstLine(turn.Prompt))
+		}
+		if _, agentErr = agent.RunHeadless(context.Background(), prompt); agentErr != nil {
+			break
+		}
+	}
+	chatLogger.LogSessionEnd(0, 0)
+	_ = chatLogger.Close()
+
+	replayedEvents, err := chatLogger.ReadEvents()
+	if err != nil {
+		return fmt.Errorf("read chat log: %w", err)
+	}
+	report := &agentReplayReport{
+		Session:     sessionPath,
+		SourceVM:    against,
+		SandboxID:   sb.ID,
+		Turns:       len(recorded),
+		Divergences: compareTurns(recorded, sessionTurns(replayedEvents)),
+	}
+
+	if text {
+		fmt.Println()
+		printAgentReplay(os.Stdout, report)
+	} else if err := writeOutput(os.Stdout, report); err != nil {
+		return err
+	}
+	if agentErr != nil {
+		return fmt.Errorf("agent: %w", agentErr)
+	}
+	if len(report.Divergences) > 0 {
+		return fmt.Errorf("replay diverged from the recorded session in %d places", len(report.Divergences))
+	}
+	return nil
+}
+
+// printAgentReplay writes each divergence and a one-line verdict.
+func printAgentReplay(w io.Writer, r *agentReplayReport) {
+	for _, d := range r.Divergences {
+		_, _ = fmt.Fprintf(w, "  DIVERGED  turn %d  %s: %s\n", d.Turn, d.Kind, d.Detail)
+	}
+	if len(r.Divergences) == 0 {
+		_, _ = fmt.Fprintf(w, "  All %d turns matched the recorded session. Sandbox %s kept for inspection.\n", r.Turns, r.SandboxID)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "  %d divergences over %d turns. Sandbox %s kept for inspection.\n", len(r.Divergences), r.Turns, r.SandboxID)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/chatlog"
+)
+
+func runCommandEvent(command string, exit float64) chatlog.Event {
+	return chatlog.Event{
+		Type:   chatlog.TypeToolCall,
+		Tool:   "run_command",
+		Args:   map[string]any{"sandbox_id": "SBX-1", "command": command},
+		Result: map[string]any{"exit_code": exit, "stdout": ""},
+	}
+}
+
+func TestLoadSession(t *testing.T) {
+	dir := t.TempDir()
+	arrayPath := filepath.Join(dir, "session.json")
+	if err := os.WriteFile(arrayPath, []byte(`[{"type":"user_message","content":"fix nginx"},{"type":"tool_call","tool":"run_command","args":{"command":"nginx -t"},"result":{"exit_code":1}}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jsonlPath := filepath.Join(dir, "session.jsonl")
+	if err := os.WriteFile(jsonlPath, []byte("{\"type\":\"session_start\"}\n{\"type\":\"user_message\",\"content\":\"fix nginx\"}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{arrayPath, jsonlPath} {
+		events, err := loadSession(path)
+		if err != nil {
+			t.Fatalf("loadSession(%s): %v", path, err)
+		}
+		turns := sessionTurns(events)
+		if len(turns) != 1 || turns[0].Prompt != "fix nginx" {
+			t.Errorf("turns from %s = %+v", filepath.Base(path), turns)
+		}
+	}
+
+	events, _ := loadSession(arrayPath)
+	calls := sessionTurns(events)[0].Calls
+	if len(calls) != 1 || calls[0].Command != "nginx -t" || calls[0].ExitCode == nil || *calls[0].ExitCode != 1 {
+		t.Errorf("calls = %+v", calls)
+	}
+}
+
+func TestCompareTurns(t *testing.T) {
+	recorded := sessionTurns([]chatlog.Event{
+		{Type: chatlog.TypeUserMessage, Content: "fix nginx"},
+		runCommandEvent("nginx -t", 1),
+		runCommandEvent("sed -i s/80/8080/ /etc/nginx/nginx.conf", 0),
+		{Type: chatlog.TypeToolCall, Tool: "read_file", Args: map[string]any{"path": "/etc/nginx/nginx.conf"}},
+		{Type: chatlog.TypeUserMessage, Content: "now restart it"},
+		runCommandEvent("systemctl restart nginx", 0),
+	})
+
+	t.Run("identical", func(t *testing.T) {
+		if got := compareTurns(recorded, recorded); len(got) != 0 {
+			t.Errorf("divergences = %+v, want none", got)
+		}
+	})
+
+	t.Run("diverged", func(t *testing.T) {
+		replayed := sessionTurns([]chatlog.Event{
+			{Type: chatlog.TypeUserMessage, Content: "fix nginx"},
+			runCommandEvent("nginx -t", 0),
+			{Type: chatlog.TypeToolCall, Tool: "read_file", Error: "no such file"},
+			{Type: chatlog.TypeToolCall, Tool: "read_file", Args: map[string]any{"path": "/etc/nginx/nginx.conf"}},
+		})
+		got := compareTurns(recorded, replayed)
+		var kinds []string
+		for _, d := range got {
+			kinds = append(kinds, d.Kind)
+		}
+		want := []string{"tool_calls", "tool_calls", "exit_code", "command_missing", "tool_error", "missing_turn"}
+		if strings.Join(kinds, ",") != strings.Join(want, ",") {
+			t.Fatalf("divergence kinds = %v, want %v\n%+v", kinds, want, got)
+		}
+		if got[0].Detail != "read_file called 2 times, was 1" || got[2].Detail != "$ nginx -t: exit 0, was exit 1" {
+			t.Errorf("details = %q, %q", got[0].Detail, got[2].Detail)
+		}
+		if got[5].Turn != 2 {
+			t.Errorf("missing turn = %d, want 2", got[5].Turn)
+		}
+	})
+}
+
+func TestPrintAgentReplay(t *testing.T) {
+	var buf bytes.Buffer
+	printAgentReplay(&buf, &agentReplayReport{SandboxID: "SBX-9", Turns: 2})
+	if !strings.Contains(buf.String(), "All 2 turns matched") {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	buf.Reset()
+	printAgentReplay(&buf, &agentReplayReport{SandboxID: "SBX-9", Turns: 2, Divergences: []agentDivergence{{Turn: 1, Kind: "exit_code", Detail: "$ nginx -t: exit 0, was exit 1"}}})
+	for _, want := range []string{"DIVERGED  turn 1  exit_code: $ nginx -t: exit 0, was exit 1", "1 divergences over 2 turns"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
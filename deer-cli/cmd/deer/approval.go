@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/tui"
+)
+
+// requireApproval is set by --require-approval: every sandbox command is
+// shown to the human and runs only if they answer yes.
+var requireApproval bool
+
+// promptCommandApproval asks on out whether command may run in sandboxID and
+// reads the answer from in. Anything but y or yes, including EOF, is a no.
+func promptCommandApproval(in *bufio.Reader, out io.Writer, sandboxID, command string) bool {
+	_, _ = fmt.Fprintf(out, "  Sandbox: %s\n  Command: %s\n  Run this command? [y/N] ", sandboxID, command)
+	return readYes(in)
+}
+
+// promptToolApproval asks on out whether the agent may call tool, which the
+// tool_approval policy says to confirm. Anything but y or yes is a no.
+func promptToolApproval(in *bufio.Reader, out io.Writer, tool string, args map[string]any) bool {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	_, _ = fmt.Fprintf(out, "  Tool: %s\n", tool)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(out, "    %s: %v\n", k, args[k])
+	}
+	_, _ = fmt.Fprint(out, "  Allow this tool call? [y/N] ")
+	return readYes(in)
+}
+
+// answerApprovalsOnStdin makes a headless agent ask its approval questions
+// on stderr and read the answers from stdin, leaving stdout for output.
+func answerApprovalsOnStdin(agent *tui.DeerAgent) {
+	stdin := bufio.NewReader(os.Stdin)
+	agent.SetStatusCallback(func(msg tea.Msg) {
+		req, ok := msg.(tui.ApprovalRequestMsg)
+		if !ok {
+			return
+		}
+		switch r := req.Request.(type) {
+		case tui.NetworkApprovalRequest:
+			if requireApproval {
+				agent.HandleApprovalResponse(req.Kind, promptCommandApproval(stdin, os.Stderr, r.SandboxID, r.Command))
+			}
+		case tui.ToolApprovalRequest:
+			agent.HandleApprovalResponse(req.Kind, promptToolApproval(stdin, os.Stderr, r.Tool, r.Args))
+		}
+	})
+}
+
+// readYes reads one answer line and reports whether it was y or yes.
+func readYes(in *bufio.Reader) bool {
+	answer, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// logCommandApproval records a --require-approval decision in the audit log,
+// if one is open.
+func logCommandApproval(al *audit.Logger, sandboxID, command string, approved bool) {
+	if al == nil {
+		return
+	}
+	al.LogApproval("run_command", "command", map[string]any{"sandbox_id": sandboxID, "command": command}, approved)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPromptCommandApproval(t *testing.T) {
+	tests := map[string]bool{"y\n": true, "YES\n": true, "n\n": false, "\n": false, "": false, "sure\n": false}
+	for input, want := range tests {
+		var out bytes.Buffer
+		if got := promptCommandApproval(bufio.NewReader(strings.NewReader(input)), &out, "sbx-1", "systemctl restart nginx"); got != want {
+			t.Errorf("answer %q: approved = %v, want %v", input, got, want)
+		}
+		if !strings.Contains(out.String(), "sbx-1") || !strings.Contains(out.String(), "systemctl restart nginx") {
+			t.Errorf("prompt does not show the target and command: %q", out.String())
+		}
+	}
+}
+
+func TestPromptToolApproval(t *testing.T) {
+	var out bytes.Buffer
+	if !promptToolApproval(bufio.NewReader(strings.NewReader("yes\n")), &out, "destroy_sandbox", map[string]any{"sandbox_id": "sbx-1"}) {
+		t.Error("yes was not taken as approval")
+	}
+	if !strings.Contains(out.String(), "Tool: destroy_sandbox") || !strings.Contains(out.String(), "sandbox_id: sbx-1") {
+		t.Errorf("prompt = %q", out.String())
+	}
+	if promptToolApproval(bufio.NewReader(strings.NewReader("")), &out, "edit_file", nil) {
+		t.Error("EOF was taken as approval")
+	}
+}
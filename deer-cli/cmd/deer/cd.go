@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// sandboxWorkdirSetter is implemented by sandbox services that keep a
+// per-sandbox working directory (the daemon-backed RemoteService).
+type sandboxWorkdirSetter interface {
+	SetSandboxWorkdir(ctx context.Context, sandboxID, workdir string) (*sandbox.SandboxInfo, error)
+}
+
+func runCd(sandboxID, dir string) error {
+	if dir != "" && !path.IsAbs(dir) {
+		return fmt.Errorf("%s is not an absolute path", dir)
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	setter, ok := svc.(sandboxWorkdirSetter)
+	if !ok {
+		return fmt.Errorf("setting a working directory needs a sandbox host; run 'deer connect' first")
+	}
+
+	sb, err := setter.SetSandboxWorkdir(context.Background(), sandboxID, dir)
+	if err != nil {
+		return fmt.Errorf("set working directory: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, sb)
+	}
+	if sb.Workdir == "" {
+		fmt.Printf("  Commands in %s run from the login directory\n", sb.ID)
+		return nil
+	}
+	fmt.Printf("  Commands in %s run from %s\n", sb.ID, sb.Workdir)
+	return nil
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunCd_RequiresAbsolutePath(t *testing.T) {
+	err := runCd("sbx-1", "src/app")
+	if err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Errorf("err = %v, want an absolute-path error", err)
+	}
+}
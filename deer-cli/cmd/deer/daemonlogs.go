@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/hostexec"
+)
+
+const (
+	// daemonUnit is the systemd unit the packaged daemon runs as.
+	daemonUnit = "deer-daemon"
+
+	// daemonLogDefaultLines is how much history 'logs --daemon' shows when
+	// --tail is not given.
+	daemonLogDefaultLines = 100
+)
+
+// daemonLogOptions selects which daemon log lines to print.
+type daemonLogOptions struct {
+	Host     string    // host name from config; empty or "localhost" reads locally
+	LogFile  string    // read this file instead of the journal
+	Tail     int       // lines of history to show before following
+	Follow   bool      // keep streaming new lines
+	Since    time.Time // drop lines older than this (zero for no bound)
+	MinLevel slog.Level
+}
+
+func runDaemonLogs(opts daemonLogOptions) error {
+	if opts.Tail <= 0 {
+		opts.Tail = daemonLogDefaultLines
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	command := daemonLogCommand(opts)
+	onOutput := func(chunk string, isStderr bool) {
+		if isStderr {
+			fmt.Fprint(os.Stderr, chunk)
+			return
+		}
+		line := strings.TrimRight(chunk, "\n")
+		if keepDaemonLogLine(line, opts.Since, opts.MinLevel) {
+			fmt.Println(line)
+		}
+	}
+
+	var exitCode int
+	var err error
+	if opts.Host == "" || opts.Host == "localhost" {
+		_, _, exitCode, err = hostexec.RunStreamingLocal(ctx, command, onOutput)
+	} else {
+		configPath, cerr := resolveConfigPath()
+		if cerr != nil {
+			return fmt.Errorf("determine config path: %w", cerr)
+		}
+		loadedCfg, cerr := config.Load(configPath)
+		if cerr != nil {
+			return fmt.Errorf("load config: %w", cerr)
+		}
+		alias, extraArgs, cerr := daemonHostSSHTarget(loadedCfg, opts.Host)
+		if cerr != nil {
+			return cerr
+		}
+		_, _, exitCode, err = hostexec.RunStreamingSSHAlias(ctx, alias, extraArgs, command, onOutput)
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read daemon logs: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("read daemon logs: command exited with status %d", exitCode)
+	}
+	return nil
+}
+
+// daemonHostSSHTarget returns the ssh destination and flags for a host from
+// the config, with the same defaults as 'deer doctor --host'.
+func daemonHostSSHTarget(cfg *config.Config, name string) (string, []string, error) {
+	for _, h := range cfg.Hosts {
+		if h.Name != name {
+			continue
+		}
+		user := h.SSHUser
+		if user == "" {
+			user = "root"
+		}
+		var extraArgs []string
+		if h.SSHPort != 0 && h.SSHPort != 22 {
+			extraArgs = append(extraArgs, "-p", strconv.Itoa(h.SSHPort))
+		}
+		return user + "@" + h.Address, extraArgs, nil
+	}
+	return "", nil, fmt.Errorf("host %q not found in config", name)
+}
+
+// daemonLogCommand builds the shell command that prints the daemon's log:
+// tail of opts.LogFile when set, otherwise the systemd journal.
+func daemonLogCommand(opts daemonLogOptions) string {
+	if opts.LogFile != "" {
+		follow := ""
+		if opts.Follow {
+			follow = " -F"
+		}
+		return fmt.Sprintf("tail -n %d%s %s", opts.Tail, follow, shellQuote(opts.LogFile))
+	}
+
+	args := []string{"journalctl", "-u", daemonUnit, "--no-pager", "-o", "cat", "-n", strconv.Itoa(opts.Tail)}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since", fmt.Sprintf("@%d", opts.Since.Unix()))
+	}
+	if opts.Follow {
+		args = append(args, "-f")
+	}
+	return "if ! command -v journalctl >/dev/null 2>&1; then " +
+		"echo 'journalctl not found on this host; pass --log-file with the daemon log path' >&2; exit 127; fi; " +
+		strings.Join(args, " ")
+}
+
+// keepDaemonLogLine applies --since and --level to one line of the daemon's
+// JSON log. Lines that are not JSON, or lack the field, are always kept.
+func keepDaemonLogLine(line string, since time.Time, minLevel slog.Level) bool {
+	var entry struct {
+		Time  time.Time `json:"time"`
+		Level string    `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return true
+	}
+	if !since.IsZero() && !entry.Time.IsZero() && entry.Time.Before(since) {
+		return false
+	}
+	if entry.Level != "" {
+		var lvl slog.Level
+		if err := lvl.UnmarshalText([]byte(entry.Level)); err == nil && lvl < minLevel {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLogLevel parses --level (debug, info, warn, error).
+func parseLogLevel(s string) (slog.Level, error) {
+	if s == "" {
+		return slog.LevelDebug, nil
+	}
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid --level %q: must be debug, info, warn, or error", s)
+	}
+	return lvl, nil
+}
+
+// parseSince parses --since as a duration before now (e.g. 30m) or an
+// RFC3339 timestamp.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration like 30m or an RFC3339 time", s)
+}
+
+// shellQuote wraps s in single quotes for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
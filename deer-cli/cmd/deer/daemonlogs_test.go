@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+func TestDaemonLogCommand(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	got := daemonLogCommand(daemonLogOptions{Tail: 50, Follow: true, Since: since})
+	for _, want := range []string{"journalctl -u deer-daemon", "-n 50", "--since @1700000000", " -f", "command -v journalctl"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("journal command %q missing %q", got, want)
+		}
+	}
+
+	got = daemonLogCommand(daemonLogOptions{Tail: 20, Follow: true, LogFile: "/var/log/deer-daemon/it's.log"})
+	if want := `tail -n 20 -F '/var/log/deer-daemon/it'"'"'s.log'`; got != want {
+		t.Errorf("file command = %q, want %q", got, want)
+	}
+}
+
+func TestKeepDaemonLogLine(t *testing.T) {
+	since := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{`{"time":"2026-01-01T12:30:00Z","level":"WARN","msg":"x"}`, true},
+		{`{"time":"2026-01-01T12:30:00Z","level":"INFO","msg":"x"}`, false},
+		{`{"time":"2026-01-01T11:00:00Z","level":"ERROR","msg":"x"}`, false},
+		{`plain text from systemd`, true},
+	}
+	for _, tt := range tests {
+		if got := keepDaemonLogLine(tt.line, since, slog.LevelWarn); got != tt.want {
+			t.Errorf("keepDaemonLogLine(%s) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if got, err := parseSince("30m", now); err != nil || !got.Equal(now.Add(-30*time.Minute)) {
+		t.Errorf("parseSince(30m) = %v, %v", got, err)
+	}
+	if got, err := parseSince("2026-01-01T10:00:00Z", now); err != nil || got.Hour() != 10 {
+		t.Errorf("parseSince(RFC3339) = %v, %v", got, err)
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Error("expected error for unparseable --since")
+	}
+}
+
+func TestDaemonHostSSHTarget(t *testing.T) {
+	cfg := &config.Config{Hosts: []config.HostConfig{{Name: "prod", Address: "10.0.0.9", SSHPort: 2222}}}
+	alias, args, err := daemonHostSSHTarget(cfg, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alias != "root@10.0.0.9" || strings.Join(args, " ") != "-p 2222" {
+		t.Errorf("target = %q %v", alias, args)
+	}
+	if _, _, err := daemonHostSSHTarget(cfg, "missing"); err == nil {
+		t.Error("expected error for unknown host")
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// snapshotDiffer is implemented by sandbox services that can diff snapshots
+// (the daemon-backed RemoteService).
+type snapshotDiffer interface {
+	DiffSnapshots(ctx context.Context, sandboxID, from, to string, onProgress func(step string)) (*sandbox.SnapshotDiff, error)
+}
+
+// diffOptions are the arguments and flags of 'sandbox diff'.
+type diffOptions struct {
+	From, To     string
+	Format       string // "files" or "unified"
+	MaxFiles     int
+	MaxFileBytes int
+}
+
+func runSandboxDiff(sandboxID string, opts diffOptions) error {
+	if opts.Format != "files" && opts.Format != "unified" {
+		return fmt.Errorf("--format must be files or unified, got %q", opts.Format)
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	differ, ok := svc.(snapshotDiffer)
+	if !ok {
+		return fmt.Errorf("snapshot diffs need a sandbox host; run 'deer connect' first")
+	}
+
+	// Progress goes to stderr so structured output on stdout stays clean.
+	ctx := context.Background()
+	diff, err := differ.DiffSnapshots(ctx, sandboxID, opts.From, opts.To, func(step string) {
+		fmt.Fprintf(os.Stderr, "  %s...\n", step)
+	})
+	if err != nil {
+		return fmt.Errorf("diff snapshots: %w", err)
+	}
+	if opts.Format == "unified" {
+		reader, ok := svc.(sandbox.SnapshotFileReader)
+		if !ok {
+			return fmt.Errorf("unified diffs need a sandbox host; run 'deer connect' first")
+		}
+		if len(diff.FilesModified) > 0 {
+			fmt.Fprintln(os.Stderr, "  Fetching modified files...")
+		}
+		diff.Patches = sandbox.UnifiedDiffs(ctx, reader, diff, opts.MaxFiles, opts.MaxFileBytes)
+	}
+
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, diff)
+	}
+	printSnapshotDiff(os.Stdout, diff)
+	printFilePatches(os.Stdout, diff.Patches)
+	return nil
+}
+
+// printFilePatches writes each patch as-is, and a note for files that were
+// not diffed.
+func printFilePatches(w io.Writer, patches []sandbox.FilePatch) {
+	for _, p := range patches {
+		fmt.Fprintln(w)
+		if p.Skipped != "" {
+			fmt.Fprintf(w, "  %s: not diffed (%s)\n", p.Path, p.Skipped)
+			continue
+		}
+		if p.Patch == "" {
+			fmt.Fprintf(w, "  %s: contents unchanged\n", p.Path)
+			continue
+		}
+		fmt.Fprint(w, p.Patch)
+	}
+}
+
+// printSnapshotDiff writes a diff as +/~/- file lists, then package changes,
+// then the commands run between the snapshots.
+func printSnapshotDiff(w io.Writer, d *sandbox.SnapshotDiff) {
+	to := d.To
+	if to == "" {
+		to = "now"
+	}
+	fmt.Fprintf(w, "  %s: %s -> %s\n", d.SandboxID, d.From, to)
+	if len(d.FilesAdded)+len(d.FilesModified)+len(d.FilesRemoved) == 0 {
+		fmt.Fprintln(w, "  No file changes")
+	}
+	for _, f := range d.FilesAdded {
+		fmt.Fprintf(w, "  + %s\n", f)
+	}
+	for _, f := range d.FilesModified {
+		fmt.Fprintf(w, "  ~ %s\n", f)
+	}
+	for _, f := range d.FilesRemoved {
+		fmt.Fprintf(w, "  - %s\n", f)
+	}
+	if len(d.PackagesAdded)+len(d.PackagesRemoved) > 0 {
+		fmt.Fprintln(w, "  Packages:")
+		for _, p := range d.PackagesAdded {
+			fmt.Fprintf(w, "    + %s %s\n", p.Name, p.Version)
+		}
+		for _, p := range d.PackagesRemoved {
+			fmt.Fprintf(w, "    - %s %s\n", p.Name, p.Version)
+		}
+	}
+	if len(d.CommandsRun) > 0 {
+		fmt.Fprintln(w, "  Commands run:")
+		for _, c := range d.CommandsRun {
+			fmt.Fprintf(w, "    [%d] %s\n", c.ExitCode, c.Command)
+		}
+	}
+}
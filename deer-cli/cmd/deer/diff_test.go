@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+)
+
+func TestPrintSnapshotDiff(t *testing.T) {
+	var buf bytes.Buffer
+	printSnapshotDiff(&buf, &sandbox.SnapshotDiff{
+		SandboxID:       "SBX-1",
+		From:            "before",
+		FilesAdded:      []string{"/etc/new"},
+		FilesModified:   []string{"/etc/app.conf"},
+		FilesRemoved:    []string{"/etc/old"},
+		PackagesAdded:   []store.PackageInfo{{Name: "nginx", Version: "1.24.0-2"}},
+		PackagesRemoved: []store.PackageInfo{{Name: "telnet", Version: "0.17-85"}},
+		CommandsRun:     []*sandbox.CommandRecord{{Command: "vi /etc/app.conf"}},
+	})
+	out := buf.String()
+	for _, want := range []string{
+		"SBX-1: before -> now",
+		"  + /etc/new\n",
+		"  ~ /etc/app.conf\n",
+		"  - /etc/old\n",
+		"    + nginx 1.24.0-2\n",
+		"    - telnet 0.17-85\n",
+		"[0] vi /etc/app.conf",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintFilePatches(t *testing.T) {
+	var buf bytes.Buffer
+	printFilePatches(&buf, []sandbox.FilePatch{
+		{Path: "/etc/app.conf", Patch: "--- a/etc/app.conf\tbefore\n+++ b/etc/app.conf\tnow\n"},
+		{Path: "/usr/bin/tool", Skipped: "binary file"},
+		{Path: "/etc/mode-only"},
+	})
+	out := buf.String()
+	for _, want := range []string{
+		"--- a/etc/app.conf\tbefore\n",
+		"  /usr/bin/tool: not diffed (binary file)\n",
+		"  /etc/mode-only: contents unchanged\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
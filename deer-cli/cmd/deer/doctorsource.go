@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/doctor"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/hostexec"
+)
+
+// runSourceVMDoctor checks that a source VM trusts the connected daemon's
+// SSH CA for read-only access. hostName, when set, names the configured
+// host the VM runs on; the VM is then reached through it.
+func runSourceVMDoctor(ctx context.Context, cfg *config.Config, vmName, hostName string) error {
+	run, err := sourceVMRunner(cfg, vmName, hostName)
+	if err != nil {
+		return err
+	}
+
+	results := doctor.RunSourceVM(ctx, run, vmName, daemonCAPubKey(ctx, cfg))
+
+	if jsonOutput {
+		allPassed, err := doctor.WriteJSON(results, os.Stdout)
+		if err != nil {
+			return err
+		}
+		if !allPassed {
+			os.Exit(1)
+		}
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("  Checking read-only SSH setup on %s...\n", vmName)
+	fmt.Println()
+	allPassed := doctor.PrintResults(results, os.Stdout, os.Getenv("NO_COLOR") == "")
+	fmt.Println()
+	if !allPassed {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// sourceVMRunner returns a RunFunc that reaches vmName as an administrator.
+// Without a host it relies on ~/.ssh/config; with one it connects as the
+// host's VM user, jumping through the host unless its VMs are bridged.
+func sourceVMRunner(cfg *config.Config, vmName, hostName string) (hostexec.RunFunc, error) {
+	if hostName == "" || hostName == "localhost" {
+		return hostexec.NewSSHAlias(vmName), nil
+	}
+	for _, h := range cfg.Hosts {
+		if h.Name != hostName {
+			continue
+		}
+		vmUser := h.SSHVMUser
+		if vmUser == "" {
+			vmUser = "root"
+		}
+		if h.DirectAccess {
+			return hostexec.NewSSH(vmName, vmUser, 22), nil
+		}
+		user := h.SSHUser
+		if user == "" {
+			user = "root"
+		}
+		jump := user + "@" + h.Address
+		if h.SSHPort != 0 && h.SSHPort != 22 {
+			jump = fmt.Sprintf("%s:%d", jump, h.SSHPort)
+		}
+		return hostexec.NewSSHWithJump(vmName, vmUser, 22, jump), nil
+	}
+	return nil, fmt.Errorf("host %q not found in config", hostName)
+}
+
+// daemonCAPubKey returns the connected daemon's SSH CA public key, or ""
+// when no daemon is reachable.
+func daemonCAPubKey(ctx context.Context, cfg *config.Config) string {
+	svc := initSandboxService(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer func() { _ = svc.Close() }()
+
+	infoCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	info, err := svc.GetHostInfo(infoCtx)
+	if err != nil {
+		return ""
+	}
+	return info.SSHCAPubKey
+}
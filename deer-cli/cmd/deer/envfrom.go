@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// exportableEnvName matches variable names the daemon can export; other
+// entries in a process environment are skipped.
+var exportableEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envSourceCommand builds the sandbox command that prints the environment of
+// source, base64 encoded. source is a pid ("1234" or "pid:1234") or a systemd
+// service name whose main process is read.
+func envSourceCommand(source string) (string, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return "", fmt.Errorf("--env-from-sandbox needs a pid or service name")
+	}
+
+	pidExpr := ""
+	prelude := ""
+	if pid, ok := strings.CutPrefix(source, "pid:"); ok || isAllDigits(source) {
+		if !ok {
+			pid = source
+		}
+		if _, err := strconv.Atoi(pid); err != nil {
+			return "", fmt.Errorf("invalid pid %q", pid)
+		}
+		pidExpr = pid
+	} else {
+		unit := shellQuote(source)
+		prelude = fmt.Sprintf("pid=$(systemctl show -p MainPID --value %s) && [ -n \"$pid\" ] && [ \"$pid\" != 0 ] || "+
+			"{ echo %s >&2; exit 1; }; ", unit, shellQuote("service "+source+" is not running"))
+		pidExpr = "$pid"
+	}
+
+	environ := "/proc/" + pidExpr + "/environ"
+	// The target usually runs as another user; fall back to sudo to read it.
+	return prelude + fmt.Sprintf("{ cat %[1]s 2>/dev/null || sudo -n cat %[1]s; } | base64", environ), nil
+}
+
+// parseEnviron parses the NUL-separated contents of /proc/<pid>/environ,
+// dropping entries whose names cannot be exported.
+func parseEnviron(data []byte) map[string]string {
+	env := make(map[string]string)
+	for _, entry := range strings.Split(string(data), "\x00") {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !exportableEnvName.MatchString(name) {
+			continue
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// fetchSandboxEnv reads the environment of a process or service running in
+// the sandbox. The read is unrecorded so the values, which are often
+// secrets, stay out of command history.
+func fetchSandboxEnv(ctx context.Context, svc sandbox.Service, sandboxID, source string) (map[string]string, error) {
+	command, err := envSourceCommand(source)
+	if err != nil {
+		return nil, err
+	}
+	result, err := svc.RunCommandWithOptions(ctx, sandboxID, command, sandbox.RunOptions{Unrecorded: true})
+	if err != nil {
+		return nil, fmt.Errorf("read environment of %s: %w", source, err)
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("read environment of %s: %s", source, strings.TrimSpace(result.Stderr))
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(result.Stdout), ""))
+	if err != nil {
+		return nil, fmt.Errorf("decode environment of %s: %w", source, err)
+	}
+	return parseEnviron(data), nil
+}
+
+func isAllDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvSourceCommand(t *testing.T) {
+	for _, src := range []string{"1234", "pid:1234"} {
+		cmd, err := envSourceCommand(src)
+		if err != nil {
+			t.Fatalf("envSourceCommand(%q): %v", src, err)
+		}
+		if !strings.Contains(cmd, "/proc/1234/environ") || strings.Contains(cmd, "systemctl") {
+			t.Errorf("envSourceCommand(%q) = %q", src, cmd)
+		}
+	}
+
+	cmd, err := envSourceCommand("nginx.service")
+	if err != nil {
+		t.Fatalf("envSourceCommand(service): %v", err)
+	}
+	if !strings.Contains(cmd, "systemctl show -p MainPID --value 'nginx.service'") || !strings.Contains(cmd, "/proc/$pid/environ") {
+		t.Errorf("envSourceCommand(service) = %q", cmd)
+	}
+
+	if _, err := envSourceCommand("pid:abc"); err == nil {
+		t.Error("expected an error for a non-numeric pid")
+	}
+}
+
+func TestParseEnviron(t *testing.T) {
+	env := parseEnviron([]byte("PATH=/usr/bin\x00DB_URL=postgres://a=b\x00bad.name=x\x00EMPTY=\x00junk\x00"))
+	want := map[string]string{"PATH": "/usr/bin", "DB_URL": "postgres://a=b", "EMPTY": ""}
+	if len(env) != len(want) {
+		t.Fatalf("env = %v, want %v", env, want)
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("env[%s] = %q, want %q", k, env[k], v)
+		}
+	}
+}
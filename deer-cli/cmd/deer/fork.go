@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// sandboxForker is implemented by sandbox services that can clone a sandbox
+// from one of its snapshots (the daemon-backed RemoteService).
+type sandboxForker interface {
+	ForkSandbox(ctx context.Context, req sandbox.ForkRequest) (*sandbox.SandboxInfo, error)
+}
+
+func runFork(req sandbox.ForkRequest) error {
+	if req.Snapshot == "" {
+		return fmt.Errorf("--snapshot is required")
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	forker, ok := svc.(sandboxForker)
+	if !ok {
+		return fmt.Errorf("forking sandboxes needs a sandbox host; run 'deer connect' first")
+	}
+
+	sb, err := forker.ForkSandbox(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("fork sandbox: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, sb)
+	}
+	fmt.Printf("  Forked %s at %s into sandbox %s (%s)\n", req.SandboxID, req.Snapshot, sb.ID, sb.Name)
+	if sb.IPAddress != "" {
+		fmt.Printf("  IP: %s\n", sb.IPAddress)
+	}
+	return nil
+}
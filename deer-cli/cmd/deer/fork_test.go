@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestRunFork_RequiresSnapshot(t *testing.T) {
+	err := runFork(sandbox.ForkRequest{SandboxID: "sbx-1"})
+	if err == nil || !strings.Contains(err.Error(), "--snapshot") {
+		t.Errorf("err = %v, want a --snapshot error", err)
+	}
+}
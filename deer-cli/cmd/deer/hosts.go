@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// hostCheckTimeout bounds each host's status call so one unreachable host
+// does not hold up 'deer hosts --check'.
+const hostCheckTimeout = 5 * time.Second
+
+// hostEntry is one sandbox host in 'deer hosts' output. The reachability
+// fields are only filled in with --check.
+type hostEntry struct {
+	Name          string `json:"name"`
+	DaemonAddress string `json:"daemon_address"`
+	Insecure      bool   `json:"insecure,omitempty"`
+	Checked       bool   `json:"checked"`
+	Reachable     bool   `json:"reachable"`
+	Version       string `json:"version,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	LatencyMS     int64  `json:"latency_ms,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// hostStatusFunc fetches the daemon status of one sandbox host.
+type hostStatusFunc func(ctx context.Context, sh config.SandboxHostConfig) (*sandbox.DaemonStatus, error)
+
+// daemonHostStatus connects to sh's daemon and asks for its status.
+func daemonHostStatus(ctx context.Context, sh config.SandboxHostConfig) (*sandbox.DaemonStatus, error) {
+	svc, err := sandbox.NewRemoteService(sh.DaemonAddress, config.ControlPlaneConfig{
+		DaemonAddress:  sh.DaemonAddress,
+		DaemonInsecure: sh.Insecure,
+		DaemonCAFile:   sh.CAFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = svc.Close() }()
+	return svc.GetStatus(ctx)
+}
+
+// checkHosts lists hosts and, when check is set, queries every host's
+// daemon concurrently, recording reachability, version, and round-trip
+// latency. Entries keep the config order.
+func checkHosts(ctx context.Context, hosts []config.SandboxHostConfig, check bool, status hostStatusFunc) []*hostEntry {
+	entries := make([]*hostEntry, len(hosts))
+	var wg sync.WaitGroup
+	for i, sh := range hosts {
+		entries[i] = &hostEntry{Name: sh.Name, DaemonAddress: sh.DaemonAddress, Insecure: sh.Insecure}
+		if !check {
+			continue
+		}
+		wg.Add(1)
+		go func(e *hostEntry, sh config.SandboxHostConfig) {
+			defer wg.Done()
+			hostCtx, cancel := context.WithTimeout(ctx, hostCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			st, err := status(hostCtx, sh)
+			e.Checked = true
+			e.LatencyMS = time.Since(start).Milliseconds()
+			if err != nil {
+				e.Error = err.Error()
+				return
+			}
+			e.Reachable = true
+			e.Version = st.Version
+			e.Provider = st.Provider
+		}(entries[i], sh)
+	}
+	wg.Wait()
+	return entries
+}
+
+func runHosts(check bool) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	entries := checkHosts(context.Background(), loadedCfg.SandboxHosts, check, daemonHostStatus)
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, entries)
+	}
+	printHosts(os.Stdout, entries, check)
+	return nil
+}
+
+// printHosts writes one row per host, with reachability columns when the
+// hosts were checked.
+func printHosts(w io.Writer, entries []*hostEntry, check bool) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "  No sandbox hosts configured.")
+		fmt.Fprintln(w, "  Run: deer connect <address>")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !check {
+		fmt.Fprintln(tw, "  NAME\tADDRESS")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "  %s\t%s\n", e.Name, e.DaemonAddress)
+		}
+		_ = tw.Flush()
+		return
+	}
+	fmt.Fprintln(tw, "  NAME\tADDRESS\tREACHABLE\tVERSION\tPROVIDER\tLATENCY")
+	for _, e := range entries {
+		reachable := "yes"
+		if !e.Reachable {
+			reachable = "no"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\t%dms\n", e.Name, e.DaemonAddress, reachable, orDash(e.Version), orDash(e.Provider), e.LatencyMS)
+	}
+	_ = tw.Flush()
+	for _, e := range entries {
+		if e.Error != "" {
+			fmt.Fprintf(w, "\n  %s: %s\n", e.Name, e.Error)
+		}
+	}
+}
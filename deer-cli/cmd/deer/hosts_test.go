@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestCheckHosts(t *testing.T) {
+	hosts := []config.SandboxHostConfig{
+		{Name: "up", DaemonAddress: "10.0.0.1:9091"},
+		{Name: "down", DaemonAddress: "10.0.0.2:9091"},
+	}
+	var calls atomic.Int32
+	status := func(_ context.Context, sh config.SandboxHostConfig) (*sandbox.DaemonStatus, error) {
+		calls.Add(1)
+		if sh.Name == "down" {
+			return nil, errors.New("connection refused")
+		}
+		return &sandbox.DaemonStatus{Version: "v0.4.0", Provider: "lxc"}, nil
+	}
+
+	entries := checkHosts(context.Background(), hosts, false, status)
+	if calls.Load() != 0 || entries[0].Checked {
+		t.Fatalf("without --check: calls=%d checked=%v, want no status calls", calls.Load(), entries[0].Checked)
+	}
+
+	entries = checkHosts(context.Background(), hosts, true, status)
+	if len(entries) != 2 || entries[0].Name != "up" || entries[1].Name != "down" {
+		t.Fatalf("entries = %+v, want config order", entries)
+	}
+	if e := entries[0]; !e.Reachable || e.Version != "v0.4.0" || e.Provider != "lxc" || e.Error != "" {
+		t.Errorf("up = %+v", e)
+	}
+	if e := entries[1]; !e.Checked || e.Reachable || !strings.Contains(e.Error, "refused") {
+		t.Errorf("down = %+v", e)
+	}
+}
+
+func TestPrintHosts(t *testing.T) {
+	var buf bytes.Buffer
+	printHosts(&buf, []*hostEntry{
+		{Name: "up", DaemonAddress: "10.0.0.1:9091", Checked: true, Reachable: true, Version: "v0.4.0", Provider: "lxc", LatencyMS: 12},
+		{Name: "down", DaemonAddress: "10.0.0.2:9091", Checked: true, Error: "connection refused"},
+	}, true)
+	out := buf.String()
+	for _, want := range []string{"REACHABLE", "12ms", "v0.4.0", "no", "down: connection refused"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// sshTargeter is implemented by sandbox services that can hand out a
+// sandbox's SSH destination (the daemon-backed RemoteService).
+type sshTargeter interface {
+	SSHTarget(ctx context.Context, sandboxID string) (*sandbox.SSHTarget, error)
+}
+
+// runSandboxInteractive attaches the local terminal to command (or a login
+// shell when empty) in the sandbox over 'ssh -t'. The session does not go
+// through the daemon's RunCommand, so it is not recorded in command history;
+// the daemon audits the SSH target lookup instead.
+func runSandboxInteractive(ctx context.Context, cfg *config.Config, svc sandbox.Service, sandboxID, command string) error {
+	targeter, ok := svc.(sshTargeter)
+	if !ok || len(cfg.SandboxHosts) == 0 {
+		return fmt.Errorf("interactive sessions need a sandbox host; run 'deer connect' first")
+	}
+	target, err := targeter.SSHTarget(ctx, sandboxID)
+	if err != nil {
+		return fmt.Errorf("get SSH target: %w", err)
+	}
+
+	args := interactiveSSHArgs(target, cfg.SandboxHosts[0], cfg.SSH.ProxyJump, command)
+	cmd := exec.Command("ssh", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// ssh puts the terminal in raw mode and restores it on exit; Ctrl-C must
+	// reach the remote program rather than kill ssh.
+	signal.Ignore(os.Interrupt)
+	defer signal.Reset(os.Interrupt)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("session exited with status %d", exitErr.ExitCode())
+		}
+		return fmt.Errorf("run ssh: %w", err)
+	}
+	return nil
+}
+
+// interactiveSSHArgs builds the ssh arguments for an interactive session,
+// checking the sandbox's host key the way the daemon is configured to.
+// The sandbox key lives on the daemon host, so unless the daemon is local the
+// session hops through it (and through proxyJump, when set) and runs a second
+// 'ssh -t' from there.
+func interactiveSSHArgs(target *sandbox.SSHTarget, host config.SandboxHostConfig, proxyJump, command string) []string {
+	hostKeyOptions := target.HostKeyOptions
+	if len(hostKeyOptions) == 0 {
+		// Daemons that predate ssh.host_key_mode only run insecure.
+		hostKeyOptions = []string{
+			"-o", "StrictHostKeyChecking=no",
+			"-o", "UserKnownHostsFile=/dev/null",
+		}
+	}
+	inner := []string{
+		"-t",
+		"-i", target.PrivateKeyPath,
+		"-o", "CertificateFile=" + target.CertificatePath,
+	}
+	inner = append(inner, hostKeyOptions...)
+	inner = append(inner,
+		"-o", "LogLevel=ERROR",
+		target.Username+"@"+target.IPAddress,
+	)
+
+	daemonHost := host.DaemonAddress
+	if h, _, err := net.SplitHostPort(daemonHost); err == nil {
+		daemonHost = h
+	}
+	if isLocalHost(daemonHost) {
+		if command != "" {
+			inner = append(inner, "--", command)
+		}
+		return inner
+	}
+
+	quoted := make([]string, 0, len(inner)+2)
+	quoted = append(quoted, "ssh")
+	for _, a := range inner {
+		quoted = append(quoted, shellQuote(a))
+	}
+	if command != "" {
+		quoted = append(quoted, "--", shellQuote(command))
+	}
+
+	user := host.SSHUser
+	if user == "" {
+		user = "root"
+	}
+	outer := []string{"-t"}
+	if proxyJump != "" {
+		outer = append(outer, "-J", proxyJump)
+	}
+	return append(outer, user+"@"+daemonHost, "--", strings.Join(quoted, " "))
+}
+
+func isLocalHost(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func testSSHTarget() *sandbox.SSHTarget {
+	return &sandbox.SSHTarget{
+		SandboxID:       "SBX-1",
+		IPAddress:       "10.0.0.5",
+		Username:        "sandbox",
+		PrivateKeyPath:  "/var/lib/deer-daemon/keys/SBX-1",
+		CertificatePath: "/var/lib/deer-daemon/keys/SBX-1-cert.pub",
+	}
+}
+
+func TestInteractiveSSHArgs_LocalDaemon(t *testing.T) {
+	args := interactiveSSHArgs(testSSHTarget(), config.SandboxHostConfig{DaemonAddress: "localhost:9091"}, "", "top")
+	got := strings.Join(args, " ")
+	if !strings.HasPrefix(got, "-t -i /var/lib/deer-daemon/keys/SBX-1 ") {
+		t.Errorf("args = %q, want a direct ssh -t with the sandbox key", got)
+	}
+	if !strings.HasSuffix(got, "sandbox@10.0.0.5 -- top") {
+		t.Errorf("args = %q, want the command after the destination", got)
+	}
+}
+
+func TestInteractiveSSHArgs_RemoteDaemonHopsThroughHost(t *testing.T) {
+	host := config.SandboxHostConfig{DaemonAddress: "10.1.0.2:9091", SSHUser: "admin"}
+	args := interactiveSSHArgs(testSSHTarget(), host, "jump@bastion", "echo 'hi'")
+
+	if strings.Join(args[:5], " ") != "-t -J jump@bastion admin@10.1.0.2 --" {
+		t.Fatalf("outer args = %q", args[:5])
+	}
+	inner := args[5]
+	if !strings.HasPrefix(inner, "ssh '-t' '-i' '/var/lib/deer-daemon/keys/SBX-1'") {
+		t.Errorf("inner = %q, want a quoted nested ssh", inner)
+	}
+	if !strings.HasSuffix(inner, `'sandbox@10.0.0.5' -- 'echo '"'"'hi'"'"''`) {
+		t.Errorf("inner = %q, want the quoted command last", inner)
+	}
+}
+
+func TestInteractiveSSHArgs_NoCommandOpensShell(t *testing.T) {
+	args := interactiveSSHArgs(testSSHTarget(), config.SandboxHostConfig{DaemonAddress: "127.0.0.1:9091"}, "", "")
+	if args[len(args)-1] != "sandbox@10.0.0.5" {
+		t.Errorf("args = %q, want the destination last", args)
+	}
+}
+
+func TestInteractiveSSHArgs_UsesDaemonHostKeyOptions(t *testing.T) {
+	target := testSSHTarget()
+	got := strings.Join(interactiveSSHArgs(target, config.SandboxHostConfig{DaemonAddress: "localhost:9091"}, "", ""), " ")
+	if !strings.Contains(got, "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null") {
+		t.Errorf("args = %q, want insecure host key options without daemon options", got)
+	}
+
+	target.HostKeyOptions = []string{"-o", "StrictHostKeyChecking=accept-new", "-o", "UserKnownHostsFile=/keys/SBX-1/known_hosts"}
+	got = strings.Join(interactiveSSHArgs(target, config.SandboxHostConfig{DaemonAddress: "localhost:9091"}, "", ""), " ")
+	if !strings.Contains(got, "-o StrictHostKeyChecking=accept-new -o UserKnownHostsFile=/keys/SBX-1/known_hosts") || strings.Contains(got, "StrictHostKeyChecking=no") {
+		t.Errorf("args = %q, want the daemon's host key options only", got)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// ipRefresher is implemented by sandbox services backed by a daemon that can
+// rediscover sandbox IPs (the RemoteService).
+type ipRefresher interface {
+	RefreshIPs(ctx context.Context, sandboxIDs ...string) ([]sandbox.IPRefresh, error)
+}
+
+func runIP(sandboxID string, all bool) error {
+	if all == (sandboxID != "") {
+		return fmt.Errorf("give a sandbox ID or --all, but not both")
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	refresher, ok := svc.(ipRefresher)
+	if !ok {
+		return fmt.Errorf("discovering IPs needs a sandbox host; run 'deer connect' first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var ids []string
+	if sandboxID != "" {
+		ids = []string{sandboxID}
+	}
+	results, err := refresher.RefreshIPs(ctx, ids...)
+	if err != nil {
+		return fmt.Errorf("discover IPs: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, results)
+	}
+	printIPRefresh(os.Stdout, results)
+
+	for _, r := range results {
+		if r.Error != "" {
+			return fmt.Errorf("IP discovery failed for some sandboxes")
+		}
+	}
+	return nil
+}
+
+// printIPRefresh writes one line per sandbox with its IP, noting changes and
+// failures.
+func printIPRefresh(w io.Writer, results []sandbox.IPRefresh) {
+	if len(results) == 0 {
+		fmt.Fprintln(w, "  No running sandboxes")
+		return
+	}
+	for _, r := range results {
+		name := r.Name
+		if name == "" {
+			name = "-"
+		}
+		switch {
+		case r.Error != "":
+			fmt.Fprintf(w, "  %s  %s  error: %s\n", r.SandboxID, name, r.Error)
+		case r.PreviousIP != "" && r.PreviousIP != r.IPAddress:
+			fmt.Fprintf(w, "  %s  %s  %s (was %s)\n", r.SandboxID, name, r.IPAddress, r.PreviousIP)
+		default:
+			fmt.Fprintf(w, "  %s  %s  %s\n", r.SandboxID, name, r.IPAddress)
+		}
+	}
+}
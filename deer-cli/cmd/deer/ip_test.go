@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestPrintIPRefresh(t *testing.T) {
+	var buf bytes.Buffer
+	printIPRefresh(&buf, []sandbox.IPRefresh{
+		{SandboxID: "SBX-1", Name: "web", IPAddress: "10.0.0.4"},
+		{SandboxID: "SBX-2", Name: "db", IPAddress: "10.0.0.9", PreviousIP: "10.0.0.5"},
+		{SandboxID: "SBX-3", Error: "discover IP: no DHCP lease"},
+	})
+	out := buf.String()
+	for _, want := range []string{"SBX-1  web  10.0.0.4\n", "SBX-2  db  10.0.0.9 (was 10.0.0.5)", "SBX-3  -  error: discover IP: no DHCP lease"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printIPRefresh(&buf, nil)
+	if !strings.Contains(buf.String(), "No running sandboxes") {
+		t.Errorf("empty output = %q", buf.String())
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+const (
+	// logsPollInterval is how often --follow checks for new commands.
+	logsPollInterval = time.Second
+
+	// logsFollowBatch is the page size --follow reads new commands in; a poll
+	// keeps paging until it has caught up.
+	logsFollowBatch = 50
+
+	// maxLogOutputBytes truncates stdout/stderr in the human-readable view.
+	maxLogOutputBytes = 500
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <sandbox_id> | logs --daemon [--host <name>]",
+	Short: "Show the command history of a sandbox, or the daemon's log",
+	Long: "Print the commands run inside a sandbox with exit codes, timestamps, and truncated output. With --json the full records are printed as an array (one object per line with --follow).\n\n" +
+		"With --daemon, print the deer-daemon's log from the systemd journal (or --log-file) on the local host or, with --host, over SSH. --since and --level filter the lines.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tail, _ := cmd.Flags().GetInt("tail")
+		follow, _ := cmd.Flags().GetBool("follow")
+		if tail < 0 {
+			return fmt.Errorf("--tail must not be negative")
+		}
+
+		daemon, _ := cmd.Flags().GetBool("daemon")
+		if !daemon {
+			for _, name := range []string{"host", "since", "level", "log-file"} {
+				if cmd.Flags().Changed(name) {
+					return fmt.Errorf("--%s requires --daemon", name)
+				}
+			}
+			if len(args) != 1 {
+				return fmt.Errorf("a sandbox ID is required (or pass --daemon)")
+			}
+			actor, _ := cmd.Flags().GetString("actor")
+			if err := validateActor(actor); err != nil {
+				return err
+			}
+			return runLogs(args[0], tail, follow, actor)
+		}
+
+		if len(args) != 0 {
+			return fmt.Errorf("--daemon does not take a sandbox ID")
+		}
+		if cmd.Flags().Changed("actor") {
+			return fmt.Errorf("--actor cannot be used with --daemon")
+		}
+		opts := daemonLogOptions{Tail: tail, Follow: follow}
+		opts.Host, _ = cmd.Flags().GetString("host")
+		opts.LogFile, _ = cmd.Flags().GetString("log-file")
+		since, _ := cmd.Flags().GetString("since")
+		level, _ := cmd.Flags().GetString("level")
+		var err error
+		if opts.Since, err = parseSince(since, time.Now()); err != nil {
+			return err
+		}
+		if opts.MinLevel, err = parseLogLevel(level); err != nil {
+			return err
+		}
+		return runDaemonLogs(opts)
+	},
+}
+
+// knownActors are the values --actor accepts.
+var knownActors = []string{sandbox.ActorHumanCLI, sandbox.ActorTUIAgent, sandbox.ActorMCPClient, sandbox.ActorPlaybook}
+
+// validateActor rejects an --actor value no command is ever recorded with.
+func validateActor(actor string) error {
+	if actor == "" || slices.Contains(knownActors, actor) {
+		return nil
+	}
+	return fmt.Errorf("unknown actor %q (want one of: %s)", actor, strings.Join(knownActors, ", "))
+}
+
+func runLogs(sandboxID string, tail int, follow bool, actor string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	core, err := initCoreServices(loadedCfg, logger)
+	if err != nil {
+		return fmt.Errorf("init core services: %w", err)
+	}
+	defer func() { _ = core.store.Close() }()
+	defer core.telemetry.Close()
+
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	useColor := os.Getenv("NO_COLOR") == ""
+
+	if !follow {
+		cmds, err := svc.ListSandboxCommands(ctx, sandboxID, tail, actor)
+		if err != nil {
+			return fmt.Errorf("list sandbox commands: %w", err)
+		}
+		if outputFormat != "" {
+			if cmds == nil {
+				cmds = []*sandbox.CommandRecord{}
+			}
+			return writeOutput(os.Stdout, cmds)
+		}
+		if len(cmds) == 0 {
+			fmt.Println("  No commands recorded for this sandbox.")
+			return nil
+		}
+		for _, c := range cmds {
+			printCommandRecord(os.Stdout, c, useColor)
+		}
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	src, ok := svc.(commandPager)
+	if !ok {
+		return fmt.Errorf("following logs needs a sandbox host; run 'deer connect' first")
+	}
+	enc := json.NewEncoder(os.Stdout)
+	f := &commandFollower{
+		src:       src,
+		sandboxID: sandboxID,
+		actor:     actor,
+		tail:      tail,
+		show: func(c *sandbox.CommandRecord) error {
+			if jsonOutput {
+				if err := enc.Encode(c); err != nil {
+					return fmt.Errorf("encode JSON output: %w", err)
+				}
+				return nil
+			}
+			printCommandRecord(os.Stdout, c, useColor)
+			return nil
+		},
+	}
+	return watchLoop(ctx, logsPollInterval, f.poll)
+}
+
+// commandPager is implemented by services that can also list the commands
+// recorded after a given one, which --follow uses to page through new
+// history.
+type commandPager interface {
+	ListSandboxCommands(ctx context.Context, sandboxID string, limit int, actor string) ([]*sandbox.CommandRecord, error)
+	ListSandboxCommandsAfter(ctx context.Context, sandboxID, afterID string, limit int, actor string) ([]*sandbox.CommandRecord, error)
+}
+
+// commandFollower prints a sandbox's new commands for logs --follow.
+type commandFollower struct {
+	src       commandPager
+	sandboxID string
+	actor     string
+	tail      int // honoured by the first fetch only
+	show      func(*sandbox.CommandRecord) error
+
+	// cursor is the last command shown; once set, polls page forward from
+	// it so nothing is skipped however many commands land between polls.
+	cursor string
+}
+
+// poll shows the commands recorded since the last poll. It is a watchLoop
+// step: listing errors are reported and retried on the next poll, and only
+// a failure to show a record ends the follow.
+func (f *commandFollower) poll(ctx context.Context) (bool, error) {
+	for {
+		var cmds []*sandbox.CommandRecord
+		var err error
+		if f.cursor == "" {
+			// Until a command shows up, the whole (short) history is new.
+			cmds, err = f.src.ListSandboxCommands(ctx, f.sandboxID, f.tail, f.actor)
+			f.tail = 0
+		} else {
+			cmds, err = f.src.ListSandboxCommandsAfter(ctx, f.sandboxID, f.cursor, logsFollowBatch, f.actor)
+		}
+		if err != nil {
+			// Keep following through transient daemon errors.
+			fmt.Fprintf(os.Stderr, "  list sandbox commands: %v\n", err)
+			return false, nil
+		}
+		for _, c := range cmds {
+			if err := f.show(c); err != nil {
+				return true, err
+			}
+			f.cursor = c.ID
+		}
+		if f.cursor == "" || len(cmds) < logsFollowBatch {
+			return false, nil
+		}
+	}
+}
+
+// printCommandRecord renders one history entry with truncated output.
+func printCommandRecord(w io.Writer, c *sandbox.CommandRecord, useColor bool) {
+	exitColor := colorFunc(useColor, "\033[32m")
+	if c.ExitCode != 0 {
+		exitColor = colorFunc(useColor, "\033[31m")
+	}
+
+	_, _ = fmt.Fprintf(w, "  [%s] $ %s\n", c.StartedAt.Format(time.RFC3339), c.Command)
+	by := ""
+	if c.Actor != "" {
+		by = "  by " + c.Actor
+	}
+	_, _ = fmt.Fprintf(w, "    %s  ended %s  (%dms)%s\n", exitColor(fmt.Sprintf("exit %d", c.ExitCode)), c.EndedAt.Format(time.RFC3339), c.DurationMS, by)
+	if c.Stdout != "" {
+		_, _ = fmt.Fprintln(w, "    STDOUT:")
+		_, _ = fmt.Fprintln(w, indentLines(truncateOutput(c.Stdout, maxLogOutputBytes), "      "))
+	}
+	if c.Stderr != "" {
+		_, _ = fmt.Fprintln(w, "    STDERR:")
+		_, _ = fmt.Fprintln(w, indentLines(truncateOutput(c.Stderr, maxLogOutputBytes), "      "))
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+// truncateOutput trims s to at most n bytes, noting how much was dropped.
+func truncateOutput(s string, n int) string {
+	s = strings.TrimRight(s, "\n")
+	if len(s) <= n {
+		return s
+	}
+	return fmt.Sprintf("%s\n... (%d more bytes)", s[:n], len(s)-n)
+}
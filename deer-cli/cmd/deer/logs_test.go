@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// fakeCommandPager serves history oldest first, paging after an ID.
+type fakeCommandPager struct {
+	cmds  []*sandbox.CommandRecord
+	calls []string
+}
+
+func (f *fakeCommandPager) ListSandboxCommands(_ context.Context, _ string, limit int, _ string) ([]*sandbox.CommandRecord, error) {
+	f.calls = append(f.calls, fmt.Sprintf("list %d", limit))
+	if limit > 0 && len(f.cmds) > limit {
+		return f.cmds[len(f.cmds)-limit:], nil
+	}
+	return f.cmds, nil
+}
+
+func (f *fakeCommandPager) ListSandboxCommandsAfter(_ context.Context, _, afterID string, limit int, _ string) ([]*sandbox.CommandRecord, error) {
+	f.calls = append(f.calls, "after "+afterID)
+	for i, c := range f.cmds {
+		if c.ID == afterID {
+			rest := f.cmds[i+1:]
+			if len(rest) > limit {
+				rest = rest[:limit]
+			}
+			return rest, nil
+		}
+	}
+	return nil, fmt.Errorf("command %s not found", afterID)
+}
+
+func TestCommandFollower_PagesFromCursor(t *testing.T) {
+	src := &fakeCommandPager{}
+	var shown []string
+	f := &commandFollower{src: src, sandboxID: "SBX-1", tail: 5, show: func(c *sandbox.CommandRecord) error {
+		shown = append(shown, c.ID)
+		return nil
+	}}
+	ctx := context.Background()
+
+	// Empty history: nothing shown, and no cursor yet.
+	if _, err := f.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	src.cmds = append(src.cmds, &sandbox.CommandRecord{ID: "c0"})
+	if _, err := f.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+
+	// More commands than a page land between polls; none may be dropped.
+	for i := 1; i <= logsFollowBatch+10; i++ {
+		src.cmds = append(src.cmds, &sandbox.CommandRecord{ID: fmt.Sprintf("c%d", i)})
+	}
+	if _, err := f.poll(ctx); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if len(shown) != logsFollowBatch+11 || shown[len(shown)-1] != fmt.Sprintf("c%d", logsFollowBatch+10) {
+		t.Fatalf("shown %d commands ending %v, want all %d", len(shown), shown[len(shown)-1], logsFollowBatch+11)
+	}
+	want := []string{"list 5", "list 0", "after c0", "after c50"}
+	if strings.Join(src.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", src.calls, want)
+	}
+}
+
+func TestPrintCommandRecord(t *testing.T) {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	printCommandRecord(&buf, &sandbox.CommandRecord{
+		Command:    "cat big.log",
+		Stdout:     strings.Repeat("x", maxLogOutputBytes+20),
+		Stderr:     "warning: slow disk",
+		ExitCode:   3,
+		DurationMS: 42,
+		StartedAt:  started,
+		EndedAt:    started.Add(42 * time.Millisecond),
+		Actor:      sandbox.ActorTUIAgent,
+	}, false)
+
+	out := buf.String()
+	for _, want := range []string{"[2026-03-01T12:00:00Z] $ cat big.log", "exit 3", "(42ms)", "by tui-agent", "... (20 more bytes)", "warning: slow disk"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestValidateActor(t *testing.T) {
+	for _, actor := range []string{"", sandbox.ActorHumanCLI, sandbox.ActorPlaybook} {
+		if err := validateActor(actor); err != nil {
+			t.Errorf("validateActor(%q) = %v", actor, err)
+		}
+	}
+	if err := validateActor("robot"); err == nil {
+		t.Error("validateActor(\"robot\") = nil, want an error")
+	}
+}
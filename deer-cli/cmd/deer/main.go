@@ -8,11 +8,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -49,6 +52,7 @@ var (
 	cfgFile      string
 	cfg          *config.Config
 	globalPrompt string
+	noTelemetry  bool
 )
 
 func main() {
@@ -56,7 +60,11 @@ func main() {
 	tui.Version = version
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		if outputFormat == outputJSON {
+			outputError(os.Stdout, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		}
 		os.Exit(1)
 	}
 }
@@ -94,9 +102,21 @@ var mcpCmd = &cobra.Command{
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Check daemon setup on a host",
-	Long:  "Validate that the deer-daemon is properly installed and configured on a sandbox host.",
+	Long: `Validate that the deer-daemon is properly installed and configured on a sandbox host.
+
+With --source-vm, check a source VM instead: that its sshd trusts the daemon's
+current SSH CA and that the deer-readonly user, principals and restricted shell
+are in place. These are the usual causes of "Permission denied" during read-only
+access. The VM is reached with your own SSH access, through --host's hypervisor
+when given.
+
+With --json (the default when stdout is not a terminal) the results are one JSON
+document: "ok" is true when every check passed, and each check has its
+name, status, detail and, for failures, remediation. The exit status is 1 when
+any check fails, so CI can gate on either.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		hostName, _ := cmd.Flags().GetString("host")
+		sourceVM, _ := cmd.Flags().GetString("source-vm")
 
 		configPath := cfgFile
 		if configPath == "" {
@@ -113,6 +133,10 @@ var doctorCmd = &cobra.Command{
 		}
 
 		ctx := context.Background()
+		if sourceVM != "" {
+			return runSourceVMDoctor(ctx, loadedCfg, sourceVM, hostName)
+		}
+
 		var run hostexec.RunFunc
 
 		if hostName == "" || hostName == "localhost" {
@@ -140,6 +164,17 @@ var doctorCmd = &cobra.Command{
 			}
 		}
 
+		if jsonOutput {
+			allPassed, err := doctor.WriteJSON(doctor.RunAll(ctx, run), os.Stdout)
+			if err != nil {
+				return err
+			}
+			if !allPassed {
+				os.Exit(1)
+			}
+			return nil
+		}
+
 		useColor := os.Getenv("NO_COLOR") == ""
 		fmt.Println()
 		fmt.Println("  Checking daemon health...")
@@ -186,24 +221,49 @@ var sourceCmd = &cobra.Command{
 }
 
 var sourcePrepareCmd = &cobra.Command{
-	Use:   "prepare <hostname>",
+	Use:   "prepare <hostname> | --all",
 	Short: "Prepare a host for read-only access",
-	Long:  "Set up the deer-readonly user and SSH key on a remote host. Uses ssh -G to resolve connection details from ~/.ssh/config.",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Set up the deer-readonly user and SSH key on a remote host. Uses ssh -G to resolve connection details from ~/.ssh/config. --all prepares every configured source host, skipping those where read-only access already works. Preparation logs in as --user, else the host's source_vm_user, else ssh.source_vm_user, else the ~/.ssh/config user; a non-root user needs passwordless sudo, as on cloud images that log in as ubuntu or ec2-user.",
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		sshUser, _ := cmd.Flags().GetString("user")
+		if all {
+			if len(args) > 0 {
+				return fmt.Errorf("pass a hostname or --all, not both")
+			}
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			return runSourcePrepareAll(concurrency, sshUser)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("a hostname is required unless --all is set")
+		}
 		hostname := args[0]
-		return runSourcePrepare(hostname)
+		return runSourcePrepare(hostname, sshUser)
 	},
 }
 
 var sourceListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List configured source hosts",
+	Long:  "List configured source hosts with when each was last prepared and whether it trusts the current source key. --stale lists instead the prepared hosts that trust a different source key than the current one, such as after the key was rotated, and need 'deer source prepare' again.",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if stale, _ := cmd.Flags().GetBool("stale"); stale {
+			return runSourceListStale()
+		}
 		return runSourceList()
 	},
 }
 
+var sourceStatusCmd = &cobra.Command{
+	Use:   "status <name>",
+	Short: "Show the recorded preparation of a source host",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSourceStatus(args[0])
+	},
+}
+
 var sourceRunCmd = &cobra.Command{
 	Use:   "run <host> <command>",
 	Short: "Run a read-only command on a source host",
@@ -225,6 +285,17 @@ var sourceReadFileCmd = &cobra.Command{
 	},
 }
 
+var sourceValidateCmd = &cobra.Command{
+	Use:   "validate <vm-name>",
+	Short: "Check that a source VM is ready to clone and read",
+	Long:  "Ask the daemon to validate a source VM. --explain adds, for each finding, its cause, the check that found it, and commands that usually fix it.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		explain, _ := cmd.Flags().GetBool("explain")
+		return runSourceValidate(args[0], explain)
+	},
+}
+
 // --- connect command ---
 
 var connectCmd = &cobra.Command{
@@ -254,22 +325,39 @@ var sandboxListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all sandboxes",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSandboxList()
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		until, _ := cmd.Flags().GetString("until")
+		if until != "" {
+			watch = true
+		}
+		return runSandboxList(watch, interval, until)
 	},
 }
 
 var sandboxCreateCmd = &cobra.Command{
-	Use:   "create <source_vm>",
+	Use:   "create [source_vm]",
 	Short: "Create a new sandbox VM",
-	Args:  cobra.ExactArgs(1),
+	Long:  "Create a new sandbox VM. With --template, unset parameters come from the named preset (see 'deer template'); flags given explicitly override it.",
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		sourceVM := args[0]
-		cpu, _ := cmd.Flags().GetInt("cpu")
-		memoryMB, _ := cmd.Flags().GetInt("memory")
-		live, _ := cmd.Flags().GetBool("live")
-		kafkaStub, _ := cmd.Flags().GetBool("kafka-stub")
-		esStub, _ := cmd.Flags().GetBool("es-stub")
-		return runSandboxCreate(sourceVM, cpu, memoryMB, live, kafkaStub, esStub)
+		var req sandbox.CreateRequest
+		if len(args) > 0 {
+			req.SourceVM = args[0]
+		}
+		req.VCPUs, _ = cmd.Flags().GetInt("cpu")
+		req.MemoryMB, _ = cmd.Flags().GetInt("memory")
+		req.Live, _ = cmd.Flags().GetBool("live")
+		req.SimpleKafkaBroker, _ = cmd.Flags().GetBool("kafka-stub")
+		req.SimpleElasticsearchBroker, _ = cmd.Flags().GetBool("es-stub")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		var err error
+		if req.TTLSeconds, err = ttlSeconds(ttl); err != nil {
+			return err
+		}
+		templateName, _ := cmd.Flags().GetString("template")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		return runSandboxCreate(req, templateName, dryRun, cmd.Flags().Changed)
 	},
 }
 
@@ -278,7 +366,163 @@ var sandboxDestroyCmd = &cobra.Command{
 	Short: "Destroy a sandbox VM",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSandboxDestroy(args[0])
+		yes, _ := cmd.Flags().GetBool("yes")
+		archive, _ := cmd.Flags().GetBool("archive")
+		return runSandboxDestroy(args[0], yes, archive)
+	},
+}
+
+var importVMCmd = &cobra.Command{
+	Use:   "import-vm <vm-name>",
+	Short: "Register an existing VM as a deer-managed sandbox",
+	Long: `Register a VM that was created outside deer as a sandbox, without cloning it.
+Once imported, the VM can be started, stopped, and run commands against like
+any other sandbox, and those operations are audited. Destroying an imported
+sandbox deletes the underlying VM and asks for extra confirmation; the
+daemon refuses it from agents and other clients that do not confirm.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		return runImportVM(args[0], name)
+	},
+}
+
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "List provider resources that no sandbox owns",
+	Long: `List resources left behind on the daemon's host by creates or destroys that
+failed part-way, such as Proxmox CTs in the sandbox VMID range that no
+sandbox owns, along with how much of the VMID range is still free.
+
+With --reclaim, the orphans are listed and, once confirmed (or with --yes),
+each one is stopped and deleted, freeing its VMID. The daemon checks each
+one is still an orphan before deleting it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reclaim, _ := cmd.Flags().GetBool("reclaim")
+		yes, _ := cmd.Flags().GetBool("yes")
+		return runOrphans(reclaim, yes)
+	},
+}
+
+var ipCmd = &cobra.Command{
+	Use:   "ip [sandbox_id]",
+	Short: "Discover and store sandbox IP addresses",
+	Long: `Ask the daemon to rediscover a sandbox's IP address and store it.
+
+With --all, every running sandbox is refreshed concurrently, which is useful
+after a host or network restart hands out new DHCP leases. An IP already held
+by another sandbox is reported as a conflict and not stored.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		all, _ := cmd.Flags().GetBool("all")
+		var id string
+		if len(args) == 1 {
+			id = args[0]
+		}
+		return runIP(id, all)
+	},
+}
+
+var sshRenewCmd = &cobra.Command{
+	Use:   "ssh-renew <sandbox_id>",
+	Short: "Reissue a sandbox's SSH key and certificate",
+	Long: `Ask the daemon to generate a new SSH key and certificate for a sandbox,
+replacing the cached ones. Commands renew an expired certificate on their own;
+use this to rotate the key by hand or before a long interactive session.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSSHRenew(args[0])
+	},
+}
+
+var forkCmd = &cobra.Command{
+	Use:   "fork <sandbox_id> --snapshot <name>",
+	Short: "Create a new sandbox from a snapshot of an existing one",
+	Long: `Clone a sandbox as it was at one of its snapshots into a new sandbox, for
+trying a different branch of changes without touching the original. The fork
+gets its own ID and inherits the parent's CPU and memory unless overridden.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := sandbox.ForkRequest{SandboxID: args[0]}
+		req.Snapshot, _ = cmd.Flags().GetString("snapshot")
+		req.Name, _ = cmd.Flags().GetString("name")
+		req.VCPUs, _ = cmd.Flags().GetInt("cpu")
+		req.MemoryMB, _ = cmd.Flags().GetInt("memory")
+		return runFork(req)
+	},
+}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <sandbox_id>",
+	Short: "Re-run a sandbox's recorded commands in a fresh sandbox",
+	Long: `Create a new sandbox from the same base image as an existing one and run the
+commands recorded for it, in order, reporting every command whose exit code
+differs from the original run. The new sandbox is left running for inspection.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		stop, _ := cmd.Flags().GetBool("stop-on-divergence")
+		return runReplay(args[0], name, stop)
+	},
+}
+
+var resizeCmd = &cobra.Command{
+	Use:   "resize <sandbox_id> [--cpu N] [--memory MB]",
+	Short: "Change a sandbox's vCPUs and memory",
+	Long: `Change the vCPUs and memory of an existing sandbox instead of recreating it.
+A running sandbox is stopped for the change and started again afterwards;
+--no-restart leaves it stopped. Memory cannot go below 256 MB.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cpu, _ := cmd.Flags().GetInt("cpu")
+		memory, _ := cmd.Flags().GetInt("memory")
+		noRestart, _ := cmd.Flags().GetBool("no-restart")
+		return runResize(args[0], cpu, memory, noRestart)
+	},
+}
+
+var cdCmd = &cobra.Command{
+	Use:   "cd <sandbox_id> [path]",
+	Short: "Set the directory a sandbox's commands run in",
+	Long: `Set the sandbox's default working directory, so commands run from it
+without a 'cd /path &&' prefix. The directory must exist in the sandbox and
+the path must be absolute. Without a path, commands go back to running from
+the login directory. 'deer sandbox run --workdir' overrides it per command.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var dir string
+		if len(args) == 2 {
+			dir = args[1]
+		}
+		return runCd(args[0], dir)
+	},
+}
+
+var hostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "List the configured sandbox hosts",
+	Long: `List the sandbox hosts from the config. With --check, each host's daemon is
+queried concurrently and the output shows whether it is reachable, its version
+and provider, and the round-trip latency, so a down host can be spotted before
+creating a sandbox on it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, _ := cmd.Flags().GetBool("check")
+		return runHosts(check)
+	},
+}
+
+var vmsCmd = &cobra.Command{
+	Use:   "vms",
+	Short: "List the source VMs on the sandbox hosts",
+	Long: `List the VMs sandboxes can be cloned from. The listing is cached locally and
+served from the cache while it is younger than vm_cache_ttl (default 5m);
+--refresh re-lists the VMs from the hosts and updates the cache.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		return runVMs(refresh)
 	},
 }
 
@@ -300,29 +544,74 @@ var sandboxStopCmd = &cobra.Command{
 	},
 }
 
+var sandboxUnlockCmd = &cobra.Command{
+	Use:   "unlock <sandbox_id>",
+	Short: "Clear a sandbox's lock left by a client that died",
+	Long: `Clear the advisory lock that start, stop, destroy and resize take on a
+sandbox, whoever holds it. Use it when the holder died mid-operation and
+you do not want to wait for the lock to expire.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSandboxUnlock(args[0])
+	},
+}
+
 var sandboxGetCmd = &cobra.Command{
 	Use:   "get <sandbox_id>",
 	Short: "Get sandbox details",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSandboxGet(args[0])
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		until, _ := cmd.Flags().GetString("until")
+		if until != "" {
+			watch = true
+		}
+		activity, _ := cmd.Flags().GetBool("activity")
+		return runSandboxGet(args[0], watch, interval, until, activity)
 	},
 }
 
 var sandboxRunCmd = &cobra.Command{
 	Use:   "run <sandbox_id> <command>",
 	Short: "Run a command in a sandbox",
-	Args:  cobra.MinimumNArgs(2),
+	Long: `Run a command in a sandbox and print its output.
+
+With --interactive the local terminal is attached to the command over
+'ssh -t' instead, for programs like vim, top, or a shell; the command may be
+omitted to open a login shell. Interactive sessions are audited by the daemon
+but not recorded in command history.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sandboxID := args[0]
 		command := strings.Join(args[1:], " ")
+		if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+			return runSandboxInteractiveCmd(sandboxID, command)
+		}
+		if command == "" {
+			return fmt.Errorf("a command is required unless --interactive is set")
+		}
 		timeoutSec, _ := cmd.Flags().GetInt("timeout")
-		return runSandboxRun(sandboxID, command, timeoutSec)
+		forwardAgent, _ := cmd.Flags().GetBool("forward-agent")
+		identities, _ := cmd.Flags().GetStringArray("identity")
+		envFrom, _ := cmd.Flags().GetString("env-from-sandbox")
+		workdir, _ := cmd.Flags().GetString("workdir")
+		return runSandboxRun(sandboxID, command, sandbox.RunOptions{
+			TimeoutSec:    timeoutSec,
+			ForwardAgent:  forwardAgent,
+			IdentityFiles: identities,
+			Workdir:       workdir,
+		}, envFrom)
 	},
 }
 
 var sandboxSnapshotCmd = &cobra.Command{
-	Use:   "snapshot <sandbox_id> [name]",
+	Use:   "snapshot",
+	Short: "Create, list, and delete sandbox snapshots",
+}
+
+var sandboxSnapshotCreateCmd = &cobra.Command{
+	Use:   "create <sandbox_id> [name]",
 	Short: "Create a snapshot of a sandbox",
 	Args:  cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -335,6 +624,74 @@ var sandboxSnapshotCmd = &cobra.Command{
 	},
 }
 
+var sandboxSnapshotListCmd = &cobra.Command{
+	Use:   "list <sandbox_id>",
+	Short: "List a sandbox's snapshots and archives",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSnapshotList(args[0])
+	},
+}
+
+var sandboxSnapshotDeleteCmd = &cobra.Command{
+	Use:   "delete <sandbox_id> <snapshot>",
+	Short: "Delete a snapshot or archive by name or ID",
+	Long: `Delete a snapshot from the sandbox host and from the daemon's records. For
+an archive the archived disk file is removed. A snapshot that exists only on
+the host, or only in the records, is still cleaned up.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSnapshotDelete(args[0], args[1])
+	},
+}
+
+var sandboxRestoreFileCmd = &cobra.Command{
+	Use:   "restore-file <sandbox_id> --snapshot <name> --path <file>",
+	Short: "Recover one file from a snapshot without reverting the sandbox",
+	Long: `Read a single file as it was in a snapshot and write it locally (--out,
+default: the file's name in the current directory; "-" for stdout), or copy
+it back into the running sandbox with --in-place. The daemon mounts the
+snapshot read-only on the host and unmounts it afterwards.
+
+Supported for LXC sandboxes on ZFS or LVM-thin storage.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var opts restoreFileOptions
+		opts.Snapshot, _ = cmd.Flags().GetString("snapshot")
+		opts.Path, _ = cmd.Flags().GetString("path")
+		opts.Out, _ = cmd.Flags().GetString("out")
+		opts.InPlace, _ = cmd.Flags().GetBool("in-place")
+		opts.Force, _ = cmd.Flags().GetBool("force")
+		return runSandboxRestoreFile(args[0], opts)
+	},
+}
+
+var sandboxDiffCmd = &cobra.Command{
+	Use:   "diff <sandbox_id> <from_snapshot> [to_snapshot]",
+	Short: "Show files changed between snapshots",
+	Long: `Show files added, modified, and removed between two snapshots of a sandbox,
+and the commands run in between. Without to_snapshot the sandbox's current
+filesystem is compared. Snapshots are named by ID or name.
+
+Volatile paths (/proc, /sys, /tmp, /var/log, ...) are skipped; set
+snapshot.diff_ignore in the daemon config to change the list.
+
+With --format unified, modified files are also shown as unified diffs of
+their contents. Binary files, files over --max-file-size, and files past
+--max-files are listed but not diffed.`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := diffOptions{From: args[1]}
+		if len(args) > 2 {
+			opts.To = args[2]
+		}
+		opts.Format, _ = cmd.Flags().GetString("format")
+		opts.MaxFiles, _ = cmd.Flags().GetInt("max-files")
+		opts.MaxFileBytes, _ = cmd.Flags().GetInt("max-file-size")
+		return runSandboxDiff(args[0], opts)
+	},
+}
+
 // --- playbook commands ---
 
 var playbookCmd = &cobra.Command{
@@ -393,6 +750,59 @@ var playbookCreateCmd = &cobra.Command{
 	},
 }
 
+var playbookFromSandboxCmd = &cobra.Command{
+	Use:   "from-sandbox <sandbox_id> --name <name>",
+	Short: "Create a playbook from the commands run in a sandbox",
+	Long: `Create a playbook that reproduces the commands recorded for a sandbox.
+Failed and read-only commands are left out. Package installs, service
+changes, and simple file operations become the matching Ansible modules;
+other commands run through the command or shell module. Review the
+playbook before running it elsewhere.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := ansible.CreatePlaybookRequest{}
+		req.Name, _ = cmd.Flags().GetString("name")
+		req.Hosts, _ = cmd.Flags().GetString("hosts")
+		req.Become, _ = cmd.Flags().GetBool("become")
+		if req.Name == "" {
+			return fmt.Errorf("--name is required")
+		}
+		return runPlaybookFromSandbox(args[0], req)
+	},
+}
+
+var playbookImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import an existing Ansible playbook",
+	Long: `Import a hand-written playbook so it can be managed like one built
+with add-task. The file must hold a single play of plain module tasks;
+roles, blocks, handlers, and task keywords such as when or loop are
+rejected with the line they appear on. The playbook takes the play's
+name unless --name is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		return runPlaybookImport(args[0], name)
+	},
+}
+
+var playbookRunCmd = &cobra.Command{
+	Use:   "run <playbook_id> <sandbox_id>",
+	Short: "Run a playbook in a sandbox",
+	Long: `Upload a playbook to a sandbox and run it there with ansible-playbook,
+streaming its output, then summarize each task and the PLAY RECAP.
+ansible-core must be installed in the sandbox.
+
+Playbook tasks can reach the network, so the run is confirmed first;
+pass --yes to skip the question.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		timeout, _ := cmd.Flags().GetInt("timeout")
+		yes, _ := cmd.Flags().GetBool("yes")
+		return runPlaybookRun(args[0], args[1], timeout, yes)
+	},
+}
+
 var playbookGetCmd = &cobra.Command{
 	Use:   "get <playbook_id>",
 	Short: "Get playbook details",
@@ -415,6 +825,23 @@ var playbookAddTaskCmd = &cobra.Command{
 	},
 }
 
+// --- daemon commands ---
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Query a connected deer-daemon",
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a daemon's self-reported status",
+	Long:  "Query a running deer-daemon for its version, host ID, provider, uptime, active sandbox count, janitor activity, and recent errors.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hostName, _ := cmd.Flags().GetString("host")
+		return runDaemonStatus(hostName)
+	},
+}
+
 // --- audit commands ---
 
 var auditCmd = &cobra.Command{
@@ -476,14 +903,22 @@ var auditShowCmd = &cobra.Command{
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default $XDG_CONFIG_HOME/deer/config.yaml)")
 	rootCmd.PersistentFlags().StringVarP(&globalPrompt, "prompt", "p", "", "run agent non-interactively with prompt and print session JSON to stdout")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print JSON instead of human-readable output (default: on when stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&noTelemetry, "no-telemetry", false, "never send anonymous usage telemetry, whatever the config says (also set by DO_NOT_TRACK=1)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "output format: json, yaml, table, or wide (--json is short for --output json)")
 	rootCmd.Flags().BoolP("version", "v", false, "print version")
+	rootCmd.Flags().BoolVar(&requireApproval, "require-approval", false, "ask before every command the agent runs in a sandbox, not just network access (prompts on stdin with --prompt)")
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := applyOutputFormat(cmd, stdoutIsTerminal()); err != nil {
+			return err
+		}
 		if err := paths.MaybeMigrate(); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: migration failed: %v\n", err)
 		}
 		return nil
 	}
 	doctorCmd.Flags().String("host", "", "host name from config (default: localhost)")
+	doctorCmd.Flags().String("source-vm", "", "check a source VM's read-only SSH setup instead of the daemon")
 
 	connectCmd.Flags().String("name", "", "display name for this daemon (default: hostname from daemon)")
 	connectCmd.Flags().Bool("insecure", false, "skip TLS verification (INSECURE: use only for local/dev daemons)")
@@ -492,10 +927,19 @@ func init() {
 
 	sourceCmd.AddCommand(sourcePrepareCmd)
 	sourceCmd.AddCommand(sourceListCmd)
+	sourceListCmd.Flags().Bool("stale", false, "List prepared hosts whose recorded key no longer matches the current source key")
+	sourceCmd.AddCommand(sourceStatusCmd)
 	sourceCmd.AddCommand(sourceRunCmd)
 	sourceCmd.AddCommand(sourceReadFileCmd)
+	sourceCmd.AddCommand(sourceValidateCmd)
 
+	sourcePrepareCmd.Flags().Bool("all", false, "Prepare every configured source host")
+	sourcePrepareCmd.Flags().Int("concurrency", defaultPrepareConcurrency, "Hosts to prepare at once with --all")
+	sourcePrepareCmd.Flags().String("user", "", "Admin user to log in as for preparation (default: source_vm_user from config, then ~/.ssh/config)")
 	sourceRunCmd.Flags().Int("timeout", 0, "Command timeout in seconds")
+	sourceValidateCmd.Flags().Bool("explain", false, "Explain each finding with its cause, the check performed, and a fix")
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonStatusCmd.Flags().String("host", "", "sandbox host name or daemon address from config (default: first configured host)")
 	auditCmd.AddCommand(auditVerifyCmd)
 	auditCmd.AddCommand(auditShowCmd)
 
@@ -504,24 +948,98 @@ func init() {
 	sandboxCmd.AddCommand(sandboxDestroyCmd)
 	sandboxCmd.AddCommand(sandboxStartCmd)
 	sandboxCmd.AddCommand(sandboxStopCmd)
+	sandboxCmd.AddCommand(sandboxUnlockCmd)
 	sandboxCmd.AddCommand(sandboxGetCmd)
 	sandboxCmd.AddCommand(sandboxRunCmd)
 	sandboxCmd.AddCommand(sandboxSnapshotCmd)
+	sandboxSnapshotCmd.AddCommand(sandboxSnapshotCreateCmd)
+	sandboxSnapshotCmd.AddCommand(sandboxSnapshotListCmd)
+	sandboxSnapshotCmd.AddCommand(sandboxSnapshotDeleteCmd)
+	sandboxCmd.AddCommand(sandboxDiffCmd)
+	sandboxDiffCmd.Flags().String("format", "files", "Diff format: files (names only) or unified (patches of modified files)")
+	sandboxDiffCmd.Flags().Int("max-files", sandbox.DefaultDiffMaxFiles, "With --format unified, the most modified files to diff")
+	sandboxDiffCmd.Flags().Int("max-file-size", sandbox.DefaultDiffMaxFileBytes, "With --format unified, skip files larger than this many bytes")
+	sandboxCmd.AddCommand(sandboxRestoreFileCmd)
+
+	sandboxRestoreFileCmd.Flags().String("snapshot", "", "Snapshot name or ID to read from (required)")
+	sandboxRestoreFileCmd.Flags().String("path", "", "Absolute path of the file in the sandbox (required)")
+	sandboxRestoreFileCmd.Flags().String("out", "", "Local file to write (default: the file's name; - for stdout)")
+	sandboxRestoreFileCmd.Flags().Bool("in-place", false, "Write the file back into the running sandbox instead")
+	sandboxRestoreFileCmd.Flags().Bool("force", false, "Overwrite an existing local file")
+
+	orphansCmd.Flags().Bool("reclaim", false, "Delete the orphaned resources")
+	orphansCmd.Flags().BoolP("yes", "y", false, "Reclaim without asking for confirmation")
+	ipCmd.Flags().Bool("all", false, "Refresh the IPs of all running sandboxes")
+	forkCmd.Flags().String("snapshot", "", "Snapshot name or ID to fork from (required)")
+	forkCmd.Flags().String("name", "", "Name for the new sandbox")
+	forkCmd.Flags().Int("cpu", 0, "Number of vCPUs (default: the parent's)")
+	forkCmd.Flags().Int("memory", 0, "RAM in MB (default: the parent's)")
+	replayCmd.Flags().String("name", "", "Name for the new sandbox")
+	replayCmd.Flags().Bool("stop-on-divergence", false, "Stop at the first command whose exit code differs from the original")
+	resizeCmd.Flags().Int("cpu", 0, "Number of vCPUs (default: unchanged)")
+	resizeCmd.Flags().Int("memory", 0, "RAM in MB (default: unchanged)")
+	resizeCmd.Flags().Bool("no-restart", false, "Leave a running sandbox stopped after resizing")
+	vmsCmd.Flags().Bool("refresh", false, "Re-list the VMs from the hosts instead of using the cache")
+	hostsCmd.Flags().Bool("check", false, "Query each host's daemon for reachability, version, and latency")
 
 	sandboxCreateCmd.Flags().Int("cpu", 0, "Number of vCPUs")
 	sandboxCreateCmd.Flags().Int("memory", 0, "RAM in MB")
 	sandboxCreateCmd.Flags().Bool("live", false, "Clone from live state instead of cached image")
 	sandboxCreateCmd.Flags().Bool("kafka-stub", false, "Start local Redpanda Kafka broker at localhost:9092 inside the sandbox")
 	sandboxCreateCmd.Flags().Bool("es-stub", false, "Start local single-node Elasticsearch at localhost:9200 inside the sandbox")
+	sandboxCreateCmd.Flags().String("template", "", "Create from a saved template; explicit flags override it")
+	sandboxCreateCmd.Flags().Bool("dry-run", false, "Show which host, ID, and resources the sandbox would get without creating it")
+	sandboxCreateCmd.Flags().Duration("ttl", 0, "Destroy the sandbox automatically after this long (e.g. 2h); 0 uses the daemon's default")
+
+	agentCmd.AddCommand(agentToolsCmd)
+	agentToolsCmd.Flags().Bool("read-only", false, "Only list the tools available in read-only mode")
+	agentCmd.AddCommand(agentReplayCmd)
+	agentReplayCmd.Flags().String("against", "", "Source VM to create the replay sandbox from (required)")
+	templateCmd.AddCommand(templateCreateCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateDeleteCmd)
+	templateCreateCmd.Flags().String("source-vm", "", "Source VM to clone (required)")
+	templateCreateCmd.Flags().Int("cpu", 0, "Number of vCPUs")
+	templateCreateCmd.Flags().Int("memory", 0, "RAM in MB")
+	templateCreateCmd.Flags().Bool("live", false, "Clone from live state instead of cached image")
+	templateCreateCmd.Flags().Bool("kafka-stub", false, "Start local Redpanda Kafka broker inside the sandbox")
+	templateCreateCmd.Flags().Bool("es-stub", false, "Start local single-node Elasticsearch inside the sandbox")
 	sandboxRunCmd.Flags().Int("timeout", 0, "Command timeout in seconds")
+	sandboxRunCmd.Flags().BoolVar(&requireApproval, "require-approval", false, "show the command and sandbox and ask for confirmation before running it")
+	sandboxRunCmd.Flags().Bool("forward-agent", false, "forward the daemon host's SSH agent into the sandbox (requires ssh.allow_agent_forwarding on the daemon)")
+	sandboxRunCmd.Flags().StringArrayP("identity", "i", nil, "additional SSH identity file on the daemon host (repeatable; must be in ssh.allowed_identity_files)")
+	sandboxRunCmd.Flags().BoolP("interactive", "t", false, "attach the terminal to the command over ssh -t (opens a shell when no command is given)")
+	sandboxRunCmd.Flags().StringP("workdir", "C", "", "absolute directory in the sandbox to run the command in (default: the sandbox's workdir, see 'deer cd')")
+	sandboxRunCmd.Flags().String("env-from-sandbox", "", "run with the environment of a process in the sandbox: a pid (1234 or pid:1234) or a systemd service name")
+	sandboxListCmd.Flags().BoolP("watch", "w", false, "re-render on an interval, highlighting state changes (Ctrl+C to exit)")
+	sandboxListCmd.Flags().Duration("interval", defaultWatchInterval, "refresh interval for --watch")
+	sandboxListCmd.Flags().String("until", "", "with --watch, exit once every sandbox reaches this state (e.g. running)")
+	sandboxGetCmd.Flags().BoolP("watch", "w", false, "re-render on an interval, highlighting changes (Ctrl+C to exit)")
+	statsCmd.Flags().BoolP("watch", "w", false, "refresh on an interval, showing CPU use over each interval (Ctrl+C to exit)")
+	statsCmd.Flags().Duration("interval", defaultWatchInterval, "refresh interval for --watch")
+	sandboxGetCmd.Flags().Duration("interval", defaultWatchInterval, "refresh interval for --watch")
+	sandboxGetCmd.Flags().String("until", "", "with --watch, exit once the sandbox reaches this state (e.g. running)")
+	sandboxGetCmd.Flags().Bool("activity", false, "also show command activity: last command, count, failure rate, total run time")
+	sandboxDestroyCmd.Flags().BoolP("yes", "y", false, "skip the confirmation prompt for imported sandboxes")
+	sandboxDestroyCmd.Flags().Bool("archive", false, "export the sandbox's disk to the daemon's archive dir before destroying it")
+	importVMCmd.Flags().String("name", "", "display name for the sandbox (default: VM name)")
 
 	playbookCmd.AddCommand(playbookListCmd)
 	playbookCmd.AddCommand(playbookCreateCmd)
+	playbookCmd.AddCommand(playbookFromSandboxCmd)
+	playbookCmd.AddCommand(playbookImportCmd)
+	playbookCmd.AddCommand(playbookRunCmd)
 	playbookCmd.AddCommand(playbookGetCmd)
 	playbookCmd.AddCommand(playbookAddTaskCmd)
 
 	playbookCreateCmd.Flags().String("hosts", "", "Target hosts (default: 'all')")
 	playbookCreateCmd.Flags().Bool("become", false, "Use privilege escalation (sudo)")
+	playbookFromSandboxCmd.Flags().String("name", "", "Name for the new playbook (required)")
+	playbookFromSandboxCmd.Flags().String("hosts", "", "Target hosts (default: 'all')")
+	playbookFromSandboxCmd.Flags().Bool("become", false, "Use privilege escalation even if no command ran under sudo")
+	playbookImportCmd.Flags().String("name", "", "Name for the imported playbook (default: the play's name)")
+	playbookRunCmd.Flags().Int("timeout", 0, "ansible-playbook timeout in seconds")
+	playbookRunCmd.Flags().BoolP("yes", "y", false, "Run without asking for confirmation")
 	playbookAddTaskCmd.Flags().String("params", "", "Task parameters as JSON")
 
 	fileCmd.AddCommand(fileReadCmd)
@@ -531,6 +1049,15 @@ func init() {
 	skillsCmd.AddCommand(skillsInstallCmd)
 	skillsCmd.AddCommand(skillsRemoveCmd)
 
+	logsCmd.Flags().IntP("tail", "n", 0, "show only the last N commands (0 for all), or N daemon log lines with --daemon (default 100)")
+	logsCmd.Flags().BoolP("follow", "f", false, "keep printing new commands or log lines as they arrive (Ctrl+C to exit)")
+	logsCmd.Flags().String("actor", "", "only show commands run by this actor: human-cli, tui-agent, mcp-client, or playbook")
+	logsCmd.Flags().Bool("daemon", false, "show the deer-daemon's own log instead of a sandbox's commands")
+	logsCmd.Flags().String("host", "", "with --daemon: host name from config (default: localhost)")
+	logsCmd.Flags().String("since", "", "with --daemon: only show lines newer than a duration (e.g. 30m) or RFC3339 time")
+	logsCmd.Flags().String("level", "", "with --daemon: minimum level to show (debug, info, warn, error)")
+	logsCmd.Flags().String("log-file", "", "with --daemon: read this log file instead of the systemd journal")
+
 	fileEditCmd.Flags().String("old", "", "String to find and replace")
 	fileEditCmd.Flags().String("new", "", "Replacement string (required)")
 	fileEditCmd.Flags().Bool("replace-all", false, "Replace all occurrences")
@@ -540,11 +1067,26 @@ func init() {
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(connectCmd)
 	rootCmd.AddCommand(sourceCmd)
+	rootCmd.AddCommand(daemonCmd)
 	rootCmd.AddCommand(auditCmd)
 	rootCmd.AddCommand(sandboxCmd)
+	rootCmd.AddCommand(importVMCmd)
+	rootCmd.AddCommand(orphansCmd)
+	rootCmd.AddCommand(ipCmd)
+	rootCmd.AddCommand(sshRenewCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(forkCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(resizeCmd)
+	rootCmd.AddCommand(cdCmd)
+	rootCmd.AddCommand(vmsCmd)
+	rootCmd.AddCommand(hostsCmd)
+	rootCmd.AddCommand(logsCmd)
+	rootCmd.AddCommand(templateCmd)
 	rootCmd.AddCommand(playbookCmd)
 	rootCmd.AddCommand(fileCmd)
 	rootCmd.AddCommand(skillsCmd)
+	rootCmd.AddCommand(agentCmd)
 }
 
 // colorFunc returns an ANSI color wrapper when useColor is true.
@@ -566,7 +1108,7 @@ func resolveConfigPath() (string, error) {
 }
 
 // runSourcePrepare prepares a host for read-only deer access.
-func runSourcePrepare(hostname string) error {
+func runSourcePrepare(hostname, sshUser string) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
 		return fmt.Errorf("determine config path: %w", err)
@@ -605,6 +1147,10 @@ func runSourcePrepare(hostname string) error {
 	if err != nil {
 		return fmt.Errorf("resolve SSH config for %s: %w", hostname, err)
 	}
+	user := prepareUser(loadedCfg, hostname, sshUser)
+	if user != "" {
+		resolved.User = user
+	}
 	fmt.Printf("  %s Resolved: %s@%s:%d\n", green("[ok]"), resolved.User, resolved.Hostname, resolved.Port)
 
 	// 2. Generate dedicated key pair
@@ -617,8 +1163,7 @@ func runSourcePrepare(hostname string) error {
 
 	// 3. SSH to host using the original alias so ~/.ssh/config is fully applied
 	fmt.Printf("  Preparing %s for read-only access...\n", hostname)
-	sshRunFn := hostexec.NewSSHAlias(hostname)
-	sshRun := readonly.SSHRunFunc(sshRunFn)
+	sshRun := prepareSSHRun(hostname, user)
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	progress := func(p readonly.PrepareProgress) {
@@ -632,7 +1177,7 @@ func runSourcePrepare(hostname string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	_, err = readonly.PrepareWithKey(ctx, sshRun, pubKey, progress, logger)
+	result, err := readonly.PrepareWithKey(ctx, sshRun, pubKey, progress, logger)
 	if err != nil {
 		fmt.Printf("  %s Preparation failed: %v\n", red("[error]"), err)
 		return err
@@ -642,6 +1187,9 @@ func runSourcePrepare(hostname string) error {
 	if err := source.SavePreparedHost(loadedCfg, configPath, hostname, resolved); err != nil {
 		return fmt.Errorf("saving config after prepare: %w", err)
 	}
+	if err := recordPreparedHost(loadedCfg, hostname, pubKey, result); err != nil {
+		fmt.Printf("  %s Could not record the preparation in the store: %v\n", red("[warning]"), err)
+	}
 
 	// 5. Deploy daemon identity key if available
 	identityPubKey := config.DaemonIdentityPubKey(loadedCfg.SandboxHosts)
@@ -743,11 +1291,11 @@ func runConnect(addr, name string, insecure, skipSave bool, sshUser string) erro
 		doctorResults := make([]doctor.CheckResult, len(checkResults))
 		for i, r := range checkResults {
 			doctorResults[i] = doctor.CheckResult{
-				Name:     r.Name,
-				Category: r.Category,
-				Passed:   r.Passed,
-				Message:  r.Message,
-				FixCmd:   r.FixCmd,
+				Name:        r.Name,
+				Category:    r.Category,
+				Passed:      r.Passed,
+				Message:     r.Message,
+				Remediation: r.FixCmd,
 			}
 		}
 		doctor.PrintResults(doctorResults, os.Stdout, useColor)
@@ -811,7 +1359,8 @@ func runConnect(addr, name string, insecure, skipSave bool, sshUser string) erro
 }
 
 // runSourceList lists configured source hosts.
-func runSourceList() error {
+// runDaemonStatus queries a daemon's GetStatus RPC and prints the result.
+func runDaemonStatus(hostName string) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
 		return fmt.Errorf("determine config path: %w", err)
@@ -822,25 +1371,73 @@ func runSourceList() error {
 		return fmt.Errorf("load config: %w", err)
 	}
 
-	if len(loadedCfg.Hosts) == 0 {
-		fmt.Println("  No source hosts configured.")
-		fmt.Println("  Run: deer source prepare <hostname>")
+	telemetryEnabled, telemetryReason := telemetry.State(loadedCfg.Telemetry, noTelemetry)
+	telemetryLine := "off (" + telemetryReason + ")"
+	if telemetryEnabled {
+		telemetryLine = "on (" + telemetryReason + ")"
+	}
+
+	if !loadedCfg.HasSandboxHosts() {
+		fmt.Println("  No sandbox hosts configured.")
+		fmt.Println("  Run: deer connect <address>")
+		fmt.Printf("  CLI telemetry: %s\n", telemetryLine)
 		return nil
 	}
+	sh, ok := loadedCfg.SandboxHost(hostName)
+	if !ok {
+		return fmt.Errorf("sandbox host %q not found in config", hostName)
+	}
+
+	useColor := os.Getenv("NO_COLOR") == ""
+	green := colorFunc(useColor, "\033[32m")
+	red := colorFunc(useColor, "\033[31m")
+	dim := colorFunc(useColor, "\033[90m")
+
+	svc, err := sandbox.NewRemoteService(sh.DaemonAddress, config.ControlPlaneConfig{
+		DaemonAddress:  sh.DaemonAddress,
+		DaemonInsecure: sh.Insecure,
+		DaemonCAFile:   sh.CAFile,
+	})
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", sh.DaemonAddress, err)
+	}
+	defer func() {
+		_ = svc.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	st, err := svc.GetStatus(ctx)
+	if err != nil {
+		fmt.Printf("\n  %s %s (%s): %v\n\n", red("[error]"), sh.Name, sh.DaemonAddress, err)
+		return fmt.Errorf("get daemon status: %w", err)
+	}
+
+	janitorRun := st.JanitorLastRun
+	if janitorRun == "" {
+		janitorRun = "never"
+	}
 
 	fmt.Println()
-	fmt.Printf("  %-20s %-25s %-10s\n", "NAME", "ADDRESS", "STATUS")
-	fmt.Printf("  %-20s %-25s %-10s\n", strings.Repeat("-", 20), strings.Repeat("-", 25), strings.Repeat("-", 10))
-	for _, h := range loadedCfg.Hosts {
-		status := "not ready"
-		if h.Prepared {
-			status = "ready"
-		}
-		addr := h.Address
-		if h.SSHPort != 0 && h.SSHPort != 22 {
-			addr = fmt.Sprintf("%s:%d", h.Address, h.SSHPort)
-		}
-		fmt.Printf("  %-20s %-25s %-10s\n", h.Name, addr, status)
+	fmt.Printf("  %s %s (%s)\n\n", green("[ok]"), sh.Name, sh.DaemonAddress)
+	fmt.Printf("  Host ID:     %s\n", st.HostID)
+	fmt.Printf("  Hostname:    %s\n", st.Hostname)
+	fmt.Printf("  Version:     %s\n", st.Version)
+	fmt.Printf("  Provider:    %s\n", st.Provider)
+	fmt.Printf("  Uptime:      %s\n", (time.Duration(st.UptimeSeconds) * time.Second).String())
+	fmt.Printf("  Sandboxes:   %d active\n", st.ActiveSandboxes)
+	fmt.Printf("  Janitor:     last run %s\n", janitorRun)
+	fmt.Printf("  Telemetry:   %s, from this CLI\n", telemetryLine)
+	fmt.Println()
+
+	if len(st.RecentErrors) == 0 {
+		fmt.Println(dim("  No recent errors."))
+		fmt.Println()
+		return nil
+	}
+	fmt.Printf("  Recent errors (%d):\n", len(st.RecentErrors))
+	for _, e := range st.RecentErrors {
+		fmt.Printf("  %s %-20s %s\n", dim(e.Time), e.Source, e.Message)
 	}
 	fmt.Println()
 	return nil
@@ -1002,6 +1599,11 @@ func runHeadless(prompt string) error {
 	chatLogger.LogSessionStart(cfg.AIAgent.Model)
 
 	agent := tui.NewDeerAgent(cfg, core.store, svc, core.source, core.telemetry, core.redactor, core.auditLog, chatLogger, fileLogger)
+	// There is no dialog without the TUI, so approvals are asked on stderr
+	// and answered on stdin; stdout carries only the session JSON. Tools the
+	// tool_approval policy confirms are always asked about.
+	agent.SetRequireApproval(requireApproval)
+	answerApprovalsOnStdin(agent)
 
 	ctx := context.Background()
 	if _, err := agent.RunHeadless(ctx, prompt); err != nil {
@@ -1092,6 +1694,7 @@ func runTUI() error {
 	}
 
 	agent := tui.NewDeerAgent(cfg, core.store, svc, core.source, core.telemetry, core.redactor, core.auditLog, chatLogger, fileLogger)
+	agent.SetRequireApproval(requireApproval)
 
 	model := tui.NewModel("deer", "daemon", "vm-agent", agent, cfg, configPath, fileLogger)
 	return tui.Run(model)
@@ -1115,9 +1718,11 @@ func initCoreServices(loadedCfg *config.Config, logger *slog.Logger) (*coreServi
 		return nil, fmt.Errorf("open store: %w", err)
 	}
 
-	tele, err := telemetry.NewService(loadedCfg.Telemetry)
-	if err != nil {
-		tele = telemetry.NewNoopService()
+	tele := telemetry.NewNoopService()
+	if enabled, _ := telemetry.State(loadedCfg.Telemetry, noTelemetry); enabled {
+		if t, err := telemetry.NewService(loadedCfg.Telemetry); err == nil {
+			tele = t
+		}
 	}
 
 	// Ensure source SSH keys exist
@@ -1211,7 +1816,7 @@ func initSandboxService(loadedCfg *config.Config, logger *slog.Logger) sandbox.S
 
 // --- sandbox command handlers ---
 
-func runSandboxList() error {
+func runSandboxList(watch bool, interval time.Duration, until string) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
 		return fmt.Errorf("determine config path: %w", err)
@@ -1243,31 +1848,84 @@ func runSandboxList() error {
 		}
 	}()
 
-	sandboxes, err := svc.ListSandboxes(ctx)
-	if err != nil {
-		return fmt.Errorf("list sandboxes: %w", err)
+	if !watch {
+		sandboxes, err := svc.ListSandboxes(ctx)
+		if err != nil {
+			return fmt.Errorf("list sandboxes: %w", err)
+		}
+		if outputFormat != "" {
+			return writeOutput(os.Stdout, sandboxList(sandboxes))
+		}
+		printSandboxTable(os.Stdout, sandboxes, nil)
+		return nil
 	}
 
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tracker := newChangeTracker(os.Getenv("NO_COLOR") == "")
+	return watchLoop(ctx, interval, func(ctx context.Context) (bool, error) {
+		sandboxes, err := svc.ListSandboxes(ctx)
+		writeWatchHeader(os.Stdout, interval, "deer sandbox list")
+		if err != nil {
+			// Keep watching through transient daemon errors.
+			fmt.Printf("  list sandboxes: %v\n", err)
+			return false, nil
+		}
+		printSandboxTable(os.Stdout, sandboxes, tracker)
+		if until == "" {
+			return false, nil
+		}
+		done, missed := listSettled(sandboxes, until)
+		if !done {
+			return false, nil
+		}
+		if len(missed) > 0 {
+			return true, fmt.Errorf("sandboxes entered a terminal state before reaching %s: %s", strings.ToUpper(until), strings.Join(missed, ", "))
+		}
+		fmt.Printf("  All sandboxes reached %s.\n", strings.ToUpper(until))
+		return true, nil
+	})
+}
+
+// printSandboxTable renders sandboxes as a table. When tracker is non-nil,
+// states and IPs that changed since the previous render are highlighted.
+func printSandboxTable(w io.Writer, sandboxes []*sandbox.SandboxInfo, tracker *changeTracker) {
 	if len(sandboxes) == 0 {
-		fmt.Println("  No sandboxes found.")
-		return nil
+		_, _ = fmt.Fprintln(w, "  No sandboxes found.")
+		return
 	}
 
-	fmt.Println()
-	fmt.Printf("  %-20s %-15s %-20s %-15s %s\n", "ID", "NAME", "STATE", "BASE IMAGE", "IP")
-	fmt.Printf("  %-20s %-15s %-20s %-15s %s\n", strings.Repeat("-", 20), strings.Repeat("-", 15), strings.Repeat("-", 20), strings.Repeat("-", 15), strings.Repeat("-", 15))
+	mark := func(key, value string) string {
+		if tracker == nil {
+			return value
+		}
+		return tracker.mark(key, value)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "  %-20s %-15s %-20s %-15s %-12s %s\n", "ID", "NAME", "STATE", "BASE IMAGE", "LAST ACTIVE", "IP")
+	_, _ = fmt.Fprintf(w, "  %-20s %-15s %-20s %-15s %-12s %s\n", strings.Repeat("-", 20), strings.Repeat("-", 15), strings.Repeat("-", 20), strings.Repeat("-", 15), strings.Repeat("-", 12), strings.Repeat("-", 15))
+	now := time.Now()
 	for _, sb := range sandboxes {
 		ip := "-"
 		if sb.IPAddress != "" {
 			ip = sb.IPAddress
 		}
-		fmt.Printf("  %-20s %-15s %-20s %-15s %s\n", sb.ID, sb.Name, sb.State, sb.BaseImage, ip)
+		// Pad before highlighting so escape codes don't skew column widths.
+		state := mark(sb.ID+"/state", fmt.Sprintf("%-20s", sb.State))
+		ip = mark(sb.ID+"/ip", ip)
+		_, _ = fmt.Fprintf(w, "  %-20s %-15s %s %-15s %-12s %s\n", sb.ID, sb.Name, state, sb.BaseImage, lastActive(sb.Activity, now), ip)
+	}
+	_, _ = fmt.Fprintln(w)
+	for _, sb := range sandboxes {
+		if sb.Disk.NearlyFull() {
+			_, _ = fmt.Fprintf(w, "  Warning: %s disk is %.0f%% full (%s free)\n", sb.ID, sb.Disk.UsedPercent(), formatBytes(sb.Disk.AvailableBytes))
+		}
 	}
-	fmt.Println()
-	return nil
 }
 
-func runSandboxCreate(sourceVM string, cpu, memoryMB int, live, kafkaStub, esStub bool) error {
+func runSandboxCreate(req sandbox.CreateRequest, templateName string, dryRun bool, explicit func(flag string) bool) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
 		return fmt.Errorf("determine config path: %w", err)
@@ -1278,6 +1936,18 @@ func runSandboxCreate(sourceVM string, cpu, memoryMB int, live, kafkaStub, esStu
 		return fmt.Errorf("load config: %w", err)
 	}
 
+	if templateName != "" {
+		tmpl, ok := loadedCfg.Template(templateName)
+		if !ok {
+			return fmt.Errorf("template %q not found (see 'deer template list')", templateName)
+		}
+		req = applyTemplate(tmpl, req, explicit)
+	}
+	if req.SourceVM == "" {
+		return fmt.Errorf("source VM is required: pass it as an argument or use --template")
+	}
+	req.AgentID = "cli"
+
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 	ctx := context.Background()
 
@@ -1299,15 +1969,23 @@ func runSandboxCreate(sourceVM string, cpu, memoryMB int, live, kafkaStub, esStu
 		}
 	}()
 
-	sb, err := svc.CreateSandbox(ctx, sandbox.CreateRequest{
-		SourceVM:                  sourceVM,
-		AgentID:                   "cli",
-		VCPUs:                     cpu,
-		MemoryMB:                  memoryMB,
-		Live:                      live,
-		SimpleKafkaBroker:         kafkaStub,
-		SimpleElasticsearchBroker: esStub,
-	})
+	if dryRun {
+		planner, ok := svc.(sandboxPlanner)
+		if !ok {
+			return fmt.Errorf("--dry-run needs a sandbox host; run 'deer connect' first")
+		}
+		plan, err := planner.PlanSandbox(ctx, req)
+		if err != nil {
+			return fmt.Errorf("plan sandbox: %w", err)
+		}
+		if outputFormat != "" {
+			return writeOutput(os.Stdout, plan)
+		}
+		printSandboxPlan(os.Stdout, plan)
+		return nil
+	}
+
+	sb, err := svc.CreateSandbox(ctx, req)
 	if err != nil {
 		return fmt.Errorf("create sandbox: %w", err)
 	}
@@ -1316,10 +1994,28 @@ func runSandboxCreate(sourceVM string, cpu, memoryMB int, live, kafkaStub, esStu
 	if sb.IPAddress != "" {
 		fmt.Printf("  IP: %s\n", sb.IPAddress)
 	}
+	if req.TTLSeconds > 0 {
+		fmt.Printf("  Expires: %s\n", time.Now().Add(time.Duration(req.TTLSeconds)*time.Second).Format(time.RFC3339))
+	}
 	return nil
 }
 
-func runSandboxDestroy(sandboxID string) error {
+// ttlSeconds converts --ttl to whole seconds for the daemon. Zero means no
+// per-sandbox TTL; anything shorter than a second is rejected rather than
+// silently becoming zero.
+func ttlSeconds(ttl time.Duration) (int, error) {
+	switch {
+	case ttl == 0:
+		return 0, nil
+	case ttl < time.Second:
+		return 0, fmt.Errorf("invalid --ttl %s: must be at least 1s", ttl)
+	case ttl > time.Duration(math.MaxInt32)*time.Second:
+		return 0, fmt.Errorf("invalid --ttl %s: too long", ttl)
+	}
+	return int(ttl / time.Second), nil
+}
+
+func runSandboxDestroy(sandboxID string, yes, archive bool) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
 		return fmt.Errorf("determine config path: %w", err)
@@ -1351,12 +2047,139 @@ func runSandboxDestroy(sandboxID string) error {
 		}
 	}()
 
-	err = svc.DestroySandbox(ctx, sandboxID)
+	if !yes {
+		if sb, err := svc.GetSandbox(ctx, sandboxID); err == nil && sb.Imported {
+			if !confirmImportedDestroy(os.Stdin, os.Stdout, sb) {
+				fmt.Println("  Aborted.")
+				return nil
+			}
+		}
+	}
+
+	// Past the prompt (or --yes) the user has confirmed, so an imported
+	// sandbox may go too.
+	destroyer, ok := svc.(sandboxDestroyer)
+	if !ok {
+		if archive {
+			return fmt.Errorf("archiving needs a sandbox host; run 'deer connect' first")
+		}
+		if err := svc.DestroySandbox(ctx, sandboxID); err != nil {
+			return fmt.Errorf("destroy sandbox: %w", err)
+		}
+		fmt.Printf("  Destroyed sandbox %s\n", sandboxID)
+		return nil
+	}
+	arc, err := destroyer.DestroySandboxWithOptions(ctx, sandboxID, sandbox.DestroyOptions{
+		Archive:         archive,
+		ConfirmImported: true,
+	})
 	if err != nil {
 		return fmt.Errorf("destroy sandbox: %w", err)
 	}
-
 	fmt.Printf("  Destroyed sandbox %s\n", sandboxID)
+	if arc != nil {
+		printArchive(os.Stdout, arc)
+	}
+	return nil
+}
+
+// sandboxPlanner is implemented by services that can report what a create
+// would do without doing it.
+type sandboxPlanner interface {
+	PlanSandbox(ctx context.Context, req sandbox.CreateRequest) (*sandbox.SandboxPlan, error)
+}
+
+// printSandboxPlan describes a planned create: where it would land, with
+// what resources, and anything that would get in the way.
+func printSandboxPlan(w io.Writer, plan *sandbox.SandboxPlan) {
+	name := plan.Name
+	if name == "" {
+		name = "(from ID)"
+	}
+	host := orDash(plan.Hostname)
+	if plan.HostID != "" {
+		host = fmt.Sprintf("%s (%s)", host, plan.HostID)
+	}
+	_, _ = fmt.Fprintln(w, "  Dry run: no sandbox was created.")
+	_, _ = fmt.Fprintf(w, "  Sandbox ID:  %s\n", plan.SandboxID)
+	_, _ = fmt.Fprintf(w, "  Name:        %s\n", name)
+	_, _ = fmt.Fprintf(w, "  Host:        %s\n", host)
+	_, _ = fmt.Fprintf(w, "  Source VM:   %s\n", plan.SourceVM)
+	if plan.SourceHost != "" {
+		_, _ = fmt.Fprintf(w, "  Source host: %s (%s)\n", plan.SourceHost, plan.SourceHostType)
+	}
+	_, _ = fmt.Fprintf(w, "  Resources:   %d vCPUs, %d MB", plan.VCPUs, plan.MemoryMB)
+	if plan.AvailableCPUs > 0 || plan.AvailableMemoryMB > 0 {
+		_, _ = fmt.Fprintf(w, " (host has %d vCPUs, %d MB free)", plan.AvailableCPUs, plan.AvailableMemoryMB)
+	}
+	_, _ = fmt.Fprintln(w)
+	if plan.RequiresApproval {
+		_, _ = fmt.Fprintln(w, "  Approval:    the daemon's pre-create hook decides")
+	}
+	for _, warning := range plan.Warnings {
+		_, _ = fmt.Fprintf(w, "  Warning: %s\n", warning)
+	}
+}
+
+// sandboxDestroyer is implemented by services that take destroy options:
+// archiving the disk first, or confirming an imported sandbox.
+type sandboxDestroyer interface {
+	DestroySandboxWithOptions(ctx context.Context, id string, opts sandbox.DestroyOptions) (*sandbox.Archive, error)
+}
+
+// printArchive reports where an archive was written and, when it landed in
+// the base image directory, how to restore it.
+func printArchive(w io.Writer, arc *sandbox.Archive) {
+	fmt.Fprintf(w, "  Archived to %s\n", arc.Path)
+	if arc.Image != "" {
+		fmt.Fprintf(w, "  Restore with: deer sandbox create %s\n", arc.Image)
+	}
+}
+
+// confirmImportedDestroy warns that destroying an imported sandbox deletes a
+// VM deer did not create, and requires the user to type its name to proceed.
+func confirmImportedDestroy(in io.Reader, out io.Writer, sb *sandbox.SandboxInfo) bool {
+	_, _ = fmt.Fprintf(out, "  Sandbox %s was imported from existing VM %q.\n", sb.ID, sb.BaseImage)
+	_, _ = fmt.Fprintln(out, "  Destroying it deletes that VM permanently.")
+	_, _ = fmt.Fprintf(out, "  Type the sandbox name (%s) to confirm: ", sb.Name)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(answer) == sb.Name
+}
+
+func runImportVM(vmName, name string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	core, err := initCoreServices(loadedCfg, logger)
+	if err != nil {
+		return fmt.Errorf("init core services: %w", err)
+	}
+	defer func() { _ = core.store.Close() }()
+	defer core.telemetry.Close()
+
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	sb, err := svc.ImportSandbox(ctx, vmName, name)
+	if err != nil {
+		return fmt.Errorf("import vm: %w", err)
+	}
+
+	fmt.Printf("  Imported %s as sandbox %s (%s)\n", vmName, sb.ID, sb.Name)
+	fmt.Printf("  State: %s\n", sb.State)
+	if sb.IPAddress != "" {
+		fmt.Printf("  IP: %s\n", sb.IPAddress)
+	}
 	return nil
 }
 
@@ -1429,7 +2252,7 @@ func runSandboxStop(sandboxID string) error {
 	return nil
 }
 
-func runSandboxGet(sandboxID string) error {
+func runSandboxGet(sandboxID string, watch bool, interval time.Duration, until string, activity bool) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
 		return fmt.Errorf("determine config path: %w", err)
@@ -1453,26 +2276,106 @@ func runSandboxGet(sandboxID string) error {
 	svc := initSandboxService(loadedCfg, logger)
 	defer func() { _ = svc.Close() }()
 
-	sb, err := svc.GetSandbox(ctx, sandboxID)
-	if err != nil {
-		return fmt.Errorf("get sandbox: %w", err)
+	if !watch {
+		sb, err := svc.GetSandbox(ctx, sandboxID)
+		if err != nil {
+			return fmt.Errorf("get sandbox: %w", err)
+		}
+		if outputFormat != "" {
+			return writeOutput(os.Stdout, sb)
+		}
+		printSandboxDetail(os.Stdout, sb, nil)
+		if activity {
+			printSandboxActivity(os.Stdout, sb.Activity)
+		}
+		return nil
 	}
 
-	fmt.Println()
-	fmt.Printf("  ID:         %s\n", sb.ID)
-	fmt.Printf("  Name:       %s\n", sb.Name)
-	fmt.Printf("  State:      %s\n", sb.State)
-	fmt.Printf("  Base Image: %s\n", sb.BaseImage)
-	fmt.Printf("  Agent ID:   %s\n", sb.AgentID)
-	fmt.Printf("  Created:    %s\n", sb.CreatedAt.Format(time.RFC3339))
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tracker := newChangeTracker(os.Getenv("NO_COLOR") == "")
+	title := "deer sandbox get " + sandboxID
+	return watchLoop(ctx, interval, func(ctx context.Context) (bool, error) {
+		sb, err := svc.GetSandbox(ctx, sandboxID)
+		if err != nil {
+			return true, fmt.Errorf("get sandbox: %w", err)
+		}
+		writeWatchHeader(os.Stdout, interval, title)
+		printSandboxDetail(os.Stdout, sb, tracker)
+		if activity {
+			printSandboxActivity(os.Stdout, sb.Activity)
+		}
+
+		if until != "" && strings.EqualFold(sb.State, until) {
+			fmt.Printf("  Sandbox reached %s.\n", sb.State)
+			return true, nil
+		}
+		if terminalSandboxState(sb.State) {
+			if until != "" {
+				return true, fmt.Errorf("sandbox entered %s before reaching %s", sb.State, strings.ToUpper(until))
+			}
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+// printSandboxDetail renders a single sandbox. When tracker is non-nil, the
+// state and IP are highlighted if they changed since the previous render.
+func printSandboxDetail(w io.Writer, sb *sandbox.SandboxInfo, tracker *changeTracker) {
+	state, ip := sb.State, sb.IPAddress
+	if tracker != nil {
+		state = tracker.mark("state", state)
+		ip = tracker.mark("ip", ip)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "  ID:         %s\n", sb.ID)
+	_, _ = fmt.Fprintf(w, "  Name:       %s\n", sb.Name)
+	_, _ = fmt.Fprintf(w, "  State:      %s\n", state)
+	_, _ = fmt.Fprintf(w, "  Base Image: %s\n", sb.BaseImage)
+	if sb.Imported {
+		_, _ = fmt.Fprintln(w, "  Imported:   yes")
+	}
+	_, _ = fmt.Fprintf(w, "  Agent ID:   %s\n", sb.AgentID)
+	_, _ = fmt.Fprintf(w, "  Created:    %s\n", sb.CreatedAt.Format(time.RFC3339))
 	if sb.IPAddress != "" {
-		fmt.Printf("  IP:         %s\n", sb.IPAddress)
+		_, _ = fmt.Fprintf(w, "  IP:         %s\n", ip)
 	}
-	fmt.Println()
-	return nil
+	if d := sb.Disk; d != nil {
+		warn := ""
+		if d.NearlyFull() {
+			warn = " - nearly full"
+		}
+		_, _ = fmt.Fprintf(w, "  Disk:       %s of %s used (%.0f%%)%s\n", formatBytes(d.UsedBytes), formatBytes(d.TotalBytes), d.UsedPercent(), warn)
+	}
+	if sb.Workdir != "" {
+		_, _ = fmt.Fprintf(w, "  Workdir:    %s\n", sb.Workdir)
+	}
+	if sb.IdleStopAt != nil {
+		_, _ = fmt.Fprintf(w, "  Idle stop:  %s if no commands run\n", sb.IdleStopAt.Local().Format(time.RFC3339))
+	}
+	_, _ = fmt.Fprintln(w)
 }
 
-func runSandboxRun(sandboxID, command string, timeoutSec int) error {
+// printSandboxActivity writes the command-history summary shown by
+// 'sandbox get --activity'.
+func printSandboxActivity(w io.Writer, a *sandbox.SandboxActivity) {
+	if a == nil || a.CommandCount == 0 {
+		_, _ = fmt.Fprintln(w, "  Activity:   no commands run")
+		_, _ = fmt.Fprintln(w)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "  Activity:")
+	_, _ = fmt.Fprintf(w, "    Last command: %s (%s)\n", a.LastActivityAt.Local().Format(time.RFC3339), lastActive(a, time.Now()))
+	_, _ = fmt.Fprintf(w, "    Commands:     %d\n", a.CommandCount)
+	_, _ = fmt.Fprintf(w, "    Failed:       %d (%.0f%%)\n", a.FailedCommands, a.FailureRate()*100)
+	_, _ = fmt.Fprintf(w, "    Run time:     %s\n", (time.Duration(a.TotalRuntimeMS) * time.Millisecond).Round(time.Millisecond))
+	_, _ = fmt.Fprintln(w)
+}
+
+func runSandboxRun(sandboxID, command string, opts sandbox.RunOptions, envFrom string) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
 		return fmt.Errorf("determine config path: %w", err)
@@ -1484,7 +2387,7 @@ func runSandboxRun(sandboxID, command string, timeoutSec int) error {
 	}
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	ctx := context.Background()
+	ctx := sandbox.WithActor(context.Background(), sandbox.ActorHumanCLI)
 
 	core, err := initCoreServices(loadedCfg, logger)
 	if err != nil {
@@ -1492,11 +2395,33 @@ func runSandboxRun(sandboxID, command string, timeoutSec int) error {
 	}
 	defer func() { _ = core.store.Close() }()
 	defer core.telemetry.Close()
+	if core.auditLog != nil {
+		defer func() { _ = core.auditLog.Close() }()
+	}
 
 	svc := initSandboxService(loadedCfg, logger)
 	defer func() { _ = svc.Close() }()
 
-	result, err := svc.RunCommand(ctx, sandboxID, command, timeoutSec, nil)
+	if envFrom != "" {
+		env, err := fetchSandboxEnv(ctx, svc, sandboxID, envFrom)
+		if err != nil {
+			return err
+		}
+		for k, v := range opts.Env {
+			env[k] = v
+		}
+		opts.Env = env
+	}
+
+	if requireApproval {
+		approved := promptCommandApproval(bufio.NewReader(os.Stdin), os.Stderr, sandboxID, command)
+		logCommandApproval(core.auditLog, sandboxID, command, approved)
+		if !approved {
+			return fmt.Errorf("command not approved")
+		}
+	}
+
+	result, err := svc.RunCommandWithOptions(ctx, sandboxID, command, opts)
 	if err != nil {
 		return fmt.Errorf("run command: %w", err)
 	}
@@ -1513,6 +2438,24 @@ func runSandboxRun(sandboxID, command string, timeoutSec int) error {
 	return nil
 }
 
+func runSandboxInteractiveCmd(sandboxID, command string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	return runSandboxInteractive(context.Background(), loadedCfg, svc, sandboxID, command)
+}
+
 func runSandboxSnapshot(sandboxID, name string) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
@@ -1651,6 +2594,94 @@ func runPlaybookCreate(name, hosts string, become bool) error {
 	return nil
 }
 
+func runPlaybookFromSandbox(sandboxID string, req ansible.CreatePlaybookRequest) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	core, err := initCoreServices(loadedCfg, logger)
+	if err != nil {
+		return fmt.Errorf("init core services: %w", err)
+	}
+	defer func() { _ = core.store.Close() }()
+	defer core.telemetry.Close()
+
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	playbookSvc := ansible.NewPlaybookService(core.store, loadedCfg.Ansible.PlaybooksDir)
+	result, err := playbookSvc.FromCommandHistory(ctx, svc, sandboxID, req)
+	if err != nil {
+		return fmt.Errorf("create playbook from sandbox: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, result)
+	}
+
+	fmt.Printf("  Created playbook %s (%s) with %d tasks from sandbox %s\n", result.Playbook.ID, result.Playbook.Name, len(result.Tasks), sandboxID)
+	for _, t := range result.Tasks {
+		fmt.Printf("    [%d] %s (%s)\n", t.Position, t.Name, t.Module)
+	}
+	if result.Playbook.FilePath != nil {
+		fmt.Printf("  Path: %s\n", *result.Playbook.FilePath)
+	}
+	return nil
+}
+
+func runPlaybookImport(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read playbook: %w", err)
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	core, err := initCoreServices(loadedCfg, logger)
+	if err != nil {
+		return fmt.Errorf("init core services: %w", err)
+	}
+	defer func() { _ = core.store.Close() }()
+	defer core.telemetry.Close()
+
+	playbookSvc := ansible.NewPlaybookService(core.store, loadedCfg.Ansible.PlaybooksDir)
+	result, err := playbookSvc.ImportFromYAML(ctx, name, data)
+	if err != nil {
+		return fmt.Errorf("import %s: %w", path, err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, result)
+	}
+
+	fmt.Printf("  Imported playbook %s (%s) with %d tasks\n", result.Playbook.ID, result.Playbook.Name, len(result.Tasks))
+	for _, t := range result.Tasks {
+		fmt.Printf("    [%d] %s (%s)\n", t.Position, t.Name, t.Module)
+	}
+	if result.Playbook.FilePath != nil {
+		fmt.Printf("  Path: %s\n", *result.Playbook.FilePath)
+	}
+	return nil
+}
+
 func runPlaybookGet(playbookID string) error {
 	configPath, err := resolveConfigPath()
 	if err != nil {
@@ -1765,7 +2796,7 @@ func runFileRead(sandboxID, path string) error {
 	}
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	ctx := context.Background()
+	ctx := sandbox.WithActor(context.Background(), sandbox.ActorHumanCLI)
 
 	core, err := initCoreServices(loadedCfg, logger)
 	if err != nil {
@@ -1816,7 +2847,7 @@ func runFileEdit(sandboxID, path, oldStr, newStr string, replaceAll bool) error
 	}
 
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	ctx := context.Background()
+	ctx := sandbox.WithActor(context.Background(), sandbox.ActorHumanCLI)
 
 	core, err := initCoreServices(loadedCfg, logger)
 	if err != nil {
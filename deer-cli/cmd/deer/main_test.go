@@ -1,9 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
 )
 
 func TestUpsertSandboxHost(t *testing.T) {
@@ -119,3 +123,87 @@ func TestUpsertSandboxHost(t *testing.T) {
 		})
 	}
 }
+
+func TestConfirmImportedDestroy(t *testing.T) {
+	sb := &sandbox.SandboxInfo{ID: "sbx-1", Name: "legacy-web", BaseImage: "legacy-web", Imported: true}
+
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "legacy-web\n", want: true},
+		{input: "  legacy-web  \n", want: true},
+		{input: "y\n", want: false},
+		{input: "", want: false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := confirmImportedDestroy(strings.NewReader(tt.input), &out, sb); got != tt.want {
+			t.Errorf("confirmImportedDestroy(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if !strings.Contains(out.String(), "deletes that VM") {
+			t.Errorf("prompt missing warning: %q", out.String())
+		}
+	}
+}
+
+func TestPrintArchive(t *testing.T) {
+	var out bytes.Buffer
+	printArchive(&out, &sandbox.Archive{Path: "/var/lib/deer/images/archive-sbx-1.qcow2", Image: "archive-sbx-1"})
+	if !strings.Contains(out.String(), "Archived to /var/lib/deer/images/archive-sbx-1.qcow2") ||
+		!strings.Contains(out.String(), "deer sandbox create archive-sbx-1") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	out.Reset()
+	printArchive(&out, &sandbox.Archive{Path: "/srv/archives/archive-sbx-1.qcow2"})
+	if strings.Contains(out.String(), "Restore with") {
+		t.Errorf("restore hint printed for an archive outside the image dir: %q", out.String())
+	}
+}
+
+func TestPrintSandboxPlan(t *testing.T) {
+	var out bytes.Buffer
+	printSandboxPlan(&out, &sandbox.SandboxPlan{
+		SandboxID:         "sbx-ab12cd",
+		Hostname:          "kvm-01",
+		SourceVM:          "web-01",
+		SourceHost:        "10.0.0.2",
+		SourceHostType:    "libvirt",
+		VCPUs:             4,
+		MemoryMB:          8192,
+		AvailableCPUs:     8,
+		AvailableMemoryMB: 4096,
+		Warnings:          []string{"8192 MB of memory requested but only 4096 MB is free"},
+		RequiresApproval:  true,
+	})
+	for _, want := range []string{"no sandbox was created", "sbx-ab12cd", "kvm-01", "10.0.0.2 (libvirt)", "4 vCPUs, 8192 MB", "4096 MB free", "pre-create hook", "Warning: 8192 MB"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestTTLSeconds(t *testing.T) {
+	for _, tc := range []struct {
+		in      time.Duration
+		want    int
+		wantErr bool
+	}{
+		{in: 0, want: 0},
+		{in: 90 * time.Minute, want: 5400},
+		{in: 1500 * time.Millisecond, want: 1},
+		{in: 500 * time.Millisecond, wantErr: true},
+		{in: -time.Hour, wantErr: true},
+		{in: 1 << 62, wantErr: true},
+	} {
+		got, err := ttlSeconds(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ttlSeconds(%s) err = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ttlSeconds(%s) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
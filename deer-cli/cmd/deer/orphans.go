@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// orphanLister is implemented by sandbox services backed by a daemon whose
+// provider can leak host resources (the RemoteService).
+type orphanLister interface {
+	ListOrphans(ctx context.Context) (*sandbox.OrphanReport, error)
+	ReclaimOrphans(ctx context.Context, ids []string) (*sandbox.OrphanReport, error)
+}
+
+func runOrphans(reclaim, yes bool) error {
+	if reclaim && !yes && outputFormat != "" {
+		return fmt.Errorf("--reclaim with --output needs --yes, since it cannot prompt")
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	lister, ok := svc.(orphanLister)
+	if !ok {
+		return fmt.Errorf("checking for orphans needs a sandbox host; run 'deer connect' first")
+	}
+
+	ctx := context.Background()
+	report, err := lister.ListOrphans(ctx)
+	if err != nil {
+		return fmt.Errorf("list orphans: %w", err)
+	}
+	if reclaim && len(report.Orphans) > 0 {
+		if !yes {
+			printOrphans(os.Stdout, report, true)
+			if !confirmReclaim(os.Stdin, os.Stdout, len(report.Orphans)) {
+				fmt.Println("  Aborted.")
+				return nil
+			}
+		}
+		ids := make([]string, 0, len(report.Orphans))
+		for _, o := range report.Orphans {
+			ids = append(ids, o.ID)
+		}
+		if report, err = lister.ReclaimOrphans(ctx, ids); err != nil {
+			return fmt.Errorf("reclaim orphans: %w", err)
+		}
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, report)
+	}
+	printOrphans(os.Stdout, report, reclaim)
+	return nil
+}
+
+// confirmReclaim asks before deleting n orphaned resources.
+func confirmReclaim(in io.Reader, out io.Writer, n int) bool {
+	_, _ = fmt.Fprintf(out, "  Delete these %d resources? They cannot be recovered. [y/N] ", n)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// printOrphans writes one line per orphan and the state of the pool.
+func printOrphans(w io.Writer, report *sandbox.OrphanReport, reclaim bool) {
+	if len(report.Orphans) == 0 {
+		fmt.Fprintln(w, "  No orphaned resources")
+	}
+	for _, o := range report.Orphans {
+		status := ""
+		switch {
+		case o.Reclaimed:
+			status = " (reclaimed)"
+		case o.Error != "":
+			status = " (" + o.Error + ")"
+		}
+		fmt.Fprintf(w, "  %s  %s  %s%s\n", o.ID, o.Name, o.State, status)
+		fmt.Fprintf(w, "    %s\n", o.Reason)
+	}
+	if report.PoolSize > 0 {
+		fmt.Fprintf(w, "  Pool: %d of %d free\n", report.PoolFree, report.PoolSize)
+	}
+	if !reclaim && len(report.Orphans) > 0 {
+		fmt.Fprintln(w, "  Run 'deer orphans --reclaim' to delete them.")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestPrintOrphans(t *testing.T) {
+	report := &sandbox.OrphanReport{
+		Orphans: []sandbox.Orphan{
+			{ID: "9003", Name: "sbx-old", State: "stopped", Reason: "no sandbox owns it", Reclaimed: true},
+			{ID: "9004", Name: "half", State: "running", Reason: "no sandbox owns it", Error: "reclaim: CT is locked"},
+		},
+		PoolSize: 1000,
+		PoolFree: 12,
+	}
+	var buf bytes.Buffer
+	printOrphans(&buf, report, true)
+	out := buf.String()
+	for _, want := range []string{"9003  sbx-old  stopped (reclaimed)", "(reclaim: CT is locked)", "Pool: 12 of 1000 free"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "--reclaim") {
+		t.Errorf("reclaim hint shown after reclaiming:\n%s", out)
+	}
+
+	buf.Reset()
+	printOrphans(&buf, &sandbox.OrphanReport{}, false)
+	if !strings.Contains(buf.String(), "No orphaned resources") {
+		t.Errorf("empty report output = %q", buf.String())
+	}
+}
+
+func TestConfirmReclaim(t *testing.T) {
+	for input, want := range map[string]bool{"y\n": true, "YES\n": true, "\n": false, "n\n": false, "": false} {
+		var out bytes.Buffer
+		if got := confirmReclaim(strings.NewReader(input), &out, 2); got != want {
+			t.Errorf("confirmReclaim(%q) = %v, want %v", input, got, want)
+		}
+		if !strings.Contains(out.String(), "Delete these 2 resources?") {
+			t.Errorf("prompt = %q", out.String())
+		}
+	}
+}
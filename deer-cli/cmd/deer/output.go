@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// Structured output formats accepted by --output.
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+	outputWide  = "wide"
+)
+
+// wideCellMax caps free-text columns in wide tables so a long name or image
+// does not push the rest of the row off screen. IDs are never truncated.
+const wideCellMax = 32
+
+// jsonOutput is the resolved value of the global --json flag. Unless the flag
+// is given explicitly it follows stdout: JSON when piped or redirected, human
+// output on a terminal.
+var jsonOutput bool
+
+// outputFormat is the resolved --output format, or "" for the default
+// human-readable output. --json is an alias for --output json.
+var outputFormat string
+
+// stdoutIsTerminal reports whether stdout is attached to a terminal.
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// applyJSONDefault sets jsonOutput from the terminal state when --json was not
+// passed on the command line.
+func applyJSONDefault(cmd *cobra.Command, isTerminal bool) {
+	if f := cmd.Flags().Lookup("json"); f != nil && f.Changed {
+		return
+	}
+	jsonOutput = !isTerminal
+}
+
+// applyOutputFormat resolves outputFormat from --output, falling back to the
+// --json behaviour when it is not given.
+func applyOutputFormat(cmd *cobra.Command, isTerminal bool) error {
+	if f := cmd.Flags().Lookup("output"); f != nil && f.Changed {
+		switch outputFormat {
+		case outputJSON, outputYAML, outputTable, outputWide:
+		default:
+			return fmt.Errorf("invalid --output %q: must be json, yaml, table, or wide", outputFormat)
+		}
+		jsonOutput = outputFormat == outputJSON
+		return nil
+	}
+	applyJSONDefault(cmd, isTerminal)
+	outputFormat = ""
+	if jsonOutput {
+		outputFormat = outputJSON
+	}
+	return nil
+}
+
+// writeOutput writes v to w in the selected --output format.
+func writeOutput(w io.Writer, v any) error {
+	switch outputFormat {
+	case outputYAML:
+		return writeYAML(w, v)
+	case outputTable:
+		return writeTable(w, v)
+	case outputWide:
+		return writeWideTable(w, v)
+	default:
+		return writeJSON(w, v)
+	}
+}
+
+// errorOutput is how a failed command reports its error under --output
+// json. Code is a stable classification from sandbox.ErrorCode.
+type errorOutput struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// outputError writes err to w as an errorOutput.
+func outputError(w io.Writer, err error) {
+	_ = writeJSON(w, errorOutput{Error: err.Error(), Code: sandbox.ErrorCode(err)})
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode JSON output: %w", err)
+	}
+	return nil
+}
+
+// writeYAML writes v to w as YAML. The value goes through JSON first so keys
+// match the JSON output rather than Go field names.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode YAML output: %w", err)
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return fmt.Errorf("encode YAML output: %w", err)
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return fmt.Errorf("encode YAML output: %w", err)
+	}
+	return enc.Close()
+}
+
+// tableRenderer is implemented by list payloads that know their columns.
+type tableRenderer interface {
+	tableHeader() []string
+	tableRows() [][]string
+}
+
+// wideTableRenderer is implemented by list payloads with extra columns for
+// --output wide.
+type wideTableRenderer interface {
+	wideTableHeader() []string
+	wideTableRows() [][]string
+}
+
+// writeTable writes v as tab-aligned columns, or as YAML when v is not a
+// recognised list.
+func writeTable(w io.Writer, v any) error {
+	t, ok := v.(tableRenderer)
+	if !ok {
+		return writeYAML(w, v)
+	}
+	return writeColumns(w, t.tableHeader(), t.tableRows())
+}
+
+// writeWideTable writes v with its wide columns, falling back to the normal
+// table for payloads without any.
+func writeWideTable(w io.Writer, v any) error {
+	t, ok := v.(wideTableRenderer)
+	if !ok {
+		return writeTable(w, v)
+	}
+	return writeColumns(w, t.wideTableHeader(), t.wideTableRows())
+}
+
+func writeColumns(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// truncateCell shortens s to at most n runes, marking the cut with "…".
+func truncateCell(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// orDash returns s, or "-" when it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sandboxList is the --output payload of 'deer sandbox list'.
+type sandboxList []*sandbox.SandboxInfo
+
+func (l sandboxList) tableHeader() []string {
+	return []string{"ID", "NAME", "STATE", "IP", "CREATED", "LAST ACTIVE"}
+}
+
+func (l sandboxList) tableRows() [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, sb := range l {
+		ip := sb.IPAddress
+		if ip == "" {
+			ip = "-"
+		}
+		created := "-"
+		if !sb.CreatedAt.IsZero() {
+			created = sb.CreatedAt.Local().Format(time.DateTime)
+		}
+		rows = append(rows, []string{sb.ID, sb.Name, sb.State, ip, created, lastActive(sb.Activity, time.Now())})
+	}
+	return rows
+}
+
+func (l sandboxList) wideTableHeader() []string {
+	return []string{"ID", "NAME", "STATE", "IP", "MAC", "HOST", "IMAGE", "AGE", "LAST ACTIVE"}
+}
+
+func (l sandboxList) wideTableRows() [][]string {
+	now := time.Now()
+	rows := make([][]string, 0, len(l))
+	for _, sb := range l {
+		age := "-"
+		if !sb.CreatedAt.IsZero() {
+			age = shortDuration(now.Sub(sb.CreatedAt))
+		}
+		rows = append(rows, []string{
+			sb.ID,
+			truncateCell(sb.Name, wideCellMax),
+			sb.State,
+			orDash(sb.IPAddress),
+			orDash(sb.MACAddress),
+			orDash(truncateCell(sb.HostID, wideCellMax)),
+			orDash(truncateCell(sb.BaseImage, wideCellMax)),
+			age,
+			lastActive(sb.Activity, now),
+		})
+	}
+	return rows
+}
+
+// formatBytes renders n in binary units, e.g. "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// lastActive describes when a sandbox last ran a command, relative to now.
+func lastActive(a *sandbox.SandboxActivity, now time.Time) string {
+	if a == nil || a.LastActivityAt.IsZero() {
+		return "never"
+	}
+	d := now.Sub(a.LastActivityAt)
+	if d < time.Minute {
+		return "just now"
+	}
+	return shortDuration(d) + " ago"
+}
+
+// shortDuration renders d in its largest whole unit, e.g. "45s", "12m", "5h",
+// or "3d". Hours are used up to two days.
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+}
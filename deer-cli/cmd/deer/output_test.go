@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func newJSONFlagCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "")
+	return cmd
+}
+
+func TestApplyJSONDefault(t *testing.T) {
+	defer func() { jsonOutput = false }()
+
+	cmd := newJSONFlagCmd()
+	applyJSONDefault(cmd, false)
+	if !jsonOutput {
+		t.Error("expected JSON output when stdout is not a terminal")
+	}
+
+	cmd = newJSONFlagCmd()
+	applyJSONDefault(cmd, true)
+	if jsonOutput {
+		t.Error("expected human output on a terminal")
+	}
+}
+
+func TestApplyJSONDefault_ExplicitFlagWins(t *testing.T) {
+	defer func() { jsonOutput = false }()
+
+	cmd := newJSONFlagCmd()
+	if err := cmd.Flags().Parse([]string{"--json=false"}); err != nil {
+		t.Fatal(err)
+	}
+	applyJSONDefault(cmd, false)
+	if jsonOutput {
+		t.Error("--json=false should hold even when piped")
+	}
+
+	cmd = newJSONFlagCmd()
+	if err := cmd.Flags().Parse([]string{"--json"}); err != nil {
+		t.Fatal(err)
+	}
+	applyJSONDefault(cmd, true)
+	if !jsonOutput {
+		t.Error("--json should hold on a terminal")
+	}
+}
+
+func TestWriteJSON_Unencodable(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeJSON(&buf, map[string]any{"ch": make(chan int)})
+	if err == nil || !strings.Contains(err.Error(), "encode JSON output") {
+		t.Fatalf("err = %v, want encode error", err)
+	}
+}
+
+func TestOutputError(t *testing.T) {
+	var buf bytes.Buffer
+	outputError(&buf, fmt.Errorf("create sandbox: %w: VM \"web-01\" not found", sandbox.ErrSourceVMNotFound))
+	var got errorOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if got.Code != "SOURCE_VM_NOT_FOUND" || !strings.Contains(got.Error, "web-01") {
+		t.Errorf("error output = %+v", got)
+	}
+}
+
+func newOutputFlagCmd() *cobra.Command {
+	cmd := newJSONFlagCmd()
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "")
+	return cmd
+}
+
+func TestApplyOutputFormat(t *testing.T) {
+	defer func() { jsonOutput, outputFormat = false, "" }()
+
+	cmd := newOutputFlagCmd()
+	if err := cmd.Flags().Parse([]string{"-o", "table"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyOutputFormat(cmd, false); err != nil {
+		t.Fatal(err)
+	}
+	if outputFormat != outputTable || jsonOutput {
+		t.Errorf("outputFormat = %q, jsonOutput = %v; want table, false", outputFormat, jsonOutput)
+	}
+
+	cmd = newOutputFlagCmd()
+	if err := cmd.Flags().Parse([]string{"--json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyOutputFormat(cmd, true); err != nil {
+		t.Fatal(err)
+	}
+	if outputFormat != outputJSON {
+		t.Errorf("--json: outputFormat = %q, want json", outputFormat)
+	}
+
+	cmd = newOutputFlagCmd()
+	if err := applyOutputFormat(cmd, true); err != nil {
+		t.Fatal(err)
+	}
+	if outputFormat != "" {
+		t.Errorf("terminal default: outputFormat = %q, want human output", outputFormat)
+	}
+
+	cmd = newOutputFlagCmd()
+	if err := cmd.Flags().Parse([]string{"--output", "xml"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyOutputFormat(cmd, true); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestWriteOutput_Table(t *testing.T) {
+	defer func() { outputFormat = "" }()
+	outputFormat = outputTable
+
+	var buf bytes.Buffer
+	list := sandboxList{
+		{ID: "sbx-1", Name: "web", State: "RUNNING", IPAddress: "10.0.0.2"},
+		{ID: "sbx-long-id", Name: "db", State: "STOPPED"},
+	}
+	if err := writeOutput(&buf, list); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	col := strings.Index(lines[0], "NAME")
+	if strings.Index(lines[1], "web") != col || strings.Index(lines[2], "db") != col {
+		t.Errorf("NAME column not aligned:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[2], " - ") {
+		t.Errorf("missing IP placeholder: %q", lines[2])
+	}
+}
+
+func TestWriteOutput_TableFallsBackToYAML(t *testing.T) {
+	defer func() { outputFormat = "" }()
+	outputFormat = outputTable
+
+	var buf bytes.Buffer
+	if err := writeOutput(&buf, &sandbox.SandboxInfo{ID: "sbx-1", IPAddress: "10.0.0.2"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "id: sbx-1") || !strings.Contains(buf.String(), "ip_address: 10.0.0.2") {
+		t.Errorf("expected YAML with JSON keys, got:\n%s", buf.String())
+	}
+}
+
+func TestLastActive(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		a    *sandbox.SandboxActivity
+		want string
+	}{
+		{nil, "never"},
+		{&sandbox.SandboxActivity{}, "never"},
+		{&sandbox.SandboxActivity{LastActivityAt: now.Add(-20 * time.Second)}, "just now"},
+		{&sandbox.SandboxActivity{LastActivityAt: now.Add(-5 * time.Minute)}, "5m ago"},
+		{&sandbox.SandboxActivity{LastActivityAt: now.Add(-3 * time.Hour)}, "3h ago"},
+		{&sandbox.SandboxActivity{LastActivityAt: now.Add(-72 * time.Hour)}, "3d ago"},
+	} {
+		if got := lastActive(tc.a, now); got != tc.want {
+			t.Errorf("lastActive(%+v) = %q, want %q", tc.a, got, tc.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[int64]string{
+		512:           "512 B",
+		1536:          "1.5 KiB",
+		10 << 30:      "10.0 GiB",
+		423420 * 1024: "413.5 MiB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestPrintSandbox_DiskWarning(t *testing.T) {
+	full := &sandbox.SandboxInfo{ID: "SBX-1", Name: "build", State: "RUNNING",
+		Disk: &sandbox.DiskUsage{TotalBytes: 10 << 30, UsedBytes: 76 << 27, AvailableBytes: 4 << 27}}
+	roomy := &sandbox.SandboxInfo{ID: "SBX-2", Name: "web", State: "RUNNING",
+		Disk: &sandbox.DiskUsage{TotalBytes: 10 << 30, UsedBytes: 2 << 30, AvailableBytes: 8 << 30}}
+
+	var buf bytes.Buffer
+	printSandboxTable(&buf, []*sandbox.SandboxInfo{full, roomy}, nil)
+	if !strings.Contains(buf.String(), "Warning: SBX-1 disk is 95% full (512.0 MiB free)") || strings.Contains(buf.String(), "SBX-2 disk") {
+		t.Errorf("list output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	printSandboxDetail(&buf, full, nil)
+	if !strings.Contains(buf.String(), "Disk:       9.5 GiB of 10.0 GiB used (95%) - nearly full") {
+		t.Errorf("detail output:\n%s", buf.String())
+	}
+}
+
+func TestShortDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		45 * time.Second: "45s",
+		12 * time.Minute: "12m",
+		30 * time.Hour:   "30h",
+		72 * time.Hour:   "3d",
+	} {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	if got := truncateCell("short", 10); got != "short" {
+		t.Errorf("truncateCell(short) = %q", got)
+	}
+	if got := truncateCell("a-very-long-sandbox-name", 10); got != "a-very-lo…" {
+		t.Errorf("truncateCell(long) = %q, want a-very-lo…", got)
+	}
+}
+
+func TestWriteOutput_Wide(t *testing.T) {
+	defer func() { outputFormat = "" }()
+	outputFormat = outputWide
+
+	list := sandboxList{{
+		ID:         "SBX-0123456789abcdef",
+		Name:       strings.Repeat("n", 40),
+		State:      "RUNNING",
+		IPAddress:  "10.0.0.5",
+		MACAddress: "52:54:00:12:34:56",
+		HostID:     "host-a",
+		BaseImage:  "ubuntu-22.04",
+		CreatedAt:  time.Now().Add(-3 * time.Hour),
+	}}
+	var buf bytes.Buffer
+	if err := writeOutput(&buf, list); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"MAC", "HOST", "AGE", "SBX-0123456789abcdef", "52:54:00:12:34:56", "host-a", "3h", strings.Repeat("n", 31) + "…"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("wide output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, strings.Repeat("n", 40)) {
+		t.Errorf("long name not truncated:\n%s", out)
+	}
+
+	// Payloads without wide columns fall back to YAML like --output table.
+	buf.Reset()
+	if err := writeOutput(&buf, map[string]string{"k": "v"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "k: v") {
+		t.Errorf("fallback output = %q", buf.String())
+	}
+}
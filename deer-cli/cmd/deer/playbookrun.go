@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/ansible"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// promptPlaybookApproval asks on out whether the playbook may run in
+// sandboxID. Playbook tasks can download packages and files, so a run is
+// treated like a command that reaches the network. Anything but y or yes,
+// including EOF, is a no.
+func promptPlaybookApproval(in *bufio.Reader, out io.Writer, playbook, sandboxID string) bool {
+	_, _ = fmt.Fprintf(out, "  Sandbox:  %s\n  Playbook: %s\n  Its tasks may reach the network. Run it? [y/N] ", sandboxID, playbook)
+	return readYes(in)
+}
+
+// logPlaybookApproval records a playbook run decision in the audit log, if
+// one is open.
+func logPlaybookApproval(al *audit.Logger, playbookID, sandboxID string, approved bool) {
+	if al == nil {
+		return
+	}
+	al.LogApproval("run_playbook", "network", map[string]any{"playbook_id": playbookID, "sandbox_id": sandboxID}, approved)
+}
+
+func runPlaybookRun(playbookID, sandboxID string, timeoutSec int, yes bool) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := sandbox.WithActor(context.Background(), sandbox.ActorHumanCLI)
+
+	core, err := initCoreServices(loadedCfg, logger)
+	if err != nil {
+		return fmt.Errorf("init core services: %w", err)
+	}
+	defer func() { _ = core.store.Close() }()
+	defer core.telemetry.Close()
+	if core.auditLog != nil {
+		defer func() { _ = core.auditLog.Close() }()
+	}
+
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	playbookSvc := ansible.NewPlaybookService(core.store, loadedCfg.Ansible.PlaybooksDir)
+	pb, err := playbookSvc.GetPlaybook(ctx, playbookID)
+	if err != nil {
+		return fmt.Errorf("get playbook: %w", err)
+	}
+
+	if !yes || requireApproval {
+		approved := promptPlaybookApproval(bufio.NewReader(os.Stdin), os.Stderr, pb.Name, sandboxID)
+		logPlaybookApproval(core.auditLog, playbookID, sandboxID, approved)
+		if !approved {
+			return fmt.Errorf("playbook run not approved")
+		}
+	}
+
+	text := outputFormat == ""
+	streamed := false
+	var onOutput sandbox.OutputCallback
+	if text {
+		onOutput = func(chunk string, isStderr bool) {
+			streamed = true
+			if isStderr {
+				_, _ = fmt.Fprint(os.Stderr, chunk)
+				return
+			}
+			_, _ = fmt.Fprint(os.Stdout, chunk)
+		}
+	}
+
+	run, err := playbookSvc.RunInSandbox(ctx, svc, playbookID, sandboxID, timeoutSec, onOutput)
+	if err != nil {
+		return err
+	}
+	if !text {
+		return writeOutput(os.Stdout, run)
+	}
+	if !streamed {
+		printPlaybookOutput(os.Stdout, run)
+	}
+	printPlaybookRun(os.Stdout, run)
+	if !run.Success {
+		return fmt.Errorf("playbook %s failed in sandbox %s", pb.Name, sandboxID)
+	}
+	return nil
+}
+
+// printPlaybookOutput writes ansible-playbook's output after the fact, for
+// services that cannot stream it.
+func printPlaybookOutput(w io.Writer, r *ansible.RunResult) {
+	if r.Stdout != "" {
+		_, _ = fmt.Fprintln(w, indentLines(r.Stdout, "  "))
+	}
+	if r.Stderr != "" {
+		_, _ = fmt.Fprintln(w, "  STDERR:")
+		_, _ = fmt.Fprintln(w, indentLines(r.Stderr, "    "))
+	}
+}
+
+// printPlaybookRun writes the per-task counts and the outcome.
+func printPlaybookRun(w io.Writer, r *ansible.RunResult) {
+	_, _ = fmt.Fprintln(w)
+	if len(r.Tasks) > 0 {
+		_, _ = fmt.Fprintln(w, "  Tasks:")
+		for _, t := range r.Tasks {
+			status := "ok"
+			switch {
+			case t.Failed > 0:
+				status = "FAILED"
+			case t.Changed > 0:
+				status = "changed"
+			case t.OK == 0 && t.Skipped > 0:
+				status = "skipped"
+			}
+			_, _ = fmt.Fprintf(w, "    %-8s %s (ok=%d changed=%d failed=%d skipped=%d)\n", status, t.Name, t.OK, t.Changed, t.Failed, t.Skipped)
+		}
+	}
+	if r.Error != "" {
+		_, _ = fmt.Fprintf(w, "  %s\n", r.Error)
+	}
+	if r.Success {
+		_, _ = fmt.Fprintf(w, "  Playbook succeeded in sandbox %s.\n", r.SandboxID)
+	} else {
+		_, _ = fmt.Fprintf(w, "  Playbook failed in sandbox %s (exit code %d).\n", r.SandboxID, r.ExitCode)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/ansible"
+)
+
+func TestPromptPlaybookApproval(t *testing.T) {
+	var out bytes.Buffer
+	if !promptPlaybookApproval(bufio.NewReader(strings.NewReader("yes\n")), &out, "web", "SBX-1") {
+		t.Error("yes was not taken as approval")
+	}
+	if !strings.Contains(out.String(), "Playbook: web") || !strings.Contains(out.String(), "reach the network") {
+		t.Errorf("prompt = %q", out.String())
+	}
+	if promptPlaybookApproval(bufio.NewReader(strings.NewReader("")), &out, "web", "SBX-1") {
+		t.Error("EOF was taken as approval")
+	}
+}
+
+func TestPrintPlaybookRun(t *testing.T) {
+	var buf bytes.Buffer
+	printPlaybookRun(&buf, &ansible.RunResult{
+		SandboxID: "SBX-1",
+		ExitCode:  2,
+		Tasks: []ansible.TaskResult{
+			{Name: "Install nginx", Changed: 1},
+			{Name: "Copy config", Failed: 1},
+			{Name: "Debian only", Skipped: 1},
+		},
+	})
+	out := buf.String()
+	for _, want := range []string{
+		"changed  Install nginx (ok=0 changed=1 failed=0 skipped=0)",
+		"FAILED   Copy config",
+		"skipped  Debian only",
+		"Playbook failed in sandbox SBX-1 (exit code 2).",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// replayStep is one replayed command and how its exit code compares with
+// the recorded run. Error is set when the command could not run at all.
+type replayStep struct {
+	Command          string `json:"command"`
+	OriginalExitCode int    `json:"original_exit_code"`
+	ExitCode         int    `json:"exit_code"`
+	Diverged         bool   `json:"diverged"`
+	Error            string `json:"error,omitempty"`
+}
+
+// replayReport is the --output payload of 'deer replay'. Stopped is set when
+// --stop-on-divergence cut the replay short.
+type replayReport struct {
+	SourceSandboxID string       `json:"source_sandbox_id"`
+	SandboxID       string       `json:"sandbox_id"`
+	BaseImage       string       `json:"base_image"`
+	Steps           []replayStep `json:"steps"`
+	Diverged        int          `json:"diverged"`
+	Stopped         bool         `json:"stopped,omitempty"`
+}
+
+// commandRunner is the part of sandbox.Service a replay needs.
+type commandRunner interface {
+	RunCommandWithOptions(ctx context.Context, sandboxID, command string, opts sandbox.RunOptions) (*sandbox.CommandResult, error)
+}
+
+// replayCommands runs cmds in order in sandboxID, each in the directory it
+// was recorded in, calling onStep after each.
+// A command diverges when its exit code differs from the recorded one or it
+// fails to run; with stopOnDivergence the first divergence ends the replay.
+func replayCommands(ctx context.Context, svc commandRunner, sandboxID string, cmds []*sandbox.CommandRecord, stopOnDivergence bool, onStep func(replayStep)) ([]replayStep, bool) {
+	steps := make([]replayStep, 0, len(cmds))
+	for _, c := range cmds {
+		step := replayStep{Command: c.Command, OriginalExitCode: c.ExitCode}
+		res, err := svc.RunCommandWithOptions(ctx, sandboxID, c.Command, sandbox.RunOptions{Workdir: c.Workdir})
+		if err != nil {
+			step.ExitCode = -1
+			step.Error = err.Error()
+		} else {
+			step.ExitCode = res.ExitCode
+		}
+		step.Diverged = err != nil || step.ExitCode != step.OriginalExitCode
+		steps = append(steps, step)
+		if onStep != nil {
+			onStep(step)
+		}
+		if step.Diverged && stopOnDivergence {
+			return steps, len(steps) < len(cmds)
+		}
+	}
+	return steps, false
+}
+
+func runReplay(sourceID, name string, stopOnDivergence bool) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := sandbox.WithActor(context.Background(), sandbox.ActorHumanCLI)
+
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	source, err := svc.GetSandbox(ctx, sourceID)
+	if err != nil {
+		return fmt.Errorf("get sandbox: %w", err)
+	}
+	cmds, err := svc.ListSandboxCommands(ctx, sourceID, 0, "")
+	if err != nil {
+		return fmt.Errorf("list sandbox commands: %w", err)
+	}
+	if len(cmds) == 0 {
+		return fmt.Errorf("sandbox %s has no recorded commands to replay", sourceID)
+	}
+
+	sb, err := svc.CreateSandbox(ctx, sandbox.CreateRequest{
+		SourceVM: source.BaseImage,
+		Name:     name,
+		AgentID:  "cli",
+		VCPUs:    source.VCPUs,
+		MemoryMB: source.MemoryMB,
+	})
+	if err != nil {
+		return fmt.Errorf("create sandbox: %w", err)
+	}
+
+	text := outputFormat == ""
+	if text {
+		fmt.Printf("  Replaying %d commands from %s in new sandbox %s (%s)\n\n", len(cmds), sourceID, sb.ID, source.BaseImage)
+	}
+	steps, stopped := replayCommands(ctx, svc, sb.ID, cmds, stopOnDivergence, func(step replayStep) {
+		if text {
+			printReplayStep(os.Stdout, step)
+		}
+	})
+
+	report := &replayReport{
+		SourceSandboxID: sourceID,
+		SandboxID:       sb.ID,
+		BaseImage:       source.BaseImage,
+		Steps:           steps,
+		Stopped:         stopped,
+	}
+	for _, s := range steps {
+		if s.Diverged {
+			report.Diverged++
+		}
+	}
+	if !text {
+		return writeOutput(os.Stdout, report)
+	}
+	printReplaySummary(os.Stdout, report, len(cmds))
+	return nil
+}
+
+// printReplayStep writes one replayed command, flagging a divergence.
+func printReplayStep(w io.Writer, s replayStep) {
+	switch {
+	case s.Error != "":
+		_, _ = fmt.Fprintf(w, "  DIVERGED  $ %s\n            failed to run: %s (was exit %d)\n", s.Command, s.Error, s.OriginalExitCode)
+	case s.Diverged:
+		_, _ = fmt.Fprintf(w, "  DIVERGED  $ %s\n            exit %d, was exit %d\n", s.Command, s.ExitCode, s.OriginalExitCode)
+	default:
+		_, _ = fmt.Fprintf(w, "  ok        $ %s\n", s.Command)
+	}
+}
+
+// printReplaySummary writes the closing counts and where the replay ran.
+func printReplaySummary(w io.Writer, r *replayReport, total int) {
+	_, _ = fmt.Fprintln(w)
+	if r.Stopped {
+		_, _ = fmt.Fprintf(w, "  Stopped at the first divergence after %d of %d commands.\n", len(r.Steps), total)
+	}
+	if r.Diverged == 0 {
+		_, _ = fmt.Fprintf(w, "  All %d commands matched their recorded exit codes.\n", len(r.Steps))
+	} else {
+		_, _ = fmt.Fprintf(w, "  %d of %d replayed commands diverged.\n", r.Diverged, len(r.Steps))
+	}
+	_, _ = fmt.Fprintf(w, "  Sandbox %s is still running; destroy it with 'deer sandbox destroy %s'.\n", r.SandboxID, r.SandboxID)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// scriptedRunner returns a fixed exit code per command, or an error for
+// commands not in the script.
+type scriptedRunner struct {
+	exitCodes map[string]int
+	ran       []string
+	workdirs  []string
+}
+
+func (r *scriptedRunner) RunCommandWithOptions(_ context.Context, _, command string, opts sandbox.RunOptions) (*sandbox.CommandResult, error) {
+	r.ran = append(r.ran, command)
+	r.workdirs = append(r.workdirs, opts.Workdir)
+	code, ok := r.exitCodes[command]
+	if !ok {
+		return nil, errors.New("connection reset")
+	}
+	return &sandbox.CommandResult{ExitCode: code}, nil
+}
+
+func replayHistory() []*sandbox.CommandRecord {
+	return []*sandbox.CommandRecord{
+		{Command: "apt-get install -y nginx", ExitCode: 0},
+		{Command: "nginx -t", Workdir: "/etc/nginx", ExitCode: 0},
+		{Command: "systemctl restart nginx", ExitCode: 0},
+	}
+}
+
+func TestReplayCommands(t *testing.T) {
+	runner := &scriptedRunner{exitCodes: map[string]int{"apt-get install -y nginx": 0, "nginx -t": 1}}
+
+	var seen int
+	steps, stopped := replayCommands(context.Background(), runner, "sbx-new", replayHistory(), false, func(replayStep) { seen++ })
+	if stopped || len(steps) != 3 || seen != 3 {
+		t.Fatalf("steps = %+v, stopped = %v, callbacks = %d", steps, stopped, seen)
+	}
+	if runner.workdirs[0] != "" || runner.workdirs[1] != "/etc/nginx" {
+		t.Errorf("workdirs = %q, want the recorded ones", runner.workdirs)
+	}
+	if steps[0].Diverged {
+		t.Errorf("matching step diverged: %+v", steps[0])
+	}
+	if !steps[1].Diverged || steps[1].ExitCode != 1 {
+		t.Errorf("exit code change not flagged: %+v", steps[1])
+	}
+	if !steps[2].Diverged || steps[2].Error == "" || steps[2].ExitCode != -1 {
+		t.Errorf("failed run not flagged: %+v", steps[2])
+	}
+}
+
+func TestReplayCommands_StopOnDivergence(t *testing.T) {
+	runner := &scriptedRunner{exitCodes: map[string]int{"apt-get install -y nginx": 0, "nginx -t": 1}}
+
+	steps, stopped := replayCommands(context.Background(), runner, "sbx-new", replayHistory(), true, nil)
+	if !stopped || len(steps) != 2 || len(runner.ran) != 2 {
+		t.Errorf("steps = %+v, stopped = %v, ran = %q", steps, stopped, runner.ran)
+	}
+}
+
+func TestPrintReplaySummary(t *testing.T) {
+	var buf bytes.Buffer
+	printReplaySummary(&buf, &replayReport{SandboxID: "sbx-new", Steps: make([]replayStep, 2), Diverged: 1, Stopped: true}, 3)
+	out := buf.String()
+	for _, want := range []string{"after 2 of 3 commands", "1 of 2 replayed commands diverged", "deer sandbox destroy sbx-new"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
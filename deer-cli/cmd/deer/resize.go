@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// sandboxResizer is implemented by sandbox services that can change a
+// sandbox's vCPUs and memory (the daemon-backed RemoteService).
+type sandboxResizer interface {
+	ResizeSandbox(ctx context.Context, sandboxID string, vcpus, memoryMB int, leaveStopped bool) (*sandbox.SandboxInfo, error)
+}
+
+func runResize(sandboxID string, vcpus, memoryMB int, noRestart bool) error {
+	if vcpus == 0 && memoryMB == 0 {
+		return fmt.Errorf("--cpu or --memory is required")
+	}
+	if vcpus < 0 || memoryMB < 0 {
+		return fmt.Errorf("--cpu and --memory must be positive")
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	resizer, ok := svc.(sandboxResizer)
+	if !ok {
+		return fmt.Errorf("resizing sandboxes needs a sandbox host; run 'deer connect' first")
+	}
+
+	sb, err := resizer.ResizeSandbox(context.Background(), sandboxID, vcpus, memoryMB, noRestart)
+	if err != nil {
+		return fmt.Errorf("resize sandbox: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, sb)
+	}
+	fmt.Printf("  Resized %s to %d vCPUs, %d MB (%s)\n", sb.ID, sb.VCPUs, sb.MemoryMB, sb.State)
+	return nil
+}
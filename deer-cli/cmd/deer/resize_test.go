@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunResize_Validation(t *testing.T) {
+	if err := runResize("sbx-1", 0, 0, false); err == nil || !strings.Contains(err.Error(), "--cpu or --memory") {
+		t.Errorf("err = %v, want a missing-flag error", err)
+	}
+	if err := runResize("sbx-1", -1, 0, false); err == nil || !strings.Contains(err.Error(), "positive") {
+		t.Errorf("err = %v, want a positive-value error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+// snapshotFileRestorer is implemented by sandbox services that can read a
+// file out of a snapshot (the daemon-backed RemoteService).
+type snapshotFileRestorer interface {
+	RestoreSnapshotFile(ctx context.Context, sandboxID, snapshot, path string, inPlace bool) ([]byte, error)
+}
+
+// restoreFileOptions are the flags of 'sandbox restore-file'.
+type restoreFileOptions struct {
+	Snapshot string
+	Path     string
+	Out      string // local destination; "-" for stdout, empty for the file's base name
+	InPlace  bool
+	Force    bool
+}
+
+func runSandboxRestoreFile(sandboxID string, opts restoreFileOptions) error {
+	if opts.Snapshot == "" || opts.Path == "" {
+		return fmt.Errorf("--snapshot and --path are required")
+	}
+	if !path.IsAbs(opts.Path) {
+		return fmt.Errorf("--path %q must be absolute", opts.Path)
+	}
+	if opts.InPlace && opts.Out != "" {
+		return fmt.Errorf("--in-place and --out cannot be used together")
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	restorer, ok := svc.(snapshotFileRestorer)
+	if !ok {
+		return fmt.Errorf("restoring files from snapshots needs a sandbox host; run 'deer connect' first")
+	}
+
+	content, err := restorer.RestoreSnapshotFile(context.Background(), sandboxID, opts.Snapshot, opts.Path, opts.InPlace)
+	if err != nil {
+		return fmt.Errorf("restore file: %w", err)
+	}
+	if opts.InPlace {
+		fmt.Printf("  Restored %s in %s from snapshot %s\n", opts.Path, sandboxID, opts.Snapshot)
+		return nil
+	}
+
+	out := restoreFileDestination(opts)
+	if out == "-" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	if err := writeRestoredFile(out, content, opts.Force); err != nil {
+		return err
+	}
+	fmt.Printf("  Wrote %s (%d bytes) from snapshot %s\n", out, len(content), opts.Snapshot)
+	return nil
+}
+
+// restoreFileDestination returns where the file is written locally: --out,
+// or the file's base name in the current directory.
+func restoreFileDestination(opts restoreFileOptions) string {
+	if opts.Out != "" {
+		return opts.Out
+	}
+	return path.Base(opts.Path)
+}
+
+// writeRestoredFile writes content to dest, refusing to replace an existing
+// file unless force is set.
+func writeRestoredFile(dest string, content []byte, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(dest, flags, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists; pass --force to overwrite it", dest)
+	}
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dest, err)
+	}
+	if _, err := f.Write(content); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write %s: %w", dest, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestoreFileDestination(t *testing.T) {
+	if got := restoreFileDestination(restoreFileOptions{Path: "/etc/nginx/nginx.conf"}); got != "nginx.conf" {
+		t.Errorf("default destination = %q, want nginx.conf", got)
+	}
+	if got := restoreFileDestination(restoreFileOptions{Path: "/etc/x", Out: "./y"}); got != "./y" {
+		t.Errorf("--out destination = %q, want ./y", got)
+	}
+}
+
+func TestWriteRestoredFile_RefusesOverwrite(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "app.conf")
+	if err := writeRestoredFile(dest, []byte("one"), false); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	err := writeRestoredFile(dest, []byte("two"), false)
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("err = %v, want a hint to pass --force", err)
+	}
+	if err := writeRestoredFile(dest, []byte("two"), true); err != nil {
+		t.Fatalf("forced write: %v", err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "two" {
+		t.Errorf("content = %q, want two", data)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// snapshotManager is implemented by sandbox services that keep a record of
+// snapshots (the daemon-backed RemoteService).
+type snapshotManager interface {
+	ListSnapshots(ctx context.Context, sandboxID string) ([]*sandbox.SnapshotInfo, error)
+	DeleteSnapshot(ctx context.Context, sandboxID, snapshot string) (*sandbox.SnapshotDeletion, error)
+}
+
+// loadSnapshotManager connects to the configured sandbox host. The caller
+// closes the returned service.
+func loadSnapshotManager() (snapshotManager, sandbox.Service, error) {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return nil, nil, fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	mgr, ok := svc.(snapshotManager)
+	if !ok {
+		_ = svc.Close()
+		return nil, nil, fmt.Errorf("managing snapshots needs a sandbox host; run 'deer connect' first")
+	}
+	return mgr, svc, nil
+}
+
+func runSnapshotList(sandboxID string) error {
+	mgr, svc, err := loadSnapshotManager()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = svc.Close() }()
+
+	snaps, err := mgr.ListSnapshots(context.Background(), sandboxID)
+	if err != nil {
+		return fmt.Errorf("list snapshots: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, snaps)
+	}
+	printSnapshots(os.Stdout, snaps)
+	return nil
+}
+
+// printSnapshots writes one row per snapshot. Point-in-time snapshots show
+// "snapshot" as their kind and "-" as their ref.
+func printSnapshots(w io.Writer, snaps []*sandbox.SnapshotInfo) {
+	if len(snaps) == 0 {
+		fmt.Fprintln(w, "  No snapshots found.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tKIND\tREF\tCREATED")
+	for _, snap := range snaps {
+		kind, ref, created := "snapshot", "-", "-"
+		if snap.Kind != "" {
+			kind = snap.Kind
+		}
+		if snap.Ref != "" {
+			ref = snap.Ref
+		}
+		if !snap.CreatedAt.IsZero() {
+			created = snap.CreatedAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", snap.SnapshotName, kind, ref, created)
+	}
+	_ = tw.Flush()
+}
+
+func runSnapshotDelete(sandboxID, snapshot string) error {
+	mgr, svc, err := loadSnapshotManager()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = svc.Close() }()
+
+	res, err := mgr.DeleteSnapshot(context.Background(), sandboxID, snapshot)
+	if err != nil {
+		return fmt.Errorf("delete snapshot: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, res)
+	}
+	printSnapshotDeletion(os.Stdout, res)
+	return nil
+}
+
+// printSnapshotDeletion confirms a delete and notes when only one of the
+// record and the host snapshot was found.
+func printSnapshotDeletion(w io.Writer, res *sandbox.SnapshotDeletion) {
+	fmt.Fprintf(w, "  Deleted snapshot %s of %s\n", res.Snapshot, res.SandboxID)
+	switch {
+	case !res.BackendDeleted:
+		fmt.Fprintln(w, "  Note: no snapshot was found on the host; removed the record only")
+	case !res.RecordDeleted:
+		fmt.Fprintln(w, "  Note: the daemon had no record of it; removed it from the host only")
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestPrintSnapshots(t *testing.T) {
+	var out bytes.Buffer
+	printSnapshots(&out, []*sandbox.SnapshotInfo{
+		{SnapshotName: "before-upgrade", CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{SnapshotName: "archive-20260302-090000", Kind: "archive", Ref: "/var/lib/deer/images/archive-sbx-1.qcow2"},
+	})
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines:\n%s", len(lines), out.String())
+	}
+	if f := strings.Fields(lines[1]); f[0] != "before-upgrade" || f[1] != "snapshot" || f[2] != "-" {
+		t.Errorf("snapshot row = %q", lines[1])
+	}
+	if f := strings.Fields(lines[2]); f[1] != "archive" || f[2] != "/var/lib/deer/images/archive-sbx-1.qcow2" || f[3] != "-" {
+		t.Errorf("archive row = %q", lines[2])
+	}
+
+	out.Reset()
+	printSnapshots(&out, nil)
+	if !strings.Contains(out.String(), "No snapshots found") {
+		t.Errorf("empty output = %q", out.String())
+	}
+}
+
+func TestPrintSnapshotDeletion(t *testing.T) {
+	tests := []struct {
+		res  sandbox.SnapshotDeletion
+		note string
+	}{
+		{res: sandbox.SnapshotDeletion{RecordDeleted: true, BackendDeleted: true}},
+		{res: sandbox.SnapshotDeletion{RecordDeleted: true}, note: "removed the record only"},
+		{res: sandbox.SnapshotDeletion{BackendDeleted: true}, note: "removed it from the host only"},
+	}
+	for _, tt := range tests {
+		tt.res.SandboxID, tt.res.Snapshot = "sbx-1", "snap-1"
+		var out bytes.Buffer
+		printSnapshotDeletion(&out, &tt.res)
+		if !strings.Contains(out.String(), "Deleted snapshot snap-1 of sbx-1") {
+			t.Errorf("output = %q", out.String())
+		}
+		if got := strings.Contains(out.String(), "Note:"); got != (tt.note != "") || !strings.Contains(out.String(), tt.note) {
+			t.Errorf("%+v: output = %q, want note %q", tt.res, out.String(), tt.note)
+		}
+	}
+}
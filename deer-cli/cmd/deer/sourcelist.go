@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sourcekeys"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store/sqlite"
+)
+
+// sourceHost is one row of 'deer source list': a configured source host
+// merged with what the store recorded when it was last prepared. Key is
+// "current" when the host trusts the current source key, "stale" when it
+// trusts another, and empty when no key was recorded.
+type sourceHost struct {
+	Name       string     `json:"name"`
+	Address    string     `json:"address,omitempty"`
+	Prepared   bool       `json:"prepared"`
+	PreparedAt *time.Time `json:"prepared_at,omitempty"`
+	Key        string     `json:"key,omitempty"`
+}
+
+// sourceHostList is the --output payload of 'deer source list'.
+type sourceHostList []sourceHost
+
+func (l sourceHostList) tableHeader() []string {
+	return []string{"NAME", "ADDRESS", "STATUS", "PREPARED", "KEY"}
+}
+
+func (l sourceHostList) tableRows() [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, h := range l {
+		rows = append(rows, []string{h.Name, orDash(h.Address), sourceHostStatus(h), formatPreparedAt(h.PreparedAt), orDash(h.Key)})
+	}
+	return rows
+}
+
+func sourceHostStatus(h sourceHost) string {
+	if h.Prepared {
+		return "ready"
+	}
+	return "not ready"
+}
+
+func formatPreparedAt(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// keyMatch reports whether a recorded fingerprint is the current key's.
+func keyMatch(recorded *string, current string) string {
+	switch {
+	case recorded == nil || current == "":
+		return ""
+	case *recorded == current:
+		return "current"
+	default:
+		return "stale"
+	}
+}
+
+// mergeSourceHosts lists the configured hosts in config order, filled in
+// from their store records, followed by recorded hosts no longer in the
+// config.
+func mergeSourceHosts(hosts []config.HostConfig, svms []*store.SourceVM, currentFP string) sourceHostList {
+	records := make(map[string]*store.SourceVM, len(svms))
+	for _, svm := range svms {
+		records[svm.Name] = svm
+	}
+
+	list := make(sourceHostList, 0, len(hosts)+len(svms))
+	seen := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		seen[h.Name] = true
+		row := sourceHost{Name: h.Name, Address: h.Address, Prepared: h.Prepared}
+		if h.SSHPort != 0 && h.SSHPort != 22 {
+			row.Address = fmt.Sprintf("%s:%d", h.Address, h.SSHPort)
+		}
+		if svm, ok := records[h.Name]; ok {
+			row.PreparedAt = svm.PreparedAt
+			row.Key = keyMatch(svm.CAFingerprint, currentFP)
+		}
+		list = append(list, row)
+	}
+	for _, svm := range svms {
+		if seen[svm.Name] {
+			continue
+		}
+		row := sourceHost{Name: svm.Name, Prepared: svm.Prepared, PreparedAt: svm.PreparedAt, Key: keyMatch(svm.CAFingerprint, currentFP)}
+		if svm.HostAddress != nil {
+			row.Address = *svm.HostAddress
+		}
+		list = append(list, row)
+	}
+	return list
+}
+
+// currentSourceFingerprint returns the fingerprint of the source key, or ""
+// when no key has been generated yet.
+func currentSourceFingerprint(cfg *config.Config) string {
+	pubKey, err := sourcekeys.GetPublicKey(cfg.SSH.SourceKeyDir)
+	if err != nil {
+		return ""
+	}
+	fp, _ := sourcekeys.Fingerprint(pubKey)
+	return fp
+}
+
+func runSourceList() error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	ctx := context.Background()
+	st, err := sqlite.New(ctx, store.Config{AutoMigrate: true})
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	svms, err := st.ListSourceVMs(ctx)
+	if err != nil {
+		return fmt.Errorf("list source VMs: %w", err)
+	}
+	list := mergeSourceHosts(loadedCfg.Hosts, svms, currentSourceFingerprint(loadedCfg))
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, list)
+	}
+
+	if len(list) == 0 {
+		fmt.Println("  No source hosts configured.")
+		fmt.Println("  Run: deer source prepare <hostname>")
+		return nil
+	}
+	printSourceHosts(os.Stdout, list)
+	return nil
+}
+
+// printSourceHosts writes the host table, noting how to fix stale keys.
+func printSourceHosts(w io.Writer, list sourceHostList) {
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "  %-20s %-25s %-10s %-17s %s\n", "NAME", "ADDRESS", "STATUS", "PREPARED", "KEY")
+	_, _ = fmt.Fprintf(w, "  %-20s %-25s %-10s %-17s %s\n", strings.Repeat("-", 20), strings.Repeat("-", 25), strings.Repeat("-", 10), strings.Repeat("-", 17), strings.Repeat("-", 7))
+	stale := 0
+	for _, h := range list {
+		if h.Key == "stale" {
+			stale++
+		}
+		_, _ = fmt.Fprintf(w, "  %-20s %-25s %-10s %-17s %s\n", h.Name, orDash(h.Address), sourceHostStatus(h), formatPreparedAt(h.PreparedAt), orDash(h.Key))
+	}
+	_, _ = fmt.Fprintln(w)
+	if stale > 0 {
+		_, _ = fmt.Fprintf(w, "  %d hosts trust an old source key; re-prepare them with: deer source prepare --all\n\n", stale)
+	}
+}
+
+// sourceStatus is the --output payload of 'deer source status'. Prepare is
+// the recorded outcome of the last preparation.
+type sourceStatus struct {
+	Name        string          `json:"name"`
+	Host        string          `json:"host,omitempty"`
+	Address     string          `json:"address,omitempty"`
+	Prepared    bool            `json:"prepared"`
+	PreparedAt  *time.Time      `json:"prepared_at,omitempty"`
+	Fingerprint string          `json:"fingerprint,omitempty"`
+	Key         string          `json:"key,omitempty"`
+	Prepare     json.RawMessage `json:"prepare,omitempty"`
+}
+
+func newSourceStatus(svm *store.SourceVM, currentFP string) *sourceStatus {
+	s := &sourceStatus{
+		Name:       svm.Name,
+		Prepared:   svm.Prepared,
+		PreparedAt: svm.PreparedAt,
+		Key:        keyMatch(svm.CAFingerprint, currentFP),
+	}
+	if svm.HostName != nil {
+		s.Host = *svm.HostName
+	}
+	if svm.HostAddress != nil {
+		s.Address = *svm.HostAddress
+	}
+	if svm.CAFingerprint != nil {
+		s.Fingerprint = *svm.CAFingerprint
+	}
+	if svm.PrepareJSON != nil && json.Valid([]byte(*svm.PrepareJSON)) {
+		s.Prepare = json.RawMessage(*svm.PrepareJSON)
+	}
+	return s
+}
+
+func runSourceStatus(name string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	ctx := context.Background()
+	st, err := sqlite.New(ctx, store.Config{AutoMigrate: true})
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	svm, err := st.GetSourceVM(ctx, name)
+	if errors.Is(err, store.ErrNotFound) {
+		return fmt.Errorf("no preparation recorded for %s; run: deer source prepare %s", name, name)
+	}
+	if err != nil {
+		return fmt.Errorf("get source VM: %w", err)
+	}
+
+	status := newSourceStatus(svm, currentSourceFingerprint(loadedCfg))
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, status)
+	}
+	printSourceStatus(os.Stdout, status)
+	return nil
+}
+
+// printSourceStatus writes a source host's record and the steps its last
+// preparation completed.
+func printSourceStatus(w io.Writer, s *sourceStatus) {
+	_, _ = fmt.Fprintf(w, "\n  Source:    %s\n", s.Name)
+	if s.Address != "" {
+		_, _ = fmt.Fprintf(w, "  Address:   %s\n", s.Address)
+	}
+	_, _ = fmt.Fprintf(w, "  Status:    %s\n", sourceHostStatus(sourceHost{Prepared: s.Prepared}))
+	_, _ = fmt.Fprintf(w, "  Prepared:  %s\n", formatPreparedAt(s.PreparedAt))
+	if s.Fingerprint != "" {
+		_, _ = fmt.Fprintf(w, "  Key:       %s (%s)\n", s.Fingerprint, orDash(s.Key))
+	}
+	var steps map[string]any
+	if len(s.Prepare) > 0 && json.Unmarshal(s.Prepare, &steps) == nil && len(steps) > 0 {
+		_, _ = fmt.Fprintln(w, "  Last preparation:")
+		for _, k := range slices.Sorted(maps.Keys(steps)) {
+			_, _ = fmt.Fprintf(w, "    %-20s %v\n", k, steps[k])
+		}
+	}
+	if s.Key == "stale" {
+		_, _ = fmt.Fprintf(w, "\n  The host trusts an old source key; re-prepare it with: deer source prepare %s\n", s.Name)
+	}
+	_, _ = fmt.Fprintln(w)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+)
+
+func TestMergeSourceHosts(t *testing.T) {
+	cur, old, addr := "SHA256:new", "SHA256:old", "10.0.0.9"
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hosts := []config.HostConfig{
+		{Name: "web-01", Address: "10.0.0.1", Prepared: true},
+		{Name: "db-01", Address: "10.0.0.2", SSHPort: 2222, Prepared: true},
+		{Name: "new-01", Address: "10.0.0.3"},
+	}
+	svms := []*store.SourceVM{
+		{Name: "db-01", Prepared: true, PreparedAt: &at, CAFingerprint: &old},
+		{Name: "web-01", Prepared: true, PreparedAt: &at, CAFingerprint: &cur},
+		{Name: "gone-01", HostAddress: &addr, Prepared: true, PreparedAt: &at},
+	}
+
+	list := mergeSourceHosts(hosts, svms, cur)
+	if len(list) != 4 {
+		t.Fatalf("len(list) = %d, want 4: %+v", len(list), list)
+	}
+	if list[0].Name != "web-01" || list[0].Key != "current" || list[0].PreparedAt == nil {
+		t.Errorf("list[0] = %+v", list[0])
+	}
+	if list[1].Address != "10.0.0.2:2222" || list[1].Key != "stale" {
+		t.Errorf("list[1] = %+v", list[1])
+	}
+	if list[2].Name != "new-01" || list[2].Prepared || list[2].Key != "" {
+		t.Errorf("list[2] = %+v", list[2])
+	}
+	if list[3].Name != "gone-01" || list[3].Address != "10.0.0.9" || list[3].Key != "" {
+		t.Errorf("list[3] = %+v", list[3])
+	}
+
+	var buf bytes.Buffer
+	printSourceHosts(&buf, list)
+	out := buf.String()
+	for _, want := range []string{"web-01", "current", "stale", "not ready", "1 hosts trust an old source key"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSourceStatus(t *testing.T) {
+	host, addr, fp := "web-01", "10.0.0.1", "SHA256:old"
+	prep := `{"shell_installed":true,"user_created":false,"key_deployed":true}`
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := newSourceStatus(&store.SourceVM{
+		Name: "web-01", HostName: &host, HostAddress: &addr, Prepared: true,
+		PreparedAt: &at, CAFingerprint: &fp, PrepareJSON: &prep,
+	}, "SHA256:new")
+	if s.Key != "stale" || s.Fingerprint != fp || string(s.Prepare) != prep {
+		t.Errorf("status = %+v", s)
+	}
+
+	var buf bytes.Buffer
+	printSourceStatus(&buf, s)
+	out := buf.String()
+	for _, want := range []string{"Source:    web-01", "10.0.0.1", "ready", "SHA256:old (stale)", "key_deployed", "user_created         false", "deer source prepare web-01"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	bad := "not json"
+	s = newSourceStatus(&store.SourceVM{Name: "db-01", PrepareJSON: &bad}, "SHA256:new")
+	if s.Prepare != nil || s.Key != "" {
+		t.Errorf("status with bad JSON = %+v", s)
+	}
+}
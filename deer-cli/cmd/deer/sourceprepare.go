@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/hostexec"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/readonly"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/source"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sourcekeys"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sshconfig"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store/sqlite"
+)
+
+// defaultPrepareConcurrency bounds how many hosts 'source prepare --all'
+// prepares at once.
+const defaultPrepareConcurrency = 4
+
+// sourcePrepareResult is the outcome for one host of 'source prepare --all'.
+// Status is "prepared", "skipped" (read-only access already works), or
+// "failed".
+type sourcePrepareResult struct {
+	Host   string `json:"host"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// prepareHosts runs prepare on every host that probe reports as not yet
+// prepared, at most concurrency at a time, calling onResult as each host
+// finishes. Results are returned in the order of hosts.
+func prepareHosts(ctx context.Context, hosts []string, concurrency int, probe func(ctx context.Context, host string) bool, prepare func(ctx context.Context, host string) error, onResult func(sourcePrepareResult)) []sourcePrepareResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]sourcePrepareResult, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			r := sourcePrepareResult{Host: host, Status: "prepared"}
+			if probe(ctx, host) {
+				r.Status = "skipped"
+			} else if err := prepare(ctx, host); err != nil {
+				r.Status = "failed"
+				r.Error = err.Error()
+			}
+			results[i] = r
+			if onResult != nil {
+				mu.Lock()
+				onResult(r)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// prepareUser returns the user 'source prepare' logs in to host as: user
+// if set (the --user flag), else the host's source_vm_user, else
+// ssh.source_vm_user. Empty leaves the choice to ~/.ssh/config. The user
+// need not be root; preparation runs its steps through sudo otherwise.
+func prepareUser(cfg *config.Config, host, user string) string {
+	if user != "" {
+		return user
+	}
+	for _, h := range cfg.Hosts {
+		if h.Name == host && h.SourceVMUser != "" {
+			return h.SourceVMUser
+		}
+	}
+	return cfg.SSH.SourceVMUser
+}
+
+// prepareSSHRun returns the runner 'source prepare' uses for host, logging
+// in as user when one is set.
+func prepareSSHRun(host, user string) readonly.SSHRunFunc {
+	if user == "" {
+		return readonly.SSHRunFunc(hostexec.NewSSHAlias(host))
+	}
+	return readonly.SSHRunFunc(hostexec.NewSSHAlias(host, "-l", user))
+}
+
+// runSourcePrepareAll prepares every configured source host that does not
+// already accept the deer-readonly key. Unlike a single prepare it never
+// prompts: hosts that already work are skipped.
+func runSourcePrepareAll(concurrency int, sshUser string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if len(loadedCfg.Hosts) == 0 {
+		fmt.Println("  No source hosts configured.")
+		fmt.Println("  Run: deer source prepare <hostname>")
+		return nil
+	}
+	hosts := make([]string, len(loadedCfg.Hosts))
+	for i, h := range loadedCfg.Hosts {
+		hosts[i] = h.Name
+	}
+
+	useColor := os.Getenv("NO_COLOR") == "" && outputFormat == ""
+	green := colorFunc(useColor, "\033[32m")
+	red := colorFunc(useColor, "\033[31m")
+	dim := colorFunc(useColor, "\033[90m")
+
+	// The key pair is shared by every host; create it once, before any
+	// worker needs it.
+	privPath, pubKey, err := sourcekeys.EnsureKeyPair(loadedCfg.SSH.SourceKeyDir)
+	if err != nil {
+		return fmt.Errorf("generate key pair: %w", err)
+	}
+	identityPubKey := config.DaemonIdentityPubKey(loadedCfg.SandboxHosts)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	probe := func(ctx context.Context, host string) bool {
+		probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_, _, code, err := hostexec.NewReadOnlySSHAlias(host, privPath)(probeCtx, "echo ok")
+		return err == nil && code == 0
+	}
+
+	// SavePreparedHost rewrites the whole config file.
+	var saveMu sync.Mutex
+	prepare := func(ctx context.Context, host string) error {
+		resolved, err := sshconfig.Resolve(host)
+		if err != nil {
+			return fmt.Errorf("resolve SSH config: %w", err)
+		}
+		user := prepareUser(loadedCfg, host, sshUser)
+		if user != "" {
+			resolved.User = user
+		}
+		sshRun := prepareSSHRun(host, user)
+		prepCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		result, err := readonly.PrepareWithKey(prepCtx, sshRun, pubKey, nil, logger)
+		if err != nil {
+			return err
+		}
+
+		saveMu.Lock()
+		err = source.SavePreparedHost(loadedCfg, configPath, host, resolved)
+		if err == nil {
+			if recErr := recordPreparedHost(loadedCfg, host, pubKey, result); recErr != nil {
+				fmt.Fprintf(os.Stderr, "  Warning: could not record %s in the store: %v\n", host, recErr)
+			}
+		}
+		saveMu.Unlock()
+		if err != nil {
+			return fmt.Errorf("saving config after prepare: %w", err)
+		}
+
+		if identityPubKey != "" {
+			deployCtx, deployCancel := context.WithTimeout(ctx, 30*time.Second)
+			defer deployCancel()
+			if err := readonly.DeployDaemonKey(deployCtx, sshRun, identityPubKey, logger); err != nil {
+				return fmt.Errorf("deploy daemon key: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var onResult func(sourcePrepareResult)
+	if outputFormat == "" {
+		fmt.Printf("  Preparing %d source hosts (%d at a time)...\n", len(hosts), max(concurrency, 1))
+		onResult = func(r sourcePrepareResult) {
+			switch r.Status {
+			case "prepared":
+				fmt.Printf("  %s %s\n", green("[ok]"), r.Host)
+			case "skipped":
+				fmt.Printf("  %s %s %s\n", dim("[skip]"), r.Host, dim("already prepared"))
+			default:
+				fmt.Printf("  %s %s: %s\n", red("[error]"), r.Host, r.Error)
+			}
+		}
+	}
+
+	results := prepareHosts(context.Background(), hosts, concurrency, probe, prepare, onResult)
+	failed := countStatus(results, "failed")
+
+	for _, r := range results {
+		if r.Status != "skipped" {
+			continue
+		}
+		if err := recordPreparedHost(loadedCfg, r.Host, pubKey, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "  Warning: could not record %s in the store: %v\n", r.Host, err)
+		}
+	}
+
+	if outputFormat != "" {
+		if err := writeOutput(os.Stdout, results); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		fmt.Printf("  %d prepared, %d skipped, %d failed.\n", countStatus(results, "prepared"), countStatus(results, "skipped"), failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d source hosts failed to prepare", failed, len(results))
+	}
+	return nil
+}
+
+// recordPreparedHost notes in the store which source key host now trusts
+// and the outcome of preparing it (nil when it already accepted the key), for
+// 'deer source list' and 'deer source status'.
+func recordPreparedHost(cfg *config.Config, host, pubKey string, result any) error {
+	ctx := context.Background()
+	st, err := sqlite.New(ctx, store.Config{AutoMigrate: true})
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	var addr string
+	for _, h := range cfg.Hosts {
+		if h.Name == host {
+			addr = h.Address
+		}
+	}
+	return source.RecordPreparedHost(ctx, st, host, addr, pubKey, result)
+}
+
+func countStatus(results []sourcePrepareResult, status string) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+func TestPrepareHosts(t *testing.T) {
+	prepared := map[string]bool{"web-1": true}
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	var seen []string
+
+	probe := func(_ context.Context, host string) bool { return prepared[host] }
+	prepare := func(_ context.Context, host string) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if host == "db-1" {
+			return errors.New("sudo: a password is required")
+		}
+		return nil
+	}
+	onResult := func(r sourcePrepareResult) {
+		mu.Lock()
+		seen = append(seen, r.Host)
+		mu.Unlock()
+	}
+
+	hosts := []string{"web-1", "web-2", "db-1", "web-3", "web-4"}
+	results := prepareHosts(context.Background(), hosts, 2, probe, prepare, onResult)
+
+	want := []sourcePrepareResult{
+		{Host: "web-1", Status: "skipped"},
+		{Host: "web-2", Status: "prepared"},
+		{Host: "db-1", Status: "failed", Error: "sudo: a password is required"},
+		{Host: "web-3", Status: "prepared"},
+		{Host: "web-4", Status: "prepared"},
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("results[%d] = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d hosts prepared at once, want at most 2", p)
+	}
+	if len(seen) != len(hosts) {
+		t.Errorf("onResult called for %v", seen)
+	}
+}
+
+func TestPrepareUser(t *testing.T) {
+	cfg := &config.Config{
+		SSH: config.SSHConfig{SourceVMUser: "admin"},
+		Hosts: []config.HostConfig{
+			{Name: "golden-ubuntu", SourceVMUser: "ubuntu"},
+			{Name: "web-1"},
+		},
+	}
+	tests := []struct {
+		host, flag, want string
+	}{
+		{"golden-ubuntu", "debian", "debian"},
+		{"golden-ubuntu", "", "ubuntu"},
+		{"web-1", "", "admin"},
+		{"unknown", "", "admin"},
+	}
+	for _, tt := range tests {
+		if got := prepareUser(cfg, tt.host, tt.flag); got != tt.want {
+			t.Errorf("prepareUser(%q, %q) = %q, want %q", tt.host, tt.flag, got, tt.want)
+		}
+	}
+
+	cfg.SSH.SourceVMUser = ""
+	if got := prepareUser(cfg, "web-1", ""); got != "" {
+		t.Errorf("prepareUser with nothing configured = %q, want empty (use ~/.ssh/config)", got)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/source"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sourcekeys"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store/sqlite"
+)
+
+// staleSourceVM is one row of 'deer source list --stale'. Fingerprint is
+// the key the host was prepared with; empty when it was never recorded.
+type staleSourceVM struct {
+	Name        string     `json:"name"`
+	Host        string     `json:"host,omitempty"`
+	PreparedAt  *time.Time `json:"prepared_at,omitempty"`
+	Fingerprint string     `json:"fingerprint,omitempty"`
+}
+
+// staleSourceVMList is the --output payload of 'deer source list --stale'.
+type staleSourceVMList []staleSourceVM
+
+func (l staleSourceVMList) tableHeader() []string {
+	return []string{"NAME", "HOST", "PREPARED", "FINGERPRINT"}
+}
+
+func (l staleSourceVMList) tableRows() [][]string {
+	rows := make([][]string, 0, len(l))
+	for _, r := range l {
+		prepared := "-"
+		if r.PreparedAt != nil {
+			prepared = r.PreparedAt.UTC().Format(time.RFC3339)
+		}
+		rows = append(rows, []string{r.Name, orDash(r.Host), prepared, orDash(r.Fingerprint)})
+	}
+	return rows
+}
+
+func staleSourceVMs(svms []*store.SourceVM) staleSourceVMList {
+	out := make(staleSourceVMList, 0, len(svms))
+	for _, svm := range svms {
+		row := staleSourceVM{Name: svm.Name, PreparedAt: svm.PreparedAt}
+		switch {
+		case svm.HostAddress != nil:
+			row.Host = *svm.HostAddress
+		case svm.HostName != nil:
+			row.Host = *svm.HostName
+		}
+		if svm.CAFingerprint != nil {
+			row.Fingerprint = *svm.CAFingerprint
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+func runSourceListStale() error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	pubKey, err := sourcekeys.GetPublicKey(loadedCfg.SSH.SourceKeyDir)
+	if err != nil {
+		return fmt.Errorf("no source key yet (run 'deer source prepare' first): %w", err)
+	}
+	current, err := sourcekeys.Fingerprint(pubKey)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	st, err := sqlite.New(ctx, store.Config{AutoMigrate: true})
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	svms, err := source.ListStaleSourceVMs(ctx, st, current)
+	if err != nil {
+		return err
+	}
+	rows := staleSourceVMs(svms)
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, rows)
+	}
+	printStaleSourceVMs(os.Stdout, rows, current)
+	return nil
+}
+
+// printStaleSourceVMs writes the stale hosts as a table under the current
+// key's fingerprint, with the command that fixes them.
+func printStaleSourceVMs(w io.Writer, rows staleSourceVMList, current string) {
+	_, _ = fmt.Fprintf(w, "\n  Current source key: %s\n\n", current)
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(w, "  No stale source hosts: every recorded host trusts the current key.")
+		_, _ = fmt.Fprintln(w)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "  %-20s %-25s %-20s %s\n", "NAME", "HOST", "PREPARED", "PREPARED WITH")
+	for _, r := range rows {
+		prepared := "-"
+		if r.PreparedAt != nil {
+			prepared = r.PreparedAt.Local().Format("2006-01-02 15:04")
+		}
+		fp := r.Fingerprint
+		if fp == "" {
+			fp = "(not recorded)"
+		}
+		_, _ = fmt.Fprintf(w, "  %-20s %-25s %-20s %s\n", r.Name, orDash(r.Host), prepared, fp)
+	}
+	_, _ = fmt.Fprintf(w, "\n  Re-prepare them with: deer source prepare <name> (or --all)\n\n")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+)
+
+func TestStaleSourceVMs(t *testing.T) {
+	name, addr, fp := "web-01", "10.0.0.1", "SHA256:old"
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rows := staleSourceVMs([]*store.SourceVM{
+		{Name: "web-01", HostName: &name, HostAddress: &addr, PreparedAt: &at, CAFingerprint: &fp},
+		{Name: "legacy-01", HostName: &name},
+	})
+	if rows[0].Host != "10.0.0.1" || rows[0].Fingerprint != "SHA256:old" || rows[0].PreparedAt != &at {
+		t.Errorf("rows[0] = %+v", rows[0])
+	}
+	if rows[1].Host != "web-01" || rows[1].Fingerprint != "" {
+		t.Errorf("rows[1] = %+v", rows[1])
+	}
+
+	var buf bytes.Buffer
+	printStaleSourceVMs(&buf, rows, "SHA256:new")
+	out := buf.String()
+	for _, want := range []string{"Current source key: SHA256:new", "web-01", "10.0.0.1", "SHA256:old", "(not recorded)", "deer source prepare"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printStaleSourceVMs(&buf, nil, "SHA256:new")
+	if !strings.Contains(buf.String(), "No stale source hosts") {
+		t.Errorf("empty output = %q", buf.String())
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// sshRenewer is implemented by sandbox services backed by a daemon that
+// issues sandbox SSH certificates (the RemoteService).
+type sshRenewer interface {
+	RenewSSHCredentials(ctx context.Context, sandboxID string) (*sandbox.SSHCredentials, error)
+}
+
+func runSSHRenew(sandboxID string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	renewer, ok := svc.(sshRenewer)
+	if !ok {
+		return fmt.Errorf("renewing SSH credentials needs a sandbox host; run 'deer connect' first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	creds, err := renewer.RenewSSHCredentials(ctx, sandboxID)
+	if err != nil {
+		return fmt.Errorf("renew SSH credentials: %w", err)
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, creds)
+	}
+	printSSHCredentials(os.Stdout, creds, time.Now())
+	return nil
+}
+
+// printSSHCredentials reports where the new key and certificate live and
+// how long the certificate is good for.
+func printSSHCredentials(w io.Writer, creds *sandbox.SSHCredentials, now time.Time) {
+	fmt.Fprintf(w, "  Renewed SSH credentials for %s (user %s)\n", creds.SandboxID, creds.Username)
+	fmt.Fprintf(w, "  Key:         %s\n", creds.PrivateKeyPath)
+	fmt.Fprintf(w, "  Certificate: %s\n", creds.CertificatePath)
+	if !creds.ValidUntil.IsZero() {
+		fmt.Fprintf(w, "  Valid until: %s (%s from now)\n", creds.ValidUntil.Format(time.RFC3339), creds.ValidUntil.Sub(now).Round(time.Second))
+	}
+}
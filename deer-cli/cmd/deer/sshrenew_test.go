@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestPrintSSHCredentials(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	printSSHCredentials(&buf, &sandbox.SSHCredentials{
+		SandboxID:       "SBX-1",
+		Username:        "sandbox",
+		PrivateKeyPath:  "/keys/SBX-1",
+		CertificatePath: "/keys/SBX-1-cert.pub",
+		ValidUntil:      now.Add(30 * time.Minute),
+	}, now)
+	out := buf.String()
+	for _, want := range []string{"SBX-1 (user sandbox)", "Key:         /keys/SBX-1\n", "Certificate: /keys/SBX-1-cert.pub", "2026-03-01T12:30:00Z (30m0s from now)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats <sandbox_id>",
+	Short: "Show a sandbox's CPU, memory and disk usage",
+	Long: `Show what a sandbox is actually using - CPU, resident memory and the space
+its disk takes on the host - as opposed to the shape it was created with. With
+--watch the numbers refresh on an interval and CPU is shown as a percentage of
+one vCPU over the last interval, which makes a runaway process easy to spot.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		return runStats(args[0], watch, interval)
+	},
+}
+
+// statsReader is implemented by sandbox services backed by a daemon that
+// can sample sandbox resource usage (the RemoteService).
+type statsReader interface {
+	GetSandboxStats(ctx context.Context, sandboxID string) (*sandbox.SandboxStats, error)
+}
+
+func runStats(sandboxID string, watch bool, interval time.Duration) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	reader, ok := svc.(statsReader)
+	if !ok {
+		return fmt.Errorf("sandbox stats need a sandbox host; run 'deer connect' first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !watch {
+		stats, err := reader.GetSandboxStats(ctx, sandboxID)
+		if err != nil {
+			return fmt.Errorf("get sandbox stats: %w", err)
+		}
+		if outputFormat != "" {
+			return writeOutput(os.Stdout, stats)
+		}
+		printSandboxStats(os.Stdout, stats, nil)
+		return nil
+	}
+
+	var prev *sandbox.SandboxStats
+	title := "deer stats " + sandboxID
+	return watchLoop(ctx, interval, func(ctx context.Context) (bool, error) {
+		stats, err := reader.GetSandboxStats(ctx, sandboxID)
+		if err != nil {
+			return true, fmt.Errorf("get sandbox stats: %w", err)
+		}
+		writeWatchHeader(os.Stdout, interval, title)
+		printSandboxStats(os.Stdout, stats, prev)
+		prev = stats
+		return false, nil
+	})
+}
+
+// cpuPercent is the CPU a sandbox used between two samples, as a percentage
+// of one vCPU. Without a previous sample it falls back to the provider's own
+// figure. ok is false when neither is available.
+func cpuPercent(cur, prev *sandbox.SandboxStats) (pct float64, ok bool) {
+	if prev != nil && cur.CPUTimeMS > 0 && cur.CPUTimeMS >= prev.CPUTimeMS {
+		elapsed := cur.SampledAt.Sub(prev.SampledAt)
+		if elapsed > 0 {
+			used := time.Duration(cur.CPUTimeMS-prev.CPUTimeMS) * time.Millisecond
+			return float64(used) / float64(elapsed) * 100, true
+		}
+	}
+	if cur.CPUPercent > 0 {
+		return cur.CPUPercent, true
+	}
+	return 0, false
+}
+
+// printSandboxStats writes one sample. prev is the previous sample in
+// --watch mode, used to turn cumulative CPU time into a percentage.
+func printSandboxStats(w io.Writer, s, prev *sandbox.SandboxStats) {
+	_, _ = fmt.Fprintf(w, "\n  Sandbox:  %s (%s)\n", s.SandboxID, orDash(s.State))
+
+	cpu := "-"
+	if pct, ok := cpuPercent(s, prev); ok {
+		cpu = fmt.Sprintf("%.1f%%", pct)
+		if s.VCPUs > 0 {
+			cpu += fmt.Sprintf(" (%d vCPUs, max %d%%)", s.VCPUs, s.VCPUs*100)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "  CPU:      %s\n", cpu)
+	if s.CPUTimeMS > 0 {
+		_, _ = fmt.Fprintf(w, "  CPU time: %s\n", (time.Duration(s.CPUTimeMS) * time.Millisecond).Round(time.Second))
+	}
+
+	_, _ = fmt.Fprintf(w, "  Memory:   %s\n", usageOf(s.MemoryBytes, s.MemoryLimitBytes))
+	_, _ = fmt.Fprintf(w, "  Disk:     %s\n\n", usageOf(s.DiskAllocatedBytes, s.DiskCapacityBytes))
+}
+
+// usageOf renders used against limit, e.g. "512.0 MiB / 2.0 GiB (25%)".
+func usageOf(used, limit int64) string {
+	switch {
+	case used <= 0 && limit <= 0:
+		return "-"
+	case limit <= 0:
+		return formatBytes(used)
+	default:
+		return fmt.Sprintf("%s / %s (%.0f%%)", formatBytes(used), formatBytes(limit), float64(used)/float64(limit)*100)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestCPUPercent(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	prev := &sandbox.SandboxStats{SampledAt: t0, CPUTimeMS: 10_000}
+	cur := &sandbox.SandboxStats{SampledAt: t0.Add(2 * time.Second), CPUTimeMS: 13_000}
+
+	if pct, ok := cpuPercent(cur, prev); !ok || pct != 150 {
+		t.Errorf("cpuPercent = %v, %v; want 150, true", pct, ok)
+	}
+	if _, ok := cpuPercent(cur, nil); ok {
+		t.Error("single sample without a provider figure should have no CPU percent")
+	}
+	lxc := &sandbox.SandboxStats{CPUPercent: 42}
+	if pct, ok := cpuPercent(lxc, nil); !ok || pct != 42 {
+		t.Errorf("provider figure = %v, %v; want 42, true", pct, ok)
+	}
+}
+
+func TestPrintSandboxStats(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	prev := &sandbox.SandboxStats{SampledAt: t0, CPUTimeMS: 60_000}
+	s := &sandbox.SandboxStats{
+		SandboxID: "SBX-1", State: "RUNNING", SampledAt: t0.Add(2 * time.Second), VCPUs: 2,
+		CPUTimeMS: 63_800, MemoryBytes: 512 << 20, MemoryLimitBytes: 2048 << 20, DiskAllocatedBytes: 3 << 30,
+	}
+
+	var buf bytes.Buffer
+	printSandboxStats(&buf, s, prev)
+	out := buf.String()
+	for _, want := range []string{"SBX-1 (RUNNING)", "190.0% (2 vCPUs, max 200%)", "CPU time: 1m4s", "512.0 MiB / 2.0 GiB (25%)", "Disk:     3.0 GiB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printSandboxStats(&buf, &sandbox.SandboxStats{SandboxID: "SBX-2", State: "STOPPED"}, nil)
+	if !strings.Contains(buf.String(), "CPU:      -") || !strings.Contains(buf.String(), "Memory:   -") {
+		t.Errorf("stopped output:\n%s", buf.String())
+	}
+}
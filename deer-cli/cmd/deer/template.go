@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage sandbox creation templates",
+	Long:  "Save named presets of sandbox creation parameters in the config file and use them with 'deer sandbox create --template <name>'.",
+}
+
+var templateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create or replace a template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tmpl := config.SandboxTemplate{Name: args[0]}
+		tmpl.SourceVM, _ = cmd.Flags().GetString("source-vm")
+		tmpl.CPU, _ = cmd.Flags().GetInt("cpu")
+		tmpl.MemoryMB, _ = cmd.Flags().GetInt("memory")
+		tmpl.Live, _ = cmd.Flags().GetBool("live")
+		tmpl.KafkaStub, _ = cmd.Flags().GetBool("kafka-stub")
+		tmpl.ESStub, _ = cmd.Flags().GetBool("es-stub")
+		return runTemplateCreate(tmpl)
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTemplateList()
+	},
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTemplateDelete(args[0])
+	},
+}
+
+// applyTemplate fills req from tmpl. Values whose flag was set explicitly on
+// the command line, and a source VM given as an argument, are kept.
+func applyTemplate(tmpl config.SandboxTemplate, req sandbox.CreateRequest, explicit func(flag string) bool) sandbox.CreateRequest {
+	if req.SourceVM == "" {
+		req.SourceVM = tmpl.SourceVM
+	}
+	if !explicit("cpu") {
+		req.VCPUs = tmpl.CPU
+	}
+	if !explicit("memory") {
+		req.MemoryMB = tmpl.MemoryMB
+	}
+	if !explicit("live") {
+		req.Live = tmpl.Live
+	}
+	if !explicit("kafka-stub") {
+		req.SimpleKafkaBroker = tmpl.KafkaStub
+	}
+	if !explicit("es-stub") {
+		req.SimpleElasticsearchBroker = tmpl.ESStub
+	}
+	return req
+}
+
+func runTemplateCreate(tmpl config.SandboxTemplate) error {
+	if tmpl.SourceVM == "" {
+		return fmt.Errorf("--source-vm is required")
+	}
+
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	_, existed := loadedCfg.Template(tmpl.Name)
+	loadedCfg.UpsertTemplate(tmpl)
+	if err := loadedCfg.Save(configPath); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+
+	if existed {
+		fmt.Printf("  Updated template %s\n", tmpl.Name)
+	} else {
+		fmt.Printf("  Created template %s\n", tmpl.Name)
+	}
+	return nil
+}
+
+func runTemplateList() error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if outputFormat != "" {
+		templates := loadedCfg.Templates
+		if templates == nil {
+			templates = []config.SandboxTemplate{}
+		}
+		return writeOutput(os.Stdout, templates)
+	}
+
+	if len(loadedCfg.Templates) == 0 {
+		fmt.Println("  No templates defined. Create one with 'deer template create <name> --source-vm <vm>'.")
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("  %-15s %-20s %-5s %-8s %s\n", "NAME", "SOURCE VM", "CPU", "MEMORY", "OPTIONS")
+	fmt.Printf("  %-15s %-20s %-5s %-8s %s\n", strings.Repeat("-", 15), strings.Repeat("-", 20), strings.Repeat("-", 5), strings.Repeat("-", 8), strings.Repeat("-", 10))
+	for _, t := range loadedCfg.Templates {
+		fmt.Printf("  %-15s %-20s %-5s %-8s %s\n", t.Name, t.SourceVM, orDefault(t.CPU, ""), orDefault(t.MemoryMB, "MB"), templateOptions(t))
+	}
+	fmt.Println()
+	return nil
+}
+
+func runTemplateDelete(name string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if !loadedCfg.RemoveTemplate(name) {
+		return fmt.Errorf("template %q not found", name)
+	}
+	if err := loadedCfg.Save(configPath); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	fmt.Printf("  Deleted template %s\n", name)
+	return nil
+}
+
+// orDefault formats n with unit, or "default" when n is unset.
+func orDefault(n int, unit string) string {
+	if n <= 0 {
+		return "default"
+	}
+	return fmt.Sprintf("%d%s", n, unit)
+}
+
+// templateOptions lists the boolean options enabled on a template.
+func templateOptions(t config.SandboxTemplate) string {
+	var opts []string
+	if t.Live {
+		opts = append(opts, "live")
+	}
+	if t.KafkaStub {
+		opts = append(opts, "kafka-stub")
+	}
+	if t.ESStub {
+		opts = append(opts, "es-stub")
+	}
+	if len(opts) == 0 {
+		return "-"
+	}
+	return strings.Join(opts, ",")
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestApplyTemplate(t *testing.T) {
+	tmpl := config.SandboxTemplate{Name: "web", SourceVM: "ubuntu", CPU: 4, MemoryMB: 8192, KafkaStub: true}
+
+	got := applyTemplate(tmpl, sandbox.CreateRequest{}, func(string) bool { return false })
+	if got.SourceVM != "ubuntu" || got.VCPUs != 4 || got.MemoryMB != 8192 || !got.SimpleKafkaBroker {
+		t.Errorf("template not applied: %+v", got)
+	}
+
+	// Explicit flags and a source VM argument win over the preset.
+	explicit := map[string]bool{"memory": true, "kafka-stub": true}
+	got = applyTemplate(tmpl, sandbox.CreateRequest{SourceVM: "debian", MemoryMB: 2048}, func(f string) bool { return explicit[f] })
+	if got.SourceVM != "debian" {
+		t.Errorf("SourceVM = %q, want the argument to win", got.SourceVM)
+	}
+	if got.MemoryMB != 2048 || got.VCPUs != 4 {
+		t.Errorf("MemoryMB/VCPUs = %d/%d, want 2048/4", got.MemoryMB, got.VCPUs)
+	}
+	if got.SimpleKafkaBroker {
+		t.Error("--kafka-stub=false should override the template")
+	}
+}
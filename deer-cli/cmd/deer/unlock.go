@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+// sandboxUnlocker is implemented by sandbox services whose daemon keeps
+// advisory sandbox locks (the daemon-backed RemoteService).
+type sandboxUnlocker interface {
+	UnlockSandbox(ctx context.Context, sandboxID string) (string, error)
+}
+
+func runSandboxUnlock(sandboxID string) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	unlocker, ok := svc.(sandboxUnlocker)
+	if !ok {
+		return fmt.Errorf("unlocking a sandbox needs a sandbox host; run 'deer connect' first")
+	}
+
+	holder, err := unlocker.UnlockSandbox(context.Background(), sandboxID)
+	if err != nil {
+		return fmt.Errorf("unlock sandbox: %w", err)
+	}
+	if holder == "" {
+		fmt.Printf("  Sandbox %s was not locked\n", sandboxID)
+		return nil
+	}
+	fmt.Printf("  Cleared the lock %s held on %s\n", holder, sandboxID)
+	return nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// issueExplanation is the --explain form of a validation issue: what went
+// wrong, which check found it, and commands that usually fix it.
+type issueExplanation struct {
+	Code        string   `json:"code"`
+	Severity    string   `json:"severity"`
+	Detail      string   `json:"detail"`
+	Cause       string   `json:"cause"`
+	Check       string   `json:"check"`
+	Remediation []string `json:"remediation,omitempty"`
+}
+
+// explainedValidation is the structured output of 'source validate --explain'.
+type explainedValidation struct {
+	*sandbox.ValidationInfo
+	Explanations []issueExplanation `json:"explanations"`
+}
+
+// issueGuide holds the explanation for one issue code. Remediation entries
+// may contain {vm}, which is replaced with the validated VM's name.
+type issueGuide struct {
+	cause       string
+	check       string
+	remediation []string
+}
+
+// issueGuides maps the daemon's validation issue codes to explanations.
+// Commands are given for both libvirt (virsh) and Proxmox LXC (pct) hosts.
+var issueGuides = map[string]issueGuide{
+	"VM_NOT_FOUND": {
+		cause: "The host has no VM or container with this name.",
+		check: "Looked up the name with 'virsh domstate' (libvirt) or the Proxmox CT list (LXC).",
+		remediation: []string{
+			"virsh list --all",
+			"pct list",
+		},
+	},
+	"VM_NOT_RUNNING": {
+		cause: "The VM exists but is stopped, so its network and SSH cannot be checked.",
+		check: "Read the VM state and required it to be 'running'.",
+		remediation: []string{
+			"virsh start {vm}",
+			"pct start <vmid>",
+		},
+	},
+	"STATUS_UNAVAILABLE": {
+		cause: "The host could not report the VM's status.",
+		check: "Queried the Proxmox API for the CT status.",
+		remediation: []string{
+			"pct status <vmid>",
+			"deer doctor --host <host>",
+		},
+	},
+	"CONFIG_UNREADABLE": {
+		cause: "The VM's configuration could not be read, so its network interfaces are unknown.",
+		check: "Read the CT config from the Proxmox API.",
+		remediation: []string{
+			"pct config <vmid>",
+		},
+	},
+	"NO_NETWORK_INTERFACE": {
+		cause: "The VM has no network interface, so sandboxes cloned from it cannot be reached.",
+		check: "Looked for a net0 entry in the CT config.",
+		remediation: []string{
+			"pct set <vmid> -net0 name=eth0,bridge=vmbr0,ip=dhcp",
+			"virsh attach-interface {vm} network default --model virtio --config --live",
+		},
+	},
+	"NO_MAC_ADDRESS": {
+		cause: "No MAC address was found on the VM's interfaces.",
+		check: "Parsed 'virsh domiflist' for an interface MAC.",
+		remediation: []string{
+			"virsh domiflist {vm}",
+			"virsh attach-interface {vm} network default --model virtio --config --live",
+		},
+	},
+	"NO_IP_ADDRESS": {
+		cause: "The VM has no IP address yet: DHCP has not answered, or the guest agent is not running.",
+		check: "Asked the DHCP leases, then the guest agent, for the VM's address.",
+		remediation: []string{
+			"virsh domifaddr {vm} --source lease",
+			"virsh domifaddr {vm} --source agent",
+			"pct exec <vmid> -- ip -4 addr",
+		},
+	},
+	"READONLY_SSH_FAILED": {
+		cause: "SSH as deer-readonly did not succeed; the VM has probably not been prepared.",
+		check: "Ran 'whoami' over SSH as deer-readonly with the daemon's certificate.",
+		remediation: []string{
+			"deer source prepare <host>",
+		},
+	},
+	"CREDENTIALS_UNAVAILABLE": {
+		cause: "The daemon could not issue SSH credentials for the read-only check.",
+		check: "Requested a source VM certificate from the daemon's key manager.",
+		remediation: []string{
+			"deer doctor --host <host>",
+		},
+	},
+}
+
+// explainIssues builds the --explain output for each issue. Unknown codes are
+// kept with a generic cause so newer daemons still produce useful output.
+func explainIssues(vmName string, issues []sandbox.ValidationIssue) []issueExplanation {
+	out := make([]issueExplanation, 0, len(issues))
+	for _, is := range issues {
+		ex := issueExplanation{Code: is.Code, Severity: is.Severity, Detail: is.Detail}
+		guide, ok := issueGuides[is.Code]
+		if !ok {
+			ex.Cause = "Reported by the daemon; this version of deer has no explanation for it."
+			ex.Check = "See the detail message."
+			out = append(out, ex)
+			continue
+		}
+		ex.Cause = guide.cause
+		ex.Check = guide.check
+		for _, r := range guide.remediation {
+			ex.Remediation = append(ex.Remediation, strings.ReplaceAll(r, "{vm}", vmName))
+		}
+		out = append(out, ex)
+	}
+	return out
+}
+
+func runSourceValidate(vmName string, explain bool) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	info, err := svc.ValidateSourceVM(context.Background(), vmName)
+	if err != nil {
+		return fmt.Errorf("validate source VM: %w", err)
+	}
+
+	if explain {
+		out := &explainedValidation{ValidationInfo: info, Explanations: explainIssues(vmName, info.Issues)}
+		if outputFormat != "" {
+			return writeOutput(os.Stdout, out)
+		}
+		printValidation(os.Stdout, info)
+		printExplanations(os.Stdout, out.Explanations)
+		return nil
+	}
+
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, info)
+	}
+	printValidation(os.Stdout, info)
+	return nil
+}
+
+// printValidation writes the terse validation summary.
+func printValidation(w io.Writer, info *sandbox.ValidationInfo) {
+	status := "valid"
+	if !info.Valid {
+		status = "invalid"
+	}
+	fmt.Fprintf(w, "  %s: %s\n", info.VMName, status)
+	if info.State != "" {
+		fmt.Fprintf(w, "  State: %s\n", info.State)
+	}
+	if info.IPAddress != "" {
+		fmt.Fprintf(w, "  IP:    %s\n", info.IPAddress)
+	}
+	for _, e := range info.Errors {
+		fmt.Fprintf(w, "  ERROR: %s\n", e)
+	}
+	for _, warn := range info.Warnings {
+		fmt.Fprintf(w, "  WARN:  %s\n", warn)
+	}
+}
+
+// printExplanations writes one block per issue with its cause, check, and
+// suggested commands.
+func printExplanations(w io.Writer, explanations []issueExplanation) {
+	for _, ex := range explanations {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  [%s] %s (%s)\n", ex.Code, ex.Detail, ex.Severity)
+		fmt.Fprintf(w, "    Cause: %s\n", ex.Cause)
+		fmt.Fprintf(w, "    Check: %s\n", ex.Check)
+		if len(ex.Remediation) > 0 {
+			fmt.Fprintln(w, "    Fix:")
+			for _, r := range ex.Remediation {
+				fmt.Fprintf(w, "      %s\n", r)
+			}
+		}
+	}
+}
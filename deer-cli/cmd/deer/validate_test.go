@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestExplainIssues(t *testing.T) {
+	got := explainIssues("web-01", []sandbox.ValidationIssue{
+		{Code: "NO_NETWORK_INTERFACE", Severity: "warning", Detail: "CT has no network interface (net0)"},
+		{Code: "SOMETHING_NEW", Severity: "error", Detail: "new check failed"},
+	})
+	if len(got) != 2 {
+		t.Fatalf("got %d explanations, want 2", len(got))
+	}
+	if got[0].Cause == "" || got[0].Check == "" {
+		t.Errorf("missing cause or check: %+v", got[0])
+	}
+	found := false
+	for _, r := range got[0].Remediation {
+		if strings.Contains(r, "virsh attach-interface web-01 ") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("remediation does not name the VM: %v", got[0].Remediation)
+	}
+	if got[1].Code != "SOMETHING_NEW" || got[1].Cause == "" || len(got[1].Remediation) != 0 {
+		t.Errorf("unknown code explanation = %+v", got[1])
+	}
+}
+
+func TestExplainedValidation_JSON(t *testing.T) {
+	info := &sandbox.ValidationInfo{VMName: "web-01", State: "stopped", Issues: []sandbox.ValidationIssue{
+		{Code: "VM_NOT_RUNNING", Severity: "error", Detail: "VM is not running"},
+	}}
+	data, err := json.Marshal(&explainedValidation{ValidationInfo: info, Explanations: explainIssues("web-01", info.Issues)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["vm_name"] != "web-01" {
+		t.Errorf("vm_name = %v, want web-01", decoded["vm_name"])
+	}
+	ex, _ := decoded["explanations"].([]any)
+	if len(ex) != 1 {
+		t.Fatalf("explanations = %v", decoded["explanations"])
+	}
+	if fix := ex[0].(map[string]any)["remediation"]; !strings.Contains(toJSON(t, fix), "virsh start web-01") {
+		t.Errorf("remediation = %v", fix)
+	}
+}
+
+func TestPrintValidation_TerseByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	printValidation(&buf, &sandbox.ValidationInfo{VMName: "web-01", Errors: []string{"VM is not running"}})
+	out := buf.String()
+	if !strings.Contains(out, "web-01: invalid") || !strings.Contains(out, "ERROR: VM is not running") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if strings.Contains(out, "Cause:") {
+		t.Errorf("terse output includes explanations:\n%s", out)
+	}
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
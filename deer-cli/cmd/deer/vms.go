@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store/sqlite"
+)
+
+// vmInventory is the output of 'deer vms'. CacheAgeSeconds is how old the
+// listing was when served; it is 0 for a listing fetched just now.
+type vmInventory struct {
+	VMs             []*sandbox.VMInfo `json:"vms"`
+	CachedAt        time.Time         `json:"cached_at"`
+	CacheAgeSeconds int64             `json:"cache_age_seconds"`
+	FromCache       bool              `json:"from_cache"`
+}
+
+func (inv *vmInventory) tableHeader() []string {
+	return []string{"NAME", "STATE", "IP", "PREPARED", "HOST"}
+}
+
+func (inv *vmInventory) tableRows() [][]string {
+	rows := make([][]string, 0, len(inv.VMs))
+	for _, vm := range inv.VMs {
+		prepared := "no"
+		if vm.Prepared {
+			prepared = "yes"
+		}
+		rows = append(rows, []string{vm.Name, vm.State, orDash(vm.IPAddress), prepared, orDash(vm.Host)})
+	}
+	return rows
+}
+
+func (inv *vmInventory) wideTableHeader() []string {
+	return append(inv.tableHeader(), "CACHED")
+}
+
+// wideTableRows adds how long ago the listing was fetched, which is the same
+// for every VM in one inventory.
+func (inv *vmInventory) wideTableRows() [][]string {
+	cached := shortDuration(time.Duration(inv.CacheAgeSeconds)*time.Second) + " ago"
+	if !inv.FromCache {
+		cached = "just now"
+	}
+	rows := inv.tableRows()
+	for i := range rows {
+		rows[i][0] = truncateCell(rows[i][0], wideCellMax)
+		rows[i] = append(rows[i], cached)
+	}
+	return rows
+}
+
+// loadVMInventory serves the cached VM listing when it is non-empty and
+// younger than ttl. Otherwise (or when refresh is set) it lists the VMs
+// through list and replaces the cache with the result.
+func loadVMInventory(ctx context.Context, st store.DataStore, list func(context.Context) ([]*sandbox.VMInfo, error), ttl time.Duration, refresh bool, now time.Time) (*vmInventory, error) {
+	if !refresh {
+		cached, err := st.ListCachedVMs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("read vm cache: %w", err)
+		}
+		if len(cached) > 0 && now.Sub(cached[0].CachedAt) < ttl {
+			inv := &vmInventory{
+				VMs:             make([]*sandbox.VMInfo, 0, len(cached)),
+				CachedAt:        cached[0].CachedAt,
+				CacheAgeSeconds: int64(now.Sub(cached[0].CachedAt).Seconds()),
+				FromCache:       true,
+			}
+			for _, vm := range cached {
+				inv.VMs = append(inv.VMs, &sandbox.VMInfo{
+					Name:      vm.Name,
+					State:     vm.State,
+					IPAddress: vm.IPAddress,
+					Prepared:  vm.Prepared,
+					Host:      vm.Host,
+				})
+			}
+			return inv, nil
+		}
+	}
+
+	vms, err := list(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list vms: %w", err)
+	}
+	entries := make([]*store.CachedVM, 0, len(vms))
+	for _, vm := range vms {
+		entries = append(entries, &store.CachedVM{
+			Name:      vm.Name,
+			Host:      vm.Host,
+			State:     vm.State,
+			IPAddress: vm.IPAddress,
+			Prepared:  vm.Prepared,
+			CachedAt:  now,
+		})
+	}
+	if err := st.ReplaceVMCache(ctx, entries); err != nil {
+		return nil, fmt.Errorf("update vm cache: %w", err)
+	}
+	return &vmInventory{VMs: vms, CachedAt: now}, nil
+}
+
+func runVMs(refresh bool) error {
+	configPath, err := resolveConfigPath()
+	if err != nil {
+		return fmt.Errorf("determine config path: %w", err)
+	}
+
+	loadedCfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	ctx := context.Background()
+	st, err := sqlite.New(ctx, store.Config{AutoMigrate: true})
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := initSandboxService(loadedCfg, logger)
+	defer func() { _ = svc.Close() }()
+
+	inv, err := loadVMInventory(ctx, st, svc.ListVMs, loadedCfg.VMCacheTTL, refresh, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if outputFormat != "" {
+		return writeOutput(os.Stdout, inv)
+	}
+	printVMInventory(os.Stdout, inv)
+	return nil
+}
+
+// printVMInventory writes one row per VM, followed by a note on the listing's
+// age when it came from the cache.
+func printVMInventory(w io.Writer, inv *vmInventory) {
+	if len(inv.VMs) == 0 {
+		fmt.Fprintln(w, "  No VMs found.")
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  "+strings.Join(inv.tableHeader(), "\t"))
+		for _, row := range inv.tableRows() {
+			fmt.Fprintln(tw, "  "+strings.Join(row, "\t"))
+		}
+		_ = tw.Flush()
+	}
+	if inv.FromCache {
+		age := time.Duration(inv.CacheAgeSeconds) * time.Second
+		fmt.Fprintf(w, "\n  Cached %s ago; run 'deer vms --refresh' to re-list.\n", age)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store/sqlite"
+)
+
+func newVMCacheStore(t *testing.T) store.Store {
+	t.Helper()
+	st, err := sqlite.New(context.Background(), store.Config{
+		DatabaseURL: filepath.Join(t.TempDir(), "state.db"),
+		AutoMigrate: true,
+	})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+// countingLister returns vms and records how many times it was called.
+func countingLister(calls *int, vms ...*sandbox.VMInfo) func(context.Context) ([]*sandbox.VMInfo, error) {
+	return func(context.Context) ([]*sandbox.VMInfo, error) {
+		*calls++
+		return vms, nil
+	}
+}
+
+func TestLoadVMInventory_CachesAndServesFresh(t *testing.T) {
+	st := newVMCacheStore(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var calls int
+	list := countingLister(&calls, &sandbox.VMInfo{Name: "web-01", State: "running", Host: "kvm-01", Prepared: true})
+
+	inv, err := loadVMInventory(ctx, st, list, 5*time.Minute, false, now)
+	if err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+	if inv.FromCache || calls != 1 {
+		t.Fatalf("first load: FromCache=%v calls=%d, want a fresh listing", inv.FromCache, calls)
+	}
+
+	inv, err = loadVMInventory(ctx, st, list, 5*time.Minute, false, now.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("second load: %v", err)
+	}
+	if !inv.FromCache || calls != 1 {
+		t.Fatalf("second load: FromCache=%v calls=%d, want the cache", inv.FromCache, calls)
+	}
+	if inv.CacheAgeSeconds != 120 {
+		t.Errorf("CacheAgeSeconds = %d, want 120", inv.CacheAgeSeconds)
+	}
+	if len(inv.VMs) != 1 || inv.VMs[0].Name != "web-01" || inv.VMs[0].Host != "kvm-01" || !inv.VMs[0].Prepared {
+		t.Errorf("cached VMs = %+v", inv.VMs)
+	}
+}
+
+func TestLoadVMInventory_StaleOrRefreshRelists(t *testing.T) {
+	st := newVMCacheStore(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var calls int
+	list := countingLister(&calls, &sandbox.VMInfo{Name: "web-01", State: "running"})
+
+	if _, err := loadVMInventory(ctx, st, list, 5*time.Minute, false, now); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	inv, err := loadVMInventory(ctx, st, list, 5*time.Minute, false, now.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("stale load: %v", err)
+	}
+	if inv.FromCache || calls != 2 {
+		t.Errorf("stale load: FromCache=%v calls=%d, want a re-list", inv.FromCache, calls)
+	}
+	inv, err = loadVMInventory(ctx, st, list, 5*time.Minute, true, now.Add(11*time.Minute))
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if inv.FromCache || calls != 3 {
+		t.Errorf("refresh: FromCache=%v calls=%d, want a re-list", inv.FromCache, calls)
+	}
+}
+
+func TestLoadVMInventory_ListErrorKeepsCache(t *testing.T) {
+	st := newVMCacheStore(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var calls int
+	if _, err := loadVMInventory(ctx, st, countingLister(&calls, &sandbox.VMInfo{Name: "web-01"}), time.Minute, false, now); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	failing := func(context.Context) ([]*sandbox.VMInfo, error) { return nil, errors.New("host unreachable") }
+	if _, err := loadVMInventory(ctx, st, failing, time.Minute, true, now); err == nil {
+		t.Fatal("expected the list error")
+	}
+	cached, err := st.ListCachedVMs(ctx)
+	if err != nil {
+		t.Fatalf("ListCachedVMs: %v", err)
+	}
+	if len(cached) != 1 {
+		t.Errorf("cache has %d VMs after a failed refresh, want 1", len(cached))
+	}
+}
+
+func TestPrintVMInventory(t *testing.T) {
+	var buf bytes.Buffer
+	printVMInventory(&buf, &vmInventory{
+		VMs:             []*sandbox.VMInfo{{Name: "web-01", State: "running", IPAddress: "10.0.0.5", Prepared: true, Host: "kvm-01"}},
+		FromCache:       true,
+		CacheAgeSeconds: 90,
+	})
+	out := buf.String()
+	for _, want := range []string{"NAME", "web-01", "10.0.0.5", "yes", "kvm-01", "Cached 1m30s ago", "--refresh"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// defaultWatchInterval is how often --watch re-renders.
+const defaultWatchInterval = 2 * time.Second
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
+// watchLoop calls render immediately and then on every interval until ctx is
+// cancelled (Ctrl+C) or render reports done. Cancellation is a clean exit.
+func watchLoop(ctx context.Context, interval time.Duration, render func(ctx context.Context) (done bool, err error)) error {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := render(ctx)
+		if err != nil || done {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeWatchHeader clears the screen and prints the watch banner.
+func writeWatchHeader(w io.Writer, interval time.Duration, title string) {
+	_, _ = fmt.Fprint(w, clearScreen)
+	_, _ = fmt.Fprintf(w, "  Every %s: %s    %s\n", interval, title, time.Now().Format("15:04:05"))
+}
+
+// changeTracker remembers the last rendered value per key so watch output can
+// highlight fields that changed since the previous refresh.
+type changeTracker struct {
+	last      map[string]string
+	highlight func(string) string
+}
+
+func newChangeTracker(useColor bool) *changeTracker {
+	return &changeTracker{
+		last:      make(map[string]string),
+		highlight: colorFunc(useColor, "\033[1;33m"),
+	}
+}
+
+// mark records value under key and returns it highlighted if it differs from
+// the previously seen value. The first sighting of a key is not highlighted.
+func (t *changeTracker) mark(key, value string) string {
+	prev, seen := t.last[key]
+	t.last[key] = value
+	if seen && prev != value {
+		return t.highlight(value)
+	}
+	return value
+}
+
+// terminalSandboxState reports whether a sandbox in this state will not
+// progress further on its own.
+func terminalSandboxState(state string) bool {
+	switch strings.ToUpper(state) {
+	case "ERROR", "DESTROYED":
+		return true
+	}
+	return false
+}
+
+// listSettled reports whether every sandbox has reached the until state or a
+// terminal one, and which of the settled sandboxes ended in a terminal state
+// other than until.
+func listSettled(sandboxes []*sandbox.SandboxInfo, until string) (done bool, missed []string) {
+	done = true
+	for _, sb := range sandboxes {
+		switch {
+		case strings.EqualFold(sb.State, until):
+		case terminalSandboxState(sb.State):
+			missed = append(missed, fmt.Sprintf("%s (%s)", sb.ID, sb.State))
+		default:
+			done = false
+		}
+	}
+	return done, missed
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestWatchLoop_StopsWhenDone(t *testing.T) {
+	calls := 0
+	err := watchLoop(context.Background(), time.Millisecond, func(context.Context) (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil {
+		t.Fatalf("watchLoop: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("render called %d times, want 3", calls)
+	}
+}
+
+func TestWatchLoop_CancelIsCleanExit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := watchLoop(ctx, time.Hour, func(context.Context) (bool, error) {
+		cancel()
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("watchLoop after cancel = %v, want nil", err)
+	}
+}
+
+func TestWatchLoop_PropagatesError(t *testing.T) {
+	want := errors.New("boom")
+	err := watchLoop(context.Background(), time.Millisecond, func(context.Context) (bool, error) {
+		return true, want
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("watchLoop = %v, want %v", err, want)
+	}
+}
+
+func TestChangeTracker_HighlightsChanges(t *testing.T) {
+	tr := newChangeTracker(true)
+
+	if got := tr.mark("state", "STARTING"); got != "STARTING" {
+		t.Errorf("first sighting = %q, want plain", got)
+	}
+	if got := tr.mark("state", "STARTING"); got != "STARTING" {
+		t.Errorf("unchanged = %q, want plain", got)
+	}
+	if got := tr.mark("state", "RUNNING"); got == "RUNNING" || !strings.Contains(got, "RUNNING") {
+		t.Errorf("changed = %q, want highlighted RUNNING", got)
+	}
+}
+
+func TestPrintSandboxTable_HighlightKeepsAlignment(t *testing.T) {
+	tr := newChangeTracker(true)
+	sbs := []*sandbox.SandboxInfo{{ID: "sbx-1", Name: "web", State: "STARTING", BaseImage: "ubuntu"}}
+
+	var buf bytes.Buffer
+	printSandboxTable(&buf, sbs, tr)
+	sbs[0].State = "RUNNING"
+	buf.Reset()
+	printSandboxTable(&buf, sbs, tr)
+
+	if !strings.Contains(buf.String(), "\033[1;33mRUNNING             \033[0m ubuntu") {
+		t.Errorf("expected padded, highlighted state column, got:\n%q", buf.String())
+	}
+}
+
+func TestTerminalSandboxState(t *testing.T) {
+	for state, want := range map[string]bool{"ERROR": true, "destroyed": true, "RUNNING": false, "STARTING": false} {
+		if got := terminalSandboxState(state); got != want {
+			t.Errorf("terminalSandboxState(%q) = %v, want %v", state, got, want)
+		}
+	}
+}
+
+func TestListSettled(t *testing.T) {
+	sbs := []*sandbox.SandboxInfo{
+		{ID: "SBX-1", State: "RUNNING"},
+		{ID: "SBX-2", State: "STARTING"},
+	}
+	if done, _ := listSettled(sbs, "running"); done {
+		t.Error("listSettled = done with a sandbox still starting")
+	}
+
+	sbs[1].State = "ERROR"
+	done, missed := listSettled(sbs, "running")
+	if !done || len(missed) != 1 || missed[0] != "SBX-2 (ERROR)" {
+		t.Errorf("listSettled = %v, %v; want done with SBX-2 missed", done, missed)
+	}
+}
@@ -0,0 +1,476 @@
+package ansible
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/readonly"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// CommandHistory is where FromCommandHistory reads a sandbox's commands;
+// sandbox.Service satisfies it.
+type CommandHistory interface {
+	ListSandboxCommands(ctx context.Context, sandboxID string, limit int, actor string) ([]*sandbox.CommandRecord, error)
+}
+
+// FromCommandHistory creates a playbook that reproduces the commands run in
+// sandboxID. Failed and read-only commands are left out; the rest are mapped
+// to Ansible modules where a common pattern is recognised (package installs,
+// service changes, file operations) and to command or shell otherwise, run
+// from the directory each command ran in. The play uses become when any
+// command ran under sudo.
+func (s *PlaybookService) FromCommandHistory(ctx context.Context, history CommandHistory, sandboxID string, req CreatePlaybookRequest) (*PlaybookWithTasks, error) {
+	records, err := history.ListSandboxCommands(ctx, sandboxID, 0, "")
+	if err != nil {
+		return nil, fmt.Errorf("list sandbox commands: %w", err)
+	}
+	var tasks []AddTaskRequest
+	become := false
+	for _, r := range records {
+		if r.ExitCode != 0 {
+			continue
+		}
+		recorded, sudo := TasksFromCommands([]string{r.Command})
+		for _, t := range recorded {
+			tasks = append(tasks, inWorkdir(t, r.Workdir))
+		}
+		become = become || sudo
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("sandbox %s has no successful commands that change the system", sandboxID)
+	}
+
+	req.Become = req.Become || become
+	return s.createWithTasks(ctx, req, tasks)
+}
+
+// inWorkdir makes t behave as it did when run from dir: command and shell
+// tasks change into it, and relative paths are resolved against it. An
+// empty dir leaves t as it is.
+func inWorkdir(t AddTaskRequest, dir string) AddTaskRequest {
+	if dir == "" {
+		return t
+	}
+	switch t.Module {
+	case "command", "shell":
+		t.Params["chdir"] = dir
+	default:
+		for _, key := range []string{"path", "dest"} {
+			if p, ok := t.Params[key].(string); ok && !path.IsAbs(p) && !strings.HasPrefix(p, "~") {
+				t.Params[key] = path.Join(dir, p)
+			}
+		}
+	}
+	return t
+}
+
+// TasksFromCommands maps shell commands to playbook tasks, skipping
+// read-only ones, and reports whether any needed sudo. Commands joined with
+// && or ; become one task per step, unless a step such as cd changes what
+// the next one sees; those, and anything with pipes, substitutions or
+// redirects other than a plain echo into a file, run through shell.
+func TasksFromCommands(commands []string) ([]AddTaskRequest, bool) {
+	var tasks []AddTaskRequest
+	become := false
+	for _, command := range commands {
+		command = strings.TrimSpace(command)
+		if command == "" || readonly.ValidateCommand(command) == nil {
+			continue
+		}
+		steps, ok := splitSteps(command)
+		if !ok || changesShellState(steps) {
+			tasks = append(tasks, shellTask(command))
+			continue
+		}
+		for _, words := range steps {
+			if len(words) > 0 && words[0] == "sudo" {
+				become = true
+				words = stripSudo(words)
+			}
+			if len(words) == 0 || readonly.ValidateCommand(joinWords(words)) == nil {
+				continue
+			}
+			tasks = append(tasks, taskFromWords(words))
+		}
+	}
+	return tasks, become
+}
+
+// splitSteps tokenizes command into the word lists of its && and ; separated
+// steps, keeping > and >> as words. It reports false when the command uses
+// shell features a single module cannot express.
+func splitSteps(command string) ([][]string, bool) {
+	var (
+		steps   [][]string
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endStep := func() {
+		endWord()
+		if len(words) > 0 {
+			steps = append(steps, words)
+		}
+		words = nil
+	}
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			inWord, escaped = true, false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if quote == '"' && (r == '$' || r == '`') {
+				return nil, false
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			endWord()
+		case r == ';':
+			endStep()
+		case r == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			endStep()
+			i++
+		case r == '>':
+			endWord()
+			if i+1 < len(runes) && runes[i+1] == '>' {
+				words = append(words, ">>")
+				i++
+			} else {
+				words = append(words, ">")
+			}
+		case strings.ContainsRune("|&<$`(){}*?\n", r):
+			return nil, false
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, false
+	}
+	endStep()
+	return steps, true
+}
+
+// changesShellState reports whether a step changes the directory or
+// environment later steps run with, so the steps cannot become separate
+// tasks.
+func changesShellState(steps [][]string) bool {
+	for _, words := range steps {
+		switch {
+		case words[0] == "cd", words[0] == "pushd", words[0] == "export", words[0] == "source", words[0] == ".":
+			return true
+		case strings.Contains(words[0], "="):
+			return true
+		}
+	}
+	return false
+}
+
+// stripSudo drops sudo and its options from the front of words.
+func stripSudo(words []string) []string {
+	words = words[1:]
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+		words = words[1:]
+	}
+	return words
+}
+
+// taskFromWords maps one tokenized step to a module task.
+func taskFromWords(words []string) AddTaskRequest {
+	line := joinWords(words)
+	if i := indexOf(words, ">", ">>"); i >= 0 {
+		if t, ok := redirectTask(words, i); ok {
+			return t
+		}
+		return shellTask(line)
+	}
+
+	args, flags := splitFlags(words[1:])
+	switch words[0] {
+	case "apt", "apt-get":
+		if t, ok := aptTask(args, flags); ok {
+			return t
+		}
+	case "dnf", "yum":
+		if t, ok := packageTask(words[0], args); ok {
+			return t
+		}
+	case "systemctl":
+		if t, ok := systemctlTask(args, flags); ok {
+			return t
+		}
+	case "service":
+		if len(args) == 2 {
+			if state, ok := serviceStates[args[1]]; ok {
+				return AddTaskRequest{Name: strings.ToUpper(args[1][:1]) + args[1][1:] + " " + args[0], Module: "service", Params: map[string]any{"name": args[0], "state": state}}
+			}
+		}
+	case "mkdir":
+		if len(args) == 1 {
+			return fileTask("Create directory", args[0], map[string]any{"state": "directory"})
+		}
+	case "touch":
+		if len(args) == 1 {
+			return fileTask("Touch file", args[0], map[string]any{"state": "touch"})
+		}
+	case "rm":
+		if len(args) == 1 {
+			return fileTask("Remove", args[0], map[string]any{"state": "absent"})
+		}
+	case "chmod":
+		if len(args) == 2 && len(flags) == 0 {
+			return fileTask("Set mode on", args[1], map[string]any{"mode": args[0]})
+		}
+	case "chown":
+		if len(args) == 2 && len(flags) == 0 {
+			owner, group, _ := strings.Cut(args[0], ":")
+			params := map[string]any{"owner": owner}
+			if group != "" {
+				params["group"] = group
+			}
+			return fileTask("Set owner of", args[1], params)
+		}
+	case "useradd", "adduser":
+		if len(args) == 1 {
+			return AddTaskRequest{Name: "Create user " + args[0], Module: "user", Params: map[string]any{"name": args[0]}}
+		}
+	case "git":
+		if len(args) >= 2 && args[0] == "clone" && len(flags) == 0 {
+			params := map[string]any{"repo": args[1]}
+			dest := strings.TrimSuffix(path.Base(args[1]), ".git")
+			if len(args) >= 3 {
+				dest = args[2]
+			}
+			params["dest"] = dest
+			return AddTaskRequest{Name: "Clone " + args[1], Module: "git", Params: params}
+		}
+	case "pip", "pip3":
+		if len(args) >= 2 && args[0] == "install" && len(flags) == 0 {
+			return AddTaskRequest{Name: "Install Python packages " + strings.Join(args[1:], ", "), Module: "pip", Params: map[string]any{"name": args[1:]}}
+		}
+	case "sed":
+		if t, ok := sedTask(words[1:]); ok {
+			return t
+		}
+	}
+	return commandTask(line)
+}
+
+// serviceStates maps systemctl and service verbs to service module states.
+var serviceStates = map[string]string{
+	"start":   "started",
+	"stop":    "stopped",
+	"restart": "restarted",
+	"reload":  "reloaded",
+}
+
+func aptTask(args, flags []string) (AddTaskRequest, bool) {
+	if len(args) == 0 {
+		return AddTaskRequest{}, false
+	}
+	switch args[0] {
+	case "update":
+		return AddTaskRequest{Name: "Update apt cache", Module: "apt", Params: map[string]any{"update_cache": true}}, true
+	case "upgrade", "dist-upgrade", "full-upgrade":
+		upgrade := "yes"
+		if args[0] != "upgrade" {
+			upgrade = "dist"
+		}
+		return AddTaskRequest{Name: "Upgrade packages", Module: "apt", Params: map[string]any{"upgrade": upgrade}}, true
+	case "install":
+		if len(args) > 1 {
+			return AddTaskRequest{Name: "Install " + strings.Join(args[1:], ", "), Module: "apt", Params: map[string]any{"name": args[1:], "state": "present"}}, true
+		}
+	case "remove", "purge":
+		if len(args) > 1 {
+			params := map[string]any{"name": args[1:], "state": "absent"}
+			if args[0] == "purge" || hasFlag(flags, "--purge") {
+				params["purge"] = true
+			}
+			return AddTaskRequest{Name: "Remove " + strings.Join(args[1:], ", "), Module: "apt", Params: params}, true
+		}
+	}
+	return AddTaskRequest{}, false
+}
+
+func packageTask(manager string, args []string) (AddTaskRequest, bool) {
+	if len(args) < 2 {
+		return AddTaskRequest{}, false
+	}
+	state := map[string]string{"install": "present", "remove": "absent", "erase": "absent"}[args[0]]
+	if state == "" {
+		return AddTaskRequest{}, false
+	}
+	verb := "Install "
+	if state == "absent" {
+		verb = "Remove "
+	}
+	return AddTaskRequest{Name: verb + strings.Join(args[1:], ", "), Module: manager, Params: map[string]any{"name": args[1:], "state": state}}, true
+}
+
+func systemctlTask(args, flags []string) (AddTaskRequest, bool) {
+	if len(args) == 0 {
+		return AddTaskRequest{}, false
+	}
+	verb, units := args[0], args[1:]
+	if len(units) != 1 {
+		return AddTaskRequest{}, false
+	}
+	unit := units[0]
+	params := map[string]any{"name": unit}
+	name := ""
+	switch verb {
+	case "enable", "disable":
+		params["enabled"] = verb == "enable"
+		name = strings.ToUpper(verb[:1]) + verb[1:] + " " + unit
+		if hasFlag(flags, "--now") {
+			params["state"] = map[bool]string{true: "started", false: "stopped"}[verb == "enable"]
+			name += " now"
+		}
+	default:
+		state, ok := serviceStates[verb]
+		if !ok {
+			return AddTaskRequest{}, false
+		}
+		params["state"] = state
+		name = strings.ToUpper(verb[:1]) + verb[1:] + " " + unit
+	}
+	return AddTaskRequest{Name: name, Module: "service", Params: params}, true
+}
+
+// redirectTask maps echo or printf into a file: > to copy, >> to lineinfile.
+func redirectTask(words []string, op int) (AddTaskRequest, bool) {
+	if op != len(words)-2 || (words[0] != "echo" && words[0] != "printf") {
+		return AddTaskRequest{}, false
+	}
+	text := words[1:op]
+	if words[0] == "echo" && len(text) > 0 && strings.HasPrefix(text[0], "-") {
+		return AddTaskRequest{}, false
+	}
+	if words[0] == "printf" && len(text) != 1 {
+		return AddTaskRequest{}, false
+	}
+	dest := words[op+1]
+	content := strings.Join(text, " ")
+	if words[op] == ">>" {
+		if words[0] == "printf" {
+			return AddTaskRequest{}, false
+		}
+		return AddTaskRequest{Name: "Add line to " + dest, Module: "lineinfile", Params: map[string]any{"path": dest, "line": content}}, true
+	}
+	if words[0] == "echo" {
+		content += "\n"
+	} else {
+		content = strings.ReplaceAll(content, `\n`, "\n")
+	}
+	return AddTaskRequest{Name: "Write " + dest, Module: "copy", Params: map[string]any{"dest": dest, "content": content}}, true
+}
+
+// sedTask maps `sed -i 's/old/new/[g]' file` to the replace module, which
+// replaces every match.
+func sedTask(args []string) (AddTaskRequest, bool) {
+	if len(args) != 3 || args[0] != "-i" {
+		return AddTaskRequest{}, false
+	}
+	expr, file := args[1], args[2]
+	if len(expr) < 4 || expr[0] != 's' {
+		return AddTaskRequest{}, false
+	}
+	parts := strings.Split(expr[2:], expr[1:2])
+	if len(parts) != 3 || (parts[2] != "" && parts[2] != "g") {
+		return AddTaskRequest{}, false
+	}
+	return AddTaskRequest{Name: "Edit " + file, Module: "replace", Params: map[string]any{"path": file, "regexp": parts[0], "replace": parts[1]}}, true
+}
+
+func fileTask(verb, path string, params map[string]any) AddTaskRequest {
+	params["path"] = path
+	return AddTaskRequest{Name: verb + " " + path, Module: "file", Params: params}
+}
+
+func commandTask(line string) AddTaskRequest {
+	return AddTaskRequest{Name: runTaskName(line), Module: "command", Params: map[string]any{"cmd": line}}
+}
+
+func shellTask(line string) AddTaskRequest {
+	return AddTaskRequest{Name: runTaskName(line), Module: "shell", Params: map[string]any{"cmd": line}}
+}
+
+// runTaskName names a command or shell task after its (shortened) command.
+func runTaskName(line string) string {
+	if r := []rune(line); len(r) > maxTaskNameCommand {
+		line = string(r[:maxTaskNameCommand-3]) + "..."
+	}
+	return "Run " + line
+}
+
+// maxTaskNameCommand bounds how much of a command goes into a task name.
+const maxTaskNameCommand = 60
+
+// joinWords turns words back into a command line, single-quoting any word
+// the shell (or Ansible's command module) would otherwise split or expand.
+func joinWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		if w == "" || strings.ContainsAny(w, " \t\n'\"\\$`*?;&|<>(){}#~") && w != ">" && w != ">>" {
+			w = "'" + strings.ReplaceAll(w, "'", `'\''`) + "'"
+		}
+		quoted[i] = w
+	}
+	return strings.Join(quoted, " ")
+}
+
+// splitFlags separates words starting with - from positional arguments.
+func splitFlags(words []string) (args, flags []string) {
+	for _, w := range words {
+		if strings.HasPrefix(w, "-") && len(w) > 1 {
+			flags = append(flags, w)
+			continue
+		}
+		args = append(args, w)
+	}
+	return args, flags
+}
+
+// hasFlag reports whether flags contains name exactly.
+func hasFlag(flags []string, name string) bool {
+	for _, f := range flags {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf(words []string, targets ...string) int {
+	for i, w := range words {
+		for _, t := range targets {
+			if w == t {
+				return i
+			}
+		}
+	}
+	return -1
+}
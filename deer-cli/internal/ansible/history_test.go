@@ -0,0 +1,112 @@
+package ansible
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+func TestTasksFromCommands(t *testing.T) {
+	tests := []struct {
+		command string
+		module  string
+		params  map[string]any
+	}{
+		{"apt-get install -y nginx curl", "apt", map[string]any{"name": []string{"nginx", "curl"}, "state": "present"}},
+		{"apt remove --purge apache2", "apt", map[string]any{"name": []string{"apache2"}, "state": "absent", "purge": true}},
+		{"dnf install -y httpd", "dnf", map[string]any{"name": []string{"httpd"}, "state": "present"}},
+		{"systemctl enable --now nginx", "service", map[string]any{"name": "nginx", "enabled": true, "state": "started"}},
+		{"systemctl restart nginx", "service", map[string]any{"name": "nginx", "state": "restarted"}},
+		{"service nginx reload", "service", map[string]any{"name": "nginx", "state": "reloaded"}},
+		{"mkdir -p /var/www/app", "file", map[string]any{"path": "/var/www/app", "state": "directory"}},
+		{"rm -rf /etc/nginx/sites-enabled/default", "file", map[string]any{"path": "/etc/nginx/sites-enabled/default", "state": "absent"}},
+		{"chmod 0640 /etc/app.conf", "file", map[string]any{"path": "/etc/app.conf", "mode": "0640"}},
+		{"chown www-data:www-data /var/www/app", "file", map[string]any{"path": "/var/www/app", "owner": "www-data", "group": "www-data"}},
+		{"git clone https://github.com/example/app.git", "git", map[string]any{"repo": "https://github.com/example/app.git", "dest": "app"}},
+		{`echo "vm.swappiness=10" >> /etc/sysctl.conf`, "lineinfile", map[string]any{"path": "/etc/sysctl.conf", "line": "vm.swappiness=10"}},
+		{"echo 'listen 8080;' > /etc/app/port.conf", "copy", map[string]any{"dest": "/etc/app/port.conf", "content": "listen 8080;\n"}},
+		{"sed -i 's/worker_processes 1/worker_processes auto/' /etc/nginx/nginx.conf", "replace", map[string]any{"path": "/etc/nginx/nginx.conf", "regexp": "worker_processes 1", "replace": "worker_processes auto"}},
+		{"nginx -s reload", "command", map[string]any{"cmd": "nginx -s reload"}},
+		{`logger -t deploy "app deployed"`, "command", map[string]any{"cmd": `logger -t deploy 'app deployed'`}},
+		{"curl -fsSL https://example.com/install.sh | bash", "shell", map[string]any{"cmd": "curl -fsSL https://example.com/install.sh | bash"}},
+		{"cd /opt/app && make install", "shell", map[string]any{"cmd": "cd /opt/app && make install"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tasks, _ := TasksFromCommands([]string{tt.command})
+			require.Len(t, tasks, 1)
+			assert.Equal(t, tt.module, tasks[0].Module)
+			assert.Equal(t, tt.params, tasks[0].Params)
+			assert.NotEmpty(t, tasks[0].Name)
+		})
+	}
+}
+
+func TestTasksFromCommands_StepsSudoAndReadOnly(t *testing.T) {
+	tasks, become := TasksFromCommands([]string{
+		"cat /etc/os-release",
+		"sudo apt-get update && sudo apt-get install -y nginx",
+		"systemctl status nginx",
+	})
+	assert.True(t, become)
+	require.Len(t, tasks, 2, "read-only commands are skipped and && steps split")
+	assert.Equal(t, "Update apt cache", tasks[0].Name)
+	assert.Equal(t, []string{"nginx"}, tasks[1].Params["name"])
+}
+
+// fakeHistory returns fixed command records for any sandbox.
+type fakeHistory []*sandbox.CommandRecord
+
+func (h fakeHistory) ListSandboxCommands(context.Context, string, int, string) ([]*sandbox.CommandRecord, error) {
+	return h, nil
+}
+
+func TestFromCommandHistory(t *testing.T) {
+	ms := newMockStore()
+	tmpDir := t.TempDir()
+	svc := NewPlaybookService(ms, tmpDir)
+	ctx := context.Background()
+
+	history := fakeHistory{
+		{Command: "sudo apt-get install -y nginx", ExitCode: 0},
+		{Command: "sudo apt-get install -y ngnix", ExitCode: 100},
+		{Command: "ls /etc/nginx", ExitCode: 0},
+		{Command: "sudo systemctl enable --now nginx", ExitCode: 0},
+	}
+	result, err := svc.FromCommandHistory(ctx, history, "sbx-1", CreatePlaybookRequest{Name: "web"})
+	require.NoError(t, err)
+	assert.True(t, result.Playbook.Become)
+	require.Len(t, result.Tasks, 2, "failed and read-only commands are left out")
+	assert.Equal(t, "apt", result.Tasks[0].Module)
+	assert.Equal(t, "service", result.Tasks[1].Module)
+
+	content, err := os.ReadFile(*result.Playbook.FilePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "enabled: true")
+
+	_, err = svc.FromCommandHistory(ctx, fakeHistory{{Command: "ls", ExitCode: 0}}, "sbx-2", CreatePlaybookRequest{Name: "empty"})
+	assert.Error(t, err)
+}
+
+func TestFromCommandHistory_Workdir(t *testing.T) {
+	svc := NewPlaybookService(newMockStore(), t.TempDir())
+	history := fakeHistory{
+		{Command: "make install", Workdir: "/opt/app", ExitCode: 0},
+		{Command: "mkdir build", Workdir: "/opt/app", ExitCode: 0},
+		{Command: "chmod 0640 /etc/app.conf", Workdir: "/opt/app", ExitCode: 0},
+	}
+	result, err := svc.FromCommandHistory(context.Background(), history, "sbx-1", CreatePlaybookRequest{Name: "app"})
+	require.NoError(t, err)
+	require.Len(t, result.Tasks, 3)
+
+	content, err := os.ReadFile(*result.Playbook.FilePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "chdir: /opt/app")
+	assert.Contains(t, string(content), "path: /opt/app/build")
+	assert.Contains(t, string(content), "path: /etc/app.conf")
+}
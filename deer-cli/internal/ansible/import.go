@@ -0,0 +1,284 @@
+package ansible
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ImportError is a problem found while validating an imported playbook,
+// located at a line of the source file.
+type ImportError struct {
+	Line    int
+	Message string
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// moduleSpec describes a module that can be imported. Required lists the
+// parameters the task must set; each entry may name alternatives separated
+// by "|". FreeForm modules also accept a plain string, stored as cmd.
+type moduleSpec struct {
+	Required []string
+	FreeForm bool
+}
+
+// importModules are the modules an imported task may use. The
+// ansible.builtin. prefix is accepted for each of them.
+var importModules = map[string]moduleSpec{
+	"apt":            {Required: []string{"name|pkg|update_cache|upgrade|deb|autoremove|autoclean"}},
+	"dnf":            {Required: []string{"name|pkg|list|update_cache|autoremove"}},
+	"yum":            {Required: []string{"name|pkg|list|update_cache|autoremove"}},
+	"package":        {Required: []string{"name", "state"}},
+	"pip":            {Required: []string{"name|requirements"}},
+	"service":        {Required: []string{"name"}},
+	"systemd":        {Required: []string{"name|daemon_reload|daemon_reexec"}},
+	"command":        {Required: []string{"cmd|argv"}, FreeForm: true},
+	"shell":          {Required: []string{"cmd"}, FreeForm: true},
+	"raw":            {Required: []string{"cmd"}, FreeForm: true},
+	"script":         {Required: []string{"cmd"}, FreeForm: true},
+	"copy":           {Required: []string{"dest"}},
+	"template":       {Required: []string{"src", "dest"}},
+	"file":           {Required: []string{"path|dest|name"}},
+	"lineinfile":     {Required: []string{"path|dest|name"}},
+	"blockinfile":    {Required: []string{"path|dest|name"}},
+	"replace":        {Required: []string{"path|dest|name", "regexp"}},
+	"user":           {Required: []string{"name"}},
+	"group":          {Required: []string{"name"}},
+	"git":            {Required: []string{"repo", "dest"}},
+	"get_url":        {Required: []string{"url", "dest"}},
+	"unarchive":      {Required: []string{"src", "dest"}},
+	"cron":           {Required: []string{"name"}},
+	"sysctl":         {Required: []string{"name"}},
+	"debug":          {},
+	"ping":           {},
+	"setup":          {},
+	"wait_for":       {},
+	"stat":           {Required: []string{"path"}},
+	"uri":            {Required: []string{"url"}},
+	"set_fact":       {},
+	"apt_key":        {},
+	"apt_repository": {Required: []string{"repo"}},
+}
+
+// ImportFromYAML validates a hand-written playbook and stores it as a new
+// playbook called name, or after its play when name is empty. Only a single
+// play of plain module tasks can be imported: roles, blocks, handlers and
+// task keywords such as when or loop have no place in the store and are
+// rejected with an *ImportError naming the line. Nothing is stored unless the
+// whole file is valid.
+func (s *PlaybookService) ImportFromYAML(ctx context.Context, name string, data []byte) (*PlaybookWithTasks, error) {
+	req, tasks, err := parsePlaybookYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		req.Name = name
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("playbook has no name; pass one explicitly")
+	}
+
+	return s.createWithTasks(ctx, req, tasks)
+}
+
+// createWithTasks creates a playbook holding tasks. If a task cannot be
+// added, the playbook is deleted again so no partial playbook is left.
+func (s *PlaybookService) createWithTasks(ctx context.Context, req CreatePlaybookRequest, tasks []AddTaskRequest) (*PlaybookWithTasks, error) {
+	pb, err := s.CreatePlaybook(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		if _, err := s.AddTask(ctx, pb.ID, t); err != nil {
+			if delErr := s.DeletePlaybook(ctx, pb.ID); delErr != nil {
+				return nil, fmt.Errorf("add task %q: %w (and delete partial playbook: %v)", t.Name, err, delErr)
+			}
+			return nil, fmt.Errorf("add task %q: %w", t.Name, err)
+		}
+	}
+	return s.GetPlaybookWithTasks(ctx, pb.ID)
+}
+
+// parsePlaybookYAML validates data and returns its play and tasks.
+func parsePlaybookYAML(data []byte) (CreatePlaybookRequest, []AddTaskRequest, error) {
+	var req CreatePlaybookRequest
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return req, nil, fmt.Errorf("parse playbook: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return req, nil, fmt.Errorf("playbook is empty")
+	}
+	plays := doc.Content[0]
+	if plays.Kind != yaml.SequenceNode {
+		return req, nil, importErr(plays, "a playbook must be a list of plays")
+	}
+	if len(plays.Content) == 0 {
+		return req, nil, importErr(plays, "playbook has no plays")
+	}
+	if len(plays.Content) > 1 {
+		return req, nil, importErr(plays.Content[1], "only single-play playbooks can be imported")
+	}
+	play := plays.Content[0]
+	if play.Kind != yaml.MappingNode {
+		return req, nil, importErr(play, "a play must be a mapping")
+	}
+
+	var tasksNode *yaml.Node
+	for i := 0; i < len(play.Content); i += 2 {
+		key, value := play.Content[i], play.Content[i+1]
+		switch key.Value {
+		case "roles":
+			return req, nil, importErr(key, "roles are not supported; import the role's tasks instead")
+		case "name", "hosts":
+			if value.Kind != yaml.ScalarNode {
+				return req, nil, importErr(value, "play %s must be a string", key.Value)
+			}
+			if key.Value == "name" {
+				req.Name = value.Value
+			} else {
+				req.Hosts = value.Value
+			}
+		case "become":
+			if err := value.Decode(&req.Become); err != nil {
+				return req, nil, importErr(value, "play become must be true or false")
+			}
+		case "tasks":
+			tasksNode = value
+		default:
+			return req, nil, importErr(key, "play keyword %q is not supported", key.Value)
+		}
+	}
+	if tasksNode == nil {
+		return req, nil, importErr(play, "play has no tasks")
+	}
+	if tasksNode.Kind != yaml.SequenceNode {
+		return req, nil, importErr(tasksNode, "tasks must be a list")
+	}
+
+	tasks := make([]AddTaskRequest, 0, len(tasksNode.Content))
+	for _, n := range tasksNode.Content {
+		t, err := parseImportTask(n)
+		if err != nil {
+			return req, nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return req, tasks, nil
+}
+
+// parseImportTask validates one task: an optional name and exactly one
+// recognised module with well-formed params.
+func parseImportTask(n *yaml.Node) (AddTaskRequest, error) {
+	var t AddTaskRequest
+	if n.Kind != yaml.MappingNode {
+		return t, importErr(n, "a task must be a mapping")
+	}
+
+	var moduleKey, moduleValue *yaml.Node
+	for i := 0; i < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		switch {
+		case key.Value == "name":
+			if value.Kind != yaml.ScalarNode {
+				return t, importErr(value, "task name must be a string")
+			}
+			t.Name = value.Value
+		case key.Value == "block" || key.Value == "rescue" || key.Value == "always":
+			return t, importErr(key, "blocks are not supported")
+		case strings.HasPrefix(key.Value, "include_") || strings.HasPrefix(key.Value, "import_"):
+			return t, importErr(key, "%s is not supported; inline the included tasks", key.Value)
+		case isTaskKeyword(key.Value):
+			return t, importErr(key, "task keyword %q is not supported", key.Value)
+		default:
+			if moduleKey != nil {
+				return t, importErr(key, "task has more than one module (%s and %s)", moduleKey.Value, key.Value)
+			}
+			moduleKey, moduleValue = key, value
+		}
+	}
+	if moduleKey == nil {
+		return t, importErr(n, "task has no module")
+	}
+
+	module := strings.TrimPrefix(moduleKey.Value, "ansible.builtin.")
+	spec, ok := importModules[module]
+	if !ok {
+		return t, importErr(moduleKey, "unrecognised module %q", moduleKey.Value)
+	}
+	params, err := moduleParams(moduleValue, spec, module)
+	if err != nil {
+		return t, err
+	}
+	for _, req := range spec.Required {
+		if !hasAnyParam(params, strings.Split(req, "|")) {
+			return t, importErr(moduleValue, "%s requires %s", module, strings.ReplaceAll(req, "|", " or "))
+		}
+	}
+
+	t.Module = moduleKey.Value
+	t.Params = params
+	if t.Name == "" {
+		t.Name = moduleKey.Value
+	}
+	return t, nil
+}
+
+// moduleParams decodes a module's value into params. Free-form modules
+// take a string as their command.
+func moduleParams(v *yaml.Node, spec moduleSpec, module string) (map[string]any, error) {
+	switch {
+	case v.Kind == yaml.ScalarNode && v.Tag == "!!null":
+		return nil, nil
+	case v.Kind == yaml.ScalarNode && spec.FreeForm:
+		return map[string]any{"cmd": v.Value}, nil
+	case v.Kind == yaml.ScalarNode:
+		return nil, importErr(v, "%s params must be a mapping, not %q", module, v.Value)
+	case v.Kind != yaml.MappingNode:
+		return nil, importErr(v, "%s params must be a mapping", module)
+	}
+	for i := 0; i < len(v.Content); i += 2 {
+		if k := v.Content[i]; k.Kind != yaml.ScalarNode || k.Tag != "!!str" {
+			return nil, importErr(k, "%s param names must be strings", module)
+		}
+	}
+	var params map[string]any
+	if err := v.Decode(&params); err != nil {
+		return nil, importErr(v, "%s params: %s", module, err)
+	}
+	return params, nil
+}
+
+// taskKeywords are task-level keywords other than name. PlaybookTask only
+// holds a module and its params, so a task using any of them cannot be
+// imported without changing what it does.
+var taskKeywords = map[string]bool{
+	"when": true, "loop": true, "register": true, "notify": true, "become": true,
+	"become_user": true, "tags": true, "vars": true, "environment": true,
+	"ignore_errors": true, "changed_when": true, "failed_when": true,
+	"delegate_to": true, "run_once": true, "until": true, "retries": true,
+	"delay": true, "args": true, "no_log": true, "check_mode": true,
+	"listen": true, "loop_control": true,
+}
+
+func isTaskKeyword(key string) bool {
+	return taskKeywords[key] || strings.HasPrefix(key, "with_")
+}
+
+func hasAnyParam(params map[string]any, names []string) bool {
+	for _, n := range names {
+		if _, ok := params[n]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func importErr(n *yaml.Node, format string, args ...any) *ImportError {
+	return &ImportError{Line: n.Line, Message: fmt.Sprintf(format, args...)}
+}
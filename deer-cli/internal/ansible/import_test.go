@@ -0,0 +1,143 @@
+package ansible
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importYAML = `- name: web
+  hosts: webservers
+  become: true
+  tasks:
+    - name: Install nginx
+      ansible.builtin.apt:
+        name: nginx
+        state: present
+    - shell: systemctl reload nginx
+    - name: Configure site
+      template:
+        src: site.j2
+        dest: /etc/nginx/sites-enabled/default
+`
+
+func TestImportFromYAML(t *testing.T) {
+	ms := newMockStore()
+	svc := NewPlaybookService(ms, t.TempDir())
+
+	result, err := svc.ImportFromYAML(context.Background(), "", []byte(importYAML))
+	require.NoError(t, err)
+	assert.Equal(t, "web", result.Playbook.Name)
+	assert.Equal(t, "webservers", result.Playbook.Hosts)
+	assert.True(t, result.Playbook.Become)
+	require.Len(t, result.Tasks, 3)
+	assert.Equal(t, "ansible.builtin.apt", result.Tasks[0].Module)
+	assert.Equal(t, "nginx", result.Tasks[0].Params["name"])
+	assert.Equal(t, "shell", result.Tasks[1].Name)
+	assert.Equal(t, map[string]any{"cmd": "systemctl reload nginx"}, result.Tasks[1].Params)
+
+	require.NotNil(t, result.Playbook.FilePath)
+	_, err = os.Stat(*result.Playbook.FilePath)
+	assert.NoError(t, err)
+
+	renamed, err := svc.ImportFromYAML(context.Background(), "web-copy", []byte(importYAML))
+	require.NoError(t, err)
+	assert.Equal(t, "web-copy", renamed.Playbook.Name)
+}
+
+func TestImportFromYAML_DeletesPartialPlaybook(t *testing.T) {
+	// A playbooks dir under a regular file makes rendering, and so AddTask,
+	// fail after the playbook row exists.
+	blocker := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+	ms := newMockStore()
+	svc := NewPlaybookService(ms, filepath.Join(blocker, "playbooks"))
+
+	_, err := svc.ImportFromYAML(context.Background(), "", []byte(importYAML))
+	require.Error(t, err)
+	assert.Empty(t, ms.playbooks)
+	assert.Empty(t, ms.playbookTasks)
+}
+
+func TestImportFromYAML_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		line int
+		want string
+	}{
+		{
+			name: "roles",
+			yaml: "- hosts: all\n  roles:\n    - nginx\n",
+			line: 2, want: "roles are not supported",
+		},
+		{
+			name: "block",
+			yaml: "- hosts: all\n  tasks:\n    - name: group\n      block:\n        - ping:\n",
+			line: 4, want: "blocks are not supported",
+		},
+		{
+			name: "task keyword",
+			yaml: "- hosts: all\n  tasks:\n    - ping:\n      when: ansible_os_family == 'Debian'\n",
+			line: 4, want: `task keyword "when"`,
+		},
+		{
+			name: "unknown module",
+			yaml: "- hosts: all\n  tasks:\n    - name: x\n      community.general.frobnicate:\n        a: 1\n",
+			line: 4, want: "unrecognised module",
+		},
+		{
+			name: "missing param",
+			yaml: "- hosts: all\n  tasks:\n    - template:\n        src: a.j2\n",
+			line: 4, want: "template requires dest",
+		},
+		{
+			name: "string params",
+			yaml: "- hosts: all\n  tasks:\n    - apt: name=nginx\n",
+			line: 3, want: "apt params must be a mapping",
+		},
+		{
+			name: "two modules",
+			yaml: "- hosts: all\n  tasks:\n    - ping:\n      debug:\n",
+			line: 4, want: "more than one module",
+		},
+		{
+			name: "second play",
+			yaml: "- hosts: all\n  tasks: []\n- hosts: db\n  tasks: []\n",
+			line: 3, want: "single-play",
+		},
+		{
+			name: "handlers",
+			yaml: "- hosts: all\n  tasks: []\n  handlers: []\n",
+			line: 3, want: `play keyword "handlers"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := newMockStore()
+			svc := NewPlaybookService(ms, t.TempDir())
+
+			_, err := svc.ImportFromYAML(context.Background(), "imported", []byte(tt.yaml))
+			var ie *ImportError
+			require.True(t, errors.As(err, &ie), "error = %v", err)
+			assert.Equal(t, tt.line, ie.Line)
+			assert.Contains(t, ie.Message, tt.want)
+			assert.Empty(t, ms.playbooks, "nothing is stored for an invalid file")
+		})
+	}
+}
+
+func TestImportFromYAML_NeedsName(t *testing.T) {
+	svc := NewPlaybookService(newMockStore(), t.TempDir())
+
+	_, err := svc.ImportFromYAML(context.Background(), "", []byte("- hosts: all\n  tasks:\n    - ping:\n"))
+	assert.ErrorContains(t, err, "no name")
+
+	_, err = svc.ImportFromYAML(context.Background(), "x", []byte("hosts: [\n"))
+	assert.ErrorContains(t, err, "parse playbook")
+}
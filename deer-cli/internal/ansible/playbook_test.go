@@ -165,6 +165,10 @@ func (m *mockStore) GetSandbox(ctx context.Context, id string) (*store.Sandbox,
 	return nil, store.ErrNotFound
 }
 
+func (m *mockStore) GetSandboxes(ctx context.Context, ids []string) ([]*store.Sandbox, []string, error) {
+	return nil, ids, nil
+}
+
 func (m *mockStore) GetSandboxByVMName(ctx context.Context, vmName string) (*store.Sandbox, error) {
 	return nil, store.ErrNotFound
 }
@@ -237,6 +241,26 @@ func (m *mockStore) ListSourceVMs(ctx context.Context) ([]*store.SourceVM, error
 	return nil, nil
 }
 
+func (m *mockStore) ReplaceVMCache(ctx context.Context, vms []*store.CachedVM) error {
+	return nil
+}
+
+func (m *mockStore) ListCachedVMs(ctx context.Context) ([]*store.CachedVM, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SaveConversation(ctx context.Context, c *store.Conversation) error {
+	return nil
+}
+
+func (m *mockStore) LoadConversation(ctx context.Context, id string) (*store.Conversation, error) {
+	return nil, store.ErrNotFound
+}
+
+func (m *mockStore) ListConversations(ctx context.Context, opt *store.ListOptions) ([]*store.Conversation, error) {
+	return nil, nil
+}
+
 func TestCreatePlaybook(t *testing.T) {
 	ms := newMockStore()
 	tmpDir := t.TempDir()
@@ -0,0 +1,142 @@
+package ansible
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// HostRecap holds the per-host counters printed in the PLAY RECAP section
+// of ansible-playbook output.
+type HostRecap struct {
+	Host        string `json:"host"`
+	OK          int    `json:"ok"`
+	Changed     int    `json:"changed"`
+	Unreachable int    `json:"unreachable"`
+	Failed      int    `json:"failed"`
+	Skipped     int    `json:"skipped"`
+	Rescued     int    `json:"rescued"`
+	Ignored     int    `json:"ignored"`
+}
+
+// Succeeded reports whether the host finished without failures and was reachable.
+func (r HostRecap) Succeeded() bool {
+	return r.Failed == 0 && r.Unreachable == 0
+}
+
+// ParseRecap extracts the PLAY RECAP entries from ansible-playbook output.
+// Lines before the recap header are ignored. Returns nil if no recap is found.
+func ParseRecap(output string) []HostRecap {
+	var recaps []HostRecap
+	inRecap := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "PLAY RECAP") {
+			inRecap = true
+			continue
+		}
+		if !inRecap || line == "" {
+			continue
+		}
+
+		host, counters, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		recap := HostRecap{Host: strings.TrimSpace(host)}
+		matched := false
+		for _, field := range strings.Fields(counters) {
+			key, val, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				continue
+			}
+			matched = true
+			switch key {
+			case "ok":
+				recap.OK = n
+			case "changed":
+				recap.Changed = n
+			case "unreachable":
+				recap.Unreachable = n
+			case "failed":
+				recap.Failed = n
+			case "skipped":
+				recap.Skipped = n
+			case "rescued":
+				recap.Rescued = n
+			case "ignored":
+				recap.Ignored = n
+			}
+		}
+		if matched {
+			recaps = append(recaps, recap)
+		}
+	}
+
+	return recaps
+}
+
+// TaskResult counts the per-host outcomes of one task in ansible-playbook
+// output. Handlers are included under their handler name.
+type TaskResult struct {
+	Name    string `json:"name"`
+	OK      int    `json:"ok"`
+	Changed int    `json:"changed"`
+	Failed  int    `json:"failed"`
+	Skipped int    `json:"skipped"`
+}
+
+// ParseTaskResults extracts each TASK (and RUNNING HANDLER) section from
+// ansible-playbook output and counts its host result lines. Failed includes
+// unreachable hosts. Returns nil if no task ran.
+func ParseTaskResults(output string) []TaskResult {
+	var tasks []TaskResult
+	var cur *TaskResult
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if name, ok := taskHeader(line); ok {
+			tasks = append(tasks, TaskResult{Name: name})
+			cur = &tasks[len(tasks)-1]
+			continue
+		}
+		if strings.HasPrefix(line, "PLAY ") {
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "ok: ["):
+			cur.OK++
+		case strings.HasPrefix(line, "changed: ["):
+			cur.Changed++
+		case strings.HasPrefix(line, "fatal: ["), strings.HasPrefix(line, "failed: ["):
+			cur.Failed++
+		case strings.HasPrefix(line, "skipping: ["):
+			cur.Skipped++
+		}
+	}
+	return tasks
+}
+
+// taskHeader returns the name from a "TASK [name] ***" or
+// "RUNNING HANDLER [name] ***" line.
+func taskHeader(line string) (string, bool) {
+	for _, prefix := range []string{"TASK [", "RUNNING HANDLER ["} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			if end := strings.LastIndex(rest, "]"); end >= 0 {
+				return rest[:end], true
+			}
+		}
+	}
+	return "", false
+}
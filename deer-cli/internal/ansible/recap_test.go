@@ -0,0 +1,81 @@
+package ansible
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecap(t *testing.T) {
+	output := `
+PLAY [nginx-setup] *************************************************************
+
+TASK [Gathering Facts] *********************************************************
+ok: [localhost]
+
+TASK [Install nginx] ***********************************************************
+changed: [localhost]
+
+PLAY RECAP *********************************************************************
+localhost                  : ok=2    changed=1    unreachable=0    failed=0    skipped=0    rescued=0    ignored=0
+web-1                      : ok=1    changed=0    unreachable=1    failed=2    skipped=3    rescued=0    ignored=1
+`
+
+	recaps := ParseRecap(output)
+	require.Len(t, recaps, 2)
+
+	assert.Equal(t, HostRecap{Host: "localhost", OK: 2, Changed: 1}, recaps[0])
+	assert.True(t, recaps[0].Succeeded())
+
+	assert.Equal(t, "web-1", recaps[1].Host)
+	assert.Equal(t, 1, recaps[1].Unreachable)
+	assert.Equal(t, 2, recaps[1].Failed)
+	assert.Equal(t, 3, recaps[1].Skipped)
+	assert.Equal(t, 1, recaps[1].Ignored)
+	assert.False(t, recaps[1].Succeeded())
+}
+
+func TestParseRecap_NoRecap(t *testing.T) {
+	assert.Nil(t, ParseRecap("ERROR! the playbook: /tmp/x.yml could not be found"))
+	assert.Nil(t, ParseRecap(""))
+}
+
+func TestParseRecap_IgnoresLinesBeforeRecap(t *testing.T) {
+	output := "fatal: [localhost]: FAILED! => {\"changed\": false}\nPLAY RECAP ***\nlocalhost : ok=0 changed=0 unreachable=0 failed=1\n"
+	recaps := ParseRecap(output)
+	require.Len(t, recaps, 1)
+	assert.Equal(t, 1, recaps[0].Failed)
+}
+
+func TestParseTaskResults(t *testing.T) {
+	output := `
+PLAY [nginx-setup] *************************************************************
+
+TASK [Gathering Facts] *********************************************************
+ok: [localhost]
+
+TASK [Install nginx] ***********************************************************
+changed: [localhost]
+ok: [web-1]
+
+TASK [Copy config [main]] ******************************************************
+skipping: [localhost]
+fatal: [web-1]: FAILED! => {"msg": "Could not find or access 'nginx.conf'"}
+
+RUNNING HANDLER [restart nginx] ************************************************
+changed: [localhost]
+
+PLAY RECAP *********************************************************************
+localhost                  : ok=3    changed=2    unreachable=0    failed=0    skipped=1
+`
+
+	tasks := ParseTaskResults(output)
+	assert.Equal(t, []TaskResult{
+		{Name: "Gathering Facts", OK: 1},
+		{Name: "Install nginx", OK: 1, Changed: 1},
+		{Name: "Copy config [main]", Failed: 1, Skipped: 1},
+		{Name: "restart nginx", Changed: 1},
+	}, tasks)
+	assert.Nil(t, ParseTaskResults("ERROR! the playbook could not be found"))
+}
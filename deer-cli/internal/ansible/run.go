@@ -0,0 +1,109 @@
+package ansible
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// SandboxRunner is the part of sandbox.Service a playbook run needs.
+type SandboxRunner interface {
+	RunCommand(ctx context.Context, sandboxID, command string, timeoutSec int, env map[string]string) (*sandbox.CommandResult, error)
+}
+
+// outputStreamer is implemented by sandbox services that stream command
+// output as it is produced (the daemon-backed RemoteService).
+type outputStreamer interface {
+	RunCommandStreaming(ctx context.Context, sandboxID, command string, opts sandbox.RunOptions, onOutput sandbox.OutputCallback) (*sandbox.CommandResult, error)
+}
+
+// RunResult is the outcome of running a playbook in a sandbox. Success is
+// false when ansible-playbook exited non-zero or any host failed or was
+// unreachable.
+type RunResult struct {
+	PlaybookID string       `json:"playbook_id"`
+	SandboxID  string       `json:"sandbox_id"`
+	Success    bool         `json:"success"`
+	ExitCode   int          `json:"exit_code"`
+	Recap      []HostRecap  `json:"recap"`
+	Tasks      []TaskResult `json:"tasks"`
+	Stdout     string       `json:"stdout"`
+	Stderr     string       `json:"stderr"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// RunInSandbox uploads the playbook to the sandbox and runs it there with
+// ansible-playbook against the sandbox itself, over a local connection.
+// When onOutput is set and runner can stream, output is passed to it as it
+// arrives. If the run fails after starting, the partial result is returned
+// with the error.
+func (s *PlaybookService) RunInSandbox(ctx context.Context, runner SandboxRunner, playbookID, sandboxID string, timeoutSec int, onOutput sandbox.OutputCallback) (*RunResult, error) {
+	pb, err := s.store.GetPlaybook(ctx, playbookID)
+	if err != nil {
+		return nil, fmt.Errorf("get playbook: %w", err)
+	}
+	yamlContent, err := s.ExportPlaybook(ctx, playbookID)
+	if err != nil {
+		return nil, fmt.Errorf("export playbook: %w", err)
+	}
+
+	// The playbook runs inside the sandbox against itself, so every host
+	// pattern in the play resolves to a single local connection.
+	hosts := pb.Hosts
+	if hosts == "" || hosts == "all" {
+		hosts = "localhost"
+	}
+	remotePath := fmt.Sprintf("/tmp/deer-playbook-%s.yml", playbookID)
+
+	encoded := base64.StdEncoding.EncodeToString(yamlContent)
+	writeCmd := fmt.Sprintf("base64 -d > %s << '--DEER_B64--'\n%s\n--DEER_B64--", shellQuote(remotePath), encoded)
+	writeResult, err := runner.RunCommand(ctx, sandboxID, writeCmd, 0, nil)
+	if err != nil {
+		return nil, fmt.Errorf("upload playbook: %w", err)
+	}
+	if writeResult.ExitCode != 0 {
+		return nil, fmt.Errorf("upload playbook: exit code %d: %s", writeResult.ExitCode, strings.TrimSpace(writeResult.Stderr))
+	}
+
+	runCmd := fmt.Sprintf("ANSIBLE_NOCOLOR=1 ansible-playbook -i %s -c local %s", shellQuote(hosts+","), shellQuote(remotePath))
+	var result *sandbox.CommandResult
+	if streamer, ok := runner.(outputStreamer); ok && onOutput != nil {
+		result, err = streamer.RunCommandStreaming(ctx, sandboxID, runCmd, sandbox.RunOptions{TimeoutSec: timeoutSec}, onOutput)
+	} else {
+		result, err = runner.RunCommand(ctx, sandboxID, runCmd, timeoutSec, nil)
+	}
+
+	var run *RunResult
+	if result != nil {
+		run = &RunResult{
+			PlaybookID: playbookID,
+			SandboxID:  sandboxID,
+			Success:    result.ExitCode == 0,
+			ExitCode:   result.ExitCode,
+			Recap:      ParseRecap(result.Stdout),
+			Tasks:      ParseTaskResults(result.Stdout),
+			Stdout:     result.Stdout,
+			Stderr:     result.Stderr,
+		}
+		for _, r := range run.Recap {
+			if !r.Succeeded() {
+				run.Success = false
+			}
+		}
+		if result.ExitCode == 127 {
+			run.Error = "ansible-playbook not found in sandbox; install ansible-core first"
+		}
+	}
+	if err != nil {
+		return run, fmt.Errorf("run playbook: %w", err)
+	}
+	return run, nil
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
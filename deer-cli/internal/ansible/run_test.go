@@ -0,0 +1,92 @@
+package ansible
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+)
+
+// fakeRunner records commands and answers ansible-playbook with output.
+// fakeStreamingRunner also passes the output to the callback.
+type fakeRunner struct {
+	commands []string
+	output   string
+	exitCode int
+	runErr   error
+}
+
+func (f *fakeRunner) RunCommand(_ context.Context, _, command string, _ int, _ map[string]string) (*sandbox.CommandResult, error) {
+	f.commands = append(f.commands, command)
+	if strings.Contains(command, "ansible-playbook") {
+		return &sandbox.CommandResult{Stdout: f.output, ExitCode: f.exitCode}, f.runErr
+	}
+	return &sandbox.CommandResult{}, nil
+}
+
+type fakeStreamingRunner struct {
+	fakeRunner
+	timeoutSec int
+}
+
+func (f *fakeStreamingRunner) RunCommandStreaming(ctx context.Context, sandboxID, command string, opts sandbox.RunOptions, onOutput sandbox.OutputCallback) (*sandbox.CommandResult, error) {
+	f.timeoutSec = opts.TimeoutSec
+	onOutput(f.output, false)
+	return f.RunCommand(ctx, sandboxID, command, opts.TimeoutSec, nil)
+}
+
+const runOutput = `TASK [Install nginx] ***
+changed: [localhost]
+
+PLAY RECAP ***
+localhost : ok=1 changed=1 unreachable=0 failed=0 skipped=0
+`
+
+func TestRunInSandbox(t *testing.T) {
+	ms := newMockStore()
+	svc := NewPlaybookService(ms, t.TempDir())
+	pb, err := svc.CreatePlaybook(context.Background(), CreatePlaybookRequest{Name: "web"})
+	require.NoError(t, err)
+
+	runner := &fakeStreamingRunner{fakeRunner: fakeRunner{output: runOutput}}
+	var streamed strings.Builder
+	run, err := svc.RunInSandbox(context.Background(), runner, pb.ID, "SBX-1", 600, func(chunk string, _ bool) {
+		streamed.WriteString(chunk)
+	})
+	require.NoError(t, err)
+	assert.True(t, run.Success)
+	assert.Equal(t, runOutput, streamed.String())
+	assert.Equal(t, 600, runner.timeoutSec)
+	assert.Equal(t, []TaskResult{{Name: "Install nginx", Changed: 1}}, run.Tasks)
+	require.Len(t, runner.commands, 2)
+	assert.Contains(t, runner.commands[0], "base64 -d > '/tmp/deer-playbook-"+pb.ID+".yml'")
+	assert.Contains(t, runner.commands[1], "ansible-playbook -i 'localhost,' -c local")
+}
+
+func TestRunInSandbox_Failures(t *testing.T) {
+	ms := newMockStore()
+	svc := NewPlaybookService(ms, t.TempDir())
+	pb, err := svc.CreatePlaybook(context.Background(), CreatePlaybookRequest{Name: "web", Hosts: "webservers"})
+	require.NoError(t, err)
+
+	runner := &fakeRunner{exitCode: 127}
+	run, err := svc.RunInSandbox(context.Background(), runner, pb.ID, "SBX-1", 0, nil)
+	require.NoError(t, err)
+	assert.False(t, run.Success)
+	assert.Contains(t, run.Error, "install ansible-core")
+	assert.Contains(t, runner.commands[1], "-i 'webservers,'")
+
+	runner = &fakeRunner{output: "TASK [x] ***\n", runErr: errors.New("connection reset")}
+	run, err = svc.RunInSandbox(context.Background(), runner, pb.ID, "SBX-1", 0, nil)
+	assert.ErrorContains(t, err, "run playbook: connection reset")
+	require.NotNil(t, run)
+	assert.Equal(t, "TASK [x] ***\n", run.Stdout)
+
+	_, err = svc.RunInSandbox(context.Background(), runner, "missing", "SBX-1", 0, nil)
+	assert.ErrorContains(t, err, "get playbook")
+}
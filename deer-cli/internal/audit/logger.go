@@ -20,6 +20,7 @@ const (
 	TypeSessionStart = "session_start"
 	TypeSessionEnd   = "session_end"
 	TypeRedaction    = "redaction"
+	TypeApproval     = "approval"
 
 	genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
 )
@@ -121,6 +122,20 @@ func (l *Logger) LogToolCall(tool string, args map[string]any, result any, err e
 	l.write(entry)
 }
 
+// LogApproval records a human decision on whether tool may run with args.
+// Kind says what was being approved, such as "command" or "network".
+func (l *Logger) LogApproval(tool, kind string, args map[string]any, approved bool) {
+	l.write(&Entry{
+		Type: TypeApproval,
+		Tool: tool,
+		Args: args,
+		Meta: map[string]any{
+			"kind":     kind,
+			"approved": approved,
+		},
+	})
+}
+
 // LogLLMRequest records an outgoing LLM API request.
 func (l *Logger) LogLLMRequest(messageCount int, tokenEstimate int, model string) {
 	l.write(&Entry{
@@ -24,6 +24,7 @@ func TestWriteAndVerifyChain(t *testing.T) {
 	logger.LogLLMResponse(800, 2)
 	logger.LogToolCall("run_command", map[string]any{"cmd": "ls"}, "file1\nfile2", nil, 150)
 	logger.LogToolCall("edit_file", map[string]any{"path": "/tmp/x"}, nil, fmt.Errorf("permission denied"), 30)
+	logger.LogApproval("run_command", "command", map[string]any{"sandbox_id": "sbx-1", "command": "rm -rf /tmp/x"}, false)
 	logger.LogSessionEnd(2, 1)
 
 	if err := logger.Close(); err != nil {
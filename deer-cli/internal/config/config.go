@@ -24,11 +24,18 @@ type Config struct {
 	Logging                     LoggingConfig       `yaml:"logging"`
 	Telemetry                   TelemetryConfig     `yaml:"telemetry"`
 	AIAgent                     AIAgentConfig       `yaml:"ai_agent"`
-	Hosts                       []HostConfig        `yaml:"hosts"`         // Source hosts for read-only SSH access
-	SandboxHosts                []SandboxHostConfig `yaml:"sandbox_hosts"` // Daemon hosts for sandbox operations
+	Hosts                       []HostConfig        `yaml:"hosts"`               // Source hosts for read-only SSH access
+	SandboxHosts                []SandboxHostConfig `yaml:"sandbox_hosts"`       // Daemon hosts for sandbox operations
+	Templates                   []SandboxTemplate   `yaml:"templates,omitempty"` // Named presets for sandbox creation
 	Redact                      RedactConfig        `yaml:"redact"`
 	Audit                       AuditConfig         `yaml:"audit"`
+	MCP                         MCPConfig           `yaml:"mcp"`
+	NetworkPolicy               NetworkPolicyConfig `yaml:"network_policy"`
+	Readonly                    ReadonlyConfig      `yaml:"readonly"` // Site allow/deny lists for source host commands
+	Resources                   ResourcesConfig     `yaml:"resources"`
+	ToolApproval                ToolApprovalPolicy  `yaml:"tool_approval"` // Per-tool auto|confirm|deny for the TUI agent
 	ChatsDir                    string              `yaml:"chats_dir"`
+	VMCacheTTL                  time.Duration       `yaml:"vm_cache_ttl"`                   // How long 'deer vms' serves the cached source VM inventory
 	ExtraAllowedCommands        []string            `yaml:"extra_allowed_commands"`         // Additional commands allowed in read-only mode
 	ExtraAllowedSubcommands     map[string][]string `yaml:"extra_allowed_subcommands"`      // Additional subcommands allowed for specific commands
 	ExtraAllowedSubcommandsMode map[string]bool     `yaml:"extra_allowed_subcommands_mode"` // true = allowlist (block all except), false = blocklist (allow all except)
@@ -48,6 +55,18 @@ type SandboxHostConfig struct {
 	DaemonIdentityPubKey string `yaml:"daemon_identity_pub_key,omitempty"`
 }
 
+// SandboxTemplate is a named preset of sandbox creation parameters. Zero
+// fields leave the daemon default in place.
+type SandboxTemplate struct {
+	Name      string `yaml:"name" json:"name"`
+	SourceVM  string `yaml:"source_vm" json:"source_vm"`
+	CPU       int    `yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	MemoryMB  int    `yaml:"memory_mb,omitempty" json:"memory_mb,omitempty"`
+	Live      bool   `yaml:"live,omitempty" json:"live,omitempty"`
+	KafkaStub bool   `yaml:"kafka_stub,omitempty" json:"kafka_stub,omitempty"`
+	ESStub    bool   `yaml:"es_stub,omitempty" json:"es_stub,omitempty"`
+}
+
 // DaemonIdentityPubKey returns the first non-empty daemon identity pub key
 // from the configured sandbox hosts.
 func DaemonIdentityPubKey(hosts []SandboxHostConfig) string {
@@ -66,6 +85,31 @@ type RedactConfig struct {
 	Allowlist      []string `yaml:"allowlist"`
 }
 
+// NetworkPolicyConfig controls approval of sandbox commands that reach the
+// network. Hosts match exactly or as a parent domain, so "example.com" also
+// covers "api.example.com".
+type NetworkPolicyConfig struct {
+	Tools      []string `yaml:"tools"`       // Extra commands treated as network tools, e.g. aws, gh
+	AllowHosts []string `yaml:"allow_hosts"` // Auto-approve when every destination matches one of these
+	DenyHosts  []string `yaml:"deny_hosts"`  // Auto-deny when any destination matches one of these
+}
+
+// ReadonlyConfig adjusts the built-in allowlist for commands run on source
+// hosts. Deny always wins over Allow and over the built-in list.
+type ReadonlyConfig struct {
+	Allow []string `yaml:"allow"` // Extra commands treated as read-only, e.g. tcpdump
+	Deny  []string `yaml:"deny"`  // Commands to refuse even if built in, e.g. find
+}
+
+// ResourcesConfig controls when creating a sandbox needs a human's
+// approval because of the host's free resources.
+type ResourcesConfig struct {
+	// MemoryOvercommitRatio is how far a sandbox may exceed the host's free
+	// memory without asking, e.g. 1.2 allows 20% over. KSM and ballooning
+	// make modest overcommit safe. Values below 1 are treated as 1.
+	MemoryOvercommitRatio float64 `yaml:"memory_overcommit_ratio"`
+}
+
 // AuditConfig controls the hash-chained audit log.
 type AuditConfig struct {
 	Enabled   bool   `yaml:"enabled"`
@@ -106,7 +150,7 @@ type ProxmoxConfig struct {
 
 // AIAgentConfig holds settings for LLM integration.
 type AIAgentConfig struct {
-	Provider      string `yaml:"provider"` // e.g., "openrouter"
+	Provider      string `yaml:"provider"` // openrouter (default), anthropic, or openai
 	APIKey        string `yaml:"api_key"`
 	Model         string `yaml:"model"`
 	Endpoint      string `yaml:"endpoint"`
@@ -118,6 +162,8 @@ type AIAgentConfig struct {
 	CompactModel       string  `yaml:"compact_model"`      // Smaller model for compaction (default: Claude 4.5 Haiku)
 	CompactThreshold   float64 `yaml:"compact_threshold"`  // Auto-compact at this % of context (default: 0.9)
 	TokensPerChar      float64 `yaml:"tokens_per_char"`    // Estimated tokens per character (default: 0.25)
+	// Session cleanup
+	CleanupConcurrency int `yaml:"cleanup_concurrency"` // Sandboxes destroyed in parallel on exit (default: 4)
 }
 
 // TelemetryConfig holds telemetry settings.
@@ -151,6 +197,7 @@ type SSHConfig struct {
 	CertTTL      time.Duration `yaml:"cert_ttl"`
 	MaxTTL       time.Duration `yaml:"max_ttl"`
 	DefaultUser  string        `yaml:"default_user"`
+	SourceVMUser string        `yaml:"source_vm_user"` // Admin user 'source prepare' logs in as when the host sets none (default: from ~/.ssh/config)
 }
 
 // AnsibleConfig holds Ansible runner settings.
@@ -161,6 +208,57 @@ type AnsibleConfig struct {
 	AllowedPlaybooks []string `yaml:"allowed_playbooks"`
 }
 
+// MCPConfig holds settings for the MCP server started by `deer mcp`.
+type MCPConfig struct {
+	DisabledTools      []string `yaml:"disabled_tools"`       // Tool names that are not exposed to MCP clients
+	HistoryOutputLimit int      `yaml:"history_output_limit"` // Bytes of stdout/stderr kept per command by get_command_history (0 = default)
+}
+
+// ToolEnabled reports whether the named MCP tool should be registered.
+func (c MCPConfig) ToolEnabled(name string) bool {
+	for _, t := range c.DisabledTools {
+		if t == name {
+			return false
+		}
+	}
+	return true
+}
+
+// Tool approval policies.
+const (
+	ToolApprovalAuto    = "auto"
+	ToolApprovalConfirm = "confirm"
+	ToolApprovalDeny    = "deny"
+)
+
+// ToolApprovalPolicy maps agent tool names to auto (run), confirm (ask the
+// human first) or deny (refuse). Tools it does not list fall back to
+// defaultToolApproval, then to auto.
+type ToolApprovalPolicy map[string]string
+
+// defaultToolApproval asks before the agent's irreversible tools.
+var defaultToolApproval = ToolApprovalPolicy{
+	"destroy_sandbox": ToolApprovalConfirm,
+	"edit_file":       ToolApprovalConfirm,
+}
+
+// Decision returns the policy for tool. An unrecognised value counts as
+// confirm, so a typo never removes a guardrail.
+func (p ToolApprovalPolicy) Decision(tool string) string {
+	v, ok := p[tool]
+	if !ok {
+		v, ok = defaultToolApproval[tool]
+	}
+	if !ok {
+		return ToolApprovalAuto
+	}
+	switch v {
+	case ToolApprovalAuto, ToolApprovalConfirm, ToolApprovalDeny:
+		return v
+	}
+	return ToolApprovalConfirm
+}
+
 // LoggingConfig holds logging settings.
 type LoggingConfig struct {
 	Level  string `yaml:"level"`
@@ -180,6 +278,7 @@ type HostConfig struct {
 	DirectAccess  bool          `yaml:"direct_access"`   // VMs reachable without proxy jump (bridged networking)
 	QueryTimeout  time.Duration `yaml:"query_timeout"`   // Per-host query timeout (default: 30s)
 	Prepared      bool          `yaml:"prepared"`        // Whether deer-readonly user has been set up
+	SourceVMUser  string        `yaml:"source_vm_user"`  // Admin user 'source prepare' logs in as, e.g. ubuntu on cloud images (default: ssh.source_vm_user)
 }
 
 // mustConfigDir returns the config directory, falling back to a best-effort default.
@@ -193,6 +292,22 @@ func mustConfigDir() string {
 	return dir
 }
 
+// providerDefaults are the endpoint and models used for ai_agent providers
+// other than OpenRouter when the config leaves them at the OpenRouter
+// defaults.
+var providerDefaults = map[string]AIAgentConfig{
+	"anthropic": {
+		Endpoint:     "https://api.anthropic.com/v1",
+		Model:        "claude-sonnet-4-5",
+		CompactModel: "claude-haiku-4-5",
+	},
+	"openai": {
+		Endpoint:     "https://api.openai.com/v1",
+		Model:        "gpt-4.1",
+		CompactModel: "gpt-4.1-mini",
+	},
+}
+
 // DefaultConfig returns config with sensible defaults.
 func DefaultConfig() *Config {
 	configDir := mustConfigDir()
@@ -220,6 +335,7 @@ func DefaultConfig() *Config {
 			WorkDir:            "/var/lib/libvirt/images/jobs",
 			SSHKeyInjectMethod: "virt-customize",
 		},
+		VMCacheTTL: 5 * time.Minute,
 		VM: VMConfig{
 			DefaultVCPUs:       2,
 			DefaultMemoryMB:    4096,
@@ -249,6 +365,9 @@ func DefaultConfig() *Config {
 			LogPath:   filepath.Join(configDir, "audit.jsonl"),
 			MaxSizeMB: 50,
 		},
+		Resources: ResourcesConfig{
+			MemoryOvercommitRatio: 1.0,
+		},
 		ChatsDir: filepath.Join(configDir, "chats"),
 		AIAgent: AIAgentConfig{
 			Provider: "openrouter",
@@ -392,11 +511,27 @@ func applyDefaults(cfg *Config) {
 	if cfg.VM.IPDiscoveryTimeout == 0 {
 		cfg.VM.IPDiscoveryTimeout = defaults.VM.IPDiscoveryTimeout
 	}
+	if cfg.VMCacheTTL == 0 {
+		cfg.VMCacheTTL = defaults.VMCacheTTL
+	}
 
 	// AIAgent defaults
 	if cfg.AIAgent.Provider == "" {
 		cfg.AIAgent.Provider = defaults.AIAgent.Provider
 	}
+	if pd, ok := providerDefaults[cfg.AIAgent.Provider]; ok {
+		// Configs written before the provider was switched still carry
+		// OpenRouter's endpoint and model IDs.
+		if cfg.AIAgent.Endpoint == "" || cfg.AIAgent.Endpoint == defaults.AIAgent.Endpoint {
+			cfg.AIAgent.Endpoint = pd.Endpoint
+		}
+		if cfg.AIAgent.Model == "" || cfg.AIAgent.Model == defaults.AIAgent.Model {
+			cfg.AIAgent.Model = pd.Model
+		}
+		if cfg.AIAgent.CompactModel == "" || cfg.AIAgent.CompactModel == defaults.AIAgent.CompactModel {
+			cfg.AIAgent.CompactModel = pd.CompactModel
+		}
+	}
 	if cfg.AIAgent.Model == "" {
 		cfg.AIAgent.Model = defaults.AIAgent.Model
 	}
@@ -419,6 +554,11 @@ func applyDefaults(cfg *Config) {
 		cfg.AIAgent.TokensPerChar = defaults.AIAgent.TokensPerChar
 	}
 
+	// Resources defaults
+	if cfg.Resources.MemoryOvercommitRatio == 0 {
+		cfg.Resources.MemoryOvercommitRatio = defaults.Resources.MemoryOvercommitRatio
+	}
+
 	// Audit defaults
 	if cfg.Audit.LogPath == "" {
 		cfg.Audit.LogPath = defaults.Audit.LogPath
@@ -497,7 +637,14 @@ func applyEnvOverrides(cfg *Config) {
 	}
 
 	// Prioritize environment variables for API Key
-	if v := os.Getenv("OPENROUTER_API_KEY"); v != "" {
+	keyEnv := "OPENROUTER_API_KEY"
+	switch cfg.AIAgent.Provider {
+	case "anthropic":
+		keyEnv = "ANTHROPIC_API_KEY"
+	case "openai":
+		keyEnv = "OPENAI_API_KEY"
+	}
+	if v := os.Getenv(keyEnv); v != "" {
 		cfg.AIAgent.APIKey = v
 	}
 
@@ -572,6 +719,17 @@ func (c *Config) HasSandboxHosts() bool {
 	return len(c.SandboxHosts) > 0
 }
 
+// SandboxHost returns the sandbox host with the given name or daemon address.
+// An empty name selects the first configured host.
+func (c *Config) SandboxHost(name string) (SandboxHostConfig, bool) {
+	for _, sh := range c.SandboxHosts {
+		if name == "" || sh.Name == name || sh.DaemonAddress == name {
+			return sh, true
+		}
+	}
+	return SandboxHostConfig{}, false
+}
+
 // UpsertSandboxHost updates an existing host entry matched by name or address,
 // or appends a new entry. Removes any remaining entries that conflict on name
 // or address after the update to prevent duplicates. Returns the updated slice.
@@ -606,6 +764,38 @@ func UpsertSandboxHost(hosts []SandboxHostConfig, entry SandboxHostConfig) []San
 	return result
 }
 
+// Template returns the sandbox template with the given name.
+func (c *Config) Template(name string) (SandboxTemplate, bool) {
+	for _, t := range c.Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return SandboxTemplate{}, false
+}
+
+// UpsertTemplate replaces the template with the same name or appends it.
+func (c *Config) UpsertTemplate(t SandboxTemplate) {
+	for i := range c.Templates {
+		if c.Templates[i].Name == t.Name {
+			c.Templates[i] = t
+			return
+		}
+	}
+	c.Templates = append(c.Templates, t)
+}
+
+// RemoveTemplate deletes the named template. Returns false if it did not exist.
+func (c *Config) RemoveTemplate(name string) bool {
+	for i, t := range c.Templates {
+		if t.Name == name {
+			c.Templates = append(c.Templates[:i], c.Templates[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // PreparedHosts returns only the hosts that have been prepared for read-only access.
 func (c *Config) PreparedHosts() []HostConfig {
 	var result []HostConfig
@@ -617,14 +807,22 @@ func (c *Config) PreparedHosts() []HostConfig {
 	return result
 }
 
-// Save writes the current config back to a YAML file.
+// Save writes the current config back to a YAML file. The file is replaced
+// atomically, so a crash mid-save leaves the previous config in place, and
+// concurrent saves from several deer processes are serialized.
 func (c *Config) Save(path string) error {
 	data, err := yaml.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0o600); err != nil {
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking config file: %w", err)
+	}
+	defer unlock()
+
+	if err := writeFileAtomic(path, data, 0o600); err != nil {
 		return fmt.Errorf("writing config file: %w", err)
 	}
 
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
@@ -132,6 +135,28 @@ func TestApplyEnvOverrides_AllFields(t *testing.T) {
 	assert.Equal(t, "test-api-key", cfg.AIAgent.APIKey)
 }
 
+func TestApplyEnvOverrides_ProviderAPIKey(t *testing.T) {
+	t.Setenv("OPENROUTER_API_KEY", "openrouter-key")
+	t.Setenv("ANTHROPIC_API_KEY", "anthropic-key")
+
+	cfg := DefaultConfig()
+	cfg.AIAgent.Provider = "anthropic"
+	applyEnvOverrides(cfg)
+	assert.Equal(t, "anthropic-key", cfg.AIAgent.APIKey)
+}
+
+func TestToolApprovalPolicy_Decision(t *testing.T) {
+	var unset ToolApprovalPolicy
+	assert.Equal(t, ToolApprovalConfirm, unset.Decision("destroy_sandbox"))
+	assert.Equal(t, ToolApprovalConfirm, unset.Decision("edit_file"))
+	assert.Equal(t, ToolApprovalAuto, unset.Decision("stop_sandbox"))
+
+	p := ToolApprovalPolicy{"destroy_sandbox": "auto", "stop_sandbox": "deny", "edit_file": "ask"}
+	assert.Equal(t, ToolApprovalAuto, p.Decision("destroy_sandbox"))
+	assert.Equal(t, ToolApprovalDeny, p.Decision("stop_sandbox"))
+	assert.Equal(t, ToolApprovalConfirm, p.Decision("edit_file"), "unknown values fall back to confirm")
+}
+
 func TestDefaultConfig_ProxmoxDefaults(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -324,6 +349,28 @@ func TestApplyDefaults_ProxmoxDefaults(t *testing.T) {
 	assert.Equal(t, 9999, cfg.Proxmox.VMIDEnd)
 }
 
+func TestApplyDefaults_AIProviderDefaults(t *testing.T) {
+	cfg := &Config{AIAgent: AIAgentConfig{Provider: "anthropic"}}
+	applyDefaults(cfg)
+	assert.Equal(t, "https://api.anthropic.com/v1", cfg.AIAgent.Endpoint)
+	assert.Equal(t, "claude-sonnet-4-5", cfg.AIAgent.Model)
+	assert.Equal(t, "claude-haiku-4-5", cfg.AIAgent.CompactModel)
+
+	// A config saved with the OpenRouter defaults picks up the new
+	// provider's; explicit choices are kept.
+	saved := DefaultConfig().AIAgent
+	cfg = &Config{AIAgent: AIAgentConfig{Provider: "openai", Endpoint: saved.Endpoint, Model: "gpt-5", CompactModel: saved.CompactModel}}
+	applyDefaults(cfg)
+	assert.Equal(t, "https://api.openai.com/v1", cfg.AIAgent.Endpoint)
+	assert.Equal(t, "gpt-5", cfg.AIAgent.Model)
+	assert.Equal(t, "gpt-4.1-mini", cfg.AIAgent.CompactModel)
+
+	cfg = &Config{}
+	applyDefaults(cfg)
+	assert.Equal(t, saved.Endpoint, cfg.AIAgent.Endpoint)
+	assert.Equal(t, saved.Model, cfg.AIAgent.Model)
+}
+
 func TestCheckFilePermissions_SecureFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "config.yaml")
@@ -498,3 +545,132 @@ proxmox:
 	assert.Contains(t, warnings[0], "insecure permissions")
 	assert.Contains(t, warnings[1], "contains secrets")
 }
+
+func TestMCPConfig_ToolEnabled(t *testing.T) {
+	cfg := MCPConfig{DisabledTools: []string{"run_playbook"}}
+	assert.False(t, cfg.ToolEnabled("run_playbook"))
+	assert.True(t, cfg.ToolEnabled("export_playbook"))
+	assert.True(t, MCPConfig{}.ToolEnabled("run_playbook"))
+}
+
+func TestSandboxHost(t *testing.T) {
+	cfg := &Config{SandboxHosts: []SandboxHostConfig{
+		{Name: "alpha", DaemonAddress: "10.0.0.1:9091"},
+		{Name: "beta", DaemonAddress: "10.0.0.2:9091"},
+	}}
+
+	sh, ok := cfg.SandboxHost("")
+	require.True(t, ok)
+	assert.Equal(t, "alpha", sh.Name)
+
+	sh, ok = cfg.SandboxHost("beta")
+	require.True(t, ok)
+	assert.Equal(t, "10.0.0.2:9091", sh.DaemonAddress)
+
+	sh, ok = cfg.SandboxHost("10.0.0.1:9091")
+	require.True(t, ok)
+	assert.Equal(t, "alpha", sh.Name)
+
+	_, ok = cfg.SandboxHost("gamma")
+	assert.False(t, ok)
+
+	_, ok = (&Config{}).SandboxHost("")
+	assert.False(t, ok)
+}
+
+func TestTemplates_UpsertAndRemove(t *testing.T) {
+	cfg := &Config{}
+	cfg.UpsertTemplate(SandboxTemplate{Name: "web", SourceVM: "ubuntu", CPU: 2})
+	cfg.UpsertTemplate(SandboxTemplate{Name: "db", SourceVM: "postgres"})
+	cfg.UpsertTemplate(SandboxTemplate{Name: "web", SourceVM: "ubuntu", CPU: 4, MemoryMB: 8192})
+
+	require.Len(t, cfg.Templates, 2)
+	tmpl, ok := cfg.Template("web")
+	require.True(t, ok)
+	assert.Equal(t, 4, tmpl.CPU)
+	assert.Equal(t, 8192, tmpl.MemoryMB)
+
+	assert.True(t, cfg.RemoveTemplate("web"))
+	assert.False(t, cfg.RemoveTemplate("web"))
+	_, ok = cfg.Template("web")
+	assert.False(t, ok)
+	assert.Len(t, cfg.Templates, 1)
+}
+
+func TestSave_PreservesTemplates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := DefaultConfig()
+	cfg.UpsertTemplate(SandboxTemplate{Name: "web", SourceVM: "ubuntu", CPU: 4, MemoryMB: 8192, KafkaStub: true})
+	require.NoError(t, cfg.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	tmpl, ok := loaded.Template("web")
+	require.True(t, ok)
+	assert.Equal(t, cfg.Templates[0], tmpl)
+}
+
+func TestSave_InterruptedWriteKeepsOldConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	cfg := DefaultConfig()
+	cfg.UpsertTemplate(SandboxTemplate{Name: "web", SourceVM: "ubuntu"})
+	require.NoError(t, cfg.Save(path))
+
+	// Fail as if the process died after writing the temp file but before
+	// moving it into place.
+	renameFile = func(string, string) error { return errors.New("killed") }
+	t.Cleanup(func() { renameFile = os.Rename })
+	cfg.UpsertTemplate(SandboxTemplate{Name: "db", SourceVM: "postgres"})
+	require.Error(t, cfg.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, loaded.Templates, 1, "the interrupted save must not touch the existing config")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.NotContains(t, e.Name(), ".tmp-", "temp file left behind")
+	}
+}
+
+func TestSave_ConcurrentSavesLeaveAValidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cfg := DefaultConfig()
+			for j := range 50 {
+				cfg.UpsertTemplate(SandboxTemplate{Name: fmt.Sprintf("t%d-%d", i, j), SourceVM: "ubuntu"})
+			}
+			assert.NoError(t, cfg.Save(path))
+		}(i)
+	}
+	wg.Wait()
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Len(t, loaded.Templates, 50, "the file should hold exactly one writer's config")
+}
+
+func TestSave_FollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.yaml")
+	link := filepath.Join(dir, "config.yaml")
+	require.NoError(t, DefaultConfig().Save(target))
+	require.NoError(t, os.Symlink(target, link))
+
+	cfg := DefaultConfig()
+	cfg.UpsertTemplate(SandboxTemplate{Name: "web", SourceVM: "ubuntu"})
+	require.NoError(t, cfg.Save(link))
+
+	fi, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, fi.Mode()&os.ModeSymlink, "save replaced the symlink with a file")
+	loaded, err := Load(target)
+	require.NoError(t, err)
+	assert.Len(t, loaded.Templates, 1)
+}
@@ -38,11 +38,11 @@ func checkDaemonBinary(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "daemon-binary",
-		Category: "binary",
-		Passed:   false,
-		Message:  "deer-daemon binary not found",
-		FixCmd:   "sudo apt install deer-daemon  # or see https://deer.sh/docs/daemon",
+		Name:        "daemon-binary",
+		Category:    "binary",
+		Passed:      false,
+		Message:     "deer-daemon binary not found",
+		Remediation: "sudo apt install deer-daemon  # or see https://deer.sh/docs/daemon",
 	}
 }
 
@@ -57,11 +57,11 @@ func checkGRPCPort(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "grpc-port",
-		Category: "connectivity",
-		Passed:   false,
-		Message:  "gRPC port :9091 not listening",
-		FixCmd:   "sudo systemctl start deer-daemon",
+		Name:        "grpc-port",
+		Category:    "connectivity",
+		Passed:      false,
+		Message:     "gRPC port :9091 not listening",
+		Remediation: "sudo systemctl start deer-daemon",
 	}
 }
 
@@ -76,11 +76,11 @@ func checkSystemdActive(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "systemd-active",
-		Category: "service",
-		Passed:   false,
-		Message:  "deer-daemon service not active",
-		FixCmd:   "sudo systemctl start deer-daemon",
+		Name:        "systemd-active",
+		Category:    "service",
+		Passed:      false,
+		Message:     "deer-daemon service not active",
+		Remediation: "sudo systemctl start deer-daemon",
 	}
 }
 
@@ -95,11 +95,11 @@ func checkSystemdEnabled(ctx context.Context, run hostexec.RunFunc) CheckResult
 		}
 	}
 	return CheckResult{
-		Name:     "systemd-enabled",
-		Category: "service",
-		Passed:   false,
-		Message:  "deer-daemon service not enabled at boot",
-		FixCmd:   "sudo systemctl enable deer-daemon",
+		Name:        "systemd-enabled",
+		Category:    "service",
+		Passed:      false,
+		Message:     "deer-daemon service not enabled at boot",
+		Remediation: "sudo systemctl enable --now deer-daemon",
 	}
 }
 
@@ -114,11 +114,11 @@ func checkLibvirtRunning(ctx context.Context, run hostexec.RunFunc) CheckResult
 		}
 	}
 	return CheckResult{
-		Name:     "libvirt-running",
-		Category: "prerequisites",
-		Passed:   false,
-		Message:  "libvirt not running",
-		FixCmd:   "sudo apt install -y libvirt-daemon-system && sudo systemctl start libvirtd",
+		Name:        "libvirt-running",
+		Category:    "prerequisites",
+		Passed:      false,
+		Message:     "libvirt not running",
+		Remediation: "sudo apt install -y libvirt-daemon-system && sudo systemctl start libvirtd",
 	}
 }
 
@@ -133,11 +133,11 @@ func checkKVMAvailable(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "kvm-available",
-		Category: "prerequisites",
-		Passed:   false,
-		Message:  "KVM not available (/dev/kvm missing)",
-		FixCmd:   "sudo modprobe kvm && sudo modprobe kvm_intel || sudo modprobe kvm_amd. If this fails, you may be on a virtualized cloud host (e.g., Hetzner Cloud) that doesn't support nested KVM. Use a dedicated server instead.",
+		Name:        "kvm-available",
+		Category:    "prerequisites",
+		Passed:      false,
+		Message:     "KVM not available (/dev/kvm missing)",
+		Remediation: "sudo modprobe kvm && sudo modprobe kvm_intel || sudo modprobe kvm_amd. If this fails, you may be on a virtualized cloud host (e.g., Hetzner Cloud) that doesn't support nested KVM. Use a dedicated server instead.",
 	}
 }
 
@@ -152,11 +152,11 @@ func checkQEMUBinary(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "qemu-binary",
-		Category: "binary",
-		Passed:   false,
-		Message:  "no qemu-system binary found on daemon host",
-		FixCmd:   "sudo apt install -y qemu-system-x86",
+		Name:        "qemu-binary",
+		Category:    "binary",
+		Passed:      false,
+		Message:     "no qemu-system binary found on daemon host",
+		Remediation: "sudo apt install -y qemu-system-x86",
 	}
 }
 
@@ -171,11 +171,11 @@ func checkKernelTools(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "kernel-tools",
-		Category: "prerequisites",
-		Passed:   false,
-		Message:  "kernel extraction tools missing (need virt-cat or qemu-nbd)",
-		FixCmd:   "sudo apt install -y libguestfs-tools",
+		Name:        "kernel-tools",
+		Category:    "prerequisites",
+		Passed:      false,
+		Message:     "kernel extraction tools missing (need virt-cat or qemu-nbd)",
+		Remediation: "sudo apt install -y libguestfs-tools",
 	}
 }
 
@@ -190,11 +190,11 @@ func checkStorageDirs(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "storage-dirs",
-		Category: "storage",
-		Passed:   false,
-		Message:  "storage directories missing (/var/lib/deer-daemon/{images,overlays})",
-		FixCmd:   "sudo mkdir -p /var/lib/deer-daemon/images /var/lib/deer-daemon/overlays",
+		Name:        "storage-dirs",
+		Category:    "storage",
+		Passed:      false,
+		Message:     "storage directories missing (/var/lib/deer-daemon/{images,overlays})",
+		Remediation: "sudo mkdir -p /var/lib/deer-daemon/images /var/lib/deer-daemon/overlays",
 	}
 }
 
@@ -209,10 +209,10 @@ func checkDaemonConfig(ctx context.Context, run hostexec.RunFunc) CheckResult {
 		}
 	}
 	return CheckResult{
-		Name:     "daemon-config",
-		Category: "config",
-		Passed:   false,
-		Message:  "daemon config not found",
-		FixCmd:   "Run the guided setup in onboarding or create /etc/deer-daemon/daemon.yaml",
+		Name:        "daemon-config",
+		Category:    "config",
+		Passed:      false,
+		Message:     "daemon config not found",
+		Remediation: "Run the guided setup in onboarding or create /etc/deer-daemon/daemon.yaml",
 	}
 }
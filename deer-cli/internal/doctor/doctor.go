@@ -2,6 +2,7 @@ package doctor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 
@@ -10,11 +11,22 @@ import (
 
 // CheckResult holds the outcome of a single doctor check.
 type CheckResult struct {
-	Name     string
-	Category string // "connectivity", "binary", "service", "prerequisites", "storage", "config"
-	Passed   bool
-	Message  string
-	FixCmd   string // empty if passed
+	Name     string `json:"name"`
+	Category string `json:"category"` // "connectivity", "binary", "service", "prerequisites", "storage", "config", "source-vm"
+	Passed   bool   `json:"passed"`
+	Message  string `json:"detail"`
+
+	// Remediation tells the operator how to fix a failed check, usually as
+	// a command to run. Empty if the check passed.
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Status returns "pass" or "fail".
+func (r CheckResult) Status() string {
+	if r.Passed {
+		return "pass"
+	}
+	return "fail"
 }
 
 // RunAll executes all doctor checks and returns results.
@@ -53,8 +65,8 @@ func PrintResults(results []CheckResult, w io.Writer, color bool) bool {
 			}
 		}
 		_, _ = fmt.Fprintf(w, "  %s%s %s%s\n", colorStart, icon, r.Message, colorEnd)
-		if !r.Passed && r.FixCmd != "" {
-			_, _ = fmt.Fprintf(w, "     Fix: %s\n", r.FixCmd)
+		if !r.Passed && r.Remediation != "" {
+			_, _ = fmt.Fprintf(w, "     Fix: %s\n", r.Remediation)
 		}
 	}
 
@@ -67,3 +79,40 @@ func PrintResults(results []CheckResult, w io.Writer, color bool) bool {
 
 	return allPassed
 }
+
+// jsonCheck is a CheckResult with its status spelled out for JSON consumers.
+type jsonCheck struct {
+	CheckResult
+	Status string `json:"status"`
+}
+
+// jsonReport is the document written by WriteJSON. OK is the overall
+// health flag automation should gate on.
+type jsonReport struct {
+	OK     bool        `json:"ok"`
+	Passed int         `json:"passed"`
+	Failed int         `json:"failed"`
+	Checks []jsonCheck `json:"checks"`
+}
+
+// WriteJSON writes check results to w as a single JSON document. Returns true
+// if all checks passed.
+func WriteJSON(results []CheckResult, w io.Writer) (bool, error) {
+	report := jsonReport{Checks: make([]jsonCheck, 0, len(results))}
+	for _, r := range results {
+		if r.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Checks = append(report.Checks, jsonCheck{CheckResult: r, Status: r.Status()})
+	}
+	report.OK = report.Failed == 0
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return false, fmt.Errorf("encode doctor results: %w", err)
+	}
+	return report.OK, nil
+}
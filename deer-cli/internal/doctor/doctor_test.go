@@ -3,6 +3,7 @@ package doctor
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -79,7 +80,7 @@ func TestRunAllMixedFailures(t *testing.T) {
 		if r.Passed {
 			passCount++
 		} else {
-			assert.NotEmpty(t, r.FixCmd, "failed check %s should have a fix command", r.Name)
+			assert.NotEmpty(t, r.Remediation, "failed check %s should have a fix command", r.Name)
 		}
 	}
 	assert.Equal(t, 2, passCount)
@@ -100,7 +101,7 @@ func TestPrintResultsAllPass(t *testing.T) {
 func TestPrintResultsWithFailures(t *testing.T) {
 	results := []CheckResult{
 		{Name: "test1", Passed: true, Message: "check 1 ok"},
-		{Name: "test2", Passed: false, Message: "check 2 failed", FixCmd: "fix it"},
+		{Name: "test2", Passed: false, Message: "check 2 failed", Remediation: "fix it"},
 	}
 
 	var buf bytes.Buffer
@@ -113,7 +114,7 @@ func TestPrintResultsWithFailures(t *testing.T) {
 func TestPrintResultsWithColor(t *testing.T) {
 	results := []CheckResult{
 		{Name: "test1", Passed: true, Message: "ok"},
-		{Name: "test2", Passed: false, Message: "fail", FixCmd: "fix"},
+		{Name: "test2", Passed: false, Message: "fail", Remediation: "fix"},
 	}
 
 	var buf bytes.Buffer
@@ -122,3 +123,56 @@ func TestPrintResultsWithColor(t *testing.T) {
 	assert.Contains(t, buf.String(), "\033[32m") // green
 	assert.Contains(t, buf.String(), "\033[31m") // red
 }
+
+func TestWriteJSON(t *testing.T) {
+	results := []CheckResult{
+		{Name: "grpc-port", Category: "connectivity", Passed: true, Message: "gRPC port :9091 listening"},
+		{Name: "kvm-available", Category: "prerequisites", Passed: false, Message: "KVM not available", Remediation: "sudo modprobe kvm"},
+	}
+
+	var buf bytes.Buffer
+	allPassed, err := WriteJSON(results, &buf)
+	assert.NoError(t, err)
+	assert.False(t, allPassed)
+
+	var report struct {
+		OK     bool `json:"ok"`
+		Passed int  `json:"passed"`
+		Failed int  `json:"failed"`
+		Checks []struct {
+			Name        string `json:"name"`
+			Status      string `json:"status"`
+			Detail      string `json:"detail"`
+			Remediation string `json:"remediation"`
+		} `json:"checks"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.False(t, report.OK)
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, "pass", report.Checks[0].Status)
+	assert.Equal(t, "fail", report.Checks[1].Status)
+	assert.Equal(t, "KVM not available", report.Checks[1].Detail)
+	assert.Equal(t, "sudo modprobe kvm", report.Checks[1].Remediation)
+	assert.NotContains(t, buf.String(), "\033[")
+}
+
+func TestWriteJSONHealthy(t *testing.T) {
+	var buf bytes.Buffer
+	allPassed, err := WriteJSON([]CheckResult{{Name: "grpc-port", Passed: true}}, &buf)
+	assert.NoError(t, err)
+	assert.True(t, allPassed)
+	assert.Contains(t, buf.String(), `"ok": true`)
+	assert.NotContains(t, buf.String(), "remediation", "passing checks carry no remediation")
+}
+
+func TestRunAllFailuresHaveRemediation(t *testing.T) {
+	run := func(ctx context.Context, command string) (string, string, int, error) {
+		return "", "", 1, nil
+	}
+	for _, r := range RunAll(context.Background(), run) {
+		assert.False(t, r.Passed, "check %s should fail", r.Name)
+		assert.NotEmpty(t, r.Remediation, "failed check %s has no remediation", r.Name)
+	}
+}
@@ -0,0 +1,211 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/hostexec"
+)
+
+// Paths and names set up on a source VM by 'deer source prepare'.
+const (
+	readonlyUser       = "deer-readonly"
+	restrictedShell    = "/usr/local/bin/deer-readonly-shell"
+	defaultCAKeyFile   = "/etc/ssh/deer_ca.pub"
+	principalsFileTmpl = "/etc/ssh/authorized_principals/%s"
+)
+
+// RunSourceVM checks that the source VM reached through run will accept the
+// daemon's read-only SSH certificates: sshd trusts a CA key file, that file
+// holds caPubKey, and the deer-readonly user, its principals and its
+// restricted shell exist. caPubKey may be empty when no daemon is connected;
+// the CA is then reported as unverified. Checks after a failed SSH
+// connection are skipped.
+func RunSourceVM(ctx context.Context, run hostexec.RunFunc, vmName, caPubKey string) []CheckResult {
+	fix := "deer source prepare " + vmName
+
+	if _, stderr, code, err := run(ctx, "true"); err != nil || code != 0 {
+		msg := strings.TrimSpace(stderr)
+		if err != nil {
+			msg = err.Error()
+		}
+		return []CheckResult{{
+			Name:        "source-ssh",
+			Category:    "source-vm",
+			Message:     fmt.Sprintf("cannot SSH to %s as an administrator: %s", vmName, orUnknown(msg)),
+			Remediation: "ssh " + vmName + "  # check ~/.ssh/config, or pass --host to go through its hypervisor",
+		}}
+	}
+
+	results := []CheckResult{{
+		Name:     "source-ssh",
+		Category: "source-vm",
+		Passed:   true,
+		Message:  fmt.Sprintf("SSH to %s works", vmName),
+	}}
+	caFile, caFileResult := checkTrustedCAKeysConfig(ctx, run, fix)
+	results = append(results,
+		caFileResult,
+		checkCAKeyTrusted(ctx, run, caFile, caPubKey, fix),
+		checkReadonlyUser(ctx, run, fix),
+		checkReadonlyPrincipals(ctx, run, fix),
+		checkRestrictedShell(ctx, run, fix),
+	)
+	return results
+}
+
+// checkTrustedCAKeysConfig reads the TrustedUserCAKeys file sshd uses. It
+// asks sshd for its effective config, which needs root, and falls back to
+// reading sshd_config. The returned path is the expected default when sshd
+// has none set, so the CA check can still say whether the key is there.
+func checkTrustedCAKeysConfig(ctx context.Context, run hostexec.RunFunc, fix string) (string, CheckResult) {
+	cmd := `{ sudo -n sshd -T 2>/dev/null || sshd -T 2>/dev/null || cat /etc/ssh/sshd_config /etc/ssh/sshd_config.d/*.conf 2>/dev/null; } | awk 'tolower($1) == "trustedusercakeys" { print $2; exit }'`
+	stdout, _, _, _ := run(ctx, cmd)
+	path := strings.TrimSpace(stdout)
+	if path == "" || path == "none" {
+		return defaultCAKeyFile, CheckResult{
+			Name:        "sshd-trusted-ca",
+			Category:    "source-vm",
+			Message:     "sshd has no TrustedUserCAKeys set, so it accepts no deer certificates",
+			Remediation: fix,
+		}
+	}
+	return path, CheckResult{
+		Name:     "sshd-trusted-ca",
+		Category: "source-vm",
+		Passed:   true,
+		Message:  "sshd trusts CA keys in " + path,
+	}
+}
+
+// checkCAKeyTrusted reports whether caFile holds the daemon's CA key.
+func checkCAKeyTrusted(ctx context.Context, run hostexec.RunFunc, caFile, caPubKey, fix string) CheckResult {
+	stdout, _, code, _ := run(ctx, "cat "+shellQuote(caFile))
+	if code != 0 {
+		return CheckResult{
+			Name:        "ca-key-trusted",
+			Category:    "source-vm",
+			Message:     caFile + " is missing or unreadable",
+			Remediation: fix,
+		}
+	}
+	want := keyBody(caPubKey)
+	if want == "" {
+		return CheckResult{
+			Name:        "ca-key-trusted",
+			Category:    "source-vm",
+			Message:     caFile + " exists, but the daemon's CA key is unknown so it could not be compared",
+			Remediation: "deer connect  # then rerun deer doctor",
+		}
+	}
+	for _, line := range strings.Split(stdout, "\n") {
+		if keyBody(line) == want {
+			return CheckResult{
+				Name:     "ca-key-trusted",
+				Category: "source-vm",
+				Passed:   true,
+				Message:  caFile + " holds the daemon's current SSH CA key",
+			}
+		}
+	}
+	return CheckResult{
+		Name:        "ca-key-trusted",
+		Category:    "source-vm",
+		Message:     caFile + " does not hold the daemon's current SSH CA key (the CA may have been rotated since the VM was prepared)",
+		Remediation: fix,
+	}
+}
+
+func checkReadonlyUser(ctx context.Context, run hostexec.RunFunc, fix string) CheckResult {
+	if _, _, code, _ := run(ctx, "id "+readonlyUser); code == 0 {
+		return CheckResult{
+			Name:     "readonly-user",
+			Category: "source-vm",
+			Passed:   true,
+			Message:  readonlyUser + " user exists",
+		}
+	}
+	return CheckResult{
+		Name:        "readonly-user",
+		Category:    "source-vm",
+		Message:     readonlyUser + " user does not exist",
+		Remediation: fix,
+	}
+}
+
+// checkReadonlyPrincipals reports whether certificates for the deer-readonly
+// principal may log in as deer-readonly.
+func checkReadonlyPrincipals(ctx context.Context, run hostexec.RunFunc, fix string) CheckResult {
+	file := fmt.Sprintf(principalsFileTmpl, readonlyUser)
+	stdout, _, code, _ := run(ctx, "cat "+file)
+	if code == 0 {
+		for _, line := range strings.Fields(stdout) {
+			if line == readonlyUser {
+				return CheckResult{
+					Name:     "readonly-principals",
+					Category: "source-vm",
+					Passed:   true,
+					Message:  file + " allows the " + readonlyUser + " principal",
+				}
+			}
+		}
+	}
+	return CheckResult{
+		Name:        "readonly-principals",
+		Category:    "source-vm",
+		Message:     file + " does not allow the " + readonlyUser + " principal",
+		Remediation: fix,
+	}
+}
+
+// checkRestrictedShell reports whether deer-readonly logs in to the
+// restricted shell, and that the shell is installed.
+func checkRestrictedShell(ctx context.Context, run hostexec.RunFunc, fix string) CheckResult {
+	stdout, _, _, _ := run(ctx, "getent passwd "+readonlyUser+" | cut -d: -f7")
+	shell := strings.TrimSpace(stdout)
+	if shell != restrictedShell {
+		return CheckResult{
+			Name:        "restricted-shell",
+			Category:    "source-vm",
+			Message:     fmt.Sprintf("%s login shell is %s, not %s", readonlyUser, orUnknown(shell), restrictedShell),
+			Remediation: fix,
+		}
+	}
+	if _, _, code, _ := run(ctx, "test -x "+restrictedShell); code != 0 {
+		return CheckResult{
+			Name:        "restricted-shell",
+			Category:    "source-vm",
+			Message:     restrictedShell + " is missing or not executable",
+			Remediation: fix,
+		}
+	}
+	return CheckResult{
+		Name:     "restricted-shell",
+		Category: "source-vm",
+		Passed:   true,
+		Message:  readonlyUser + " uses the restricted shell " + restrictedShell,
+	}
+}
+
+// keyBody returns the type and base64 fields of an authorized_keys style
+// line, dropping any comment, so keys compare equal whatever they are named.
+func keyBody(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+		return ""
+	}
+	return fields[0] + " " + fields[1]
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// shellQuote single-quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,121 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCAKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIdeerca deer-ssh-ca"
+
+// preparedVM answers like a source VM that 'deer source prepare' set up,
+// with overrides replacing the answer to any command containing the key.
+func preparedVM(overrides map[string]func() (string, int)) func(context.Context, string) (string, string, int, error) {
+	return func(_ context.Context, command string) (string, string, int, error) {
+		for k, f := range overrides {
+			if strings.Contains(command, k) {
+				out, code := f()
+				return out, "", code, nil
+			}
+		}
+		switch {
+		case strings.Contains(command, "sshd -T"):
+			return "/etc/ssh/deer_ca.pub\n", "", 0, nil
+		case strings.HasPrefix(command, "cat '/etc/ssh/deer_ca.pub'"):
+			// Same key, different comment.
+			return "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIdeerca host-ca\n", "", 0, nil
+		case strings.Contains(command, "authorized_principals"):
+			return "deer-readonly\n", "", 0, nil
+		case strings.Contains(command, "getent passwd"):
+			return "/usr/local/bin/deer-readonly-shell\n", "", 0, nil
+		}
+		return "", "", 0, nil
+	}
+}
+
+func resultsByName(results []CheckResult) map[string]CheckResult {
+	m := make(map[string]CheckResult, len(results))
+	for _, r := range results {
+		m[r.Name] = r
+	}
+	return m
+}
+
+func TestRunSourceVMAllPass(t *testing.T) {
+	results := RunSourceVM(context.Background(), preparedVM(nil), "web-1", testCAKey)
+	require.Len(t, results, 6)
+	for _, r := range results {
+		assert.True(t, r.Passed, "check %s should pass: %s", r.Name, r.Message)
+		assert.Equal(t, "source-vm", r.Category)
+	}
+}
+
+func TestRunSourceVMStaleCA(t *testing.T) {
+	run := preparedVM(map[string]func() (string, int){
+		"cat '/etc/ssh/deer_ca.pub'": func() (string, int) { return "ssh-ed25519 AAAAold old-ca\n", 0 },
+	})
+	r := resultsByName(RunSourceVM(context.Background(), run, "web-1", testCAKey))["ca-key-trusted"]
+	assert.False(t, r.Passed)
+	assert.Contains(t, r.Message, "rotated")
+	assert.Equal(t, "deer source prepare web-1", r.Remediation)
+}
+
+func TestRunSourceVMFollowsConfiguredCAFile(t *testing.T) {
+	var read string
+	run := preparedVM(map[string]func() (string, int){
+		"sshd -T": func() (string, int) { return "/etc/ssh/ca_keys\n", 0 },
+		"cat '/etc/ssh/ca_keys'": func() (string, int) {
+			read = "/etc/ssh/ca_keys"
+			return "ssh-rsa AAAAother\n" + testCAKey + "\n", 0
+		},
+	})
+	r := resultsByName(RunSourceVM(context.Background(), run, "web-1", testCAKey))
+	assert.Equal(t, "/etc/ssh/ca_keys", read)
+	assert.True(t, r["ca-key-trusted"].Passed, r["ca-key-trusted"].Message)
+}
+
+func TestRunSourceVMMissingSetup(t *testing.T) {
+	run := preparedVM(map[string]func() (string, int){
+		"sshd -T":               func() (string, int) { return "", 0 },
+		"cat '/etc/ssh/deer_ca": func() (string, int) { return "", 1 },
+		"id deer-readonly":      func() (string, int) { return "", 1 },
+		"authorized_principals": func() (string, int) { return "", 1 },
+		"getent passwd":         func() (string, int) { return "/bin/bash\n", 0 },
+	})
+	byName := resultsByName(RunSourceVM(context.Background(), run, "web-1", testCAKey))
+	assert.True(t, byName["source-ssh"].Passed)
+	for _, name := range []string{"sshd-trusted-ca", "ca-key-trusted", "readonly-user", "readonly-principals", "restricted-shell"} {
+		assert.False(t, byName[name].Passed, "check %s should fail", name)
+		assert.NotEmpty(t, byName[name].Remediation, "check %s should have a fix", name)
+	}
+	assert.Contains(t, byName["restricted-shell"].Message, "/bin/bash")
+}
+
+func TestRunSourceVMUnknownCA(t *testing.T) {
+	r := resultsByName(RunSourceVM(context.Background(), preparedVM(nil), "web-1", ""))["ca-key-trusted"]
+	assert.False(t, r.Passed)
+	assert.Contains(t, r.Remediation, "deer connect")
+}
+
+func TestRunSourceVMSSHFailureStops(t *testing.T) {
+	calls := 0
+	run := func(context.Context, string) (string, string, int, error) {
+		calls++
+		return "", "Permission denied (publickey)", 255, nil
+	}
+	results := RunSourceVM(context.Background(), run, "web-1", testCAKey)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Passed)
+	assert.Contains(t, results[0].Message, "Permission denied")
+	assert.Equal(t, 1, calls)
+
+	errRun := func(context.Context, string) (string, string, int, error) {
+		return "", "", 1, errors.New("exec: ssh not found")
+	}
+	results = RunSourceVM(context.Background(), errRun, "web-1", testCAKey)
+	assert.Contains(t, results[0].Message, "ssh not found")
+}
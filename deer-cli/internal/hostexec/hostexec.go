@@ -213,7 +213,9 @@ type OutputCallback func(chunk string, isStderr bool)
 
 // RunStreamingSSHAlias runs a command via SSH using the host alias, streaming
 // stdout/stderr line-by-line through the callback as they arrive.
-// Returns the full buffered stdout, stderr, exit code, and error.
+// Returns the exit code and error. Output is only buffered and returned when
+// onOutput is nil; a streaming caller keeps what it needs, so a long-running
+// command such as a log follow does not grow without bound.
 func RunStreamingSSHAlias(ctx context.Context, hostAlias string, extraArgs []string, command string, onOutput OutputCallback) (stdout, stderr string, exitCode int, err error) {
 	args := []string{
 		"-o", "StrictHostKeyChecking=accept-new",
@@ -223,8 +225,18 @@ func RunStreamingSSHAlias(ctx context.Context, hostAlias string, extraArgs []str
 	args = append(args, extraArgs...)
 	args = append(args, hostAlias, "--", command)
 
-	cmd := exec.CommandContext(ctx, "ssh", args...)
+	return runStreaming(exec.CommandContext(ctx, "ssh", args...), onOutput)
+}
+
+// RunStreamingLocal runs a command locally via bash, streaming stdout/stderr
+// line-by-line through the callback like RunStreamingSSHAlias.
+func RunStreamingLocal(ctx context.Context, command string, onOutput OutputCallback) (stdout, stderr string, exitCode int, err error) {
+	return runStreaming(exec.CommandContext(ctx, "bash", "-c", command), onOutput)
+}
 
+// runStreaming starts cmd and feeds its output to onOutput as lines arrive,
+// or buffers it for the return values when onOutput is nil.
+func runStreaming(cmd *exec.Cmd, onOutput OutputCallback) (stdout, stderr string, exitCode int, err error) {
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return "", "", 1, fmt.Errorf("stdout pipe: %w", err)
@@ -248,9 +260,10 @@ func RunStreamingSSHAlias(ctx context.Context, hostAlias string, extraArgs []str
 		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 		for scanner.Scan() {
 			line := scanner.Text() + "\n"
-			stdoutBuf.WriteString(line)
 			if onOutput != nil {
 				onOutput(line, false)
+			} else {
+				stdoutBuf.WriteString(line)
 			}
 		}
 		if err := scanner.Err(); err != nil {
@@ -266,9 +279,10 @@ func RunStreamingSSHAlias(ctx context.Context, hostAlias string, extraArgs []str
 		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
 		for scanner.Scan() {
 			line := scanner.Text() + "\n"
-			stderrBuf.WriteString(line)
 			if onOutput != nil {
 				onOutput(line, true)
+			} else {
+				stderrBuf.WriteString(line)
 			}
 		}
 		if err := scanner.Err(); err != nil {
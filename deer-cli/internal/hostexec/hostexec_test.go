@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,6 +50,25 @@ func TestNewLocalExitCode(t *testing.T) {
 	assert.Equal(t, 42, code)
 }
 
+func TestRunStreamingLocal_BuffersOnlyWithoutCallback(t *testing.T) {
+	var mu sync.Mutex
+	var streamed strings.Builder
+	stdout, stderr, code, err := RunStreamingLocal(context.Background(), "echo out; echo err >&2", func(chunk string, _ bool) {
+		mu.Lock()
+		streamed.WriteString(chunk)
+		mu.Unlock()
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout+stderr, "streamed output should not also be buffered")
+	assert.Contains(t, streamed.String(), "out\n")
+
+	stdout, stderr, _, err = RunStreamingLocal(context.Background(), "echo out; echo err >&2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", stdout)
+	assert.Equal(t, "err\n", stderr)
+}
+
 func TestNewSSHCommandConstruction(t *testing.T) {
 	// We can't test actual SSH, but we can verify the function is constructed
 	run := NewSSH("192.168.1.100", "root", 22)
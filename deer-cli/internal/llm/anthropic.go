@@ -0,0 +1,289 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+const (
+	// anthropicVersion is the Messages API version the client speaks.
+	anthropicVersion = "2023-06-01"
+	// anthropicMaxTokens caps each response; the API requires a value.
+	anthropicMaxTokens = 8192
+)
+
+type anthropicClient struct {
+	config config.AIAgentConfig
+	client *http.Client
+
+	// models caches the /models response for the life of the process.
+	modelsMu sync.Mutex
+	models   []Model
+}
+
+// NewAnthropicClient creates a client for the Anthropic Messages API. Tool
+// calls and results are translated to and from the OpenAI-style messages
+// the agent works with.
+func NewAnthropicClient(cfg config.AIAgentConfig) Client {
+	return &anthropicClient{
+		config: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Minute,
+		},
+	}
+}
+
+// anthropicBlock is one content block of a Messages API message.
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"`
+	Content []anthropicBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema ParameterSchema `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicTool    `json:"tools,omitempty"`
+}
+
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (c *anthropicClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if req.Model == "" {
+		req.Model = c.config.Model
+	}
+
+	body, err := json.Marshal(toAnthropicRequest(req))
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.config.Endpoint+"/messages", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	c.setHeaders(httpReq)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error.Message != "" {
+			return nil, fmt.Errorf("anthropic error (%d): %s", resp.StatusCode, errResp.Error.Message)
+		}
+		return nil, fmt.Errorf("anthropic error: status code %d", resp.StatusCode)
+	}
+
+	var ar anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ar); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return fromAnthropicResponse(ar), nil
+}
+
+// ChatStream is not implemented for Anthropic; callers fall back to Chat.
+func (c *anthropicClient) ChatStream(context.Context, ChatRequest) (<-chan StreamChunk, error) {
+	return nil, ErrStreamingUnsupported
+}
+
+func (c *anthropicClient) setHeaders(r *http.Request) {
+	r.Header.Set("x-api-key", c.config.APIKey)
+	r.Header.Set("anthropic-version", anthropicVersion)
+}
+
+// toAnthropicRequest converts an OpenAI-style request. System messages
+// become the system prompt, tool results become tool_result blocks in a
+// user turn, and consecutive turns of the same role are merged because the
+// API requires user and assistant turns to alternate.
+func toAnthropicRequest(req ChatRequest) anthropicRequest {
+	out := anthropicRequest{
+		Model:     strings.TrimPrefix(req.Model, "anthropic/"),
+		MaxTokens: anthropicMaxTokens,
+	}
+
+	var system []string
+	for _, msg := range req.Messages {
+		var role string
+		var blocks []anthropicBlock
+		switch msg.Role {
+		case RoleSystem:
+			system = append(system, msg.Content)
+			continue
+		case RoleTool:
+			role = "user"
+			blocks = []anthropicBlock{{Type: "tool_result", ToolUseID: msg.ToolCallID, Content: msg.Content}}
+		case RoleAssistant:
+			role = "assistant"
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := json.RawMessage(tc.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: tc.ID, Name: tc.Function.Name, Input: input})
+			}
+		default:
+			role = "user"
+			blocks = []anthropicBlock{{Type: "text", Text: msg.Content}}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(out.Messages); n > 0 && out.Messages[n-1].Role == role {
+			out.Messages[n-1].Content = append(out.Messages[n-1].Content, blocks...)
+			continue
+		}
+		out.Messages = append(out.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+	out.System = strings.Join(system, "\n\n")
+
+	for _, t := range req.Tools {
+		schema := t.Function.Parameters
+		if schema.Type == "" {
+			schema.Type = "object"
+		}
+		if schema.Properties == nil {
+			schema.Properties = map[string]Property{}
+		}
+		out.Tools = append(out.Tools, anthropicTool{
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	return out
+}
+
+// fromAnthropicResponse converts a Messages API response to the single
+// choice the agent expects, with tool_use blocks as ToolCalls.
+func fromAnthropicResponse(ar anthropicResponse) *ChatResponse {
+	msg := Message{Role: RoleAssistant}
+	var text []string
+	for _, b := range ar.Content {
+		switch b.Type {
+		case "text":
+			text = append(text, b.Text)
+		case "tool_use":
+			args := string(b.Input)
+			if args == "" {
+				args = "{}"
+			}
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{
+				ID:       b.ID,
+				Type:     "function",
+				Function: FunctionCall{Name: b.Name, Arguments: args},
+			})
+		}
+	}
+	msg.Content = strings.Join(text, "")
+
+	finish := ar.StopReason
+	switch ar.StopReason {
+	case "end_turn", "stop_sequence":
+		finish = "stop"
+	case "tool_use":
+		finish = "tool_calls"
+	case "max_tokens":
+		finish = "length"
+	}
+
+	return &ChatResponse{
+		ID:      ar.ID,
+		Choices: []Choice{{Message: msg, FinishReason: finish}},
+		Usage: &Usage{
+			PromptTokens:     ar.Usage.InputTokens,
+			CompletionTokens: ar.Usage.OutputTokens,
+			TotalTokens:      ar.Usage.InputTokens + ar.Usage.OutputTokens,
+		},
+	}
+}
+
+// ListModels returns Anthropic's model list, sorted by ID. The API reports
+// neither context length nor prices, so those are left at zero. The first
+// successful fetch is cached; failures are not, so a later call retries.
+func (c *anthropicClient) ListModels(ctx context.Context) ([]Model, error) {
+	c.modelsMu.Lock()
+	defer c.modelsMu.Unlock()
+	if c.models != nil {
+		return c.models, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.config.Endpoint+"/models?limit=1000", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	c.setHeaders(httpReq)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("anthropic error: status code %d", resp.StatusCode)
+	}
+
+	var listResp struct {
+		Data []struct {
+			ID          string `json:"id"`
+			DisplayName string `json:"display_name"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	models := make([]Model, 0, len(listResp.Data))
+	for _, m := range listResp.Data {
+		models = append(models, Model{ID: m.ID, Name: m.DisplayName})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	c.models = models
+	return models, nil
+}
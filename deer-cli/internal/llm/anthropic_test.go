@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+func TestAnthropicChat_ToolCalls(t *testing.T) {
+	var got anthropicRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("path = %s, want /messages", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "k" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("headers = %v", r.Header)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_1","stop_reason":"tool_use",
+			"content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"toolu_2","name":"run_command","input":{"command":"uptime"}}],
+			"usage":{"input_tokens":900,"output_tokens":40}}`))
+	}))
+	defer srv.Close()
+
+	c := NewAnthropicClient(config.AIAgentConfig{Endpoint: srv.URL, APIKey: "k", Model: "anthropic/claude-sonnet-4-5"})
+	resp, err := c.Chat(context.Background(), ChatRequest{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "You are Deer."},
+			{Role: RoleUser, Content: "Is the box up?"},
+			{Role: RoleAssistant, ToolCalls: []ToolCall{
+				{ID: "toolu_1", Type: "function", Function: FunctionCall{Name: "list_sandboxes", Arguments: "{}"}},
+				{ID: "toolu_0", Type: "function", Function: FunctionCall{Name: "list_hosts", Arguments: ""}},
+			}},
+			{Role: RoleTool, ToolCallID: "toolu_1", Content: `{"sandboxes":[]}`},
+			{Role: RoleTool, ToolCallID: "toolu_0", Content: `{"hosts":[]}`},
+		},
+		Tools: []Tool{{Type: "function", Function: Function{Name: "run_command", Description: "Run a command",
+			Parameters: ParameterSchema{Type: "object", Properties: map[string]Property{"command": {Type: "string"}}, Required: []string{"command"}}}}},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if got.Model != "claude-sonnet-4-5" || got.System != "You are Deer." || got.MaxTokens == 0 {
+		t.Errorf("request = model %q, system %q, max_tokens %d", got.Model, got.System, got.MaxTokens)
+	}
+	if len(got.Messages) != 3 {
+		t.Fatalf("messages = %+v, want user, assistant, user", got.Messages)
+	}
+	if calls := got.Messages[1].Content; len(calls) != 2 || calls[0].Type != "tool_use" || string(calls[1].Input) != "{}" {
+		t.Errorf("assistant blocks = %+v", calls)
+	}
+	if results := got.Messages[2].Content; len(results) != 2 || results[1].ToolUseID != "toolu_0" || results[1].Type != "tool_result" {
+		t.Errorf("tool results = %+v, want both merged into one user turn", results)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].InputSchema.Required[0] != "command" {
+		t.Errorf("tools = %+v", got.Tools)
+	}
+
+	msg := resp.Choices[0].Message
+	if msg.Content != "Checking." || resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("message = %+v, finish = %q", msg, resp.Choices[0].FinishReason)
+	}
+	if len(msg.ToolCalls) != 1 || msg.ToolCalls[0].ID != "toolu_2" || msg.ToolCalls[0].Function.Arguments != `{"command":"uptime"}` {
+		t.Errorf("tool calls = %+v", msg.ToolCalls)
+	}
+	if resp.Usage.PromptTokens != 900 || resp.Usage.TotalTokens != 940 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestAnthropicChat_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	}))
+	defer srv.Close()
+
+	c := NewAnthropicClient(config.AIAgentConfig{Endpoint: srv.URL})
+	_, err := c.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}})
+	if err == nil || err.Error() != "anthropic error (401): invalid x-api-key" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestAnthropicChatStream_Unsupported(t *testing.T) {
+	c := NewAnthropicClient(config.AIAgentConfig{})
+	if _, err := c.ChatStream(context.Background(), ChatRequest{}); !errors.Is(err, ErrStreamingUnsupported) {
+		t.Errorf("err = %v, want ErrStreamingUnsupported", err)
+	}
+}
+
+func TestOpenAIChat_StripsVendorPrefix(t *testing.T) {
+	var model string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Title") != "" {
+			t.Error("OpenRouter attribution header sent to OpenAI")
+		}
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		model = req.Model
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"hi"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewOpenAIClient(config.AIAgentConfig{Endpoint: srv.URL, Model: "openai/gpt-4.1", SiteName: "deer"})
+	if _, err := c.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hello"}}}); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if model != "gpt-4.1" {
+		t.Errorf("model = %q, want gpt-4.1", model)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	for _, p := range []string{"openrouter", "anthropic", "openai"} {
+		if c, err := NewClient(config.AIAgentConfig{Provider: p}); c == nil || err != nil {
+			t.Errorf("NewClient(%q) = %v, %v", p, c, err)
+		}
+	}
+	if _, err := NewClient(config.AIAgentConfig{Provider: "bedrock"}); err == nil {
+		t.Error("NewClient accepted an unknown provider")
+	}
+}
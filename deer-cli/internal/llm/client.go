@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"errors"
 )
 
 // Role represents the role of a message author.
@@ -68,12 +69,31 @@ type ChatRequest struct {
 	Model    string    `json:"model"`
 	Messages []Message `json:"messages"`
 	Tools    []Tool    `json:"tools,omitempty"`
+	// Stream and StreamOptions are set by ChatStream; callers leave them
+	// unset.
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// StreamOptions asks a streaming provider for extra chunks.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // ChatResponse represents a response from a chat completion.
 type ChatResponse struct {
 	ID      string   `json:"id"`
 	Choices []Choice `json:"choices"`
+	// Usage is the provider's token accounting for the request. It is nil
+	// when the provider does not report usage.
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// Usage reports the tokens billed for a chat completion.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // Choice represents a single choice in a ChatResponse.
@@ -82,7 +102,102 @@ type Choice struct {
 	FinishReason string  `json:"finish_reason"`
 }
 
+// ErrStreamingUnsupported is returned by ChatStream when the provider
+// cannot stream; callers fall back to Chat.
+var ErrStreamingUnsupported = errors.New("streaming not supported")
+
 // Client is the interface for LLM providers.
 type Client interface {
 	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	// ChatStream is Chat with the reply delivered in pieces as the provider
+	// produces it. The channel is closed after the last chunk; a chunk with
+	// Err set ends the stream early. Stopping early requires cancelling ctx.
+	ChatStream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error)
+	// ListModels returns the models the provider offers.
+	ListModels(ctx context.Context) ([]Model, error)
+}
+
+// StreamChunk is one piece of a streamed reply: a fragment of text, tool
+// call fragments, or the closing finish reason and usage.
+type StreamChunk struct {
+	Content      string
+	ToolCalls    []ToolCallDelta
+	FinishReason string
+	Usage        *Usage
+	Err          error
+}
+
+// ToolCallDelta is a fragment of the tool call at Index. The ID, type and
+// name arrive once; Arguments arrive in pieces to be concatenated.
+type ToolCallDelta struct {
+	Index    int          `json:"index"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// StreamAccumulator rebuilds a ChatResponse from stream chunks, holding
+// tool calls back until their fragments are all in.
+type StreamAccumulator struct {
+	content   []byte
+	toolCalls []ToolCall
+	finish    string
+	usage     *Usage
+}
+
+// Add folds chunk into the response.
+func (a *StreamAccumulator) Add(chunk StreamChunk) {
+	a.content = append(a.content, chunk.Content...)
+	for _, d := range chunk.ToolCalls {
+		for len(a.toolCalls) <= d.Index {
+			a.toolCalls = append(a.toolCalls, ToolCall{Type: "function"})
+		}
+		tc := &a.toolCalls[d.Index]
+		if d.ID != "" {
+			tc.ID = d.ID
+		}
+		if d.Type != "" {
+			tc.Type = d.Type
+		}
+		if d.Function.Name != "" {
+			tc.Function.Name = d.Function.Name
+		}
+		tc.Function.Arguments += d.Function.Arguments
+	}
+	if chunk.FinishReason != "" {
+		a.finish = chunk.FinishReason
+	}
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+}
+
+// Content returns the text received so far.
+func (a *StreamAccumulator) Content() string {
+	return string(a.content)
+}
+
+// Response returns the reply as Chat would have.
+func (a *StreamAccumulator) Response() *ChatResponse {
+	msg := Message{Role: RoleAssistant, Content: string(a.content)}
+	for _, tc := range a.toolCalls {
+		if tc.Function.Arguments == "" {
+			tc.Function.Arguments = "{}"
+		}
+		msg.ToolCalls = append(msg.ToolCalls, tc)
+	}
+	return &ChatResponse{
+		Choices: []Choice{{Message: msg, FinishReason: a.finish}},
+		Usage:   a.usage,
+	}
+}
+
+// Model describes a model offered by an LLM provider. Prices are in USD per
+// token as reported by the provider; zero means free or unknown.
+type Model struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	ContextLength   int     `json:"context_length"`
+	PromptPrice     float64 `json:"prompt_price"`
+	CompletionPrice float64 `json:"completion_price"`
 }
@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+// NewOpenAIClient creates a client for the OpenAI chat completions API.
+// OpenRouter uses the same wire format, so requests and tool calls need no
+// translation.
+func NewOpenAIClient(cfg config.AIAgentConfig) Client {
+	return &chatCompletionsClient{
+		name:   "openai",
+		config: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Minute,
+		},
+	}
+}
+
+// NewClient returns the client for cfg.Provider: openrouter, anthropic or
+// openai.
+func NewClient(cfg config.AIAgentConfig) (Client, error) {
+	switch cfg.Provider {
+	case "openrouter":
+		return NewOpenRouterClient(cfg), nil
+	case "anthropic":
+		return NewAnthropicClient(cfg), nil
+	case "openai":
+		return NewOpenAIClient(cfg), nil
+	}
+	return nil, fmt.Errorf("unknown ai_agent.provider %q: use openrouter, anthropic or openai", cfg.Provider)
+}
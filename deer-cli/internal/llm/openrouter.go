@@ -1,24 +1,38 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
 )
 
-type openRouterClient struct {
+// chatCompletionsClient speaks the OpenAI chat completions API, which
+// OpenRouter also serves. name labels errors; OpenRouter's attribution
+// headers are sent only when name is "openrouter".
+type chatCompletionsClient struct {
+	name   string
 	config config.AIAgentConfig
 	client *http.Client
+
+	// models caches the /models response for the life of the process.
+	modelsMu sync.Mutex
+	models   []Model
 }
 
 // NewOpenRouterClient creates a new OpenRouter client.
 func NewOpenRouterClient(cfg config.AIAgentConfig) Client {
-	return &openRouterClient{
+	return &chatCompletionsClient{
+		name:   "openrouter",
 		config: cfg,
 		client: &http.Client{
 			Timeout: 10 * time.Minute,
@@ -26,10 +40,112 @@ func NewOpenRouterClient(cfg config.AIAgentConfig) Client {
 	}
 }
 
-func (c *openRouterClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+func (c *chatCompletionsClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var chatResp ChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &chatResp, nil
+}
+
+// streamEvent is one server-sent event of a streamed chat completion.
+// OpenRouter reports failures after the stream has started as an event
+// with Error set.
+type streamEvent struct {
+	Choices []struct {
+		Delta struct {
+			Content   string          `json:"content"`
+			ToolCalls []ToolCallDelta `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ChatStream sends req with streaming on and relays the server-sent events
+// as chunks. Usage is requested so the last chunk carries it.
+func (c *chatCompletionsClient) ChatStream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
+	req.Stream = true
+	req.StreamOptions = &StreamOptions{IncludeUsage: true}
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make(chan StreamChunk)
+	go func() {
+		defer close(chunks)
+		defer func() { _ = resp.Body.Close() }()
+
+		send := func(chunk StreamChunk) bool {
+			select {
+			case chunks <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			// Lines other than data are blank separators or comments, which
+			// OpenRouter sends as keep-alives.
+			data, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			data = strings.TrimSpace(data)
+			if data == "[DONE]" {
+				return
+			}
+			var ev streamEvent
+			if err := json.Unmarshal([]byte(data), &ev); err != nil {
+				send(StreamChunk{Err: fmt.Errorf("decode stream event: %w", err)})
+				return
+			}
+			if ev.Error != nil {
+				send(StreamChunk{Err: fmt.Errorf("%s error: %s", c.name, ev.Error.Message)})
+				return
+			}
+			chunk := StreamChunk{Usage: ev.Usage}
+			if len(ev.Choices) > 0 {
+				chunk.Content = ev.Choices[0].Delta.Content
+				chunk.ToolCalls = ev.Choices[0].Delta.ToolCalls
+				chunk.FinishReason = ev.Choices[0].FinishReason
+			}
+			if !send(chunk) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			send(StreamChunk{Err: fmt.Errorf("read stream: %w", err)})
+		}
+	}()
+	return chunks, nil
+}
+
+// post sends req to the chat completions endpoint and returns the response
+// if it succeeded. The caller closes the body.
+func (c *chatCompletionsClient) post(ctx context.Context, req ChatRequest) (*http.Response, error) {
 	if req.Model == "" {
 		req.Model = c.config.Model
 	}
+	if c.name != "openrouter" {
+		// OpenRouter model IDs carry a vendor prefix the vendor's own API
+		// does not use.
+		req.Model = strings.TrimPrefix(req.Model, c.name+"/")
+	}
 
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -44,20 +160,22 @@ func (c *openRouterClient) Chat(ctx context.Context, req ChatRequest) (*ChatResp
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
 
-	if c.config.SiteURL != "" {
-		httpReq.Header.Set("HTTP-Referer", c.config.SiteURL)
-	}
-	if c.config.SiteName != "" {
-		httpReq.Header.Set("X-Title", c.config.SiteName)
+	if c.name == "openrouter" {
+		if c.config.SiteURL != "" {
+			httpReq.Header.Set("HTTP-Referer", c.config.SiteURL)
+		}
+		if c.config.SiteName != "" {
+			httpReq.Header.Set("X-Title", c.config.SiteName)
+		}
 	}
 
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
 		var errResp struct {
 			Error struct {
 				Message string `json:"message"`
@@ -65,15 +183,67 @@ func (c *openRouterClient) Chat(ctx context.Context, req ChatRequest) (*ChatResp
 			} `json:"error"`
 		}
 		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error.Message != "" {
-			return nil, fmt.Errorf("openrouter error (%d): %s", resp.StatusCode, errResp.Error.Message)
+			return nil, fmt.Errorf("%s error (%d): %s", c.name, resp.StatusCode, errResp.Error.Message)
 		}
-		return nil, fmt.Errorf("openrouter error: status code %d", resp.StatusCode)
+		return nil, fmt.Errorf("%s error: status code %d", c.name, resp.StatusCode)
 	}
+	return resp, nil
+}
 
-	var chatResp ChatResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+// ListModels returns the provider's model list, sorted by ID. OpenAI reports
+// only IDs; OpenRouter adds names, context lengths and prices. The first
+// successful fetch is cached; failures are not, so a later call retries.
+func (c *chatCompletionsClient) ListModels(ctx context.Context) ([]Model, error) {
+	c.modelsMu.Lock()
+	defer c.modelsMu.Unlock()
+	if c.models != nil {
+		return c.models, nil
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.config.Endpoint+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s error: status code %d", c.name, resp.StatusCode)
+	}
+
+	var listResp struct {
+		Data []struct {
+			ID            string `json:"id"`
+			Name          string `json:"name"`
+			ContextLength int    `json:"context_length"`
+			Pricing       struct {
+				Prompt     string `json:"prompt"`
+				Completion string `json:"completion"`
+			} `json:"pricing"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
-	return &chatResp, nil
+	models := make([]Model, 0, len(listResp.Data))
+	for _, m := range listResp.Data {
+		// Prices come as decimal strings; unparseable ones are left at zero.
+		prompt, _ := strconv.ParseFloat(m.Pricing.Prompt, 64)
+		completion, _ := strconv.ParseFloat(m.Pricing.Completion, 64)
+		models = append(models, Model{
+			ID:              m.ID,
+			Name:            m.Name,
+			ContextLength:   m.ContextLength,
+			PromptPrice:     prompt,
+			CompletionPrice: completion,
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	c.models = models
+	return models, nil
 }
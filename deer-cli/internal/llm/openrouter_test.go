@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+func TestOpenRouterListModels_Caches(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/models" {
+			t.Errorf("path = %s, want /models", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"z/big","name":"Big","context_length":200000,"pricing":{"prompt":"0.000003","completion":"0.000015"}},
+			{"id":"a/free","name":"Free","context_length":8192,"pricing":{"prompt":"0","completion":"0"}}
+		]}`))
+	}))
+	defer srv.Close()
+
+	c := NewOpenRouterClient(config.AIAgentConfig{Endpoint: srv.URL, APIKey: "k"})
+	models, err := c.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "a/free" {
+		t.Fatalf("models = %+v, want sorted by ID", models)
+	}
+	if models[1].ContextLength != 200000 || models[1].PromptPrice != 0.000003 {
+		t.Errorf("model = %+v", models[1])
+	}
+
+	if _, err := c.ListModels(context.Background()); err != nil {
+		t.Fatalf("second ListModels: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want the list cached after the first call", requests)
+	}
+}
+
+func TestOpenRouterListModels_ErrorNotCached(t *testing.T) {
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"m"}]}`))
+	}))
+	defer srv.Close()
+
+	c := NewOpenRouterClient(config.AIAgentConfig{Endpoint: srv.URL})
+	if _, err := c.ListModels(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	fail = false
+	models, err := c.ListModels(context.Background())
+	if err != nil || len(models) != 1 {
+		t.Errorf("retry = %v, %v", models, err)
+	}
+}
+
+func TestOpenRouterChat_DecodesUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"gen-1","choices":[{"message":{"role":"assistant","content":"hi"}}],
+			"usage":{"prompt_tokens":1200,"completion_tokens":34,"total_tokens":1234}}`))
+	}))
+	defer srv.Close()
+
+	c := NewOpenRouterClient(config.AIAgentConfig{Endpoint: srv.URL, Model: "m"})
+	resp, err := c.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hello"}}})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 1200 || resp.Usage.CompletionTokens != 34 {
+		t.Errorf("usage = %+v, want prompt 1200, completion 34", resp.Usage)
+	}
+}
+
+func TestOpenRouterChatStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("request stream = %v, options = %+v", req.Stream, req.StreamOptions)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, `: OPENROUTER PROCESSING
+
+data: {"choices":[{"delta":{"content":"Let me "}}]}
+
+data: {"choices":[{"delta":{"content":"check.","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"run_command","arguments":"{\"comm"}}]}}]}
+
+data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"and\":\"uptime\"}"}}]},"finish_reason":"tool_calls"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":50,"completion_tokens":7,"total_tokens":57}}
+
+data: [DONE]
+
+`)
+	}))
+	defer srv.Close()
+
+	c := NewOpenRouterClient(config.AIAgentConfig{Endpoint: srv.URL})
+	chunks, err := c.ChatStream(context.Background(), ChatRequest{Messages: []Message{{Role: RoleUser, Content: "up?"}}})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var acc StreamAccumulator
+	var texts []string
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			t.Fatalf("chunk error: %v", chunk.Err)
+		}
+		acc.Add(chunk)
+		if chunk.Content != "" {
+			texts = append(texts, acc.Content())
+		}
+	}
+	if len(texts) != 2 || texts[1] != "Let me check." {
+		t.Errorf("text so far = %q", texts)
+	}
+
+	resp := acc.Response()
+	msg := resp.Choices[0].Message
+	if len(msg.ToolCalls) != 1 || msg.ToolCalls[0].ID != "call_1" || msg.ToolCalls[0].Function.Arguments != `{"command":"uptime"}` {
+		t.Errorf("tool calls = %+v", msg.ToolCalls)
+	}
+	if resp.Choices[0].FinishReason != "tool_calls" || resp.Usage == nil || resp.Usage.TotalTokens != 57 {
+		t.Errorf("finish = %q, usage = %+v", resp.Choices[0].FinishReason, resp.Usage)
+	}
+}
+
+func TestOpenRouterChatStream_MidStreamError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: {\"error\":{\"message\":\"provider overloaded\"}}\n\n")
+	}))
+	defer srv.Close()
+
+	c := NewOpenRouterClient(config.AIAgentConfig{Endpoint: srv.URL})
+	chunks, err := c.ChatStream(context.Background(), ChatRequest{})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	var last StreamChunk
+	for chunk := range chunks {
+		last = chunk
+	}
+	if last.Err == nil || last.Err.Error() != "openrouter error: provider overloaded" {
+		t.Errorf("last chunk error = %v", last.Err)
+	}
+}
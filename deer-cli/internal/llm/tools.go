@@ -5,6 +5,7 @@ var readOnlyTools = map[string]bool{
 	"list_sandboxes":        true,
 	"get_sandbox":           true,
 	"list_vms":              true,
+	"list_templates":        true,
 	"read_file":             true,
 	"list_playbooks":        true,
 	"get_playbook":          true,
@@ -136,11 +137,44 @@ func GetTools() []Tool {
 							Type:        "boolean",
 							Description: "If true, start a local single-node Elasticsearch (localhost:9200) inside the sandbox. Use together with kafka_stub for logstash pipelines to verify data flows correctly through the pipeline. Logstash output should be pointed to localhost:9200.",
 						},
+						"dry_run": {
+							Type:        "boolean",
+							Description: "If true, report the sandbox ID, host, resources, and any capacity warnings the create would produce without creating anything.",
+						},
 					},
 					Required: []string{"source_vm"},
 				},
 			},
 		},
+		{
+			Type: "function",
+			Function: Function{
+				Name:        "create_from_template",
+				Description: "Create a new sandbox from a named template (a saved preset of source VM, CPU, memory, and stub options). Use list_templates first to see which templates exist.",
+				Parameters: ParameterSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"template": {
+							Type:        "string",
+							Description: "The template name. Must be a name returned by list_templates.",
+						},
+						"host": {
+							Type:        "string",
+							Description: "Optional target host name for multi-host setups.",
+						},
+						"cpu": {
+							Type:        "integer",
+							Description: "Optional vCPU count overriding the template.",
+						},
+						"memory_mb": {
+							Type:        "integer",
+							Description: "Optional RAM in MB overriding the template.",
+						},
+					},
+					Required: []string{"template"},
+				},
+			},
+		},
 		{
 			Type: "function",
 			Function: Function{
@@ -174,6 +208,10 @@ func GetTools() []Tool {
 							Type:        "string",
 							Description: "The shell command to execute.",
 						},
+						"cwd": {
+							Type:        "string",
+							Description: "Optional absolute directory to run the command in. Omitted uses the sandbox's working directory, or the login directory if none is set.",
+						},
 					},
 					Required: []string{"sandbox_id", "command"},
 				},
@@ -241,6 +279,17 @@ func GetTools() []Tool {
 				},
 			},
 		},
+		{
+			Type: "function",
+			Function: Function{
+				Name:        "list_templates",
+				Description: "List the team's sandbox templates: named presets of source VM, CPU, memory, and stub options. Prefer create_from_template when one fits the task.",
+				Parameters: ParameterSchema{
+					Type:       "object",
+					Properties: map[string]Property{},
+				},
+			},
+		},
 		{
 			Type: "function",
 			Function: Function{
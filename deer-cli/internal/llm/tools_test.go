@@ -109,6 +109,7 @@ func TestGetReadOnlyTools(t *testing.T) {
 		"list_sandboxes",
 		"list_skills",
 		"list_tasks",
+		"list_templates",
 		"list_vms",
 		"load_skill",
 		"read_file",
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
@@ -13,11 +14,12 @@ import (
 
 	"github.com/aspectrr/deer.sh/deer-cli/internal/ansible"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/source"
 )
 
 // jsonResult marshals v to JSON and returns it as a text tool result.
 func jsonResult(v any) (*mcp.CallToolResult, error) {
-	data, err := json.Marshal(v)
+	data, err := json.Marshal(jsonSafe(v))
 	if err != nil {
 		return nil, fmt.Errorf("marshal result: %w", err)
 	}
@@ -27,7 +29,7 @@ func jsonResult(v any) (*mcp.CallToolResult, error) {
 // errorResult marshals v to JSON and returns it as a tool result with IsError set.
 // This gives AI agents structured error context instead of opaque error strings.
 func errorResult(v any) (*mcp.CallToolResult, error) {
-	data, err := json.Marshal(v)
+	data, err := json.Marshal(jsonSafe(v))
 	if err != nil {
 		return nil, fmt.Errorf("marshal error result: %w", err)
 	}
@@ -36,6 +38,55 @@ func errorResult(v any) (*mcp.CallToolResult, error) {
 	return result, nil
 }
 
+// jsonSafe rewrites values that encoding/json would drop or reject inside the
+// loosely typed maps handlers build: errors become their message (json would
+// encode most as {}), and nested channels, funcs and complex numbers become a
+// type placeholder instead of failing the whole result. []byte and time.Time
+// are left to encoding/json, which writes them as base64 and RFC 3339.
+func jsonSafe(v any) any {
+	switch x := v.(type) {
+	case error:
+		return x.Error()
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, e := range x {
+			out[k] = jsonSafeElem(e)
+		}
+		return out
+	case []map[string]any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = jsonSafeElem(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, e := range x {
+			out[i] = jsonSafeElem(e)
+		}
+		return out
+	}
+	return v
+}
+
+// jsonSafeElem is jsonSafe for a value nested in a map or slice.
+func jsonSafeElem(v any) any {
+	if v == nil {
+		return nil
+	}
+	switch v.(type) {
+	case error, map[string]any, []map[string]any, []any:
+		return jsonSafe(v)
+	case []byte, time.Time, json.Marshaler:
+		return v
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("<%s>", rv.Type())
+	}
+	return v
+}
+
 // ShellEscape safely escapes a string for use in a shell command.
 func ShellEscape(s string) (string, error) {
 	if err := ValidateShellArg(s); err != nil {
@@ -168,8 +219,9 @@ func (s *Server) handleRunCommand(ctx context.Context, request mcp.CallToolReque
 	}
 
 	timeoutSec := request.GetInt("timeout_seconds", 0)
+	cwd := request.GetString("cwd", "")
 
-	result, err := s.service.RunCommand(ctx, sandboxID, command, timeoutSec, nil)
+	result, err := s.service.RunCommandWithOptions(ctx, sandboxID, command, sandbox.RunOptions{TimeoutSec: timeoutSec, Workdir: cwd})
 	if err != nil {
 		s.logger.Error("run_command failed", "error", err, "sandbox_id", sandboxID, "command", command)
 		resp := map[string]any{
@@ -193,6 +245,59 @@ func (s *Server) handleRunCommand(ctx context.Context, request mcp.CallToolReque
 	})
 }
 
+// defaultHistoryLimit is how many commands get_command_history returns
+// when the client does not ask for a number.
+const defaultHistoryLimit = 20
+
+// defaultHistoryOutput caps the stdout and stderr of each command returned
+// by get_command_history unless mcp.history_output_limit is set, so a long
+// history does not fill the client's context window.
+const defaultHistoryOutput = 2 * 1024
+
+func (s *Server) handleGetCommandHistory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.trackToolCall("get_command_history")
+
+	sandboxID := request.GetString("sandbox_id", "")
+	if sandboxID == "" {
+		return nil, fmt.Errorf("sandbox_id is required")
+	}
+	limit := request.GetInt("limit", defaultHistoryLimit)
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	outputLimit := s.cfg.MCP.HistoryOutputLimit
+	if outputLimit <= 0 {
+		outputLimit = defaultHistoryOutput
+	}
+
+	records, err := s.service.ListSandboxCommands(ctx, sandboxID, limit, "")
+	if err != nil {
+		s.logger.Error("get_command_history failed", "error", err, "sandbox_id", sandboxID)
+		return errorResult(map[string]any{"sandbox_id": sandboxID, "error": fmt.Sprintf("list commands: %s", err)})
+	}
+	commands := make([]map[string]any, 0, len(records))
+	for _, r := range records {
+		cmd := map[string]any{
+			"command":     r.Command,
+			"exit_code":   r.ExitCode,
+			"started_at":  r.StartedAt,
+			"ended_at":    r.EndedAt,
+			"duration_ms": r.DurationMS,
+			"stdout":      tailOutput(r.Stdout, outputLimit),
+			"stderr":      tailOutput(r.Stderr, outputLimit),
+		}
+		if r.Actor != "" {
+			cmd["actor"] = r.Actor
+		}
+		commands = append(commands, cmd)
+	}
+	return jsonResult(map[string]any{
+		"sandbox_id": sandboxID,
+		"commands":   commands,
+		"count":      len(commands),
+	})
+}
+
 func (s *Server) handleStartSandbox(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.trackToolCall("start_sandbox")
 
@@ -271,6 +376,14 @@ func (s *Server) handleGetSandbox(ctx context.Context, request mcp.CallToolReque
 	if sb.IPAddress != "" {
 		result["ip"] = sb.IPAddress
 	}
+	if d := sb.Disk; d != nil {
+		result["disk"] = map[string]any{
+			"total_bytes":     d.TotalBytes,
+			"available_bytes": d.AvailableBytes,
+			"used_percent":    int(d.UsedPercent() + 0.5),
+			"nearly_full":     d.NearlyFull(),
+		}
+	}
 
 	return jsonResult(result)
 }
@@ -333,6 +446,83 @@ func (s *Server) handleCreateSnapshot(ctx context.Context, request mcp.CallToolR
 	})
 }
 
+// snapshotLister is implemented by sandbox services that keep a record of
+// snapshots (the daemon-backed RemoteService).
+type snapshotLister interface {
+	ListSnapshots(ctx context.Context, sandboxID string) ([]*sandbox.SnapshotInfo, error)
+}
+
+// snapshotDiffer is implemented by sandbox services that can diff snapshots.
+type snapshotDiffer interface {
+	DiffSnapshots(ctx context.Context, sandboxID, from, to string, onProgress func(step string)) (*sandbox.SnapshotDiff, error)
+}
+
+func (s *Server) handleListSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.trackToolCall("list_snapshots")
+
+	sandboxID := request.GetString("sandbox_id", "")
+	if sandboxID == "" {
+		return nil, fmt.Errorf("sandbox_id is required")
+	}
+	lister, ok := s.service.(snapshotLister)
+	if !ok {
+		return errorResult(map[string]any{"sandbox_id": sandboxID, "error": "listing snapshots needs a sandbox host"})
+	}
+
+	snaps, err := lister.ListSnapshots(ctx, sandboxID)
+	if err != nil {
+		s.logger.Error("list_snapshots failed", "error", err, "sandbox_id", sandboxID)
+		return errorResult(map[string]any{"sandbox_id": sandboxID, "error": fmt.Sprintf("list snapshots: %s", err)})
+	}
+	if snaps == nil {
+		snaps = []*sandbox.SnapshotInfo{}
+	}
+	return jsonResult(map[string]any{
+		"sandbox_id": sandboxID,
+		"snapshots":  snaps,
+		"count":      len(snaps),
+	})
+}
+
+func (s *Server) handleDiffSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.trackToolCall("diff_snapshots")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	sandboxID := request.GetString("sandbox_id", "")
+	if sandboxID == "" {
+		return nil, fmt.Errorf("sandbox_id is required")
+	}
+	from := request.GetString("from", "")
+	if from == "" {
+		return nil, fmt.Errorf("from is required")
+	}
+	to := request.GetString("to", "")
+	format := request.GetString("format", "files")
+	if format != "files" && format != "unified" {
+		return nil, fmt.Errorf("format must be 'files' or 'unified'")
+	}
+	differ, ok := s.service.(snapshotDiffer)
+	if !ok {
+		return errorResult(map[string]any{"sandbox_id": sandboxID, "error": "diffing snapshots needs a sandbox host"})
+	}
+
+	diff, err := differ.DiffSnapshots(ctx, sandboxID, from, to, nil)
+	if err != nil {
+		s.logger.Error("diff_snapshots failed", "error", err, "sandbox_id", sandboxID, "from", from, "to", to)
+		return errorResult(map[string]any{"sandbox_id": sandboxID, "from": from, "to": to, "error": fmt.Sprintf("diff snapshots: %s", err)})
+	}
+	if format == "unified" {
+		reader, ok := s.service.(sandbox.SnapshotFileReader)
+		if !ok {
+			return errorResult(map[string]any{"sandbox_id": sandboxID, "error": "unified diffs need a sandbox host"})
+		}
+		diff.Patches = sandbox.UnifiedDiffs(ctx, reader, diff, request.GetInt("max_files", 0), request.GetInt("max_file_size", 0))
+	}
+	return jsonResult(diff)
+}
+
 func (s *Server) handleCreatePlaybook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.trackToolCall("create_playbook")
 
@@ -366,6 +556,51 @@ func (s *Server) handleCreatePlaybook(ctx context.Context, request mcp.CallToolR
 	return jsonResult(result)
 }
 
+func (s *Server) handleCreatePlaybookFromSandbox(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.trackToolCall("create_playbook_from_sandbox")
+
+	sandboxID := request.GetString("sandbox_id", "")
+	if sandboxID == "" {
+		return nil, fmt.Errorf("sandbox_id is required")
+	}
+	name := request.GetString("name", "")
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	pb, err := s.playbookService.FromCommandHistory(ctx, s.service, sandboxID, ansible.CreatePlaybookRequest{
+		Name:   name,
+		Hosts:  request.GetString("hosts", ""),
+		Become: request.GetBool("become", false),
+	})
+	if err != nil {
+		s.logger.Error("create_playbook_from_sandbox failed", "error", err, "sandbox_id", sandboxID, "name", name)
+		return errorResult(map[string]any{"sandbox_id": sandboxID, "name": name, "error": fmt.Sprintf("create playbook from sandbox: %s", err)})
+	}
+
+	tasks := make([]map[string]any, 0, len(pb.Tasks))
+	for _, t := range pb.Tasks {
+		tasks = append(tasks, map[string]any{
+			"id":       t.ID,
+			"name":     t.Name,
+			"module":   t.Module,
+			"position": t.Position,
+		})
+	}
+	result := map[string]any{
+		"id":         pb.Playbook.ID,
+		"name":       pb.Playbook.Name,
+		"hosts":      pb.Playbook.Hosts,
+		"become":     pb.Playbook.Become,
+		"sandbox_id": sandboxID,
+		"tasks":      tasks,
+	}
+	if pb.Playbook.FilePath != nil {
+		result["file_path"] = *pb.Playbook.FilePath
+	}
+	return jsonResult(result)
+}
+
 func (s *Server) handleAddPlaybookTask(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.trackToolCall("add_playbook_task")
 
@@ -668,6 +903,82 @@ func (s *Server) handleGetPlaybook(ctx context.Context, request mcp.CallToolRequ
 	return jsonResult(result)
 }
 
+func (s *Server) handleExportPlaybook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.trackToolCall("export_playbook")
+
+	playbookID := request.GetString("playbook_id", "")
+	if playbookID == "" {
+		return nil, fmt.Errorf("playbook_id is required")
+	}
+
+	yamlContent, err := s.playbookService.ExportPlaybook(ctx, playbookID)
+	if err != nil {
+		s.logger.Error("export_playbook failed", "error", err, "playbook_id", playbookID)
+		return errorResult(map[string]any{"playbook_id": playbookID, "error": fmt.Sprintf("export playbook: %s", err)})
+	}
+
+	return jsonResult(map[string]any{
+		"playbook_id":  playbookID,
+		"yaml_content": string(yamlContent),
+	})
+}
+
+// maxPlaybookOutput caps how much ansible-playbook output is returned to the
+// MCP client. The PLAY RECAP is always at the end, so the tail is kept.
+const maxPlaybookOutput = 16 * 1024
+
+func (s *Server) handleRunPlaybook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.trackToolCall("run_playbook")
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	playbookID := request.GetString("playbook_id", "")
+	if playbookID == "" {
+		return nil, fmt.Errorf("playbook_id is required")
+	}
+	sandboxID := request.GetString("sandbox_id", "")
+	if sandboxID == "" {
+		return nil, fmt.Errorf("sandbox_id is required")
+	}
+	timeoutSec := request.GetInt("timeout_seconds", 0)
+
+	run, err := s.playbookService.RunInSandbox(ctx, s.service, playbookID, sandboxID, timeoutSec, nil)
+	if err != nil {
+		s.logger.Error("run_playbook failed", "error", err, "playbook_id", playbookID, "sandbox_id", sandboxID)
+		resp := map[string]any{"playbook_id": playbookID, "sandbox_id": sandboxID, "error": err.Error()}
+		if run != nil {
+			resp["exit_code"] = run.ExitCode
+			resp["stdout"] = tailOutput(run.Stdout, maxPlaybookOutput)
+			resp["stderr"] = tailOutput(run.Stderr, maxPlaybookOutput)
+		}
+		return errorResult(resp)
+	}
+
+	resp := map[string]any{
+		"playbook_id": playbookID,
+		"sandbox_id":  sandboxID,
+		"success":     run.Success,
+		"exit_code":   run.ExitCode,
+		"recap":       run.Recap,
+		"tasks":       run.Tasks,
+		"stdout":      tailOutput(run.Stdout, maxPlaybookOutput),
+		"stderr":      tailOutput(run.Stderr, maxPlaybookOutput),
+	}
+	if run.Error != "" {
+		resp["error"] = run.Error
+	}
+	return jsonResult(resp)
+}
+
+// tailOutput returns at most limit bytes from the end of s.
+func tailOutput(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return "...(truncated)\n" + s[len(s)-limit:]
+}
+
 func (s *Server) handleRunSourceCommand(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	s.trackToolCall("run_source_command")
 
@@ -709,6 +1020,13 @@ func (s *Server) handleRunSourceCommand(ctx context.Context, request mcp.CallToo
 	}
 
 	// Fallback to daemon-based source command
+	if err := source.ReadonlyPolicy(s.cfg).ValidateCommand(command); err != nil {
+		return errorResult(map[string]any{
+			"host":    host,
+			"command": command,
+			"error":   fmt.Sprintf("command not allowed: %s", err),
+		})
+	}
 	timeoutSec := request.GetInt("timeout_seconds", 0)
 	result, err := s.service.RunSourceCommand(ctx, host, command, timeoutSec)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/aspectrr/deer.sh/deer-cli/internal/ansible"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/sandbox"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
@@ -62,12 +63,14 @@ func testConfig() *config.Config {
 
 type mockStore struct {
 	sandboxes       map[string]*store.Sandbox
+	playbooks       map[string]*store.Playbook
 	listSandboxesFn func(ctx context.Context, filter store.SandboxFilter, opt *store.ListOptions) ([]*store.Sandbox, error)
 }
 
 func newMockStore() *mockStore {
 	return &mockStore{
 		sandboxes: make(map[string]*store.Sandbox),
+		playbooks: make(map[string]*store.Playbook),
 	}
 }
 
@@ -91,6 +94,19 @@ func (m *mockStore) GetSandbox(ctx context.Context, id string) (*store.Sandbox,
 	return sb, nil
 }
 
+func (m *mockStore) GetSandboxes(ctx context.Context, ids []string) ([]*store.Sandbox, []string, error) {
+	var found []*store.Sandbox
+	var notFound []string
+	for _, id := range ids {
+		if sb, ok := m.sandboxes[id]; ok {
+			found = append(found, sb)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return found, notFound, nil
+}
+
 func (m *mockStore) GetSandboxByVMName(ctx context.Context, vmName string) (*store.Sandbox, error) {
 	return nil, store.ErrNotFound
 }
@@ -161,6 +177,9 @@ func (m *mockStore) GetPublication(ctx context.Context, id string) (*store.Publi
 }
 func (m *mockStore) CreatePlaybook(ctx context.Context, pb *store.Playbook) error { return nil }
 func (m *mockStore) GetPlaybook(ctx context.Context, id string) (*store.Playbook, error) {
+	if pb, ok := m.playbooks[id]; ok {
+		return pb, nil
+	}
 	return nil, store.ErrNotFound
 }
 
@@ -200,8 +219,17 @@ func (m *mockStore) GetNextTaskPosition(ctx context.Context, playbookID string)
 func (m *mockStore) GetSourceVM(ctx context.Context, name string) (*store.SourceVM, error) {
 	return nil, store.ErrNotFound
 }
-func (m *mockStore) UpsertSourceVM(ctx context.Context, svm *store.SourceVM) error { return nil }
-func (m *mockStore) ListSourceVMs(ctx context.Context) ([]*store.SourceVM, error)  { return nil, nil }
+func (m *mockStore) UpsertSourceVM(ctx context.Context, svm *store.SourceVM) error     { return nil }
+func (m *mockStore) ListSourceVMs(ctx context.Context) ([]*store.SourceVM, error)      { return nil, nil }
+func (m *mockStore) ReplaceVMCache(ctx context.Context, vms []*store.CachedVM) error   { return nil }
+func (m *mockStore) ListCachedVMs(ctx context.Context) ([]*store.CachedVM, error)      { return nil, nil }
+func (m *mockStore) SaveConversation(ctx context.Context, c *store.Conversation) error { return nil }
+func (m *mockStore) LoadConversation(ctx context.Context, id string) (*store.Conversation, error) {
+	return nil, store.ErrNotFound
+}
+func (m *mockStore) ListConversations(ctx context.Context, opt *store.ListOptions) ([]*store.Conversation, error) {
+	return nil, nil
+}
 
 // --- mock sandbox.Service ---
 
@@ -213,6 +241,8 @@ type mockSandboxService struct {
 	startSandboxFn     func(ctx context.Context, id string) (*sandbox.SandboxInfo, error)
 	stopSandboxFn      func(ctx context.Context, id string, force bool) error
 	runCommandFn       func(ctx context.Context, sandboxID, command string, timeoutSec int, env map[string]string) (*sandbox.CommandResult, error)
+	lastRunOptions     sandbox.RunOptions
+	listCommandsFn     func(ctx context.Context, sandboxID string, limit int) ([]*sandbox.CommandRecord, error)
 	createSnapshotFn   func(ctx context.Context, sandboxID, name string) (*sandbox.SnapshotInfo, error)
 	listVMsFn          func(ctx context.Context) ([]*sandbox.VMInfo, error)
 	runSourceCommandFn func(ctx context.Context, vmName, command string, timeoutSec int) (*sandbox.SourceCommandResult, error)
@@ -233,6 +263,19 @@ func (m *mockSandboxService) GetSandbox(ctx context.Context, id string) (*sandbo
 	return nil, fmt.Errorf("sandbox not found: %s", id)
 }
 
+func (m *mockSandboxService) GetSandboxes(ctx context.Context, ids []string) ([]*sandbox.SandboxInfo, []string, error) {
+	var found []*sandbox.SandboxInfo
+	var notFound []string
+	for _, id := range ids {
+		if sb, err := m.GetSandbox(ctx, id); err == nil {
+			found = append(found, sb)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return found, notFound, nil
+}
+
 func (m *mockSandboxService) ListSandboxes(ctx context.Context) ([]*sandbox.SandboxInfo, error) {
 	if m.listSandboxesFn != nil {
 		return m.listSandboxesFn(ctx)
@@ -261,6 +304,10 @@ func (m *mockSandboxService) StopSandbox(ctx context.Context, id string, force b
 	return nil
 }
 
+func (m *mockSandboxService) ImportSandbox(ctx context.Context, vmName, name string) (*sandbox.SandboxInfo, error) {
+	return &sandbox.SandboxInfo{ID: "SBX-imported", Name: vmName, Imported: true}, nil
+}
+
 func (m *mockSandboxService) RunCommand(ctx context.Context, sandboxID, command string, timeoutSec int, env map[string]string) (*sandbox.CommandResult, error) {
 	if m.runCommandFn != nil {
 		return m.runCommandFn(ctx, sandboxID, command, timeoutSec, env)
@@ -268,6 +315,18 @@ func (m *mockSandboxService) RunCommand(ctx context.Context, sandboxID, command
 	return &sandbox.CommandResult{SandboxID: sandboxID, ExitCode: 0}, nil
 }
 
+func (m *mockSandboxService) RunCommandWithOptions(ctx context.Context, sandboxID, command string, opts sandbox.RunOptions) (*sandbox.CommandResult, error) {
+	m.lastRunOptions = opts
+	return m.RunCommand(ctx, sandboxID, command, opts.TimeoutSec, opts.Env)
+}
+
+func (m *mockSandboxService) ListSandboxCommands(ctx context.Context, sandboxID string, limit int, actor string) ([]*sandbox.CommandRecord, error) {
+	if m.listCommandsFn != nil {
+		return m.listCommandsFn(ctx, sandboxID, limit)
+	}
+	return nil, nil
+}
+
 func (m *mockSandboxService) CreateSnapshot(ctx context.Context, sandboxID, name string) (*sandbox.SnapshotInfo, error) {
 	if m.createSnapshotFn != nil {
 		return m.createSnapshotFn(ctx, sandboxID, name)
@@ -309,6 +368,9 @@ func (m *mockSandboxService) GetHostInfo(ctx context.Context) (*sandbox.HostInfo
 }
 
 func (m *mockSandboxService) Health(ctx context.Context) error { return nil }
+func (m *mockSandboxService) GetStatus(ctx context.Context) (*sandbox.DaemonStatus, error) {
+	return &sandbox.DaemonStatus{}, nil
+}
 func (m *mockSandboxService) DoctorCheck(ctx context.Context) ([]sandbox.DoctorCheckResult, error) {
 	return nil, nil
 }
@@ -483,6 +545,26 @@ func TestJsonResult_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "marshal result")
 }
 
+func TestJSONResult_NonSerializableValues(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	result, err := jsonResult(map[string]any{
+		"error":  fmt.Errorf("boom"),
+		"data":   []byte("hi"),
+		"at":     ts,
+		"ch":     make(chan int),
+		"nested": []map[string]any{{"err": fmt.Errorf("inner")}},
+	})
+	require.NoError(t, err)
+
+	m := parseJSON(t, result)
+	assert.Equal(t, "boom", m["error"])
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("hi")), m["data"])
+	assert.Equal(t, ts.Format(time.RFC3339), m["at"])
+	assert.Equal(t, "<chan int>", m["ch"])
+	nested := m["nested"].([]any)
+	assert.Equal(t, "inner", nested[0].(map[string]any)["err"])
+}
+
 // --- errorResult tests ---
 
 func TestErrorResult(t *testing.T) {
@@ -502,6 +584,13 @@ func TestErrorResult_MarshalError(t *testing.T) {
 	assert.Contains(t, err.Error(), "marshal error result")
 }
 
+func TestErrorResult_ErrorValue(t *testing.T) {
+	result, err := errorResult(map[string]any{"error": fmt.Errorf("create sandbox: quota")})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "create sandbox: quota", parseJSON(t, result)["error"])
+}
+
 // --- handleListSandboxes tests ---
 
 func TestHandleListSandboxes_Empty(t *testing.T) {
@@ -691,6 +780,52 @@ func TestHandleRunCommand_MissingCommand(t *testing.T) {
 	assert.Contains(t, err.Error(), "command is required")
 }
 
+// --- handleGetCommandHistory tests ---
+
+func TestHandleGetCommandHistory(t *testing.T) {
+	var gotLimit int
+	srv := testServerWithService(&mockSandboxService{
+		listCommandsFn: func(_ context.Context, sandboxID string, limit int) ([]*sandbox.CommandRecord, error) {
+			gotLimit = limit
+			return []*sandbox.CommandRecord{
+				{Command: "apt-get install -y nginx", ExitCode: 0, Stdout: strings.Repeat("x", 100) + "done", Actor: "mcp-client"},
+				{Command: "systemctl status nginx", ExitCode: 3, Stderr: "inactive"},
+			}, nil
+		},
+	})
+	srv.cfg.MCP.HistoryOutputLimit = 4
+
+	result, err := srv.handleGetCommandHistory(context.Background(), newRequest("get_command_history", map[string]any{"sandbox_id": "SBX-1", "limit": float64(5)}))
+	require.NoError(t, err)
+	assert.Equal(t, 5, gotLimit)
+	m := parseJSON(t, result)
+	assert.Equal(t, float64(2), m["count"])
+	cmds := m["commands"].([]any)
+	first := cmds[0].(map[string]any)
+	assert.Equal(t, "apt-get install -y nginx", first["command"])
+	assert.Equal(t, "...(truncated)\ndone", first["stdout"])
+	assert.Equal(t, "mcp-client", first["actor"])
+	assert.Equal(t, float64(3), cmds[1].(map[string]any)["exit_code"])
+}
+
+func TestHandleGetCommandHistory_Defaults(t *testing.T) {
+	var gotLimit int
+	srv := testServerWithService(&mockSandboxService{
+		listCommandsFn: func(_ context.Context, _ string, limit int) ([]*sandbox.CommandRecord, error) {
+			gotLimit = limit
+			return nil, nil
+		},
+	})
+
+	result, err := srv.handleGetCommandHistory(context.Background(), newRequest("get_command_history", map[string]any{"sandbox_id": "SBX-1"}))
+	require.NoError(t, err)
+	assert.Equal(t, defaultHistoryLimit, gotLimit)
+	assert.Equal(t, []any{}, parseJSON(t, result)["commands"])
+
+	_, err = srv.handleGetCommandHistory(context.Background(), newRequest("get_command_history", map[string]any{}))
+	assert.ErrorContains(t, err, "sandbox_id is required")
+}
+
 // --- handleCreateSnapshot tests ---
 
 func TestHandleCreateSnapshot_MissingSandboxID(t *testing.T) {
@@ -702,6 +837,80 @@ func TestHandleCreateSnapshot_MissingSandboxID(t *testing.T) {
 	assert.Contains(t, err.Error(), "sandbox_id is required")
 }
 
+// snapshotService adds snapshot listing and diffing to the mock.
+type snapshotService struct {
+	mockSandboxService
+	diffArgs []string
+}
+
+func (m *snapshotService) ListSnapshots(_ context.Context, sandboxID string) ([]*sandbox.SnapshotInfo, error) {
+	return []*sandbox.SnapshotInfo{{SnapshotID: "SNP-1", SnapshotName: "before", SandboxID: sandboxID}}, nil
+}
+
+func (m *snapshotService) DiffSnapshots(_ context.Context, sandboxID, from, to string, _ func(string)) (*sandbox.SnapshotDiff, error) {
+	m.diffArgs = []string{sandboxID, from, to}
+	return &sandbox.SnapshotDiff{SandboxID: sandboxID, From: from, To: to, FilesModified: []string{"/etc/nginx/nginx.conf"}}, nil
+}
+
+func (m *snapshotService) RestoreSnapshotFile(_ context.Context, _, snapshot, _ string, _ bool) ([]byte, error) {
+	return []byte("worker_processes 1;\n"), nil
+}
+
+func TestHandleDiffSnapshots_Unified(t *testing.T) {
+	svc := &snapshotService{}
+	svc.runCommandFn = func(_ context.Context, _, command string, _ int, _ map[string]string) (*sandbox.CommandResult, error) {
+		return &sandbox.CommandResult{Stdout: base64.StdEncoding.EncodeToString([]byte("worker_processes 4;\n"))}, nil
+	}
+	srv := testServerWithService(svc)
+
+	result, err := srv.handleDiffSnapshots(context.Background(), newRequest("diff_snapshots", map[string]any{
+		"sandbox_id": "SBX-1", "from": "before", "format": "unified",
+	}))
+	require.NoError(t, err)
+	patches := parseJSON(t, result)["patches"].([]any)
+	require.Len(t, patches, 1)
+	patch := patches[0].(map[string]any)["patch"].(string)
+	assert.Contains(t, patch, "-worker_processes 1;\n+worker_processes 4;\n")
+
+	_, err = srv.handleDiffSnapshots(context.Background(), newRequest("diff_snapshots", map[string]any{
+		"sandbox_id": "SBX-1", "from": "before", "format": "side-by-side",
+	}))
+	assert.ErrorContains(t, err, "format must be")
+}
+
+func TestHandleListSnapshots(t *testing.T) {
+	srv := testServerWithService(&snapshotService{})
+
+	result, err := srv.handleListSnapshots(context.Background(), newRequest("list_snapshots", map[string]any{"sandbox_id": "SBX-1"}))
+	require.NoError(t, err)
+	m := parseJSON(t, result)
+	assert.Equal(t, float64(1), m["count"])
+	snaps := m["snapshots"].([]any)
+	assert.Equal(t, "before", snaps[0].(map[string]any)["snapshot_name"])
+}
+
+func TestHandleListSnapshots_Unsupported(t *testing.T) {
+	srv := testServer()
+
+	result, err := srv.handleListSnapshots(context.Background(), newRequest("list_snapshots", map[string]any{"sandbox_id": "SBX-1"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleDiffSnapshots(t *testing.T) {
+	svc := &snapshotService{}
+	srv := testServerWithService(svc)
+
+	result, err := srv.handleDiffSnapshots(context.Background(), newRequest("diff_snapshots", map[string]any{"sandbox_id": "SBX-1", "from": "before"}))
+	require.NoError(t, err)
+	m := parseJSON(t, result)
+	assert.Equal(t, []string{"SBX-1", "before", ""}, svc.diffArgs)
+	assert.Equal(t, []any{"/etc/nginx/nginx.conf"}, m["files_modified"])
+
+	_, err = srv.handleDiffSnapshots(context.Background(), newRequest("diff_snapshots", map[string]any{"sandbox_id": "SBX-1"}))
+	assert.ErrorContains(t, err, "from is required")
+}
+
 // --- handleCreatePlaybook tests ---
 
 func TestHandleCreatePlaybook_MissingName(t *testing.T) {
@@ -713,6 +922,39 @@ func TestHandleCreatePlaybook_MissingName(t *testing.T) {
 	assert.Contains(t, err.Error(), "name is required")
 }
 
+// --- handleCreatePlaybookFromSandbox tests ---
+
+func TestHandleCreatePlaybookFromSandbox_MissingParams(t *testing.T) {
+	srv := testServer()
+	ctx := context.Background()
+
+	_, err := srv.handleCreatePlaybookFromSandbox(ctx, newRequest("create_playbook_from_sandbox", map[string]any{"name": "web"}))
+	assert.ErrorContains(t, err, "sandbox_id is required")
+
+	_, err = srv.handleCreatePlaybookFromSandbox(ctx, newRequest("create_playbook_from_sandbox", map[string]any{"sandbox_id": "SBX-1"}))
+	assert.ErrorContains(t, err, "name is required")
+}
+
+func TestHandleCreatePlaybookFromSandbox_NoChanges(t *testing.T) {
+	srv := testServerWithService(&mockSandboxService{
+		listCommandsFn: func(context.Context, string, int) ([]*sandbox.CommandRecord, error) {
+			return []*sandbox.CommandRecord{
+				{Command: "cat /etc/os-release", ExitCode: 0},
+				{Command: "apt-get install -y nginx", ExitCode: 100},
+			}, nil
+		},
+	})
+	srv.playbookService = ansible.NewPlaybookService(srv.store, t.TempDir())
+
+	result, err := srv.handleCreatePlaybookFromSandbox(context.Background(), newRequest("create_playbook_from_sandbox", map[string]any{
+		"sandbox_id": "SBX-1",
+		"name":       "web",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, parseJSON(t, result)["error"], "no successful commands")
+}
+
 // --- handleAddPlaybookTask tests ---
 
 func TestHandleAddPlaybookTask_MissingPlaybookID(t *testing.T) {
@@ -751,6 +993,102 @@ func TestHandleAddPlaybookTask_MissingModule(t *testing.T) {
 	assert.Contains(t, err.Error(), "module is required")
 }
 
+// --- handleExportPlaybook tests ---
+
+func TestHandleExportPlaybook_MissingID(t *testing.T) {
+	srv := testServer()
+	ctx := context.Background()
+
+	_, err := srv.handleExportPlaybook(ctx, newRequest("export_playbook", map[string]any{}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "playbook_id is required")
+}
+
+func TestHandleExportPlaybook_NotFound(t *testing.T) {
+	srv := testServer()
+	srv.playbookService = ansible.NewPlaybookService(newMockStore(), "")
+	ctx := context.Background()
+
+	result, err := srv.handleExportPlaybook(ctx, newRequest("export_playbook", map[string]any{
+		"playbook_id": "PB-missing",
+	}))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	m := parseJSON(t, result)
+	assert.Equal(t, "PB-missing", m["playbook_id"])
+}
+
+// --- handleRunPlaybook tests ---
+
+func TestHandleRunPlaybook_MissingPlaybookID(t *testing.T) {
+	srv := testServer()
+	ctx := context.Background()
+
+	_, err := srv.handleRunPlaybook(ctx, newRequest("run_playbook", map[string]any{
+		"sandbox_id": "SBX-1",
+	}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "playbook_id is required")
+}
+
+func TestHandleRunPlaybook_MissingSandboxID(t *testing.T) {
+	srv := testServer()
+	ctx := context.Background()
+
+	_, err := srv.handleRunPlaybook(ctx, newRequest("run_playbook", map[string]any{
+		"playbook_id": "PB-1",
+	}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sandbox_id is required")
+}
+
+func TestHandleRunPlaybook_SummarizesRecap(t *testing.T) {
+	var commands []string
+	svc := &mockSandboxService{
+		runCommandFn: func(_ context.Context, _, command string, _ int, _ map[string]string) (*sandbox.CommandResult, error) {
+			commands = append(commands, command)
+			if strings.HasPrefix(command, "ANSIBLE_NOCOLOR=1 ansible-playbook") {
+				return &sandbox.CommandResult{
+					ExitCode: 2,
+					Stdout:   "PLAY RECAP ***\nlocalhost : ok=1 changed=1 unreachable=0 failed=1 skipped=0\n",
+				}, nil
+			}
+			return &sandbox.CommandResult{ExitCode: 0}, nil
+		},
+	}
+	st := newMockStore()
+	st.playbooks["PB-1"] = &store.Playbook{ID: "PB-1", Name: "nginx", Hosts: "all"}
+	srv := testServerWithService(svc)
+	srv.playbookService = ansible.NewPlaybookService(st, "")
+	ctx := context.Background()
+
+	result, err := srv.handleRunPlaybook(ctx, newRequest("run_playbook", map[string]any{
+		"playbook_id": "PB-1",
+		"sandbox_id":  "SBX-1",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	require.Len(t, commands, 2)
+	assert.Contains(t, commands[0], "base64 -d > '/tmp/deer-playbook-PB-1.yml'")
+	assert.Contains(t, commands[1], "-i 'localhost,' -c local '/tmp/deer-playbook-PB-1.yml'")
+
+	m := parseJSON(t, result)
+	assert.Equal(t, false, m["success"])
+	assert.Equal(t, float64(2), m["exit_code"])
+	recap, ok := m["recap"].([]any)
+	require.True(t, ok)
+	require.Len(t, recap, 1)
+	host := recap[0].(map[string]any)
+	assert.Equal(t, "localhost", host["host"])
+	assert.Equal(t, float64(1), host["failed"])
+}
+
+func TestTailOutput(t *testing.T) {
+	assert.Equal(t, "short", tailOutput("short", 10))
+	assert.Equal(t, "...(truncated)\nrecap", tailOutput("head and recap", 5))
+}
+
 // --- handleEditFile tests ---
 
 func TestHandleEditFile_MissingSandboxID(t *testing.T) {
@@ -861,6 +1199,25 @@ func TestHandleRunSourceCommand_MissingCommand(t *testing.T) {
 	assert.Contains(t, err.Error(), "command is required")
 }
 
+func TestHandleRunSourceCommand_DaemonUsesReadonlyPolicy(t *testing.T) {
+	called := false
+	srv := testServerWithService(&mockSandboxService{
+		runSourceCommandFn: func(_ context.Context, vmName, _ string, _ int) (*sandbox.SourceCommandResult, error) {
+			called = true
+			return &sandbox.SourceCommandResult{SourceVM: vmName}, nil
+		},
+	})
+	srv.cfg.Readonly.Deny = []string{"find"}
+
+	result, err := srv.handleRunSourceCommand(context.Background(), newRequest("run_source_command", map[string]any{
+		"host":    "ubuntu-base",
+		"command": "find / -name id_rsa",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.False(t, called, "a denied command reached the daemon")
+}
+
 // --- handleReadSourceFile tests ---
 
 func TestHandleReadSourceFile_MissingHost(t *testing.T) {
@@ -1001,6 +1358,20 @@ func TestHandleRunCommand_IncludesCommandInError(t *testing.T) {
 	assert.Equal(t, "whoami", m["command"])
 }
 
+func TestHandleRunCommand_Cwd(t *testing.T) {
+	svc := &mockSandboxService{}
+	srv := testServerWithService(svc)
+
+	result, err := srv.handleRunCommand(context.Background(), newRequest("run_command", map[string]any{
+		"sandbox_id": "SBX-1",
+		"command":    "make",
+		"cwd":        "/src/app",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, "/src/app", svc.lastRunOptions.Workdir)
+}
+
 // --- handleEditFile with mock VM ---
 
 func TestHandleEditFile_OldStrNotFound(t *testing.T) {
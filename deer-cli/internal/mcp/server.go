@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -71,13 +72,24 @@ func (s *Server) Serve() error {
 	return server.ServeStdio(s.mcpServer)
 }
 
+// addTool registers a tool unless it has been disabled in the MCP config.
+func (s *Server) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if !s.cfg.MCP.ToolEnabled(tool.Name) {
+		s.logger.Debug("mcp tool disabled by config", "tool", tool.Name)
+		return
+	}
+	s.mcpServer.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handler(sandbox.WithActor(ctx, sandbox.ActorMCPClient), request)
+	})
+}
+
 // registerTools registers all deer tools on the MCP server.
 func (s *Server) registerTools() {
-	s.mcpServer.AddTool(mcp.NewTool("list_sandboxes",
+	s.addTool(mcp.NewTool("list_sandboxes",
 		mcp.WithDescription("List all existing sandboxes with their state and IP addresses."),
 	), s.handleListSandboxes)
 
-	s.mcpServer.AddTool(mcp.NewTool("create_sandbox",
+	s.addTool(mcp.NewTool("create_sandbox",
 		mcp.WithDescription("Create a new sandbox VM by cloning from a base image. Use list_vms first to see available base images for cloning."),
 		mcp.WithString("source_vm", mcp.Required(), mcp.Description("The name of the base VM image to clone from. Must be a name returned by list_vms.")),
 		mcp.WithNumber("cpu", mcp.Description("Number of vCPUs (default: 2).")),
@@ -87,51 +99,81 @@ func (s *Server) registerTools() {
 		mcp.WithBoolean("es_stub", mcp.Description("If true, start a local single-node Elasticsearch inside the sandbox at localhost:9200.")),
 	), s.handleCreateSandbox)
 
-	s.mcpServer.AddTool(mcp.NewTool("destroy_sandbox",
+	s.addTool(mcp.NewTool("destroy_sandbox",
 		mcp.WithDescription("Completely destroy a sandbox VM and remove its storage."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox to destroy.")),
 	), s.handleDestroySandbox)
 
-	s.mcpServer.AddTool(mcp.NewTool("run_command",
+	s.addTool(mcp.NewTool("run_command",
 		mcp.WithDescription("Execute a shell command inside a sandbox via SSH."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox to run the command in.")),
 		mcp.WithString("command", mcp.Required(), mcp.Description("The shell command to execute.")),
 		mcp.WithNumber("timeout_seconds", mcp.Description("Optional command timeout in seconds. 0 or omitted uses the configured default.")),
+		mcp.WithString("cwd", mcp.Description("Optional absolute directory to run the command in. Omitted uses the sandbox's working directory, or the login directory if none is set.")),
 	), s.handleRunCommand)
 
-	s.mcpServer.AddTool(mcp.NewTool("start_sandbox",
+	s.addTool(mcp.NewTool("get_command_history",
+		mcp.WithDescription("List the commands previously run in a sandbox, newest first, with exit codes, timestamps, and the tail of their output. Use it to recall what has already been done after reconnecting."),
+		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox.")),
+		mcp.WithNumber("limit", mcp.Description("Maximum number of commands to return (default 20).")),
+	), s.handleGetCommandHistory)
+
+	s.addTool(mcp.NewTool("start_sandbox",
 		mcp.WithDescription("Start a stopped sandbox VM."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox to start.")),
 	), s.handleStartSandbox)
 
-	s.mcpServer.AddTool(mcp.NewTool("stop_sandbox",
+	s.addTool(mcp.NewTool("stop_sandbox",
 		mcp.WithDescription("Stop a running sandbox VM."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox to stop.")),
 	), s.handleStopSandbox)
 
-	s.mcpServer.AddTool(mcp.NewTool("get_sandbox",
+	s.addTool(mcp.NewTool("get_sandbox",
 		mcp.WithDescription("Get detailed information about a specific sandbox."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox.")),
 	), s.handleGetSandbox)
 
-	s.mcpServer.AddTool(mcp.NewTool("list_vms",
+	s.addTool(mcp.NewTool("list_vms",
 		mcp.WithDescription("List available base VM images that can be cloned to create sandboxes. These are the valid values for the source_vm parameter in create_sandbox."),
 	), s.handleListVMs)
 
-	s.mcpServer.AddTool(mcp.NewTool("create_snapshot",
+	s.addTool(mcp.NewTool("create_snapshot",
 		mcp.WithDescription("Create a snapshot of the current sandbox state."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox.")),
 		mcp.WithString("name", mcp.Description("Optional name for the snapshot.")),
 	), s.handleCreateSnapshot)
 
-	s.mcpServer.AddTool(mcp.NewTool("create_playbook",
+	s.addTool(mcp.NewTool("list_snapshots",
+		mcp.WithDescription("List a sandbox's snapshots and disk archives, oldest first."),
+		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox.")),
+	), s.handleListSnapshots)
+
+	s.addTool(mcp.NewTool("diff_snapshots",
+		mcp.WithDescription("Compare two snapshots of a sandbox, or a snapshot with the live sandbox: files added, modified, and removed, packages changed, and the commands run in between."),
+		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox.")),
+		mcp.WithString("from", mcp.Required(), mcp.Description("Name of the earlier snapshot.")),
+		mcp.WithString("to", mcp.Description("Name of the later snapshot. Omit to compare with the sandbox's current state.")),
+		mcp.WithString("format", mcp.Description("'files' (default) lists changed paths; 'unified' also returns a unified diff of each modified file's contents.")),
+		mcp.WithNumber("max_files", mcp.Description("With format 'unified', the most modified files to diff (default 20).")),
+		mcp.WithNumber("max_file_size", mcp.Description("With format 'unified', skip files larger than this many bytes (default 262144).")),
+	), s.handleDiffSnapshots)
+
+	s.addTool(mcp.NewTool("create_playbook",
 		mcp.WithDescription("Create a new Ansible playbook."),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the playbook.")),
 		mcp.WithString("hosts", mcp.Description("Target hosts (default: 'all').")),
 		mcp.WithBoolean("become", mcp.Description("Whether to use privilege escalation (sudo).")),
 	), s.handleCreatePlaybook)
 
-	s.mcpServer.AddTool(mcp.NewTool("add_playbook_task",
+	s.addTool(mcp.NewTool("create_playbook_from_sandbox",
+		mcp.WithDescription("Create an Ansible playbook from the commands run in a sandbox. Failed and read-only commands are skipped; package installs, service changes and file operations become their Ansible modules, everything else a command or shell task."),
+		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox whose command history to use.")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the playbook.")),
+		mcp.WithString("hosts", mcp.Description("Target hosts (default: 'all').")),
+		mcp.WithBoolean("become", mcp.Description("Force privilege escalation (sudo). It is enabled anyway if any command used sudo.")),
+	), s.handleCreatePlaybookFromSandbox)
+
+	s.addTool(mcp.NewTool("add_playbook_task",
 		mcp.WithDescription("Add a task to an Ansible playbook."),
 		mcp.WithString("playbook_id", mcp.Required(), mcp.Description("The ID of the playbook.")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Name of the task.")),
@@ -139,7 +181,7 @@ func (s *Server) registerTools() {
 		mcp.WithObject("params", mcp.Description("Parameters for the Ansible module.")),
 	), s.handleAddPlaybookTask)
 
-	s.mcpServer.AddTool(mcp.NewTool("edit_file",
+	s.addTool(mcp.NewTool("edit_file",
 		mcp.WithDescription("Edit a file on a sandbox VM by replacing text or create a new file."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox containing the file.")),
 		mcp.WithString("path", mcp.Required(), mcp.Description("The absolute path to the file inside the sandbox.")),
@@ -148,43 +190,55 @@ func (s *Server) registerTools() {
 		mcp.WithBoolean("replace_all", mcp.Description("Replace all occurrences of old_str. Default: false.")),
 	), s.handleEditFile)
 
-	s.mcpServer.AddTool(mcp.NewTool("read_file",
+	s.addTool(mcp.NewTool("read_file",
 		mcp.WithDescription("Read the contents of a file on a sandbox VM."),
 		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox containing the file.")),
 		mcp.WithString("path", mcp.Required(), mcp.Description("The absolute path to the file inside the sandbox.")),
 	), s.handleReadFile)
 
-	s.mcpServer.AddTool(mcp.NewTool("list_playbooks",
+	s.addTool(mcp.NewTool("list_playbooks",
 		mcp.WithDescription("List all Ansible playbooks."),
 	), s.handleListPlaybooks)
 
-	s.mcpServer.AddTool(mcp.NewTool("get_playbook",
+	s.addTool(mcp.NewTool("get_playbook",
 		mcp.WithDescription("Get the full definition of an Ansible playbook including its YAML content and all tasks."),
 		mcp.WithString("playbook_id", mcp.Required(), mcp.Description("The ID of the playbook to retrieve.")),
 	), s.handleGetPlaybook)
 
-	s.mcpServer.AddTool(mcp.NewTool("run_source_command",
+	s.addTool(mcp.NewTool("export_playbook",
+		mcp.WithDescription("Render an Ansible playbook to YAML without running it."),
+		mcp.WithString("playbook_id", mcp.Required(), mcp.Description("The ID of the playbook to export.")),
+	), s.handleExportPlaybook)
+
+	s.addTool(mcp.NewTool("run_playbook",
+		mcp.WithDescription("Run an Ansible playbook against a sandbox and return a per-host summary of the results. Use this to verify a playbook in a sandbox before it is applied to production."),
+		mcp.WithString("playbook_id", mcp.Required(), mcp.Description("The ID of the playbook to run.")),
+		mcp.WithString("sandbox_id", mcp.Required(), mcp.Description("The ID of the sandbox to run the playbook in.")),
+		mcp.WithNumber("timeout_seconds", mcp.Description("Optional run timeout in seconds. 0 or omitted uses the configured default.")),
+	), s.handleRunPlaybook)
+
+	s.addTool(mcp.NewTool("run_source_command",
 		mcp.WithDescription("Execute a read-only command on a source host. Only diagnostic commands allowed."),
 		mcp.WithString("host", mcp.Required(), mcp.Description("The name of the source host to run the command on.")),
 		mcp.WithString("command", mcp.Required(), mcp.Description("The read-only diagnostic command to execute.")),
 		mcp.WithNumber("timeout_seconds", mcp.Description("Optional command timeout in seconds.")),
 	), s.handleRunSourceCommand)
 
-	s.mcpServer.AddTool(mcp.NewTool("read_source_file",
+	s.addTool(mcp.NewTool("read_source_file",
 		mcp.WithDescription("Read the contents of a file on a source host. This is read-only."),
 		mcp.WithString("host", mcp.Required(), mcp.Description("The name of the source host containing the file.")),
 		mcp.WithString("path", mcp.Required(), mcp.Description("The absolute path to the file on the source host.")),
 	), s.handleReadSourceFile)
 
-	s.mcpServer.AddTool(mcp.NewTool("list_hosts",
+	s.addTool(mcp.NewTool("list_hosts",
 		mcp.WithDescription("List all configured source hosts (production systems) with their preparation status. These are for read-only investigation via run_source_command, NOT for create_sandbox."),
 	), s.handleListHosts)
 
-	s.mcpServer.AddTool(mcp.NewTool("list_skills",
+	s.addTool(mcp.NewTool("list_skills",
 		mcp.WithDescription("List all available skills. Skills provide domain-specific knowledge (e.g. Elasticsearch deployment, Kafka operations, on-call debugging). Use this to discover what skills are available, then use load_skill to get the full content."),
 	), s.handleListSkills)
 
-	s.mcpServer.AddTool(mcp.NewTool("load_skill",
+	s.addTool(mcp.NewTool("load_skill",
 		mcp.WithDescription("Load the full content of a skill by name. Use this after list_skills to retrieve detailed domain knowledge. The loaded skill provides procedures, runbooks, and tool usage guidance for specific technologies."),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the skill to load (must match a name from list_skills).")),
 	), s.handleLoadSkill)
@@ -40,3 +40,16 @@ func TestNewServer_RegistersAllTools(t *testing.T) {
 	require.NotNil(t, srv)
 	assert.NotNil(t, srv.mcpServer)
 }
+
+func TestNewServer_DisabledTools(t *testing.T) {
+	cfg := testConfig()
+	cfg.MCP.DisabledTools = []string{"run_playbook", "destroy_sandbox"}
+	st := newMockStore()
+
+	srv := NewServer(cfg, st, nil, nil, nil, noopLogger())
+	require.NotNil(t, srv)
+	assert.Nil(t, srv.mcpServer.GetTool("run_playbook"))
+	assert.Nil(t, srv.mcpServer.GetTool("destroy_sandbox"))
+	assert.NotNil(t, srv.mcpServer.GetTool("export_playbook"))
+	assert.NotNil(t, srv.mcpServer.GetTool("list_sandboxes"))
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"github.com/aspectrr/deer.sh/shared/multierr"
 )
 
 // sentinelName is the file written to the config dir after a successful migration.
@@ -74,10 +76,7 @@ func MaybeMigrate() error {
 	}
 
 	if len(copyErrors) > 0 {
-		for _, e := range copyErrors {
-			fmt.Fprintf(os.Stderr, "Warning: migration: %v\n", e)
-		}
-		return fmt.Errorf("migrate: %d file(s) failed to copy", len(copyErrors))
+		return fmt.Errorf("migrate: %d file(s) failed to copy: %w", len(copyErrors), multierr.Combine(copyErrors...))
 	}
 
 	// All copies succeeded - write sentinel
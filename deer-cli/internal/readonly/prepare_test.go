@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -53,10 +56,10 @@ func (m *mockSSHRun) getCommands() []string {
 }
 
 // decodeBase64Command extracts the original command from the base64 wrapper.
-// Prepare wraps every command as: echo <base64> | base64 -d | sudo bash
+// Prepare wraps every command as: echo <base64> | base64 -d | <rootShell>
 func decodeBase64Command(wrapped string) (string, error) {
 	prefix := "echo "
-	suffix := " | base64 -d | sudo bash"
+	suffix := " | base64 -d | " + rootShell
 	if !strings.HasPrefix(wrapped, prefix) || !strings.HasSuffix(wrapped, suffix) {
 		return "", fmt.Errorf("command not in expected base64 wrapper format: %s", wrapped)
 	}
@@ -132,7 +135,7 @@ func TestPrepare_Base64Wrapping(t *testing.T) {
 	}
 
 	for i, cmd := range commands {
-		if !strings.HasPrefix(cmd, "echo ") || !strings.HasSuffix(cmd, " | base64 -d | sudo bash") {
+		if !strings.HasPrefix(cmd, "echo ") || !strings.HasSuffix(cmd, " | base64 -d | "+rootShell) {
 			t.Errorf("command %d not base64-wrapped: %s", i, cmd)
 		}
 		_, err := decodeBase64Command(cmd)
@@ -880,3 +883,29 @@ func TestSetupSourceHost_NonZeroExit(t *testing.T) {
 		t.Errorf("error should mention setup source host: %v", err)
 	}
 }
+
+func TestAsRoot_RunsWithoutSudoAsRoot(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("needs root: a non-root user goes through sudo")
+	}
+	// A PATH holding only these tools proves root never calls sudo.
+	dir := t.TempDir()
+	for _, name := range []string{"bash", "base64", "id"} {
+		bin, err := exec.LookPath(name)
+		if err != nil {
+			t.Skipf("%s not installed", name)
+		}
+		if err := os.Symlink(bin, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("/bin/sh", "-c", asRoot(`echo "it's" $(id -u)`))
+	cmd.Env = []string{"PATH=" + dir}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run: %v: %s", err, out)
+	}
+	if got := strings.TrimSpace(string(out)); got != "it's 0" {
+		t.Errorf("output = %q, want %q", got, "it's 0")
+	}
+}
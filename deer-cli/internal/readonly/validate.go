@@ -36,3 +36,7 @@ func ValidateCommand(command string) error {
 func ValidateCommandWithExtra(command string, extraAllowed []string) error {
 	return readonly.ValidateCommandWithExtra(command, extraAllowed)
 }
+
+// Policy adjusts the default allowlist with user-configured allow and deny
+// lists. Deny always wins; a nil Policy keeps the defaults.
+type Policy = readonly.Policy
@@ -2,6 +2,7 @@ package readonly
 
 import (
 	"sort"
+	"strings"
 	"testing"
 )
 
@@ -457,3 +458,81 @@ func TestSubcommandRestrictions(t *testing.T) {
 		t.Error("expected 'status' in systemctl subcommands")
 	}
 }
+
+func TestPolicyValidateCommand(t *testing.T) {
+	p := &Policy{Allow: []string{"tcpdump"}, Deny: []string{"find", " tcpdump2 "}}
+
+	if err := ValidateCommand("tcpdump -i eth0 -c 10"); err == nil {
+		t.Fatal("expected tcpdump to be blocked by default")
+	}
+	if err := p.ValidateCommand("tcpdump -i eth0 -c 10"); err != nil {
+		t.Errorf("expected tcpdump to be allowed by the policy, got: %v", err)
+	}
+
+	// Denied built-in commands are refused directly, in pipelines and as xargs targets
+	for _, cmd := range []string{"find /etc -name '*.conf'", "ls | find .", "echo /tmp | xargs find"} {
+		if err := p.ValidateCommand(cmd); err == nil {
+			t.Errorf("expected %q to be denied by the policy", cmd)
+		}
+	}
+
+	// Other defaults are unaffected
+	if err := p.ValidateCommand("ls -la | grep conf"); err != nil {
+		t.Errorf("expected default commands to stay allowed: %v", err)
+	}
+
+	// Deny wins over allow
+	both := &Policy{Allow: []string{"tcpdump"}, Deny: []string{"tcpdump"}}
+	if err := both.ValidateCommand("tcpdump -i eth0"); err == nil {
+		t.Error("expected deny to win over allow")
+	}
+
+	// A nil policy is the default allowlist
+	var none *Policy
+	if err := none.ValidateCommand("find /etc"); err != nil {
+		t.Errorf("expected nil policy to allow find: %v", err)
+	}
+	if err := none.ValidateCommand("tcpdump"); err == nil {
+		t.Error("expected nil policy to block tcpdump")
+	}
+}
+
+func TestValidateCommand_NamesWriteOperator(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want string // substring of the error, "" if allowed
+	}{
+		{"grep root /etc/passwd", ""},
+		{"ls | wc -l", ""},
+		{"cat x | grep y", ""},
+		{"grep '>' /etc/hosts", ""},
+		{"cat x > y", `redirection ">"`},
+		{"cat /etc/passwd >> /tmp/x", `redirection ">>"`},
+		{"ls /nope 2> /tmp/err", `redirection "2>"`},
+		{"ls /nope 2>&1 | head", `redirection "2>&1"`},
+		{"ls &> /tmp/all", `redirection "&>"`},
+		{"echo hi >| /tmp/x", `redirection ">|"`},
+		{"ps aux | tee /tmp/ps", `"tee" writes its input to files`},
+		{"ls | xargs tee", `xargs command "tee"`},
+		{"ls | tee >(cat)", `process substitution ">("`},
+	}
+
+	for _, tc := range tests {
+		err := ValidateCommand(tc.cmd)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("ValidateCommand(%q) = %v, want allowed", tc.cmd, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ValidateCommand(%q) = %v, want error containing %s", tc.cmd, err, tc.want)
+		}
+	}
+
+	// tee can be allowed explicitly by a policy
+	p := &Policy{Allow: []string{"tee"}}
+	if err := p.ValidateCommand("ps aux | tee /tmp/ps"); err != nil {
+		t.Errorf("expected tee to be allowed by the policy: %v", err)
+	}
+}
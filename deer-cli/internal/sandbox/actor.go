@@ -0,0 +1,27 @@
+package sandbox
+
+import "context"
+
+// Actors name who initiated a sandbox command. The daemon records the actor
+// with each command so the history and audit log can tell a human's
+// commands from the agent's.
+const (
+	ActorHumanCLI  = "human-cli"
+	ActorTUIAgent  = "tui-agent"
+	ActorMCPClient = "mcp-client"
+	ActorPlaybook  = "playbook"
+)
+
+type actorKey struct{}
+
+// WithActor returns a context whose sandbox commands are attributed to
+// actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set on ctx by WithActor, or "" when none was.
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
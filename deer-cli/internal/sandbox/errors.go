@@ -0,0 +1,85 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Failures callers may want to tell apart. RemoteService wraps daemon
+// errors with these where the daemon's status code identifies them.
+var (
+	ErrSourceVMNotFound      = errors.New("source VM not found")
+	ErrInsufficientResources = errors.New("insufficient host resources")
+	ErrSSHTimeout            = errors.New("SSH connection to the sandbox timed out")
+)
+
+// ErrorCode classifies err as a stable, machine-readable code such as
+// "SOURCE_VM_NOT_FOUND" for JSON error output. Sentinel errors come first,
+// then the daemon's gRPC status code; anything else is "ERROR".
+func ErrorCode(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrSourceVMNotFound):
+		return "SOURCE_VM_NOT_FOUND"
+	case errors.Is(err, ErrInsufficientResources):
+		return "INSUFFICIENT_RESOURCES"
+	case errors.Is(err, ErrSSHTimeout):
+		return "SSH_TIMEOUT"
+	case errors.Is(err, ErrNoSandboxHost):
+		return "NO_SANDBOX_HOST"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "TIMEOUT"
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return "ERROR"
+	}
+	switch st.Code() {
+	case codes.NotFound:
+		return "NOT_FOUND"
+	case codes.AlreadyExists:
+		return "ALREADY_EXISTS"
+	case codes.InvalidArgument:
+		return "INVALID_ARGUMENT"
+	case codes.FailedPrecondition:
+		return "FAILED_PRECONDITION"
+	case codes.PermissionDenied, codes.Unauthenticated:
+		return "PERMISSION_DENIED"
+	case codes.ResourceExhausted:
+		return "INSUFFICIENT_RESOURCES"
+	case codes.DeadlineExceeded:
+		return "TIMEOUT"
+	case codes.Unavailable:
+		return "UNAVAILABLE"
+	case codes.Unimplemented:
+		return "UNSUPPORTED"
+	}
+	return "ERROR"
+}
+
+// createError wraps a daemon error from creating or planning a sandbox. A
+// create only reports NotFound when the source VM cannot be resolved.
+func createError(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", ErrSourceVMNotFound, status.Convert(err).Message())
+	case codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", ErrInsufficientResources, status.Convert(err).Message())
+	}
+	return err
+}
+
+// commandError wraps a daemon error from running a command in a sandbox.
+// DeadlineExceeded is only an SSH timeout when the caller's own deadline
+// has not passed.
+func commandError(ctx context.Context, err error) error {
+	if status.Code(err) == codes.DeadlineExceeded && ctx.Err() == nil {
+		return fmt.Errorf("%w: %s", ErrSSHTimeout, status.Convert(err).Message())
+	}
+	return err
+}
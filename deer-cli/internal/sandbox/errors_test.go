@@ -0,0 +1,45 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{createError(status.Error(codes.NotFound, `resolve source host: VM "web" not found`)), "SOURCE_VM_NOT_FOUND"},
+		{createError(status.Error(codes.ResourceExhausted, "not enough memory")), "INSUFFICIENT_RESOURCES"},
+		{commandError(context.Background(), status.Error(codes.DeadlineExceeded, "run command: Connection timed out")), "SSH_TIMEOUT"},
+		{fmt.Errorf("create sandbox: %w", ErrNoSandboxHost), "NO_SANDBOX_HOST"},
+		{fmt.Errorf("get sandbox: %w", status.Error(codes.NotFound, "sandbox not found")), "NOT_FOUND"},
+		{status.Error(codes.AlreadyExists, `a sandbox named "x" already exists`), "ALREADY_EXISTS"},
+		{status.Error(codes.Unavailable, "connection refused"), "UNAVAILABLE"},
+		{errors.New("boom"), "ERROR"},
+	}
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCommandError_CallerDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := commandError(ctx, status.Error(codes.DeadlineExceeded, "deadline exceeded"))
+	if errors.Is(err, ErrSSHTimeout) {
+		t.Error("the caller's own deadline was reported as an SSH timeout")
+	}
+	if got := ErrorCode(err); got != "TIMEOUT" {
+		t.Errorf("ErrorCode = %q, want TIMEOUT", got)
+	}
+}
@@ -0,0 +1,155 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Default bounds for UnifiedDiffs.
+const (
+	DefaultDiffMaxFiles     = 20
+	DefaultDiffMaxFileBytes = 256 * 1024
+)
+
+// FilePatch is the unified diff of one modified file. Skipped explains why
+// Patch is empty: the file is binary, too large, past the file limit, or
+// could not be read.
+type FilePatch struct {
+	Path    string `json:"path"`
+	Patch   string `json:"patch,omitempty"`
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// SnapshotFileReader reads file contents from snapshots and from the live
+// sandbox. RemoteService implements it.
+type SnapshotFileReader interface {
+	RestoreSnapshotFile(ctx context.Context, sandboxID, snapshot, path string, inPlace bool) ([]byte, error)
+	RunCommandWithOptions(ctx context.Context, sandboxID, command string, opts RunOptions) (*CommandResult, error)
+}
+
+// errFileTooLarge is returned by readFileAt for a live file over the size
+// limit, which is checked before the file is read.
+var errFileTooLarge = errors.New("file too large")
+
+// exitFileTooLarge is the exit status of readFileAt's command when the file
+// is over the limit.
+const exitFileTooLarge = 3
+
+// UnifiedDiffs renders d's modified files as unified diffs, reading each
+// from snapshot d.From and from d.To (the live sandbox when To is empty).
+// At most maxFiles files are diffed and files over maxFileBytes on either
+// side are skipped; zero picks the defaults.
+func UnifiedDiffs(ctx context.Context, r SnapshotFileReader, d *SnapshotDiff, maxFiles, maxFileBytes int) []FilePatch {
+	if maxFiles <= 0 {
+		maxFiles = DefaultDiffMaxFiles
+	}
+	if maxFileBytes <= 0 {
+		maxFileBytes = DefaultDiffMaxFileBytes
+	}
+	to := d.To
+	if to == "" {
+		to = "now"
+	}
+
+	patches := make([]FilePatch, 0, len(d.FilesModified))
+	for i, path := range d.FilesModified {
+		p := FilePatch{Path: path}
+		if i >= maxFiles {
+			p.Skipped = fmt.Sprintf("more than %d modified files", maxFiles)
+			patches = append(patches, p)
+			continue
+		}
+		before, err := r.RestoreSnapshotFile(ctx, d.SandboxID, d.From, path, false)
+		if err != nil {
+			p.Skipped = fmt.Sprintf("read from %s: %s", d.From, err)
+			patches = append(patches, p)
+			continue
+		}
+		after, err := readFileAt(ctx, r, d.SandboxID, d.To, path, maxFileBytes)
+		if errors.Is(err, errFileTooLarge) {
+			p.Skipped = fmt.Sprintf("larger than %d bytes", maxFileBytes)
+			patches = append(patches, p)
+			continue
+		}
+		if err != nil {
+			p.Skipped = fmt.Sprintf("read from %s: %s", to, err)
+			patches = append(patches, p)
+			continue
+		}
+		switch {
+		case len(before) > maxFileBytes || len(after) > maxFileBytes:
+			p.Skipped = fmt.Sprintf("larger than %d bytes", maxFileBytes)
+		case bytes.IndexByte(before, 0) >= 0 || bytes.IndexByte(after, 0) >= 0:
+			p.Skipped = "binary file"
+		default:
+			p.Patch, err = unifiedDiff(path, d.From, to, string(before), string(after))
+			if err != nil {
+				p.Skipped = err.Error()
+			}
+		}
+		patches = append(patches, p)
+	}
+	return patches
+}
+
+// readFileAt returns path from snapshot, or from the running sandbox when
+// snapshot is empty. A live file is read unrecorded, since it may hold
+// secrets, and only if it is at most limit bytes.
+func readFileAt(ctx context.Context, r SnapshotFileReader, sandboxID, snapshot, path string, limit int) ([]byte, error) {
+	if snapshot != "" {
+		return r.RestoreSnapshotFile(ctx, sandboxID, snapshot, path, false)
+	}
+	q := quoteShellArg(path)
+	command := fmt.Sprintf(`size=$(stat -c %%s -- %s) || exit 1; [ "$size" -le %d ] || exit %d; base64 -- %s`, q, limit, exitFileTooLarge, q)
+	res, err := r.RunCommandWithOptions(ctx, sandboxID, command, RunOptions{Unrecorded: true})
+	if err != nil {
+		return nil, err
+	}
+	switch res.ExitCode {
+	case 0:
+	case exitFileTooLarge:
+		return nil, errFileTooLarge
+	default:
+		return nil, fmt.Errorf("read exited %d: %s", res.ExitCode, strings.TrimSpace(res.Stderr))
+	}
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(res.Stdout), ""))
+}
+
+// unifiedDiff renders a git-style patch of path between two versions, with
+// the snapshot names in the file headers.
+func unifiedDiff(path, fromLabel, toLabel, before, after string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        patchLines(before),
+		B:        patchLines(after),
+		FromFile: "a" + path,
+		FromDate: fromLabel,
+		ToFile:   "b" + path,
+		ToDate:   toLabel,
+		Context:  3,
+	})
+}
+
+// patchLines splits s into newline-terminated lines. A missing final
+// newline is added so the last line prints on its own in the patch.
+func patchLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+	return lines
+}
+
+func quoteShellArg(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,111 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeFileReader serves snapshot files from files[snapshot][path] and the
+// live sandbox from live[path].
+type fakeFileReader struct {
+	files    map[string]map[string]string
+	live     map[string]string
+	commands []string
+}
+
+func (f *fakeFileReader) RestoreSnapshotFile(_ context.Context, _, snapshot, path string, _ bool) ([]byte, error) {
+	content, ok := f.files[snapshot][path]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return []byte(content), nil
+}
+
+func (f *fakeFileReader) RunCommandWithOptions(_ context.Context, _, command string, opts RunOptions) (*CommandResult, error) {
+	f.commands = append(f.commands, command)
+	if !opts.Unrecorded {
+		return nil, errors.New("live file read would be recorded")
+	}
+	for path, content := range f.live {
+		if strings.HasSuffix(command, "base64 -- '"+path+"'") {
+			if strings.Contains(command, fmt.Sprintf(`-le %d ]`, len(content)-1)) {
+				return &CommandResult{ExitCode: 3}, nil
+			}
+			return &CommandResult{Stdout: base64.StdEncoding.EncodeToString([]byte(content)) + "\n"}, nil
+		}
+	}
+	return &CommandResult{ExitCode: 1, Stderr: "No such file or directory"}, nil
+}
+
+func TestUnifiedDiffs(t *testing.T) {
+	r := &fakeFileReader{
+		files: map[string]map[string]string{
+			"before": {
+				"/etc/app.conf": "port=80\nworkers=2\n",
+				"/usr/bin/tool": "ELF\x00\x01",
+				"/etc/big":      strings.Repeat("a", 64),
+			},
+			"after": {
+				"/etc/app.conf": "port=8080\nworkers=2\n",
+				"/usr/bin/tool": "ELF\x00\x02",
+				"/etc/big":      "b",
+			},
+		},
+	}
+	d := &SnapshotDiff{
+		SandboxID:     "SBX-1",
+		From:          "before",
+		To:            "after",
+		FilesModified: []string{"/etc/app.conf", "/usr/bin/tool", "/etc/big", "/etc/gone", "/etc/extra"},
+	}
+
+	patches := UnifiedDiffs(context.Background(), r, d, 4, 32)
+	if len(patches) != 5 {
+		t.Fatalf("got %d patches, want 5", len(patches))
+	}
+	want := "--- a/etc/app.conf\tbefore\n+++ b/etc/app.conf\tafter\n@@ -1,2 +1,2 @@\n-port=80\n+port=8080\n workers=2\n"
+	if patches[0].Patch != want {
+		t.Errorf("patch =\n%s\nwant\n%s", patches[0].Patch, want)
+	}
+	for i, skipped := range []string{"binary file", "larger than 32 bytes", "read from before: not found", "more than 4 modified files"} {
+		if got := patches[i+1].Skipped; got != skipped {
+			t.Errorf("%s skipped = %q, want %q", patches[i+1].Path, got, skipped)
+		}
+	}
+}
+
+func TestUnifiedDiffs_Live(t *testing.T) {
+	r := &fakeFileReader{
+		files: map[string]map[string]string{"before": {"/etc/motd": "hello\n", "/etc/it's": "x\n"}},
+		live:  map[string]string{"/etc/motd": "hello\nworld\n"},
+	}
+	d := &SnapshotDiff{SandboxID: "SBX-1", From: "before", FilesModified: []string{"/etc/motd", "/etc/it's"}}
+
+	patches := UnifiedDiffs(context.Background(), r, d, 0, 0)
+	if !strings.Contains(patches[0].Patch, "+++ b/etc/motd\tnow\n") || !strings.Contains(patches[0].Patch, "+world\n") {
+		t.Errorf("live patch = %q", patches[0].Patch)
+	}
+	if want := `stat -c %s -- '/etc/it'\''s'`; !strings.Contains(r.commands[1], want) {
+		t.Errorf("command %q does not quote the path as %s", r.commands[1], want)
+	}
+	if !strings.HasPrefix(patches[1].Skipped, "read from now: read exited 1") {
+		t.Errorf("skipped = %q", patches[1].Skipped)
+	}
+}
+
+func TestUnifiedDiffs_LiveTooLarge(t *testing.T) {
+	r := &fakeFileReader{
+		files: map[string]map[string]string{"before": {"/var/log/app.log": "a\n"}},
+		live:  map[string]string{"/var/log/app.log": strings.Repeat("line\n", 10)},
+	}
+	d := &SnapshotDiff{SandboxID: "SBX-1", From: "before", FilesModified: []string{"/var/log/app.log"}}
+
+	patches := UnifiedDiffs(context.Background(), r, d, 0, 49)
+	if patches[0].Skipped != "larger than 49 bytes" {
+		t.Errorf("skipped = %q, want larger than 49 bytes", patches[0].Skipped)
+	}
+}
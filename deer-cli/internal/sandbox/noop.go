@@ -7,6 +7,9 @@ import (
 
 const noSandboxMsg = "no sandbox hosts configured, configure a sandbox host to create sandboxes, run commands, and edit files, daemon setup guide: https://deer.sh/docs/daemon"
 
+// ErrNoSandboxHost is returned by every NoopService operation.
+var ErrNoSandboxHost = errors.New(noSandboxMsg)
+
 // NoopService implements the Service interface but returns "not configured" for all operations.
 // Used when no sandbox hosts are configured, allowing the CLI to still function
 // with read-only source access.
@@ -18,75 +21,95 @@ func NewNoopService() *NoopService {
 }
 
 func (n *NoopService) CreateSandbox(ctx context.Context, req CreateRequest) (*SandboxInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) CreateSandboxStream(ctx context.Context, req CreateRequest, onProgress func(step string, stepNum, total int)) (*SandboxInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) GetSandbox(ctx context.Context, id string) (*SandboxInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) ListSandboxes(ctx context.Context) ([]*SandboxInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
+}
+
+func (n *NoopService) GetSandboxes(ctx context.Context, ids []string) ([]*SandboxInfo, []string, error) {
+	return nil, nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) DestroySandbox(ctx context.Context, id string) error {
-	return errors.New(noSandboxMsg)
+	return ErrNoSandboxHost
 }
 
 func (n *NoopService) StartSandbox(ctx context.Context, id string) (*SandboxInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) StopSandbox(ctx context.Context, id string, force bool) error {
-	return errors.New(noSandboxMsg)
+	return ErrNoSandboxHost
+}
+
+func (n *NoopService) ImportSandbox(ctx context.Context, vmName, name string) (*SandboxInfo, error) {
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) RunCommand(ctx context.Context, sandboxID, command string, timeoutSec int, env map[string]string) (*CommandResult, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
+}
+
+func (n *NoopService) RunCommandWithOptions(ctx context.Context, sandboxID, command string, opts RunOptions) (*CommandResult, error) {
+	return nil, ErrNoSandboxHost
+}
+
+func (n *NoopService) ListSandboxCommands(ctx context.Context, sandboxID string, limit int, actor string) ([]*CommandRecord, error) {
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) CreateSnapshot(ctx context.Context, sandboxID, name string) (*SnapshotInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) ListVMs(ctx context.Context) ([]*VMInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) ValidateSourceVM(ctx context.Context, vmName string) (*ValidationInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) PrepareSourceVM(ctx context.Context, vmName, sshUser, keyPath string) (*PrepareInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) RunSourceCommand(ctx context.Context, vmName, command string, timeoutSec int) (*SourceCommandResult, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) ReadSourceFile(ctx context.Context, vmName, path string) (string, error) {
-	return "", errors.New(noSandboxMsg)
+	return "", ErrNoSandboxHost
 }
 
 func (n *NoopService) GetHostInfo(ctx context.Context) (*HostInfo, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) Health(ctx context.Context) error {
-	return errors.New(noSandboxMsg)
+	return ErrNoSandboxHost
+}
+
+func (n *NoopService) GetStatus(ctx context.Context) (*DaemonStatus, error) {
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) DoctorCheck(ctx context.Context) ([]DoctorCheckResult, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) ScanSourceHostKeys(ctx context.Context) ([]ScanSourceHostKeysResult, error) {
-	return nil, errors.New(noSandboxMsg)
+	return nil, ErrNoSandboxHost
 }
 
 func (n *NoopService) Close() error {
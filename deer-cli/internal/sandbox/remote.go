@@ -4,16 +4,21 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/user"
 	"time"
 
 	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
 	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -25,6 +30,23 @@ type RemoteService struct {
 	client deerv1.DaemonServiceClient
 }
 
+// clientIdentityHeader carries clientIdentity to the daemon, which uses it to
+// name the holder when a sandbox is locked by another process.
+const clientIdentityHeader = "x-deer-client"
+
+// clientIdentity describes this process as user@host plus pid.
+func clientIdentity() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "localhost"
+	}
+	return fmt.Sprintf("%s@%s (deer pid %d)", name, host, os.Getpid())
+}
+
 // NewRemoteService dials the daemon gRPC endpoint and returns a Service.
 // It uses TLS configuration from the ControlPlaneConfig:
 //   - If DaemonCAFile is set, use it to verify the daemon's TLS cert
@@ -60,8 +82,15 @@ func NewRemoteService(addr string, cpCfg config.ControlPlaneConfig) (*RemoteServ
 		})
 	}
 
+	identity := clientIdentity()
 	conn, err := grpc.NewClient(addr,
 		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, clientIdentityHeader, identity), method, req, reply, cc, opts...)
+		}),
+		grpc.WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(metadata.AppendToOutgoingContext(ctx, clientIdentityHeader, identity), desc, cc, method, opts...)
+		}),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("dial daemon at %s: %w", addr, err)
@@ -94,7 +123,7 @@ func (r *RemoteService) CreateSandbox(ctx context.Context, req CreateRequest) (*
 		SimpleElasticsearchBroker: req.SimpleElasticsearchBroker,
 	})
 	if err != nil {
-		return nil, err
+		return nil, createError(err)
 	}
 	return &SandboxInfo{
 		ID:        resp.GetSandboxId(),
@@ -126,17 +155,23 @@ func (r *RemoteService) CreateSandboxStream(ctx context.Context, req CreateReque
 			}
 			return r.CreateSandbox(ctx, req)
 		}
-		return nil, err
+		return nil, createError(err)
 	}
 
 	for {
 		progress, err := stream.Recv()
 		if err != nil {
-			return nil, err
+			return nil, createError(err)
 		}
 
 		if progress.GetError() != "" {
-			return nil, fmt.Errorf("sandbox creation failed: %s", progress.GetError())
+			// The daemon ends the stream with a status saying why; keep its
+			// classification when it arrives.
+			failed := fmt.Errorf("sandbox creation failed: %s", progress.GetError())
+			if _, err := stream.Recv(); status.Code(err) == codes.NotFound || status.Code(err) == codes.ResourceExhausted {
+				return nil, fmt.Errorf("sandbox creation failed: %w", createError(err))
+			}
+			return nil, failed
 		}
 
 		if progress.GetDone() {
@@ -155,6 +190,42 @@ func (r *RemoteService) CreateSandboxStream(ctx context.Context, req CreateReque
 	}
 }
 
+// PlanSandbox asks the daemon what creating req would do - the sandbox ID,
+// source host, and resources - without creating anything.
+func (r *RemoteService) PlanSandbox(ctx context.Context, req CreateRequest) (*SandboxPlan, error) {
+	resp, err := r.client.PlanSandbox(ctx, &deerv1.CreateSandboxCommand{
+		BaseImage:                 req.SourceVM,
+		SourceVm:                  req.SourceVM,
+		Name:                      req.Name,
+		Vcpus:                     int32(req.VCPUs),
+		MemoryMb:                  int32(req.MemoryMB),
+		TtlSeconds:                int32(req.TTLSeconds),
+		AgentId:                   req.AgentID,
+		Network:                   req.Network,
+		Live:                      req.Live,
+		SimpleKafkaBroker:         req.SimpleKafkaBroker,
+		SimpleElasticsearchBroker: req.SimpleElasticsearchBroker,
+	})
+	if err != nil {
+		return nil, createError(err)
+	}
+	return &SandboxPlan{
+		SandboxID:         resp.GetSandboxId(),
+		Name:              resp.GetName(),
+		HostID:            resp.GetHostId(),
+		Hostname:          resp.GetHostname(),
+		SourceVM:          resp.GetSourceVm(),
+		SourceHost:        resp.GetSourceHost(),
+		SourceHostType:    resp.GetSourceHostType(),
+		VCPUs:             int(resp.GetVcpus()),
+		MemoryMB:          int(resp.GetMemoryMb()),
+		AvailableCPUs:     int(resp.GetAvailableCpus()),
+		AvailableMemoryMB: resp.GetAvailableMemoryMb(),
+		Warnings:          resp.GetWarnings(),
+		RequiresApproval:  resp.GetRequiresApproval(),
+	}, nil
+}
+
 func (r *RemoteService) GetSandbox(ctx context.Context, id string) (*SandboxInfo, error) {
 	resp, err := r.client.GetSandbox(ctx, &deerv1.GetSandboxRequest{SandboxId: id})
 	if err != nil {
@@ -175,11 +246,43 @@ func (r *RemoteService) ListSandboxes(ctx context.Context) ([]*SandboxInfo, erro
 	return result, nil
 }
 
+// GetSandboxes looks up ids in one daemon call. Found sandboxes come back in
+// the order of ids; the rest are listed in notFound.
+func (r *RemoteService) GetSandboxes(ctx context.Context, ids []string) ([]*SandboxInfo, []string, error) {
+	resp, err := r.client.GetSandboxes(ctx, &deerv1.GetSandboxesRequest{SandboxIds: ids})
+	if err != nil {
+		return nil, nil, err
+	}
+	result := make([]*SandboxInfo, 0, len(resp.GetSandboxes()))
+	for _, sb := range resp.GetSandboxes() {
+		result = append(result, protoToSandboxInfo(sb))
+	}
+	return result, resp.GetNotFound(), nil
+}
+
 func (r *RemoteService) DestroySandbox(ctx context.Context, id string) error {
 	_, err := r.client.DestroySandbox(ctx, &deerv1.DestroySandboxCommand{SandboxId: id})
 	return err
 }
 
+// DestroySandboxWithOptions destroys a sandbox, archiving its disk on the
+// daemon host first when opts.Archive is set. The sandbox is kept if the
+// archive fails. The returned Archive is nil when nothing was archived.
+func (r *RemoteService) DestroySandboxWithOptions(ctx context.Context, id string, opts DestroyOptions) (*Archive, error) {
+	resp, err := r.client.DestroySandbox(ctx, &deerv1.DestroySandboxCommand{
+		SandboxId:       id,
+		Archive:         opts.Archive,
+		ConfirmImported: opts.ConfirmImported,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetArchivePath() == "" {
+		return nil, nil
+	}
+	return &Archive{Path: resp.GetArchivePath(), Image: resp.GetArchiveImage()}, nil
+}
+
 func (r *RemoteService) StartSandbox(ctx context.Context, id string) (*SandboxInfo, error) {
 	resp, err := r.client.StartSandbox(ctx, &deerv1.StartSandboxCommand{SandboxId: id})
 	if err != nil {
@@ -197,15 +300,71 @@ func (r *RemoteService) StopSandbox(ctx context.Context, id string, force bool)
 	return err
 }
 
+func (r *RemoteService) ImportSandbox(ctx context.Context, vmName, name string) (*SandboxInfo, error) {
+	resp, err := r.client.ImportSandbox(ctx, &deerv1.ImportSandboxCommand{
+		VmName:  vmName,
+		Name:    name,
+		AgentId: "cli",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return protoToSandboxInfo(resp), nil
+}
+
+// ResizeSandbox changes a sandbox's vCPUs and memory; zero leaves a value
+// as it is. A running sandbox is restarted unless leaveStopped is set.
+func (r *RemoteService) ResizeSandbox(ctx context.Context, sandboxID string, vcpus, memoryMB int, leaveStopped bool) (*SandboxInfo, error) {
+	resp, err := r.client.ResizeSandbox(ctx, &deerv1.ResizeSandboxCommand{
+		SandboxId:    sandboxID,
+		Vcpus:        int32(vcpus),
+		MemoryMb:     int32(memoryMB),
+		LeaveStopped: leaveStopped,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return protoToSandboxInfo(resp), nil
+}
+
+// SetSandboxWorkdir sets the directory the sandbox's commands run in when
+// they do not name one. An empty workdir goes back to the login directory.
+func (r *RemoteService) SetSandboxWorkdir(ctx context.Context, sandboxID, workdir string) (*SandboxInfo, error) {
+	resp, err := r.client.SetSandboxWorkdir(ctx, &deerv1.SetSandboxWorkdirRequest{SandboxId: sandboxID, Workdir: workdir})
+	if err != nil {
+		return nil, err
+	}
+	return protoToSandboxInfo(resp), nil
+}
+
+// UnlockSandbox clears the sandbox's advisory lock whoever holds it and
+// returns the holder, or "" if it was not locked.
+func (r *RemoteService) UnlockSandbox(ctx context.Context, sandboxID string) (string, error) {
+	resp, err := r.client.UnlockSandbox(ctx, &deerv1.UnlockSandboxRequest{SandboxId: sandboxID})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetLockedBy(), nil
+}
+
 func (r *RemoteService) RunCommand(ctx context.Context, sandboxID, command string, timeoutSec int, env map[string]string) (*CommandResult, error) {
+	return r.RunCommandWithOptions(ctx, sandboxID, command, RunOptions{TimeoutSec: timeoutSec, Env: env})
+}
+
+func (r *RemoteService) RunCommandWithOptions(ctx context.Context, sandboxID, command string, opts RunOptions) (*CommandResult, error) {
 	resp, err := r.client.RunCommand(ctx, &deerv1.RunCommandCommand{
 		SandboxId:      sandboxID,
 		Command:        command,
-		TimeoutSeconds: int32(timeoutSec),
-		Env:            env,
+		TimeoutSeconds: int32(opts.TimeoutSec),
+		Env:            opts.Env,
+		ForwardAgent:   opts.ForwardAgent,
+		IdentityFiles:  opts.IdentityFiles,
+		Workdir:        opts.Workdir,
+		Actor:          ActorFrom(ctx),
+		Unrecorded:     opts.Unrecorded,
 	})
 	if err != nil {
-		return nil, err
+		return nil, commandError(ctx, err)
 	}
 	return &CommandResult{
 		SandboxID:  resp.GetSandboxId(),
@@ -216,6 +375,278 @@ func (r *RemoteService) RunCommand(ctx context.Context, sandboxID, command strin
 	}, nil
 }
 
+// OutputCallback receives a chunk of streaming command output.
+type OutputCallback func(chunk string, isStderr bool)
+
+// RunCommandStreaming runs a command like RunCommandWithOptions, passing
+// output to onOutput as the daemon streams it back.
+func (r *RemoteService) RunCommandStreaming(ctx context.Context, sandboxID, command string, opts RunOptions, onOutput OutputCallback) (*CommandResult, error) {
+	stream, err := r.client.StreamCommand(ctx, &deerv1.RunCommandCommand{
+		SandboxId:      sandboxID,
+		Command:        command,
+		TimeoutSeconds: int32(opts.TimeoutSec),
+		Env:            opts.Env,
+		ForwardAgent:   opts.ForwardAgent,
+		IdentityFiles:  opts.IdentityFiles,
+		Workdir:        opts.Workdir,
+		Actor:          ActorFrom(ctx),
+		Unrecorded:     opts.Unrecorded,
+	})
+	if err != nil {
+		return nil, commandError(ctx, err)
+	}
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("command stream ended without a result")
+		}
+		if err != nil {
+			return nil, commandError(ctx, err)
+		}
+		if resp := msg.GetResult(); resp != nil {
+			return &CommandResult{
+				SandboxID:  resp.GetSandboxId(),
+				Stdout:     resp.GetStdout(),
+				Stderr:     resp.GetStderr(),
+				ExitCode:   int(resp.GetExitCode()),
+				DurationMS: resp.GetDurationMs(),
+			}, nil
+		}
+		if onOutput != nil && len(msg.GetChunk()) > 0 {
+			onOutput(string(msg.GetChunk()), msg.GetIsStderr())
+		}
+	}
+}
+
+// ListOrphans asks the daemon for provider resources that no sandbox owns.
+func (r *RemoteService) ListOrphans(ctx context.Context) (*OrphanReport, error) {
+	resp, err := r.client.ListOrphans(ctx, &deerv1.ListOrphansRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return orphanReport(resp.GetOrphans(), resp.GetPoolSize(), resp.GetPoolFree()), nil
+}
+
+// ReclaimOrphans asks the daemon to delete the orphans with the given IDs.
+// The daemon skips any that a sandbox owns by now and reports it as an
+// error on its entry.
+func (r *RemoteService) ReclaimOrphans(ctx context.Context, ids []string) (*OrphanReport, error) {
+	resp, err := r.client.ReclaimOrphans(ctx, &deerv1.ReclaimOrphansRequest{Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	return orphanReport(resp.GetOrphans(), resp.GetPoolSize(), resp.GetPoolFree()), nil
+}
+
+// orphanReport converts the daemon's orphan entries and pool counts.
+func orphanReport(orphans []*deerv1.OrphanResource, poolSize, poolFree int32) *OrphanReport {
+	report := &OrphanReport{
+		Orphans:  make([]Orphan, 0, len(orphans)),
+		PoolSize: int(poolSize),
+		PoolFree: int(poolFree),
+	}
+	for _, o := range orphans {
+		report.Orphans = append(report.Orphans, Orphan{
+			ID:        o.GetId(),
+			Name:      o.GetName(),
+			State:     o.GetState(),
+			Reason:    o.GetReason(),
+			Reclaimed: o.GetReclaimed(),
+			Error:     o.GetError(),
+		})
+	}
+	return report
+}
+
+// RefreshIPs asks the daemon to rediscover and store the IPs of the given
+// sandboxes, or of every running sandbox when none are given.
+func (r *RemoteService) RefreshIPs(ctx context.Context, sandboxIDs ...string) ([]IPRefresh, error) {
+	resp, err := r.client.RefreshSandboxIPs(ctx, &deerv1.RefreshSandboxIPsRequest{SandboxIds: sandboxIDs})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]IPRefresh, 0, len(resp.GetResults()))
+	for _, res := range resp.GetResults() {
+		results = append(results, IPRefresh{
+			SandboxID:  res.GetSandboxId(),
+			Name:       res.GetName(),
+			IPAddress:  res.GetIpAddress(),
+			PreviousIP: res.GetPreviousIp(),
+			Error:      res.GetError(),
+		})
+	}
+	return results, nil
+}
+
+// SSHTarget asks the daemon for a sandbox's SSH destination, for interactive
+// sessions that cannot go through RunCommand.
+func (r *RemoteService) SSHTarget(ctx context.Context, sandboxID string) (*SSHTarget, error) {
+	resp, err := r.client.GetSandboxSSHTarget(ctx, &deerv1.GetSandboxSSHTargetRequest{SandboxId: sandboxID})
+	if err != nil {
+		return nil, err
+	}
+	return &SSHTarget{
+		SandboxID:       resp.GetSandboxId(),
+		IPAddress:       resp.GetIpAddress(),
+		Username:        resp.GetUsername(),
+		PrivateKeyPath:  resp.GetPrivateKeyPath(),
+		CertificatePath: resp.GetCertificatePath(),
+		HostKeyOptions:  resp.GetHostKeyOptions(),
+	}, nil
+}
+
+// RenewSSHCredentials asks the daemon to reissue the SSH key and
+// certificate it uses for a sandbox.
+func (r *RemoteService) RenewSSHCredentials(ctx context.Context, sandboxID string) (*SSHCredentials, error) {
+	resp, err := r.client.RenewSandboxSSHCredentials(ctx, &deerv1.RenewSandboxSSHCredentialsRequest{SandboxId: sandboxID})
+	if err != nil {
+		return nil, err
+	}
+	validUntil, _ := time.Parse(time.RFC3339, resp.GetValidUntil())
+	return &SSHCredentials{
+		SandboxID:       resp.GetSandboxId(),
+		Username:        resp.GetUsername(),
+		PrivateKeyPath:  resp.GetPrivateKeyPath(),
+		CertificatePath: resp.GetCertificatePath(),
+		ValidUntil:      validUntil,
+	}, nil
+}
+
+// GetSandboxStats asks the daemon what a sandbox is using right now.
+func (r *RemoteService) GetSandboxStats(ctx context.Context, sandboxID string) (*SandboxStats, error) {
+	resp, err := r.client.GetSandboxStats(ctx, &deerv1.GetSandboxStatsRequest{SandboxId: sandboxID})
+	if err != nil {
+		return nil, err
+	}
+	sampledAt, _ := time.Parse(time.RFC3339Nano, resp.GetSampledAt())
+	return &SandboxStats{
+		SandboxID:          resp.GetSandboxId(),
+		State:              resp.GetState(),
+		SampledAt:          sampledAt,
+		VCPUs:              int(resp.GetVcpus()),
+		CPUTimeMS:          resp.GetCpuTimeMs(),
+		CPUPercent:         resp.GetCpuPercent(),
+		MemoryBytes:        resp.GetMemoryBytes(),
+		MemoryLimitBytes:   resp.GetMemoryLimitBytes(),
+		DiskAllocatedBytes: resp.GetDiskAllocatedBytes(),
+		DiskCapacityBytes:  resp.GetDiskCapacityBytes(),
+	}, nil
+}
+
+// ListSandboxCommands returns the most recent limit commands run in a
+// sandbox (0 for all), oldest first. A non-empty actor keeps only the
+// commands that actor ran.
+func (r *RemoteService) ListSandboxCommands(ctx context.Context, sandboxID string, limit int, actor string) ([]*CommandRecord, error) {
+	resp, err := r.client.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{
+		SandboxId: sandboxID,
+		Limit:     int32(limit),
+		Actor:     actor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commandRecordsFromProto(resp.GetCommands()), nil
+}
+
+// ListSandboxCommandsAfter returns the commands recorded in a sandbox after
+// the command afterID, oldest first, up to limit (0 for all). It pages
+// forward through history for --follow.
+func (r *RemoteService) ListSandboxCommandsAfter(ctx context.Context, sandboxID, afterID string, limit int, actor string) ([]*CommandRecord, error) {
+	resp, err := r.client.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{
+		SandboxId: sandboxID,
+		Limit:     int32(limit),
+		Actor:     actor,
+		AfterId:   afterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commandRecordsFromProto(resp.GetCommands()), nil
+}
+
+func commandRecordsFromProto(cmds []*deerv1.CommandRecord) []*CommandRecord {
+	result := make([]*CommandRecord, 0, len(cmds))
+	for _, c := range cmds {
+		startedAt, _ := time.Parse(time.RFC3339, c.GetStartedAt())
+		endedAt, _ := time.Parse(time.RFC3339, c.GetEndedAt())
+		result = append(result, &CommandRecord{
+			ID:         c.GetId(),
+			Command:    c.GetCommand(),
+			Actor:      c.GetActor(),
+			Workdir:    c.GetWorkdir(),
+			Stdout:     c.GetStdout(),
+			Stderr:     c.GetStderr(),
+			ExitCode:   int(c.GetExitCode()),
+			DurationMS: c.GetDurationMs(),
+			StartedAt:  startedAt,
+			EndedAt:    endedAt,
+		})
+	}
+	return result
+}
+
+// DiffSnapshots compares the files recorded at snapshot from with snapshot
+// to, or with the sandbox's current filesystem when to is empty. onProgress
+// receives each step while the daemon works.
+func (r *RemoteService) DiffSnapshots(ctx context.Context, sandboxID, from, to string, onProgress func(step string)) (*SnapshotDiff, error) {
+	stream, err := r.client.DiffSnapshots(ctx, &deerv1.DiffSnapshotsRequest{
+		SandboxId:    sandboxID,
+		FromSnapshot: from,
+		ToSnapshot:   to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if d := msg.GetDiff(); d != nil {
+			return &SnapshotDiff{
+				SandboxID:       sandboxID,
+				From:            from,
+				To:              to,
+				FilesAdded:      d.GetFilesAdded(),
+				FilesModified:   d.GetFilesModified(),
+				FilesRemoved:    d.GetFilesRemoved(),
+				PackagesAdded:   packagesFromProto(d.GetPackagesAdded()),
+				PackagesRemoved: packagesFromProto(d.GetPackagesRemoved()),
+				CommandsRun:     commandRecordsFromProto(d.GetCommandsRun()),
+			}, nil
+		}
+		if onProgress != nil {
+			onProgress(msg.GetStep())
+		}
+	}
+}
+
+// RestoreSnapshotFile returns path as it was in snapshot. With inPlace the
+// daemon writes it back into the running sandbox and no content is returned.
+func (r *RemoteService) RestoreSnapshotFile(ctx context.Context, sandboxID, snapshot, path string, inPlace bool) ([]byte, error) {
+	resp, err := r.client.RestoreSnapshotFile(ctx, &deerv1.RestoreSnapshotFileRequest{
+		SandboxId: sandboxID,
+		Snapshot:  snapshot,
+		Path:      path,
+		InPlace:   inPlace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetContent(), nil
+}
+
+func packagesFromProto(pkgs []*deerv1.SnapshotPackage) []store.PackageInfo {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	out := make([]store.PackageInfo, 0, len(pkgs))
+	for _, p := range pkgs {
+		out = append(out, store.PackageInfo{Name: p.GetName(), Version: p.GetVersion()})
+	}
+	return out
+}
+
 func (r *RemoteService) CreateSnapshot(ctx context.Context, sandboxID, name string) (*SnapshotInfo, error) {
 	resp, err := r.client.CreateSnapshot(ctx, &deerv1.SnapshotCommand{
 		SandboxId:    sandboxID,
@@ -231,6 +662,64 @@ func (r *RemoteService) CreateSnapshot(ctx context.Context, sandboxID, name stri
 	}, nil
 }
 
+// ForkSandbox creates a new sandbox from a snapshot of an existing one.
+func (r *RemoteService) ForkSandbox(ctx context.Context, req ForkRequest) (*SandboxInfo, error) {
+	resp, err := r.client.ForkSandbox(ctx, &deerv1.ForkSandboxCommand{
+		SandboxId: req.SandboxID,
+		Snapshot:  req.Snapshot,
+		Name:      req.Name,
+		Vcpus:     int32(req.VCPUs),
+		MemoryMb:  int32(req.MemoryMB),
+		AgentId:   req.AgentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SandboxInfo{
+		ID:        resp.GetSandboxId(),
+		Name:      resp.GetName(),
+		State:     resp.GetState(),
+		IPAddress: resp.GetIpAddress(),
+		BaseImage: req.SandboxID,
+	}, nil
+}
+
+// ListSnapshots returns a sandbox's recorded snapshots, oldest first.
+func (r *RemoteService) ListSnapshots(ctx context.Context, sandboxID string) ([]*SnapshotInfo, error) {
+	resp, err := r.client.ListSnapshots(ctx, &deerv1.ListSnapshotsRequest{SandboxId: sandboxID})
+	if err != nil {
+		return nil, err
+	}
+	snaps := make([]*SnapshotInfo, 0, len(resp.GetSnapshots()))
+	for _, pb := range resp.GetSnapshots() {
+		createdAt, _ := time.Parse(time.RFC3339, pb.GetCreatedAt())
+		snaps = append(snaps, &SnapshotInfo{
+			SnapshotID:   pb.GetId(),
+			SnapshotName: pb.GetName(),
+			SandboxID:    sandboxID,
+			Kind:         pb.GetKind(),
+			Ref:          pb.GetRef(),
+			CreatedAt:    createdAt,
+		})
+	}
+	return snaps, nil
+}
+
+// DeleteSnapshot removes a snapshot, named by ID or name, from the backend
+// and the daemon's records.
+func (r *RemoteService) DeleteSnapshot(ctx context.Context, sandboxID, snapshot string) (*SnapshotDeletion, error) {
+	resp, err := r.client.DeleteSnapshot(ctx, &deerv1.DeleteSnapshotRequest{SandboxId: sandboxID, Snapshot: snapshot})
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotDeletion{
+		SandboxID:      resp.GetSandboxId(),
+		Snapshot:       resp.GetSnapshot(),
+		RecordDeleted:  resp.GetRecordDeleted(),
+		BackendDeleted: resp.GetBackendDeleted(),
+	}, nil
+}
+
 func (r *RemoteService) ListVMs(ctx context.Context) ([]*VMInfo, error) {
 	resp, err := r.client.ListSourceVMs(ctx, &deerv1.ListSourceVMsCommand{})
 	if err != nil {
@@ -243,6 +732,7 @@ func (r *RemoteService) ListVMs(ctx context.Context) ([]*VMInfo, error) {
 			State:     vm.GetState(),
 			IPAddress: vm.GetIpAddress(),
 			Prepared:  vm.GetPrepared(),
+			Host:      vm.GetHost(),
 		})
 	}
 	return result, nil
@@ -264,9 +754,25 @@ func (r *RemoteService) ValidateSourceVM(ctx context.Context, vmName string) (*V
 		HasNetwork: resp.GetHasNetwork(),
 		Warnings:   resp.GetWarnings(),
 		Errors:     resp.GetErrors(),
+		Issues:     validationIssuesFromProto(resp.GetIssues()),
 	}, nil
 }
 
+func validationIssuesFromProto(issues []*deerv1.ValidationIssue) []ValidationIssue {
+	if len(issues) == 0 {
+		return nil
+	}
+	out := make([]ValidationIssue, 0, len(issues))
+	for _, is := range issues {
+		out = append(out, ValidationIssue{
+			Code:     is.GetCode(),
+			Severity: is.GetSeverity(),
+			Detail:   is.GetDetail(),
+		})
+	}
+	return out
+}
+
 func (r *RemoteService) PrepareSourceVM(ctx context.Context, vmName, sshUser, keyPath string) (*PrepareInfo, error) {
 	resp, err := r.client.PrepareSourceVM(ctx, &deerv1.PrepareSourceVMCommand{
 		SourceVm:   vmName,
@@ -350,6 +856,33 @@ func (r *RemoteService) Health(ctx context.Context) error {
 	return err
 }
 
+func (r *RemoteService) GetStatus(ctx context.Context) (*DaemonStatus, error) {
+	resp, err := r.client.GetStatus(ctx, &deerv1.GetStatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var recentErrors []DaemonError
+	for _, e := range resp.GetRecentErrors() {
+		recentErrors = append(recentErrors, DaemonError{
+			Time:    e.GetTime(),
+			Source:  e.GetSource(),
+			Message: e.GetMessage(),
+		})
+	}
+
+	return &DaemonStatus{
+		HostID:          resp.GetHostId(),
+		Hostname:        resp.GetHostname(),
+		Version:         resp.GetVersion(),
+		Provider:        resp.GetProvider(),
+		ActiveSandboxes: int(resp.GetActiveSandboxes()),
+		StartedAt:       resp.GetStartedAt(),
+		UptimeSeconds:   resp.GetUptimeSeconds(),
+		JanitorLastRun:  resp.GetJanitorLastRun(),
+		RecentErrors:    recentErrors,
+	}, nil
+}
+
 func (r *RemoteService) DoctorCheck(ctx context.Context) ([]DoctorCheckResult, error) {
 	resp, err := r.client.DoctorCheck(ctx, &deerv1.DoctorCheckRequest{})
 	if err != nil {
@@ -390,15 +923,55 @@ func protoToSandboxInfo(pb *deerv1.SandboxInfo) *SandboxInfo {
 	if pb.GetCreatedAt() != "" {
 		createdAt, _ = time.Parse(time.RFC3339, pb.GetCreatedAt())
 	}
-	return &SandboxInfo{
-		ID:        pb.GetSandboxId(),
-		Name:      pb.GetName(),
-		State:     pb.GetState(),
-		IPAddress: pb.GetIpAddress(),
-		BaseImage: pb.GetBaseImage(),
-		AgentID:   pb.GetAgentId(),
-		VCPUs:     int(pb.GetVcpus()),
-		MemoryMB:  int(pb.GetMemoryMb()),
-		CreatedAt: createdAt,
+	info := &SandboxInfo{
+		ID:         pb.GetSandboxId(),
+		Name:       pb.GetName(),
+		State:      pb.GetState(),
+		IPAddress:  pb.GetIpAddress(),
+		MACAddress: pb.GetMacAddress(),
+		HostID:     pb.GetHostId(),
+		BaseImage:  pb.GetBaseImage(),
+		AgentID:    pb.GetAgentId(),
+		VCPUs:      int(pb.GetVcpus()),
+		MemoryMB:   int(pb.GetMemoryMb()),
+		CreatedAt:  createdAt,
+		Imported:   pb.GetImported(),
+		Activity:   activityFromProto(pb.GetActivity()),
+		Disk:       diskFromProto(pb.GetDisk()),
+		Workdir:    pb.GetWorkdir(),
+	}
+	if t, err := time.Parse(time.RFC3339, pb.GetIdleStopAt()); err == nil {
+		info.IdleStopAt = &t
+	}
+	return info
+}
+
+func diskFromProto(pb *deerv1.SandboxDiskUsage) *DiskUsage {
+	if pb == nil {
+		return nil
+	}
+	d := &DiskUsage{
+		TotalBytes:     pb.GetTotalBytes(),
+		UsedBytes:      pb.GetUsedBytes(),
+		AvailableBytes: pb.GetAvailableBytes(),
+	}
+	if pb.GetCheckedAt() != "" {
+		d.CheckedAt, _ = time.Parse(time.RFC3339, pb.GetCheckedAt())
+	}
+	return d
+}
+
+func activityFromProto(pb *deerv1.SandboxActivity) *SandboxActivity {
+	if pb == nil {
+		return nil
+	}
+	a := &SandboxActivity{
+		CommandCount:   int(pb.GetCommandCount()),
+		FailedCommands: int(pb.GetFailedCommands()),
+		TotalRuntimeMS: pb.GetTotalRuntimeMs(),
+	}
+	if pb.GetLastActivityAt() != "" {
+		a.LastActivityAt, _ = time.Parse(time.RFC3339, pb.GetLastActivityAt())
 	}
+	return a
 }
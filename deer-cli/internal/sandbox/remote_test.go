@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"testing"
+	"time"
 
 	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
 	"google.golang.org/grpc"
@@ -19,6 +20,12 @@ type mockDaemonClient struct {
 	createSandboxErr  error
 	createStream      grpc.ServerStreamingClient[deerv1.SandboxProgress]
 	createStreamErr   error
+	statusResp        *deerv1.DaemonStatusResponse
+	validateResp      *deerv1.SourceVMValidation
+	runCommandReq     *deerv1.RunCommandCommand
+	commandOutput     []*deerv1.CommandOutput
+	commands          []*deerv1.CommandRecord
+	listCommandsReq   *deerv1.ListSandboxCommandsRequest
 }
 
 func (m *mockDaemonClient) ListSourceVMs(_ context.Context, _ *deerv1.ListSourceVMsCommand, _ ...grpc.CallOption) (*deerv1.SourceVMsList, error) {
@@ -41,6 +48,10 @@ func (m *mockDaemonClient) GetSandbox(context.Context, *deerv1.GetSandboxRequest
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
 
+func (m *mockDaemonClient) GetSandboxes(context.Context, *deerv1.GetSandboxesRequest, ...grpc.CallOption) (*deerv1.GetSandboxesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
 func (m *mockDaemonClient) ListSandboxes(context.Context, *deerv1.ListSandboxesRequest, ...grpc.CallOption) (*deerv1.ListSandboxesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
@@ -57,7 +68,89 @@ func (m *mockDaemonClient) StopSandbox(context.Context, *deerv1.StopSandboxComma
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
 
-func (m *mockDaemonClient) RunCommand(context.Context, *deerv1.RunCommandCommand, ...grpc.CallOption) (*deerv1.CommandResult, error) {
+func (m *mockDaemonClient) ImportSandbox(context.Context, *deerv1.ImportSandboxCommand, ...grpc.CallOption) (*deerv1.SandboxInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) ListSandboxCommands(_ context.Context, req *deerv1.ListSandboxCommandsRequest, _ ...grpc.CallOption) (*deerv1.ListSandboxCommandsResponse, error) {
+	m.listCommandsReq = req
+	return &deerv1.ListSandboxCommandsResponse{Commands: m.commands}, nil
+}
+
+func (m *mockDaemonClient) RunCommand(_ context.Context, req *deerv1.RunCommandCommand, _ ...grpc.CallOption) (*deerv1.CommandResult, error) {
+	m.runCommandReq = req
+	return &deerv1.CommandResult{SandboxId: req.GetSandboxId()}, nil
+}
+
+func (m *mockDaemonClient) StreamCommand(_ context.Context, req *deerv1.RunCommandCommand, _ ...grpc.CallOption) (grpc.ServerStreamingClient[deerv1.CommandOutput], error) {
+	if m.commandOutput == nil {
+		return nil, status.Error(codes.Unimplemented, "not implemented")
+	}
+	m.runCommandReq = req
+	return &fakeCommandOutputStream{msgs: m.commandOutput}, nil
+}
+
+func (m *mockDaemonClient) ListOrphans(context.Context, *deerv1.ListOrphansRequest, ...grpc.CallOption) (*deerv1.ListOrphansResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) ReclaimOrphans(context.Context, *deerv1.ReclaimOrphansRequest, ...grpc.CallOption) (*deerv1.ReclaimOrphansResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) PlanSandbox(context.Context, *deerv1.CreateSandboxCommand, ...grpc.CallOption) (*deerv1.SandboxPlan, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) ResizeSandbox(context.Context, *deerv1.ResizeSandboxCommand, ...grpc.CallOption) (*deerv1.SandboxInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) SetSandboxWorkdir(context.Context, *deerv1.SetSandboxWorkdirRequest, ...grpc.CallOption) (*deerv1.SandboxInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) UnlockSandbox(context.Context, *deerv1.UnlockSandboxRequest, ...grpc.CallOption) (*deerv1.SandboxUnlocked, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) GetSandboxStats(context.Context, *deerv1.GetSandboxStatsRequest, ...grpc.CallOption) (*deerv1.SandboxStats, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) ForkSandbox(context.Context, *deerv1.ForkSandboxCommand, ...grpc.CallOption) (*deerv1.SandboxCreated, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) ListSnapshots(context.Context, *deerv1.ListSnapshotsRequest, ...grpc.CallOption) (*deerv1.ListSnapshotsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) DeleteSnapshot(context.Context, *deerv1.DeleteSnapshotRequest, ...grpc.CallOption) (*deerv1.SnapshotDeleted, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) RefreshSandboxIPs(context.Context, *deerv1.RefreshSandboxIPsRequest, ...grpc.CallOption) (*deerv1.RefreshSandboxIPsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) DiffSnapshots(context.Context, *deerv1.DiffSnapshotsRequest, ...grpc.CallOption) (grpc.ServerStreamingClient[deerv1.DiffSnapshotsProgress], error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) RestoreSnapshotFile(context.Context, *deerv1.RestoreSnapshotFileRequest, ...grpc.CallOption) (*deerv1.SnapshotFileResult, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) GetSandboxSSHTarget(context.Context, *deerv1.GetSandboxSSHTargetRequest, ...grpc.CallOption) (*deerv1.SandboxSSHTarget, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) RenewSandboxSSHCredentials(context.Context, *deerv1.RenewSandboxSSHCredentialsRequest, ...grpc.CallOption) (*deerv1.SandboxSSHCredentials, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (m *mockDaemonClient) RunCommandBatch(context.Context, *deerv1.RunCommandBatchCommand, ...grpc.CallOption) (*deerv1.RunCommandBatchResult, error) {
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
 
@@ -66,6 +159,9 @@ func (m *mockDaemonClient) CreateSnapshot(context.Context, *deerv1.SnapshotComma
 }
 
 func (m *mockDaemonClient) ValidateSourceVM(context.Context, *deerv1.ValidateSourceVMCommand, ...grpc.CallOption) (*deerv1.SourceVMValidation, error) {
+	if m.validateResp != nil {
+		return m.validateResp, nil
+	}
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
 
@@ -89,6 +185,13 @@ func (m *mockDaemonClient) Health(context.Context, *deerv1.HealthRequest, ...grp
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
 
+func (m *mockDaemonClient) GetStatus(context.Context, *deerv1.GetStatusRequest, ...grpc.CallOption) (*deerv1.DaemonStatusResponse, error) {
+	if m.statusResp != nil {
+		return m.statusResp, nil
+	}
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
 func (m *mockDaemonClient) DiscoverHosts(context.Context, *deerv1.DiscoverHostsCommand, ...grpc.CallOption) (*deerv1.DiscoverHostsResult, error) {
 	return nil, status.Error(codes.Unimplemented, "not implemented")
 }
@@ -152,6 +255,20 @@ func (f *fakeSandboxProgressStream) Recv() (*deerv1.SandboxProgress, error) {
 	return msg, nil
 }
 
+type fakeCommandOutputStream struct {
+	fakeSandboxProgressStream
+	msgs []*deerv1.CommandOutput
+}
+
+func (f *fakeCommandOutputStream) Recv() (*deerv1.CommandOutput, error) {
+	if f.idx >= len(f.msgs) {
+		return nil, io.EOF
+	}
+	msg := f.msgs[f.idx]
+	f.idx++
+	return msg, nil
+}
+
 func (m *mockDaemonClient) ScanSourceHostKeys(_ context.Context, _ *deerv1.ScanSourceHostKeysRequest, _ ...grpc.CallOption) (*deerv1.ScanSourceHostKeysResponse, error) {
 	return &deerv1.ScanSourceHostKeysResponse{
 		Results: []*deerv1.ScanSourceHostKeysResult{
@@ -203,6 +320,142 @@ func TestScanSourceHostKeys_DelegatesToDaemon(t *testing.T) {
 	}
 }
 
+func TestGetStatus_DelegatesToDaemon(t *testing.T) {
+	mock := &mockDaemonClient{
+		statusResp: &deerv1.DaemonStatusResponse{
+			HostId:          "host-1",
+			Version:         "0.1.0",
+			Provider:        "microvm",
+			ActiveSandboxes: 3,
+			UptimeSeconds:   120,
+			JanitorLastRun:  "2026-01-01T00:00:00Z",
+			RecentErrors: []*deerv1.DaemonError{
+				{Time: "2026-01-01T00:00:00Z", Source: "janitor", Message: "boom"},
+			},
+		},
+	}
+	svc := &RemoteService{client: mock}
+
+	st, err := svc.GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus: %v", err)
+	}
+	if st.HostID != "host-1" || st.Provider != "microvm" || st.ActiveSandboxes != 3 {
+		t.Errorf("unexpected status: %+v", st)
+	}
+	if st.JanitorLastRun != "2026-01-01T00:00:00Z" {
+		t.Errorf("got janitor last run %q", st.JanitorLastRun)
+	}
+	if len(st.RecentErrors) != 1 || st.RecentErrors[0].Source != "janitor" {
+		t.Errorf("unexpected recent errors: %+v", st.RecentErrors)
+	}
+}
+
+func TestRunCommandWithOptions_SendsSSHOptions(t *testing.T) {
+	mock := &mockDaemonClient{}
+	svc := &RemoteService{client: mock}
+
+	_, err := svc.RunCommandWithOptions(context.Background(), "sbx-1", "git clone git@github.com:org/repo.git", RunOptions{
+		TimeoutSec:    60,
+		ForwardAgent:  true,
+		IdentityFiles: []string{"/etc/deer/keys/deploy"},
+	})
+	if err != nil {
+		t.Fatalf("RunCommandWithOptions: %v", err)
+	}
+	req := mock.runCommandReq
+	if !req.GetForwardAgent() || len(req.GetIdentityFiles()) != 1 || req.GetTimeoutSeconds() != 60 {
+		t.Errorf("unexpected request: %+v", req)
+	}
+
+	if _, err := svc.RunCommand(context.Background(), "sbx-1", "ls", 0, nil); err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if mock.runCommandReq.GetForwardAgent() {
+		t.Error("plain RunCommand must not forward the agent")
+	}
+}
+
+func TestRunCommand_SendsActorFromContext(t *testing.T) {
+	mock := &mockDaemonClient{}
+	svc := &RemoteService{client: mock}
+
+	ctx := WithActor(context.Background(), ActorMCPClient)
+	if _, err := svc.RunCommand(ctx, "sbx-1", "ls", 0, nil); err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if got := mock.runCommandReq.GetActor(); got != ActorMCPClient {
+		t.Errorf("actor = %q, want %q", got, ActorMCPClient)
+	}
+
+	if _, err := svc.RunCommand(context.Background(), "sbx-1", "ls", 0, nil); err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if got := mock.runCommandReq.GetActor(); got != "" {
+		t.Errorf("actor without WithActor = %q, want empty", got)
+	}
+}
+
+func TestRunCommandStreaming_ForwardsChunks(t *testing.T) {
+	mock := &mockDaemonClient{commandOutput: []*deerv1.CommandOutput{
+		{Chunk: []byte("building\n")},
+		{Chunk: []byte("warning\n"), IsStderr: true},
+		{Result: &deerv1.CommandResult{SandboxId: "sbx-1", Stdout: "building\n", Stderr: "warning\n", ExitCode: 2}},
+	}}
+	svc := &RemoteService{client: mock}
+
+	var chunks []string
+	result, err := svc.RunCommandStreaming(context.Background(), "sbx-1", "make", RunOptions{TimeoutSec: 30}, func(chunk string, isStderr bool) {
+		if isStderr {
+			chunk = "err:" + chunk
+		}
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("RunCommandStreaming: %v", err)
+	}
+	if len(chunks) != 2 || chunks[0] != "building\n" || chunks[1] != "err:warning\n" {
+		t.Errorf("chunks = %q", chunks)
+	}
+	if result.ExitCode != 2 || result.Stdout != "building\n" {
+		t.Errorf("result = %+v", result)
+	}
+	if mock.runCommandReq.GetTimeoutSeconds() != 30 {
+		t.Errorf("timeout = %d, want 30", mock.runCommandReq.GetTimeoutSeconds())
+	}
+
+	mock.commandOutput = []*deerv1.CommandOutput{{Chunk: []byte("partial")}}
+	if _, err := svc.RunCommandStreaming(context.Background(), "sbx-1", "make", RunOptions{}, nil); err == nil {
+		t.Error("expected an error when the stream ends without a result")
+	}
+}
+
+func TestValidateSourceVM_MapsIssues(t *testing.T) {
+	mock := &mockDaemonClient{
+		validateResp: &deerv1.SourceVMValidation{
+			SourceVm: "ubuntu-base",
+			Valid:    false,
+			Errors:   []string{"VM is not running"},
+			Issues: []*deerv1.ValidationIssue{
+				{Code: "VM_NOT_RUNNING", Severity: "error", Detail: "VM is not running"},
+			},
+		},
+	}
+	svc := &RemoteService{client: mock}
+
+	info, err := svc.ValidateSourceVM(context.Background(), "ubuntu-base")
+	if err != nil {
+		t.Fatalf("ValidateSourceVM: %v", err)
+	}
+	if len(info.Issues) != 1 {
+		t.Fatalf("got %d issues, want 1", len(info.Issues))
+	}
+	want := ValidationIssue{Code: "VM_NOT_RUNNING", Severity: "error", Detail: "VM is not running"}
+	if info.Issues[0] != want {
+		t.Errorf("got issue %+v, want %+v", info.Issues[0], want)
+	}
+}
+
 func TestCreateSandboxStream_DelegatesProgressToCallback(t *testing.T) {
 	mock := &mockDaemonClient{
 		createStream: &fakeSandboxProgressStream{
@@ -280,3 +533,63 @@ func TestCreateSandboxStream_FallsBackToUnaryWithSyntheticProgress(t *testing.T)
 		t.Fatalf("synthetic progress = %v, want [Creating sandbox 1 9]", progress[0])
 	}
 }
+
+func TestListSandboxCommands_MapsRecords(t *testing.T) {
+	mock := &mockDaemonClient{commands: []*deerv1.CommandRecord{{
+		Id:         "CMD-1",
+		Command:    "uname -a",
+		Actor:      "tui-agent",
+		Stdout:     "Linux",
+		ExitCode:   0,
+		DurationMs: 12,
+		StartedAt:  "2026-03-01T12:00:00Z",
+		EndedAt:    "2026-03-01T12:00:01Z",
+	}}}
+	svc := &RemoteService{client: mock}
+
+	cmds, err := svc.ListSandboxCommands(context.Background(), "sbx-1", 5, "tui-agent")
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if mock.listCommandsReq.GetSandboxId() != "sbx-1" || mock.listCommandsReq.GetLimit() != 5 || mock.listCommandsReq.GetActor() != "tui-agent" {
+		t.Errorf("unexpected request: %+v", mock.listCommandsReq)
+	}
+	if len(cmds) != 1 || cmds[0].Command != "uname -a" || cmds[0].Actor != "tui-agent" || cmds[0].DurationMS != 12 {
+		t.Fatalf("unexpected records: %+v", cmds)
+	}
+	if cmds[0].EndedAt.Sub(cmds[0].StartedAt) != time.Second {
+		t.Errorf("timestamps not parsed: %v - %v", cmds[0].StartedAt, cmds[0].EndedAt)
+	}
+}
+
+func TestProtoToSandboxInfo_Activity(t *testing.T) {
+	info := protoToSandboxInfo(&deerv1.SandboxInfo{
+		SandboxId: "SBX-1",
+		Activity: &deerv1.SandboxActivity{
+			LastActivityAt: "2026-03-01T12:05:00Z",
+			CommandCount:   4,
+			FailedCommands: 1,
+			TotalRuntimeMs: 1500,
+		},
+	})
+	a := info.Activity
+	if a == nil || a.CommandCount != 4 || a.FailedCommands != 1 || a.TotalRuntimeMS != 1500 {
+		t.Fatalf("activity = %+v", a)
+	}
+	if a.LastActivityAt.IsZero() || a.FailureRate() != 0.25 {
+		t.Errorf("last = %v, failure rate = %v", a.LastActivityAt, a.FailureRate())
+	}
+	if protoToSandboxInfo(&deerv1.SandboxInfo{SandboxId: "SBX-2"}).Activity != nil {
+		t.Error("expected nil activity when the daemon sends none")
+	}
+}
+
+func TestProtoToSandboxInfo_IdleStopAt(t *testing.T) {
+	info := protoToSandboxInfo(&deerv1.SandboxInfo{SandboxId: "SBX-1", IdleStopAt: "2026-03-01T12:35:00Z"})
+	if info.IdleStopAt == nil || !info.IdleStopAt.Equal(time.Date(2026, 3, 1, 12, 35, 0, 0, time.UTC)) {
+		t.Errorf("IdleStopAt = %v", info.IdleStopAt)
+	}
+	if protoToSandboxInfo(&deerv1.SandboxInfo{SandboxId: "SBX-2"}).IdleStopAt != nil {
+		t.Error("expected nil IdleStopAt when idle stops are off")
+	}
+}
@@ -0,0 +1,44 @@
+package sandbox
+
+import (
+	"fmt"
+	"math"
+)
+
+// ResourceCheck is whether a planned sandbox fits in the host's free
+// memory, allowing for overcommit.
+type ResourceCheck struct {
+	RequiredMemoryMB  int   `json:"required_memory_mb"`
+	AvailableMemoryMB int64 `json:"available_memory_mb"`
+	// AllowedMemoryMB is AvailableMemoryMB scaled by the overcommit ratio:
+	// the most a sandbox may ask for without approval.
+	AllowedMemoryMB int64    `json:"allowed_memory_mb"`
+	NeedsApproval   bool     `json:"needs_approval"`
+	Warnings        []string `json:"warnings,omitempty"`
+}
+
+// CheckResourcesForSandbox compares plan's memory against the host's free
+// memory times overcommitRatio. Requests within that are approved
+// automatically, with a warning when they overcommit at all; larger ones
+// need a human's approval. A ratio below 1 counts as 1, and a host that
+// did not report its free memory is never flagged.
+func CheckResourcesForSandbox(plan *SandboxPlan, overcommitRatio float64) ResourceCheck {
+	if overcommitRatio < 1 {
+		overcommitRatio = 1
+	}
+	check := ResourceCheck{
+		RequiredMemoryMB:  plan.MemoryMB,
+		AvailableMemoryMB: plan.AvailableMemoryMB,
+		AllowedMemoryMB:   int64(math.Floor(float64(plan.AvailableMemoryMB) * overcommitRatio)),
+	}
+	if plan.AvailableMemoryMB <= 0 || int64(plan.MemoryMB) <= plan.AvailableMemoryMB {
+		return check
+	}
+	if int64(plan.MemoryMB) <= check.AllowedMemoryMB {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("%d MB requested overcommits the host's %d MB free memory, within the %.2gx allowed", plan.MemoryMB, plan.AvailableMemoryMB, overcommitRatio))
+		return check
+	}
+	check.NeedsApproval = true
+	check.Warnings = append(check.Warnings, fmt.Sprintf("%d MB requested exceeds the %d MB allowed (%d MB free x %.2g overcommit)", plan.MemoryMB, check.AllowedMemoryMB, plan.AvailableMemoryMB, overcommitRatio))
+	return check
+}
@@ -0,0 +1,31 @@
+package sandbox
+
+import "testing"
+
+func TestCheckResourcesForSandbox(t *testing.T) {
+	tests := []struct {
+		name          string
+		memoryMB      int
+		availableMB   int64
+		ratio         float64
+		needsApproval bool
+		warnings      int
+	}{
+		{name: "fits", memoryMB: 2048, availableMB: 4096, ratio: 1.2},
+		{name: "within overcommit", memoryMB: 4800, availableMB: 4096, ratio: 1.2, warnings: 1},
+		{name: "beyond overcommit", memoryMB: 5000, availableMB: 4096, ratio: 1.2, needsApproval: true, warnings: 1},
+		{name: "ratio below one", memoryMB: 4097, availableMB: 4096, ratio: 0.5, needsApproval: true, warnings: 1},
+		{name: "free memory unknown", memoryMB: 8192, availableMB: 0, ratio: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := CheckResourcesForSandbox(&SandboxPlan{MemoryMB: tt.memoryMB, AvailableMemoryMB: tt.availableMB}, tt.ratio)
+			if check.NeedsApproval != tt.needsApproval {
+				t.Errorf("NeedsApproval = %v, want %v", check.NeedsApproval, tt.needsApproval)
+			}
+			if len(check.Warnings) != tt.warnings {
+				t.Errorf("Warnings = %q, want %d", check.Warnings, tt.warnings)
+			}
+		})
+	}
+}
@@ -10,12 +10,16 @@ type Service interface {
 	CreateSandboxStream(ctx context.Context, req CreateRequest, onProgress func(step string, stepNum, total int)) (*SandboxInfo, error)
 	GetSandbox(ctx context.Context, id string) (*SandboxInfo, error)
 	ListSandboxes(ctx context.Context) ([]*SandboxInfo, error)
+	GetSandboxes(ctx context.Context, ids []string) (found []*SandboxInfo, notFound []string, err error)
 	DestroySandbox(ctx context.Context, id string) error
 	StartSandbox(ctx context.Context, id string) (*SandboxInfo, error)
 	StopSandbox(ctx context.Context, id string, force bool) error
+	ImportSandbox(ctx context.Context, vmName, name string) (*SandboxInfo, error)
 
 	// Command execution
 	RunCommand(ctx context.Context, sandboxID, command string, timeoutSec int, env map[string]string) (*CommandResult, error)
+	RunCommandWithOptions(ctx context.Context, sandboxID, command string, opts RunOptions) (*CommandResult, error)
+	ListSandboxCommands(ctx context.Context, sandboxID string, limit int, actor string) ([]*CommandRecord, error)
 
 	// Snapshots
 	CreateSnapshot(ctx context.Context, sandboxID, name string) (*SnapshotInfo, error)
@@ -30,6 +34,7 @@ type Service interface {
 	// Host info
 	GetHostInfo(ctx context.Context) (*HostInfo, error)
 	Health(ctx context.Context) error
+	GetStatus(ctx context.Context) (*DaemonStatus, error)
 	DoctorCheck(ctx context.Context) ([]DoctorCheckResult, error)
 	ScanSourceHostKeys(ctx context.Context) ([]ScanSourceHostKeysResult, error)
 
@@ -3,19 +3,96 @@
 // the transport (gRPC, local provider, etc.).
 package sandbox
 
-import "time"
+import (
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+)
 
 // SandboxInfo contains details about a sandbox.
 type SandboxInfo struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	State     string    `json:"state"`
-	IPAddress string    `json:"ip_address,omitempty"`
-	BaseImage string    `json:"base_image"`
-	AgentID   string    `json:"agent_id"`
-	VCPUs     int       `json:"vcpus"`
-	MemoryMB  int       `json:"memory_mb"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	State      string    `json:"state"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	MACAddress string    `json:"mac_address,omitempty"`
+	HostID     string    `json:"host_id,omitempty"`
+	BaseImage  string    `json:"base_image"`
+	AgentID    string    `json:"agent_id"`
+	VCPUs      int       `json:"vcpus"`
+	MemoryMB   int       `json:"memory_mb"`
+	CreatedAt  time.Time `json:"created_at"`
+	Imported   bool      `json:"imported,omitempty"`
+	// Activity is nil until the sandbox has run a command.
+	Activity *SandboxActivity `json:"activity,omitempty"`
+	// Disk is nil until the daemon has measured the root filesystem.
+	Disk *DiskUsage `json:"disk,omitempty"`
+	// IdleStopAt is when the daemon will stop the sandbox if it stays idle;
+	// nil when the daemon has idle stops off.
+	IdleStopAt *time.Time `json:"idle_stop_at,omitempty"`
+	// Workdir is the directory commands run in when they do not name one;
+	// empty means the login directory.
+	Workdir string `json:"workdir,omitempty"`
+}
+
+// DiskWarnPercent is the root filesystem usage at which a sandbox is
+// reported as nearly full.
+const DiskWarnPercent = 90
+
+// DiskUsage is a sandbox's root filesystem usage when last measured.
+type DiskUsage struct {
+	TotalBytes     int64     `json:"total_bytes"`
+	UsedBytes      int64     `json:"used_bytes"`
+	AvailableBytes int64     `json:"available_bytes"`
+	CheckedAt      time.Time `json:"checked_at"`
+}
+
+// UsedPercent is the share of the filesystem in use, as df reports it.
+func (d *DiskUsage) UsedPercent() float64 {
+	if d == nil || d.UsedBytes+d.AvailableBytes == 0 {
+		return 0
+	}
+	return float64(d.UsedBytes) * 100 / float64(d.UsedBytes+d.AvailableBytes)
+}
+
+// NearlyFull reports whether usage is at or above DiskWarnPercent.
+func (d *DiskUsage) NearlyFull() bool {
+	return d.UsedPercent() >= DiskWarnPercent
+}
+
+// SandboxActivity summarizes a sandbox's command history.
+type SandboxActivity struct {
+	LastActivityAt time.Time `json:"last_activity_at"`
+	CommandCount   int       `json:"command_count"`
+	FailedCommands int       `json:"failed_commands"`
+	TotalRuntimeMS int64     `json:"total_runtime_ms"`
+}
+
+// FailureRate is the fraction of commands that exited non-zero.
+func (a *SandboxActivity) FailureRate() float64 {
+	if a == nil || a.CommandCount == 0 {
+		return 0
+	}
+	return float64(a.FailedCommands) / float64(a.CommandCount)
+}
+
+// RunOptions holds optional parameters for RunCommandWithOptions.
+type RunOptions struct {
+	TimeoutSec int
+	Env        map[string]string
+	// ForwardAgent forwards the daemon host's SSH agent into the sandbox for
+	// this command. The daemon must allow it; it is never on by default.
+	ForwardAgent bool
+	// IdentityFiles are extra private keys (paths on the daemon host) to offer
+	// alongside the sandbox key.
+	IdentityFiles []string
+	// Workdir is an absolute directory to run the command in. Empty uses the
+	// sandbox's default working directory, if it has one.
+	Workdir string
+	// Unrecorded keeps the command's output out of command history and off
+	// the idle timer. Use it for reads made on the caller's behalf, such as
+	// a process environment or file contents, which may hold secrets.
+	Unrecorded bool
 }
 
 // CreateRequest holds parameters for creating a sandbox.
@@ -32,6 +109,26 @@ type CreateRequest struct {
 	SimpleElasticsearchBroker bool
 }
 
+// SandboxPlan is what creating a sandbox would do, worked out by the daemon
+// without creating anything. Warnings list problems the create would likely
+// hit; RequiresApproval is set when a daemon policy hook decides whether the
+// create may go ahead.
+type SandboxPlan struct {
+	SandboxID         string   `json:"sandbox_id"`
+	Name              string   `json:"name,omitempty"`
+	HostID            string   `json:"host_id,omitempty"`
+	Hostname          string   `json:"hostname,omitempty"`
+	SourceVM          string   `json:"source_vm"`
+	SourceHost        string   `json:"source_host,omitempty"`
+	SourceHostType    string   `json:"source_host_type,omitempty"`
+	VCPUs             int      `json:"vcpus"`
+	MemoryMB          int      `json:"memory_mb"`
+	AvailableCPUs     int      `json:"available_cpus,omitempty"`
+	AvailableMemoryMB int64    `json:"available_memory_mb,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
+	RequiresApproval  bool     `json:"requires_approval"`
+}
+
 // CommandResult holds the result of a command execution.
 type CommandResult struct {
 	SandboxID  string `json:"sandbox_id"`
@@ -41,11 +138,152 @@ type CommandResult struct {
 	DurationMS int64  `json:"duration_ms"`
 }
 
-// SnapshotInfo holds details about a created snapshot.
+// CommandRecord is a command from a sandbox's recorded history.
+type CommandRecord struct {
+	ID         string    `json:"id"`
+	Command    string    `json:"command"`
+	Actor      string    `json:"actor,omitempty"`
+	Workdir    string    `json:"workdir,omitempty"`
+	Stdout     string    `json:"stdout"`
+	Stderr     string    `json:"stderr"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMS int64     `json:"duration_ms"`
+	StartedAt  time.Time `json:"started_at"`
+	EndedAt    time.Time `json:"ended_at"`
+}
+
+// SnapshotDiff lists files and packages changed between two snapshots (To is
+// empty when compared with the live sandbox) and the commands run in between.
+// A package upgrade is listed as the old version removed and the new one
+// added. Patches is filled in only when unified diffs were asked for.
+type SnapshotDiff struct {
+	SandboxID       string              `json:"sandbox_id"`
+	From            string              `json:"from"`
+	To              string              `json:"to,omitempty"`
+	FilesAdded      []string            `json:"files_added"`
+	FilesModified   []string            `json:"files_modified"`
+	FilesRemoved    []string            `json:"files_removed"`
+	PackagesAdded   []store.PackageInfo `json:"packages_added,omitempty"`
+	PackagesRemoved []store.PackageInfo `json:"packages_removed,omitempty"`
+	CommandsRun     []*CommandRecord    `json:"commands_run"`
+	Patches         []FilePatch         `json:"patches,omitempty"`
+}
+
+// Orphan is a provider resource, such as a Proxmox CT, that no sandbox owns.
+type Orphan struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	State     string `json:"state,omitempty"`
+	Reason    string `json:"reason"`
+	Reclaimed bool   `json:"reclaimed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// OrphanReport lists a daemon's orphans and its provider's allocation pool.
+// PoolSize is zero when the provider has no fixed pool.
+type OrphanReport struct {
+	Orphans  []Orphan `json:"orphans"`
+	PoolSize int      `json:"pool_size,omitempty"`
+	PoolFree int      `json:"pool_free,omitempty"`
+}
+
+// IPRefresh is the outcome of rediscovering one sandbox's IP.
+type IPRefresh struct {
+	SandboxID  string `json:"sandbox_id"`
+	Name       string `json:"name,omitempty"`
+	IPAddress  string `json:"ip_address,omitempty"`
+	PreviousIP string `json:"previous_ip,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DestroyOptions holds optional parameters for DestroySandboxWithOptions.
+type DestroyOptions struct {
+	// Archive exports the sandbox's disk before destroying it.
+	Archive bool
+	// ConfirmImported allows destroying a sandbox adopted with import-vm,
+	// which deletes the VM it was imported from. The daemon refuses such a
+	// destroy without it.
+	ConfirmImported bool
+}
+
+// Archive is where a destroyed sandbox's disk was saved. Image is set when
+// the archive landed in the daemon's base image directory and can be passed
+// to CreateSandbox as a source image.
+type Archive struct {
+	Path  string `json:"path"`
+	Image string `json:"image,omitempty"`
+}
+
+// SSHTarget describes how to reach a sandbox over SSH from its daemon host.
+// Key and certificate paths are on the daemon host.
+type SSHTarget struct {
+	SandboxID       string
+	IPAddress       string
+	Username        string
+	PrivateKeyPath  string
+	CertificatePath string
+	// HostKeyOptions are the ssh options checking the sandbox's host key
+	// under the daemon's host key mode. Empty from daemons that predate it.
+	HostKeyOptions []string
+}
+
+// SSHCredentials is a freshly issued SSH key and certificate for a sandbox.
+// Paths are on the daemon host.
+type SSHCredentials struct {
+	SandboxID       string    `json:"sandbox_id"`
+	Username        string    `json:"username"`
+	PrivateKeyPath  string    `json:"private_key_path"`
+	CertificatePath string    `json:"certificate_path"`
+	ValidUntil      time.Time `json:"valid_until"`
+}
+
+// SandboxStats is what a sandbox was using when the daemon sampled it, as
+// opposed to the shape it was created with. Fields the provider cannot
+// measure are zero. CPUPercent is of one vCPU and only set by providers
+// that sample it themselves; otherwise compare CPUTimeMS across samples.
+type SandboxStats struct {
+	SandboxID          string    `json:"sandbox_id"`
+	State              string    `json:"state"`
+	SampledAt          time.Time `json:"sampled_at"`
+	VCPUs              int       `json:"vcpus"`
+	CPUTimeMS          int64     `json:"cpu_time_ms"`
+	CPUPercent         float64   `json:"cpu_percent,omitempty"`
+	MemoryBytes        int64     `json:"memory_bytes"`
+	MemoryLimitBytes   int64     `json:"memory_limit_bytes"`
+	DiskAllocatedBytes int64     `json:"disk_allocated_bytes"`
+	DiskCapacityBytes  int64     `json:"disk_capacity_bytes,omitempty"`
+}
+
+// SnapshotInfo holds details about a snapshot. Kind is empty for a
+// point-in-time snapshot and "archive" for a disk archive, whose file is Ref.
 type SnapshotInfo struct {
-	SnapshotID   string `json:"snapshot_id"`
-	SnapshotName string `json:"snapshot_name"`
-	SandboxID    string `json:"sandbox_id"`
+	SnapshotID   string    `json:"snapshot_id"`
+	SnapshotName string    `json:"snapshot_name"`
+	SandboxID    string    `json:"sandbox_id"`
+	Kind         string    `json:"kind,omitempty"`
+	Ref          string    `json:"ref,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ForkRequest describes a sandbox cloned from another sandbox's snapshot.
+// Zero VCPUs or MemoryMB inherit the parent's.
+type ForkRequest struct {
+	SandboxID string
+	Snapshot  string
+	Name      string
+	VCPUs     int
+	MemoryMB  int
+	AgentID   string
+}
+
+// SnapshotDeletion reports what deleting a snapshot removed: the daemon's
+// record and the backend snapshot or archive file. Either may already have
+// been gone.
+type SnapshotDeletion struct {
+	SandboxID      string `json:"sandbox_id"`
+	Snapshot       string `json:"snapshot"`
+	RecordDeleted  bool   `json:"record_deleted"`
+	BackendDeleted bool   `json:"backend_deleted"`
 }
 
 // VMInfo describes a source VM available for cloning.
@@ -54,18 +292,29 @@ type VMInfo struct {
 	State     string `json:"state"`
 	IPAddress string `json:"ip_address,omitempty"`
 	Prepared  bool   `json:"prepared"`
+	Host      string `json:"host,omitempty"`
 }
 
 // ValidationInfo contains source VM validation results.
 type ValidationInfo struct {
-	VMName     string   `json:"vm_name"`
-	Valid      bool     `json:"valid"`
-	State      string   `json:"state"`
-	MACAddress string   `json:"mac_address,omitempty"`
-	IPAddress  string   `json:"ip_address,omitempty"`
-	HasNetwork bool     `json:"has_network"`
-	Warnings   []string `json:"warnings,omitempty"`
-	Errors     []string `json:"errors,omitempty"`
+	VMName     string            `json:"vm_name"`
+	Valid      bool              `json:"valid"`
+	State      string            `json:"state"`
+	MACAddress string            `json:"mac_address,omitempty"`
+	IPAddress  string            `json:"ip_address,omitempty"`
+	HasNetwork bool              `json:"has_network"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	Errors     []string          `json:"errors,omitempty"`
+	Issues     []ValidationIssue `json:"issues,omitempty"`
+}
+
+// ValidationIssue is a single coded validation finding. Code is a stable
+// machine-readable identifier such as "VM_NOT_FOUND"; Severity is "error"
+// or "warning".
+type ValidationIssue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Detail   string `json:"detail"`
 }
 
 // PrepareInfo contains the result of preparing a source VM.
@@ -125,3 +374,23 @@ type HostInfo struct {
 	SSHIdentityPubKey string           `json:"ssh_identity_pub_key,omitempty"`
 	SourceHosts       []SourceHostInfo `json:"source_hosts,omitempty"`
 }
+
+// DaemonError is a recent error reported by the daemon.
+type DaemonError struct {
+	Time    string `json:"time"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+// DaemonStatus is a daemon's self-reported runtime status.
+type DaemonStatus struct {
+	HostID          string        `json:"host_id"`
+	Hostname        string        `json:"hostname"`
+	Version         string        `json:"version"`
+	Provider        string        `json:"provider"`
+	ActiveSandboxes int           `json:"active_sandboxes"`
+	StartedAt       string        `json:"started_at,omitempty"`
+	UptimeSeconds   int64         `json:"uptime_seconds"`
+	JanitorLastRun  string        `json:"janitor_last_run,omitempty"`
+	RecentErrors    []DaemonError `json:"recent_errors,omitempty"`
+}
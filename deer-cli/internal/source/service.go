@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 
 	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/hostexec"
@@ -62,7 +63,7 @@ func (s *Service) RunCommand(ctx context.Context, hostName, command string) (*Co
 		return nil, fmt.Errorf("host %q is not prepared - run: deer source prepare %s", hostName, hostName)
 	}
 
-	if err := readonly.ValidateCommandWithExtra(command, s.cfg.ExtraAllowedCommands); err != nil {
+	if err := s.policy().ValidateCommand(command); err != nil {
 		return nil, fmt.Errorf("command not allowed: %w (use request_source_access to ask the human for approval if this command is needed for diagnosis)", err)
 	}
 
@@ -87,7 +88,8 @@ func (s *Service) RunCommand(ctx context.Context, hostName, command string) (*Co
 }
 
 // RunCommandStreaming executes a read-only command with live output streaming.
-// Functionally identical to RunCommand but calls onOutput for each line as it arrives.
+// Functionally identical to RunCommand but calls onOutput for each line as it
+// arrives; the result still carries the full output.
 func (s *Service) RunCommandStreaming(ctx context.Context, hostName, command string, onOutput hostexec.OutputCallback) (*CommandResult, error) {
 	host, err := s.findHost(hostName)
 	if err != nil {
@@ -97,7 +99,7 @@ func (s *Service) RunCommandStreaming(ctx context.Context, hostName, command str
 		return nil, fmt.Errorf("host %q is not prepared - run: deer source prepare %s", hostName, hostName)
 	}
 
-	if err := readonly.ValidateCommandWithExtra(command, s.cfg.ExtraAllowedCommands); err != nil {
+	if err := s.policy().ValidateCommand(command); err != nil {
 		return nil, fmt.Errorf("command not allowed: %w (use request_source_access to ask the human for approval if this command is needed for diagnosis)", err)
 	}
 
@@ -106,21 +108,36 @@ func (s *Service) RunCommandStreaming(ctx context.Context, hostName, command str
 		"-o", "IdentitiesOnly=yes",
 		"-i", s.keyPath,
 	}
-	stdout, stderr, exitCode, err := hostexec.RunStreamingSSHAlias(ctx, hostName, extraArgs, command, onOutput)
+	// hostexec only buffers output nobody streams, so keep it here.
+	var stdout, stderr strings.Builder
+	var mu sync.Mutex
+	collect := func(chunk string, isStderr bool) {
+		mu.Lock()
+		if isStderr {
+			stderr.WriteString(chunk)
+		} else {
+			stdout.WriteString(chunk)
+		}
+		mu.Unlock()
+		if onOutput != nil {
+			onOutput(chunk, isStderr)
+		}
+	}
+	_, _, exitCode, err := hostexec.RunStreamingSSHAlias(ctx, hostName, extraArgs, command, collect)
 	if err != nil {
 		return &CommandResult{
 			Host:     hostName,
 			ExitCode: exitCode,
-			Stdout:   stdout,
-			Stderr:   stderr,
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
 		}, fmt.Errorf("ssh command failed: %w", err)
 	}
 
 	return &CommandResult{
 		Host:     hostName,
 		ExitCode: exitCode,
-		Stdout:   stdout,
-		Stderr:   stderr,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
 	}, nil
 }
 
@@ -201,3 +218,19 @@ func (s *Service) findHost(name string) (*config.HostConfig, error) {
 	}
 	return nil, fmt.Errorf("host %q not found in config - run: deer source prepare %s", name, name)
 }
+
+// policy is the read-only policy for source host commands.
+func (s *Service) policy() *readonly.Policy {
+	return ReadonlyPolicy(s.cfg)
+}
+
+// ReadonlyPolicy is the read-only policy for source host commands: the
+// built-in allowlist plus extra_allowed_commands and readonly.allow, minus
+// readonly.deny. Commands sent to the daemon are checked against it before
+// they go out; the daemon then applies its own readonly lists.
+func ReadonlyPolicy(cfg *config.Config) *readonly.Policy {
+	allow := make([]string, 0, len(cfg.ExtraAllowedCommands)+len(cfg.Readonly.Allow))
+	allow = append(allow, cfg.ExtraAllowedCommands...)
+	allow = append(allow, cfg.Readonly.Allow...)
+	return &readonly.Policy{Allow: allow, Deny: cfg.Readonly.Deny}
+}
@@ -3,6 +3,7 @@ package source
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
@@ -56,3 +57,27 @@ func TestReadFileRequiresAbsolutePath(t *testing.T) {
 		t.Error("expected error for relative path")
 	}
 }
+
+func TestRunCommandReadonlyPolicy(t *testing.T) {
+	cfg := &config.Config{
+		Hosts: []config.HostConfig{
+			{Name: "web-01", Address: "10.0.0.1", Prepared: true},
+		},
+		ExtraAllowedCommands: []string{"docker"},
+		Readonly:             config.ReadonlyConfig{Allow: []string{"tcpdump"}, Deny: []string{"find", "docker"}},
+	}
+	svc := NewService(cfg, "/tmp/key", slog.Default())
+
+	p := svc.policy()
+	if err := p.ValidateCommand("tcpdump -c 5"); err != nil {
+		t.Errorf("expected readonly.allow to allow tcpdump: %v", err)
+	}
+	if err := p.ValidateCommand("docker ps"); err == nil {
+		t.Error("expected readonly.deny to win over extra_allowed_commands")
+	}
+
+	_, err := svc.RunCommand(context.TODO(), "web-01", "find /etc")
+	if err == nil || !strings.Contains(err.Error(), "command not allowed") {
+		t.Errorf("expected find to be refused before SSH, got %v", err)
+	}
+}
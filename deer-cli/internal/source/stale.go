@@ -0,0 +1,70 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sourcekeys"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+)
+
+// RecordPreparedHost stores that hostname trusts pubKey, with the key's
+// fingerprint in the SourceVM record's CAFingerprint, so a later key
+// rotation can tell which hosts need preparing again. result is the
+// preparation's outcome, saved as PrepareJSON; nil records a host found to
+// already accept the key and keeps the details of its last preparation.
+func RecordPreparedHost(ctx context.Context, st store.Store, hostname, address, pubKey string, result any) error {
+	fp, err := sourcekeys.Fingerprint(pubKey)
+	if err != nil {
+		return err
+	}
+	svm := &store.SourceVM{
+		Name:          hostname,
+		HostName:      &hostname,
+		Prepared:      true,
+		CAFingerprint: &fp,
+	}
+	if address != "" {
+		svm.HostAddress = &address
+	}
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("encode prepare result: %w", err)
+		}
+		now := time.Now().UTC()
+		prepareJSON := string(data)
+		svm.PreparedAt = &now
+		svm.PrepareJSON = &prepareJSON
+	} else if prev, err := st.GetSourceVM(ctx, hostname); err == nil {
+		svm.PreparedAt = prev.PreparedAt
+		svm.PrepareJSON = prev.PrepareJSON
+	}
+	if err := st.UpsertSourceVM(ctx, svm); err != nil {
+		return fmt.Errorf("record prepared host %s: %w", hostname, err)
+	}
+	return nil
+}
+
+// ListStaleSourceVMs returns the prepared source VMs whose recorded key
+// fingerprint no longer matches currentFingerprint, or was never recorded.
+// Their deer-readonly user still trusts the old key, so read-only access
+// fails until they are prepared again.
+func ListStaleSourceVMs(ctx context.Context, st store.Store, currentFingerprint string) ([]*store.SourceVM, error) {
+	svms, err := st.ListSourceVMs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list source VMs: %w", err)
+	}
+	var stale []*store.SourceVM
+	for _, svm := range svms {
+		if !svm.Prepared {
+			continue
+		}
+		if svm.CAFingerprint == nil || *svm.CAFingerprint != currentFingerprint {
+			stale = append(stale, svm)
+		}
+	}
+	return stale, nil
+}
@@ -0,0 +1,76 @@
+package source
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/sourcekeys"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store/sqlite"
+)
+
+func TestListStaleSourceVMs(t *testing.T) {
+	ctx := context.Background()
+	st, err := sqlite.New(ctx, store.Config{DatabaseURL: filepath.Join(t.TempDir(), "deer.db"), AutoMigrate: true})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	_, oldKey, err := sourcekeys.EnsureKeyPair(filepath.Join(t.TempDir(), "old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, newKey, err := sourcekeys.EnsureKeyPair(filepath.Join(t.TempDir(), "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RecordPreparedHost(ctx, st, "web-01", "10.0.0.1", oldKey, map[string]bool{"user_created": true}); err != nil {
+		t.Fatalf("RecordPreparedHost: %v", err)
+	}
+	if err := RecordPreparedHost(ctx, st, "db-01", "", newKey, nil); err != nil {
+		t.Fatalf("RecordPreparedHost: %v", err)
+	}
+	if err := st.UpsertSourceVM(ctx, &store.SourceVM{Name: "legacy-01", Prepared: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.UpsertSourceVM(ctx, &store.SourceVM{Name: "never-01"}); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := sourcekeys.Fingerprint(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale, err := ListStaleSourceVMs(ctx, st, current)
+	if err != nil {
+		t.Fatalf("ListStaleSourceVMs: %v", err)
+	}
+	var names []string
+	for _, svm := range stale {
+		names = append(names, svm.Name)
+	}
+	if len(names) != 2 || names[0] != "legacy-01" || names[1] != "web-01" {
+		t.Fatalf("stale = %v, want [legacy-01 web-01]", names)
+	}
+	if stale[1].HostAddress == nil || *stale[1].HostAddress != "10.0.0.1" || stale[1].PreparedAt == nil {
+		t.Errorf("web-01 record = %+v", stale[1])
+	}
+
+	// Re-verifying web-01 against the new key keeps its preparation details.
+	if err := RecordPreparedHost(ctx, st, "web-01", "10.0.0.1", newKey, nil); err != nil {
+		t.Fatalf("RecordPreparedHost: %v", err)
+	}
+	got, err := st.GetSourceVM(ctx, "web-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PrepareJSON == nil || *got.PrepareJSON != `{"user_created":true}` || got.PreparedAt == nil {
+		t.Errorf("web-01 after re-verify = %+v", got)
+	}
+	if got.CAFingerprint == nil || *got.CAFingerprint != current {
+		t.Errorf("web-01 fingerprint = %v, want %s", got.CAFingerprint, current)
+	}
+}
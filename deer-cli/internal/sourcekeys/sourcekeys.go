@@ -81,6 +81,16 @@ func GetPublicKey(keyDir string) (string, error) {
 	return string(data), nil
 }
 
+// Fingerprint returns the SHA256 fingerprint of an authorized_keys-format
+// public key, as ssh-keygen -l prints it.
+func Fingerprint(pubKey string) (string, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pubKey))
+	if err != nil {
+		return "", fmt.Errorf("parse public key: %w", err)
+	}
+	return ssh.FingerprintSHA256(key), nil
+}
+
 // GetPrivateKeyPath returns the path to the private key in the key directory.
 // The returned path may not exist on disk if EnsureKeyPair has not been called.
 func GetPrivateKeyPath(keyDir string) string {
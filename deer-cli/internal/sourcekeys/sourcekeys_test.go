@@ -90,6 +90,32 @@ func TestGetPublicKeyMissing(t *testing.T) {
 	}
 }
 
+func TestFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	_, pub1, err := EnsureKeyPair(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatalf("EnsureKeyPair: %v", err)
+	}
+	_, pub2, err := EnsureKeyPair(filepath.Join(dir, "b"))
+	if err != nil {
+		t.Fatalf("EnsureKeyPair: %v", err)
+	}
+
+	fp1, err := Fingerprint(pub1)
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+	if !strings.HasPrefix(fp1, "SHA256:") {
+		t.Errorf("fingerprint = %q, want SHA256: prefix", fp1)
+	}
+	if fp2, _ := Fingerprint(pub2); fp2 == fp1 {
+		t.Error("different keys should have different fingerprints")
+	}
+	if _, err := Fingerprint("not a key"); err == nil {
+		t.Error("expected error for malformed key")
+	}
+}
+
 func TestGetPrivateKeyPath(t *testing.T) {
 	path := GetPrivateKeyPath("/some/dir")
 	if path != "/some/dir/source_ed25519" {
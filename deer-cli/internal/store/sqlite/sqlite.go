@@ -148,6 +148,36 @@ func (s *sqliteStore) GetSandbox(ctx context.Context, id string) (*store.Sandbox
 	return sandboxFromModel(&model), nil
 }
 
+// GetSandboxes fetches the live sandboxes among ids with a single IN
+// query. Found sandboxes are returned in the order of ids; ids with no live
+// sandbox are listed in notFound.
+func (s *sqliteStore) GetSandboxes(ctx context.Context, ids []string) ([]*store.Sandbox, []string, error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+	var models []SandboxModel
+	if err := s.db.WithContext(ctx).
+		Where("id IN ? AND deleted_at IS NULL", ids).
+		Find(&models).Error; err != nil {
+		return nil, nil, mapDBError(err)
+	}
+	byID := make(map[string]*SandboxModel, len(models))
+	for i := range models {
+		byID[models[i].ID] = &models[i]
+	}
+
+	found := make([]*store.Sandbox, 0, len(byID))
+	var notFound []string
+	for _, id := range ids {
+		if m, ok := byID[id]; ok {
+			found = append(found, sandboxFromModel(m))
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return found, notFound, nil
+}
+
 func (s *sqliteStore) GetSandboxByVMName(ctx context.Context, vmName string) (*store.Sandbox, error) {
 	var model SandboxModel
 	if err := s.db.WithContext(ctx).
@@ -860,6 +890,132 @@ func (s *sqliteStore) ListSourceVMs(ctx context.Context) ([]*store.SourceVM, err
 	return out, nil
 }
 
+// --- VM inventory cache ---
+
+// ReplaceVMCache swaps the cached VM inventory for vms in one transaction,
+// so readers never see a half-written listing.
+func (s *sqliteStore) ReplaceVMCache(ctx context.Context, vms []*store.CachedVM) error {
+	if s.conf.ReadOnly {
+		return fmt.Errorf("sqlite: ReplaceVMCache: %w", store.ErrInvalid)
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&VMCacheModel{}).Error; err != nil {
+			return mapDBError(err)
+		}
+		if len(vms) == 0 {
+			return nil
+		}
+		models := make([]VMCacheModel, 0, len(vms))
+		for _, vm := range vms {
+			models = append(models, VMCacheModel{
+				Name:      vm.Name,
+				Host:      vm.Host,
+				State:     vm.State,
+				IPAddress: vm.IPAddress,
+				Prepared:  vm.Prepared,
+				CachedAt:  vm.CachedAt.UTC(),
+			})
+		}
+		return mapDBError(tx.Create(&models).Error)
+	})
+}
+
+func (s *sqliteStore) ListCachedVMs(ctx context.Context) ([]*store.CachedVM, error) {
+	var models []VMCacheModel
+	if err := s.db.WithContext(ctx).Order("host ASC, name ASC").Find(&models).Error; err != nil {
+		return nil, mapDBError(err)
+	}
+	out := make([]*store.CachedVM, 0, len(models))
+	for _, m := range models {
+		out = append(out, &store.CachedVM{
+			Name:      m.Name,
+			Host:      m.Host,
+			State:     m.State,
+			IPAddress: m.IPAddress,
+			Prepared:  m.Prepared,
+			CachedAt:  m.CachedAt,
+		})
+	}
+	return out, nil
+}
+
+// --- Conversation ---
+
+// SaveConversation inserts c or replaces the saved conversation with the
+// same ID, keeping its original CreatedAt.
+func (s *sqliteStore) SaveConversation(ctx context.Context, c *store.Conversation) error {
+	if s.conf.ReadOnly {
+		return fmt.Errorf("sqlite: SaveConversation: %w", store.ErrInvalid)
+	}
+	if c == nil || c.ID == "" {
+		return fmt.Errorf("sqlite: SaveConversation: %w", store.ErrInvalid)
+	}
+	now := time.Now().UTC()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing ConversationModel
+		err := tx.Where("id = ?", c.ID).First(&existing).Error
+		switch {
+		case err == nil:
+			c.CreatedAt = existing.CreatedAt
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.CreatedAt = now
+		default:
+			return mapDBError(err)
+		}
+		c.UpdatedAt = now
+		return mapDBError(tx.Save(&ConversationModel{
+			ID:          c.ID,
+			Title:       c.Title,
+			Messages:    c.Messages,
+			Compactions: c.Compactions,
+			CreatedAt:   c.CreatedAt,
+			UpdatedAt:   c.UpdatedAt,
+		}).Error)
+	})
+}
+
+func (s *sqliteStore) LoadConversation(ctx context.Context, id string) (*store.Conversation, error) {
+	var m ConversationModel
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		return nil, mapDBError(err)
+	}
+	return conversationFromModel(&m), nil
+}
+
+// ListConversations returns saved conversations, most recently updated
+// first.
+func (s *sqliteStore) ListConversations(ctx context.Context, opt *store.ListOptions) ([]*store.Conversation, error) {
+	tx := s.db.WithContext(ctx).Model(&ConversationModel{}).Order("updated_at DESC")
+	if opt != nil {
+		if opt.Limit > 0 {
+			tx = tx.Limit(opt.Limit)
+		}
+		if opt.Offset > 0 {
+			tx = tx.Offset(opt.Offset)
+		}
+	}
+	var models []ConversationModel
+	if err := tx.Find(&models).Error; err != nil {
+		return nil, mapDBError(err)
+	}
+	out := make([]*store.Conversation, 0, len(models))
+	for i := range models {
+		out = append(out, conversationFromModel(&models[i]))
+	}
+	return out, nil
+}
+
+func conversationFromModel(m *ConversationModel) *store.Conversation {
+	return &store.Conversation{
+		ID:          m.ID,
+		Title:       m.Title,
+		Messages:    m.Messages,
+		Compactions: m.Compactions,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
 // --- Migration ---
 
 func (s *sqliteStore) autoMigrate(ctx context.Context) error {
@@ -874,6 +1030,8 @@ func (s *sqliteStore) autoMigrate(ctx context.Context) error {
 		&PlaybookTaskModel{},
 		&HostResourcesModel{},
 		&SourceVMModel{},
+		&VMCacheModel{},
+		&ConversationModel{},
 	); err != nil {
 		return err
 	}
@@ -1033,6 +1191,28 @@ type SourceVMModel struct {
 
 func (SourceVMModel) TableName() string { return "source_vms" }
 
+type VMCacheModel struct {
+	Host      string    `gorm:"primaryKey;column:host"`
+	Name      string    `gorm:"primaryKey;column:name"`
+	State     string    `gorm:"column:state;not null"`
+	IPAddress string    `gorm:"column:ip_address"`
+	Prepared  bool      `gorm:"column:prepared;not null;default:false"`
+	CachedAt  time.Time `gorm:"column:cached_at;not null"`
+}
+
+func (VMCacheModel) TableName() string { return "vm_cache" }
+
+type ConversationModel struct {
+	ID          string    `gorm:"primaryKey;column:id"`
+	Title       string    `gorm:"column:title;not null"`
+	Messages    string    `gorm:"column:messages;type:text;not null"`
+	Compactions int       `gorm:"column:compactions;not null;default:0"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;not null;index"`
+}
+
+func (ConversationModel) TableName() string { return "conversations" }
+
 // --- Converters ---
 
 func sandboxToModel(sb *store.Sandbox) *SandboxModel {
@@ -271,6 +271,38 @@ func TestNotFoundErrors(t *testing.T) {
 	assert.ErrorIs(t, err, store.ErrNotFound)
 }
 
+func TestGetSandboxes(t *testing.T) {
+	s, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	for _, id := range []string{"SBX-101", "SBX-102", "SBX-103"} {
+		require.NoError(t, s.CreateSandbox(ctx, &store.Sandbox{
+			ID:          id,
+			JobID:       "JOB-101",
+			AgentID:     "agent-1",
+			SandboxName: "sb-" + id,
+			BaseImage:   "ubuntu-base",
+			Network:     "default",
+			State:       store.SandboxStateRunning,
+		}))
+	}
+	require.NoError(t, s.DeleteSandbox(ctx, "SBX-102"))
+
+	found, notFound, err := s.GetSandboxes(ctx, []string{"SBX-103", "SBX-404", "SBX-102", "SBX-101"})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "SBX-103", found[0].ID)
+	assert.Equal(t, "SBX-101", found[1].ID)
+	assert.Equal(t, []string{"SBX-404", "SBX-102"}, notFound)
+
+	found, notFound, err = s.GetSandboxes(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	assert.Empty(t, notFound)
+}
+
 func TestDuplicateSandboxName(t *testing.T) {
 	s, cleanup := setupTestStore(t)
 	defer cleanup()
@@ -453,3 +485,80 @@ func TestPing(t *testing.T) {
 	err := s.Ping(ctx)
 	require.NoError(t, err)
 }
+
+func TestVMCache(t *testing.T) {
+	s, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	vms, err := s.ListCachedVMs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, vms)
+
+	cachedAt := time.Now().UTC().Truncate(time.Second)
+	err = s.ReplaceVMCache(ctx, []*store.CachedVM{
+		{Name: "web-01", Host: "kvm-02", State: "running", IPAddress: "10.0.0.5", Prepared: true, CachedAt: cachedAt},
+		{Name: "db-01", Host: "kvm-01", State: "shut off", CachedAt: cachedAt},
+	})
+	require.NoError(t, err)
+
+	vms, err = s.ListCachedVMs(ctx)
+	require.NoError(t, err)
+	require.Len(t, vms, 2)
+	// Ordered by host, then name
+	assert.Equal(t, "db-01", vms[0].Name)
+	assert.Equal(t, "web-01", vms[1].Name)
+	assert.True(t, vms[1].Prepared)
+	assert.Equal(t, "10.0.0.5", vms[1].IPAddress)
+	assert.True(t, vms[1].CachedAt.Equal(cachedAt))
+
+	// Replacing drops VMs missing from the new listing
+	err = s.ReplaceVMCache(ctx, []*store.CachedVM{
+		{Name: "web-01", Host: "kvm-02", State: "shut off", CachedAt: cachedAt.Add(time.Minute)},
+	})
+	require.NoError(t, err)
+
+	vms, err = s.ListCachedVMs(ctx)
+	require.NoError(t, err)
+	require.Len(t, vms, 1)
+	assert.Equal(t, "shut off", vms[0].State)
+
+	require.NoError(t, s.ReplaceVMCache(ctx, nil))
+	vms, err = s.ListCachedVMs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, vms)
+}
+
+func TestConversations(t *testing.T) {
+	s, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	_, err := s.LoadConversation(ctx, "missing")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	assert.ErrorIs(t, s.SaveConversation(ctx, &store.Conversation{}), store.ErrInvalid)
+
+	first := &store.Conversation{ID: "sess-1", Title: "fix nginx", Messages: `[{"role":"user","content":"fix nginx"}]`}
+	require.NoError(t, s.SaveConversation(ctx, first))
+	created := first.CreatedAt
+	second := &store.Conversation{ID: "sess-2", Title: "check disk", Messages: `[]`}
+	require.NoError(t, s.SaveConversation(ctx, second))
+
+	// Saving again replaces the messages and keeps the creation time.
+	first.Messages = `[{"role":"user","content":"fix nginx"},{"role":"assistant","content":"done"}]`
+	first.Compactions = 1
+	require.NoError(t, s.SaveConversation(ctx, first))
+
+	got, err := s.LoadConversation(ctx, "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, first.Messages, got.Messages)
+	assert.Equal(t, 1, got.Compactions)
+	assert.True(t, got.CreatedAt.Equal(created))
+
+	list, err := s.ListConversations(ctx, &store.ListOptions{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "sess-1", list[0].ID, "most recently updated first")
+}
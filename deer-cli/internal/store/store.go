@@ -249,6 +249,17 @@ type SourceVM struct {
 	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// CachedVM is a source VM as last listed by the sandbox host. Every entry of
+// one inventory shares CachedAt, the time it was fetched.
+type CachedVM struct {
+	Name      string    `json:"name" db:"name"`
+	Host      string    `json:"host,omitempty" db:"host"`
+	State     string    `json:"state" db:"state"`
+	IPAddress string    `json:"ip_address,omitempty" db:"ip_address"`
+	Prepared  bool      `json:"prepared" db:"prepared"`
+	CachedAt  time.Time `json:"cached_at" db:"cached_at"`
+}
+
 // Playbook represents an Ansible playbook stored in the database.
 type Playbook struct {
 	ID        string    `json:"id" db:"id"`
@@ -271,12 +282,25 @@ type PlaybookTask struct {
 	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
 }
 
+// Conversation is a saved TUI agent conversation, so it can be resumed
+// after the TUI exits. Messages is the agent's JSON-encoded history,
+// including tool calls and results; the store does not interpret it.
+type Conversation struct {
+	ID          string    `json:"id" db:"id"`                   // TUI session ID
+	Title       string    `json:"title" db:"title"`             // first user prompt, shortened
+	Messages    string    `json:"messages" db:"messages"`       // JSON-encoded []llm.Message
+	Compactions int       `json:"compactions" db:"compactions"` // times the history was replaced by a summary
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
 // DataStore declares data operations. This is transaction-friendly and
 // can be implemented by both the root Store and a transactional context.
 type DataStore interface {
 	// Sandbox
 	CreateSandbox(ctx context.Context, sb *Sandbox) error
 	GetSandbox(ctx context.Context, id string) (*Sandbox, error)
+	GetSandboxes(ctx context.Context, ids []string) (found []*Sandbox, notFound []string, err error)
 	GetSandboxByVMName(ctx context.Context, vmName string) (*Sandbox, error)
 	ListSandboxes(ctx context.Context, filter SandboxFilter, opt *ListOptions) ([]*Sandbox, error)
 	UpdateSandbox(ctx context.Context, sb *Sandbox) error
@@ -322,6 +346,15 @@ type DataStore interface {
 	UpsertSourceVM(ctx context.Context, svm *SourceVM) error
 	ListSourceVMs(ctx context.Context) ([]*SourceVM, error)
 
+	// VM inventory cache
+	ReplaceVMCache(ctx context.Context, vms []*CachedVM) error
+	ListCachedVMs(ctx context.Context) ([]*CachedVM, error)
+
+	// Conversation
+	SaveConversation(ctx context.Context, c *Conversation) error
+	LoadConversation(ctx context.Context, id string) (*Conversation, error)
+	ListConversations(ctx context.Context, opt *ListOptions) ([]*Conversation, error)
+
 	// PlaybookTask
 	CreatePlaybookTask(ctx context.Context, task *PlaybookTask) error
 	GetPlaybookTask(ctx context.Context, id string) (*PlaybookTask, error)
@@ -43,6 +43,29 @@ func NewNoopService() Service {
 	return &NoopService{}
 }
 
+// DoNotTrack reports whether the DO_NOT_TRACK environment variable asks for
+// tracking to be off. Any value other than empty, "0", or "false" counts.
+func DoNotTrack() bool {
+	v := strings.TrimSpace(os.Getenv("DO_NOT_TRACK"))
+	return v != "" && v != "0" && !strings.EqualFold(v, "false")
+}
+
+// State reports whether telemetry will be sent and, in a few words, why.
+// noTelemetry (the --no-telemetry flag) and DO_NOT_TRACK override cfg.
+func State(cfg config.TelemetryConfig, noTelemetry bool) (enabled bool, reason string) {
+	switch {
+	case noTelemetry:
+		return false, "disabled by --no-telemetry"
+	case DoNotTrack():
+		return false, "disabled by DO_NOT_TRACK"
+	case !cfg.EnableAnonymousUsage:
+		return false, "disabled in config"
+	case posthogAPIKey == "":
+		return false, "not included in this build"
+	}
+	return true, "enabled in config"
+}
+
 type posthogService struct {
 	client     posthog.Client
 	distinctID string
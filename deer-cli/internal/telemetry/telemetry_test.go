@@ -129,3 +129,35 @@ func TestGetOrCreateDistinctID_Persistence(t *testing.T) {
 		t.Errorf("expected %q, got %q", knownID, id3)
 	}
 }
+
+func TestDoNotTrack(t *testing.T) {
+	for v, want := range map[string]bool{"": false, "0": false, "false": false, "1": true, "true": true, "yes": true} {
+		t.Setenv("DO_NOT_TRACK", v)
+		if got := DoNotTrack(); got != want {
+			t.Errorf("DO_NOT_TRACK=%q: DoNotTrack() = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	orig := posthogAPIKey
+	posthogAPIKey = "test-key"
+	t.Cleanup(func() { posthogAPIKey = orig })
+	on := config.TelemetryConfig{EnableAnonymousUsage: true}
+
+	t.Setenv("DO_NOT_TRACK", "")
+	if enabled, reason := State(on, false); !enabled {
+		t.Errorf("State(enabled config) = off (%s), want on", reason)
+	}
+	if enabled, reason := State(on, true); enabled || reason != "disabled by --no-telemetry" {
+		t.Errorf("State with --no-telemetry = %v (%s)", enabled, reason)
+	}
+	if enabled, reason := State(config.TelemetryConfig{}, false); enabled || reason != "disabled in config" {
+		t.Errorf("State(disabled config) = %v (%s)", enabled, reason)
+	}
+
+	t.Setenv("DO_NOT_TRACK", "1")
+	if enabled, reason := State(on, false); enabled || reason != "disabled by DO_NOT_TRACK" {
+		t.Errorf("State with DO_NOT_TRACK=1 = %v (%s)", enabled, reason)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -30,6 +31,7 @@ import (
 	"github.com/aspectrr/deer.sh/deer-cli/internal/sshconfig"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/telemetry"
+	"github.com/aspectrr/deer.sh/shared/multierr"
 )
 
 const tlsDebuggingGuidance = "\n\nWhen debugging TLS/SSL issues on source hosts:\n" +
@@ -39,12 +41,27 @@ const tlsDebuggingGuidance = "\n\nWhen debugging TLS/SSL issues on source hosts:
 	"- Use `openssl s_client -connect localhost:<port>` to inspect the live certificate chain (no file access needed)\n" +
 	"- Use `ls -la` on cert directories to check ownership and permissions as a diagnostic"
 
-// PendingApproval represents a sandbox creation waiting for memory approval
+// ApprovalKind is what a pending approval asks the human about.
+type ApprovalKind string
+
+const (
+	// ApprovalMemory is a sandbox that asks for more memory than the host
+	// can spare, even allowing for overcommit.
+	ApprovalMemory ApprovalKind = "memory"
+	// ApprovalNetwork is a sandbox command that reaches the network.
+	ApprovalNetwork ApprovalKind = "network"
+	// ApprovalCommand is any sandbox command under --require-approval.
+	ApprovalCommand ApprovalKind = "command"
+	// ApprovalTool is a tool call the tool_approval policy says to confirm.
+	ApprovalTool ApprovalKind = "tool"
+)
+
+// PendingApproval is a yes/no question the agent is blocked on until the
+// human answers it through HandleApprovalResponse.
 type PendingApproval struct {
-	Request      MemoryApprovalRequest
-	SourceVM     string
-	SandboxName  string
-	Host         *config.HostConfig // nil for local
+	Kind ApprovalKind
+	// Details describes what is being approved, as recorded in the audit log.
+	Details      map[string]any
 	ResponseChan chan bool
 }
 
@@ -69,6 +86,12 @@ type DeerAgent struct {
 	// Conversation history for context
 	history []llm.Message
 
+	// sessionID keys the saved copy of history in the store; compactions
+	// and conversationTitle are saved alongside it.
+	sessionID         string
+	compactions       int
+	conversationTitle string
+
 	// Track sandboxes created during this session for cleanup on exit
 	createdSandboxes []string
 
@@ -85,8 +108,8 @@ type DeerAgent struct {
 	// Stays true after withAutoReadOnly exits until a write tool explicitly clears it.
 	displayReadOnly bool
 
-	// Pending approval for network access
-	pendingNetworkApproval *PendingNetworkApproval
+	// Pending yes/no approval (memory, network, command or tool), guarded by mu
+	pendingApproval *PendingApproval
 
 	// Pending approval for source command elevation
 	pendingSourceAccess *PendingSourceAccess
@@ -97,6 +120,10 @@ type DeerAgent struct {
 	// Read-only mode: only query tools are available to the LLM
 	readOnly bool
 
+	// requireApproval asks the human before every sandbox command, not just
+	// those that reach the network.
+	requireApproval bool
+
 	// Re-prepare warning: tracks the last host warned about re-prepare
 	lastPrepareWarned string
 
@@ -109,20 +136,21 @@ type DeerAgent struct {
 	// Task list for tracking agent progress
 	taskList *TaskList
 
+	// measuredTokens is the prompt+completion token count the provider
+	// reported for the last request; measuredLen is the history length that
+	// request covered. Zero measuredTokens means no usage has been reported.
+	measuredTokens int
+	measuredLen    int
+
 	// cancelFunc cancels the active agent Run context when ESC is pressed.
-	// mu protects cancelFunc, runID, done, currentSourceVM, autoReadOnly, and readOnly.
+	// mu protects cancelFunc, runID, done, currentSourceVM, autoReadOnly, readOnly,
+	// and pendingApproval.
 	cancelFunc context.CancelFunc
 	runID      uint64
 	done       chan struct{}
 	mu         sync.Mutex
 }
 
-// PendingNetworkApproval represents a network access request waiting for approval
-type PendingNetworkApproval struct {
-	Request      NetworkApprovalRequest
-	ResponseChan chan bool
-}
-
 // PendingSourceAccess represents a command elevation request waiting for approval
 type PendingSourceAccess struct {
 	Request      SourceAccessApprovalRequest
@@ -135,9 +163,9 @@ func NewDeerAgent(cfg *config.Config, st store.Store, svc sandbox.Service, srcSv
 		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
 
-	var llmClient llm.Client
-	if cfg.AIAgent.Provider == "openrouter" {
-		llmClient = llm.NewOpenRouterClient(cfg.AIAgent)
+	llmClient, err := llm.NewClient(cfg.AIAgent)
+	if err != nil {
+		logger.Warn("no LLM client", "error", err)
 	}
 
 	return &DeerAgent{
@@ -154,6 +182,7 @@ func NewDeerAgent(cfg *config.Config, st store.Store, svc sandbox.Service, srcSv
 		logger:                  logger,
 		skillLoader:             initSkillLoader(logger),
 		history:                 make([]llm.Message, 0),
+		sessionID:               newSessionID(),
 		swapTimeout:             2 * time.Second,
 		redactedSeen:            make(map[string]bool),
 		sessionElevatedCommands: make(map[string]map[string]bool),
@@ -188,6 +217,11 @@ func (a *DeerAgent) SetReadOnly(ro bool) {
 	a.readOnly = ro
 }
 
+// SetRequireApproval turns on asking the human before every sandbox command.
+func (a *DeerAgent) SetRequireApproval(on bool) {
+	a.requireApproval = on
+}
+
 // SetSandboxService hot-swaps the sandbox service (e.g. after /connect).
 // Must be called after Cancel() to avoid race conditions with running agent.
 // Waits for the running goroutine to finish before swapping.
@@ -232,7 +266,9 @@ func (a *DeerAgent) sendStatus(msg tea.Msg) {
 // finishRun sends the final TUI-facing status update and returns the only
 // direct completion signal for Run(). AgentDoneMsg must not be queued through
 // statusCallback, otherwise it can remain buffered and break the next run.
+// The conversation is saved first, so every finished turn survives a restart.
 func (a *DeerAgent) finishRun(msg tea.Msg) tea.Msg {
+	a.saveConversation(context.Background())
 	if msg != nil {
 		a.sendStatus(msg)
 	}
@@ -333,6 +369,19 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 			a.telemetry.Track("tui_slash_command", map[string]any{"command": cmdName})
 
 			// Commands with arguments (checked before exact match switch)
+			if input == "/models" || strings.HasPrefix(input, "/models ") {
+				if a.llmClient == nil {
+					return a.finishRun(AgentResponseMsg{Response: AgentResponse{
+						Content: "No LLM provider configured. Set one in `/settings`.",
+						Done:    true,
+					}})
+				}
+				models, err := a.llmClient.ListModels(ctx)
+				return a.finishRun(AgentResponseMsg{Response: AgentResponse{
+					Content: a.formatModelsResult(models, strings.TrimSpace(strings.TrimPrefix(input, "/models")), err),
+					Done:    true,
+				}})
+			}
 			if strings.HasPrefix(input, "/prepare ") {
 				hostname := strings.TrimSpace(strings.TrimPrefix(input, "/prepare "))
 				if hostname == "" {
@@ -406,15 +455,28 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 					return a.finishRun(CompactErrorMsg{Err: err})
 				}
 				return a.finishRun(result)
+			case "/resume":
+				content, err := a.resumeConversation(ctx)
+				if err != nil {
+					return a.finishRun(AgentErrorMsg{Err: err})
+				}
+				return a.finishRun(AgentResponseMsg{Response: AgentResponse{
+					Content: content,
+					Done:    true,
+				}})
 			case "/context":
 				// Show context usage
 				usage := a.GetContextUsage()
-				tokens := a.EstimateTokens()
+				tokens := a.ContextTokens()
+				source := "estimated"
+				if a.TokensMeasured() {
+					source = "reported by provider"
+				}
 				maxTokens := a.cfg.AIAgent.TotalContextTokens
 				threshold := a.cfg.AIAgent.CompactThreshold
 				return a.finishRun(AgentResponseMsg{Response: AgentResponse{
-					Content: fmt.Sprintf("Context usage: %d/%d tokens (%.1f%%)\nAuto-compact threshold: %.0f%%",
-						tokens, maxTokens, usage*100, threshold*100),
+					Content: fmt.Sprintf("Context usage: %d/%d tokens (%.1f%%, %s)\nAuto-compact threshold: %.0f%%",
+						tokens, maxTokens, usage*100, source, threshold*100),
 					Done: true,
 				}})
 			case "/allowlist":
@@ -452,6 +514,8 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 				b.WriteString("- **/allowlist**: Show the read-only command allowlist\n")
 				b.WriteString("- **/compact**: Summarize and compact conversation history\n")
 				b.WriteString("- **/context**: Show current context token usage\n")
+				b.WriteString("- **/resume**: Resume the last saved conversation\n")
+				b.WriteString("- **/models [filter]**: List available models with context size and pricing\n")
 				b.WriteString("- **/settings**: Open configuration settings\n")
 				b.WriteString("- **/clear**: Clear conversation history\n")
 				b.WriteString("- **/help**: Show this help message\n")
@@ -463,7 +527,7 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 				}})
 			default:
 				return a.finishRun(AgentResponseMsg{Response: AgentResponse{
-					Content: fmt.Sprintf("Unknown command: %s. Available: /vms, /sandboxes, /hosts, /playbooks, /prepare, /allowlist, /compact, /context, /settings", input),
+					Content: fmt.Sprintf("Unknown command: %s. Available: /vms, /sandboxes, /hosts, /playbooks, /prepare, /allowlist, /compact, /context, /resume, /models, /settings", input),
 					Done:    true,
 				}})
 			}
@@ -482,7 +546,7 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 
 		// LLM client is required
 		if a.llmClient == nil || a.cfg.AIAgent.APIKey == "" {
-			return a.finishRun(AgentErrorMsg{Err: fmt.Errorf("LLM provider not configured. Please set OPENROUTER_API_KEY environment variable or configure it in /settings")})
+			return a.finishRun(AgentErrorMsg{Err: fmt.Errorf("LLM provider not configured. Please set the API key for ai_agent.provider (e.g. OPENROUTER_API_KEY) or configure it in /settings")})
 		}
 
 		// Check if auto-compaction is needed before making LLM call
@@ -600,10 +664,10 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 
 			// Log LLM request to audit
 			if a.auditLog != nil {
-				a.auditLog.LogLLMRequest(len(req.Messages), a.EstimateTokens(), a.cfg.AIAgent.Model)
+				a.auditLog.LogLLMRequest(len(req.Messages), a.ContextTokens(), a.cfg.AIAgent.Model)
 			}
 
-			resp, err := a.llmClient.Chat(ctx, req)
+			resp, err := a.chat(ctx, req)
 			if err != nil {
 				a.logger.Error("LLM chat failed", "error", err)
 				return a.finishRun(AgentErrorMsg{Err: fmt.Errorf("llm chat: %w", err)})
@@ -618,7 +682,7 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 
 			// Log LLM response to audit
 			if a.auditLog != nil {
-				a.auditLog.LogLLMResponse(len(msg.Content)/4, len(msg.ToolCalls))
+				a.auditLog.LogLLMResponse(completionTokens(resp.Usage, msg), len(msg.ToolCalls))
 			}
 
 			// Restore redacted tokens in LLM response
@@ -644,6 +708,7 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 			}
 
 			a.history = append(a.history, msg)
+			a.recordUsage(resp.Usage)
 
 			if len(msg.ToolCalls) > 0 {
 				a.logger.Debug("LLM response contains tool calls", "tool_count", len(msg.ToolCalls))
@@ -728,6 +793,49 @@ func (a *DeerAgent) Run(input string) tea.Cmd {
 	}
 }
 
+// streamUpdateInterval is the least time between partial responses sent
+// to the TUI while a reply streams in.
+const streamUpdateInterval = 50 * time.Millisecond
+
+// chat sends req to the LLM. When the provider can stream, the reply's text
+// goes to the TUI as partial responses while it arrives; tool calls are
+// only acted on once the whole reply is in. Providers that cannot stream
+// get a plain Chat.
+func (a *DeerAgent) chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	chunks, err := a.llmClient.ChatStream(ctx, req)
+	if errors.Is(err, llm.ErrStreamingUnsupported) {
+		return a.llmClient.Chat(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var acc llm.StreamAccumulator
+	var lastSent time.Time
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+		acc.Add(chunk)
+		// The TUI drops status messages when its queue is full, so partial
+		// updates are rate limited. The response that completes the reply
+		// carries the full text.
+		if chunk.Content == "" || time.Since(lastSent) < streamUpdateInterval {
+			continue
+		}
+		lastSent = time.Now()
+		content := acc.Content()
+		if a.redactor != nil {
+			content = a.redactor.Restore(content)
+		}
+		a.sendStatus(AgentResponseMsg{Response: AgentResponse{Content: content, Partial: true}})
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return acc.Response(), nil
+}
+
 // RunHeadless runs a single prompt through the agent synchronously and returns
 // the final LLM response text. It is the non-interactive equivalent of Run(),
 // with no TUI coupling: no slash commands, no sendStatus calls, no tea.Cmd.
@@ -743,7 +851,7 @@ func (a *DeerAgent) RunHeadless(ctx context.Context, input string) (string, erro
 	}
 
 	if a.llmClient == nil || a.cfg.AIAgent.APIKey == "" {
-		return "", fmt.Errorf("LLM provider not configured - set OPENROUTER_API_KEY or configure in settings")
+		return "", fmt.Errorf("LLM provider not configured - set the API key for ai_agent.provider (e.g. OPENROUTER_API_KEY) or configure in settings")
 	}
 
 	if a.NeedsCompaction() {
@@ -821,7 +929,7 @@ func (a *DeerAgent) RunHeadless(ctx context.Context, input string) (string, erro
 		req := llm.ChatRequest{Messages: messages, Tools: tools}
 
 		if a.auditLog != nil {
-			a.auditLog.LogLLMRequest(len(req.Messages), a.EstimateTokens(), a.cfg.AIAgent.Model)
+			a.auditLog.LogLLMRequest(len(req.Messages), a.ContextTokens(), a.cfg.AIAgent.Model)
 		}
 
 		resp, err := a.llmClient.Chat(ctx, req)
@@ -835,7 +943,7 @@ func (a *DeerAgent) RunHeadless(ctx context.Context, input string) (string, erro
 		msg := resp.Choices[0].Message
 
 		if a.auditLog != nil {
-			a.auditLog.LogLLMResponse(len(msg.Content)/4, len(msg.ToolCalls))
+			a.auditLog.LogLLMResponse(completionTokens(resp.Usage, msg), len(msg.ToolCalls))
 		}
 
 		if a.redactor != nil {
@@ -856,6 +964,7 @@ func (a *DeerAgent) RunHeadless(ctx context.Context, input string) (string, erro
 		}
 
 		a.history = append(a.history, msg)
+		a.recordUsage(resp.Usage)
 
 		if len(msg.ToolCalls) == 0 {
 			return msg.Content, nil
@@ -903,6 +1012,8 @@ func (a *DeerAgent) RunHeadless(ctx context.Context, input string) (string, erro
 
 // executeTool dispatches tool calls to internal methods
 func (a *DeerAgent) executeTool(ctx context.Context, tc llm.ToolCall) (any, error) {
+	ctx = sandbox.WithActor(ctx, sandbox.ActorTUIAgent)
+
 	// Parse args for status message
 	var args map[string]any
 	_ = json.Unmarshal([]byte(tc.Function.Arguments), &args)
@@ -921,6 +1032,10 @@ func (a *DeerAgent) executeTool(ctx context.Context, tc llm.ToolCall) (any, erro
 		})
 	}
 
+	if err := a.checkToolPolicy(ctx, tc.Function.Name, args); err != nil {
+		return nil, err
+	}
+
 	switch tc.Function.Name {
 	case "list_sandboxes":
 		return a.listSandboxes(ctx)
@@ -934,11 +1049,38 @@ func (a *DeerAgent) executeTool(ctx context.Context, tc llm.ToolCall) (any, erro
 			Live                      bool   `json:"live"`
 			SimpleKafkaBroker         bool   `json:"kafka_stub"`
 			SimpleElasticsearchBroker bool   `json:"es_stub"`
+			DryRun                    bool   `json:"dry_run"`
+		}
+		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+			return nil, err
+		}
+		req := sandbox.CreateRequest{
+			SourceVM:                  args.SourceVM,
+			AgentID:                   "tui-agent",
+			VCPUs:                     args.CPU,
+			MemoryMB:                  args.MemoryMB,
+			Live:                      args.Live,
+			SimpleKafkaBroker:         args.SimpleKafkaBroker,
+			SimpleElasticsearchBroker: args.SimpleElasticsearchBroker,
+		}
+		if args.DryRun {
+			return a.planSandbox(ctx, req)
+		}
+		return a.createSandbox(ctx, req)
+	case "create_from_template":
+		a.clearStickyReadOnly()
+		var args struct {
+			Template string `json:"template"`
+			Host     string `json:"host"`
+			CPU      int    `json:"cpu"`
+			MemoryMB int    `json:"memory_mb"`
 		}
 		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
 			return nil, err
 		}
-		return a.createSandbox(ctx, args.SourceVM, args.Host, args.CPU, args.MemoryMB, args.Live, args.SimpleKafkaBroker, args.SimpleElasticsearchBroker)
+		return a.createFromTemplate(ctx, args.Template, args.CPU, args.MemoryMB)
+	case "list_templates":
+		return a.listTemplates(), nil
 	case "destroy_sandbox":
 		a.clearStickyReadOnly()
 		var args struct {
@@ -953,11 +1095,12 @@ func (a *DeerAgent) executeTool(ctx context.Context, tc llm.ToolCall) (any, erro
 		var args struct {
 			SandboxID string `json:"sandbox_id"`
 			Command   string `json:"command"`
+			Cwd       string `json:"cwd"`
 		}
 		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
 			return nil, err
 		}
-		return a.runCommand(ctx, args.SandboxID, args.Command)
+		return a.runCommand(ctx, args.SandboxID, args.Command, args.Cwd)
 	case "start_sandbox":
 		a.clearStickyReadOnly()
 		var args struct {
@@ -1208,10 +1351,15 @@ func (a *DeerAgent) executeTool(ctx context.Context, tc llm.ToolCall) (any, erro
 	}
 }
 
-// Reset clears the conversation history
+// Reset clears the conversation history and starts a new saved session; the
+// previous one stays in the store and can be resumed.
 func (a *DeerAgent) Reset() {
 	a.logger.Debug("conversation reset", "previous_message_count", len(a.history))
 	a.history = make([]llm.Message, 0)
+	a.sessionID = newSessionID()
+	a.compactions = 0
+	a.conversationTitle = ""
+	a.measuredTokens, a.measuredLen = 0, 0
 	if a.taskList != nil {
 		a.taskList.Clear()
 	}
@@ -1219,17 +1367,20 @@ func (a *DeerAgent) Reset() {
 
 // EstimateTokens estimates the token count for the current conversation history
 func (a *DeerAgent) EstimateTokens() int {
+	// Include system prompt
+	return a.estimateChars(len(a.cfg.AIAgent.DefaultSystem), a.history)
+}
+
+// estimateChars converts extra characters plus the characters in msgs into
+// an approximate token count using the configured tokens-per-char ratio.
+func (a *DeerAgent) estimateChars(extra int, msgs []llm.Message) int {
 	tokensPerChar := a.cfg.AIAgent.TokensPerChar
 	if tokensPerChar <= 0 {
 		tokensPerChar = 0.25 // default
 	}
 
-	totalChars := 0
-	// Include system prompt
-	totalChars += len(a.cfg.AIAgent.DefaultSystem)
-
-	// Include all messages
-	for _, msg := range a.history {
+	totalChars := extra
+	for _, msg := range msgs {
 		totalChars += len(msg.Content)
 		// Account for tool calls
 		for _, tc := range msg.ToolCalls {
@@ -1241,13 +1392,50 @@ func (a *DeerAgent) EstimateTokens() int {
 	return int(float64(totalChars) * tokensPerChar)
 }
 
+// ContextTokens returns the token count of the current conversation. When the
+// provider reported usage for the last request, that count is used and only
+// messages appended since (such as tool results) are estimated; otherwise the
+// whole history is estimated.
+func (a *DeerAgent) ContextTokens() int {
+	if a.TokensMeasured() {
+		return a.measuredTokens + a.estimateChars(0, a.history[a.measuredLen:])
+	}
+	return a.EstimateTokens()
+}
+
+// TokensMeasured reports whether ContextTokens is based on provider usage.
+func (a *DeerAgent) TokensMeasured() bool {
+	return a.measuredTokens > 0 && a.measuredLen <= len(a.history)
+}
+
+// recordUsage stores the provider-reported usage for the request that just
+// produced the last history message. A response without usage clears the
+// measurement so the estimate is used instead.
+func (a *DeerAgent) recordUsage(u *llm.Usage) {
+	if u == nil || u.PromptTokens+u.CompletionTokens == 0 {
+		a.measuredTokens, a.measuredLen = 0, 0
+		return
+	}
+	a.measuredTokens = u.PromptTokens + u.CompletionTokens
+	a.measuredLen = len(a.history)
+}
+
+// completionTokens returns the reported completion tokens, or an estimate
+// from the message content when the provider did not report usage.
+func completionTokens(u *llm.Usage, msg llm.Message) int {
+	if u != nil && u.CompletionTokens > 0 {
+		return u.CompletionTokens
+	}
+	return len(msg.Content) / 4
+}
+
 // GetContextUsage returns the current context usage as a percentage
 func (a *DeerAgent) GetContextUsage() float64 {
 	maxTokens := a.cfg.AIAgent.TotalContextTokens
 	if maxTokens <= 0 {
 		maxTokens = 64000
 	}
-	return float64(a.EstimateTokens()) / float64(maxTokens)
+	return float64(a.ContextTokens()) / float64(maxTokens)
 }
 
 // NeedsCompaction returns true if the context is at or above the compaction threshold
@@ -1265,7 +1453,7 @@ func (a *DeerAgent) Compact(ctx context.Context) (CompactCompleteMsg, error) {
 		return CompactCompleteMsg{}, fmt.Errorf("no conversation history to compact")
 	}
 
-	previousTokens := a.EstimateTokens()
+	previousTokens := a.ContextTokens()
 	a.logger.Info("compaction starting", "previous_tokens", previousTokens, "message_count", len(a.history))
 
 	// Build the conversation text for summarization
@@ -1337,6 +1525,9 @@ func (a *DeerAgent) Compact(ctx context.Context) (CompactCompleteMsg, error) {
 			Content: "I understand the context from our previous conversation. I'm ready to continue helping you. What would you like to do next?",
 		},
 	}
+	a.measuredTokens, a.measuredLen = 0, 0
+	a.compactions++
+	a.saveConversation(ctx)
 
 	newTokens := a.EstimateTokens()
 	a.logger.Info("compaction complete", "previous_tokens", previousTokens, "new_tokens", newTokens)
@@ -1404,7 +1595,15 @@ func normalizeVMName(name string) string {
 	return strings.Trim(s, "-")
 }
 
-func (a *DeerAgent) createSandbox(ctx context.Context, sourceVM, hostName string, cpu, memoryMB int, live bool, simpleKafkaBroker bool, simpleElasticsearchBroker bool) (map[string]any, error) {
+// createSandbox creates a sandbox from req, streaming progress to the TUI.
+// The source VM name is resolved against list_vms first so a near-miss
+// ("Ubuntu 22.04" for "ubuntu-22.04") still works. AgentID defaults to
+// "tui-agent".
+func (a *DeerAgent) createSandbox(ctx context.Context, req sandbox.CreateRequest) (map[string]any, error) {
+	if req.AgentID == "" {
+		req.AgentID = "tui-agent"
+	}
+	sourceVM := req.SourceVM
 	if sourceVM == "" {
 		return nil, fmt.Errorf("source-vm is required - call list_vms first to see available VM images for cloning")
 	}
@@ -1430,20 +1629,17 @@ func (a *DeerAgent) createSandbox(ctx context.Context, sourceVM, hostName string
 		}
 		sourceVM = resolvedName
 	}
+	req.SourceVM = sourceVM
+
+	if err := a.checkSandboxResources(ctx, req); err != nil {
+		return nil, err
+	}
 
-	a.logger.Info("sandbox creation attempt", "source_vm", sourceVM, "cpu", cpu, "memory_mb", memoryMB, "live", live, "kafka_stub", simpleKafkaBroker, "es_stub", simpleElasticsearchBroker)
+	a.logger.Info("sandbox creation attempt", "source_vm", sourceVM, "cpu", req.VCPUs, "memory_mb", req.MemoryMB, "live", req.Live, "kafka_stub", req.SimpleKafkaBroker, "es_stub", req.SimpleElasticsearchBroker)
 	lastStepNum := 0
 	lastTotal := 0
 
-	sb, err := a.service.CreateSandboxStream(ctx, sandbox.CreateRequest{
-		SourceVM:                  sourceVM,
-		AgentID:                   "tui-agent",
-		VCPUs:                     cpu,
-		MemoryMB:                  memoryMB,
-		Live:                      live,
-		SimpleKafkaBroker:         simpleKafkaBroker,
-		SimpleElasticsearchBroker: simpleElasticsearchBroker,
-	}, func(step string, stepNum, total int) {
+	sb, err := a.service.CreateSandboxStream(ctx, req, func(step string, stepNum, total int) {
 		lastStepNum = stepNum
 		lastTotal = total
 		a.sendStatus(SandboxCreateProgressMsg{
@@ -1494,18 +1690,168 @@ func (a *DeerAgent) createSandbox(ctx context.Context, sourceVM, hostName string
 	return result, nil
 }
 
-// HandleApprovalResponse handles the response from the memory approval dialog
-func (a *DeerAgent) HandleApprovalResponse(approved bool) {
-	// No-op in remote mode - daemon handles resource checking
-	a.logger.Debug("memory approval response (no-op in remote mode)", "approved", approved)
+// sandboxPlanner is implemented by sandbox services that can report what a
+// create would do without doing it (the daemon-backed RemoteService).
+type sandboxPlanner interface {
+	PlanSandbox(ctx context.Context, req sandbox.CreateRequest) (*sandbox.SandboxPlan, error)
 }
 
-// HandleNetworkApprovalResponse handles the response from the network approval dialog
-func (a *DeerAgent) HandleNetworkApprovalResponse(approved bool) {
-	a.logger.Info("network approval response", "approved", approved)
-	if a.pendingNetworkApproval != nil && a.pendingNetworkApproval.ResponseChan != nil {
-		a.pendingNetworkApproval.ResponseChan <- approved
+// planSandbox answers create_sandbox with dry_run set: the daemon works out
+// the sandbox ID, source host, and resources, and nothing is created.
+func (a *DeerAgent) planSandbox(ctx context.Context, req sandbox.CreateRequest) (map[string]any, error) {
+	if req.SourceVM == "" {
+		return nil, fmt.Errorf("source-vm is required - call list_vms first to see available VM images for cloning")
+	}
+	planner, ok := a.service.(sandboxPlanner)
+	if !ok {
+		return nil, fmt.Errorf("dry runs need a sandbox host")
+	}
+	plan, err := planner.PlanSandbox(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{
+		"dry_run":           true,
+		"sandbox_id":        plan.SandboxID,
+		"host":              plan.Hostname,
+		"source_vm":         plan.SourceVM,
+		"vcpus":             plan.VCPUs,
+		"memory_mb":         plan.MemoryMB,
+		"requires_approval": plan.RequiresApproval,
 	}
+	if plan.SourceHost != "" {
+		result["source_host"] = plan.SourceHost
+	}
+	if len(plan.Warnings) > 0 {
+		result["warnings"] = plan.Warnings
+	}
+	return result, nil
+}
+
+// checkSandboxResources plans req on the daemon and, when its memory is
+// beyond the overcommit resources.memory_overcommit_ratio allows, asks the
+// human before the create goes ahead. Services that cannot plan, and plans
+// that fail, leave the decision to the daemon.
+func (a *DeerAgent) checkSandboxResources(ctx context.Context, req sandbox.CreateRequest) error {
+	planner, ok := a.service.(sandboxPlanner)
+	if !ok {
+		return nil
+	}
+	plan, err := planner.PlanSandbox(ctx, req)
+	if err != nil {
+		a.logger.Warn("sandbox plan failed, skipping resource check", "source_vm", req.SourceVM, "error", err)
+		return nil
+	}
+
+	check := sandbox.CheckResourcesForSandbox(plan, a.cfg.Resources.MemoryOvercommitRatio)
+	if !check.NeedsApproval {
+		for _, w := range check.Warnings {
+			a.logger.Info("sandbox memory overcommit auto-approved", "source_vm", req.SourceVM, "warning", w)
+		}
+		return nil
+	}
+
+	request := MemoryApprovalRequest{
+		SourceVM:          req.SourceVM,
+		HostName:          plan.Hostname,
+		RequiredMemoryMB:  check.RequiredMemoryMB,
+		AvailableMemoryMB: check.AvailableMemoryMB,
+		Warnings:          check.Warnings,
+	}
+	a.logger.Warn("requesting memory approval", "source_vm", req.SourceVM, "memory_mb", check.RequiredMemoryMB, "available_memory_mb", check.AvailableMemoryMB)
+
+	details := map[string]any{
+		"source_vm":           req.SourceVM,
+		"memory_mb":           check.RequiredMemoryMB,
+		"available_memory_mb": check.AvailableMemoryMB,
+	}
+	approved, err := a.awaitApproval(ctx, ApprovalMemory, details, request)
+	if err != nil {
+		return fmt.Errorf("memory approval cancelled: %w", err)
+	}
+	a.logger.Info("memory approval result", "approved", approved, "source_vm", req.SourceVM)
+	if a.auditLog != nil {
+		a.auditLog.LogApproval("create_sandbox", string(ApprovalMemory), details, approved)
+	}
+	if !approved {
+		return fmt.Errorf("sandbox creation denied by user: %d MB of memory requested but only %d MB is free", check.RequiredMemoryMB, check.AvailableMemoryMB)
+	}
+	return nil
+}
+
+// awaitApproval asks the human a yes/no question of the given kind and
+// waits for the answer given through HandleApprovalResponse, or for ctx to
+// end. request is the dialog's request for kind; see ApprovalRequestMsg.
+func (a *DeerAgent) awaitApproval(ctx context.Context, kind ApprovalKind, details map[string]any, request any) (bool, error) {
+	responseChan := make(chan bool, 1)
+	a.mu.Lock()
+	a.pendingApproval = &PendingApproval{
+		Kind:         kind,
+		Details:      details,
+		ResponseChan: responseChan,
+	}
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.pendingApproval = nil
+		a.mu.Unlock()
+	}()
+	a.sendStatus(ApprovalRequestMsg{Kind: kind, Details: details, Request: request})
+
+	select {
+	case approved := <-responseChan:
+		return approved, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// HandleApprovalResponse delivers the human's answer to the pending
+// approval. An answer for a different kind than the one pending, or with
+// none pending, is dropped.
+func (a *DeerAgent) HandleApprovalResponse(kind ApprovalKind, approved bool) {
+	a.logger.Info("approval response", "kind", kind, "approved", approved)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pendingApproval == nil || a.pendingApproval.Kind != kind {
+		a.logger.Warn("approval response with no matching request", "kind", kind)
+		return
+	}
+	select {
+	case a.pendingApproval.ResponseChan <- approved:
+	default:
+	}
+}
+
+// checkToolPolicy applies the tool_approval policy to a tool call before it
+// is dispatched. deny and a refused confirm come back as errors the model
+// sees as the tool's result.
+func (a *DeerAgent) checkToolPolicy(ctx context.Context, tool string, args map[string]any) error {
+	var policy config.ToolApprovalPolicy
+	if a.cfg != nil {
+		policy = a.cfg.ToolApproval
+	}
+	switch policy.Decision(tool) {
+	case config.ToolApprovalAuto:
+		return nil
+	case config.ToolApprovalDeny:
+		a.logger.Info("tool denied by policy", "tool", tool)
+		return fmt.Errorf("%s is not allowed by the tool_approval policy; do not retry it", tool)
+	}
+
+	a.logger.Warn("requesting tool approval", "tool", tool)
+	approved, err := a.awaitApproval(ctx, ApprovalTool, args, ToolApprovalRequest{Tool: tool, Args: args})
+	if err != nil {
+		return fmt.Errorf("%s approval cancelled: %w", tool, err)
+	}
+	a.logger.Info("tool approval result", "tool", tool, "approved", approved)
+	if a.auditLog != nil {
+		a.auditLog.LogApproval(tool, string(ApprovalTool), args, approved)
+	}
+	if !approved {
+		return fmt.Errorf("%s denied by user", tool)
+	}
+	return nil
 }
 
 // HandleSourceAccessResponse handles the response from the source command elevation dialog
@@ -1677,7 +2023,7 @@ func (a *DeerAgent) runPrepareInline(ctx context.Context, hostname string) tea.M
 	sshRun := readonly.SSHRunFunc(sshRunFn)
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
-	_, err = readonly.PrepareWithKey(ctx, sshRun, pubKey, nil, logger)
+	prepResult, err := readonly.PrepareWithKey(ctx, sshRun, pubKey, nil, logger)
 	if err != nil {
 		return a.finishRun(AgentResponseMsg{Response: AgentResponse{
 			Content: fmt.Sprintf("Preparation failed for %s: %v", hostname, err),
@@ -1691,6 +2037,11 @@ func (a *DeerAgent) runPrepareInline(ctx context.Context, hostname string) tea.M
 	if err := source.SavePreparedHost(a.cfg, configPath, hostname, resolved); err != nil {
 		a.logger.Warn("failed to save config after prepare", "error", err)
 	}
+	if a.store != nil {
+		if err := source.RecordPreparedHost(ctx, a.store, hostname, resolved.Hostname, pubKey, prepResult); err != nil {
+			a.logger.Warn("failed to record source key fingerprint", "host", hostname, "error", err)
+		}
+	}
 	a.sendStatus(SourcePrepareProgressMsg{SourceVM: hostname, StepName: "Saving config", StepNum: 4, Total: totalSteps, Done: true})
 
 	// 5. Deploy daemon identity key if available
@@ -1730,7 +2081,7 @@ func (a *DeerAgent) destroySandbox(ctx context.Context, id string) (map[string]a
 	}, nil
 }
 
-func (a *DeerAgent) runCommand(ctx context.Context, sandboxID, command string) (map[string]any, error) {
+func (a *DeerAgent) runCommand(ctx context.Context, sandboxID, command, cwd string) (map[string]any, error) {
 	truncCmd := command
 	if len(truncCmd) > 120 {
 		truncCmd = truncCmd[:120] + "..."
@@ -1748,41 +2099,57 @@ func (a *DeerAgent) runCommand(ctx context.Context, sandboxID, command string) (
 	}
 
 	// Check if command requires network access and request approval
-	networkTool, urls := detectNetworkAccess(command)
-	if networkTool != "" {
-		a.logger.Warn("network access detected, requesting approval", "tool", networkTool, "urls", urls, "sandbox_id", sandboxID)
+	networkTool, urls := detectNetworkAccess(command, a.cfg.NetworkPolicy.Tools...)
+	decision, deniedHost := evaluateNetworkPolicy(a.cfg.NetworkPolicy, networkTargets(command, a.cfg.NetworkPolicy.Tools...))
+	if networkTool != "" && decision == networkDeny {
+		a.logger.Warn("network access denied by policy", "tool", networkTool, "host", deniedHost, "sandbox_id", sandboxID)
+		return map[string]any{
+			"sandbox_id": sandboxID,
+			"error":      fmt.Sprintf("network access to %s denied by network_policy.deny_hosts", deniedHost),
+			"exit_code":  -1,
+		}, nil
+	}
+	if networkTool != "" && decision == networkAllow {
+		a.logger.Info("network access allowed by policy", "tool", networkTool, "urls", urls, "sandbox_id", sandboxID)
+	}
+	// With --require-approval every command is asked about, and that one
+	// question covers any network access too.
+	if a.requireApproval || (networkTool != "" && decision == networkPrompt) {
 		request := NetworkApprovalRequest{
-			Command:     command,
-			SandboxID:   sandboxID,
-			NetworkTool: networkTool,
-			URLs:        urls,
+			Command:         command,
+			SandboxID:       sandboxID,
+			NetworkTool:     networkTool,
+			URLs:            urls,
+			RequireApproval: a.requireApproval,
 		}
-
-		responseChan := make(chan bool, 1)
-		a.pendingNetworkApproval = &PendingNetworkApproval{
-			Request:      request,
-			ResponseChan: responseChan,
+		kind := ApprovalNetwork
+		if a.requireApproval {
+			kind = ApprovalCommand
 		}
-		a.sendStatus(NetworkApprovalRequestMsg{Request: request})
+		a.logger.Warn("requesting command approval", "kind", kind, "tool", networkTool, "urls", urls, "sandbox_id", sandboxID)
 
-		var approved bool
-		select {
-		case approved = <-responseChan:
-		case <-ctx.Done():
-			a.pendingNetworkApproval = nil
+		details := map[string]any{"sandbox_id": sandboxID, "command": command}
+		approved, err := a.awaitApproval(ctx, kind, details, request)
+		if err != nil {
 			return map[string]any{
 				"sandbox_id": sandboxID,
-				"error":      "network approval cancelled: context deadline exceeded",
+				"error":      fmt.Sprintf("%s approval cancelled: %v", kind, err),
 				"exit_code":  -1,
 			}, nil
 		}
-		a.pendingNetworkApproval = nil
-		a.logger.Info("network approval result", "approved", approved, "tool", networkTool, "sandbox_id", sandboxID)
+		a.logger.Info("command approval result", "kind", kind, "approved", approved, "tool", networkTool, "sandbox_id", sandboxID)
+		if a.auditLog != nil {
+			a.auditLog.LogApproval("run_command", string(kind), details, approved)
+		}
 
 		if !approved {
+			msg := "network access denied by user"
+			if a.requireApproval {
+				msg = "command denied by user"
+			}
 			return map[string]any{
 				"sandbox_id": sandboxID,
-				"error":      "network access denied by user",
+				"error":      msg,
 				"exit_code":  -1,
 			}, nil
 		}
@@ -1790,7 +2157,19 @@ func (a *DeerAgent) runCommand(ctx context.Context, sandboxID, command string) (
 
 	a.sendStatus(CommandOutputStartMsg{SandboxID: sandboxID})
 
-	result, err := a.service.RunCommand(ctx, sandboxID, command, 0, nil)
+	// Services that stream show output as it arrives; the rest show it once
+	// the command finishes.
+	streamer, streaming := a.service.(commandStreamer)
+	var result *sandbox.CommandResult
+	var err error
+	if streaming {
+		result, err = streamer.RunCommandStreaming(ctx, sandboxID, command, sandbox.RunOptions{Workdir: cwd}, func(chunk string, isStderr bool) {
+			redacted, _ := a.redactContent(chunk)
+			a.sendStatus(CommandOutputChunkMsg{SandboxID: sandboxID, IsStderr: isStderr, Chunk: redacted})
+		})
+	} else {
+		result, err = a.service.RunCommandWithOptions(ctx, sandboxID, command, sandbox.RunOptions{Workdir: cwd})
+	}
 	if err != nil {
 		a.logger.Error("command execution failed", "sandbox_id", sandboxID, "error", err)
 		a.sendStatus(CommandOutputDoneMsg{SandboxID: sandboxID})
@@ -1818,10 +2197,10 @@ func (a *DeerAgent) runCommand(ctx context.Context, sandboxID, command string) (
 	}
 
 	// Show output in live output box
-	if stdout != "" {
+	if stdout != "" && !streaming {
 		a.sendStatus(CommandOutputChunkMsg{SandboxID: sandboxID, Chunk: stdout})
 	}
-	if stderr != "" {
+	if stderr != "" && !streaming {
 		a.sendStatus(CommandOutputChunkMsg{SandboxID: sandboxID, IsStderr: true, Chunk: stderr})
 	}
 	a.sendStatus(CommandOutputDoneMsg{SandboxID: sandboxID})
@@ -1834,59 +2213,6 @@ func (a *DeerAgent) runCommand(ctx context.Context, sandboxID, command string) (
 	}, nil
 }
 
-// detectNetworkAccess checks if a command uses network tools and extracts URLs
-// Returns the network tool name (empty if none) and any URLs found
-func detectNetworkAccess(command string) (string, []string) {
-	// Network tools that require approval
-	networkTools := []string{"curl", "wget", "nc", "netcat", "ssh", "scp", "rsync", "ftp", "sftp", "telnet", "nmap", "ping"}
-
-	cmdLower := strings.ToLower(command)
-	var detectedTool string
-
-	for _, tool := range networkTools {
-		// Check if the tool appears as a command (not part of another word)
-		// Look for tool at start, after pipe, after &&, after ;, or after whitespace
-		patterns := []string{
-			tool + " ",   // tool at start or after space
-			"|" + tool,   // after pipe
-			"| " + tool,  // after pipe with space
-			"&&" + tool,  // after &&
-			"&& " + tool, // after && with space
-			";" + tool,   // after ;
-			"; " + tool,  // after ; with space
-			"$(" + tool,  // in subshell
-			"`" + tool,   // in backticks
-		}
-
-		for _, pattern := range patterns {
-			if strings.Contains(cmdLower, pattern) || strings.HasPrefix(cmdLower, tool+" ") || cmdLower == tool {
-				detectedTool = tool
-				break
-			}
-		}
-		if detectedTool != "" {
-			break
-		}
-	}
-
-	if detectedTool == "" {
-		return "", nil
-	}
-
-	// Extract URLs from the command
-	var urls []string
-	// Simple URL pattern matching
-	words := strings.Fields(command)
-	for _, word := range words {
-		if strings.HasPrefix(word, "http://") || strings.HasPrefix(word, "https://") ||
-			strings.HasPrefix(word, "ftp://") || strings.HasPrefix(word, "sftp://") {
-			urls = append(urls, word)
-		}
-	}
-
-	return detectedTool, urls
-}
-
 // editFile edits a file on a sandbox by replacing old_str with new_str, or creates the file if old_str is empty.
 // This operates on files inside the sandbox VM via SSH.
 func (a *DeerAgent) editFile(ctx context.Context, sandboxID, path, oldStr, newStr string) (map[string]any, error) {
@@ -1980,6 +2306,12 @@ func (a *DeerAgent) editFile(ctx context.Context, sandboxID, path, oldStr, newSt
 	}, nil
 }
 
+// commandStreamer is implemented by sandbox services that can stream
+// command output (the daemon-backed RemoteService).
+type commandStreamer interface {
+	RunCommandStreaming(ctx context.Context, sandboxID, command string, opts sandbox.RunOptions, onOutput sandbox.OutputCallback) (*sandbox.CommandResult, error)
+}
+
 // redactContent runs the Redactor on content and returns whether any redaction occurred.
 // If the redactor is nil (redaction disabled), content passes through unchanged.
 func (a *DeerAgent) redactContent(content string) (string, bool) {
@@ -2187,6 +2519,15 @@ func (a *DeerAgent) getSandbox(ctx context.Context, id string) (map[string]any,
 	if sb.IPAddress != "" {
 		result["ip"] = sb.IPAddress
 	}
+	if d := sb.Disk; d != nil {
+		// Lets the caller spot "No space left on device" as the root cause.
+		result["disk"] = map[string]any{
+			"total_bytes":     d.TotalBytes,
+			"available_bytes": d.AvailableBytes,
+			"used_percent":    int(d.UsedPercent() + 0.5),
+			"nearly_full":     d.NearlyFull(),
+		}
+	}
 
 	return result, nil
 }
@@ -2217,6 +2558,58 @@ func (a *DeerAgent) listVMs(ctx context.Context) (map[string]any, error) {
 	}, nil
 }
 
+func (a *DeerAgent) listTemplates() map[string]any {
+	var templates []config.SandboxTemplate
+	if a.cfg != nil {
+		templates = a.cfg.Templates
+	}
+
+	result := make([]map[string]any, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, map[string]any{
+			"name":       t.Name,
+			"source_vm":  t.SourceVM,
+			"cpu":        t.CPU,
+			"memory_mb":  t.MemoryMB,
+			"live":       t.Live,
+			"kafka_stub": t.KafkaStub,
+			"es_stub":    t.ESStub,
+		})
+	}
+
+	return map[string]any{
+		"templates": result,
+		"count":     len(result),
+	}
+}
+
+// createFromTemplate creates a sandbox from a named template. Non-zero cpu or
+// memoryMB override the template's values.
+func (a *DeerAgent) createFromTemplate(ctx context.Context, name string, cpu, memoryMB int) (map[string]any, error) {
+	var tmpl config.SandboxTemplate
+	var ok bool
+	if a.cfg != nil {
+		tmpl, ok = a.cfg.Template(name)
+	}
+	if !ok {
+		return nil, fmt.Errorf("template %q not found - call list_templates to see available templates", name)
+	}
+	if cpu <= 0 {
+		cpu = tmpl.CPU
+	}
+	if memoryMB <= 0 {
+		memoryMB = tmpl.MemoryMB
+	}
+	return a.createSandbox(ctx, sandbox.CreateRequest{
+		SourceVM:                  tmpl.SourceVM,
+		VCPUs:                     cpu,
+		MemoryMB:                  memoryMB,
+		Live:                      tmpl.Live,
+		SimpleKafkaBroker:         tmpl.KafkaStub,
+		SimpleElasticsearchBroker: tmpl.ESStub,
+	})
+}
+
 func (a *DeerAgent) createSnapshot(ctx context.Context, sandboxID, name string) (map[string]any, error) {
 	if name == "" {
 		name = fmt.Sprintf("snap-%d", time.Now().Unix())
@@ -2472,6 +2865,35 @@ func (a *DeerAgent) formatHostsResult(result map[string]any, err error) string {
 	return b.String()
 }
 
+// formatModelsResult lists models whose ID contains filter, with their
+// context window and price per million tokens.
+func (a *DeerAgent) formatModelsResult(models []llm.Model, filter string, err error) string {
+	if err != nil {
+		return fmt.Sprintf("Failed to list models: %v", err)
+	}
+	var b strings.Builder
+	matched := 0
+	for _, m := range models {
+		if filter != "" && !strings.Contains(strings.ToLower(m.ID), strings.ToLower(filter)) {
+			continue
+		}
+		matched++
+		price := "free"
+		if m.PromptPrice > 0 || m.CompletionPrice > 0 {
+			price = fmt.Sprintf("$%.2f/M in, $%.2f/M out", m.PromptPrice*1e6, m.CompletionPrice*1e6)
+		}
+		fmt.Fprintf(&b, "- `%s` - %dk context, %s\n", m.ID, m.ContextLength/1000, price)
+	}
+	if matched == 0 {
+		if filter != "" {
+			return fmt.Sprintf("No models match %q.", filter)
+		}
+		return "No models available."
+	}
+	header := fmt.Sprintf("**Models (%d):**\n\nCurrent: `%s`, compaction: `%s`\n\n", matched, a.cfg.AIAgent.Model, a.cfg.AIAgent.CompactModel)
+	return header + b.String() + "\nSet `model` or `compact_model` in `/settings`."
+}
+
 func (a *DeerAgent) listPlaybooks(ctx context.Context) (map[string]any, error) {
 	playbooks, err := a.playbookService.ListPlaybooks(ctx, nil)
 	if err != nil {
@@ -2534,6 +2956,9 @@ func (a *DeerAgent) runSourceCommand(ctx context.Context, sourceVM, command stri
 	}
 	a.logger.Debug("run source command", "source_vm", sourceVM, "command", truncCmd)
 
+	if err := source.ReadonlyPolicy(a.cfg).ValidateCommand(command); err != nil {
+		return nil, fmt.Errorf("command not allowed: %w", err)
+	}
 	result, err := a.service.RunSourceCommand(ctx, sourceVM, command, 0)
 	if err != nil {
 		a.logger.Error("source command failed", "source_vm", sourceVM, "error", err)
@@ -2617,10 +3042,11 @@ func (a *DeerAgent) Cleanup(ctx context.Context) error {
 
 	a.logger.Info("cleanup starting", "sandbox_count", len(a.createdSandboxes))
 
+	live := a.liveSandboxes(ctx, a.createdSandboxes)
 	var errs []error
 	for _, id := range a.createdSandboxes {
 		// Check if sandbox still exists before destroying
-		if _, err := a.service.GetSandbox(ctx, id); err != nil {
+		if !a.sandboxExists(ctx, live, id) {
 			// Sandbox no longer exists (already destroyed by user), skip
 			continue
 		}
@@ -2636,8 +3062,8 @@ func (a *DeerAgent) Cleanup(ctx context.Context) error {
 	a.createdSandboxes = nil
 
 	a.logger.Info("cleanup complete", "errors", len(errs))
-	if len(errs) > 0 {
-		return fmt.Errorf("cleanup errors: %v", errs)
+	if err := multierr.Combine(errs...); err != nil {
+		return fmt.Errorf("cleanup: %w", err)
 	}
 	return nil
 }
@@ -2659,63 +3085,126 @@ func (a *DeerAgent) ClearCreatedSandboxes() {
 	a.createdSandboxes = nil
 }
 
+// liveSandboxes returns which of ids still exist, so a cleanup checks its
+// sandboxes with one bulk lookup instead of one call per sandbox. It returns
+// nil if the lookup fails.
+func (a *DeerAgent) liveSandboxes(ctx context.Context, ids []string) map[string]bool {
+	sandboxes, _, err := a.service.GetSandboxes(ctx, ids)
+	if err != nil {
+		a.logger.Debug("cleanup: bulk sandbox lookup failed, checking each sandbox", "error", err)
+		return nil
+	}
+	live := make(map[string]bool, len(sandboxes))
+	for _, sb := range sandboxes {
+		live[sb.ID] = true
+	}
+	return live
+}
+
+// sandboxExists looks id up in live, falling back to asking the service
+// when the bulk lookup was unavailable.
+func (a *DeerAgent) sandboxExists(ctx context.Context, live map[string]bool, id string) bool {
+	if live != nil {
+		return live[id]
+	}
+	_, err := a.service.GetSandbox(ctx, id)
+	return err == nil
+}
+
+// defaultCleanupConcurrency is how many sandboxes CleanupWithProgress destroys
+// at once when ai_agent.cleanup_concurrency is unset.
+const defaultCleanupConcurrency = 4
+
 // CleanupWithProgress destroys all sandboxes, sending progress updates through the status callback.
-// Each sandbox gets its own 60-second timeout to avoid one slow destroy blocking others.
+// Up to ai_agent.cleanup_concurrency sandboxes are destroyed in parallel, and each gets its own
+// 60-second timeout to avoid one slow destroy blocking others.
 func (a *DeerAgent) CleanupWithProgress(sandboxIDs []string) {
 	total := len(sandboxIDs)
-	a.logger.Info("cleanup with progress starting", "total", total)
-	destroyed := 0
-	failed := 0
-	skipped := 0
+	workers := defaultCleanupConcurrency
+	if a.cfg != nil && a.cfg.AIAgent.CleanupConcurrency > 0 {
+		workers = a.cfg.AIAgent.CleanupConcurrency
+	}
+	if workers > total {
+		workers = total
+	}
+	a.logger.Info("cleanup with progress starting", "total", total, "concurrency", workers)
 
 	// Per-sandbox timeout - 60s should be enough for remote hosts
 	const perSandboxTimeout = 60 * time.Second
 
-	for _, id := range sandboxIDs {
-		// Send progress: destroying
+	lookupCtx, lookupCancel := context.WithTimeout(context.Background(), perSandboxTimeout)
+	live := a.liveSandboxes(lookupCtx, sandboxIDs)
+	lookupCancel()
+
+	var (
+		mu                         sync.Mutex
+		destroyed, failed, skipped int
+		errs                       []error
+	)
+	count := func(n *int) {
+		mu.Lock()
+		*n++
+		mu.Unlock()
+	}
+
+	destroyOne := func(id string) {
 		a.sendStatus(CleanupProgressMsg{
 			SandboxID: id,
 			Status:    CleanupStatusDestroying,
 		})
 
-		// Small delay to let the UI update
-		time.Sleep(50 * time.Millisecond)
-
 		// Create a fresh context for each sandbox destruction
 		ctx, cancel := context.WithTimeout(context.Background(), perSandboxTimeout)
+		defer cancel()
 
 		// Check if sandbox still exists
-		if _, err := a.service.GetSandbox(ctx, id); err != nil {
-			// Already destroyed
-			cancel()
-			skipped++
+		if !a.sandboxExists(ctx, live, id) {
+			count(&skipped)
 			a.logger.Debug("cleanup: sandbox already gone", "sandbox_id", id)
 			a.sendStatus(CleanupProgressMsg{
 				SandboxID: id,
 				Status:    CleanupStatusSkipped,
 			})
-			continue
+			return
 		}
 
-		// Destroy the sandbox
 		if err := a.service.DestroySandbox(ctx, id); err != nil {
-			failed++
+			count(&failed)
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("destroy sandbox %s: %w", id, err))
+			mu.Unlock()
 			a.logger.Warn("cleanup: failed to destroy sandbox", "sandbox_id", id, "error", err)
 			a.sendStatus(CleanupProgressMsg{
 				SandboxID: id,
 				Status:    CleanupStatusFailed,
 				Error:     err.Error(),
 			})
-		} else {
-			destroyed++
-			a.logger.Debug("cleanup: sandbox destroyed", "sandbox_id", id)
-			a.sendStatus(CleanupProgressMsg{
-				SandboxID: id,
-				Status:    CleanupStatusDestroyed,
-			})
+			return
 		}
-		cancel()
+		count(&destroyed)
+		a.logger.Debug("cleanup: sandbox destroyed", "sandbox_id", id)
+		a.sendStatus(CleanupProgressMsg{
+			SandboxID: id,
+			Status:    CleanupStatusDestroyed,
+		})
+	}
+
+	ids := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				destroyOne(id)
+			}
+		}()
+	}
+	for _, id := range sandboxIDs {
+		ids <- id
 	}
+	close(ids)
+	wg.Wait()
 
 	// Clear the created sandboxes list
 	a.createdSandboxes = nil
@@ -2728,6 +3217,7 @@ func (a *DeerAgent) CleanupWithProgress(sandboxIDs []string) {
 		Destroyed: destroyed,
 		Failed:    failed,
 		Skipped:   skipped,
+		Err:       multierr.Combine(errs...),
 	})
 }
 
@@ -4,14 +4,19 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/aspectrr/deer.sh/deer-cli/internal/audit"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/hostexec"
 	"github.com/aspectrr/deer.sh/deer-cli/internal/llm"
@@ -24,6 +29,7 @@ import (
 // stubService is a minimal sandbox.Service for testing SetSandboxService.
 type stubService struct {
 	closed                bool
+	ran                   []string
 	createSandboxStreamFn func(context.Context, sandbox.CreateRequest, func(string, int, int)) (*sandbox.SandboxInfo, error)
 }
 
@@ -38,14 +44,28 @@ func (s *stubService) GetSandbox(context.Context, string) (*sandbox.SandboxInfo,
 func (s *stubService) ListSandboxes(context.Context) ([]*sandbox.SandboxInfo, error) {
 	return nil, nil
 }
+func (s *stubService) GetSandboxes(context.Context, []string) ([]*sandbox.SandboxInfo, []string, error) {
+	return nil, nil, nil
+}
 func (s *stubService) DestroySandbox(context.Context, string) error { return nil }
 func (s *stubService) StartSandbox(context.Context, string) (*sandbox.SandboxInfo, error) {
 	return nil, nil
 }
 func (s *stubService) StopSandbox(context.Context, string, bool) error { return nil }
+func (s *stubService) ImportSandbox(context.Context, string, string) (*sandbox.SandboxInfo, error) {
+	return nil, nil
+}
 func (s *stubService) RunCommand(context.Context, string, string, int, map[string]string) (*sandbox.CommandResult, error) {
 	return nil, nil
 }
+func (s *stubService) RunCommandWithOptions(_ context.Context, sandboxID, command string, _ sandbox.RunOptions) (*sandbox.CommandResult, error) {
+	s.ran = append(s.ran, command)
+	return &sandbox.CommandResult{SandboxID: sandboxID}, nil
+}
+
+func (s *stubService) ListSandboxCommands(context.Context, string, int, string) ([]*sandbox.CommandRecord, error) {
+	return nil, nil
+}
 
 func (s *stubService) CreateSnapshot(context.Context, string, string) (*sandbox.SnapshotInfo, error) {
 	return nil, nil
@@ -79,6 +99,9 @@ func (s *stubService) CreateSandboxStream(_ context.Context, req sandbox.CreateR
 }
 func (s *stubService) GetHostInfo(context.Context) (*sandbox.HostInfo, error) { return nil, nil }
 func (s *stubService) Health(context.Context) error                           { return nil }
+func (s *stubService) GetStatus(context.Context) (*sandbox.DaemonStatus, error) {
+	return nil, nil
+}
 func (s *stubService) DoctorCheck(context.Context) ([]sandbox.DoctorCheckResult, error) {
 	return nil, nil
 }
@@ -250,7 +273,7 @@ func TestCreateSandbox_SendsDoneProgressOnSuccess(t *testing.T) {
 		statuses = append(statuses, msg)
 	})
 
-	result, err := agent.createSandbox(context.Background(), "ubuntu", "", 2, 2048, true, false, false)
+	result, err := agent.createSandbox(context.Background(), sandbox.CreateRequest{SourceVM: "ubuntu", VCPUs: 2, MemoryMB: 2048, Live: true})
 	if err != nil {
 		t.Fatalf("createSandbox returned error: %v", err)
 	}
@@ -273,6 +296,81 @@ func TestCreateSandbox_SendsDoneProgressOnSuccess(t *testing.T) {
 	}
 }
 
+// planningService is a stubService that can also plan creates.
+type planningService struct {
+	stubService
+	planned     []sandbox.CreateRequest
+	availableMB int64
+}
+
+func (s *planningService) PlanSandbox(_ context.Context, req sandbox.CreateRequest) (*sandbox.SandboxPlan, error) {
+	s.planned = append(s.planned, req)
+	return &sandbox.SandboxPlan{SandboxID: "sbx-planned", SourceVM: req.SourceVM, VCPUs: 2, MemoryMB: 2048, AvailableMemoryMB: s.availableMB, Warnings: []string{"low memory"}}, nil
+}
+
+func TestCreateSandbox_MemoryOvercommit(t *testing.T) {
+	created := 0
+	svc := &planningService{availableMB: 1800}
+	svc.createSandboxStreamFn = func(context.Context, sandbox.CreateRequest, func(string, int, int)) (*sandbox.SandboxInfo, error) {
+		created++
+		return &sandbox.SandboxInfo{ID: "SBX-1"}, nil
+	}
+	agent := &DeerAgent{
+		cfg:     &config.Config{Resources: config.ResourcesConfig{MemoryOvercommitRatio: 1.2}},
+		service: svc,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	var asked []MemoryApprovalRequest
+	agent.SetStatusCallback(func(msg tea.Msg) {
+		if req, ok := msg.(ApprovalRequestMsg); ok {
+			asked = append(asked, req.Request.(MemoryApprovalRequest))
+			agent.HandleApprovalResponse(req.Kind, false)
+		}
+	})
+
+	// 2048 MB fits in 1.2x the 1800 MB free, so nobody is asked.
+	if _, err := agent.createSandbox(context.Background(), sandbox.CreateRequest{SourceVM: "ubuntu"}); err != nil {
+		t.Fatalf("createSandbox within overcommit: %v", err)
+	}
+	if len(asked) != 0 || created != 1 {
+		t.Fatalf("within overcommit: asked %+v, created %d", asked, created)
+	}
+
+	// At 1.0 the same request is asked about, and a "no" stops the create.
+	agent.cfg.Resources.MemoryOvercommitRatio = 1.0
+	if _, err := agent.createSandbox(context.Background(), sandbox.CreateRequest{SourceVM: "ubuntu"}); err == nil {
+		t.Fatal("createSandbox after a denied approval: want an error")
+	}
+	if len(asked) != 1 || asked[0].RequiredMemoryMB != 2048 || asked[0].AvailableMemoryMB != 1800 || created != 1 {
+		t.Errorf("beyond overcommit: asked %+v, created %d", asked, created)
+	}
+}
+
+func TestPlanSandbox_DoesNotCreate(t *testing.T) {
+	svc := &planningService{}
+	svc.createSandboxStreamFn = func(context.Context, sandbox.CreateRequest, func(string, int, int)) (*sandbox.SandboxInfo, error) {
+		t.Fatal("dry run created a sandbox")
+		return nil, nil
+	}
+	agent := &DeerAgent{service: svc, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	result, err := agent.planSandbox(context.Background(), sandbox.CreateRequest{SourceVM: "ubuntu"})
+	if err != nil {
+		t.Fatalf("planSandbox: %v", err)
+	}
+	if result["dry_run"] != true || result["sandbox_id"] != "sbx-planned" || len(svc.planned) != 1 {
+		t.Errorf("result = %v, planned = %v", result, svc.planned)
+	}
+	if len(agent.createdSandboxes) != 0 {
+		t.Errorf("createdSandboxes = %v, want none", agent.createdSandboxes)
+	}
+
+	agent.service = &stubService{}
+	if _, err := agent.planSandbox(context.Background(), sandbox.CreateRequest{SourceVM: "ubuntu"}); err == nil {
+		t.Error("planSandbox on a service that cannot plan: want an error")
+	}
+}
+
 func TestCreateSandbox_SendsDoneProgressOnError(t *testing.T) {
 	var statuses []tea.Msg
 	svc := &stubService{
@@ -289,7 +387,7 @@ func TestCreateSandbox_SendsDoneProgressOnError(t *testing.T) {
 		statuses = append(statuses, msg)
 	})
 
-	_, err := agent.createSandbox(context.Background(), "ubuntu", "", 2, 2048, true, false, false)
+	_, err := agent.createSandbox(context.Background(), sandbox.CreateRequest{SourceVM: "ubuntu", VCPUs: 2, MemoryMB: 2048, Live: true})
 	if err == nil {
 		t.Fatal("expected createSandbox to return error")
 	}
@@ -306,6 +404,39 @@ func TestCreateSandbox_SendsDoneProgressOnError(t *testing.T) {
 	}
 }
 
+func TestCreateFromTemplate_AppliesPresetAndOverrides(t *testing.T) {
+	var got sandbox.CreateRequest
+	svc := &stubService{
+		createSandboxStreamFn: func(_ context.Context, req sandbox.CreateRequest, _ func(string, int, int)) (*sandbox.SandboxInfo, error) {
+			got = req
+			return &sandbox.SandboxInfo{ID: "SBX-9", BaseImage: req.SourceVM}, nil
+		},
+	}
+	agent := &DeerAgent{
+		service: svc,
+		cfg: &config.Config{Templates: []config.SandboxTemplate{
+			{Name: "web", SourceVM: "ubuntu", CPU: 4, MemoryMB: 8192, KafkaStub: true},
+		}},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	if _, err := agent.createFromTemplate(context.Background(), "web", 0, 2048); err != nil {
+		t.Fatalf("createFromTemplate: %v", err)
+	}
+	if got.SourceVM != "ubuntu" || got.VCPUs != 4 || got.MemoryMB != 2048 || !got.SimpleKafkaBroker {
+		t.Errorf("create request = %+v, want template values with memory overridden", got)
+	}
+
+	if _, err := agent.createFromTemplate(context.Background(), "missing", 0, 0); err == nil || !strings.Contains(err.Error(), "list_templates") {
+		t.Errorf("unknown template err = %v, want a hint to call list_templates", err)
+	}
+
+	listed := agent.listTemplates()
+	if listed["count"] != 1 {
+		t.Errorf("listTemplates count = %v, want 1", listed["count"])
+	}
+}
+
 func TestNormalizeVMName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -814,3 +945,439 @@ func TestRunSourceCommand_StreamingChunksAreRedacted(t *testing.T) {
 		}
 	}
 }
+
+// cleanupService tracks how many destroys run at once.
+type cleanupService struct {
+	stubService
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+	gone     map[string]bool
+	failing  map[string]bool
+	// bulk makes GetSandboxes answer; otherwise it fails and cleanup
+	// checks each sandbox with GetSandbox.
+	bulk    bool
+	lookups int
+	gets    int
+}
+
+func (s *cleanupService) GetSandboxes(_ context.Context, ids []string) ([]*sandbox.SandboxInfo, []string, error) {
+	if !s.bulk {
+		return nil, nil, errors.New("bulk lookup not supported")
+	}
+	s.lookups++
+	var found []*sandbox.SandboxInfo
+	var notFound []string
+	for _, id := range ids {
+		if s.gone[id] {
+			notFound = append(notFound, id)
+		} else {
+			found = append(found, &sandbox.SandboxInfo{ID: id})
+		}
+	}
+	return found, notFound, nil
+}
+
+func (s *cleanupService) GetSandbox(_ context.Context, id string) (*sandbox.SandboxInfo, error) {
+	s.mu.Lock()
+	s.gets++
+	s.mu.Unlock()
+	if s.gone[id] {
+		return nil, errors.New("not found")
+	}
+	return &sandbox.SandboxInfo{ID: id}, nil
+}
+
+func (s *cleanupService) DestroySandbox(_ context.Context, id string) error {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxSeen {
+		s.maxSeen = s.inFlight
+	}
+	s.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+	if s.failing[id] {
+		return errors.New("destroy failed")
+	}
+	return nil
+}
+
+func TestCleanupWithProgress_BoundedConcurrency(t *testing.T) {
+	svc := &cleanupService{
+		gone:    map[string]bool{"sbx-gone": true},
+		failing: map[string]bool{"sbx-bad": true},
+	}
+	cfg := &config.Config{}
+	cfg.AIAgent.CleanupConcurrency = 3
+	a := &DeerAgent{cfg: cfg, service: svc, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+
+	var mu sync.Mutex
+	final := map[string]CleanupStatus{}
+	var done *CleanupCompleteMsg
+	a.SetStatusCallback(func(msg tea.Msg) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch m := msg.(type) {
+		case CleanupProgressMsg:
+			final[m.SandboxID] = m.Status
+		case CleanupCompleteMsg:
+			done = &m
+		}
+	})
+
+	ids := []string{"sbx-1", "sbx-2", "sbx-3", "sbx-4", "sbx-5", "sbx-gone", "sbx-bad"}
+	a.CleanupWithProgress(ids)
+
+	if svc.maxSeen > 3 || svc.maxSeen < 2 {
+		t.Errorf("max concurrent destroys = %d, want 2-3", svc.maxSeen)
+	}
+	if done == nil {
+		t.Fatal("no CleanupCompleteMsg")
+	}
+	if done.Total != 7 || done.Destroyed != 5 || done.Skipped != 1 || done.Failed != 1 {
+		t.Errorf("summary = %+v, want 7 total, 5 destroyed, 1 skipped, 1 failed", *done)
+	}
+	if final["sbx-gone"] != CleanupStatusSkipped || final["sbx-bad"] != CleanupStatusFailed || final["sbx-3"] != CleanupStatusDestroyed {
+		t.Errorf("final statuses = %v", final)
+	}
+	if done.Err == nil || !strings.Contains(done.Err.Error(), "destroy sandbox sbx-bad") {
+		t.Errorf("Err = %v, want the sbx-bad failure", done.Err)
+	}
+}
+
+func TestCleanupWithProgress_LooksUpOnce(t *testing.T) {
+	svc := &cleanupService{bulk: true, gone: map[string]bool{"sbx-gone": true}}
+	a := &DeerAgent{cfg: &config.Config{}, service: svc, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	var done *CleanupCompleteMsg
+	a.SetStatusCallback(func(msg tea.Msg) {
+		if m, ok := msg.(CleanupCompleteMsg); ok {
+			done = &m
+		}
+	})
+
+	a.CleanupWithProgress([]string{"sbx-1", "sbx-gone", "sbx-2"})
+
+	if svc.lookups != 1 || svc.gets != 0 {
+		t.Errorf("got %d bulk lookups and %d GetSandbox calls, want 1 and 0", svc.lookups, svc.gets)
+	}
+	if done == nil || done.Destroyed != 2 || done.Skipped != 1 {
+		t.Errorf("summary = %+v, want 2 destroyed, 1 skipped", done)
+	}
+}
+
+// fakeModelClient is an llm.Client that only lists models.
+type fakeModelClient struct {
+	models []llm.Model
+	calls  int
+}
+
+func (f *fakeModelClient) Chat(context.Context, llm.ChatRequest) (*llm.ChatResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeModelClient) ChatStream(context.Context, llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	return nil, llm.ErrStreamingUnsupported
+}
+
+func (f *fakeModelClient) ListModels(context.Context) ([]llm.Model, error) {
+	f.calls++
+	return f.models, nil
+}
+
+// streamingClient is an llm.Client that streams chunks.
+type streamingClient struct {
+	fakeModelClient
+	chunks []llm.StreamChunk
+}
+
+func (c *streamingClient) ChatStream(context.Context, llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	ch := make(chan llm.StreamChunk, len(c.chunks))
+	for _, chunk := range c.chunks {
+		ch <- chunk
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestChat_StreamsPartialContent(t *testing.T) {
+	client := &streamingClient{chunks: []llm.StreamChunk{
+		{Content: "Checking "},
+		{ToolCalls: []llm.ToolCallDelta{{Index: 0, ID: "call-1", Function: llm.FunctionCall{Name: "list_sandboxes", Arguments: "{"}}}},
+		{Content: "sandboxes.", ToolCalls: []llm.ToolCallDelta{{Index: 0, Function: llm.FunctionCall{Arguments: "}"}}}},
+		{FinishReason: "tool_calls", Usage: &llm.Usage{PromptTokens: 10, CompletionTokens: 4}},
+	}}
+	agent := &DeerAgent{llmClient: client, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	var partials []string
+	agent.SetStatusCallback(func(msg tea.Msg) {
+		if r, ok := msg.(AgentResponseMsg); ok && r.Response.Partial {
+			partials = append(partials, r.Response.Content)
+		}
+	})
+
+	resp, err := agent.chat(context.Background(), llm.ChatRequest{})
+	if err != nil {
+		t.Fatalf("chat: %v", err)
+	}
+	// Updates are rate limited, so chunks this close together give one.
+	if len(partials) != 1 || partials[0] != "Checking " {
+		t.Errorf("partials = %q", partials)
+	}
+	msg := resp.Choices[0].Message
+	if msg.Content != "Checking sandboxes." || len(msg.ToolCalls) != 1 || msg.ToolCalls[0].Function.Arguments != "{}" {
+		t.Errorf("message = %+v", msg)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 10 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	client.chunks = []llm.StreamChunk{{Content: "Hi"}, {Err: errors.New("provider overloaded")}}
+	if _, err := agent.chat(context.Background(), llm.ChatRequest{}); err == nil || err.Error() != "provider overloaded" {
+		t.Errorf("chat after a stream error = %v", err)
+	}
+
+	// A provider that cannot stream falls back to Chat.
+	agent.llmClient = &fakeModelClient{}
+	if _, err := agent.chat(context.Background(), llm.ChatRequest{}); err == nil || err.Error() != "not implemented" {
+		t.Errorf("fallback chat = %v, want the Chat error", err)
+	}
+}
+
+func TestRun_ModelsSlashCommand(t *testing.T) {
+	var statuses []tea.Msg
+	client := &fakeModelClient{models: []llm.Model{
+		{ID: "anthropic/claude-haiku", ContextLength: 200000, PromptPrice: 0.000001, CompletionPrice: 0.000005},
+		{ID: "z-ai/glm-4.5-air:free", ContextLength: 128000},
+	}}
+	agent := &DeerAgent{
+		cfg:       &config.Config{AIAgent: config.AIAgentConfig{Model: "anthropic/claude-haiku"}},
+		llmClient: client,
+		telemetry: telemetry.NewNoopService(),
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	agent.SetStatusCallback(func(msg tea.Msg) { statuses = append(statuses, msg) })
+
+	agent.Run("/models")()
+	resp := statuses[len(statuses)-1].(AgentResponseMsg)
+	for _, want := range []string{"`anthropic/claude-haiku` - 200k context, $1.00/M in, $5.00/M out", "`z-ai/glm-4.5-air:free` - 128k context, free", "Current: `anthropic/claude-haiku`"} {
+		if !strings.Contains(resp.Response.Content, want) {
+			t.Errorf("/models output missing %q:\n%s", want, resp.Response.Content)
+		}
+	}
+
+	agent.Run("/models GLM")()
+	resp = statuses[len(statuses)-1].(AgentResponseMsg)
+	if strings.Contains(resp.Response.Content, "`anthropic/claude-haiku` -") || !strings.Contains(resp.Response.Content, "Models (1)") {
+		t.Errorf("filtered output:\n%s", resp.Response.Content)
+	}
+}
+
+func TestContextTokens_PrefersReportedUsage(t *testing.T) {
+	agent := &DeerAgent{
+		cfg: &config.Config{AIAgent: config.AIAgentConfig{TokensPerChar: 0.25}},
+		history: []llm.Message{
+			{Role: llm.RoleUser, Content: strings.Repeat("a", 400)},
+			{Role: llm.RoleAssistant, Content: strings.Repeat("b", 400)},
+		},
+	}
+	if got := agent.ContextTokens(); got != 200 || agent.TokensMeasured() {
+		t.Fatalf("ContextTokens = %d (measured %v), want the 200-token estimate", got, agent.TokensMeasured())
+	}
+
+	agent.recordUsage(&llm.Usage{PromptTokens: 900, CompletionTokens: 100})
+	if got := agent.ContextTokens(); got != 1000 || !agent.TokensMeasured() {
+		t.Fatalf("ContextTokens = %d, want the reported 1000", got)
+	}
+
+	// Messages appended after the last response are estimated on top.
+	agent.history = append(agent.history, llm.Message{Role: llm.RoleTool, Content: strings.Repeat("c", 40)})
+	if got := agent.ContextTokens(); got != 1010 {
+		t.Errorf("ContextTokens = %d, want 1010", got)
+	}
+
+	agent.recordUsage(nil)
+	if agent.TokensMeasured() {
+		t.Error("a response without usage should fall back to the estimate")
+	}
+}
+
+func TestAwaitApproval_BlocksUntilAnswered(t *testing.T) {
+	requests := map[ApprovalKind]any{
+		ApprovalMemory:  MemoryApprovalRequest{SourceVM: "ubuntu", RequiredMemoryMB: 4096},
+		ApprovalNetwork: NetworkApprovalRequest{SandboxID: "sbx-1", Command: "curl example.com"},
+		ApprovalCommand: NetworkApprovalRequest{SandboxID: "sbx-1", Command: "ls", RequireApproval: true},
+		ApprovalTool:    ToolApprovalRequest{Tool: "destroy_sandbox"},
+	}
+	for kind, request := range requests {
+		for _, answer := range []bool{true, false} {
+			t.Run(fmt.Sprintf("%s/%v", kind, answer), func(t *testing.T) {
+				agent := &DeerAgent{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+				asked := make(chan ApprovalRequestMsg, 1)
+				agent.SetStatusCallback(func(msg tea.Msg) {
+					if req, ok := msg.(ApprovalRequestMsg); ok {
+						asked <- req
+					}
+				})
+
+				type result struct {
+					approved bool
+					err      error
+				}
+				done := make(chan result, 1)
+				go func() {
+					approved, err := agent.awaitApproval(context.Background(), kind, map[string]any{"k": "v"}, request)
+					done <- result{approved, err}
+				}()
+
+				req := <-asked
+				if req.Kind != kind || req.Details["k"] != "v" || !reflect.DeepEqual(req.Request, request) {
+					t.Fatalf("request = %+v, want kind %s with the details and dialog request", req, kind)
+				}
+
+				// An answer for another kind must not unblock it.
+				other := ApprovalTool
+				if kind == ApprovalTool {
+					other = ApprovalMemory
+				}
+				agent.HandleApprovalResponse(other, !answer)
+				select {
+				case r := <-done:
+					t.Fatalf("returned %+v before being answered", r)
+				case <-time.After(20 * time.Millisecond):
+				}
+
+				agent.HandleApprovalResponse(kind, answer)
+				select {
+				case r := <-done:
+					if r.err != nil || r.approved != answer {
+						t.Errorf("awaitApproval = %v, %v; want %v, nil", r.approved, r.err, answer)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("still blocked after the answer")
+				}
+
+				// Once answered nothing is pending, so a late answer is dropped.
+				agent.HandleApprovalResponse(kind, answer)
+				if agent.pendingApproval != nil {
+					t.Error("pendingApproval not cleared")
+				}
+			})
+		}
+	}
+}
+
+func TestAwaitApproval_Cancelled(t *testing.T) {
+	agent := &DeerAgent{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	ctx, cancel := context.WithCancel(context.Background())
+	agent.SetStatusCallback(func(msg tea.Msg) {
+		if _, ok := msg.(ApprovalRequestMsg); ok {
+			cancel()
+		}
+	})
+
+	approved, err := agent.awaitApproval(ctx, ApprovalNetwork, nil, NetworkApprovalRequest{})
+	if approved || !errors.Is(err, context.Canceled) {
+		t.Errorf("awaitApproval = %v, %v; want false, context.Canceled", approved, err)
+	}
+}
+
+func TestExecuteTool_ApprovalPolicy(t *testing.T) {
+	agent := &DeerAgent{
+		cfg:     &config.Config{ToolApproval: config.ToolApprovalPolicy{"stop_sandbox": "deny"}},
+		service: &stubService{},
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	var asked []string
+	answer := false
+	agent.SetStatusCallback(func(msg tea.Msg) {
+		if req, ok := msg.(ApprovalRequestMsg); ok {
+			asked = append(asked, req.Request.(ToolApprovalRequest).Tool)
+			agent.HandleApprovalResponse(req.Kind, answer)
+		}
+	})
+	call := func(name, args string) error {
+		_, err := agent.executeTool(context.Background(), llm.ToolCall{ID: "call-1", Function: llm.FunctionCall{Name: name, Arguments: args}})
+		return err
+	}
+
+	if err := call("stop_sandbox", `{"sandbox_id":"sbx-1"}`); err == nil || !strings.Contains(err.Error(), "not allowed by the tool_approval policy") {
+		t.Errorf("denied tool: err = %v", err)
+	}
+	if err := call("destroy_sandbox", `{"sandbox_id":"sbx-1"}`); err == nil || err.Error() != "destroy_sandbox denied by user" {
+		t.Errorf("refused confirm: err = %v", err)
+	}
+	answer = true
+	if err := call("destroy_sandbox", `{"sandbox_id":"sbx-1"}`); err != nil {
+		t.Errorf("approved confirm: err = %v", err)
+	}
+	if err := call("list_sandboxes", `{}`); err != nil {
+		t.Errorf("auto tool: err = %v", err)
+	}
+	if len(asked) != 2 || asked[0] != "destroy_sandbox" || asked[1] != "destroy_sandbox" {
+		t.Errorf("asked about %q, want destroy_sandbox twice", asked)
+	}
+}
+
+func TestRunCommand_RequireApproval(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.NewLogger(logPath, 10)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	svc := &stubService{}
+	agent := &DeerAgent{
+		cfg:              &config.Config{},
+		service:          svc,
+		auditLog:         auditLog,
+		currentSandboxID: "sbx-1",
+		logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	agent.SetRequireApproval(true)
+
+	var asked []NetworkApprovalRequest
+	answer := false
+	agent.SetStatusCallback(func(msg tea.Msg) {
+		if req, ok := msg.(ApprovalRequestMsg); ok {
+			asked = append(asked, req.Request.(NetworkApprovalRequest))
+			agent.HandleApprovalResponse(req.Kind, answer)
+		}
+	})
+
+	// A command with no network access is still asked about, and a "no"
+	// keeps it from running.
+	res, err := agent.runCommand(context.Background(), "sbx-1", "rm -rf /var/cache/apt", "")
+	if err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if res["error"] != "command denied by user" || len(svc.ran) != 0 {
+		t.Errorf("denied command: result %v, ran %q", res, svc.ran)
+	}
+
+	// One question covers both the command and its network access.
+	answer = true
+	if _, err := agent.runCommand(context.Background(), "sbx-1", "curl https://example.com", ""); err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if len(asked) != 2 || !asked[0].RequireApproval || asked[1].NetworkTool != "curl" || len(svc.ran) != 1 {
+		t.Errorf("asked %+v, ran %q", asked, svc.ran)
+	}
+
+	_ = auditLog.Close()
+	entries, err := audit.ReadRecent(logPath, 10)
+	if err != nil {
+		t.Fatalf("ReadRecent: %v", err)
+	}
+	var decisions []any
+	for _, e := range entries {
+		if e.Type == audit.TypeApproval {
+			decisions = append(decisions, e.Meta["approved"])
+		}
+	}
+	if len(decisions) != 2 || decisions[0] != false || decisions[1] != true {
+		t.Errorf("audited decisions = %v, want [false true]", decisions)
+	}
+}
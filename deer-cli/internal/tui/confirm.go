@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -246,13 +247,18 @@ func (m ConfirmModel) View() string {
 
 	// Memory comparison
 	deficit := int64(m.request.RequiredMemoryMB) - m.request.AvailableMemoryMB
-	percentAvailable := float64(m.request.AvailableMemoryMB) / float64(m.request.TotalMemoryMB) * 100
 
 	b.WriteString(m.styles.warning.Render("Memory Status:"))
 	b.WriteString("\n")
 	fmt.Fprintf(&b, "  Required:  %s MB\n", m.styles.highlight.Render(fmt.Sprintf("%d", m.request.RequiredMemoryMB)))
-	fmt.Fprintf(&b, "  Available: %s MB (%.1f%% of total)\n", m.styles.error.Render(fmt.Sprintf("%d", m.request.AvailableMemoryMB)), percentAvailable)
-	fmt.Fprintf(&b, "  Total:     %d MB\n", m.request.TotalMemoryMB)
+	// The daemon's plan reports free memory but not the host total.
+	if m.request.TotalMemoryMB > 0 {
+		percentAvailable := float64(m.request.AvailableMemoryMB) / float64(m.request.TotalMemoryMB) * 100
+		fmt.Fprintf(&b, "  Available: %s MB (%.1f%% of total)\n", m.styles.error.Render(fmt.Sprintf("%d", m.request.AvailableMemoryMB)), percentAvailable)
+		fmt.Fprintf(&b, "  Total:     %d MB\n", m.request.TotalMemoryMB)
+	} else {
+		fmt.Fprintf(&b, "  Available: %s MB\n", m.styles.error.Render(fmt.Sprintf("%d", m.request.AvailableMemoryMB)))
+	}
 	fmt.Fprintf(&b, "  Deficit:   %s MB\n", m.styles.error.Render(fmt.Sprintf("%d", deficit)))
 	b.WriteString("\n")
 
@@ -325,9 +331,15 @@ type MemoryApprovalResponseMsg struct {
 	Result MemoryApprovalResult
 }
 
-// MemoryApprovalRequestMsg is sent when the agent needs memory approval
-type MemoryApprovalRequestMsg struct {
-	Request MemoryApprovalRequest
+// ApprovalRequestMsg is sent when the agent blocks on a yes/no approval.
+// Request is what the dialog for Kind shows: a MemoryApprovalRequest for
+// ApprovalMemory, a NetworkApprovalRequest for ApprovalNetwork and
+// ApprovalCommand, or a ToolApprovalRequest for ApprovalTool. The answer
+// goes back through DeerAgent.HandleApprovalResponse.
+type ApprovalRequestMsg struct {
+	Kind    ApprovalKind
+	Details map[string]any
+	Request any
 }
 
 // RunConfirmDialog runs a standalone confirmation dialog
@@ -354,6 +366,9 @@ type NetworkApprovalRequest struct {
 	SandboxID   string   // The sandbox where the command will run
 	NetworkTool string   // The detected network tool (curl, wget, etc.)
 	URLs        []string // Detected URLs in the command
+	// RequireApproval is set when the question comes from --require-approval
+	// rather than network access; NetworkTool is empty unless both apply.
+	RequireApproval bool
 }
 
 // NetworkApprovalResult is the response from the user
@@ -362,11 +377,6 @@ type NetworkApprovalResult struct {
 	Request  NetworkApprovalRequest
 }
 
-// NetworkApprovalRequestMsg is sent when the agent needs network approval
-type NetworkApprovalRequestMsg struct {
-	Request NetworkApprovalRequest
-}
-
 // NetworkApprovalResponseMsg is sent when the user responds to the network approval dialog
 type NetworkApprovalResponseMsg struct {
 	Result NetworkApprovalResult
@@ -444,14 +454,21 @@ func (m NetworkConfirmModel) View() string {
 	var b strings.Builder
 
 	// Title
-	b.WriteString(m.styles.title.Render("! Network Access Request"))
+	title := "! Network Access Request"
+	if m.request.RequireApproval {
+		title = "! Command Approval Request"
+	}
+	b.WriteString(m.styles.title.Render(title))
 	b.WriteString("\n\n")
 
 	// Context info
 	b.WriteString(m.styles.info.Render(fmt.Sprintf("Sandbox: %s", m.styles.highlight.Render(m.request.SandboxID))))
 	b.WriteString("\n")
-	b.WriteString(m.styles.info.Render(fmt.Sprintf("Tool: %s", m.styles.highlight.Render(m.request.NetworkTool))))
-	b.WriteString("\n\n")
+	if m.request.NetworkTool != "" {
+		b.WriteString(m.styles.info.Render(fmt.Sprintf("Tool: %s", m.styles.highlight.Render(m.request.NetworkTool))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// Command
 	b.WriteString(m.styles.warning.Render("Command:"))
@@ -478,15 +495,25 @@ func (m NetworkConfirmModel) View() string {
 	}
 
 	// Warning message
-	b.WriteString(m.styles.warning.Render("This command will access the internet."))
-	b.WriteString("\n")
-	b.WriteString(m.styles.info.Render("  - Data may be downloaded or uploaded"))
-	b.WriteString("\n")
-	b.WriteString(m.styles.info.Render("  - External servers will be contacted"))
-	b.WriteString("\n\n")
+	if m.request.NetworkTool != "" {
+		b.WriteString(m.styles.warning.Render("This command will access the internet."))
+		b.WriteString("\n")
+		b.WriteString(m.styles.info.Render("  - Data may be downloaded or uploaded"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.info.Render("  - External servers will be contacted"))
+		b.WriteString("\n\n")
+	}
+	if m.request.RequireApproval {
+		b.WriteString(m.styles.info.Render("Every command needs approval in this session (--require-approval)."))
+		b.WriteString("\n\n")
+	}
 
 	// Question
-	b.WriteString(m.styles.highlight.Render("Allow this network access?"))
+	question := "Allow this network access?"
+	if m.request.RequireApproval {
+		question = "Run this command?"
+	}
+	b.WriteString(m.styles.highlight.Render(question))
 	b.WriteString("\n\n")
 
 	// Buttons
@@ -821,3 +848,149 @@ func (m SourceAccessConfirmModel) View() string {
 
 	return content
 }
+
+// ToolApprovalRequest contains a tool call that the tool_approval policy
+// says needs confirming.
+type ToolApprovalRequest struct {
+	Tool string
+	Args map[string]any
+}
+
+// ToolApprovalResult is the response from the user
+type ToolApprovalResult struct {
+	Approved bool
+	Request  ToolApprovalRequest
+}
+
+// ToolApprovalResponseMsg is sent when the user responds to the tool approval dialog
+type ToolApprovalResponseMsg struct {
+	Result ToolApprovalResult
+}
+
+// ToolConfirmModel is a Bubble Tea model for confirming a tool call
+type ToolConfirmModel struct {
+	request  ToolApprovalRequest
+	selected int // 0 = No (default safe option), 1 = Yes
+	width    int
+	height   int
+	styles   confirmStyles
+
+	resultChan chan<- ToolApprovalResult
+}
+
+// NewToolConfirmModel creates a new confirmation dialog for a tool call
+func NewToolConfirmModel(request ToolApprovalRequest, resultChan chan<- ToolApprovalResult) ToolConfirmModel {
+	return ToolConfirmModel{
+		request:    request,
+		selected:   0,
+		styles:     newConfirmStyles(),
+		resultChan: resultChan,
+	}
+}
+
+// Init implements tea.Model
+func (m ToolConfirmModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m ToolConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, confirmKeys.Left):
+			m.selected = 0
+		case key.Matches(msg, confirmKeys.Right):
+			m.selected = 1
+		case key.Matches(msg, confirmKeys.Tab):
+			m.selected = (m.selected + 1) % 2
+		case key.Matches(msg, confirmKeys.Yes):
+			m.selected = 1
+			return m.confirm()
+		case key.Matches(msg, confirmKeys.No), key.Matches(msg, confirmKeys.Escape):
+			m.selected = 0
+			return m.confirm()
+		case key.Matches(msg, confirmKeys.Enter):
+			return m.confirm()
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m ToolConfirmModel) confirm() (tea.Model, tea.Cmd) {
+	result := ToolApprovalResult{
+		Approved: m.selected == 1,
+		Request:  m.request,
+	}
+	if m.resultChan != nil {
+		m.resultChan <- result
+	}
+	return m, func() tea.Msg {
+		return ToolApprovalResponseMsg{Result: result}
+	}
+}
+
+// View implements tea.Model
+func (m ToolConfirmModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.styles.title.Render("! Tool Approval Required"))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.styles.info.Render(fmt.Sprintf("The agent wants to run %s", m.styles.highlight.Render(m.request.Tool))))
+	b.WriteString("\n\n")
+
+	if len(m.request.Args) > 0 {
+		b.WriteString(m.styles.warning.Render("Arguments:"))
+		b.WriteString("\n")
+		keys := make([]string, 0, len(m.request.Args))
+		for k := range m.request.Args {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := fmt.Sprintf("%v", m.request.Args[k])
+			if i := strings.IndexByte(v, '\n'); i >= 0 {
+				v = v[:i] + " ..."
+			}
+			if len(v) > 70 {
+				v = v[:67] + "..."
+			}
+			b.WriteString(m.styles.info.Render(fmt.Sprintf("  %s: %s", k, v)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.styles.info.Render("The tool_approval policy asks before this tool runs."))
+	b.WriteString("\n\n")
+
+	b.WriteString(m.styles.highlight.Render("Allow this tool call?"))
+	b.WriteString("\n\n")
+
+	var noBtn, yesBtn string
+	if m.selected == 0 {
+		noBtn = m.styles.buttonFocus.Render(" [ No ] ")
+		yesBtn = m.styles.button.Render("   Yes   ")
+	} else {
+		noBtn = m.styles.button.Render("   No   ")
+		yesBtn = m.styles.buttonFocus.Render(" [ Yes ] ")
+	}
+
+	buttons := lipgloss.JoinHorizontal(lipgloss.Center, noBtn, "    ", yesBtn)
+	b.WriteString(buttons)
+	b.WriteString("\n\n")
+
+	b.WriteString(m.styles.help.Render("  <-/-> or Tab: select | Enter: confirm | y/n: quick select | Esc: cancel"))
+
+	content := m.styles.dialog.Render(b.String())
+
+	if m.width > 0 && m.height > 0 {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+	}
+
+	return content
+}
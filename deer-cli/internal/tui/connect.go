@@ -484,8 +484,8 @@ func (m ConnectModel) renderDoctorResults(b *strings.Builder, successStyle, errS
 			b.WriteString(errStyle.Render(fmt.Sprintf("  x %s", r.Message)))
 		}
 		b.WriteString("\n")
-		if !r.Passed && r.FixCmd != "" {
-			fmt.Fprintf(b, "     Fix: %s\n", r.FixCmd)
+		if !r.Passed && r.Remediation != "" {
+			fmt.Fprintf(b, "     Fix: %s\n", r.Remediation)
 		}
 	}
 	fmt.Fprintf(b, "\n  %d/%d passed", passed, passed+failed)
@@ -605,11 +605,11 @@ func (m ConnectModel) runDoctorChecks() tea.Cmd {
 		doctorResults := make([]doctor.CheckResult, len(results))
 		for i, r := range results {
 			doctorResults[i] = doctor.CheckResult{
-				Name:     r.Name,
-				Category: r.Category,
-				Passed:   r.Passed,
-				Message:  r.Message,
-				FixCmd:   r.FixCmd,
+				Name:        r.Name,
+				Category:    r.Category,
+				Passed:      r.Passed,
+				Message:     r.Message,
+				Remediation: r.FixCmd,
 			}
 		}
 		return ConnectDoctorResultMsg{Results: doctorResults}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/llm"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+)
+
+// maxConversationTitle bounds the title shown when offering to resume.
+const maxConversationTitle = 80
+
+// ConversationResumer is implemented by runners that persist conversations.
+// NewModel uses it to offer /resume on launch.
+type ConversationResumer interface {
+	// ResumableConversation returns the most recent saved conversation other
+	// than the current one, or nil if there is none.
+	ResumableConversation(ctx context.Context) *store.Conversation
+}
+
+// newSessionID returns the key a fresh conversation is saved under.
+func newSessionID() string {
+	return uuid.New().String()
+}
+
+// SessionID returns the key the current conversation is saved under.
+func (a *DeerAgent) SessionID() string {
+	return a.sessionID
+}
+
+// saveConversation persists the history, tool calls and results included,
+// under the current session ID. Failures are logged rather than surfaced:
+// losing the saved copy must not interrupt the conversation itself.
+func (a *DeerAgent) saveConversation(ctx context.Context) {
+	if a.store == nil || len(a.history) == 0 {
+		return
+	}
+	data, err := json.Marshal(a.history)
+	if err != nil {
+		a.logger.Warn("encode conversation", "session_id", a.sessionID, "error", err)
+		return
+	}
+	if a.conversationTitle == "" {
+		a.conversationTitle = conversationTitle(a.history)
+	}
+	c := &store.Conversation{
+		ID:          a.sessionID,
+		Title:       a.conversationTitle,
+		Messages:    string(data),
+		Compactions: a.compactions,
+	}
+	if err := a.store.SaveConversation(ctx, c); err != nil {
+		a.logger.Warn("save conversation", "session_id", a.sessionID, "error", err)
+	}
+}
+
+// conversationTitle is the first user message, on one line and truncated.
+// It is taken once per session so a compaction summary never replaces it.
+func conversationTitle(history []llm.Message) string {
+	for _, msg := range history {
+		if msg.Role != llm.RoleUser {
+			continue
+		}
+		title := strings.Join(strings.Fields(msg.Content), " ")
+		if r := []rune(title); len(r) > maxConversationTitle {
+			title = string(r[:maxConversationTitle-3]) + "..."
+		}
+		return title
+	}
+	return ""
+}
+
+// ResumableConversation implements ConversationResumer.
+func (a *DeerAgent) ResumableConversation(ctx context.Context) *store.Conversation {
+	if a.store == nil {
+		return nil
+	}
+	recent, err := a.store.ListConversations(ctx, &store.ListOptions{Limit: 2})
+	if err != nil {
+		a.logger.Warn("list conversations", "error", err)
+		return nil
+	}
+	for _, c := range recent {
+		if c.ID != a.sessionID {
+			return c
+		}
+	}
+	return nil
+}
+
+// resumeConversation replaces the history with the saved conversation and
+// continues saving under its ID.
+func (a *DeerAgent) resumeConversation(ctx context.Context) (string, error) {
+	c := a.ResumableConversation(ctx)
+	if c == nil {
+		return "", errors.New("no saved conversation to resume")
+	}
+	var history []llm.Message
+	if err := json.Unmarshal([]byte(c.Messages), &history); err != nil {
+		return "", fmt.Errorf("decode conversation %s: %w", c.ID, err)
+	}
+
+	a.Reset()
+	a.history = history
+	a.sessionID = c.ID
+	a.conversationTitle = c.Title
+	a.compactions = c.Compactions
+	a.logger.Info("conversation resumed", "session_id", c.ID, "message_count", len(history))
+
+	content := fmt.Sprintf("Resumed conversation from %s (%d messages", c.UpdatedAt.Local().Format(time.DateTime), len(history))
+	if c.Compactions > 0 {
+		content += fmt.Sprintf(", compacted %d times", c.Compactions)
+	}
+	content += ")"
+	if c.Title != "" {
+		content += ": " + c.Title
+	}
+	return content, nil
+}
+
+// resumeOffer is the startup line offering to resume c.
+func resumeOffer(c *store.Conversation) string {
+	title := c.Title
+	if title == "" {
+		title = "untitled"
+	}
+	return fmt.Sprintf("Your last conversation (%q, %s) was saved. Type '/resume' to continue it.",
+		title, c.UpdatedAt.Local().Format(time.DateTime))
+}
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/llm"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/store/sqlite"
+	"github.com/aspectrr/deer.sh/deer-cli/internal/telemetry"
+)
+
+func newConversationAgent(t *testing.T, st store.Store) *DeerAgent {
+	t.Helper()
+	return &DeerAgent{
+		cfg:       &config.Config{},
+		store:     st,
+		sessionID: newSessionID(),
+		telemetry: telemetry.NewNoopService(),
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestConversation_SaveAndResume(t *testing.T) {
+	ctx := context.Background()
+	st, err := sqlite.New(ctx, store.Config{DatabaseURL: filepath.Join(t.TempDir(), "state.db"), AutoMigrate: true})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer func() { _ = st.Close() }()
+
+	first := newConversationAgent(t, st)
+	first.history = []llm.Message{
+		{Role: llm.RoleUser, Content: "why is nginx\n  failing?"},
+		{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "call-1", Type: "function", Function: llm.FunctionCall{Name: "run_source_command", Arguments: `{"command":"systemctl status nginx"}`}}}},
+		{Role: llm.RoleTool, ToolCallID: "call-1", Name: "run_source_command", Content: "inactive (dead)"},
+	}
+	first.compactions = 1
+	first.finishRun(nil)
+
+	// A new TUI session sees the first one as resumable, but not itself.
+	second := newConversationAgent(t, st)
+	c := second.ResumableConversation(ctx)
+	if c == nil || c.ID != first.SessionID() || c.Title != "why is nginx failing?" {
+		t.Fatalf("ResumableConversation() = %+v", c)
+	}
+	if first.ResumableConversation(ctx) != nil {
+		t.Error("the current conversation was offered for resume")
+	}
+
+	var statuses []tea.Msg
+	second.SetStatusCallback(func(msg tea.Msg) { statuses = append(statuses, msg) })
+	second.Run("/resume")()
+	resp, ok := statuses[len(statuses)-1].(AgentResponseMsg)
+	if !ok || !strings.Contains(resp.Response.Content, "3 messages, compacted 1 times") {
+		t.Fatalf("/resume status = %#v", statuses[len(statuses)-1])
+	}
+	if second.SessionID() != first.SessionID() || second.compactions != 1 {
+		t.Errorf("session = %s/%d, want %s/1", second.SessionID(), second.compactions, first.SessionID())
+	}
+	if len(second.history) != 3 || second.history[1].ToolCalls[0].Function.Name != "run_source_command" || second.history[2].ToolCallID != "call-1" {
+		t.Errorf("resumed history = %+v", second.history)
+	}
+
+	// Reset starts a fresh session and leaves the saved one resumable.
+	second.Reset()
+	if second.SessionID() == first.SessionID() {
+		t.Error("Reset kept the session ID")
+	}
+	if c := second.ResumableConversation(ctx); c == nil || c.ID != first.SessionID() {
+		t.Errorf("after Reset, ResumableConversation() = %+v", c)
+	}
+}
+
+func TestConversation_ResumeWithoutStore(t *testing.T) {
+	a := newConversationAgent(t, nil)
+	a.history = []llm.Message{{Role: llm.RoleUser, Content: "hi"}}
+	a.saveConversation(context.Background())
+	if _, err := a.resumeConversation(context.Background()); err == nil {
+		t.Error("resumeConversation() without a store succeeded")
+	}
+}
+
+func TestConversationTitle(t *testing.T) {
+	long := strings.Repeat("é", 100)
+	got := conversationTitle([]llm.Message{{Role: llm.RoleAssistant, Content: "hello"}, {Role: llm.RoleUser, Content: long}})
+	if r := []rune(got); len(r) != maxConversationTitle || !strings.HasSuffix(got, "...") {
+		t.Errorf("conversationTitle() = %q", got)
+	}
+}
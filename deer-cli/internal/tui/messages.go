@@ -28,6 +28,10 @@ type AgentResponse struct {
 	ToolResults   []ToolResult
 	Done          bool
 	AwaitingInput bool
+	// Partial marks a reply still streaming in: Content is the text so far
+	// and replaces the previous partial update. The next non-partial
+	// response completes the same message.
+	Partial bool
 }
 
 // UserInputMsg is sent when the user submits input
@@ -217,6 +221,7 @@ type CleanupCompleteMsg struct {
 	Destroyed int
 	Failed    int
 	Skipped   int
+	Err       error // the failed destroys, one per sandbox; nil if none failed
 }
 
 // SourcePrepareProgressMsg is sent during source VM preparation to show step-by-step progress
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log/slog"
@@ -108,6 +109,11 @@ type Model struct {
 	inSourcePrepareConfirm    bool
 	sourcePrepareApprovalChan chan<- SourcePrepareApprovalResult
 
+	// Tool approval dialog
+	toolConfirmModel ToolConfirmModel
+	inToolConfirm    bool
+	toolApprovalChan chan<- ToolApprovalResult
+
 	// Source access elevation dialog
 	sourceAccessConfirmModel SourceAccessConfirmModel
 	inSourceAccessConfirm    bool
@@ -143,6 +149,11 @@ type Model struct {
 	liveOutputIndex   int // Index in conversation where live output is displayed
 	currentRetry      *RetryAttemptMsg
 
+	// streaming is set while an assistant reply streams into
+	// conversation[streamingEntry].
+	streaming      bool
+	streamingEntry int
+
 	// Live prepare progress (inline with conversation, like live command output)
 	showingLivePrepare  bool
 	livePrepareSourceVM string
@@ -189,6 +200,8 @@ var allCommands = []commandSuggestion{
 	{"/prepare", "Prepare a host for read-only access"},
 	{"/compact", "Summarize and compact conversation history"},
 	{"/context", "Show current context token usage"},
+	{"/resume", "Resume the last saved conversation"},
+	{"/models", "List available models with context size and pricing"},
 	{"/connect", "Connect to a deer daemon"},
 	{"/settings", "Open configuration settings"},
 	{"/allowlist", "Show and edit read-only command allowlist"},
@@ -300,6 +313,14 @@ func NewModel(title, provider, modelName string, runner AgentRunner, cfg *config
 		Role:    "system",
 		Content: startupMsg,
 	})
+	if resumer, ok := runner.(ConversationResumer); ok {
+		if c := resumer.ResumableConversation(context.Background()); c != nil {
+			m.conversation = append(m.conversation, ConversationEntry{
+				Role:    "system",
+				Content: resumeOffer(c),
+			})
+		}
+	}
 
 	return m
 }
@@ -540,7 +561,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Send response to the agent
 		if agent, ok := m.agentRunner.(*DeerAgent); ok {
-			agent.HandleApprovalResponse(approvalResp.Result.Approved)
+			agent.HandleApprovalResponse(ApprovalMemory, approvalResp.Result.Approved)
 		}
 
 		if approvalResp.Result.Approved {
@@ -563,7 +584,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Send response to the agent
 		if agent, ok := m.agentRunner.(*DeerAgent); ok {
-			agent.HandleNetworkApprovalResponse(networkResp.Result.Approved)
+			kind := ApprovalNetwork
+			if networkResp.Result.Request.RequireApproval {
+				kind = ApprovalCommand
+			}
+			agent.HandleApprovalResponse(kind, networkResp.Result.Approved)
 		}
 
 		if networkResp.Result.Approved {
@@ -598,6 +623,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(ThinkingCmd(), m.listenForStatus())
 	}
 
+	// Handle tool approval response
+	if toolResp, ok := msg.(ToolApprovalResponseMsg); ok {
+		m.inToolConfirm = false
+		m.state = StateThinking
+		m.thinking = true
+		m.thinkingDots = 0
+
+		if agent, ok := m.agentRunner.(*DeerAgent); ok {
+			agent.HandleApprovalResponse(ApprovalTool, toolResp.Result.Approved)
+		}
+
+		if toolResp.Result.Approved {
+			m.addSystemMessage(fmt.Sprintf("%s approved. Running...", toolResp.Result.Request.Tool))
+		} else {
+			m.addSystemMessage(fmt.Sprintf("%s denied.", toolResp.Result.Request.Tool))
+		}
+
+		m.updateViewportContent(true)
+		return m, tea.Batch(ThinkingCmd(), m.listenForStatus())
+	}
+
 	// Handle source access elevation response
 	if saResp, ok := msg.(SourceAccessApprovalResponseMsg); ok {
 		m.inSourceAccessConfirm = false
@@ -647,6 +693,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	}
 
+	// If in tool confirmation mode, delegate to tool confirm model
+	if m.inToolConfirm {
+		var cmd tea.Cmd
+		toolModel, cmd := m.toolConfirmModel.Update(msg)
+		m.toolConfirmModel = toolModel.(ToolConfirmModel)
+		return m, cmd
+	}
+
 	// If in source access elevation mode, delegate to source access confirm model
 	if m.inSourceAccessConfirm {
 		var cmd tea.Cmd
@@ -658,7 +712,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.MouseMsg:
 		if !m.inSettings && !m.inPlaybooks && !m.inConnect && !m.inMemoryConfirm &&
-			!m.inNetworkConfirm && !m.inSourcePrepareConfirm && !m.inCleanup &&
+			!m.inNetworkConfirm && !m.inSourcePrepareConfirm && !m.inToolConfirm && !m.inCleanup &&
 			!m.inAllowlist && !m.inRedaction {
 			switch msg.Button {
 			case tea.MouseButtonWheelUp:
@@ -1284,10 +1338,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case AgentResponseMsg:
 		// Final assistant/tool UI updates arrive through statusChan. When Done is
 		// true, stop listening so the next run starts with a clean status stream.
-		// Tool results were already sent via ToolCompleteMsg.
-		if msg.Response.Content != "" {
+		// Tool results were already sent via ToolCompleteMsg. A streamed reply
+		// grows in place and the next non-partial response completes it.
+		switch {
+		case m.streaming && m.streamingEntry < len(m.conversation):
+			if msg.Response.Content != "" {
+				m.conversation[m.streamingEntry].Content = msg.Response.Content
+			}
+		case msg.Response.Content != "":
 			m.addAssistantMessage(msg.Response.Content)
+			m.streamingEntry = len(m.conversation) - 1
 		}
+		m.streaming = msg.Response.Partial
 
 		if !msg.Response.Done {
 			m.updateViewportContent(true)
@@ -1318,6 +1380,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case AgentErrorMsg:
+		m.streaming = false
 		m.thinking = false
 		m.state = StateIdle
 		m.agentStatus = StatusThinking
@@ -1366,46 +1429,70 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.updateViewportContent(true)
 		return m, nil
 
-	case MemoryApprovalRequestMsg:
-		// Show the memory approval confirmation dialog
-		m.inMemoryConfirm = true
-		m.state = StateMemoryApproval
+	case ApprovalRequestMsg:
+		// Show the dialog for the approval's kind
+		m.state = StateMemoryApproval // Reuse the same state for approval dialogs
 		m.thinking = false
 
-		// Create result channel for the confirmation
-		resultChan := make(chan MemoryApprovalResult, 1)
-		m.approvalChan = resultChan
-		m.confirmModel = NewConfirmModel(msg.Request, resultChan)
+		switch req := msg.Request.(type) {
+		case MemoryApprovalRequest:
+			m.inMemoryConfirm = true
 
-		// Update dimensions for the confirm model
-		if m.width > 0 && m.height > 0 {
-			confirmModel, _ := m.confirmModel.Update(tea.WindowSizeMsg{
-				Width:  m.width,
-				Height: m.height,
-			})
-			m.confirmModel = confirmModel.(ConfirmModel)
-		}
+			// Create result channel for the confirmation
+			resultChan := make(chan MemoryApprovalResult, 1)
+			m.approvalChan = resultChan
+			m.confirmModel = NewConfirmModel(req, resultChan)
 
-		return m, nil
+			// Update dimensions for the confirm model
+			if m.width > 0 && m.height > 0 {
+				confirmModel, _ := m.confirmModel.Update(tea.WindowSizeMsg{
+					Width:  m.width,
+					Height: m.height,
+				})
+				m.confirmModel = confirmModel.(ConfirmModel)
+			}
 
-	case NetworkApprovalRequestMsg:
-		// Show the network approval confirmation dialog
-		m.inNetworkConfirm = true
-		m.state = StateMemoryApproval // Reuse the same state for approval dialogs
-		m.thinking = false
+		case NetworkApprovalRequest:
+			m.inNetworkConfirm = true
 
-		// Create result channel for the confirmation
-		resultChan := make(chan NetworkApprovalResult, 1)
-		m.networkApprovalChan = resultChan
-		m.networkConfirmModel = NewNetworkConfirmModel(msg.Request, resultChan)
+			resultChan := make(chan NetworkApprovalResult, 1)
+			m.networkApprovalChan = resultChan
+			m.networkConfirmModel = NewNetworkConfirmModel(req, resultChan)
 
-		// Update dimensions for the confirm model
-		if m.width > 0 && m.height > 0 {
-			networkModel, _ := m.networkConfirmModel.Update(tea.WindowSizeMsg{
-				Width:  m.width,
-				Height: m.height,
-			})
-			m.networkConfirmModel = networkModel.(NetworkConfirmModel)
+			if m.width > 0 && m.height > 0 {
+				networkModel, _ := m.networkConfirmModel.Update(tea.WindowSizeMsg{
+					Width:  m.width,
+					Height: m.height,
+				})
+				m.networkConfirmModel = networkModel.(NetworkConfirmModel)
+			}
+
+		case ToolApprovalRequest:
+			m.inToolConfirm = true
+
+			resultChan := make(chan ToolApprovalResult, 1)
+			m.toolApprovalChan = resultChan
+			m.toolConfirmModel = NewToolConfirmModel(req, resultChan)
+
+			if m.width > 0 && m.height > 0 {
+				toolModel, _ := m.toolConfirmModel.Update(tea.WindowSizeMsg{
+					Width:  m.width,
+					Height: m.height,
+				})
+				m.toolConfirmModel = toolModel.(ToolConfirmModel)
+			}
+
+		default:
+			// No dialog for this request: answer no rather than leave the
+			// agent waiting.
+			if agent, ok := m.agentRunner.(*DeerAgent); ok {
+				agent.HandleApprovalResponse(msg.Kind, false)
+			}
+			m.addSystemMessage(fmt.Sprintf("Cannot show %s approval; denied.", msg.Kind))
+			m.state = StateThinking
+			m.thinking = true
+			m.updateViewportContent(true)
+			return m, tea.Batch(ThinkingCmd(), m.listenForStatus())
 		}
 
 		return m, nil
@@ -1600,6 +1687,11 @@ func (m Model) View() string {
 		return m.sourcePrepareConfirmModel.View()
 	}
 
+	// Show tool approval dialog if in confirmation mode
+	if m.inToolConfirm {
+		return m.toolConfirmModel.View()
+	}
+
 	// Show source access elevation dialog if in confirmation mode
 	if m.inSourceAccessConfirm {
 		return m.sourceAccessConfirmModel.View()
@@ -2162,6 +2254,13 @@ func (m *Model) updateViewportContent(forceScroll bool) {
 					case "create_sandbox":
 						if src, ok := m.currentToolArgs["source_vm"].(string); ok {
 							statusText = fmt.Sprintf(" Creating sandbox from: %s", src)
+							if dryRun, _ := m.currentToolArgs["dry_run"].(bool); dryRun {
+								statusText = fmt.Sprintf(" Planning sandbox from: %s", src)
+							}
+						}
+					case "create_from_template":
+						if name, ok := m.currentToolArgs["template"].(string); ok {
+							statusText = fmt.Sprintf(" Creating sandbox from template: %s", name)
 						}
 					case "destroy_sandbox":
 						if id, ok := m.currentToolArgs["sandbox_id"].(string); ok {
@@ -2325,7 +2424,7 @@ func (m *Model) formatToolOutput(toolName string, args, result map[string]any) s
 			}
 		}
 
-	case "create_sandbox":
+	case "create_sandbox", "create_from_template":
 		if id, ok := result["sandbox_id"]; ok {
 			b.WriteString(m.styles.ToolDetails.Render(fmt.Sprintf("      ID: %v", id)))
 			b.WriteString("\n")
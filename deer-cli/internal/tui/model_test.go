@@ -235,6 +235,32 @@ func TestSandboxCreateProgressDoneWithoutDetailsClosesWithoutOverwriting(t *test
 	}
 }
 
+func TestModelStreamedResponseUpdatesInPlace(t *testing.T) {
+	model, _ := newTestModel(t)
+	model.state = StateThinking
+	model.thinking = true
+	before := len(model.conversation)
+
+	for _, resp := range []AgentResponse{
+		{Content: "Checking", Partial: true},
+		{Content: "Checking nginx.", Partial: true},
+		{Content: "Checking nginx."},
+		{Content: "It is running.", Done: true},
+	} {
+		model.statusChan <- AgentResponseMsg{Response: resp}
+		updated, _ := model.Update(dequeueStatus(t, model))
+		model = updated.(Model)
+	}
+
+	got := model.conversation[before:]
+	if len(got) != 2 || got[0].Content != "Checking nginx." || got[1].Content != "It is running." {
+		t.Fatalf("conversation = %+v, want the streamed reply once, then the final one", got)
+	}
+	if model.streaming || model.state != StateIdle {
+		t.Errorf("streaming = %v, state = %v after the run finished", model.streaming, model.state)
+	}
+}
+
 func TestModelConsecutiveRunsAfterPrepareShowSourceToolResults(t *testing.T) {
 	model, _ := newTestModel(t)
 
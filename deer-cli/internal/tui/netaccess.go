@@ -0,0 +1,491 @@
+package tui
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+// networkTools are commands that reach the network and need approval when
+// they appear in command position.
+var networkTools = map[string]bool{
+	"curl": true, "wget": true, "nc": true, "netcat": true, "ssh": true, "scp": true,
+	"rsync": true, "ftp": true, "sftp": true, "telnet": true, "nmap": true, "ping": true,
+}
+
+// commandWrappers run their arguments as a command, so the word after them
+// (and their flags) is also in command position.
+var commandWrappers = map[string]bool{
+	"sudo": true, "env": true, "time": true, "nohup": true, "exec": true,
+	"command": true, "nice": true, "xargs": true, "timeout": true, "watch": true,
+	"busybox": true, "toybox": true,
+}
+
+// shells run the script passed to -c, which is checked as a command line of
+// its own.
+var shells = map[string]bool{
+	"sh": true, "bash": true, "dash": true, "zsh": true, "ksh": true, "ash": true,
+}
+
+// detectNetworkAccess checks if a command uses network tools and extracts URLs
+// Returns the network tool name (empty if none) and any URLs found.
+// extraTools are treated as network tools in addition to the built-in set.
+func detectNetworkAccess(command string, extraTools ...string) (string, []string) {
+	var detectedTool string
+	var urls []string
+	for _, words := range shellCommands(command) {
+		if script, ok := nestedScript(words); ok {
+			tool, nested := detectNetworkAccess(script, extraTools...)
+			if detectedTool == "" {
+				detectedTool = tool
+			}
+			urls = append(urls, nested...)
+			continue
+		}
+		if detectedTool == "" {
+			detectedTool = networkToolIn(words, extraTools)
+		}
+		for _, word := range words {
+			if strings.HasPrefix(word, "http://") || strings.HasPrefix(word, "https://") ||
+				strings.HasPrefix(word, "ftp://") || strings.HasPrefix(word, "sftp://") {
+				urls = append(urls, word)
+			}
+		}
+	}
+	if detectedTool == "" {
+		return "", nil
+	}
+	return detectedTool, urls
+}
+
+// networkDecision is the outcome of checking a command's URLs against the
+// configured network policy.
+type networkDecision int
+
+const (
+	networkPrompt networkDecision = iota
+	networkAllow
+	networkDeny
+)
+
+// evaluateNetworkPolicy checks the destinations from networkTargets. It
+// denies when any destination is a denied host, allows only when every
+// network command names at least one destination and all of them are
+// allowed hosts, and otherwise asks for approval. The returned host is the
+// one that decided a denial.
+func evaluateNetworkPolicy(policy config.NetworkPolicyConfig, targets [][]string) (networkDecision, string) {
+	if len(targets) == 0 {
+		return networkPrompt, ""
+	}
+	allowed := true
+	for _, hosts := range targets {
+		if len(hosts) == 0 {
+			allowed = false
+		}
+		for _, host := range hosts {
+			if hostMatches(host, policy.DenyHosts) {
+				return networkDeny, host
+			}
+			if !hostMatches(host, policy.AllowHosts) {
+				allowed = false
+			}
+		}
+	}
+	if allowed {
+		return networkAllow, ""
+	}
+	return networkPrompt, ""
+}
+
+// networkTargets returns, for each simple command in command that runs a
+// network tool, the hosts its arguments may name. Every argument that could
+// be a host counts, with or without a URL scheme, so a command is only
+// auto-approved when nothing it is given falls outside the allow list.
+// Options such as curl's --resolve or -x can send traffic somewhere other
+// than the hosts named, so any option that is not a known plain flag adds
+// an empty host, which matches neither list and forces the prompt.
+func networkTargets(command string, extraTools ...string) [][]string {
+	var out [][]string
+	for _, words := range shellCommands(command) {
+		if script, ok := nestedScript(words); ok {
+			out = append(out, networkTargets(script, extraTools...)...)
+			continue
+		}
+		tool := networkToolIn(words, extraTools)
+		if tool == "" {
+			continue
+		}
+		hosts := []string{}
+		options := true
+		for _, word := range words[commandIndex(words)+1:] {
+			if word == "--" {
+				options = false
+				continue
+			}
+			if options && len(word) > 1 && word[0] == '-' && !isPlainFlag(tool, word) {
+				hosts = append(hosts, "")
+			}
+			if host := targetHost(word); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+		out = append(out, hosts)
+	}
+	return out
+}
+
+// plainFlags lists, per network tool, the options known to take no value
+// and not to change where the tool connects: the letters allowed in a short
+// option cluster such as -fsSL, and whole long options.
+var plainFlags = map[string]struct {
+	short string
+	long  []string
+}{
+	"curl": {"fsSLIikv", []string{
+		"--fail", "--silent", "--show-error", "--location", "--head", "--include",
+		"--insecure", "--verbose", "--compressed",
+	}},
+	"wget": {"qvcNS", []string{
+		"--quiet", "--verbose", "--no-verbose", "--continue", "--timestamping",
+		"--server-response", "--spider",
+	}},
+	"rsync": {"avzrlptgoDhPnuc", []string{
+		"--archive", "--verbose", "--compress", "--recursive", "--delete",
+		"--progress", "--partial", "--dry-run", "--checksum",
+	}},
+	"ping": {"qn", nil},
+}
+
+// isPlainFlag reports whether option is one of tool's plainFlags. Options
+// of other tools, options with a value and unknown options all report
+// false.
+func isPlainFlag(tool, option string) bool {
+	flags, ok := plainFlags[tool]
+	if !ok {
+		return false
+	}
+	if strings.HasPrefix(option, "--") {
+		return slices.Contains(flags.long, option)
+	}
+	for _, r := range option[1:] {
+		if !strings.ContainsRune(flags.short, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hostWord matches a bare host name or IPv4 address.
+var hostWord = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
+// targetHost returns the host word may name: a URL's host, or the host in
+// user@host:port/path forms. Options, paths and file references return "".
+// A word built from a variable or substitution is returned as is, so it can
+// never match the allow list.
+func targetHost(word string) string {
+	if strings.HasPrefix(word, "--") {
+		_, value, ok := strings.Cut(word, "=")
+		if !ok {
+			return ""
+		}
+		word = value
+	}
+	if word == "" || strings.ContainsAny(word[:1], "-/.~@") {
+		return ""
+	}
+	if strings.ContainsAny(word, "$`") {
+		return word
+	}
+	if strings.Contains(word, "://") {
+		return urlHost(word)
+	}
+	host := word
+	if at := strings.LastIndexByte(host, '@'); at >= 0 {
+		host = host[at+1:]
+	}
+	if cut := strings.IndexAny(host, ":/"); cut >= 0 {
+		host = host[:cut]
+	}
+	host = strings.ToLower(host)
+	if !hostWord.MatchString(host) {
+		return ""
+	}
+	return host
+}
+
+// urlHost returns the lower-cased host of raw without its port, or "" if
+// raw does not parse.
+func urlHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// hostMatches reports whether host equals one of patterns or is a subdomain
+// of one.
+func hostMatches(host string, patterns []string) bool {
+	if host == "" {
+		return false
+	}
+	for _, p := range patterns {
+		p = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(p), "*."))
+		if p != "" && (host == p || strings.HasSuffix(host, "."+p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// networkToolIn returns the network tool a simple command runs, looking past
+// environment assignments and wrappers such as sudo, or "" if it runs none.
+func networkToolIn(words, extraTools []string) string {
+	i := commandIndex(words)
+	if i < 0 {
+		return ""
+	}
+	name := commandName(words[i])
+	if networkTools[name] {
+		return name
+	}
+	for _, tool := range extraTools {
+		if strings.EqualFold(tool, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// nestedScript returns the command line a simple command hands to a shell:
+// the script after sh -c (or bash -lc and the like), or the arguments of
+// eval.
+func nestedScript(words []string) (string, bool) {
+	i := commandIndex(words)
+	if i < 0 {
+		return "", false
+	}
+	name, args := commandName(words[i]), words[i+1:]
+	if name == "eval" {
+		return strings.Join(args, " "), len(args) > 0
+	}
+	if !shells[name] {
+		return "", false
+	}
+	for j := 0; j < len(args); j++ {
+		arg := args[j]
+		switch {
+		case arg == "-o" || arg == "+o":
+			j++ // takes an option name
+		case strings.HasPrefix(arg, "--"):
+		case strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "+"):
+			if strings.ContainsRune(arg[1:], 'c') && j+1 < len(args) {
+				return args[j+1], true
+			}
+		default:
+			return "", false // a script file
+		}
+	}
+	return "", false
+}
+
+// commandIndex returns the index in words of the program a simple command
+// runs, looking past environment assignments and wrappers such as sudo or
+// busybox, or -1 if there is none.
+func commandIndex(words []string) int {
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if isEnvAssignment(word) {
+			continue
+		}
+		name := commandName(word)
+		if !commandWrappers[name] {
+			return i
+		}
+		// Skip the wrapper's options. sudo's -u/-g take a value, and
+		// timeout's first operand is its duration.
+		for i+1 < len(words) && strings.HasPrefix(words[i+1], "-") {
+			i++
+			if name == "sudo" && (words[i] == "-u" || words[i] == "-g") {
+				i++
+			}
+		}
+		if name == "timeout" && i+1 < len(words) {
+			i++
+		}
+	}
+	return -1
+}
+
+// commandName returns the lower-cased program name of word.
+func commandName(word string) string {
+	return strings.ToLower(path.Base(word))
+}
+
+// isEnvAssignment reports whether word is a VAR=value prefix.
+func isEnvAssignment(word string) bool {
+	eq := strings.IndexByte(word, '=')
+	if eq <= 0 {
+		return false
+	}
+	for i, r := range word[:eq] {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// shellCommands splits a shell command line into its simple commands, each
+// as a list of unquoted words. Commands are separated by unquoted |, ||, &,
+// &&, ;, newlines and parentheses; $(...) and backtick substitutions are
+// returned as commands of their own, including inside double quotes.
+// Comments are dropped. It is a heuristic for spotting which programs a line
+// runs, not a full shell parser.
+func shellCommands(line string) [][]string {
+	type frame struct {
+		words    []string
+		word     strings.Builder
+		inWord   bool
+		inDouble bool
+		closer   rune // ')' or '`' ending a substitution
+		subshell bool // marks an open ( ... ) rather than a saved command
+	}
+	var (
+		out   [][]string
+		stack []*frame
+		cur   = &frame{}
+	)
+
+	endWord := func() {
+		if cur.inWord {
+			cur.words = append(cur.words, cur.word.String())
+			cur.word.Reset()
+			cur.inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(cur.words) > 0 {
+			out = append(out, cur.words)
+		}
+		cur.words = nil
+	}
+	// A substitution saves the enclosing command, whose current word
+	// continues after the closer.
+	openSubst := func(closer rune) {
+		stack = append(stack, cur)
+		cur = &frame{closer: closer}
+	}
+	closeSubst := func() {
+		endCommand()
+		cur = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		cur.inWord = true
+	}
+	inSubshell := func() bool {
+		return len(stack) > 0 && stack[len(stack)-1].subshell
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case ch == '\\' && next != 0:
+			cur.word.WriteRune(next)
+			cur.inWord = true
+			i++
+		case ch == '$' && next == '(':
+			i++
+			openSubst(')')
+		case ch == '`' && cur.closer == '`' && !inSubshell():
+			closeSubst()
+		case ch == '`':
+			openSubst('`')
+		case cur.inDouble:
+			if ch == '"' {
+				cur.inDouble = false
+			} else {
+				cur.word.WriteRune(ch)
+			}
+		case ch == '"':
+			cur.inDouble = true
+			cur.inWord = true
+		case ch == '\'':
+			end := strings.IndexRune(string(runes[i+1:]), '\'')
+			if end < 0 {
+				cur.word.WriteString(string(runes[i+1:]))
+				i = len(runes)
+			} else {
+				rest := []rune(string(runes[i+1:])[:end])
+				cur.word.WriteString(string(rest))
+				i += len(rest) + 1
+			}
+			cur.inWord = true
+		case ch == '#' && !cur.inWord:
+			for i+1 < len(runes) && runes[i+1] != '\n' {
+				i++
+			}
+		case ch == ' ' || ch == '\t':
+			endWord()
+		case ch == '|' || ch == '&' || ch == ';' || ch == '\n':
+			endCommand()
+		case ch == '(':
+			endCommand()
+			stack = append(stack, &frame{subshell: true})
+		case ch == ')' && inSubshell():
+			endCommand()
+			stack = stack[:len(stack)-1]
+		case ch == ')' && cur.closer == ')':
+			closeSubst()
+		case ch == ')':
+			endCommand()
+		default:
+			cur.word.WriteRune(ch)
+			cur.inWord = true
+		}
+	}
+
+	// Flush anything left open by unbalanced quotes or substitutions.
+	for {
+		endCommand()
+		if len(stack) == 0 {
+			break
+		}
+		if top := stack[len(stack)-1]; !top.subshell {
+			cur = top
+		}
+		stack = stack[:len(stack)-1]
+	}
+	return out
+}
+
+// ToolApproval describes when the agent asks the human before running tool
+// under policy: "denied" when the tool_approval policy refuses it, "always"
+// when the policy says confirm and for source access requests, "network"
+// for sandbox commands that reach the network (subject to network_policy's
+// allow and deny hosts), and "never" for everything else.
+func ToolApproval(policy config.ToolApprovalPolicy, tool string) string {
+	switch policy.Decision(tool) {
+	case config.ToolApprovalDeny:
+		return "denied"
+	case config.ToolApprovalConfirm:
+		return "always"
+	}
+	switch tool {
+	case "request_source_access":
+		return "always"
+	case "run_command":
+		return "network"
+	}
+	return "never"
+}
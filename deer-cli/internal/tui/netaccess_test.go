@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-cli/internal/config"
+)
+
+func TestDetectNetworkAccess(t *testing.T) {
+	tests := []struct {
+		command string
+		tool    string
+		urls    []string
+	}{
+		{command: `echo "curl is great"`},
+		{command: `echo x | curl example.com`, tool: "curl"},
+		{command: `cat curl-config.txt`},
+		{command: `ls # then curl it`},
+		{command: `grep -r 'wget ' /etc`},
+		{command: `curl https://example.com/a`, tool: "curl", urls: []string{"https://example.com/a"}},
+		{command: `wget -q "https://example.com/b"`, tool: "wget", urls: []string{"https://example.com/b"}},
+		{command: `apt-get update && ping -c1 8.8.8.8`, tool: "ping"},
+		{command: `true;ssh host uptime`, tool: "ssh"},
+		{command: `echo $(curl -s http://x)`, tool: "curl", urls: []string{"http://x"}},
+		{command: `echo "ip: $(curl ifconfig.me)"`, tool: "curl"},
+		{command: "echo `nc -z db 5432`", tool: "nc"},
+		{command: `(cd /tmp && wget http://y)`, tool: "wget", urls: []string{"http://y"}},
+		{command: `sudo -u app /usr/bin/rsync -a src dst`, tool: "rsync"},
+		{command: `HTTPS_PROXY=p timeout 5 curl x`, tool: "curl"},
+		{command: `CURL`, tool: "curl"},
+		{command: `echo 'a' "b" \curl`, tool: ""},
+		{command: `sh -c 'curl https://example.com/c'`, tool: "curl", urls: []string{"https://example.com/c"}},
+		{command: `bash -lc "wget -q http://z && echo ok"`, tool: "wget", urls: []string{"http://z"}},
+		{command: `sudo bash -o pipefail -c 'true | nc -z db 5432'`, tool: "nc"},
+		{command: `eval curl https://e.example`, tool: "curl", urls: []string{"https://e.example"}},
+		{command: `busybox wget http://w`, tool: "wget", urls: []string{"http://w"}},
+		{command: `sh -c 'echo hi'`},
+		{command: `bash ./install.sh curl`},
+	}
+	for _, tt := range tests {
+		tool, urls := detectNetworkAccess(tt.command)
+		if tool != tt.tool || !reflect.DeepEqual(urls, tt.urls) {
+			t.Errorf("detectNetworkAccess(%q) = %q, %v; want %q, %v", tt.command, tool, urls, tt.tool, tt.urls)
+		}
+	}
+}
+
+func TestShellCommands(t *testing.T) {
+	got := shellCommands(`A=1 echo "x; y" | grep 'a|b' && (ls -l) # tail`)
+	want := [][]string{{"A=1", "echo", "x; y"}, {"grep", "a|b"}, {"ls", "-l"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("shellCommands = %q, want %q", got, want)
+	}
+}
+
+func TestDetectNetworkAccess_ExtraTools(t *testing.T) {
+	if tool, _ := detectNetworkAccess("aws s3 ls"); tool != "" {
+		t.Errorf("aws detected without being configured: %q", tool)
+	}
+	if tool, _ := detectNetworkAccess("aws s3 ls", "AWS", "gh"); tool != "aws" {
+		t.Errorf("tool = %q, want aws", tool)
+	}
+}
+
+func TestEvaluateNetworkPolicy(t *testing.T) {
+	policy := config.NetworkPolicyConfig{
+		AllowHosts: []string{"mirror.internal", "*.pkg.example.com"},
+		DenyHosts:  []string{"evil.example"},
+	}
+	tests := []struct {
+		command string
+		want    networkDecision
+	}{
+		{`curl http://mirror.internal/debian/`, networkAllow},
+		{`curl -fsSL https://MIRROR.internal:8443/x https://eu.pkg.example.com/y`, networkAllow},
+		{`curl -fsSL https://MIRROR.internal:8443/x -o /tmp/x`, networkPrompt},
+		{`wget https://sub.mirror.internal/z`, networkAllow},
+		{`curl mirror.internal/debian/`, networkAllow},
+		{`rsync -a /src deploy@mirror.internal:/srv/`, networkAllow},
+		{`curl https://notmirror.internal/`, networkPrompt},
+		{`curl http://mirror.internal/ https://github.com/`, networkPrompt},
+		{`curl https://mirror.internal && ssh attacker`, networkPrompt},
+		{`curl https://mirror.internal; sh -c 'curl github.com'`, networkPrompt},
+		{`curl $URL`, networkPrompt},
+		{`curl -s`, networkPrompt},
+		{`curl --resolve mirror.internal:443:10.0.0.5 https://mirror.internal`, networkPrompt},
+		{`curl --connect-to mirror.internal:443:other.host:443 https://mirror.internal`, networkPrompt},
+		{`curl --proxy mirror.internal:3128 https://mirror.internal`, networkPrompt},
+		{`curl -x mirror.internal:3128 https://mirror.internal`, networkPrompt},
+		{`curl -sx mirror.internal:3128 https://mirror.internal`, networkPrompt},
+		{`curl --unknown-flag https://mirror.internal`, networkPrompt},
+		{`ssh -o ProxyCommand=nc mirror.internal`, networkPrompt},
+		{`curl -x evil.example https://mirror.internal`, networkDeny},
+		{`curl http://mirror.internal/ http://cdn.evil.example/x`, networkDeny},
+		{`curl evil.example`, networkDeny},
+		{`ssh root@cdn.evil.example uptime`, networkDeny},
+		{`curl --url=evil.example`, networkDeny},
+		{`ls`, networkPrompt},
+	}
+	for _, tt := range tests {
+		if got, _ := evaluateNetworkPolicy(policy, networkTargets(tt.command)); got != tt.want {
+			t.Errorf("evaluateNetworkPolicy(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package workflow
 import (
 	"errors"
 	"fmt"
+
+	"github.com/aspectrr/deer.sh/shared/multierr"
 )
 
 // Sentinel errors for workflow stages. These allow callers to identify
@@ -134,7 +136,7 @@ func (s *CleanupStack) ExecuteAll() error {
 		}
 	}
 	if len(errs) > 0 {
-		return fmt.Errorf("%w: %d cleanup(s) failed: %v", ErrRollbackFailed, len(errs), errs)
+		return fmt.Errorf("%w: %d cleanup(s) failed: %w", ErrRollbackFailed, len(errs), multierr.Combine(errs...))
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -28,11 +29,14 @@ import (
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/id"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/image"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/janitor"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/metrics"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/microvm"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/network"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	dockerProvider "github.com/aspectrr/deer.sh/deer-daemon/internal/provider/docker"
 	lxcProvider "github.com/aspectrr/deer.sh/deer-daemon/internal/provider/lxc"
 	microvmProvider "github.com/aspectrr/deer.sh/deer-daemon/internal/provider/microvm"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/readonly"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/redact"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/snapshotpull"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/sourcevm"
@@ -74,6 +78,9 @@ func run(ctx context.Context, logger *slog.Logger) error {
 	if err != nil {
 		return err
 	}
+	if err := cfg.Libvirt.Validate(); err != nil {
+		return fmt.Errorf("invalid config %s: %w", cfgPath, err)
+	}
 
 	// Ensure host ID
 	if cfg.HostID == "" {
@@ -118,7 +125,7 @@ func run(ctx context.Context, logger *slog.Logger) error {
 
 	switch cfg.Provider {
 	case "lxc":
-		prov, err = initLXCProvider(cfg, logger)
+		prov, err = initLXCProvider(ctx, cfg, logger)
 		if err != nil {
 			return err
 		}
@@ -126,6 +133,12 @@ func run(ctx context.Context, logger *slog.Logger) error {
 			"host", cfg.LXC.Host,
 			"node", cfg.LXC.Node,
 		)
+	case "docker", "podman":
+		prov, err = initDockerProvider(ctx, cfg, logger)
+		if err != nil {
+			return err
+		}
+		logger.Info("Docker provider initialized", "host", cfg.Docker.Host)
 	default: // "microvm" or empty (default)
 		prov, keyMgr, caPubKey, err = initMicroVMProvider(ctx, cfg, logger)
 		if err != nil {
@@ -140,17 +153,62 @@ func run(ctx context.Context, logger *slog.Logger) error {
 	if err := prov.RecoverState(ctx); err != nil {
 		logger.Warn("state recovery failed", "error", err)
 	}
+	daemon.ReattachImportedSandboxes(ctx, st, prov, logger)
+	daemon.ReconcileInterruptedCreates(ctx, st, prov, logger, time.Now())
+	daemon.ReportOrphans(ctx, st, prov, logger)
+
+	// Start the metrics endpoint, if configured
+	var met *metrics.Metrics
+	if cfg.Metrics.ListenAddr != "" {
+		met = metrics.New()
+		if mp, ok := prov.(interface{ SetMetrics(*metrics.Metrics) }); ok {
+			mp.SetMetrics(met)
+		} else {
+			logger.Info("provider does not report metrics; only janitor series are exported", "provider", cfg.Provider)
+		}
+		if err := startMetricsServer(ctx, cfg.Metrics.ListenAddr, met, logger); err != nil {
+			return err
+		}
+	}
 
-	// Initialize janitor
+	// Initialize janitor. Without the daemon service, expired sandboxes are
+	// destroyed directly; with it, the service destroys them so they are
+	// archived and cleaned up like client destroys. The janitor starts once
+	// the service is wired in.
 	destroyFn := func(ctx context.Context, sandboxID string) error {
-		if err := prov.DestroySandbox(ctx, sandboxID); err != nil {
+		// Skip sandboxes a client is currently mutating; the next pass retries.
+		if err := st.AcquireSandboxLock(ctx, sandboxID, "janitor", 15*time.Minute); err != nil {
+			return err
+		}
+		defer func() { _ = st.ReleaseSandboxLock(context.Background(), sandboxID, "janitor") }()
+
+		if err := prov.DestroySandbox(ctx, sandboxID); err != nil && !errors.Is(err, provider.ErrSandboxNotFound) {
 			return err
 		}
 		return st.DeleteSandbox(ctx, sandboxID)
 	}
 
+	stopFn := func(ctx context.Context, sandboxID string) error {
+		if err := st.AcquireSandboxLock(ctx, sandboxID, "janitor", 15*time.Minute); err != nil {
+			return err
+		}
+		defer func() { _ = st.ReleaseSandboxLock(context.Background(), sandboxID, "janitor") }()
+
+		if err := prov.StopSandbox(ctx, sandboxID, false); err != nil {
+			return err
+		}
+		sb, err := st.GetSandbox(ctx, sandboxID)
+		if err != nil {
+			return err
+		}
+		sb.State = "STOPPED"
+		sb.UpdatedAt = time.Now().UTC()
+		return st.UpdateSandbox(ctx, sb)
+	}
+
 	jan := janitor.New(st, destroyFn, cfg.Janitor.DefaultTTL, logger)
-	go jan.Start(ctx, cfg.Janitor.Interval)
+	jan.SetIdleStop(stopFn, cfg.Janitor.IdleTimeout)
+	jan.SetMetrics(met)
 
 	// Initialize snapshot puller
 	imgStore, err := image.NewStore(cfg.Image.BaseDir, logger)
@@ -158,6 +216,7 @@ func run(ctx context.Context, logger *slog.Logger) error {
 		return fmt.Errorf("init image store for puller: %w", err)
 	}
 	puller := snapshotpull.NewPuller(imgStore, st.DB(), logger)
+	puller.SetDownloadLimits(cfg.Image.MaxDownloadBPS, cfg.Image.MaxTotalDownloadBPS)
 
 	// Initialize redactor
 	redactor := redact.New()
@@ -199,8 +258,12 @@ func run(ctx context.Context, logger *slog.Logger) error {
 	// Start DaemonService gRPC server (inbound from CLI)
 	if cfg.Daemon.Enabled {
 		daemonSrv := daemon.NewServer(cfg, prov, st, puller, keyMgr, tele, redactor, auditLog, cfg.HostID, version, cfg.SSH.IdentityFile, caPubKey, identityPubKey, logger)
+		daemonSrv.SetJanitor(jan)
+		jan.SetDestroy(daemonSrv.ExpireSandbox)
+		jan.SetBusy(daemonSrv.CommandRunning)
 		grpcServer := grpc.NewServer(
 			grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+				defer daemonSrv.TrackRPC()()
 				defer func() {
 					if r := recover(); r != nil {
 						logger.Error("panic recovered in gRPC handler", "method", info.FullMethod, "panic", r)
@@ -210,6 +273,7 @@ func run(ctx context.Context, logger *slog.Logger) error {
 				return handler(ctx, req)
 			}),
 			grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+				defer daemonSrv.TrackRPC()()
 				defer func() {
 					if r := recover(); r != nil {
 						logger.Error("panic recovered in gRPC stream handler", "method", info.FullMethod, "panic", r)
@@ -232,11 +296,11 @@ func run(ctx context.Context, logger *slog.Logger) error {
 				logger.Error("daemon gRPC server error", "error", err)
 			}
 		}()
-		go func() {
-			<-ctx.Done()
-			grpcServer.GracefulStop()
-		}()
+		// Runs when run returns, after the shutdown signal: deferred calls
+		// registered earlier (audit log, state store) are still open.
+		defer drainAndStop(grpcServer, daemonSrv, cfg.Daemon.DrainTimeout, logger)
 	}
+	go jan.Start(ctx, cfg.Janitor.Interval)
 
 	logger.Info("sandbox-host ready",
 		"host_id", cfg.HostID,
@@ -257,6 +321,7 @@ func run(ctx context.Context, logger *slog.Logger) error {
 				KeyFile:         cfg.ControlPlane.KeyFile,
 				CAFile:          cfg.ControlPlane.CAFile,
 				SSHIdentityFile: cfg.SSH.IdentityFile,
+				SourceVMUser:    cfg.SourceVMUserFor(""),
 			},
 			prov,
 			st,
@@ -289,6 +354,67 @@ func run(ctx context.Context, logger *slog.Logger) error {
 	return nil
 }
 
+// drainAndStop shuts the gRPC server down without aborting work in flight.
+// It stops accepting new sandboxes, waits up to timeout for running
+// requests to finish, then stops the server. A second SIGINT or SIGTERM
+// during the drain stops it immediately.
+func drainAndStop(srv *grpc.Server, daemonSrv *daemon.Server, timeout time.Duration, logger *slog.Logger) {
+	force := make(chan os.Signal, 1)
+	signal.Notify(force, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(force)
+
+	daemonSrv.Drain()
+	logger.Info("draining in-flight requests before shutdown", "in_flight", daemonSrv.InFlight(), "drain_timeout", timeout)
+
+	waitCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if timeout > 0 {
+		var stop context.CancelFunc
+		waitCtx, stop = context.WithTimeout(waitCtx, timeout)
+		defer stop()
+	}
+	go func() {
+		select {
+		case <-force:
+			logger.Warn("second shutdown signal, stopping immediately")
+			cancel()
+		case <-waitCtx.Done():
+		}
+	}()
+
+	if err := daemonSrv.WaitIdle(waitCtx); err != nil {
+		logger.Warn("drain incomplete, stopping with requests in flight", "in_flight", daemonSrv.InFlight(), "error", err)
+		srv.Stop()
+		return
+	}
+	srv.GracefulStop()
+	logger.Info("drain complete")
+}
+
+// startMetricsServer serves m at /metrics on addr until ctx is done.
+func startMetricsServer(ctx context.Context, addr string, m *metrics.Metrics, logger *slog.Logger) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen for metrics on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+			logger.Warn("metrics server error", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	logger.Info("metrics server started", "addr", lis.Addr().String())
+	return nil
+}
+
 func initMicroVMProvider(ctx context.Context, cfg *config.Config, logger *slog.Logger) (provider.SandboxProvider, sshkeys.KeyProvider, string, error) {
 	// Initialize microVM manager
 	vmMgr, err := microvm.NewManager(cfg.MicroVM.QEMUBinary, cfg.MicroVM.WorkDir, logger)
@@ -306,9 +432,14 @@ func initMicroVMProvider(ctx context.Context, cfg *config.Config, logger *slog.L
 		cfg.Network.DHCPMode,
 		logger,
 	)
+	netMgr.SetPollInterval(cfg.Network.IPPollInterval)
+	if err := netMgr.SetIsolationMode(cfg.Network.IsolationMode); err != nil {
+		return nil, nil, "", fmt.Errorf("network config: %w", err)
+	}
 	logger.Info("network manager initialized",
 		"default_bridge", cfg.Network.DefaultBridge,
 		"dhcp_mode", cfg.Network.DHCPMode,
+		"isolation_mode", netMgr.IsolationMode(),
 	)
 
 	// Initialize image store
@@ -377,6 +508,14 @@ func initMicroVMProvider(ctx context.Context, cfg *config.Config, logger *slog.L
 					caPubKey,
 					logger,
 				)
+				srcVMMgr.SetReadyTimeout(cfg.MicroVM.IPDiscoveryTimeout)
+				srcVMMgr.SetPolicy(&readonly.Policy{Allow: cfg.Readonly.Allow, Deny: cfg.Readonly.Deny})
+				if cfg.Libvirt.SASLUser != "" {
+					authFile := filepath.Join(filepath.Dir(cfg.State.DBPath), "libvirt-auth.conf")
+					if err := srcVMMgr.SetSASLAuth(authFile, cfg.Libvirt.SASLUser, cfg.Libvirt.SASLPassword); err != nil {
+						return nil, nil, "", fmt.Errorf("configure libvirt SASL auth: %w", err)
+					}
+				}
 				logger.Info("source VM manager initialized",
 					"libvirt_uri", cfg.Libvirt.URI,
 					"network", cfg.Libvirt.Network,
@@ -418,25 +557,62 @@ func initMicroVMProvider(ctx context.Context, cfg *config.Config, logger *slog.L
 	// pass nil directly to avoid the nil-typed-pointer-in-interface trap
 	// where a nil *ReadinessServer stored in a ReadinessWaiter interface
 	// is non-nil, causing a panic on method calls.
+	var prov *microvmProvider.Provider
 	if readiness != nil {
-		return microvmProvider.New(vmMgr, netMgr, imgStore, srcVMMgr, keyMgr, cfg.MicroVM.KernelPath, cfg.MicroVM.InitrdPath, cfg.MicroVM.RootDevice, cfg.MicroVM.Accel, cfg.MicroVM.IPDiscoveryTimeout, cfg.MicroVM.ReadinessTimeout, caPubKey, bridgeIP, readiness, redpandaCacheURL, disableCloudInit, cfg.MicroVM.SocketVMNetClient, cfg.MicroVM.SocketVMNetPath, logger), keyMgr, caPubKey, nil
+		prov = microvmProvider.New(vmMgr, netMgr, imgStore, srcVMMgr, keyMgr, cfg.MicroVM.KernelPath, cfg.MicroVM.InitrdPath, cfg.MicroVM.RootDevice, cfg.MicroVM.Accel, cfg.MicroVM.IPDiscoveryTimeout, cfg.MicroVM.ReadinessTimeout, caPubKey, bridgeIP, readiness, redpandaCacheURL, disableCloudInit, cfg.MicroVM.SocketVMNetClient, cfg.MicroVM.SocketVMNetPath, logger)
+	} else {
+		prov = microvmProvider.New(vmMgr, netMgr, imgStore, srcVMMgr, keyMgr, cfg.MicroVM.KernelPath, cfg.MicroVM.InitrdPath, cfg.MicroVM.RootDevice, cfg.MicroVM.Accel, cfg.MicroVM.IPDiscoveryTimeout, cfg.MicroVM.ReadinessTimeout, caPubKey, bridgeIP, nil, redpandaCacheURL, disableCloudInit, cfg.MicroVM.SocketVMNetClient, cfg.MicroVM.SocketVMNetPath, logger)
+	}
+	if err := prov.SetInjectMethods(cfg.MicroVM.SSHKeyInjectMethod, cfg.MicroVM.ImageInjectMethods); err != nil {
+		return nil, nil, "", fmt.Errorf("microvm.ssh_key_inject_method: %w", err)
 	}
-	return microvmProvider.New(vmMgr, netMgr, imgStore, srcVMMgr, keyMgr, cfg.MicroVM.KernelPath, cfg.MicroVM.InitrdPath, cfg.MicroVM.RootDevice, cfg.MicroVM.Accel, cfg.MicroVM.IPDiscoveryTimeout, cfg.MicroVM.ReadinessTimeout, caPubKey, bridgeIP, nil, redpandaCacheURL, disableCloudInit, cfg.MicroVM.SocketVMNetClient, cfg.MicroVM.SocketVMNetPath, logger), keyMgr, caPubKey, nil
+	if err := prov.SetHostKeyMode(cfg.SSH.HostKeyMode, cfg.SSH.KnownHostsFile); err != nil {
+		return nil, nil, "", fmt.Errorf("ssh config: %w", err)
+	}
+	return prov, keyMgr, caPubKey, nil
 }
 
-func initLXCProvider(cfg *config.Config, logger *slog.Logger) (provider.SandboxProvider, error) {
+func initDockerProvider(ctx context.Context, cfg *config.Config, logger *slog.Logger) (provider.SandboxProvider, error) {
+	prov, err := dockerProvider.New(dockerProvider.Config{
+		Host:        cfg.Docker.Host,
+		Network:     cfg.Docker.Network,
+		Timeout:     cfg.Docker.Timeout,
+		StopTimeout: cfg.Docker.StopTimeout,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := prov.CheckConfig(checkCtx); err != nil {
+		return nil, fmt.Errorf("docker config check: %w", err)
+	}
+	return prov, nil
+}
+
+func initLXCProvider(ctx context.Context, cfg *config.Config, logger *slog.Logger) (provider.SandboxProvider, error) {
 	lxcCfg := lxcProvider.Config{
-		Host:      cfg.LXC.Host,
-		TokenID:   cfg.LXC.TokenID,
-		Secret:    cfg.LXC.Secret,
-		Node:      cfg.LXC.Node,
-		Storage:   cfg.LXC.Storage,
-		Bridge:    cfg.LXC.Bridge,
-		VMIDStart: cfg.LXC.VMIDStart,
-		VMIDEnd:   cfg.LXC.VMIDEnd,
-		VerifySSL: cfg.LXC.VerifySSL,
-		Timeout:   cfg.LXC.Timeout,
-	}
-
-	return lxcProvider.New(lxcCfg, logger)
+		Host:         cfg.LXC.Host,
+		TokenID:      cfg.LXC.TokenID,
+		Secret:       cfg.LXC.Secret,
+		Node:         cfg.LXC.Node,
+		Storage:      cfg.LXC.Storage,
+		Bridge:       cfg.LXC.Bridge,
+		VMIDStart:    cfg.LXC.VMIDStart,
+		VMIDEnd:      cfg.LXC.VMIDEnd,
+		VerifySSL:    cfg.LXC.VerifySSL,
+		Timeout:      cfg.LXC.Timeout,
+		CloneTimeout: cfg.LXC.CloneTimeout,
+	}
+
+	prov, err := lxcProvider.New(lxcCfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := prov.CheckConfig(checkCtx); err != nil {
+		return nil, fmt.Errorf("lxc config check: %w", err)
+	}
+	return prov, nil
 }
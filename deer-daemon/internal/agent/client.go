@@ -18,6 +18,7 @@ import (
 
 	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
 
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/daemon"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/kafkastub"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/snapshotpull"
@@ -42,6 +43,7 @@ type Client struct {
 	keyFile         string
 	caFile          string
 	sshIdentityFile string
+	sourceVMUser    string
 
 	prov       provider.SandboxProvider
 	localStore *state.Store
@@ -73,6 +75,7 @@ type Config struct {
 	KeyFile         string
 	CAFile          string
 	SSHIdentityFile string
+	SourceVMUser    string // user to prepare source VMs as when a command names none
 }
 
 // NewClient creates a new agent client.
@@ -105,6 +108,7 @@ func NewClient(
 		keyFile:         cfg.KeyFile,
 		caFile:          cfg.CAFile,
 		sshIdentityFile: cfg.SSHIdentityFile,
+		sourceVMUser:    cfg.SourceVMUser,
 		prov:            prov,
 		localStore:      localStore,
 		puller:          puller,
@@ -710,7 +714,11 @@ func (c *Client) handleCreateSnapshot(ctx context.Context, reqID string, cmd *de
 func (c *Client) handlePrepareSourceVM(ctx context.Context, reqID string, cmd *deerv1.PrepareSourceVMCommand) *deerv1.HostMessage {
 	vmName := cmd.GetSourceVm()
 
-	result, err := c.prov.PrepareSourceVM(ctx, vmName, cmd.GetSshUser(), cmd.GetSshKeyPath())
+	sshUser := cmd.GetSshUser()
+	if sshUser == "" {
+		sshUser = c.sourceVMUser
+	}
+	result, err := c.prov.PrepareSourceVM(ctx, vmName, sshUser, cmd.GetSshKeyPath())
 	if err != nil {
 		return errorResponse(reqID, "", fmt.Sprintf("prepare source VM %s: %v", vmName, err))
 	}
@@ -810,20 +818,14 @@ func (c *Client) handleValidateSourceVM(ctx context.Context, reqID string, cmd *
 	if err != nil {
 		return errorResponse(reqID, "", fmt.Sprintf("validate source VM: %v", err))
 	}
+	if caps, err := c.prov.Capabilities(ctx); err == nil {
+		result.CheckHostMemory(caps, provider.DefaultSandboxMemMB)
+	}
 
 	return &deerv1.HostMessage{
 		RequestId: reqID,
 		Payload: &deerv1.HostMessage_SourceVmValidation{
-			SourceVmValidation: &deerv1.SourceVMValidation{
-				SourceVm:   result.VMName,
-				Valid:      result.Valid,
-				State:      result.State,
-				MacAddress: result.MACAddress,
-				IpAddress:  result.IPAddress,
-				HasNetwork: result.HasNetwork,
-				Warnings:   result.Warnings,
-				Errors:     result.Errors,
-			},
+			SourceVmValidation: daemon.SourceVMValidationToProto(result),
 		},
 	}
 }
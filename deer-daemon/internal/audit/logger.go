@@ -13,16 +13,26 @@ import (
 )
 
 const (
-	TypeSandboxCreated   = "sandbox_created"
-	TypeSandboxDestroyed = "sandbox_destroyed"
-	TypeSandboxStarted   = "sandbox_started"
-	TypeSandboxStopped   = "sandbox_stopped"
-	TypeCommandExecuted  = "command_executed"
-	TypeSnapshotCreated  = "snapshot_created"
-	TypeSourceCommand    = "source_command"
-	TypeFileRead         = "file_read"
-	TypeSessionStart     = "session_start"
-	TypeSessionEnd       = "session_end"
+	TypeSandboxCreated      = "sandbox_created"
+	TypeSandboxCreateDenied = "sandbox_create_denied"
+	TypeSandboxDestroyed    = "sandbox_destroyed"
+	TypeSandboxStarted      = "sandbox_started"
+	TypeSandboxStopped      = "sandbox_stopped"
+	TypeSandboxImported     = "sandbox_imported"
+	TypeSandboxResized      = "sandbox_resized"
+	TypeSandboxUnlocked     = "sandbox_unlocked"
+	TypeCommandExecuted     = "command_executed"
+	TypeInteractiveSession  = "interactive_session"
+	TypeSSHCredsRenewed     = "ssh_credentials_renewed"
+	TypeSnapshotCreated     = "snapshot_created"
+	TypeSnapshotDeleted     = "snapshot_deleted"
+	TypeSandboxArchived     = "sandbox_archived"
+	TypeSnapshotFileRestore = "snapshot_file_restored"
+	TypeOrphanReclaimed     = "orphan_reclaimed"
+	TypeSourceCommand       = "source_command"
+	TypeFileRead            = "file_read"
+	TypeSessionStart        = "session_start"
+	TypeSessionEnd          = "session_end"
 
 	genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
 )
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -14,7 +17,8 @@ type Config struct {
 	// HostID is a persistent identifier for this host. Generated on first run.
 	HostID string `yaml:"host_id"`
 
-	// Provider selects the sandbox provider: "microvm" (default) or "lxc".
+	// Provider selects the sandbox provider: "microvm" (default), "lxc", or
+	// "docker" (Docker or Podman containers; "podman" is an alias).
 	Provider string `yaml:"provider"`
 
 	// Daemon configures the inbound gRPC server for CLI access.
@@ -41,6 +45,9 @@ type Config struct {
 	// LXC configures Proxmox LXC container management (only used when provider: lxc).
 	LXC LXCConfig `yaml:"lxc"`
 
+	// Docker configures Docker/Podman containers (only used when provider: docker).
+	Docker DockerConfig `yaml:"docker"`
+
 	// State configures local state storage.
 	State StateConfig `yaml:"state"`
 
@@ -53,10 +60,36 @@ type Config struct {
 	// Audit configures the audit trail log.
 	Audit AuditConfig `yaml:"audit"`
 
+	// Hooks configures policy hooks consulted before sandbox operations.
+	Hooks HooksConfig `yaml:"hooks"`
+
+	// Snapshot configures snapshot filesystem manifests and diffs.
+	Snapshot SnapshotConfig `yaml:"snapshot"`
+
+	// Archive configures the disk archives taken before a sandbox is destroyed.
+	Archive ArchiveConfig `yaml:"archive"`
+
+	// OutputRedaction configures scrubbing of secrets from command output.
+	OutputRedaction OutputRedactionConfig `yaml:"output_redaction"`
+
+	// Readonly adjusts the allowlist source VM commands are checked against.
+	Readonly ReadonlyConfig `yaml:"readonly"`
+
+	// Metrics configures the Prometheus metrics endpoint.
+	Metrics MetricsConfig `yaml:"metrics"`
+
 	// SourceHosts configures remote hypervisor hosts where source VMs live.
 	// The daemon auto-discovers VMs on these hosts so the CLI only needs
 	// to send a VM name (no SourceHostConnection required).
 	SourceHosts []SourceHostConfig `yaml:"source_hosts"`
+
+	// SourceHostWorkers bounds how many source hosts are listed at once
+	// (default 8).
+	SourceHostWorkers int `yaml:"source_host_workers"`
+
+	// SourceHostTimeout bounds each source host's VM listing so one slow
+	// host cannot hold up the rest (default 30s).
+	SourceHostTimeout time.Duration `yaml:"source_host_timeout"`
 }
 
 // SourceHostConfig describes a remote hypervisor host the daemon can reach via SSH.
@@ -65,6 +98,26 @@ type SourceHostConfig struct {
 	SSHUser string `yaml:"ssh_user"` // default: deer-daemon
 	SSHPort int    `yaml:"ssh_port"` // default: 22
 	Type    string `yaml:"type"`     // "libvirt" (default) or "proxmox"
+
+	// SourceVMUser is the admin user preparation logs in to this host's VMs
+	// as, e.g. ubuntu for cloud images without a root login. Default:
+	// ssh.source_vm_user.
+	SourceVMUser string `yaml:"source_vm_user"`
+}
+
+// SourceVMUserFor returns the user to prepare VMs on the source host at
+// address as: that host's source_vm_user, else ssh.source_vm_user, else
+// root. An address matching no source host gets the global default.
+func (c *Config) SourceVMUserFor(address string) string {
+	for _, h := range c.SourceHosts {
+		if address != "" && h.Address == address && h.SourceVMUser != "" {
+			return h.SourceVMUser
+		}
+	}
+	if c.SSH.SourceVMUser != "" {
+		return c.SSH.SourceVMUser
+	}
+	return "root"
 }
 
 // TelemetryConfig controls anonymous telemetry.
@@ -79,6 +132,74 @@ type AuditConfig struct {
 	MaxSizeMB int    `yaml:"max_size_mb"`
 }
 
+// SnapshotConfig configures the filesystem manifests recorded with snapshots.
+type SnapshotConfig struct {
+	// DiffIgnore lists paths left out of manifests and diffs. When empty,
+	// volatile paths such as /proc, /sys, /tmp, and /var/log are skipped.
+	DiffIgnore []string `yaml:"diff_ignore"`
+}
+
+// ReadonlyConfig adjusts the built-in allowlist for commands run on source
+// VMs. Deny always wins over Allow and over the built-in list.
+type ReadonlyConfig struct {
+	Allow []string `yaml:"allow"` // extra commands treated as read-only, e.g. tcpdump
+	Deny  []string `yaml:"deny"`  // commands to refuse even if built in, e.g. find
+}
+
+// ArchiveConfig configures disk archives of destroyed sandboxes.
+type ArchiveConfig struct {
+	// Dir is where archives are written. When empty, the base image
+	// directory is used so an archive can seed new sandboxes by name.
+	Dir string `yaml:"dir"`
+
+	// OnDestroy archives every sandbox before it is destroyed, as if each
+	// destroy request asked for it, including janitor expiries. Providers
+	// that cannot archive destroy without one.
+	OnDestroy bool `yaml:"on_destroy"`
+}
+
+// OutputRedactionConfig configures the secrets replaced with [REDACTED] in
+// command stdout and stderr before they are stored, audited, or returned.
+// AWS keys, bearer tokens, API keys, private key blocks, connection string
+// passwords, and common secret environment variables are always covered.
+type OutputRedactionConfig struct {
+	// Enabled turns output redaction on (default true).
+	Enabled bool `yaml:"enabled"`
+
+	// Patterns are extra regexes to scrub, keyed by a name recorded in the
+	// audit log when the pattern matches. With a capture group, only the
+	// first group is replaced.
+	Patterns map[string]string `yaml:"patterns"`
+
+	// EnvVars are extra environment variable names whose values are
+	// scrubbed when printed as NAME=value.
+	EnvVars []string `yaml:"env_vars"`
+}
+
+// HooksConfig configures operator policy hooks.
+type HooksConfig struct {
+	// PreCreate is invoked with the proposed parameters before a sandbox is
+	// cloned and may allow, deny, or adjust the request.
+	PreCreate PreCreateHookConfig `yaml:"pre_create"`
+}
+
+// PreCreateHookConfig selects an HTTP endpoint or a local command as the
+// pre-create hook. URL takes precedence when both are set.
+type PreCreateHookConfig struct {
+	// URL receives a JSON POST of the create parameters.
+	URL string `yaml:"url"`
+
+	// Command is run via sh -c with the create parameters as JSON on stdin.
+	Command string `yaml:"command"`
+
+	// Timeout bounds each hook invocation (default 10s).
+	Timeout time.Duration `yaml:"timeout"`
+
+	// FailOpen allows creates to proceed when the hook itself errors or is
+	// unreachable. By default such failures reject the create.
+	FailOpen bool `yaml:"fail_open"`
+}
+
 // DaemonConfig configures the inbound gRPC server for direct CLI access.
 type DaemonConfig struct {
 	// ListenAddr is the address the daemon gRPC server listens on.
@@ -92,6 +213,11 @@ type DaemonConfig struct {
 
 	// TLSKeyFile is the path to the TLS key for the daemon gRPC server.
 	TLSKeyFile string `yaml:"tls_key_file"`
+
+	// DrainTimeout is how long shutdown waits for in-flight requests, such
+	// as sandbox creation and streaming commands, after it stops accepting
+	// new sandboxes. Zero waits until they finish.
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
 }
 
 // LXCConfig configures LXC provider settings for Proxmox.
@@ -106,6 +232,24 @@ type LXCConfig struct {
 	VMIDEnd   int           `yaml:"vmid_end"`
 	VerifySSL bool          `yaml:"verify_ssl"`
 	Timeout   time.Duration `yaml:"timeout"`
+
+	// CloneTimeout bounds how long a CT clone may take before it is stopped
+	// and cleaned up. Zero uses the provider default (10m).
+	CloneTimeout time.Duration `yaml:"clone_timeout"`
+}
+
+// DockerConfig configures the Docker/Podman container provider.
+type DockerConfig struct {
+	// Host is the Engine API endpoint. Empty uses the Docker socket; for
+	// Podman use its compatibility socket, e.g. unix:///run/podman/podman.sock.
+	Host string `yaml:"host"`
+
+	// Network is the network sandboxes are attached to. Empty uses the
+	// engine's default.
+	Network string `yaml:"network"`
+
+	Timeout     time.Duration `yaml:"timeout"`
+	StopTimeout time.Duration `yaml:"stop_timeout"`
 }
 
 // ControlPlaneConfig configures the gRPC connection to the control plane.
@@ -156,7 +300,9 @@ type MicroVMConfig struct {
 	// CommandTimeout is the default command execution timeout.
 	CommandTimeout time.Duration `yaml:"command_timeout"`
 
-	// IPDiscoveryTimeout is how long to wait for IP discovery.
+	// IPDiscoveryTimeout is how long to wait for IP discovery. It also bounds
+	// the wait for a source VM to report an IP and accept SSH before it is
+	// prepared.
 	IPDiscoveryTimeout time.Duration `yaml:"ip_discovery_timeout"`
 
 	// ReadinessTimeout is how long to wait for cloud-init phone_home readiness.
@@ -172,6 +318,18 @@ type MicroVMConfig struct {
 	// and any required services configured. Significantly speeds up boot.
 	DisableCloudInit bool `yaml:"disable_cloudinit"`
 
+	// SSHKeyInjectMethod is how the SSH CA key and sandbox user get into a
+	// guest: "cloud-init", "virt-customize" (written into the disk before
+	// boot), "none" (pre-baked or Windows images), or "auto" to inspect each
+	// base image with libguestfs. Defaults to auto, or none when
+	// disable_cloudinit is set. Auto falls back to cloud-init when the image
+	// cannot be inspected.
+	SSHKeyInjectMethod string `yaml:"ssh_key_inject_method"`
+
+	// ImageInjectMethods overrides SSHKeyInjectMethod for individual base
+	// images, keyed by image name.
+	ImageInjectMethods map[string]string `yaml:"image_inject_methods"`
+
 	// SocketVMNetClient is the path to the socket_vmnet_client binary (macOS only).
 	// When set, networking uses socket_vmnet instead of TAP/bridge devices.
 	// e.g. /opt/homebrew/opt/socket_vmnet/bin/socket_vmnet_client
@@ -192,12 +350,33 @@ type NetworkConfig struct {
 
 	// DHCPMode determines IP discovery strategy: "libvirt", "arp", or "dnsmasq".
 	DHCPMode string `yaml:"dhcp_mode"`
+
+	// IPPollInterval is how often IP discovery re-checks leases and the
+	// neighbour table while waiting for a sandbox's address (default 2s).
+	IPPollInterval time.Duration `yaml:"ip_poll_interval"`
+
+	// IPUniqueness sets where two sandboxes may not share an IP: "network"
+	// (default) only flags sandboxes on the same bridge, since separate NAT
+	// networks reuse the same private ranges; "global" flags any two.
+	IPUniqueness string `yaml:"ip_uniqueness"`
+
+	// IsolationMode controls whether sandboxes on the same bridge can reach
+	// each other: "none" (default) or "bridge-private", which uses ebtables
+	// and iptables so each sandbox reaches the gateway but no other sandbox.
+	IsolationMode string `yaml:"isolation_mode"`
 }
 
 // ImageConfig configures base image storage and management.
 type ImageConfig struct {
 	// BaseDir is the directory containing base QCOW2 images.
 	BaseDir string `yaml:"base_dir"`
+
+	// MaxDownloadBPS caps each snapshot pull, in bytes per second. Zero means unlimited.
+	MaxDownloadBPS int64 `yaml:"max_download_bps"`
+
+	// MaxTotalDownloadBPS caps all concurrent snapshot pulls combined, in bytes
+	// per second. Zero means unlimited.
+	MaxTotalDownloadBPS int64 `yaml:"max_total_download_bps"`
 }
 
 // SSHConfig configures SSH CA and key management.
@@ -217,20 +396,93 @@ type SSHConfig struct {
 	// DefaultUser is the default SSH user for sandbox access.
 	DefaultUser string `yaml:"default_user"`
 
+	// SourceVMUser is the admin user preparation logs in to source VMs as
+	// when neither the request nor the VM's source host names one. When it
+	// is not root, preparation runs its steps through sudo. Default: root.
+	SourceVMUser string `yaml:"source_vm_user"`
+
 	// ProxyJump is an optional SSH proxy jump host.
 	ProxyJump string `yaml:"proxy_jump"`
 
 	// IdentityFile is the SSH private key for outbound host connections.
 	IdentityFile string `yaml:"identity_file"`
+
+	// HostKeyMode is how sandbox host keys are checked: "insecure" (the
+	// default) accepts any key; "tofu" records each sandbox's key on first
+	// connect and rejects a changed one; "strict" accepts only keys in
+	// KnownHostsFile.
+	HostKeyMode string `yaml:"host_key_mode"`
+
+	// KnownHostsFile is the pre-populated known_hosts file used in strict
+	// host key mode.
+	KnownHostsFile string `yaml:"known_hosts_file"`
+
+	// AllowAgentForwarding lets clients request ForwardAgent=yes when running
+	// sandbox commands. The daemon's own SSH_AUTH_SOCK is forwarded, so only
+	// enable this where the daemon runs as the operator. Off by default.
+	AllowAgentForwarding bool `yaml:"allow_agent_forwarding"`
+
+	// AllowedIdentityFiles lists extra private keys on this host that clients
+	// may ask to offer, in addition to the sandbox certificate key.
+	AllowedIdentityFiles []string `yaml:"allowed_identity_files"`
 }
 
 // LibvirtConfig configures libvirt access for source VM operations.
 type LibvirtConfig struct {
-	// URI is the libvirt connection URI (e.g., "qemu:///system").
+	// URI is the libvirt connection URI. Supported forms:
+	//   qemu:///system                            local daemon, default socket
+	//   qemu+unix:///system?socket=/path/to/sock  local daemon, explicit socket
+	//   qemu+ssh://user@host/system               remote over SSH
+	//   qemu+tcp://host/system                    remote over TCP (usually SASL)
+	//   qemu+tls://host/system                    remote over TLS
 	URI string `yaml:"uri"`
 
 	// Network is the default libvirt network name.
 	Network string `yaml:"network"`
+
+	// SASLUser and SASLPassword are credentials for libvirt SASL auth, used
+	// with qemu+tcp and qemu+tls URIs. They are written to a libvirt auth
+	// file passed to virsh via LIBVIRT_AUTH_FILE.
+	SASLUser     string `yaml:"sasl_user"`
+	SASLPassword string `yaml:"sasl_password"`
+}
+
+// libvirtSchemes are the URI schemes accepted for libvirt.uri.
+var libvirtSchemes = []string{"qemu", "qemu+unix", "qemu+ssh", "qemu+libssh2", "qemu+tcp", "qemu+tls"}
+
+// Validate checks that the URI uses a supported scheme and that SASL
+// credentials are complete.
+func (c LibvirtConfig) Validate() error {
+	u, err := url.Parse(c.URI)
+	if err != nil {
+		return fmt.Errorf("libvirt.uri %q: %w", c.URI, err)
+	}
+	if !slices.Contains(libvirtSchemes, u.Scheme) {
+		return fmt.Errorf("libvirt.uri %q: unsupported scheme %q (supported: %s)", c.URI, u.Scheme, strings.Join(libvirtSchemes, ", "))
+	}
+	if u.Scheme == "qemu+unix" && u.Host != "" {
+		return fmt.Errorf("libvirt.uri %q: qemu+unix URIs cannot name a host", c.URI)
+	}
+	if c.SASLPassword != "" && c.SASLUser == "" {
+		return fmt.Errorf("libvirt.sasl_password is set without libvirt.sasl_user")
+	}
+	return nil
+}
+
+// Scopes for network.ip_uniqueness.
+const (
+	IPUniquenessNetwork = "network"
+	IPUniquenessGlobal  = "global"
+)
+
+// Validate checks that ip_uniqueness names a known scope. Empty is the
+// default network scope.
+func (c NetworkConfig) Validate() error {
+	switch c.IPUniqueness {
+	case "", IPUniquenessNetwork, IPUniquenessGlobal:
+		return nil
+	}
+	return fmt.Errorf("unknown network.ip_uniqueness %q: want %q or %q", c.IPUniqueness, IPUniquenessNetwork, IPUniquenessGlobal)
 }
 
 // StateConfig configures local state storage.
@@ -246,6 +498,18 @@ type JanitorConfig struct {
 
 	// DefaultTTL is the default sandbox TTL if none is specified.
 	DefaultTTL time.Duration `yaml:"default_ttl"`
+
+	// IdleTimeout stops (not destroys) a running sandbox that has not run a
+	// command or changed state for this long, unless a command is running in
+	// it now. Zero disables idle stops.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+}
+
+// MetricsConfig configures the Prometheus metrics endpoint.
+type MetricsConfig struct {
+	// ListenAddr is where /metrics is served, e.g. "localhost:9100". Empty
+	// (the default) disables the endpoint.
+	ListenAddr string `yaml:"listen_addr"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
@@ -255,8 +519,9 @@ func DefaultConfig() Config {
 
 	return Config{
 		Daemon: DaemonConfig{
-			ListenAddr: ":9091",
-			Enabled:    true,
+			ListenAddr:   ":9091",
+			Enabled:      true,
+			DrainTimeout: 60 * time.Second,
 		},
 		ControlPlane: ControlPlaneConfig{
 			Address:  "",
@@ -279,7 +544,9 @@ func DefaultConfig() Config {
 			BridgeMap: map[string]string{
 				"default": "virbr0",
 			},
-			DHCPMode: "arp",
+			DHCPMode:       "arp",
+			IPPollInterval: 2 * time.Second,
+			IPUniqueness:   IPUniquenessNetwork,
 		},
 		Image: ImageConfig{
 			BaseDir: "/var/lib/deer-daemon/images",
@@ -290,6 +557,8 @@ func DefaultConfig() Config {
 			KeyDir:       "/var/lib/deer-daemon/keys",
 			CertTTL:      30 * time.Minute,
 			DefaultUser:  "sandbox",
+			SourceVMUser: "root",
+			HostKeyMode:  "insecure",
 			IdentityFile: filepath.Join(deerDir, "identity"),
 		},
 		Libvirt: LibvirtConfig{
@@ -308,6 +577,9 @@ func DefaultConfig() Config {
 			LogPath:   filepath.Join(deerDir, "daemon-audit.jsonl"),
 			MaxSizeMB: 50,
 		},
+		OutputRedaction: OutputRedactionConfig{
+			Enabled: true,
+		},
 	}
 }
 
@@ -326,6 +598,9 @@ func Load(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
+	if err := cfg.Network.Validate(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
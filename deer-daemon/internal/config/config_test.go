@@ -45,6 +45,9 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Network.DHCPMode != "arp" {
 		t.Errorf("Network.DHCPMode = %q, want %q", cfg.Network.DHCPMode, "arp")
 	}
+	if cfg.Network.IPPollInterval != 2*time.Second {
+		t.Errorf("Network.IPPollInterval = %v, want %v", cfg.Network.IPPollInterval, 2*time.Second)
+	}
 	if v, ok := cfg.Network.BridgeMap["default"]; !ok || v != "virbr0" {
 		t.Errorf("Network.BridgeMap[\"default\"] = %q (ok=%v), want %q", v, ok, "virbr0")
 	}
@@ -72,6 +75,12 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.SSH.DefaultUser != "sandbox" {
 		t.Errorf("SSH.DefaultUser = %q, want %q", cfg.SSH.DefaultUser, "sandbox")
 	}
+	if cfg.SSH.SourceVMUser != "root" {
+		t.Errorf("SSH.SourceVMUser = %q, want %q", cfg.SSH.SourceVMUser, "root")
+	}
+	if cfg.SSH.HostKeyMode != "insecure" {
+		t.Errorf("SSH.HostKeyMode = %q, want %q", cfg.SSH.HostKeyMode, "insecure")
+	}
 
 	// Libvirt defaults
 	if cfg.Libvirt.URI != "qemu:///system" {
@@ -191,6 +200,26 @@ func TestLoad_ValidYAML(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "ssh key inject methods",
+			yaml: `microvm:
+  ssh_key_inject_method: cloud-init
+  image_inject_methods:
+    custom-debian: virt-customize
+    win2022: none
+`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.MicroVM.SSHKeyInjectMethod != "cloud-init" {
+					t.Errorf("SSHKeyInjectMethod = %q, want cloud-init", cfg.MicroVM.SSHKeyInjectMethod)
+				}
+				if got := cfg.MicroVM.ImageInjectMethods["custom-debian"]; got != "virt-customize" {
+					t.Errorf("ImageInjectMethods[custom-debian] = %q, want virt-customize", got)
+				}
+				if got := cfg.MicroVM.ImageInjectMethods["win2022"]; got != "none" {
+					t.Errorf("ImageInjectMethods[win2022] = %q, want none", got)
+				}
+			},
+		},
 		{
 			name: "partial override preserves defaults",
 			yaml: `microvm:
@@ -247,6 +276,10 @@ func TestLoad_InvalidYAML(t *testing.T) {
 			name:    "wrong type for integer field",
 			content: "microvm:\n  default_vcpus: not_a_number",
 		},
+		{
+			name:    "unknown ip_uniqueness",
+			content: "network:\n  ip_uniqueness: bridge",
+		},
 	}
 
 	for _, tt := range tests {
@@ -342,3 +375,30 @@ func TestSave_RoundTrip(t *testing.T) {
 		t.Errorf("SSH.CertTTL = %v, want %v", loaded.SSH.CertTTL, original.SSH.CertTTL)
 	}
 }
+
+func TestLibvirtConfig_Validate(t *testing.T) {
+	valid := []string{
+		"qemu:///system",
+		"qemu+unix:///system?socket=/run/libvirt/libvirt-sock",
+		"qemu+ssh://root@host/system",
+		"qemu+tcp://host:16509/system",
+		"qemu+tls://host/system",
+	}
+	for _, uri := range valid {
+		if err := (LibvirtConfig{URI: uri}).Validate(); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", uri, err)
+		}
+	}
+
+	invalid := []LibvirtConfig{
+		{URI: "xen:///system"},
+		{URI: "http://host/system"},
+		{URI: "qemu+unix://host/system"},
+		{URI: "qemu+tcp://host/system", SASLPassword: "secret"},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", c)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package daemon
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/id"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+)
+
+// archiveTimeout bounds exporting a sandbox disk, which copies the whole
+// flattened image.
+const archiveTimeout = 30 * time.Minute
+
+// archiveDir returns where archives are written: archive.dir, or the base
+// image directory so archives can be used as base images.
+func (s *Server) archiveDir() string {
+	if s.cfg == nil {
+		return ""
+	}
+	if s.cfg.Archive.Dir != "" {
+		return s.cfg.Archive.Dir
+	}
+	return s.cfg.Image.BaseDir
+}
+
+// archiveSandbox stops a sandbox and exports its disk, recording the archive
+// as a snapshot of kind "archive" so it is listed with the sandbox's other
+// snapshots. It returns the archive path and, when the archive sits in the
+// base image directory, the image name a new sandbox can be created from.
+func (s *Server) archiveSandbox(ctx context.Context, sandboxID string) (path, imageName string, err error) {
+	archiver, ok := s.prov.(provider.Archiver)
+	if !ok {
+		return "", "", status.Error(codes.Unimplemented, "this provider cannot archive sandboxes")
+	}
+	dir := s.archiveDir()
+	if dir == "" {
+		return "", "", status.Error(codes.FailedPrecondition, "no archive directory configured (set archive.dir)")
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, archiveTimeout)
+	defer cancel()
+
+	// The sandbox is about to be destroyed, so stop it for a consistent
+	// image. Export still proceeds if the stop fails.
+	if err := s.prov.StopSandbox(ctx, sandboxID, false); err != nil {
+		s.logger.Warn("stop before archive failed", "sandbox_id", sandboxID, "error", err)
+	}
+
+	result, err := archiver.ArchiveSandbox(ctx, sandboxID, dir)
+	if err != nil {
+		s.logAudit(audit.TypeSandboxArchived, map[string]any{"sandbox_id": sandboxID}, err, time.Since(start).Milliseconds())
+		return "", "", status.Errorf(codes.Internal, "archive sandbox: %v", err)
+	}
+	s.logAudit(audit.TypeSandboxArchived, map[string]any{
+		"sandbox_id": sandboxID,
+		"path":       result.Path,
+		"size_bytes": result.SizeBytes,
+	}, nil, time.Since(start).Milliseconds())
+
+	if s.cfg != nil && filepath.Clean(filepath.Dir(result.Path)) == filepath.Clean(s.cfg.Image.BaseDir) {
+		imageName = strings.TrimSuffix(filepath.Base(result.Path), ".qcow2")
+	}
+
+	snapID, err := id.Generate("SNP-")
+	if err == nil {
+		err = s.store.CreateSnapshot(ctx, &state.Snapshot{
+			ID:        snapID,
+			SandboxID: sandboxID,
+			Name:      "archive-" + start.UTC().Format("20060102-150405"),
+			Kind:      state.SnapshotKindArchive,
+			Ref:       result.Path,
+			CreatedAt: start.UTC(),
+		})
+	}
+	if err != nil {
+		s.logger.Warn("record archive failed", "sandbox_id", sandboxID, "path", result.Path, "error", err)
+	}
+	s.logger.Info("sandbox archived", "sandbox_id", sandboxID, "path", result.Path, "size_bytes", result.SizeBytes)
+	return result.Path, imageName, nil
+}
+
+// wantsArchive reports whether a destroy should archive first, either by
+// request or because archive.on_destroy is set. archive.on_destroy is only a
+// default, so it is skipped on providers that cannot archive; an explicit
+// request still fails there.
+func (s *Server) wantsArchive(requested bool) bool {
+	if requested {
+		return true
+	}
+	if s.cfg == nil || !s.cfg.Archive.OnDestroy {
+		return false
+	}
+	_, ok := s.prov.(provider.Archiver)
+	return ok
+}
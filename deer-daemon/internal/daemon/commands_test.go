@@ -0,0 +1,604 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/sshkeys"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+func TestListSandboxCommands(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if err := s.store.CreateCommand(ctx, &state.Command{
+			ID:        fmt.Sprintf("CMD-%d", i),
+			SandboxID: "SBX-1",
+			Command:   fmt.Sprintf("echo %d", i),
+			ExitCode:  i,
+			StartedAt: base.Add(time.Duration(i) * time.Minute),
+			EndedAt:   base.Add(time.Duration(i)*time.Minute + time.Second),
+		}); err != nil {
+			t.Fatalf("CreateCommand: %v", err)
+		}
+	}
+
+	resp, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if got := len(resp.GetCommands()); got != 3 {
+		t.Fatalf("got %d commands, want 3", got)
+	}
+	if resp.GetCommands()[0].GetId() != "CMD-0" || resp.GetCommands()[2].GetId() != "CMD-2" {
+		t.Errorf("commands not oldest first: %v", resp.GetCommands())
+	}
+
+	resp, err = s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1", Limit: 2})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands with limit: %v", err)
+	}
+	if len(resp.GetCommands()) != 2 || resp.GetCommands()[0].GetId() != "CMD-1" || resp.GetCommands()[1].GetExitCode() != 2 {
+		t.Errorf("limit should keep the most recent commands, got %v", resp.GetCommands())
+	}
+
+	_, err = s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing id code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestRunCommand_RecordsActor(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &fakeCommandProvider{}
+
+	for _, run := range []struct{ command, actor string }{
+		{"whoami", "human-cli"},
+		{"ls", "tui-agent"},
+		{"make", "tui-agent"},
+	} {
+		if _, err := s.RunCommand(ctx, &deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: run.command, Actor: run.actor}); err != nil {
+			t.Fatalf("RunCommand(%s): %v", run.command, err)
+		}
+	}
+
+	resp, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1", Actor: "tui-agent", Limit: 1})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if len(resp.GetCommands()) != 1 || resp.GetCommands()[0].GetCommand() != "make" || resp.GetCommands()[0].GetActor() != "tui-agent" {
+		t.Errorf("filtered commands = %v, want only the latest tui-agent command", resp.GetCommands())
+	}
+
+	resp, err = s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if len(resp.GetCommands()) != 3 || resp.GetCommands()[0].GetActor() != "human-cli" {
+		t.Errorf("unfiltered commands = %v", resp.GetCommands())
+	}
+}
+
+func TestRunCommand_UnrecordedKeepsOutOfHistory(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &fakeCommandProvider{}
+
+	res, err := s.RunCommand(ctx, &deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "cat /proc/1/environ", Unrecorded: true})
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if res.GetStdout() != "cat /proc/1/environ\n" {
+		t.Errorf("stdout = %q, want the command's output", res.GetStdout())
+	}
+
+	resp, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if len(resp.GetCommands()) != 0 {
+		t.Errorf("unrecorded command was saved in history: %v", resp.GetCommands())
+	}
+}
+
+// fakeCommandProvider answers RunCommand with the exit code in codes[command].
+type fakeCommandProvider struct {
+	fakeCreateSandboxProvider
+	codes map[string]int
+	ran   []string
+}
+
+func (f *fakeCommandProvider) RunCommand(_ context.Context, _ string, command string, _ time.Duration) (*provider.CommandResult, error) {
+	f.ran = append(f.ran, command)
+	return &provider.CommandResult{Stdout: command + "\n", ExitCode: f.codes[command]}, nil
+}
+
+// blockingCommandProvider holds RunCommand until release is closed.
+type blockingCommandProvider struct {
+	fakeCreateSandboxProvider
+	started, release chan struct{}
+}
+
+func (f *blockingCommandProvider) RunCommand(context.Context, string, string, time.Duration) (*provider.CommandResult, error) {
+	close(f.started)
+	<-f.release
+	return &provider.CommandResult{}, nil
+}
+
+func TestRunCommand_TracksCommandsInFlight(t *testing.T) {
+	s := newLockTestServer(t)
+	prov := &blockingCommandProvider{started: make(chan struct{}), release: make(chan struct{})}
+	s.prov = prov
+
+	done := make(chan error)
+	go func() {
+		_, err := s.RunCommand(context.Background(), &deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "make test"})
+		done <- err
+	}()
+	<-prov.started
+	if !s.CommandRunning("SBX-1") {
+		t.Error("CommandRunning = false while a command is in flight")
+	}
+	close(prov.release)
+	if err := <-done; err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if s.CommandRunning("SBX-1") {
+		t.Error("CommandRunning = true after the command finished")
+	}
+}
+
+func TestRunCommandBatch(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeCommandProvider{codes: map[string]int{"false": 1}}
+	s.prov = prov
+
+	resp, err := s.RunCommandBatch(ctx, &deerv1.RunCommandBatchCommand{
+		SandboxId:   "SBX-1",
+		Commands:    []string{"true", "false", "echo never"},
+		StopOnError: true,
+	})
+	if err != nil {
+		t.Fatalf("RunCommandBatch: %v", err)
+	}
+	if len(resp.GetResults()) != 2 || resp.GetResults()[1].GetExitCode() != 1 {
+		t.Fatalf("results = %v, want two with the second failing", resp.GetResults())
+	}
+	if len(prov.ran) != 2 {
+		t.Errorf("ran %v, want to stop after the failing command", prov.ran)
+	}
+
+	history, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if len(history.GetCommands()) != 2 {
+		t.Errorf("recorded %d commands, want 2", len(history.GetCommands()))
+	}
+
+	prov.ran = nil
+	resp, err = s.RunCommandBatch(ctx, &deerv1.RunCommandBatchCommand{
+		SandboxId: "SBX-1",
+		Commands:  []string{"false", "true"},
+	})
+	if err != nil {
+		t.Fatalf("RunCommandBatch without stop_on_error: %v", err)
+	}
+	if len(resp.GetResults()) != 2 {
+		t.Errorf("got %d results, want all commands to run", len(resp.GetResults()))
+	}
+
+	_, err = s.RunCommandBatch(ctx, &deerv1.RunCommandBatchCommand{SandboxId: "SBX-1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty batch code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+// failingCommandProvider fails to run the command named fail.
+type failingCommandProvider struct {
+	fakeCommandProvider
+	fail string
+}
+
+func (f *failingCommandProvider) RunCommand(ctx context.Context, id, command string, timeout time.Duration) (*provider.CommandResult, error) {
+	if command == f.fail {
+		return nil, errors.New("ssh: connection reset")
+	}
+	return f.fakeCommandProvider.RunCommand(ctx, id, command, timeout)
+}
+
+func TestRunCommandBatch_KeepsResultsBeforeFailure(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &failingCommandProvider{fail: "make"}
+
+	resp, err := s.RunCommandBatch(ctx, &deerv1.RunCommandBatchCommand{
+		SandboxId: "SBX-1",
+		Commands:  []string{"configure", "make", "make install"},
+	})
+	if err != nil {
+		t.Fatalf("RunCommandBatch: %v", err)
+	}
+	if len(resp.GetResults()) != 1 || resp.GetResults()[0].GetStdout() != "configure\n" {
+		t.Errorf("results = %v, want the command before the failure", resp.GetResults())
+	}
+	if !strings.Contains(resp.GetError(), "after 1 of 3 commands") {
+		t.Errorf("error = %q", resp.GetError())
+	}
+	history, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil || len(history.GetCommands()) != 1 {
+		t.Errorf("history = %v, %v; want the command that ran recorded", history.GetCommands(), err)
+	}
+
+	// A batch that fails before any command runs is an error outright.
+	s.prov = &failingCommandProvider{fail: "configure"}
+	if _, err := s.RunCommandBatch(ctx, &deerv1.RunCommandBatchCommand{SandboxId: "SBX-1", Commands: []string{"configure"}}); status.Code(err) != codes.Internal {
+		t.Errorf("err = %v, want Internal", err)
+	}
+}
+
+func TestRunCommand_AppliesEnv(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeCommandProvider{}
+	s.prov = prov
+
+	_, err := s.RunCommand(ctx, &deerv1.RunCommandCommand{
+		SandboxId: "SBX-1",
+		Command:   "env",
+		Env:       map[string]string{"B": "it's", "A": "1"},
+	})
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	want := `export A='1' B='it'\''s'; env`
+	if len(prov.ran) != 1 || prov.ran[0] != want {
+		t.Errorf("ran %q, want %q", prov.ran, want)
+	}
+
+	history, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if got := history.GetCommands()[0].GetCommand(); got != "env" {
+		t.Errorf("recorded command = %q, want env without the exports", got)
+	}
+
+	_, err = s.RunCommand(ctx, &deerv1.RunCommandCommand{
+		SandboxId: "SBX-1",
+		Command:   "env",
+		Env:       map[string]string{"BAD NAME": "x"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid name: err = %v, want InvalidArgument", err)
+	}
+}
+
+type fakeKeyProvider struct {
+	sshkeys.KeyProvider
+}
+
+func (fakeKeyProvider) GetCredentials(_ context.Context, sandboxID, username string) (*sshkeys.Credentials, error) {
+	return &sshkeys.Credentials{
+		Username:        username,
+		PrivateKeyPath:  "/keys/" + sandboxID,
+		CertificatePath: "/keys/" + sandboxID + "-cert.pub",
+	}, nil
+}
+
+func (fakeKeyProvider) RenewCredentials(_ context.Context, sandboxID, username string) (*sshkeys.Credentials, error) {
+	return &sshkeys.Credentials{
+		Username:        username,
+		PrivateKeyPath:  "/keys/" + sandboxID,
+		CertificatePath: "/keys/" + sandboxID + "-cert.pub",
+		ValidUntil:      time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC),
+	}, nil
+}
+
+func (fakeKeyProvider) GetSourceVMCredentials(_ context.Context, vmName string) (*sshkeys.Credentials, error) {
+	return &sshkeys.Credentials{
+		Username:        "deer-readonly",
+		PrivateKeyPath:  "/keys/" + vmName,
+		CertificatePath: "/keys/" + vmName + "-cert.pub",
+	}, nil
+}
+
+func TestRenewSandboxSSHCredentials(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+
+	_, err := s.RenewSandboxSSHCredentials(ctx, &deerv1.RenewSandboxSSHCredentialsRequest{SandboxId: "SBX-1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("without key manager: err = %v, want FailedPrecondition", err)
+	}
+
+	s.keyMgr = fakeKeyProvider{}
+	creds, err := s.RenewSandboxSSHCredentials(ctx, &deerv1.RenewSandboxSSHCredentialsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("RenewSandboxSSHCredentials: %v", err)
+	}
+	if creds.GetUsername() != "sandbox" || creds.GetCertificatePath() != "/keys/SBX-1-cert.pub" || creds.GetValidUntil() != "2026-03-01T12:30:00Z" {
+		t.Errorf("creds = %v", creds)
+	}
+
+	_, err = s.RenewSandboxSSHCredentials(ctx, &deerv1.RenewSandboxSSHCredentialsRequest{SandboxId: "SBX-missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("missing sandbox: err = %v, want NotFound", err)
+	}
+}
+
+func TestGetSandboxSSHTarget(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &fakeRecoverProvider{ips: map[string]string{"SBX-1": "10.0.0.9"}}
+
+	_, err := s.GetSandboxSSHTarget(ctx, &deerv1.GetSandboxSSHTargetRequest{SandboxId: "SBX-1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("without key manager: err = %v, want FailedPrecondition", err)
+	}
+
+	s.keyMgr = fakeKeyProvider{}
+	target, err := s.GetSandboxSSHTarget(ctx, &deerv1.GetSandboxSSHTargetRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandboxSSHTarget: %v", err)
+	}
+	if target.GetIpAddress() != "10.0.0.9" || target.GetUsername() != "sandbox" || target.GetPrivateKeyPath() != "/keys/SBX-1" {
+		t.Errorf("target = %v", target)
+	}
+	if got := strings.Join(target.GetHostKeyOptions(), " "); got != "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null" {
+		t.Errorf("host key options without config = %q, want insecure", got)
+	}
+
+	s.cfg = &config.Config{SSH: config.SSHConfig{HostKeyMode: sshkeys.HostKeyStrict, KnownHostsFile: "/etc/deer/known_hosts"}}
+	target, err = s.GetSandboxSSHTarget(ctx, &deerv1.GetSandboxSSHTargetRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandboxSSHTarget: %v", err)
+	}
+	if got := strings.Join(target.GetHostKeyOptions(), " "); got != "-o StrictHostKeyChecking=yes -o UserKnownHostsFile=/etc/deer/known_hosts" {
+		t.Errorf("strict host key options = %q", got)
+	}
+
+	_, err = s.GetSandboxSSHTarget(ctx, &deerv1.GetSandboxSSHTargetRequest{SandboxId: "SBX-missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("missing sandbox: err = %v, want NotFound", err)
+	}
+}
+
+func TestSandboxActivity(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+
+	end := time.Date(2026, 3, 1, 12, 5, 0, 0, time.UTC)
+	for i, code := range []int{0, 1, 0} {
+		if err := s.store.CreateCommand(ctx, &state.Command{
+			ID:         fmt.Sprintf("CMD-%d", i),
+			SandboxID:  "SBX-1",
+			ExitCode:   code,
+			DurationMS: 1000,
+			StartedAt:  end.Add(-time.Duration(3-i) * time.Minute),
+			EndedAt:    end.Add(-time.Duration(2-i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("CreateCommand: %v", err)
+		}
+	}
+
+	info, err := s.GetSandbox(ctx, &deerv1.GetSandboxRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	a := info.GetActivity()
+	if a.GetCommandCount() != 3 || a.GetFailedCommands() != 1 || a.GetTotalRuntimeMs() != 3000 {
+		t.Errorf("activity = %+v", a)
+	}
+	if a.GetLastActivityAt() != end.Format(time.RFC3339) {
+		t.Errorf("last_activity_at = %q, want %q", a.GetLastActivityAt(), end.Format(time.RFC3339))
+	}
+
+	list, err := s.ListSandboxes(ctx, &deerv1.ListSandboxesRequest{})
+	if err != nil {
+		t.Fatalf("ListSandboxes: %v", err)
+	}
+	if got := list.GetSandboxes()[0].GetActivity().GetCommandCount(); got != 3 {
+		t.Errorf("listed command_count = %d, want 3", got)
+	}
+}
+
+// fakeStreamingProvider emits each line of its output as a separate chunk.
+type fakeStreamingProvider struct {
+	fakeCommandProvider
+}
+
+func (f *fakeStreamingProvider) RunCommandStreaming(_ context.Context, _ string, command string, _ time.Duration, _ provider.CommandOptions, onOutput provider.OutputFunc) (*provider.CommandResult, error) {
+	onOutput([]byte("one\n"), false)
+	onOutput([]byte("oops\n"), true)
+	onOutput([]byte("two\n"), false)
+	return &provider.CommandResult{Stdout: "one\ntwo\n", Stderr: "oops\n", ExitCode: 3}, nil
+}
+
+type fakeCommandStream struct {
+	grpc.ServerStream
+	msgs []*deerv1.CommandOutput
+}
+
+func (f *fakeCommandStream) Send(msg *deerv1.CommandOutput) error {
+	f.msgs = append(f.msgs, msg)
+	return nil
+}
+
+func (f *fakeCommandStream) Context() context.Context { return context.Background() }
+
+func TestStreamCommand(t *testing.T) {
+	s := newLockTestServer(t)
+	s.prov = &fakeStreamingProvider{}
+
+	stream := &fakeCommandStream{}
+	if err := s.StreamCommand(&deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "run"}, stream); err != nil {
+		t.Fatalf("StreamCommand: %v", err)
+	}
+	if len(stream.msgs) != 4 {
+		t.Fatalf("got %d messages, want 3 chunks and a result", len(stream.msgs))
+	}
+	if string(stream.msgs[0].GetChunk()) != "one\n" || !stream.msgs[1].GetIsStderr() {
+		t.Errorf("chunks = %v", stream.msgs[:3])
+	}
+	if r := stream.msgs[3].GetResult(); r == nil || r.GetExitCode() != 3 || r.GetStdout() != "one\ntwo\n" {
+		t.Errorf("result = %v", r)
+	}
+
+	history, err := s.ListSandboxCommands(context.Background(), &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if len(history.GetCommands()) != 1 {
+		t.Errorf("recorded %d commands, want 1", len(history.GetCommands()))
+	}
+
+	// Providers that cannot stream deliver the output in one piece.
+	s.prov = &fakeCommandProvider{}
+	stream = &fakeCommandStream{}
+	if err := s.StreamCommand(&deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "echo hi"}, stream); err != nil {
+		t.Fatalf("StreamCommand fallback: %v", err)
+	}
+	if len(stream.msgs) != 2 || string(stream.msgs[0].GetChunk()) != "echo hi\n" || stream.msgs[1].GetResult() == nil {
+		t.Errorf("fallback messages = %v", stream.msgs)
+	}
+
+	err = s.StreamCommand(&deerv1.RunCommandCommand{SandboxId: "SBX-1"}, &fakeCommandStream{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing command code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestRunCommandError_Unhealthy(t *testing.T) {
+	err := runCommandError(fmt.Errorf("run command: %w: over 15 SSH retries", provider.ErrSandboxUnhealthy))
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("code = %v, want Unavailable for an unhealthy sandbox", status.Code(err))
+	}
+	if status.Code(runCommandError(fmt.Errorf("boom"))) != codes.Internal {
+		t.Error("other failures should stay Internal")
+	}
+}
+
+func TestRunCommandError_SSHTimeout(t *testing.T) {
+	err := runCommandError(fmt.Errorf("run command: ssh failed (exit 255): ssh: connect to host 10.0.0.5 port 22: Connection timed out"))
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("code = %v, want DeadlineExceeded for an SSH connect timeout", status.Code(err))
+	}
+}
+
+func TestRunCommand_RedactsOutput(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &fakeCommandProvider{}
+	s.outputScrubber = newOutputScrubber(config.OutputRedactionConfig{Enabled: true}, s.logger)
+
+	resp, err := s.RunCommand(ctx, &deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "echo GITHUB_TOKEN=ghp_abc123"})
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if got, want := resp.GetStdout(), "echo GITHUB_TOKEN=[REDACTED]\n"; got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	history, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if got := history.GetCommands()[0].GetStdout(); strings.Contains(got, "ghp_abc123") {
+		t.Errorf("stored stdout = %q, want the token scrubbed", got)
+	}
+}
+
+func TestGetSandbox_IdleStopAt(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+
+	info, err := s.GetSandbox(ctx, &deerv1.GetSandboxRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if info.GetIdleStopAt() != "" {
+		t.Errorf("idle_stop_at = %q, want unset with idle stops off", info.GetIdleStopAt())
+	}
+
+	s.cfg = &config.Config{Janitor: config.JanitorConfig{IdleTimeout: 30 * time.Minute}}
+	lastEnd := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	if err := s.store.CreateCommand(ctx, &state.Command{ID: "CMD-1", SandboxID: "SBX-1", StartedAt: lastEnd, EndedAt: lastEnd}); err != nil {
+		t.Fatalf("CreateCommand: %v", err)
+	}
+	info, err = s.GetSandbox(ctx, &deerv1.GetSandboxRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if want := lastEnd.Add(30 * time.Minute).Format(time.RFC3339); info.GetIdleStopAt() != want {
+		t.Errorf("idle_stop_at = %q, want %q", info.GetIdleStopAt(), want)
+	}
+}
+
+func TestListSandboxCommands_AfterID(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	base := time.Now().UTC().Add(-time.Hour)
+	for i, actor := range []string{"human-cli", "tui-agent", "human-cli", "human-cli"} {
+		// CMD-3 started first but ended last, so it sorts last.
+		started := base.Add(time.Duration(i) * time.Minute)
+		if i == 3 {
+			started = base.Add(-time.Minute)
+		}
+		cmd := &state.Command{
+			ID:        fmt.Sprintf("CMD-%d", i),
+			SandboxID: "SBX-1",
+			Command:   fmt.Sprintf("step %d", i),
+			Actor:     actor,
+			StartedAt: started,
+			EndedAt:   base.Add(time.Duration(i)*time.Minute + time.Second),
+		}
+		if err := s.store.CreateCommand(ctx, cmd); err != nil {
+			t.Fatalf("CreateCommand: %v", err)
+		}
+	}
+
+	ids := func(resp *deerv1.ListSandboxCommandsResponse) string {
+		var out []string
+		for _, c := range resp.GetCommands() {
+			out = append(out, c.GetId())
+		}
+		return strings.Join(out, ",")
+	}
+
+	resp, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1", AfterId: "CMD-0", Limit: 2})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if got := ids(resp); got != "CMD-1,CMD-2" {
+		t.Errorf("page = %s, want the two oldest after CMD-0", got)
+	}
+	resp, err = s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1", AfterId: "CMD-0", Actor: "human-cli"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands with actor: %v", err)
+	}
+	if got := ids(resp); got != "CMD-2,CMD-3" {
+		t.Errorf("human-cli page = %s, want CMD-2,CMD-3", got)
+	}
+
+	_, err = s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1", AfterId: "CMD-missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unknown after_id: err = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+func TestDestroySandbox_ProviderReportsMissing(t *testing.T) {
+	s := newLockTestServer(t)
+	s.prov = &fakeCreateSandboxProvider{
+		destroyFn: func(context.Context, string) error {
+			return fmt.Errorf("sandbox SBX-1 not tracked: %w", provider.ErrSandboxNotFound)
+		},
+	}
+
+	resp, err := s.DestroySandbox(context.Background(), &deerv1.DestroySandboxCommand{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("DestroySandbox: %v", err)
+	}
+	if resp.GetSandboxId() != "SBX-1" {
+		t.Errorf("sandbox_id = %q, want SBX-1", resp.GetSandboxId())
+	}
+	if _, err := s.store.GetSandbox(context.Background(), "SBX-1"); err == nil {
+		t.Error("expected sandbox row to be deleted from the store")
+	}
+
+	// A retry after the row is gone must also succeed.
+	if _, err := s.DestroySandbox(context.Background(), &deerv1.DestroySandboxCommand{SandboxId: "SBX-1"}); err != nil {
+		t.Fatalf("retry DestroySandbox: %v", err)
+	}
+}
+
+func TestDestroySandbox_ProviderFailureKeepsState(t *testing.T) {
+	s := newLockTestServer(t)
+	s.prov = &fakeCreateSandboxProvider{
+		destroyFn: func(context.Context, string) error {
+			return errors.New("qemu refused to die")
+		},
+	}
+
+	_, err := s.DestroySandbox(context.Background(), &deerv1.DestroySandboxCommand{SandboxId: "SBX-1"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected Internal error, got %v", err)
+	}
+	if _, err := s.store.GetSandbox(context.Background(), "SBX-1"); err != nil {
+		t.Errorf("expected sandbox row to be kept after a real failure, got %v", err)
+	}
+}
+
+// fakeArchiveProvider archives into destDir, or fails with err.
+type fakeArchiveProvider struct {
+	fakeCreateSandboxProvider
+	err error
+}
+
+func (f *fakeArchiveProvider) ArchiveSandbox(_ context.Context, sandboxID, destDir string) (*provider.ArchiveResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &provider.ArchiveResult{Path: filepath.Join(destDir, "archive-"+sandboxID+".qcow2"), SizeBytes: 1 << 20}, nil
+}
+
+func TestDestroySandbox_Archive(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	baseDir := t.TempDir()
+	s.cfg = &config.Config{Image: config.ImageConfig{BaseDir: baseDir}}
+	prov := &fakeArchiveProvider{}
+	s.prov = prov
+
+	resp, err := s.DestroySandbox(ctx, &deerv1.DestroySandboxCommand{SandboxId: "SBX-1", Archive: true})
+	if err != nil {
+		t.Fatalf("DestroySandbox: %v", err)
+	}
+	if resp.GetArchivePath() != filepath.Join(baseDir, "archive-SBX-1.qcow2") || resp.GetArchiveImage() != "archive-SBX-1" {
+		t.Errorf("archive = %q (image %q)", resp.GetArchivePath(), resp.GetArchiveImage())
+	}
+	snaps, err := s.store.ListSnapshots(ctx, "SBX-1")
+	if err != nil || len(snaps) != 1 || snaps[0].Kind != state.SnapshotKindArchive || snaps[0].Ref != resp.GetArchivePath() {
+		t.Errorf("snapshots = %+v, %v; want one archive record", snaps, err)
+	}
+	if len(prov.destroyed) != 1 {
+		t.Errorf("destroyed = %v, want the sandbox destroyed after archiving", prov.destroyed)
+	}
+}
+
+func TestDestroySandbox_ArchiveFailureKeepsSandbox(t *testing.T) {
+	s := newLockTestServer(t)
+	s.cfg = &config.Config{Archive: config.ArchiveConfig{Dir: t.TempDir(), OnDestroy: true}}
+	prov := &fakeArchiveProvider{err: errors.New("qemu-img: disk full")}
+	s.prov = prov
+
+	_, err := s.DestroySandbox(context.Background(), &deerv1.DestroySandboxCommand{SandboxId: "SBX-1"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("err = %v, want Internal from archive.on_destroy", err)
+	}
+	if len(prov.destroyed) != 0 {
+		t.Errorf("destroyed %v although the archive failed", prov.destroyed)
+	}
+	if _, err := s.store.GetSandbox(context.Background(), "SBX-1"); err != nil {
+		t.Errorf("sandbox row removed after a failed archive: %v", err)
+	}
+}
+
+func TestDestroySandbox_ArchiveOnDestroySkippedWithoutArchiver(t *testing.T) {
+	s := newLockTestServer(t)
+	s.cfg = &config.Config{Archive: config.ArchiveConfig{Dir: t.TempDir(), OnDestroy: true}}
+	prov := &fakeCreateSandboxProvider{}
+	s.prov = prov
+
+	resp, err := s.DestroySandbox(context.Background(), &deerv1.DestroySandboxCommand{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("DestroySandbox: %v", err)
+	}
+	if resp.GetArchivePath() != "" || len(prov.destroyed) != 1 {
+		t.Errorf("archive = %q, destroyed = %v; want a plain destroy", resp.GetArchivePath(), prov.destroyed)
+	}
+
+	// An explicit archive request still fails on such a provider.
+	s = newLockTestServer(t)
+	s.cfg = &config.Config{Archive: config.ArchiveConfig{Dir: t.TempDir()}}
+	if _, err := s.DestroySandbox(context.Background(), &deerv1.DestroySandboxCommand{SandboxId: "SBX-1", Archive: true}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("err = %v, want Unimplemented", err)
+	}
+}
+
+func TestExpireSandbox_ArchivesOnDestroy(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.cfg = &config.Config{Archive: config.ArchiveConfig{Dir: t.TempDir(), OnDestroy: true}}
+	prov := &fakeArchiveProvider{}
+	s.prov = prov
+
+	if err := s.ExpireSandbox(ctx, "SBX-1"); err != nil {
+		t.Fatalf("ExpireSandbox: %v", err)
+	}
+	snaps, err := s.store.ListSnapshots(ctx, "SBX-1")
+	if err != nil || len(snaps) != 1 || snaps[0].Kind != state.SnapshotKindArchive {
+		t.Errorf("snapshots = %+v, %v; want the expired sandbox archived", snaps, err)
+	}
+	if len(prov.destroyed) != 1 {
+		t.Errorf("destroyed = %v", prov.destroyed)
+	}
+	if _, err := s.store.GetSandbox(ctx, "SBX-1"); err == nil {
+		t.Error("expected the expired sandbox's row to be deleted")
+	}
+}
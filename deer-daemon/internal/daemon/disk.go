@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+)
+
+const (
+	// diskCommand reports root filesystem usage in 1K blocks. -P keeps each
+	// filesystem on one line and is understood by GNU and busybox df alike.
+	diskCommand = "df -Pk /"
+
+	diskProbeTimeout = 10 * time.Second
+
+	// diskProbeMaxAge is how long GetSandbox trusts a measurement before
+	// running df again, so polling a sandbox doesn't SSH in on every call.
+	diskProbeMaxAge = time.Minute
+)
+
+// diskMeasurement is a cached disk usage and when it was taken.
+type diskMeasurement struct {
+	usage *deerv1.SandboxDiskUsage
+	at    time.Time
+}
+
+// probeDisk measures a sandbox's root filesystem and caches the result for
+// list responses.
+func (s *Server) probeDisk(ctx context.Context, sandboxID string) (*deerv1.SandboxDiskUsage, error) {
+	result, err := provider.RunProbe(ctx, s.prov, sandboxID, diskCommand, diskProbeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("df exited %d: %s", result.ExitCode, strings.TrimSpace(result.Stderr))
+	}
+	usage, err := parseDF(result.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	usage.CheckedAt = now.Format(time.RFC3339)
+
+	s.diskMu.Lock()
+	if s.diskUsage == nil {
+		s.diskUsage = make(map[string]diskMeasurement)
+	}
+	s.diskUsage[sandboxID] = diskMeasurement{usage: usage, at: now}
+	s.diskMu.Unlock()
+	return usage, nil
+}
+
+// attachDisk sets the disk usage of each info. With probe set, running
+// sandboxes whose last measurement is older than diskProbeMaxAge are
+// measured now; otherwise the last measurement is used. A failed probe is
+// logged and falls back to the cached value.
+func (s *Server) attachDisk(ctx context.Context, infos []*deerv1.SandboxInfo, probe bool) {
+	for _, info := range infos {
+		s.diskMu.Lock()
+		cached, ok := s.diskUsage[info.GetSandboxId()]
+		s.diskMu.Unlock()
+		info.Disk = cached.usage
+
+		if !probe || info.GetState() != "RUNNING" || (ok && time.Since(cached.at) < diskProbeMaxAge) {
+			continue
+		}
+		usage, err := s.probeDisk(ctx, info.GetSandboxId())
+		if err != nil {
+			s.logger.Debug("disk usage probe failed", "sandbox_id", info.GetSandboxId(), "error", err)
+			continue
+		}
+		info.Disk = usage
+	}
+}
+
+// forgetDisk drops the cached disk usage of a destroyed sandbox.
+func (s *Server) forgetDisk(sandboxID string) {
+	s.diskMu.Lock()
+	delete(s.diskUsage, sandboxID)
+	s.diskMu.Unlock()
+}
+
+// parseDF reads the filesystem line of `df -Pk` output.
+func parseDF(out string) (*deerv1.SandboxDiskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return nil, fmt.Errorf("unexpected df output: %q", out)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("unexpected df line: %q", lines[len(lines)-1])
+	}
+	var kb [3]int64
+	for i := range kb {
+		n, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse df field %q: %w", fields[i+1], err)
+		}
+		kb[i] = n
+	}
+	return &deerv1.SandboxDiskUsage{
+		TotalBytes:     kb[0] * 1024,
+		UsedBytes:      kb[1] * 1024,
+		AvailableBytes: kb[2] * 1024,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// fakeDFProvider answers the disk probe with fixed df output.
+type fakeDFProvider struct {
+	fakeCreateSandboxProvider
+	out    string
+	probes int
+}
+
+func (f *fakeDFProvider) RunCommand(context.Context, string, string, time.Duration) (*provider.CommandResult, error) {
+	f.probes++
+	return &provider.CommandResult{Stdout: f.out}, nil
+}
+
+func TestParseDF(t *testing.T) {
+	gnu := "Filesystem     1024-blocks    Used Available Capacity Mounted on\n/dev/vda1         10218772 9254048    423420      96% /\n"
+	busybox := "Filesystem           1024-blocks      Used Available Capacity Mounted on\noverlay                 2048000    512000   1536000  25% /\n"
+
+	usage, err := parseDF(gnu)
+	if err != nil {
+		t.Fatalf("parseDF(gnu): %v", err)
+	}
+	if usage.GetTotalBytes() != 10218772*1024 || usage.GetUsedBytes() != 9254048*1024 || usage.GetAvailableBytes() != 423420*1024 {
+		t.Errorf("gnu usage = %v", usage)
+	}
+	if usage, err := parseDF(busybox); err != nil || usage.GetAvailableBytes() != 1536000*1024 {
+		t.Errorf("parseDF(busybox) = %v, %v", usage, err)
+	}
+	if _, err := parseDF("df: /: No such file"); err == nil {
+		t.Error("expected an error for output without a filesystem line")
+	}
+}
+
+func TestGetSandbox_ReportsDiskUsage(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &fakeDFProvider{out: "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/vda1 1000 950 50 95% /\n"}
+
+	info, err := s.GetSandbox(ctx, &deerv1.GetSandboxRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if info.GetDisk().GetUsedBytes() != 950*1024 || info.GetDisk().GetCheckedAt() == "" {
+		t.Fatalf("disk = %v", info.GetDisk())
+	}
+
+	// List reuses the measurement instead of probing every sandbox.
+	s.prov = &fakeCreateSandboxProvider{}
+	resp, err := s.ListSandboxes(ctx, &deerv1.ListSandboxesRequest{})
+	if err != nil {
+		t.Fatalf("ListSandboxes: %v", err)
+	}
+	if resp.GetSandboxes()[0].GetDisk().GetUsedBytes() != 950*1024 {
+		t.Errorf("listed disk = %v, want the cached measurement", resp.GetSandboxes()[0].GetDisk())
+	}
+}
+
+func TestGetSandbox_ReusesRecentDiskUsage(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeDFProvider{out: "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/vda1 1000 500 500 50% /\n"}
+	s.prov = prov
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.GetSandbox(ctx, &deerv1.GetSandboxRequest{SandboxId: "SBX-1"}); err != nil {
+			t.Fatalf("GetSandbox: %v", err)
+		}
+	}
+	if prov.probes != 1 {
+		t.Fatalf("probes = %d, want 1 while the measurement is fresh", prov.probes)
+	}
+
+	// Age the measurement past diskProbeMaxAge.
+	s.diskMu.Lock()
+	m := s.diskUsage["SBX-1"]
+	m.at = m.at.Add(-2 * diskProbeMaxAge)
+	s.diskUsage["SBX-1"] = m
+	s.diskMu.Unlock()
+
+	if _, err := s.GetSandbox(ctx, &deerv1.GetSandboxRequest{SandboxId: "SBX-1"}); err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if prov.probes != 2 {
+		t.Errorf("probes = %d, want a new probe once the measurement is stale", prov.probes)
+	}
+}
+
+// fakeProbeDFProvider answers the disk probe only through RunProbe.
+type fakeProbeDFProvider struct {
+	fakeDFProvider
+}
+
+func (f *fakeProbeDFProvider) RunCommand(context.Context, string, string, time.Duration) (*provider.CommandResult, error) {
+	return nil, errors.New("disk probe should not spend the command retry budget")
+}
+
+func (f *fakeProbeDFProvider) RunProbe(ctx context.Context, id, command string, timeout time.Duration) (*provider.CommandResult, error) {
+	return f.fakeDFProvider.RunCommand(ctx, id, command, timeout)
+}
+
+func TestGetSandbox_DiskProbeUsesProbeRunner(t *testing.T) {
+	s := newLockTestServer(t)
+	prov := &fakeProbeDFProvider{fakeDFProvider{out: "Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/vda1 1000 500 500 50% /\n"}}
+	s.prov = prov
+
+	info, err := s.GetSandbox(context.Background(), &deerv1.GetSandboxRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if prov.probes != 1 || info.GetDisk().GetUsedBytes() != 500*1024 {
+		t.Errorf("probes = %d, disk = %v; want one probe through RunProbe", prov.probes, info.GetDisk())
+	}
+}
@@ -221,6 +221,7 @@ func (s *Server) checkSourceHosts(ctx context.Context) []*deerv1.DoctorCheckResu
 				Category: "source-hosts",
 				Passed:   false,
 				Message:  fmt.Sprintf("cannot create manager for %s: %v", host, err),
+				FixCmd:   "check this host's entry under source_hosts in the daemon config, then restart deer-daemon",
 			})
 			continue
 		}
@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// drainPollInterval is how often WaitIdle checks for in-flight RPCs.
+const drainPollInterval = 100 * time.Millisecond
+
+// drainState tracks whether the daemon is draining before shutdown and how
+// many RPCs it is still serving.
+type drainState struct {
+	draining atomic.Bool
+
+	mu       sync.Mutex
+	inflight int
+}
+
+// Drain stops the server accepting new sandboxes. Requests that create one
+// fail with codes.Unavailable; everything else, including commands already
+// streaming, carries on so the caller can wait for it with WaitIdle.
+func (s *Server) Drain() {
+	if !s.drain.draining.Swap(true) {
+		s.logger.Info("draining: no longer accepting new sandboxes")
+	}
+}
+
+// Draining reports whether Drain has been called.
+func (s *Server) Draining() bool {
+	return s.drain.draining.Load()
+}
+
+// TrackRPC counts an RPC as in flight until the returned func is called.
+// The daemon's gRPC interceptors call it for every request.
+func (s *Server) TrackRPC() (done func()) {
+	s.drain.mu.Lock()
+	s.drain.inflight++
+	s.drain.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.drain.mu.Lock()
+			s.drain.inflight--
+			s.drain.mu.Unlock()
+		})
+	}
+}
+
+// InFlight returns the number of RPCs being served.
+func (s *Server) InFlight() int {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	return s.drain.inflight
+}
+
+// WaitIdle blocks until no RPCs are in flight or ctx is done, returning
+// ctx's error in the latter case.
+func (s *Server) WaitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for s.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// rejectIfDraining returns an Unavailable error for requests that would
+// create a sandbox while the daemon is draining, so clients retry on
+// another host or after the restart.
+func (s *Server) rejectIfDraining() error {
+	if s.Draining() {
+		return status.Error(codes.Unavailable, "daemon is draining for shutdown and not accepting new sandboxes; retry shortly or use another host")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+func TestDrainRejectsNewSandboxes(t *testing.T) {
+	s := newLockTestServer(t)
+	s.Drain()
+	if !s.Draining() {
+		t.Fatal("Draining() = false after Drain")
+	}
+	ctx := context.Background()
+
+	if _, err := s.CreateSandbox(ctx, &deerv1.CreateSandboxCommand{BaseImage: "ubuntu"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("CreateSandbox while draining: code = %v, want Unavailable (err %v)", status.Code(err), err)
+	}
+	if _, err := s.ForkSandbox(ctx, &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "SNP-1"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("ForkSandbox while draining: code = %v, want Unavailable", status.Code(err))
+	}
+	if _, err := s.ImportSandbox(ctx, &deerv1.ImportSandboxCommand{VmName: "web-1"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("ImportSandbox while draining: code = %v, want Unavailable", status.Code(err))
+	}
+}
+
+func TestWaitIdle(t *testing.T) {
+	s := newLockTestServer(t)
+	done := s.TrackRPC()
+	if got := s.InFlight(); got != 1 {
+		t.Fatalf("InFlight = %d, want 1", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.WaitIdle(ctx); err == nil {
+		t.Fatal("WaitIdle returned nil with an RPC in flight")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		done()
+		done() // a second call must not drive the count negative
+	}()
+	if err := s.WaitIdle(context.Background()); err != nil {
+		t.Fatalf("WaitIdle: %v", err)
+	}
+	if got := s.InFlight(); got != 0 {
+		t.Errorf("InFlight = %d after done, want 0", got)
+	}
+}
@@ -0,0 +1,116 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+)
+
+// ForkSandbox creates a new sandbox from a snapshot of an existing one. The
+// fork gets its own ID and record, with BaseImage set to the parent's ID so
+// its lineage shows up in listings.
+func (s *Server) ForkSandbox(ctx context.Context, req *deerv1.ForkSandboxCommand) (*deerv1.SandboxCreated, error) {
+	if err := s.rejectIfDraining(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	s.telemetry.Track("daemon_sandbox_forked", nil)
+
+	parentID := req.GetSandboxId()
+	if parentID == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if req.GetSnapshot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "snapshot is required")
+	}
+	cloner, ok := s.prov.(provider.SnapshotCloner)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the configured provider cannot clone sandboxes from snapshots")
+	}
+
+	parent, err := s.store.GetSandbox(ctx, parentID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+	snap, err := s.store.GetSnapshot(ctx, parentID, req.GetSnapshot())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "snapshot %s of %s not found", req.GetSnapshot(), parentID)
+		}
+		return nil, status.Errorf(codes.Internal, "look up snapshot: %v", err)
+	}
+	if snap.Kind == state.SnapshotKindArchive {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s is a disk archive; create a sandbox from %s instead", snap.Name, snap.Ref)
+	}
+
+	cmd := &deerv1.CreateSandboxCommand{
+		Name:         req.GetName(),
+		Vcpus:        req.GetVcpus(),
+		MemoryMb:     req.GetMemoryMb(),
+		AgentId:      req.GetAgentId(),
+		SshPublicKey: req.GetSshPublicKey(),
+		// The parent is the fork's base, as recorded below; the pre-create
+		// hook sees it the same way.
+		BaseImage: parentID,
+	}
+	if cmd.Vcpus == 0 {
+		cmd.Vcpus = int32(parent.VCPUs)
+	}
+	if cmd.MemoryMb == 0 {
+		cmd.MemoryMb = int32(parent.MemoryMB)
+	}
+	sandboxID, err := s.resolveSandboxID(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := s.lockSandbox(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	vcpus, memMB, err := s.applyPreCreateHook(ctx, cmd, sandboxID, int(cmd.Vcpus), int(cmd.MemoryMb))
+	if err != nil {
+		return nil, err
+	}
+	createReq := s.providerCreateRequest(cmd, sandboxID, parentID, vcpus, memMB)
+	if err := s.reserveSandbox(ctx, cmd, sandboxID, parentID, createReq.VCPUs, createReq.MemoryMB); err != nil {
+		return nil, err
+	}
+	meta := map[string]any{
+		"sandbox_id":  sandboxID,
+		"forked_from": parentID,
+		"snapshot":    snap.Name,
+		"vcpus":       createReq.VCPUs,
+		"memory_mb":   createReq.MemoryMB,
+	}
+	result, err := cloner.CloneFromSnapshot(ctx, parentID, snap.Name, createReq)
+	if err != nil {
+		s.releaseSandbox(ctx, sandboxID)
+		s.logAudit(audit.TypeSandboxCreated, meta, err, time.Since(start).Milliseconds())
+		return nil, status.Errorf(codes.Internal, "fork sandbox: %v", err)
+	}
+	s.persistCreatedSandbox(ctx, result, cmd, parentID, createReq.VCPUs, createReq.MemoryMB)
+	s.logAudit(audit.TypeSandboxCreated, meta, nil, time.Since(start).Milliseconds())
+
+	return &deerv1.SandboxCreated{
+		SandboxId:  result.SandboxID,
+		Name:       result.Name,
+		State:      result.State,
+		IpAddress:  result.IPAddress,
+		MacAddress: result.MACAddress,
+		Bridge:     result.Bridge,
+		Pid:        int32(result.PID),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/hook"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// fakeForkProvider records the snapshot and request it was asked to clone.
+type fakeForkProvider struct {
+	fakeCreateSandboxProvider
+	parent, snapshot string
+	req              provider.CreateRequest
+	err              error
+}
+
+func (f *fakeForkProvider) CloneFromSnapshot(_ context.Context, parentID, snapshotName string, req provider.CreateRequest) (*provider.SandboxResult, error) {
+	f.parent, f.snapshot, f.req = parentID, snapshotName, req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &provider.SandboxResult{SandboxID: req.SandboxID, Name: req.Name, State: "RUNNING", IPAddress: "10.0.0.9"}, nil
+}
+
+func newForkTestServer(t *testing.T) (*Server, *fakeForkProvider) {
+	t.Helper()
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	sb, _ := s.store.GetSandbox(ctx, "SBX-1")
+	sb.VCPUs, sb.MemoryMB = 4, 4096
+	if err := s.store.UpdateSandbox(ctx, sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, snap := range []*state.Snapshot{
+		{ID: "SNP-1", SandboxID: "SBX-1", Name: "before-upgrade", CreatedAt: time.Now()},
+		{ID: "SNP-2", SandboxID: "SBX-1", Name: "archive-1", Kind: state.SnapshotKindArchive, Ref: "/images/archive-sbx-1.qcow2", CreatedAt: time.Now()},
+	} {
+		if err := s.store.CreateSnapshot(ctx, snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prov := &fakeForkProvider{}
+	s.prov = prov
+	return s, prov
+}
+
+func TestForkSandbox(t *testing.T) {
+	s, prov := newForkTestServer(t)
+	ctx := context.Background()
+
+	got, err := s.ForkSandbox(ctx, &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "SNP-1", Name: "experiment"})
+	if err != nil {
+		t.Fatalf("ForkSandbox: %v", err)
+	}
+	if prov.parent != "SBX-1" || prov.snapshot != "before-upgrade" {
+		t.Errorf("cloned %s@%s, want SBX-1@before-upgrade", prov.parent, prov.snapshot)
+	}
+	if prov.req.VCPUs != 4 || prov.req.MemoryMB != 4096 {
+		t.Errorf("resources = %d vCPU / %d MB, want the parent's 4 / 4096", prov.req.VCPUs, prov.req.MemoryMB)
+	}
+	if got.GetSandboxId() == "" || got.GetSandboxId() == "SBX-1" {
+		t.Errorf("fork ID = %q", got.GetSandboxId())
+	}
+	sb, err := s.store.GetSandbox(ctx, got.GetSandboxId())
+	if err != nil {
+		t.Fatalf("fork not recorded: %v", err)
+	}
+	if sb.BaseImage != "SBX-1" || sb.Name != "experiment" || sb.State != "RUNNING" {
+		t.Errorf("fork record = %+v", sb)
+	}
+}
+
+func TestForkSandbox_PreCreateHook(t *testing.T) {
+	s, prov := newForkTestServer(t)
+	ctx := context.Background()
+
+	h := &fakePreCreateHook{decision: &hook.Decision{Allow: true, MemoryMB: 2048}}
+	s.preCreate = h
+	if _, err := s.ForkSandbox(ctx, &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "before-upgrade"}); err != nil {
+		t.Fatalf("ForkSandbox: %v", err)
+	}
+	if h.got.BaseImage != "SBX-1" || h.got.MemoryMB != 4096 {
+		t.Errorf("hook saw base %q with %d MB, want SBX-1 with 4096", h.got.BaseImage, h.got.MemoryMB)
+	}
+	if prov.req.MemoryMB != 2048 {
+		t.Errorf("provider memory = %d, want the hook's 2048", prov.req.MemoryMB)
+	}
+
+	prov.req = provider.CreateRequest{}
+	s.preCreate = &fakePreCreateHook{decision: &hook.Decision{Allow: false, Reason: "no forks on this host"}}
+	_, err := s.ForkSandbox(ctx, &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "before-upgrade", Name: "denied"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("err = %v, want PermissionDenied", err)
+	}
+	if prov.req.SandboxID != "" {
+		t.Error("provider was called after the hook denied the fork")
+	}
+	if inUse, _ := s.store.SandboxNameInUse(ctx, "denied"); inUse {
+		t.Error("denied fork left a record behind")
+	}
+}
+
+func TestForkSandbox_Errors(t *testing.T) {
+	s, prov := newForkTestServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *deerv1.ForkSandboxCommand
+		want codes.Code
+	}{
+		{"missing snapshot", &deerv1.ForkSandboxCommand{SandboxId: "SBX-1"}, codes.InvalidArgument},
+		{"unknown sandbox", &deerv1.ForkSandboxCommand{SandboxId: "SBX-9", Snapshot: "before-upgrade"}, codes.NotFound},
+		{"unknown snapshot", &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "nope"}, codes.NotFound},
+		{"archive", &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "archive-1"}, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		if _, err := s.ForkSandbox(ctx, tt.req); status.Code(err) != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	prov.err = errors.New("clone failed")
+	if _, err := s.ForkSandbox(ctx, &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "before-upgrade", Name: "doomed"}); status.Code(err) != codes.Internal {
+		t.Errorf("provider failure err = %v, want Internal", err)
+	}
+	if inUse, _ := s.store.SandboxNameInUse(ctx, "doomed"); inUse {
+		t.Error("failed fork left its CREATING record behind")
+	}
+
+	s.prov = &fakeCreateSandboxProvider{}
+	if _, err := s.ForkSandbox(ctx, &deerv1.ForkSandboxCommand{SandboxId: "SBX-1", Snapshot: "before-upgrade"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("unsupported provider err = %v, want Unimplemented", err)
+	}
+}
@@ -4,10 +4,24 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
 
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/readonly"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/shellutil"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/sourcevm"
+	"github.com/aspectrr/deer.sh/shared/multierr"
 )
 
 // adhocSourceVMManager creates a temporary sourcevm.Manager from a SourceHostConnection.
@@ -47,7 +61,10 @@ func (s *Server) adhocSourceVMManager(conn *deerv1.SourceHostConnection) (*sourc
 		proxyJump = fmt.Sprintf("%s@%s:%d", user, host, port)
 	}
 
-	return sourcevm.NewManager(uri, "default", s.keyMgr, "deer-readonly", proxyJump, s.sshIdentityFile, s.caPubKey, s.logger), nil
+	mgr := sourcevm.NewManager(uri, "default", s.keyMgr, "deer-readonly", proxyJump, s.sshIdentityFile, s.caPubKey, s.logger)
+	mgr.SetReadyTimeout(s.cfg.MicroVM.IPDiscoveryTimeout)
+	mgr.SetPolicy(&readonly.Policy{Allow: s.cfg.Readonly.Allow, Deny: s.cfg.Readonly.Deny})
+	return mgr, nil
 }
 
 // sourceHostConns builds SourceHostConnections from the daemon's configured source hosts.
@@ -76,6 +93,15 @@ func (s *Server) sourceHostConns() []*deerv1.SourceHostConnection {
 	return conns
 }
 
+// prepareUser returns the user to prepare a source VM as: requested if the
+// caller named one, else the configured user for the VM's source host.
+func (s *Server) prepareUser(requested, sourceHost string) string {
+	if requested != "" {
+		return requested
+	}
+	return s.cfg.SourceVMUserFor(sourceHost)
+}
+
 // resolveSourceHost looks up which configured source host owns vmName.
 // It checks the cache first, then discovers VMs across all configured hosts.
 func (s *Server) resolveSourceHost(ctx context.Context, vmName string) (*deerv1.SourceHostConnection, error) {
@@ -89,6 +115,9 @@ func (s *Server) resolveSourceHost(ctx context.Context, vmName string) (*deerv1.
 
 	// Discover across all configured source hosts
 	for _, conn := range s.sourceHostConns() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		mgr, err := s.adhocSourceVMManager(conn)
 		if err != nil {
 			s.logger.Warn("failed to create manager for source host", "host", conn.SshHost, "error", err)
@@ -114,3 +143,167 @@ func (s *Server) resolveSourceHost(ctx context.Context, vmName string) (*deerv1.
 	}
 	return nil, fmt.Errorf("VM %q not found on any configured source host", vmName)
 }
+
+// Defaults for listing source hosts when the config leaves them unset.
+const (
+	defaultSourceHostWorkers = 8
+	defaultSourceHostTimeout = 30 * time.Second
+)
+
+// listSourceHostVMs lists VMs on the source hosts concurrently, at most
+// source_host_workers at a time and each bounded by source_host_timeout, and
+// caches which host owns each VM. VMs are returned grouped by host name. Once
+// ctx is cancelled no further hosts are started and what was gathered so far
+// is returned with Cancelled set, so a slow or hung host doesn't hold the
+// caller past its deadline. The error aggregates every per-host failure,
+// each prefixed with its host.
+func (s *Server) listSourceHostVMs(ctx context.Context, conns []*deerv1.SourceHostConnection, list func(context.Context, *deerv1.SourceHostConnection) ([]sourcevm.VMInfo, error)) (*deerv1.SourceVMsList, error) {
+	workers, timeout := defaultSourceHostWorkers, defaultSourceHostTimeout
+	if s.cfg != nil {
+		if s.cfg.SourceHostWorkers > 0 {
+			workers = s.cfg.SourceHostWorkers
+		}
+		if s.cfg.SourceHostTimeout > 0 {
+			timeout = s.cfg.SourceHostTimeout
+		}
+	}
+
+	sorted := append([]*deerv1.SourceHostConnection(nil), conns...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetSshHost() < sorted[j].GetSshHost()
+	})
+
+	type hostResult struct {
+		vms     []sourcevm.VMInfo
+		err     error
+		started bool
+	}
+	results := make([]hostResult, len(sorted))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, conn := range sorted {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		results[i].started = true
+		wg.Add(1)
+		go func(r *hostResult, conn *deerv1.SourceHostConnection) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			hostCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			r.vms, r.err = list(hostCtx, conn)
+		}(&results[i], conn)
+	}
+	wg.Wait()
+
+	out := &deerv1.SourceVMsList{}
+	var errs []error
+	s.vmHostMu.Lock()
+	defer s.vmHostMu.Unlock()
+	for i, r := range results {
+		conn := sorted[i]
+		if !r.started {
+			out.Cancelled = true
+			continue
+		}
+		// A host cancelled mid-listing may still have returned some VMs.
+		for _, vm := range r.vms {
+			s.vmHostCache[vm.Name] = conn
+			out.Vms = append(out.Vms, &deerv1.SourceVMListEntry{
+				Name:      vm.Name,
+				State:     vm.State,
+				IpAddress: vm.IPAddress,
+				Prepared:  vm.Prepared,
+				Host:      conn.GetSshHost(),
+			})
+		}
+		if r.err != nil {
+			if ctx.Err() != nil {
+				out.Cancelled = true
+				continue
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", conn.GetSshHost(), r.err))
+		}
+	}
+	return out, multierr.Combine(errs...)
+}
+
+// SourceVMValidationToProto converts a source VM validation result, with its
+// coded issues, to its proto form. The control plane agent shares it.
+func SourceVMValidationToProto(result *provider.ValidationResult) *deerv1.SourceVMValidation {
+	out := &deerv1.SourceVMValidation{
+		SourceVm:   result.VMName,
+		Valid:      result.Valid,
+		State:      result.State,
+		MacAddress: result.MACAddress,
+		IpAddress:  result.IPAddress,
+		HasNetwork: result.HasNetwork,
+		Warnings:   result.Warnings,
+		Errors:     result.Errors,
+	}
+	for _, is := range result.Issues {
+		out.Issues = append(out.Issues, &deerv1.ValidationIssue{
+			Code:     is.Code,
+			Severity: is.Severity,
+			Detail:   is.Detail,
+		})
+	}
+	return out
+}
+
+// checkCommandOptions rejects SSH options the daemon config does not permit.
+// Agent forwarding and extra identities expose credentials on the daemon host
+// to the sandbox, so both are opt-in.
+func (s *Server) checkCommandOptions(opts provider.CommandOptions) error {
+	if opts.IsZero() {
+		return nil
+	}
+	var sshCfg config.SSHConfig
+	if s.cfg != nil {
+		sshCfg = s.cfg.SSH
+	}
+	if opts.ForwardAgent && !sshCfg.AllowAgentForwarding {
+		return status.Error(codes.PermissionDenied, "agent forwarding is disabled on this daemon (set ssh.allow_agent_forwarding)")
+	}
+	allowed := make(map[string]bool, len(sshCfg.AllowedIdentityFiles))
+	for _, f := range sshCfg.AllowedIdentityFiles {
+		allowed[filepath.Clean(f)] = true
+	}
+	for _, id := range opts.IdentityFiles {
+		if !allowed[filepath.Clean(id)] {
+			return status.Errorf(codes.PermissionDenied, "identity file %s is not in ssh.allowed_identity_files", id)
+		}
+	}
+	return nil
+}
+
+// envNamePattern matches names a POSIX shell can export.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// commandWithEnv prefixes command with exports for env, in name order.
+func commandWithEnv(env map[string]string, command string) (string, error) {
+	if len(env) == 0 {
+		return command, nil
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if !envNamePattern.MatchString(name) {
+			return "", status.Errorf(codes.InvalidArgument, "invalid environment variable name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("export")
+	for _, name := range names {
+		b.WriteString(" " + name + "=" + shellutil.Quote(env[name]))
+	}
+	b.WriteString("; " + command)
+	return b.String(), nil
+}
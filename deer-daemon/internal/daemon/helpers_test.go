@@ -1,9 +1,21 @@
 package daemon
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/sourcevm"
 	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
 )
 
@@ -107,3 +119,240 @@ func TestResolveSourceHost_NotFound_NoHosts(t *testing.T) {
 		t.Fatal("expected error for VM not found")
 	}
 }
+
+func TestPrepareUser(t *testing.T) {
+	s := &Server{
+		cfg: &config.Config{
+			SSH: config.SSHConfig{SourceVMUser: "admin"},
+			SourceHosts: []config.SourceHostConfig{
+				{Address: "10.0.0.1", SourceVMUser: "ubuntu"},
+				{Address: "10.0.0.2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		requested, host, want string
+	}{
+		{"debian", "10.0.0.1", "debian"},
+		{"", "10.0.0.1", "ubuntu"},
+		{"", "10.0.0.2", "admin"},
+		{"", "", "admin"},
+	}
+	for _, tt := range tests {
+		if got := s.prepareUser(tt.requested, tt.host); got != tt.want {
+			t.Errorf("prepareUser(%q, %q) = %q, want %q", tt.requested, tt.host, got, tt.want)
+		}
+	}
+
+	s.cfg.SSH.SourceVMUser = ""
+	if got := s.prepareUser("", "10.0.0.2"); got != "root" {
+		t.Errorf("prepareUser with no configured user = %q, want root", got)
+	}
+}
+
+func TestCheckCommandOptions(t *testing.T) {
+	s := &Server{cfg: &config.Config{}}
+
+	if err := s.checkCommandOptions(provider.CommandOptions{}); err != nil {
+		t.Fatalf("zero options: %v", err)
+	}
+	if err := s.checkCommandOptions(provider.CommandOptions{ForwardAgent: true}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("forwarding with default config: code = %v, want PermissionDenied", status.Code(err))
+	}
+	if err := s.checkCommandOptions(provider.CommandOptions{IdentityFiles: []string{"/root/.ssh/id_ed25519"}}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("unlisted identity: code = %v, want PermissionDenied", status.Code(err))
+	}
+
+	s.cfg.SSH.AllowAgentForwarding = true
+	s.cfg.SSH.AllowedIdentityFiles = []string{"/etc/deer/keys/deploy"}
+	opts := provider.CommandOptions{ForwardAgent: true, IdentityFiles: []string{"/etc/deer/keys/../keys/deploy"}}
+	if err := s.checkCommandOptions(opts); err != nil {
+		t.Errorf("allowed options rejected: %v", err)
+	}
+}
+
+func TestRunCommand_OptionsUnsupportedByProvider(t *testing.T) {
+	s := newLockTestServer(t)
+	s.cfg = &config.Config{}
+	s.cfg.SSH.AllowAgentForwarding = true
+
+	_, err := s.RunCommand(context.Background(), &deerv1.RunCommandCommand{
+		SandboxId:    "SBX-1",
+		Command:      "git clone git@github.com:org/private.git",
+		ForwardAgent: true,
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("code = %v, want FailedPrecondition (err=%v)", status.Code(err), err)
+	}
+}
+
+func TestListSourceHostVMs_CancelledMidListing(t *testing.T) {
+	// One worker lists the hosts in order, so h3 is never started.
+	s := &Server{cfg: &config.Config{SourceHostWorkers: 1}, vmHostCache: make(map[string]*deerv1.SourceHostConnection)}
+	conns := []*deerv1.SourceHostConnection{{SshHost: "h1"}, {SshHost: "h2"}, {SshHost: "h3"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var called []string
+	list, err := s.listSourceHostVMs(ctx, conns, func(ctx context.Context, conn *deerv1.SourceHostConnection) ([]sourcevm.VMInfo, error) {
+		called = append(called, conn.GetSshHost())
+		switch conn.GetSshHost() {
+		case "h1":
+			return []sourcevm.VMInfo{{Name: "web"}}, nil
+		case "h2":
+			// Ctrl+C arrives while h2 is being inspected.
+			cancel()
+			return []sourcevm.VMInfo{{Name: "db"}}, ctx.Err()
+		}
+		return []sourcevm.VMInfo{{Name: "late"}}, nil
+	})
+	if err != nil {
+		t.Fatalf("lastErr = %v, want nil for a cancelled listing", err)
+	}
+	if !list.GetCancelled() {
+		t.Error("expected Cancelled to be set")
+	}
+	if strings.Join(called, ",") != "h1,h2" {
+		t.Errorf("hosts queried = %v, want h1,h2", called)
+	}
+	if len(list.GetVms()) != 2 || list.GetVms()[1].GetName() != "db" || list.GetVms()[1].GetHost() != "h2" {
+		t.Errorf("partial results = %v, want web and db", list.GetVms())
+	}
+	if s.vmHostCache["db"].GetSshHost() != "h2" {
+		t.Error("partial results should still populate the host cache")
+	}
+}
+
+func TestListSourceHostVMs_HostErrorContinues(t *testing.T) {
+	s := &Server{vmHostCache: make(map[string]*deerv1.SourceHostConnection)}
+	conns := []*deerv1.SourceHostConnection{{SshHost: "h1"}, {SshHost: "h2"}}
+
+	list, err := s.listSourceHostVMs(context.Background(), conns, func(_ context.Context, conn *deerv1.SourceHostConnection) ([]sourcevm.VMInfo, error) {
+		if conn.GetSshHost() == "h1" {
+			return nil, errors.New("ssh: connection refused")
+		}
+		return []sourcevm.VMInfo{{Name: "web"}}, nil
+	})
+	if err == nil {
+		t.Error("expected the h1 failure to be reported")
+	}
+	if list.GetCancelled() || len(list.GetVms()) != 1 {
+		t.Errorf("list = %+v, want one VM and not cancelled", list)
+	}
+}
+
+func TestListSourceHostVMs_AggregatesHostErrors(t *testing.T) {
+	s := &Server{vmHostCache: make(map[string]*deerv1.SourceHostConnection)}
+	conns := []*deerv1.SourceHostConnection{{SshHost: "h1"}, {SshHost: "h2"}, {SshHost: "h3"}}
+	refused := errors.New("ssh: connection refused")
+
+	_, err := s.listSourceHostVMs(context.Background(), conns, func(_ context.Context, conn *deerv1.SourceHostConnection) ([]sourcevm.VMInfo, error) {
+		switch conn.GetSshHost() {
+		case "h1":
+			return nil, refused
+		case "h3":
+			return nil, context.DeadlineExceeded
+		}
+		return nil, nil
+	})
+	if err == nil {
+		t.Fatal("expected both host failures to be reported")
+	}
+	if !errors.Is(err, refused) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want both failures visible to errors.Is", err)
+	}
+	if got, want := err.Error(), "h1: ssh: connection refused; h3: context deadline exceeded"; got != want {
+		t.Errorf("err = %q, want %q", got, want)
+	}
+}
+
+func TestListSourceHostVMs_ConcurrentAndOrderedByHost(t *testing.T) {
+	s := &Server{vmHostCache: make(map[string]*deerv1.SourceHostConnection)}
+	conns := []*deerv1.SourceHostConnection{{SshHost: "h3"}, {SshHost: "h1"}, {SshHost: "h2"}}
+
+	// Each host waits until all three are being listed, which only
+	// completes if they run concurrently.
+	var started sync.WaitGroup
+	started.Add(len(conns))
+	list, err := s.listSourceHostVMs(context.Background(), conns, func(ctx context.Context, conn *deerv1.SourceHostConnection) ([]sourcevm.VMInfo, error) {
+		started.Done()
+		started.Wait()
+		return []sourcevm.VMInfo{{Name: "vm-" + conn.GetSshHost()}}, nil
+	})
+	if err != nil {
+		t.Fatalf("listSourceHostVMs: %v", err)
+	}
+	var hosts []string
+	for _, vm := range list.GetVms() {
+		hosts = append(hosts, vm.GetHost())
+	}
+	if strings.Join(hosts, ",") != "h1,h2,h3" {
+		t.Errorf("hosts = %v, want h1,h2,h3", hosts)
+	}
+}
+
+func TestListSourceHostVMs_PerHostTimeout(t *testing.T) {
+	s := &Server{cfg: &config.Config{SourceHostTimeout: 20 * time.Millisecond}, vmHostCache: make(map[string]*deerv1.SourceHostConnection)}
+	conns := []*deerv1.SourceHostConnection{{SshHost: "fast"}, {SshHost: "hung"}}
+
+	list, err := s.listSourceHostVMs(context.Background(), conns, func(ctx context.Context, conn *deerv1.SourceHostConnection) ([]sourcevm.VMInfo, error) {
+		if conn.GetSshHost() == "hung" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return []sourcevm.VMInfo{{Name: "web"}}, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.HasPrefix(err.Error(), "hung: ") {
+		t.Errorf("err = %v, want the hung host's deadline", err)
+	}
+	if list.GetCancelled() || len(list.GetVms()) != 1 {
+		t.Errorf("list = %+v, want the fast host's VM and not cancelled", list)
+	}
+}
+
+// fakeSourceHostTools puts virsh and ssh stand-ins on PATH: virsh reports
+// one address for any VM and ssh echoes the command it was asked to run.
+func fakeSourceHostTools(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"virsh": "#!/bin/sh\necho ' vnet0      52:54:00:00:00:01    ipv4         192.168.122.10/24'\n",
+		"ssh":   "#!/bin/sh\nfor last; do :; done\necho \"ran: $last\"\n",
+	}
+	for name, body := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestRunSourceCommand_ReadonlyPolicy(t *testing.T) {
+	fakeSourceHostTools(t)
+	s := newLockTestServer(t)
+	s.cfg = &config.Config{Readonly: config.ReadonlyConfig{Allow: []string{"tcpdump"}, Deny: []string{"ps"}}}
+	s.keyMgr = fakeKeyProvider{}
+	ctx := context.Background()
+	conn := &deerv1.SourceHostConnection{SshHost: "hv-1"}
+
+	resp, err := s.RunSourceCommand(ctx, &deerv1.RunSourceCommandCommand{SourceVm: "web-01", Command: "tcpdump -c 1 -i eth0", SourceHostConnection: conn})
+	if err != nil {
+		t.Fatalf("allowed extra command: %v", err)
+	}
+	if resp.GetStdout() != "ran: tcpdump -c 1 -i eth0\n" {
+		t.Errorf("stdout = %q, want the command run over ssh", resp.GetStdout())
+	}
+
+	_, err = s.RunSourceCommand(ctx, &deerv1.RunSourceCommandCommand{SourceVm: "web-01", Command: "ps aux", SourceHostConnection: conn})
+	if err == nil || !strings.Contains(err.Error(), "command validation") {
+		t.Errorf("denied built-in command: err = %v, want a validation error", err)
+	}
+
+	s.cfg.Readonly = config.ReadonlyConfig{}
+	_, err = s.RunSourceCommand(ctx, &deerv1.RunSourceCommandCommand{SourceVm: "web-01", Command: "tcpdump -c 1", SourceHostConnection: conn})
+	if err == nil || !strings.Contains(err.Error(), "command validation") {
+		t.Errorf("tcpdump without readonly.allow: err = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// ImportSandbox registers an existing VM/CT as a managed sandbox without
+// cloning it. The sandbox row records the VM name as its base image and is
+// flagged as imported so clients can guard destructive operations.
+func (s *Server) ImportSandbox(ctx context.Context, req *deerv1.ImportSandboxCommand) (*deerv1.SandboxInfo, error) {
+	if err := s.rejectIfDraining(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	s.telemetry.Track("daemon_sandbox_imported", nil)
+
+	vmName := req.GetVmName()
+	if vmName == "" {
+		return nil, status.Error(codes.InvalidArgument, "vm_name is required")
+	}
+
+	importer, ok := s.prov.(provider.Importer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the configured provider does not support importing existing VMs")
+	}
+
+	existing, err := s.store.ListSandboxes(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list sandboxes: %v", err)
+	}
+	for _, sb := range existing {
+		if sb.Imported && sb.BaseImage == vmName {
+			return nil, status.Errorf(codes.AlreadyExists, "%s is already imported as sandbox %s", vmName, sb.ID)
+		}
+	}
+
+	sandboxID, err := s.store.NewSandboxID(ctx, "sbx-")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "generate sandbox ID: %v", err)
+	}
+
+	result, err := importer.ImportSandbox(ctx, sandboxID, vmName)
+	if err != nil {
+		if errors.Is(err, provider.ErrSandboxNotFound) {
+			return nil, status.Errorf(codes.NotFound, "import %s: %v", vmName, err)
+		}
+		return nil, status.Errorf(codes.Internal, "import %s: %v", vmName, err)
+	}
+
+	name := req.GetName()
+	if name == "" {
+		name = vmName
+	}
+	now := time.Now().UTC()
+	sb := &state.Sandbox{
+		ID:        sandboxID,
+		Name:      name,
+		AgentID:   req.GetAgentId(),
+		BaseImage: vmName,
+		IPAddress: result.IPAddress,
+		State:     result.State,
+		Imported:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.store.CreateSandbox(ctx, sb); err != nil {
+		if errors.Is(err, state.ErrNameInUse) {
+			return nil, status.Errorf(codes.AlreadyExists, "persist imported sandbox: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "persist imported sandbox: %v", err)
+	}
+
+	s.logAudit(audit.TypeSandboxImported, map[string]any{
+		"sandbox_id": sandboxID,
+		"vm_name":    vmName,
+	}, nil, time.Since(start).Milliseconds())
+
+	return sandboxToInfo(sb, s.hostID), nil
+}
+
+// ReattachImportedSandboxes re-registers imported sandboxes with the provider
+// after a restart and reconciles their recorded state. Providers only recover
+// sandboxes they cloned themselves, so imported ones need this extra pass.
+func ReattachImportedSandboxes(ctx context.Context, st *state.Store, prov provider.SandboxProvider, logger *slog.Logger) {
+	importer, ok := prov.(provider.Importer)
+	if !ok || st == nil {
+		return
+	}
+	sandboxes, err := st.ListSandboxes(ctx)
+	if err != nil {
+		logger.Warn("list sandboxes for import reattach failed", "error", err)
+		return
+	}
+	for _, sb := range sandboxes {
+		if !sb.Imported {
+			continue
+		}
+		result, err := importer.ImportSandbox(ctx, sb.ID, sb.BaseImage)
+		if err != nil {
+			logger.Warn("reattach imported sandbox failed", "sandbox_id", sb.ID, "vm_name", sb.BaseImage, "error", err)
+			continue
+		}
+		if sb.State != result.State || (result.IPAddress != "" && sb.IPAddress != result.IPAddress) {
+			sb.State = result.State
+			if result.IPAddress != "" {
+				sb.IPAddress = result.IPAddress
+			}
+			sb.UpdatedAt = time.Now().UTC()
+			if err := st.UpdateSandbox(ctx, sb); err != nil {
+				logger.Warn("update imported sandbox state failed", "sandbox_id", sb.ID, "error", err)
+			}
+		}
+	}
+}
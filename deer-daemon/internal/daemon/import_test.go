@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+type fakeImportProvider struct {
+	fakeCreateSandboxProvider
+	vms      map[string]string // vm name -> state
+	imported map[string]string // sandbox ID -> vm name
+}
+
+func (f *fakeImportProvider) ImportSandbox(_ context.Context, sandboxID, vmName string) (*provider.SandboxResult, error) {
+	st, ok := f.vms[vmName]
+	if !ok {
+		return nil, fmt.Errorf("CT %q: %w", vmName, provider.ErrSandboxNotFound)
+	}
+	if f.imported == nil {
+		f.imported = make(map[string]string)
+	}
+	f.imported[sandboxID] = vmName
+	return &provider.SandboxResult{SandboxID: sandboxID, Name: vmName, State: st}, nil
+}
+
+func TestImportSandbox(t *testing.T) {
+	s := newLockTestServer(t)
+	prov := &fakeImportProvider{vms: map[string]string{"legacy-web": "RUNNING"}}
+	s.prov = prov
+
+	info, err := s.ImportSandbox(context.Background(), &deerv1.ImportSandboxCommand{VmName: "legacy-web"})
+	if err != nil {
+		t.Fatalf("ImportSandbox: %v", err)
+	}
+	if !info.GetImported() || info.GetName() != "legacy-web" || info.GetState() != "RUNNING" {
+		t.Errorf("unexpected info: %+v", info)
+	}
+
+	sb, err := s.store.GetSandbox(context.Background(), info.GetSandboxId())
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if !sb.Imported || sb.BaseImage != "legacy-web" {
+		t.Errorf("stored sandbox = %+v, want imported with base image legacy-web", sb)
+	}
+
+	_, err = s.ImportSandbox(context.Background(), &deerv1.ImportSandboxCommand{VmName: "legacy-web"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("second import code = %v, want AlreadyExists", status.Code(err))
+	}
+
+	_, err = s.ImportSandbox(context.Background(), &deerv1.ImportSandboxCommand{VmName: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("missing VM code = %v, want NotFound", status.Code(err))
+	}
+}
+
+func TestDestroySandbox_ImportedNeedsConfirmation(t *testing.T) {
+	s := newLockTestServer(t)
+	s.prov = &fakeImportProvider{vms: map[string]string{"legacy-web": "RUNNING"}}
+	ctx := context.Background()
+
+	info, err := s.ImportSandbox(ctx, &deerv1.ImportSandboxCommand{VmName: "legacy-web"})
+	if err != nil {
+		t.Fatalf("ImportSandbox: %v", err)
+	}
+	id := info.GetSandboxId()
+	if _, err := s.DestroySandbox(ctx, &deerv1.DestroySandboxCommand{SandboxId: id}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("unconfirmed destroy err = %v, want FailedPrecondition", err)
+	}
+	if _, err := s.store.GetSandbox(ctx, id); err != nil {
+		t.Fatalf("imported sandbox removed by an unconfirmed destroy: %v", err)
+	}
+	if _, err := s.DestroySandbox(ctx, &deerv1.DestroySandboxCommand{SandboxId: id, ConfirmImported: true}); err != nil {
+		t.Fatalf("confirmed destroy: %v", err)
+	}
+}
+
+func TestImportSandbox_Unsupported(t *testing.T) {
+	s := newLockTestServer(t)
+
+	_, err := s.ImportSandbox(context.Background(), &deerv1.ImportSandboxCommand{VmName: "legacy-web"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("code = %v, want Unimplemented", status.Code(err))
+	}
+}
+
+func TestReattachImportedSandboxes(t *testing.T) {
+	s := newLockTestServer(t)
+	prov := &fakeImportProvider{vms: map[string]string{"legacy-web": "RUNNING"}}
+	s.prov = prov
+
+	info, err := s.ImportSandbox(context.Background(), &deerv1.ImportSandboxCommand{VmName: "legacy-web"})
+	if err != nil {
+		t.Fatalf("ImportSandbox: %v", err)
+	}
+
+	// Simulate a restart where the VM was stopped out-of-band.
+	restarted := &fakeImportProvider{vms: map[string]string{"legacy-web": "STOPPED"}}
+	ReattachImportedSandboxes(context.Background(), s.store, restarted, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if restarted.imported[info.GetSandboxId()] != "legacy-web" {
+		t.Errorf("sandbox %s was not re-registered with the provider", info.GetSandboxId())
+	}
+	sb, err := s.store.GetSandbox(context.Background(), info.GetSandboxId())
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if sb.State != "STOPPED" {
+		t.Errorf("state = %q, want STOPPED after reconcile", sb.State)
+	}
+}
@@ -0,0 +1,130 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// ipRefreshWorkers bounds concurrent IP discovery so a bulk refresh does not
+// flood the provider with lease lookups.
+const ipRefreshWorkers = 8
+
+// RefreshSandboxIPs rediscovers the IP of each requested sandbox, or of every
+// running sandbox when none are named, and stores any that changed. A
+// discovered IP already held by another sandbox is reported as a conflict and
+// not stored.
+func (s *Server) RefreshSandboxIPs(ctx context.Context, req *deerv1.RefreshSandboxIPsRequest) (*deerv1.RefreshSandboxIPsResponse, error) {
+	if s.store == nil {
+		return nil, status.Error(codes.FailedPrecondition, "sandbox store not available")
+	}
+	all, err := s.store.ListSandboxes(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list sandboxes: %v", err)
+	}
+
+	var targets []*state.Sandbox
+	if len(req.GetSandboxIds()) == 0 {
+		for _, sb := range all {
+			if sb.State == "RUNNING" {
+				targets = append(targets, sb)
+			}
+		}
+	} else {
+		byID := make(map[string]*state.Sandbox, len(all))
+		for _, sb := range all {
+			byID[sb.ID] = sb
+		}
+		for _, id := range req.GetSandboxIds() {
+			sb, ok := byID[id]
+			if !ok {
+				return nil, status.Errorf(codes.NotFound, "sandbox not found: %s", id)
+			}
+			targets = append(targets, sb)
+		}
+	}
+
+	results := make([]*deerv1.SandboxIPResult, len(targets))
+	sem := make(chan struct{}, ipRefreshWorkers)
+	var wg sync.WaitGroup
+	for i, sb := range targets {
+		results[i] = &deerv1.SandboxIPResult{SandboxId: sb.ID, Name: sb.Name, PreviousIp: sb.IPAddress}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Error = ctx.Err().Error()
+			continue
+		}
+		wg.Add(1)
+		go func(r *deerv1.SandboxIPResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ip, err := s.prov.GetSandboxIP(ctx, r.SandboxId)
+			if err != nil {
+				r.Error = fmt.Sprintf("discover IP: %v", err)
+				return
+			}
+			r.IpAddress = ip
+		}(results[i])
+	}
+	wg.Wait()
+
+	// Check uniqueness against the stored IPs of sandboxes not refreshed
+	// here and against the other fresh results.
+	owners := make(map[string]string)
+	bridges := make(map[string]string, len(targets))
+	for _, sb := range targets {
+		bridges[sb.ID] = sb.Bridge
+	}
+	for _, sb := range all {
+		if _, refreshed := bridges[sb.ID]; !refreshed && sb.IPAddress != "" {
+			owners[s.ipScopeKey(sb.Bridge, sb.IPAddress)] = sb.ID
+		}
+	}
+	for _, r := range results {
+		if r.Error != "" || r.IpAddress == "" {
+			continue
+		}
+		key := s.ipScopeKey(bridges[r.SandboxId], r.IpAddress)
+		if err := validateIPUniqueness(owners, key, r.SandboxId, r.IpAddress); err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		owners[key] = r.SandboxId
+		if r.IpAddress == r.PreviousIp {
+			continue
+		}
+		if err := s.store.UpdateSandboxIP(ctx, r.SandboxId, r.IpAddress); err != nil {
+			r.Error = fmt.Sprintf("store IP: %v", err)
+			continue
+		}
+		s.logger.Info("sandbox IP refreshed", "sandbox_id", r.SandboxId, "previous_ip", r.PreviousIp, "ip", r.IpAddress)
+	}
+	return &deerv1.RefreshSandboxIPsResponse{Results: results}, nil
+}
+
+// ipScopeKey returns the key under which ip must be unique. With the default
+// "network" scope an IP only conflicts with the same IP on the same bridge;
+// with network.ip_uniqueness set to "global" it conflicts across all bridges.
+func (s *Server) ipScopeKey(bridge, ip string) string {
+	if s.cfg != nil && s.cfg.Network.IPUniqueness == config.IPUniquenessGlobal {
+		return ip
+	}
+	return bridge + "/" + ip
+}
+
+// validateIPUniqueness returns an error if key is already held by a sandbox
+// other than id in owners, which maps ipScopeKey keys to sandbox IDs.
+func validateIPUniqueness(owners map[string]string, key, id, ip string) error {
+	if owner, ok := owners[key]; ok && owner != id {
+		return fmt.Errorf("IP %s conflicts with sandbox %s", ip, owner)
+	}
+	return nil
+}
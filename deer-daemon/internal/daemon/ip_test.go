@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// fakeIPProvider reports the IPs in ips; sandboxes without one fail discovery.
+type fakeIPProvider struct {
+	fakeCreateSandboxProvider
+	ips map[string]string
+}
+
+func (f *fakeIPProvider) GetSandboxIP(_ context.Context, id string) (string, error) {
+	if ip, ok := f.ips[id]; ok {
+		return ip, nil
+	}
+	return "", errors.New("no DHCP lease")
+}
+
+func TestRefreshSandboxIPs_All(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	for _, sb := range []*state.Sandbox{
+		{ID: "SBX-2", Name: "two", State: "RUNNING", IPAddress: "10.0.0.2"},
+		{ID: "SBX-3", Name: "three", State: "RUNNING"},
+		{ID: "SBX-4", Name: "four", State: "STOPPED", IPAddress: "10.0.0.9"},
+		{ID: "SBX-5", Name: "five", State: "RUNNING"},
+	} {
+		if err := s.store.CreateSandbox(ctx, sb); err != nil {
+			t.Fatalf("CreateSandbox: %v", err)
+		}
+	}
+	s.prov = &fakeIPProvider{ips: map[string]string{
+		"SBX-1": "10.0.0.1",
+		"SBX-2": "10.0.0.7", // new lease
+		"SBX-5": "10.0.0.9", // still held by stopped SBX-4
+	}}
+
+	resp, err := s.RefreshSandboxIPs(ctx, &deerv1.RefreshSandboxIPsRequest{})
+	if err != nil {
+		t.Fatalf("RefreshSandboxIPs: %v", err)
+	}
+	results := map[string]*deerv1.SandboxIPResult{}
+	for _, r := range resp.GetResults() {
+		results[r.GetSandboxId()] = r
+	}
+	if len(results) != 4 {
+		t.Fatalf("results = %v, want the four running sandboxes", resp.GetResults())
+	}
+	if r := results["SBX-2"]; r.GetIpAddress() != "10.0.0.7" || r.GetPreviousIp() != "10.0.0.2" || r.GetError() != "" {
+		t.Errorf("SBX-2 = %v", r)
+	}
+	if results["SBX-3"].GetError() == "" {
+		t.Error("SBX-3 should report the discovery failure")
+	}
+	if results["SBX-5"].GetError() == "" {
+		t.Error("SBX-5 should report the IP conflict with SBX-4")
+	}
+
+	for id, want := range map[string]string{"SBX-1": "10.0.0.1", "SBX-2": "10.0.0.7", "SBX-5": ""} {
+		sb, err := s.store.GetSandbox(ctx, id)
+		if err != nil {
+			t.Fatalf("GetSandbox %s: %v", id, err)
+		}
+		if sb.IPAddress != want {
+			t.Errorf("%s stored IP = %q, want %q", id, sb.IPAddress, want)
+		}
+	}
+}
+
+func TestRefreshSandboxIPs_Named(t *testing.T) {
+	s := newLockTestServer(t)
+	s.prov = &fakeIPProvider{ips: map[string]string{"SBX-1": "10.0.0.1"}}
+
+	resp, err := s.RefreshSandboxIPs(context.Background(), &deerv1.RefreshSandboxIPsRequest{SandboxIds: []string{"SBX-1"}})
+	if err != nil {
+		t.Fatalf("RefreshSandboxIPs: %v", err)
+	}
+	if len(resp.GetResults()) != 1 || resp.GetResults()[0].GetIpAddress() != "10.0.0.1" {
+		t.Errorf("results = %v", resp.GetResults())
+	}
+
+	if _, err := s.RefreshSandboxIPs(context.Background(), &deerv1.RefreshSandboxIPsRequest{SandboxIds: []string{"SBX-404"}}); err == nil {
+		t.Error("expected NotFound for an unknown sandbox")
+	}
+}
+
+func TestRefreshSandboxIPs_ScopeByBridge(t *testing.T) {
+	for _, tc := range []struct {
+		scope        string
+		wantConflict bool
+	}{
+		{"network", false},
+		{"global", true},
+	} {
+		t.Run(tc.scope, func(t *testing.T) {
+			s := newLockTestServer(t)
+			s.cfg = &config.Config{Network: config.NetworkConfig{IPUniqueness: tc.scope}}
+			ctx := context.Background()
+			// Same private IP, but on a separate NAT bridge.
+			if err := s.store.CreateSandbox(ctx, &state.Sandbox{ID: "SBX-2", Name: "two", State: "STOPPED", Bridge: "virbr1", IPAddress: "192.168.122.10"}); err != nil {
+				t.Fatalf("CreateSandbox: %v", err)
+			}
+			s.prov = &fakeIPProvider{ips: map[string]string{"SBX-1": "192.168.122.10"}}
+
+			resp, err := s.RefreshSandboxIPs(ctx, &deerv1.RefreshSandboxIPsRequest{SandboxIds: []string{"SBX-1"}})
+			if err != nil {
+				t.Fatalf("RefreshSandboxIPs: %v", err)
+			}
+			if got := resp.GetResults()[0].GetError() != ""; got != tc.wantConflict {
+				t.Errorf("conflict = %v, want %v (%v)", got, tc.wantConflict, resp.GetResults()[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+)
+
+const (
+	// sandboxLockTTL bounds how long a mutating operation may hold a sandbox
+	// lock. It only matters if the daemon dies mid-operation; normal calls
+	// release the lock when they return.
+	sandboxLockTTL = 15 * time.Minute
+
+	// clientIdentityHeader is the gRPC metadata key the CLI uses to identify
+	// itself (user@host and pid) so lock contention errors name the holder.
+	clientIdentityHeader = "x-deer-client"
+)
+
+// lockOwner identifies the caller of an RPC for sandbox locking. It prefers
+// the identity sent by the CLI and falls back to the peer address.
+func lockOwner(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(clientIdentityHeader); len(v) > 0 && v[0] != "" {
+			return v[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
+
+// lockSandbox acquires the advisory lock on a sandbox for the duration of a
+// mutating operation. The returned release func is always non-nil. A sandbox
+// that is not tracked in the store is not locked, so operations on it proceed.
+func (s *Server) lockSandbox(ctx context.Context, id string) (func(), error) {
+	noop := func() {}
+	if s.store == nil {
+		return noop, nil
+	}
+
+	owner := lockOwner(ctx)
+	if err := s.store.AcquireSandboxLock(ctx, id, owner, sandboxLockTTL); err != nil {
+		if state.IsLocked(err) {
+			return noop, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("failed to acquire sandbox lock, continuing without it", "sandbox_id", id, "error", err)
+		}
+		return noop, nil
+	}
+
+	return func() {
+		if err := s.store.ReleaseSandboxLock(context.Background(), id, owner); err != nil {
+			s.logger.Warn("failed to release sandbox lock", "sandbox_id", id, "error", err)
+		}
+	}, nil
+}
+
+// UnlockSandbox clears a sandbox's advisory lock whoever holds it, for when
+// the holder died mid-operation and the lock has not yet expired.
+func (s *Server) UnlockSandbox(ctx context.Context, req *deerv1.UnlockSandboxRequest) (*deerv1.SandboxUnlocked, error) {
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if s.store == nil {
+		return nil, status.Error(codes.FailedPrecondition, "sandbox store not available")
+	}
+
+	holder, err := s.store.ForceReleaseSandboxLock(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %s", id)
+	}
+	s.logAudit(audit.TypeSandboxUnlocked, map[string]any{
+		"sandbox_id": id,
+		"locked_by":  holder,
+		"by":         lockOwner(ctx),
+	}, err, 0)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unlock sandbox: %v", err)
+	}
+	return &deerv1.SandboxUnlocked{SandboxId: id, LockedBy: holder}, nil
+}
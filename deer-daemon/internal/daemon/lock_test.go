@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/telemetry"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+func newLockTestServer(t *testing.T) *Server {
+	t.Helper()
+	st, err := state.NewStore(":memory:")
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	if err := st.CreateSandbox(context.Background(), &state.Sandbox{ID: "SBX-1", Name: "sbx", State: "RUNNING"}); err != nil {
+		t.Fatalf("CreateSandbox: %v", err)
+	}
+	return &Server{
+		store:     st,
+		prov:      &fakeCreateSandboxProvider{},
+		telemetry: telemetry.NewNoopService(),
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func clientCtx(id string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(clientIdentityHeader, id))
+}
+
+func TestLockOwner(t *testing.T) {
+	if got := lockOwner(clientCtx("alice@laptop pid 42")); got != "alice@laptop pid 42" {
+		t.Errorf("lockOwner = %q", got)
+	}
+	if got := lockOwner(context.Background()); got != "unknown" {
+		t.Errorf("lockOwner without metadata = %q, want unknown", got)
+	}
+}
+
+func TestLockSandbox_Contention(t *testing.T) {
+	s := newLockTestServer(t)
+
+	release, err := s.lockSandbox(clientCtx("alice"), "SBX-1")
+	if err != nil {
+		t.Fatalf("first lock: %v", err)
+	}
+
+	_, err = s.StopSandbox(clientCtx("bob"), &deerv1.StopSandboxCommand{SandboxId: "SBX-1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition, got %v", err)
+	}
+	if msg := status.Convert(err).Message(); msg == "" || !containsAll(msg, "SBX-1", "in use by alice") {
+		t.Errorf("unexpected error message: %q", msg)
+	}
+
+	release()
+
+	if _, err := s.lockSandbox(clientCtx("bob"), "SBX-1"); err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+}
+
+func TestLockSandbox_UntrackedSandbox(t *testing.T) {
+	s := newLockTestServer(t)
+
+	release, err := s.lockSandbox(clientCtx("alice"), "SBX-unknown")
+	if err != nil {
+		t.Fatalf("expected untracked sandbox to proceed unlocked, got %v", err)
+	}
+	release()
+}
+
+func TestUnlockSandbox_ClearsOtherHolder(t *testing.T) {
+	s := newLockTestServer(t)
+
+	if _, err := s.lockSandbox(clientCtx("alice"), "SBX-1"); err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	resp, err := s.UnlockSandbox(clientCtx("bob"), &deerv1.UnlockSandboxRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("UnlockSandbox: %v", err)
+	}
+	if resp.GetLockedBy() != "alice" {
+		t.Errorf("locked_by = %q, want alice", resp.GetLockedBy())
+	}
+	if _, err := s.lockSandbox(clientCtx("bob"), "SBX-1"); err != nil {
+		t.Errorf("lock after unlock: %v", err)
+	}
+
+	_, err = s.UnlockSandbox(context.Background(), &deerv1.UnlockSandboxRequest{SandboxId: "SBX-unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unlock unknown sandbox: got %v, want NotFound", err)
+	}
+}
+
+func containsAll(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
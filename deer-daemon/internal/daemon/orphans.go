@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// liveSandboxes returns the sandboxes in the store, including CREATING rows
+// of creates still in progress, for the provider's orphan check.
+func liveSandboxes(ctx context.Context, st *state.Store) ([]provider.LiveSandbox, error) {
+	sandboxes, err := st.ListSandboxes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live := make([]provider.LiveSandbox, 0, len(sandboxes))
+	for _, sb := range sandboxes {
+		live = append(live, provider.LiveSandbox{ID: sb.ID, Name: sb.Name})
+	}
+	return live, nil
+}
+
+// poolLow reports whether fewer than a tenth of a provider's pool, or ten
+// entries, are free.
+func poolLow(size, free int) bool {
+	return size > 0 && (free <= 10 || free*10 < size)
+}
+
+// ReportOrphans logs provider resources that no sandbox owns and warns when
+// the provider's allocation pool is nearly used up. It runs at startup; the
+// resources are only deleted on request through ReclaimOrphans.
+func ReportOrphans(ctx context.Context, st *state.Store, prov provider.SandboxProvider, logger *slog.Logger) {
+	reclaimer, ok := prov.(provider.OrphanReclaimer)
+	if !ok || st == nil {
+		return
+	}
+	live, err := liveSandboxes(ctx, st)
+	if err != nil {
+		logger.Warn("list sandboxes for orphan check failed", "error", err)
+		return
+	}
+	report, err := reclaimer.ListOrphans(ctx, live)
+	if err != nil {
+		logger.Warn("orphan check failed", "error", err)
+		return
+	}
+	for _, o := range report.Orphans {
+		logger.Warn("orphaned provider resource", "id", o.ID, "name", o.Name, "state", o.State, "reason", o.Reason)
+	}
+	if len(report.Orphans) > 0 {
+		logger.Warn("run 'deer orphans --reclaim' to free orphaned resources", "count", len(report.Orphans))
+	}
+	if poolLow(report.PoolSize, report.PoolFree) {
+		logger.Warn("provider allocation pool nearly full", "size", report.PoolSize, "free", report.PoolFree, "orphans", len(report.Orphans))
+	}
+}
+
+// ListOrphans reports provider resources that belong to no sandbox in the
+// store. It never deletes them; ReclaimOrphans does, by ID.
+func (s *Server) ListOrphans(ctx context.Context, _ *deerv1.ListOrphansRequest) (*deerv1.ListOrphansResponse, error) {
+	_, report, err := s.orphanReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := &deerv1.ListOrphansResponse{
+		PoolSize: int32(report.PoolSize),
+		PoolFree: int32(report.PoolFree),
+	}
+	for _, o := range report.Orphans {
+		resp.Orphans = append(resp.Orphans, &deerv1.OrphanResource{Id: o.ID, Name: o.Name, State: o.State, Reason: o.Reason})
+	}
+	return resp, nil
+}
+
+// ReclaimOrphans deletes the requested orphans. Each ID is checked against
+// a fresh orphan report first, so a resource a sandbox has claimed since it
+// was listed is not deleted.
+func (s *Server) ReclaimOrphans(ctx context.Context, req *deerv1.ReclaimOrphansRequest) (*deerv1.ReclaimOrphansResponse, error) {
+	if len(req.GetIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ids are required")
+	}
+	reclaimer, report, err := s.orphanReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orphans := make(map[string]provider.Orphan, len(report.Orphans))
+	for _, o := range report.Orphans {
+		orphans[o.ID] = o
+	}
+
+	resp := &deerv1.ReclaimOrphansResponse{
+		PoolSize: int32(report.PoolSize),
+		PoolFree: int32(report.PoolFree),
+	}
+	for _, id := range req.GetIds() {
+		o, ok := orphans[id]
+		if !ok {
+			resp.Orphans = append(resp.Orphans, &deerv1.OrphanResource{Id: id, Error: "not an orphan"})
+			continue
+		}
+		entry := &deerv1.OrphanResource{Id: o.ID, Name: o.Name, State: o.State, Reason: o.Reason}
+		start := time.Now()
+		err := reclaimer.ReclaimOrphan(ctx, o.ID)
+		s.logAudit(audit.TypeOrphanReclaimed, map[string]any{
+			"orphan_id": o.ID,
+			"name":      o.Name,
+		}, err, time.Since(start).Milliseconds())
+		if err != nil {
+			entry.Error = fmt.Sprintf("reclaim: %v", err)
+		} else {
+			entry.Reclaimed = true
+			resp.PoolFree++
+		}
+		resp.Orphans = append(resp.Orphans, entry)
+	}
+	return resp, nil
+}
+
+// orphanReport asks the provider for its current orphans.
+func (s *Server) orphanReport(ctx context.Context) (provider.OrphanReclaimer, *provider.OrphanReport, error) {
+	reclaimer, ok := s.prov.(provider.OrphanReclaimer)
+	if !ok {
+		return nil, nil, status.Error(codes.Unimplemented, "this provider does not track orphaned resources")
+	}
+	if s.store == nil {
+		return nil, nil, status.Error(codes.FailedPrecondition, "sandbox store not available")
+	}
+	live, err := liveSandboxes(ctx, s.store)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "list sandboxes: %v", err)
+	}
+	report, err := reclaimer.ListOrphans(ctx, live)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Unavailable, "list orphans: %v", err)
+	}
+	return reclaimer, report, nil
+}
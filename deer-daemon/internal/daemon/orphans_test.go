@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// fakeOrphanProvider reports every resource in res not named by a live
+// sandbox as an orphan.
+type fakeOrphanProvider struct {
+	fakeCreateSandboxProvider
+	res       map[string]string // id -> name
+	reclaimed []string
+	failID    string
+}
+
+func (f *fakeOrphanProvider) ListOrphans(_ context.Context, live []provider.LiveSandbox) (*provider.OrphanReport, error) {
+	names := map[string]bool{}
+	for _, sb := range live {
+		names[sb.Name] = true
+	}
+	report := &provider.OrphanReport{PoolSize: 100, PoolFree: 5}
+	for _, id := range []string{"9001", "9002", "9003"} {
+		if name, ok := f.res[id]; ok && !names[name] {
+			report.Orphans = append(report.Orphans, provider.Orphan{ID: id, Name: name})
+		}
+	}
+	return report, nil
+}
+
+func (f *fakeOrphanProvider) ReclaimOrphan(_ context.Context, id string) error {
+	if id == f.failID {
+		return errors.New("CT is locked")
+	}
+	f.reclaimed = append(f.reclaimed, id)
+	return nil
+}
+
+func TestListOrphans(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeOrphanProvider{
+		res:    map[string]string{"9001": "sbx", "9002": "leaked", "9003": "stuck"},
+		failID: "9003",
+	}
+	s.prov = prov
+
+	resp, err := s.ListOrphans(ctx, &deerv1.ListOrphansRequest{})
+	if err != nil {
+		t.Fatalf("ListOrphans: %v", err)
+	}
+	if len(resp.GetOrphans()) != 2 || resp.GetOrphans()[0].GetId() != "9002" {
+		t.Fatalf("orphans = %v, want 9002 and 9003", resp.GetOrphans())
+	}
+	if len(prov.reclaimed) != 0 {
+		t.Errorf("listing reclaimed %v", prov.reclaimed)
+	}
+
+	s.prov = &fakeCreateSandboxProvider{}
+	_, err = s.ListOrphans(ctx, &deerv1.ListOrphansRequest{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("code = %v, want Unimplemented", status.Code(err))
+	}
+}
+
+func TestReclaimOrphans(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeOrphanProvider{
+		res:    map[string]string{"9001": "sbx", "9002": "leaked", "9003": "stuck"},
+		failID: "9003",
+	}
+	s.prov = prov
+
+	resp, err := s.ReclaimOrphans(ctx, &deerv1.ReclaimOrphansRequest{Ids: []string{"9002", "9003", "9001"}})
+	if err != nil {
+		t.Fatalf("ReclaimOrphans: %v", err)
+	}
+	got := resp.GetOrphans()
+	if len(got) != 3 || !got[0].GetReclaimed() || got[1].GetError() == "" || got[2].GetError() != "not an orphan" {
+		t.Errorf("orphans = %v, want 9002 reclaimed, 9003 failed and 9001 refused", got)
+	}
+	if len(prov.reclaimed) != 1 || prov.reclaimed[0] != "9002" {
+		t.Errorf("reclaimed = %v, want only 9002", prov.reclaimed)
+	}
+	if resp.GetPoolFree() != 6 {
+		t.Errorf("pool free = %d, want 6 after one reclaim", resp.GetPoolFree())
+	}
+
+	if _, err := s.ReclaimOrphans(ctx, &deerv1.ReclaimOrphansRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("no ids: err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestPoolLow(t *testing.T) {
+	for _, tc := range []struct {
+		size, free int
+		want       bool
+	}{
+		{0, 0, false},
+		{1000, 500, false},
+		{1000, 50, true},
+		{50, 10, true},
+		{50, 20, false},
+	} {
+		if got := poolLow(tc.size, tc.free); got != tc.want {
+			t.Errorf("poolLow(%d, %d) = %v, want %v", tc.size, tc.free, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// PlanSandbox works out what CreateSandbox would do for req - the sandbox
+// ID, the source host the VM resolves to, the effective resources, and
+// whether they fit on this host - without pulling an image, reserving a
+// record, or touching the provider. Problems the create would likely hit
+// are reported as warnings rather than errors; only a name or ID that is
+// already taken fails the plan, as it would fail the create.
+func (s *Server) PlanSandbox(ctx context.Context, req *deerv1.CreateSandboxCommand) (*deerv1.SandboxPlan, error) {
+	s.telemetry.Track("daemon_sandbox_planned", nil)
+
+	sandboxID, err := s.resolveSandboxID(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	plan := &deerv1.SandboxPlan{
+		SandboxId:        sandboxID,
+		Name:             req.GetName(),
+		HostId:           s.hostID,
+		Hostname:         hostname,
+		SourceVm:         req.GetSourceVm(),
+		RequiresApproval: s.preCreate != nil,
+	}
+
+	conn := req.GetSourceHostConnection()
+	if conn == nil && req.GetSourceVm() != "" && s.puller != nil && len(s.cfg.SourceHosts) > 0 {
+		resolved, err := s.resolveSourceHost(ctx, req.GetSourceVm())
+		if err != nil {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("resolve source host: %v", err))
+		}
+		conn = resolved
+	}
+	if conn != nil {
+		plan.SourceHostType = conn.GetType()
+		plan.SourceHost = conn.GetSshHost()
+		if conn.GetType() == "proxmox" {
+			plan.SourceHost = conn.GetProxmoxHost()
+		}
+	}
+
+	createReq := s.providerCreateRequest(req, sandboxID, req.GetBaseImage(), int(req.GetVcpus()), int(req.GetMemoryMb()))
+	plan.Vcpus = int32(createReq.VCPUs)
+	plan.MemoryMb = int32(createReq.MemoryMB)
+
+	caps, err := s.prov.Capabilities(ctx)
+	if err != nil {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("read host capacity: %v", err))
+		return plan, nil
+	}
+	plan.AvailableCpus = int32(caps.AvailableCPUs)
+	plan.AvailableMemoryMb = int64(caps.AvailableMemMB)
+	if caps.AvailableCPUs > 0 && createReq.VCPUs > caps.AvailableCPUs {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d vCPUs requested but the host has %d", createReq.VCPUs, caps.AvailableCPUs))
+	}
+	if caps.AvailableMemMB > 0 && createReq.MemoryMB > caps.AvailableMemMB {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d MB of memory requested but only %d MB is free", createReq.MemoryMB, caps.AvailableMemMB))
+	}
+	return plan, nil
+}
@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+type fakeCapacityProvider struct {
+	fakeCreateSandboxProvider
+	caps provider.HostCapabilities
+}
+
+func (f *fakeCapacityProvider) Capabilities(context.Context) (*provider.HostCapabilities, error) {
+	return &f.caps, nil
+}
+
+func TestPlanSandbox_CreatesNothing(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeCapacityProvider{caps: provider.HostCapabilities{AvailableCPUs: 8, AvailableMemMB: 16384}}
+	prov.createFn = func(context.Context, provider.CreateRequest) (*provider.SandboxResult, error) {
+		t.Fatal("PlanSandbox called the provider's CreateSandbox")
+		return nil, nil
+	}
+	s.prov = prov
+
+	plan, err := s.PlanSandbox(ctx, &deerv1.CreateSandboxCommand{SourceVm: "web-01", Name: "trial"})
+	if err != nil {
+		t.Fatalf("PlanSandbox: %v", err)
+	}
+	if !strings.HasPrefix(plan.GetSandboxId(), "sbx-") || plan.GetName() != "trial" || plan.GetSourceVm() != "web-01" {
+		t.Errorf("plan = %+v", plan)
+	}
+	if plan.GetVcpus() != int32(provider.DefaultSandboxVCPUs) || plan.GetMemoryMb() != int32(provider.DefaultSandboxMemMB) {
+		t.Errorf("resources = %d vCPUs / %d MB, want the defaults", plan.GetVcpus(), plan.GetMemoryMb())
+	}
+	if len(plan.GetWarnings()) != 0 || plan.GetRequiresApproval() {
+		t.Errorf("warnings = %v, requires_approval = %v", plan.GetWarnings(), plan.GetRequiresApproval())
+	}
+
+	sandboxes, err := s.store.ListSandboxes(ctx)
+	if err != nil {
+		t.Fatalf("ListSandboxes: %v", err)
+	}
+	if len(sandboxes) != 1 {
+		t.Errorf("store has %d sandboxes after a plan, want only the seeded one", len(sandboxes))
+	}
+}
+
+func TestPlanSandbox_WarnsWhenHostIsShort(t *testing.T) {
+	s := newLockTestServer(t)
+	s.prov = &fakeCapacityProvider{caps: provider.HostCapabilities{AvailableCPUs: 2, AvailableMemMB: 1024}}
+
+	plan, err := s.PlanSandbox(context.Background(), &deerv1.CreateSandboxCommand{SourceVm: "web-01", Vcpus: 4, MemoryMb: 4096})
+	if err != nil {
+		t.Fatalf("PlanSandbox: %v", err)
+	}
+	if len(plan.GetWarnings()) != 2 {
+		t.Errorf("warnings = %v, want one for vCPUs and one for memory", plan.GetWarnings())
+	}
+}
+
+func TestPlanSandbox_NameInUse(t *testing.T) {
+	s := newLockTestServer(t)
+
+	_, err := s.PlanSandbox(context.Background(), &deerv1.CreateSandboxCommand{SourceVm: "web-01", Name: "sbx"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("PlanSandbox with a taken name = %v, want AlreadyExists", err)
+	}
+}
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/hook"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// applyPreCreateHook consults the configured pre-create hook and returns the
+// vCPU and memory values to create with. A denial is returned as
+// PermissionDenied carrying the hook's reason.
+func (s *Server) applyPreCreateHook(ctx context.Context, req *deerv1.CreateSandboxCommand, sandboxID string, vcpus, memMB int) (int, int, error) {
+	if s.preCreate == nil {
+		return vcpus, memMB, nil
+	}
+
+	params := hook.CreateParams{
+		SandboxID:  sandboxID,
+		Name:       req.GetName(),
+		SourceVM:   req.GetSourceVm(),
+		BaseImage:  req.GetBaseImage(),
+		VCPUs:      vcpus,
+		MemoryMB:   memMB,
+		TTLSeconds: int(req.GetTtlSeconds()),
+		AgentID:    req.GetAgentId(),
+		Client:     lockOwner(ctx),
+		HostID:     s.hostID,
+	}
+
+	start := time.Now()
+	decision, err := s.preCreate.PreCreate(ctx, params)
+	if err != nil {
+		if s.cfg != nil && s.cfg.Hooks.PreCreate.FailOpen {
+			s.logger.Warn("pre-create hook failed, allowing create", "sandbox_id", sandboxID, "error", err)
+			return vcpus, memMB, nil
+		}
+		s.recordError("hook.pre_create", err)
+		return 0, 0, status.Errorf(codes.Unavailable, "pre-create hook: %v", err)
+	}
+
+	if !decision.Allow {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "denied by policy"
+		}
+		s.logAudit(audit.TypeSandboxCreateDenied, map[string]any{
+			"sandbox_id": sandboxID,
+			"source_vm":  req.GetSourceVm(),
+			"vcpus":      vcpus,
+			"memory_mb":  memMB,
+		}, errors.New(reason), time.Since(start).Milliseconds())
+		return 0, 0, status.Errorf(codes.PermissionDenied, "sandbox create denied: %s", reason)
+	}
+
+	adjusted := decision.Apply(params)
+	if adjusted.VCPUs != vcpus || adjusted.MemoryMB != memMB {
+		s.logger.Info("pre-create hook adjusted resources",
+			"sandbox_id", sandboxID,
+			"vcpus", adjusted.VCPUs,
+			"memory_mb", adjusted.MemoryMB,
+			"reason", decision.Reason,
+		)
+	}
+	return adjusted.VCPUs, adjusted.MemoryMB, nil
+}
@@ -0,0 +1,109 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/hook"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+type fakePreCreateHook struct {
+	decision *hook.Decision
+	err      error
+	got      hook.CreateParams
+}
+
+func (f *fakePreCreateHook) PreCreate(_ context.Context, p hook.CreateParams) (*hook.Decision, error) {
+	f.got = p
+	return f.decision, f.err
+}
+
+func TestCreateSandbox_PreCreateHookDenies(t *testing.T) {
+	prov := &fakeCreateSandboxProvider{
+		createFn: func(context.Context, provider.CreateRequest) (*provider.SandboxResult, error) {
+			t.Fatal("provider should not be called when the hook denies")
+			return nil, nil
+		},
+	}
+	server := newTestCreateSandboxServer(t, prov, nil, &config.Config{})
+	server.preCreate = &fakePreCreateHook{decision: &hook.Decision{Allow: false, Reason: "only approved base images"}}
+
+	_, err := server.CreateSandbox(context.Background(), &deerv1.CreateSandboxCommand{
+		SandboxId: "sbx-denied",
+		BaseImage: "random-image",
+	})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("code = %v, want PermissionDenied (err=%v)", status.Code(err), err)
+	}
+	if !strings.Contains(err.Error(), "only approved base images") {
+		t.Errorf("error %q does not carry the hook reason", err)
+	}
+}
+
+func TestCreateSandbox_PreCreateHookCapsMemory(t *testing.T) {
+	var gotMem int
+	prov := &fakeCreateSandboxProvider{
+		createFn: func(_ context.Context, req provider.CreateRequest) (*provider.SandboxResult, error) {
+			gotMem = req.MemoryMB
+			return &provider.SandboxResult{SandboxID: req.SandboxID, State: "RUNNING"}, nil
+		},
+	}
+	server := newTestCreateSandboxServer(t, prov, nil, &config.Config{})
+	h := &fakePreCreateHook{decision: &hook.Decision{Allow: true, MemoryMB: 8192}}
+	server.preCreate = h
+
+	_, err := server.CreateSandbox(context.Background(), &deerv1.CreateSandboxCommand{
+		SandboxId: "sbx-capped",
+		BaseImage: "ubuntu-22.04",
+		Vcpus:     2,
+		MemoryMb:  16384,
+	})
+	if err != nil {
+		t.Fatalf("CreateSandbox: %v", err)
+	}
+	if h.got.MemoryMB != 16384 {
+		t.Errorf("hook saw memory %d, want 16384", h.got.MemoryMB)
+	}
+	if gotMem != 8192 {
+		t.Errorf("provider memory = %d, want 8192", gotMem)
+	}
+}
+
+func TestCreateSandbox_PreCreateHookFailure(t *testing.T) {
+	created := false
+	prov := &fakeCreateSandboxProvider{
+		createFn: func(_ context.Context, req provider.CreateRequest) (*provider.SandboxResult, error) {
+			created = true
+			return &provider.SandboxResult{SandboxID: req.SandboxID, State: "RUNNING"}, nil
+		},
+	}
+	req := &deerv1.CreateSandboxCommand{SandboxId: "sbx-hook-down", BaseImage: "ubuntu-22.04"}
+
+	server := newTestCreateSandboxServer(t, prov, nil, &config.Config{})
+	server.preCreate = &fakePreCreateHook{err: errors.New("connection refused")}
+	if _, err := server.CreateSandbox(context.Background(), req); status.Code(err) != codes.Unavailable {
+		t.Fatalf("code = %v, want Unavailable", status.Code(err))
+	}
+	if created {
+		t.Fatal("sandbox created despite hook failure")
+	}
+
+	cfg := &config.Config{}
+	cfg.Hooks.PreCreate.FailOpen = true
+	server = newTestCreateSandboxServer(t, prov, nil, cfg)
+	server.preCreate = &fakePreCreateHook{err: errors.New("connection refused")}
+	if _, err := server.CreateSandbox(context.Background(), req); err != nil {
+		t.Fatalf("CreateSandbox with fail_open: %v", err)
+	}
+	if !created {
+		t.Fatal("expected sandbox to be created with fail_open")
+	}
+}
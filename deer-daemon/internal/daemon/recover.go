@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+const (
+	// stateCreating marks a sandbox row written before the provider clone so
+	// a create interrupted by a daemon crash leaves a record to reconcile.
+	stateCreating = "CREATING"
+
+	// stateStarting is reported by providers that boot asynchronously.
+	stateStarting = "STARTING"
+)
+
+// reserveSandbox records a CREATING row for sandboxID before the provider is
+// called. A name taken by a concurrent create is rejected; other store
+// failures are logged and the create proceeds untracked until it finishes.
+func (s *Server) reserveSandbox(ctx context.Context, req *deerv1.CreateSandboxCommand, sandboxID, baseImage string, vcpus, memMB int) error {
+	if s.store == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	sb := &state.Sandbox{
+		ID:         sandboxID,
+		Name:       req.GetName(),
+		AgentID:    req.GetAgentId(),
+		BaseImage:  baseImage,
+		State:      stateCreating,
+		VCPUs:      vcpus,
+		MemoryMB:   memMB,
+		TTLSeconds: int(req.GetTtlSeconds()),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.store.CreateSandbox(ctx, sb); err != nil {
+		if errors.Is(err, state.ErrNameInUse) {
+			return status.Errorf(codes.AlreadyExists, "a sandbox named %q already exists", req.GetName())
+		}
+		s.logger.Warn("failed to record creating sandbox", "sandbox_id", sandboxID, "error", err)
+	}
+	return nil
+}
+
+// releaseSandbox drops the CREATING row of a create that failed in the provider.
+func (s *Server) releaseSandbox(ctx context.Context, sandboxID string) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.DeleteSandbox(ctx, sandboxID); err != nil {
+		s.logger.Warn("failed to drop creating sandbox record", "sandbox_id", sandboxID, "error", err)
+	}
+}
+
+// ReconcileInterruptedCreates resolves sandboxes left in CREATING or STARTING
+// by a daemon that died mid-create. Only rows created before cutoff are
+// considered so in-flight creates are left alone. A sandbox the provider can
+// still reach is promoted to RUNNING; anything else is destroyed and marked
+// ERROR so it shows up for the operator instead of lingering as a ghost.
+func ReconcileInterruptedCreates(ctx context.Context, st *state.Store, prov provider.SandboxProvider, logger *slog.Logger, cutoff time.Time) {
+	if st == nil || prov == nil {
+		return
+	}
+	sandboxes, err := st.ListSandboxes(ctx)
+	if err != nil {
+		logger.Warn("list sandboxes for create recovery failed", "error", err)
+		return
+	}
+	for _, sb := range sandboxes {
+		if sb.State != stateCreating && sb.State != stateStarting {
+			continue
+		}
+		if !sb.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if err := recoverInterruptedCreate(ctx, st, prov, sb); err != nil {
+			logger.Warn("recover interrupted create failed", "sandbox_id", sb.ID, "error", err)
+			continue
+		}
+		logger.Info("recovered interrupted create", "sandbox_id", sb.ID, "state", sb.State)
+	}
+}
+
+func recoverInterruptedCreate(ctx context.Context, st *state.Store, prov provider.SandboxProvider, sb *state.Sandbox) error {
+	ip, err := prov.GetSandboxIP(ctx, sb.ID)
+	if err == nil && ip != "" {
+		sb.State = "RUNNING"
+		sb.IPAddress = ip
+	} else {
+		if err := prov.DestroySandbox(ctx, sb.ID); err != nil && !errors.Is(err, provider.ErrSandboxNotFound) {
+			return fmt.Errorf("destroy half-created sandbox: %w", err)
+		}
+		sb.State = "ERROR"
+	}
+	sb.UpdatedAt = time.Now().UTC()
+	return st.UpdateSandbox(ctx, sb)
+}
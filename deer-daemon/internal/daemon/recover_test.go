@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+type fakeRecoverProvider struct {
+	fakeCreateSandboxProvider
+	ips map[string]string // sandbox ID -> IP of sandboxes still up
+}
+
+func (f *fakeRecoverProvider) GetSandboxIP(_ context.Context, sandboxID string) (string, error) {
+	if ip, ok := f.ips[sandboxID]; ok {
+		return ip, nil
+	}
+	return "", provider.ErrSandboxNotFound
+}
+
+func TestReconcileInterruptedCreates(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	old := time.Now().Add(-time.Hour).UTC()
+	for _, sb := range []*state.Sandbox{
+		{ID: "SBX-up", Name: "up", State: stateCreating, CreatedAt: old},
+		{ID: "SBX-gone", Name: "gone", State: stateStarting, CreatedAt: old},
+		{ID: "SBX-new", Name: "new", State: stateCreating, CreatedAt: time.Now().Add(time.Hour).UTC()},
+	} {
+		if err := s.store.CreateSandbox(ctx, sb); err != nil {
+			t.Fatalf("CreateSandbox: %v", err)
+		}
+	}
+
+	prov := &fakeRecoverProvider{ips: map[string]string{"SBX-up": "10.0.0.5"}}
+	ReconcileInterruptedCreates(ctx, s.store, prov, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Now())
+
+	want := map[string]string{"SBX-up": "RUNNING", "SBX-gone": "ERROR", "SBX-new": stateCreating, "SBX-1": "RUNNING"}
+	for id, wantState := range want {
+		sb, err := s.store.GetSandbox(ctx, id)
+		if err != nil {
+			t.Fatalf("GetSandbox(%s): %v", id, err)
+		}
+		if sb.State != wantState {
+			t.Errorf("%s state = %q, want %q", id, sb.State, wantState)
+		}
+	}
+	if sb, _ := s.store.GetSandbox(ctx, "SBX-up"); sb.IPAddress != "10.0.0.5" {
+		t.Errorf("SBX-up IP = %q, want 10.0.0.5", sb.IPAddress)
+	}
+	if len(prov.destroyed) != 1 || prov.destroyed[0] != "SBX-gone" {
+		t.Errorf("destroyed = %v, want [SBX-gone]", prov.destroyed)
+	}
+}
+
+func TestCreateSandbox_TracksCreatingRow(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	var seen string
+	s.prov = &fakeCreateSandboxProvider{
+		createFn: func(ctx context.Context, req provider.CreateRequest) (*provider.SandboxResult, error) {
+			if sb, err := s.store.GetSandbox(ctx, req.SandboxID); err == nil {
+				seen = sb.State
+			}
+			return nil, errors.New("clone failed")
+		},
+	}
+
+	_, err := s.CreateSandbox(ctx, &deerv1.CreateSandboxCommand{SandboxId: "SBX-fail", Name: "fail", BaseImage: "base"})
+	if err == nil {
+		t.Fatal("expected create error")
+	}
+	if seen != stateCreating {
+		t.Errorf("state during provider create = %q, want %q", seen, stateCreating)
+	}
+	if _, err := s.store.GetSandbox(ctx, "SBX-fail"); err == nil {
+		t.Error("failed create left a sandbox record behind")
+	}
+}
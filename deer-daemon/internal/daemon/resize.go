@@ -0,0 +1,135 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+)
+
+// ResizeSandbox changes a sandbox's vCPUs and memory. The provider applies
+// the new shape to a stopped sandbox, so a running one is stopped first and,
+// unless the request says otherwise, started again afterwards.
+func (s *Server) ResizeSandbox(ctx context.Context, req *deerv1.ResizeSandboxCommand) (*deerv1.SandboxInfo, error) {
+	start := time.Now()
+	s.telemetry.Track("daemon_sandbox_resized", nil)
+
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	vcpus, memMB := int(req.GetVcpus()), int(req.GetMemoryMb())
+	switch {
+	case vcpus == 0 && memMB == 0:
+		return nil, status.Error(codes.InvalidArgument, "vcpus or memory_mb is required")
+	case vcpus < 0:
+		return nil, status.Error(codes.InvalidArgument, "vcpus must be at least 1")
+	case memMB != 0 && memMB < provider.MinSandboxMemMB:
+		return nil, status.Errorf(codes.InvalidArgument, "memory_mb must be at least %d", provider.MinSandboxMemMB)
+	}
+	resizer, ok := s.prov.(provider.Resizer)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the configured provider cannot resize sandboxes")
+	}
+
+	release, err := s.lockSandbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	sb, err := s.store.GetSandbox(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+	if vcpus == 0 {
+		vcpus = sb.VCPUs
+	}
+	if memMB == 0 {
+		memMB = sb.MemoryMB
+	}
+	// A resize is held to the same policy as a create, and is checked
+	// before the sandbox is stopped so a denial leaves it running.
+	vcpus, memMB, err = s.applyPreCreateHook(ctx, &deerv1.CreateSandboxCommand{
+		Name:       sb.Name,
+		BaseImage:  sb.BaseImage,
+		AgentId:    sb.AgentID,
+		TtlSeconds: int32(sb.TTLSeconds),
+	}, id, vcpus, memMB)
+	if err != nil {
+		return nil, err
+	}
+	meta := map[string]any{
+		"sandbox_id":     id,
+		"from_vcpus":     sb.VCPUs,
+		"from_memory_mb": sb.MemoryMB,
+		"vcpus":          vcpus,
+		"memory_mb":      memMB,
+	}
+
+	wasRunning := sb.State == "RUNNING"
+	if wasRunning {
+		if err := s.prov.StopSandbox(ctx, id, false); err != nil {
+			return nil, status.Errorf(codes.Internal, "stop sandbox for resize: %v", err)
+		}
+		sb.State = "STOPPED"
+		sb.UpdatedAt = time.Now().UTC()
+		if err := s.store.UpdateSandbox(ctx, sb); err != nil {
+			s.logger.Warn("failed to update sandbox state", "sandbox_id", id, "error", err)
+		}
+	}
+
+	if err := resizer.SetSandboxResources(ctx, id, vcpus, memMB); err != nil {
+		s.logAudit(audit.TypeSandboxResized, meta, err, time.Since(start).Milliseconds())
+		if wasRunning {
+			s.restartSandbox(ctx, sb)
+		}
+		if errors.Is(err, provider.ErrSandboxNotFound) {
+			return nil, status.Errorf(codes.NotFound, "resize sandbox: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "resize sandbox: %v", err)
+	}
+	sb.VCPUs = vcpus
+	sb.MemoryMB = memMB
+	sb.UpdatedAt = time.Now().UTC()
+	if err := s.store.UpdateSandbox(ctx, sb); err != nil {
+		return nil, status.Errorf(codes.Internal, "persist resized sandbox: %v", err)
+	}
+
+	if wasRunning && !req.GetLeaveStopped() {
+		if err := s.restartSandbox(ctx, sb); err != nil {
+			s.logAudit(audit.TypeSandboxResized, meta, err, time.Since(start).Milliseconds())
+			return nil, status.Errorf(codes.Internal, "sandbox resized but failed to restart: %v", err)
+		}
+	}
+
+	meta["restarted"] = wasRunning && !req.GetLeaveStopped()
+	s.logAudit(audit.TypeSandboxResized, meta, nil, time.Since(start).Milliseconds())
+	return sandboxToInfo(sb, s.hostID), nil
+}
+
+// restartSandbox starts a sandbox that resize stopped and records its new
+// state. A failed resize calls it too, so the sandbox is not left stopped
+// with its old shape.
+func (s *Server) restartSandbox(ctx context.Context, sb *state.Sandbox) error {
+	result, err := s.prov.StartSandbox(ctx, sb.ID)
+	if err != nil {
+		s.logger.Warn("failed to restart sandbox after resize", "sandbox_id", sb.ID, "error", err)
+		return err
+	}
+	sb.State = result.State
+	sb.IPAddress = result.IPAddress
+	sb.UpdatedAt = time.Now().UTC()
+	if err := s.store.UpdateSandbox(ctx, sb); err != nil {
+		s.logger.Warn("failed to update sandbox state", "sandbox_id", sb.ID, "error", err)
+	}
+	return nil
+}
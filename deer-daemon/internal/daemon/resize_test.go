@@ -0,0 +1,145 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/hook"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+type fakeResizeProvider struct {
+	fakeCreateSandboxProvider
+	calls     []string
+	resized   [2]int
+	resizeErr error
+}
+
+func (f *fakeResizeProvider) StopSandbox(context.Context, string, bool) error {
+	f.calls = append(f.calls, "stop")
+	return nil
+}
+
+func (f *fakeResizeProvider) StartSandbox(_ context.Context, id string) (*provider.SandboxResult, error) {
+	f.calls = append(f.calls, "start")
+	return &provider.SandboxResult{SandboxID: id, State: "RUNNING", IPAddress: "10.0.0.9"}, nil
+}
+
+func (f *fakeResizeProvider) SetSandboxResources(_ context.Context, _ string, vcpus, memoryMB int) error {
+	f.calls = append(f.calls, "resize")
+	f.resized = [2]int{vcpus, memoryMB}
+	return f.resizeErr
+}
+
+func TestResizeSandbox_StopsResizesAndRestarts(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	sb, _ := s.store.GetSandbox(ctx, "SBX-1")
+	sb.VCPUs, sb.MemoryMB = 2, 2048
+	if err := s.store.UpdateSandbox(ctx, sb); err != nil {
+		t.Fatalf("UpdateSandbox: %v", err)
+	}
+	prov := &fakeResizeProvider{}
+	s.prov = prov
+
+	info, err := s.ResizeSandbox(ctx, &deerv1.ResizeSandboxCommand{SandboxId: "SBX-1", Vcpus: 4})
+	if err != nil {
+		t.Fatalf("ResizeSandbox: %v", err)
+	}
+	if got := prov.calls; len(got) != 3 || got[0] != "stop" || got[1] != "resize" || got[2] != "start" {
+		t.Errorf("provider calls = %v, want stop, resize, start", got)
+	}
+	if prov.resized != [2]int{4, 2048} {
+		t.Errorf("resized to %v, want 4 vCPUs and memory unchanged", prov.resized)
+	}
+	if info.GetVcpus() != 4 || info.GetMemoryMb() != 2048 || info.GetState() != "RUNNING" {
+		t.Errorf("info = %+v", info)
+	}
+	sb, _ = s.store.GetSandbox(ctx, "SBX-1")
+	if sb.VCPUs != 4 || sb.IPAddress != "10.0.0.9" {
+		t.Errorf("stored sandbox = %+v", sb)
+	}
+}
+
+func TestResizeSandbox_PreCreateHook(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeResizeProvider{}
+	s.prov = prov
+
+	s.preCreate = &fakePreCreateHook{decision: &hook.Decision{Allow: false, Reason: "too big"}}
+	_, err := s.ResizeSandbox(ctx, &deerv1.ResizeSandboxCommand{SandboxId: "SBX-1", MemoryMb: 65536})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("err = %v, want PermissionDenied", err)
+	}
+	if len(prov.calls) != 0 {
+		t.Errorf("provider calls = %v, want none after a denial", prov.calls)
+	}
+
+	s.preCreate = &fakePreCreateHook{decision: &hook.Decision{Allow: true, MemoryMB: 8192}}
+	if _, err := s.ResizeSandbox(ctx, &deerv1.ResizeSandboxCommand{SandboxId: "SBX-1", MemoryMb: 65536}); err != nil {
+		t.Fatalf("ResizeSandbox: %v", err)
+	}
+	if prov.resized[1] != 8192 {
+		t.Errorf("resized memory = %d, want the hook's 8192", prov.resized[1])
+	}
+}
+
+func TestResizeSandbox_RestartsAfterFailure(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeResizeProvider{resizeErr: errors.New("virsh setmem failed")}
+	s.prov = prov
+
+	if _, err := s.ResizeSandbox(ctx, &deerv1.ResizeSandboxCommand{SandboxId: "SBX-1", Vcpus: 8}); status.Code(err) != codes.Internal {
+		t.Fatalf("err = %v, want Internal", err)
+	}
+	if got := prov.calls; len(got) != 3 || got[2] != "start" {
+		t.Errorf("provider calls = %v, want stop, resize, start", got)
+	}
+	if sb, _ := s.store.GetSandbox(ctx, "SBX-1"); sb.State != "RUNNING" {
+		t.Errorf("state = %s, want RUNNING after the failed resize", sb.State)
+	}
+}
+
+func TestResizeSandbox_LeaveStopped(t *testing.T) {
+	s := newLockTestServer(t)
+	prov := &fakeResizeProvider{}
+	s.prov = prov
+
+	info, err := s.ResizeSandbox(context.Background(), &deerv1.ResizeSandboxCommand{SandboxId: "SBX-1", MemoryMb: 4096, LeaveStopped: true})
+	if err != nil {
+		t.Fatalf("ResizeSandbox: %v", err)
+	}
+	if len(prov.calls) != 2 || info.GetState() != "STOPPED" || info.GetMemoryMb() != 4096 {
+		t.Errorf("calls = %v, info = %+v; want stopped with 4096 MB", prov.calls, info)
+	}
+}
+
+func TestResizeSandbox_Validation(t *testing.T) {
+	s := newLockTestServer(t)
+	s.prov = &fakeResizeProvider{}
+
+	for name, req := range map[string]*deerv1.ResizeSandboxCommand{
+		"nothing to change": {SandboxId: "SBX-1"},
+		"negative vcpus":    {SandboxId: "SBX-1", Vcpus: -1},
+		"too little memory": {SandboxId: "SBX-1", MemoryMb: provider.MinSandboxMemMB - 1},
+	} {
+		if _, err := s.ResizeSandbox(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: code = %v, want InvalidArgument", name, status.Code(err))
+		}
+	}
+	if _, err := s.ResizeSandbox(context.Background(), &deerv1.ResizeSandboxCommand{SandboxId: "SBX-404", Vcpus: 2}); status.Code(err) != codes.NotFound {
+		t.Errorf("missing sandbox: code = %v, want NotFound", status.Code(err))
+	}
+
+	s.prov = &fakeCreateSandboxProvider{}
+	if _, err := s.ResizeSandbox(context.Background(), &deerv1.ResizeSandboxCommand{SandboxId: "SBX-1", Vcpus: 2}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("unsupported provider: code = %v, want Unimplemented", status.Code(err))
+	}
+}
@@ -3,12 +3,14 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -17,18 +19,23 @@ import (
 
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/hook"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/janitor"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/kafkastub"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/redact"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/snapshotpull"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/sourcevm"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/sshconfig"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/sshkeys"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/telemetry"
+	"github.com/aspectrr/deer.sh/shared/multierr"
 
 	genid "github.com/aspectrr/deer.sh/deer-daemon/internal/id"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 const createSandboxStreamTotalSteps = 9
@@ -52,6 +59,7 @@ type Server struct {
 	keyMgr                   sshkeys.KeyProvider
 	telemetry                telemetry.Service
 	redactor                 *redact.Redactor
+	outputScrubber           *redact.OutputScrubber
 	auditLog                 *audit.Logger
 	hostID                   string
 	version                  string
@@ -64,6 +72,20 @@ type Server struct {
 
 	vmHostMu    sync.RWMutex
 	vmHostCache map[string]*deerv1.SourceHostConnection // VM name -> host connection
+
+	startedAt    time.Time
+	janitor      *janitor.Janitor
+	preCreate    hook.PreCreate
+	errMu        sync.Mutex
+	recentErrors []recentError
+
+	diskMu    sync.Mutex
+	diskUsage map[string]diskMeasurement // sandbox ID -> last measurement
+
+	runningMu sync.Mutex
+	running   map[string]int // sandbox ID -> commands in flight
+
+	drain drainState
 }
 
 // NewServer creates a new DaemonService server.
@@ -90,7 +112,40 @@ func NewServer(cfg *config.Config, prov provider.SandboxProvider, store *state.S
 		logger:          logger.With("component", "daemon-service"),
 		kafkaMgr:        kafkaMgr,
 		vmHostCache:     make(map[string]*deerv1.SourceHostConnection),
+		startedAt:       time.Now().UTC(),
+		preCreate:       hook.NewPreCreate(cfg.Hooks.PreCreate),
+		outputScrubber:  newOutputScrubber(cfg.OutputRedaction, logger),
+	}
+}
+
+// newOutputScrubber builds the command output scrubber, or returns nil when
+// output redaction is disabled. Invalid extra patterns are logged and the
+// built-in rules are used alone rather than leaving output unscrubbed.
+func newOutputScrubber(cfg config.OutputRedactionConfig, logger *slog.Logger) *redact.OutputScrubber {
+	if !cfg.Enabled {
+		return nil
+	}
+	sc, err := redact.NewOutputScrubber(cfg.Patterns, cfg.EnvVars)
+	if err != nil {
+		logger.Warn("ignoring custom output redaction patterns", "error", err)
+		sc, _ = redact.NewOutputScrubber(nil, cfg.EnvVars)
 	}
+	return sc
+}
+
+// scrubResult redacts secrets from a command's stdout and stderr in place
+// and returns the names of the rules that matched.
+func (s *Server) scrubResult(result *provider.CommandResult) []string {
+	var stdoutFired, stderrFired []string
+	result.Stdout, stdoutFired = s.outputScrubber.Scrub(result.Stdout)
+	result.Stderr, stderrFired = s.outputScrubber.Scrub(result.Stderr)
+	fired := stdoutFired
+	for _, name := range stderrFired {
+		if !slices.Contains(fired, name) {
+			fired = append(fired, name)
+		}
+	}
+	return fired
 }
 
 func (s *Server) sendSandboxCreateProgress(stream deerv1.DaemonService_CreateSandboxStreamServer, sandboxID string, stepNum int, step string) error {
@@ -131,11 +186,55 @@ func (s *Server) persistCreatedSandbox(ctx context.Context, result *provider.San
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
+	// Replace the CREATING row written by reserveSandbox, keeping its creation time.
+	if existing, err := s.store.GetSandbox(ctx, result.SandboxID); err == nil {
+		sb.CreatedAt = existing.CreatedAt
+		if err := s.store.UpdateSandbox(ctx, sb); err != nil {
+			s.logger.Warn("failed to persist sandbox state", "sandbox_id", result.SandboxID, "error", err)
+		}
+		return
+	}
 	if err := s.store.CreateSandbox(ctx, sb); err != nil {
 		s.logger.Warn("failed to persist sandbox state", "sandbox_id", result.SandboxID, "error", err)
 	}
 }
 
+// resolveSandboxID returns the client-supplied sandbox ID or a fresh
+// collision-checked one. Requests reusing a live sandbox's ID or name are
+// rejected before anything is cloned.
+func (s *Server) resolveSandboxID(ctx context.Context, req *deerv1.CreateSandboxCommand) (string, error) {
+	if s.store == nil {
+		id, err := genid.Generate("sbx-")
+		if err != nil {
+			return "", status.Errorf(codes.Internal, "generate sandbox ID: %v", err)
+		}
+		return id, nil
+	}
+
+	if name := req.GetName(); name != "" {
+		inUse, err := s.store.SandboxNameInUse(ctx, name)
+		if err != nil {
+			return "", status.Errorf(codes.Internal, "check sandbox name: %v", err)
+		}
+		if inUse {
+			return "", status.Errorf(codes.AlreadyExists, "a sandbox named %q already exists", name)
+		}
+	}
+
+	if id := req.GetSandboxId(); id != "" {
+		if _, err := s.store.GetSandbox(ctx, id); err == nil {
+			return "", status.Errorf(codes.AlreadyExists, "sandbox %s already exists", id)
+		}
+		return id, nil
+	}
+
+	id, err := s.store.NewSandboxID(ctx, "sbx-")
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "generate sandbox ID: %v", err)
+	}
+	return id, nil
+}
+
 func (s *Server) providerCreateRequest(req *deerv1.CreateSandboxCommand, sandboxID, baseImage string, vcpus, memMB int) provider.CreateRequest {
 	createReq := provider.CreateRequest{
 		SandboxID:           sandboxID,
@@ -164,22 +263,19 @@ func (s *Server) providerCreateRequest(req *deerv1.CreateSandboxCommand, sandbox
 }
 
 func (s *Server) rollbackCreateFailure(ctx context.Context, sandboxID string) error {
-	var errs []string
+	var errs []error
 	s.removeKafkaStubs(ctx, sandboxID)
 	if s.prov != nil {
 		if err := s.prov.DestroySandbox(ctx, sandboxID); err != nil {
-			errs = append(errs, fmt.Sprintf("destroy sandbox: %v", err))
+			errs = append(errs, fmt.Errorf("destroy sandbox: %w", err))
 		}
 	}
 	if s.store != nil {
 		if err := s.store.DeleteSandbox(ctx, sandboxID); err != nil {
-			errs = append(errs, fmt.Sprintf("delete sandbox state: %v", err))
+			errs = append(errs, fmt.Errorf("delete sandbox state: %w", err))
 		}
 	}
-	if len(errs) == 0 {
-		return nil
-	}
-	return fmt.Errorf("%s", strings.Join(errs, "; "))
+	return multierr.Combine(errs...)
 }
 
 func (s *Server) attachKafkaDataSourcesForCreate(ctx context.Context, result *provider.SandboxResult, req *deerv1.CreateSandboxCommand) ([]*deerv1.SandboxKafkaStubInfo, error) {
@@ -199,17 +295,16 @@ func (s *Server) attachKafkaDataSourcesForCreate(ctx context.Context, result *pr
 }
 
 func (s *Server) CreateSandbox(ctx context.Context, req *deerv1.CreateSandboxCommand) (*deerv1.SandboxCreated, error) {
+	if err := s.rejectIfDraining(); err != nil {
+		return nil, err
+	}
 	start := time.Now()
 	s.telemetry.Track("daemon_sandbox_created", nil)
 	s.logger.Info("CreateSandbox", "base_image", req.GetBaseImage(), "source_vm", req.GetSourceVm(), "name", req.GetName())
 
-	sandboxID := req.GetSandboxId()
-	if sandboxID == "" {
-		var err error
-		sandboxID, err = genid.Generate("sbx-")
-		if err != nil {
-			return nil, status.Errorf(codes.Internal, "generate sandbox ID: %v", err)
-		}
+	sandboxID, err := s.resolveSandboxID(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
 	vcpus := int(req.GetVcpus())
@@ -220,6 +315,10 @@ func (s *Server) CreateSandbox(ctx context.Context, req *deerv1.CreateSandboxCom
 	if memMB == 0 {
 		memMB = 2048
 	}
+	vcpus, memMB, err = s.applyPreCreateHook(ctx, req, sandboxID, vcpus, memMB)
+	if err != nil {
+		return nil, err
+	}
 
 	// Resolve source host connection: use provided, or resolve from config
 	baseImage := req.GetBaseImage()
@@ -263,9 +362,13 @@ func (s *Server) CreateSandbox(ctx context.Context, req *deerv1.CreateSandboxCom
 	}
 
 	createReq := s.providerCreateRequest(req, sandboxID, baseImage, vcpus, memMB)
+	if err := s.reserveSandbox(ctx, req, sandboxID, baseImage, createReq.VCPUs, createReq.MemoryMB); err != nil {
+		return nil, err
+	}
 	result, err := s.prov.CreateSandbox(ctx, createReq)
 	if err != nil {
 		s.logger.Error("CreateSandbox failed", "error", err)
+		s.releaseSandbox(ctx, sandboxID)
 		return nil, status.Errorf(codes.Internal, "create sandbox: %v", err)
 	}
 
@@ -296,18 +399,17 @@ func (s *Server) CreateSandbox(ctx context.Context, req *deerv1.CreateSandboxCom
 }
 
 func (s *Server) CreateSandboxStream(req *deerv1.CreateSandboxCommand, stream deerv1.DaemonService_CreateSandboxStreamServer) error {
+	if err := s.rejectIfDraining(); err != nil {
+		return err
+	}
 	ctx := stream.Context()
 	start := time.Now()
 	s.telemetry.Track("daemon_sandbox_created_stream", nil)
 	s.logger.Info("CreateSandboxStream", "base_image", req.GetBaseImage(), "source_vm", req.GetSourceVm(), "name", req.GetName())
 
-	sandboxID := req.GetSandboxId()
-	if sandboxID == "" {
-		var err error
-		sandboxID, err = genid.Generate("sbx-")
-		if err != nil {
-			return status.Errorf(codes.Internal, "generate sandbox ID: %v", err)
-		}
+	sandboxID, err := s.resolveSandboxID(ctx, req)
+	if err != nil {
+		return err
 	}
 
 	vcpus := int(req.GetVcpus())
@@ -318,6 +420,11 @@ func (s *Server) CreateSandboxStream(req *deerv1.CreateSandboxCommand, stream de
 	if memMB == 0 {
 		memMB = 2048
 	}
+	vcpus, memMB, err = s.applyPreCreateHook(ctx, req, sandboxID, vcpus, memMB)
+	if err != nil {
+		s.sendSandboxCreateError(stream, sandboxID, err)
+		return err
+	}
 
 	// Resolve source host connection: use provided, or resolve from config
 	baseImage := req.GetBaseImage()
@@ -389,11 +496,16 @@ func (s *Server) CreateSandboxStream(req *deerv1.CreateSandboxCommand, stream de
 	if rp, ok := s.prov.(sandboxCreateProgressProvider); ok {
 		// Use streaming provider
 		createReq := s.providerCreateRequest(req, sandboxID, baseImage, vcpus, memMB)
+		if err := s.reserveSandbox(ctx, req, sandboxID, baseImage, createReq.VCPUs, createReq.MemoryMB); err != nil {
+			s.sendSandboxCreateError(stream, sandboxID, err)
+			return err
+		}
 		result, err := rp.CreateSandboxWithProgress(ctx, createReq, func(step string, stepNum, total int) {
 			_ = s.sendSandboxCreateProgress(stream, sandboxID, stepNum+2, step)
 		})
 		if err != nil {
 			s.logger.Error("CreateSandboxStream failed", "error", err)
+			s.releaseSandbox(ctx, sandboxID)
 			s.sendSandboxCreateError(stream, sandboxID, err)
 			return status.Errorf(codes.Internal, "create sandbox: %v", err)
 		}
@@ -435,9 +547,14 @@ func (s *Server) CreateSandboxStream(req *deerv1.CreateSandboxCommand, stream de
 		return err
 	}
 	createReq := s.providerCreateRequest(req, sandboxID, baseImage, vcpus, memMB)
+	if err := s.reserveSandbox(ctx, req, sandboxID, baseImage, createReq.VCPUs, createReq.MemoryMB); err != nil {
+		s.sendSandboxCreateError(stream, sandboxID, err)
+		return err
+	}
 	result, err := s.prov.CreateSandbox(ctx, createReq)
 	if err != nil {
 		s.logger.Error("CreateSandboxStream (unary fallback) failed", "error", err)
+		s.releaseSandbox(ctx, sandboxID)
 		s.sendSandboxCreateError(stream, sandboxID, err)
 		return status.Errorf(codes.Internal, "create sandbox: %v", err)
 	}
@@ -484,7 +601,41 @@ func (s *Server) GetSandbox(ctx context.Context, req *deerv1.GetSandboxRequest)
 		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
 	}
 
-	return sandboxToInfo(sb), nil
+	info := sandboxToInfo(sb, s.hostID)
+	s.attachActivity(ctx, []*deerv1.SandboxInfo{info}, id)
+	s.attachDisk(ctx, []*deerv1.SandboxInfo{info}, true)
+	s.attachIdleStop(ctx, info, sb)
+	return info, nil
+}
+
+// GetSandboxes looks up several sandboxes with one store query, returning
+// the ones that exist and the IDs that matched none.
+func (s *Server) GetSandboxes(ctx context.Context, req *deerv1.GetSandboxesRequest) (*deerv1.GetSandboxesResponse, error) {
+	ids := req.GetSandboxIds()
+	if len(ids) == 0 {
+		return &deerv1.GetSandboxesResponse{}, nil
+	}
+
+	sandboxes, notFound, err := s.store.GetSandboxes(ctx, ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get sandboxes: %v", err)
+	}
+
+	infos := make([]*deerv1.SandboxInfo, 0, len(sandboxes))
+	found := make([]string, 0, len(sandboxes))
+	for _, sb := range sandboxes {
+		infos = append(infos, sandboxToInfo(sb, s.hostID))
+		found = append(found, sb.ID)
+	}
+	if len(found) > 0 {
+		s.attachActivity(ctx, infos, found...)
+	}
+	s.attachDisk(ctx, infos, false)
+
+	return &deerv1.GetSandboxesResponse{
+		Sandboxes: infos,
+		NotFound:  notFound,
+	}, nil
 }
 
 func (s *Server) ListSandboxes(ctx context.Context, _ *deerv1.ListSandboxesRequest) (*deerv1.ListSandboxesResponse, error) {
@@ -495,8 +646,10 @@ func (s *Server) ListSandboxes(ctx context.Context, _ *deerv1.ListSandboxesReque
 
 	infos := make([]*deerv1.SandboxInfo, 0, len(sandboxes))
 	for _, sb := range sandboxes {
-		infos = append(infos, sandboxToInfo(sb))
+		infos = append(infos, sandboxToInfo(sb, s.hostID))
 	}
+	s.attachActivity(ctx, infos)
+	s.attachDisk(ctx, infos, false)
 
 	return &deerv1.ListSandboxesResponse{
 		Sandboxes: infos,
@@ -513,21 +666,90 @@ func (s *Server) DestroySandbox(ctx context.Context, req *deerv1.DestroySandboxC
 		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
 	}
 
-	if err := s.prov.DestroySandbox(ctx, id); err != nil {
-		s.logger.Error("DestroySandbox failed", "sandbox_id", id, "error", err)
-		return nil, status.Errorf(codes.Internal, "destroy sandbox: %v", err)
+	release, err := s.lockSandbox(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	if err := s.store.DeleteSandbox(ctx, id); err != nil {
-		s.logger.Warn("failed to delete sandbox from store", "sandbox_id", id, "error", err)
+	// Destroying an imported sandbox deletes the VM it was adopted from,
+	// which deer did not create, so the client has to say it means it.
+	if sb, err := s.store.GetSandbox(ctx, id); err == nil && sb.Imported && !req.GetConfirmImported() {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"sandbox %s was imported from existing VM %q; destroying it deletes that VM, so it needs confirm_imported", id, sb.BaseImage)
 	}
-	s.removeKafkaStubs(ctx, id)
 
+	resp, err := s.destroySandbox(ctx, id, req.GetArchive())
+	if err != nil {
+		return nil, err
+	}
 	s.logAudit(audit.TypeSandboxDestroyed, map[string]any{
-		"sandbox_id": id,
+		"sandbox_id":   id,
+		"archive_path": resp.ArchivePath,
 	}, nil, time.Since(start).Milliseconds())
 
-	return &deerv1.SandboxDestroyed{SandboxId: id}, nil
+	return resp, nil
+}
+
+// ExpireSandbox destroys a sandbox whose TTL has run out. It is the
+// janitor's destroy: it takes the same path as DestroySandbox, so
+// archive.on_destroy applies and keys are cleaned up, but locks the sandbox
+// as the janitor.
+func (s *Server) ExpireSandbox(ctx context.Context, id string) error {
+	start := time.Now()
+	if err := s.store.AcquireSandboxLock(ctx, id, "janitor", sandboxLockTTL); err != nil {
+		return err
+	}
+	defer func() { _ = s.store.ReleaseSandboxLock(context.Background(), id, "janitor") }()
+
+	resp, err := s.destroySandbox(ctx, id, false)
+	if err != nil {
+		return err
+	}
+	s.logAudit(audit.TypeSandboxDestroyed, map[string]any{
+		"sandbox_id":   id,
+		"archive_path": resp.ArchivePath,
+		"expired":      true,
+	}, nil, time.Since(start).Milliseconds())
+	return nil
+}
+
+// destroySandbox archives a locked sandbox if asked to or archive.on_destroy
+// is set, then destroys it and cleans up its state and keys.
+func (s *Server) destroySandbox(ctx context.Context, id string, archive bool) (*deerv1.SandboxDestroyed, error) {
+	// An archive that fails aborts the destroy: the archive is the safety
+	// net, so the sandbox is kept rather than lost.
+	var err error
+	resp := &deerv1.SandboxDestroyed{SandboxId: id}
+	if s.wantsArchive(archive) {
+		resp.ArchivePath, resp.ArchiveImage, err = s.archiveSandbox(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// A sandbox that is already gone from the provider (destroyed out-of-band
+	// or by an earlier attempt) still needs its state row and keys cleaned up,
+	// so destroy is safe to retry.
+	if err := s.prov.DestroySandbox(ctx, id); err != nil {
+		if !errors.Is(err, provider.ErrSandboxNotFound) {
+			s.logger.Error("DestroySandbox failed", "sandbox_id", id, "error", err)
+			return nil, status.Errorf(codes.Internal, "destroy sandbox: %v", err)
+		}
+		s.logger.Info("sandbox already gone from provider, cleaning up state", "sandbox_id", id, "error", err)
+	}
+
+	if err := s.store.DeleteSandbox(ctx, id); err != nil {
+		s.logger.Warn("failed to delete sandbox from store", "sandbox_id", id, "error", err)
+	}
+	s.forgetDisk(id)
+	if s.keyMgr != nil {
+		if err := s.keyMgr.CleanupSandbox(ctx, id); err != nil {
+			s.logger.Warn("failed to clean up sandbox SSH keys", "sandbox_id", id, "error", err)
+		}
+	}
+	s.removeKafkaStubs(ctx, id)
+	return resp, nil
 }
 
 func (s *Server) StartSandbox(ctx context.Context, req *deerv1.StartSandboxCommand) (*deerv1.SandboxStarted, error) {
@@ -539,6 +761,12 @@ func (s *Server) StartSandbox(ctx context.Context, req *deerv1.StartSandboxComma
 		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
 	}
 
+	release, err := s.lockSandbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	result, err := s.prov.StartSandbox(ctx, id)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "start sandbox: %v", err)
@@ -574,6 +802,12 @@ func (s *Server) StopSandbox(ctx context.Context, req *deerv1.StopSandboxCommand
 		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
 	}
 
+	release, err := s.lockSandbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	if err := s.prov.StopSandbox(ctx, id, req.GetForce()); err != nil {
 		return nil, status.Errorf(codes.Internal, "stop sandbox: %v", err)
 	}
@@ -601,12 +835,301 @@ func (s *Server) RunCommand(ctx context.Context, req *deerv1.RunCommandCommand)
 	start := time.Now()
 	s.telemetry.Track("daemon_command_executed", nil)
 
+	run, err := s.commandRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer s.trackCommand(req.GetSandboxId())()
+	result, err := provider.RunCommandWithOptions(ctx, s.prov, req.GetSandboxId(), run.command, run.timeout, run.opts)
+	if err != nil {
+		return nil, runCommandError(err)
+	}
+	return s.finishCommand(ctx, req, run, result, start), nil
+}
+
+// trackCommand counts a command as running in sandboxID until the returned
+// func is called, so the janitor does not stop the sandbox under it.
+func (s *Server) trackCommand(sandboxID string) (done func()) {
+	s.runningMu.Lock()
+	if s.running == nil {
+		s.running = make(map[string]int)
+	}
+	s.running[sandboxID]++
+	s.runningMu.Unlock()
+	return func() {
+		s.runningMu.Lock()
+		if s.running[sandboxID]--; s.running[sandboxID] <= 0 {
+			delete(s.running, sandboxID)
+		}
+		s.runningMu.Unlock()
+	}
+}
+
+// CommandRunning reports whether a command is in flight in sandboxID. A
+// long command records nothing until it ends, so the janitor asks this
+// before stopping a sandbox that looks idle.
+func (s *Server) CommandRunning(sandboxID string) bool {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	return s.running[sandboxID] > 0
+}
+
+// StreamCommand runs a command like RunCommand, sending output chunks as the
+// command produces them and the final result as the last message.
+func (s *Server) StreamCommand(req *deerv1.RunCommandCommand, stream deerv1.DaemonService_StreamCommandServer) error {
+	ctx := stream.Context()
+	start := time.Now()
+	s.telemetry.Track("daemon_command_streamed", nil)
+
+	run, err := s.commandRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer s.trackCommand(req.GetSandboxId())()
+
+	// Output may arrive from the stdout and stderr readers at once, and a
+	// stream must not be sent to concurrently.
+	var mu sync.Mutex
+	var sendErr error
+	// Chunks are scrubbed as they arrive; a secret split across two chunks
+	// is still scrubbed from the final result and the stored history.
+	onOutput := func(chunk []byte, isStderr bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if sendErr != nil {
+			return
+		}
+		if s.outputScrubber != nil {
+			scrubbed, _ := s.outputScrubber.Scrub(string(chunk))
+			chunk = []byte(scrubbed)
+		}
+		sendErr = stream.Send(&deerv1.CommandOutput{Chunk: chunk, IsStderr: isStderr})
+	}
+
+	result, err := provider.RunCommandStreaming(ctx, s.prov, req.GetSandboxId(), run.command, run.timeout, run.opts, onOutput)
+	if err != nil {
+		return runCommandError(err)
+	}
+	final := s.finishCommand(ctx, req, run, result, start)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Send(&deerv1.CommandOutput{Result: final})
+}
+
+// commandRun is a validated run request.
+type commandRun struct {
+	command string // as run, with the workdir and env applied
+	workdir string // recorded with the command so it can be replayed
+	timeout time.Duration
+	opts    provider.CommandOptions
+	batch   bool // run as part of RunCommandBatch, noted in the audit log
+}
+
+// commandRequest validates a run request and returns the command to run
+// (with workdir and env applied), its timeout, and the SSH options.
+func (s *Server) commandRequest(ctx context.Context, req *deerv1.RunCommandCommand) (commandRun, error) {
+	if req.GetSandboxId() == "" {
+		return commandRun{}, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if req.GetCommand() == "" {
+		return commandRun{}, status.Error(codes.InvalidArgument, "command is required")
+	}
+
+	timeout := time.Duration(req.GetTimeoutSeconds()) * time.Second
+	if req.GetTimeoutSeconds() > 3600 {
+		timeout = time.Hour
+	}
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+
+	opts := provider.CommandOptions{
+		ForwardAgent:  req.GetForwardAgent(),
+		IdentityFiles: req.GetIdentityFiles(),
+	}
+	if err := s.checkCommandOptions(opts); err != nil {
+		return commandRun{}, err
+	}
+
+	workdir, err := s.commandWorkdir(ctx, req.GetSandboxId(), req.GetWorkdir())
+	if err != nil {
+		return commandRun{}, err
+	}
+
+	// History and audit keep the command as given; env values may be secrets.
+	command, err := commandWithEnv(req.GetEnv(), commandInDir(workdir, req.GetCommand()))
+	if err != nil {
+		return commandRun{}, err
+	}
+	return commandRun{command: command, workdir: workdir, timeout: timeout, opts: opts}, nil
+}
+
+// isSSHTimeout reports whether err is ssh giving up on connecting to the
+// sandbox, as opposed to the command itself failing.
+func isSSHTimeout(err error) bool {
+	msg := err.Error()
+	return errors.Is(err, context.DeadlineExceeded) ||
+		strings.Contains(msg, "Connection timed out") ||
+		strings.Contains(msg, "Operation timed out")
+}
+
+// runCommandError maps a provider command error to a gRPC status.
+func runCommandError(err error) error {
+	if errors.Is(err, provider.ErrCommandOptionsUnsupported) {
+		return status.Errorf(codes.FailedPrecondition, "run command: %v", err)
+	}
+	if errors.Is(err, provider.ErrSandboxUnhealthy) {
+		return status.Errorf(codes.Unavailable, "run command: %v", err)
+	}
+	if isSSHTimeout(err) {
+		return status.Errorf(codes.DeadlineExceeded, "run command: %v", err)
+	}
+	return status.Errorf(codes.Internal, "run command: %v", err)
+}
+
+// finishCommand records and audits a completed command and converts its
+// result for the response. Unrecorded commands are audited only.
+func (s *Server) finishCommand(ctx context.Context, req *deerv1.RunCommandCommand, run commandRun, result *provider.CommandResult, start time.Time) *deerv1.CommandResult {
+	id := req.GetSandboxId()
+	var redacted []string
+	if !req.GetUnrecorded() {
+		redacted = s.scrubResult(result)
+		s.recordCommand(ctx, id, req.GetActor(), req.GetCommand(), run.workdir, result)
+	}
+	if result.ExitCode != 0 && strings.Contains(result.Stderr+result.Stdout, "No space left on device") {
+		// Remeasure so list output flags the full disk.
+		if _, err := s.probeDisk(ctx, id); err != nil {
+			s.logger.Debug("disk usage probe failed", "sandbox_id", id, "error", err)
+		}
+	}
+
+	meta := map[string]any{
+		"sandbox_id":    id,
+		"command":       req.GetCommand(),
+		"exit_code":     result.ExitCode,
+		"forward_agent": run.opts.ForwardAgent,
+		"actor":         req.GetActor(),
+	}
+	if req.GetUnrecorded() {
+		meta["unrecorded"] = true
+	}
+	if run.batch {
+		meta["batch"] = true
+	}
+	if len(redacted) > 0 {
+		meta["redacted"] = redacted
+	}
+	s.logAudit(audit.TypeCommandExecuted, meta, nil, time.Since(start).Milliseconds())
+
+	return &deerv1.CommandResult{
+		SandboxId:  id,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		ExitCode:   int32(result.ExitCode),
+		DurationMs: result.DurationMS,
+	}
+}
+
+// GetSandboxSSHTarget returns the address and daemon-host credentials for a
+// sandbox so a client can open an interactive SSH session through the daemon
+// host. Such sessions bypass RunCommand, so they are audited here instead of
+// being recorded in command history.
+func (s *Server) GetSandboxSSHTarget(ctx context.Context, req *deerv1.GetSandboxSSHTargetRequest) (*deerv1.SandboxSSHTarget, error) {
 	id := req.GetSandboxId()
 	if id == "" {
 		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
 	}
-	if req.GetCommand() == "" {
-		return nil, status.Error(codes.InvalidArgument, "command is required")
+	if _, err := s.store.GetSandbox(ctx, id); err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+	if s.keyMgr == nil {
+		return nil, status.Error(codes.FailedPrecondition, "SSH key manager not available")
+	}
+
+	ip, err := s.prov.GetSandboxIP(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "get sandbox IP: %v", err)
+	}
+	creds, err := s.keyMgr.GetCredentials(ctx, id, "sandbox")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get sandbox SSH credentials: %v", err)
+	}
+
+	var sshCfg config.SSHConfig
+	if s.cfg != nil {
+		sshCfg = s.cfg.SSH
+	}
+
+	s.logAudit(audit.TypeInteractiveSession, map[string]any{
+		"sandbox_id": id,
+		"ip_address": ip,
+	}, nil, 0)
+
+	return &deerv1.SandboxSSHTarget{
+		SandboxId:       id,
+		IpAddress:       ip,
+		Username:        creds.Username,
+		PrivateKeyPath:  creds.PrivateKeyPath,
+		CertificatePath: creds.CertificatePath,
+		HostKeyOptions:  sshkeys.HostKeyArgs(sshCfg.HostKeyMode, sshCfg.KnownHostsFile, creds),
+	}, nil
+}
+
+// RenewSandboxSSHCredentials reissues the SSH key and certificate the
+// daemon uses for a sandbox, so sessions that outlive the certificate TTL
+// can reconnect without waiting for it to expire.
+func (s *Server) RenewSandboxSSHCredentials(ctx context.Context, req *deerv1.RenewSandboxSSHCredentialsRequest) (*deerv1.SandboxSSHCredentials, error) {
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if _, err := s.store.GetSandbox(ctx, id); err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+	if s.keyMgr == nil {
+		return nil, status.Error(codes.FailedPrecondition, "SSH key manager not available")
+	}
+
+	creds, err := s.keyMgr.RenewCredentials(ctx, id, "sandbox")
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "renew sandbox SSH credentials: %v", err)
+	}
+
+	s.logAudit(audit.TypeSSHCredsRenewed, map[string]any{
+		"sandbox_id":  id,
+		"valid_until": creds.ValidUntil.UTC().Format(time.RFC3339),
+	}, nil, 0)
+
+	return &deerv1.SandboxSSHCredentials{
+		SandboxId:       id,
+		Username:        creds.Username,
+		PrivateKeyPath:  creds.PrivateKeyPath,
+		CertificatePath: creds.CertificatePath,
+		ValidUntil:      creds.ValidUntil.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// RunCommandBatch runs several commands in a sandbox in order. Each command
+// is recorded and audited as if run through RunCommand.
+func (s *Server) RunCommandBatch(ctx context.Context, req *deerv1.RunCommandBatchCommand) (*deerv1.RunCommandBatchResult, error) {
+	s.telemetry.Track("daemon_command_batch_executed", nil)
+
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if len(req.GetCommands()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "commands are required")
+	}
+	for i, c := range req.GetCommands() {
+		if c == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "command %d is empty", i)
+		}
 	}
 
 	timeout := time.Duration(req.GetTimeoutSeconds()) * time.Second
@@ -617,17 +1140,46 @@ func (s *Server) RunCommand(ctx context.Context, req *deerv1.RunCommandCommand)
 		timeout = 5 * time.Minute
 	}
 
-	result, err := s.prov.RunCommand(ctx, id, req.GetCommand(), timeout)
+	workdir, err := s.commandWorkdir(ctx, id, "")
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "run command: %v", err)
+		return nil, err
+	}
+	commands := make([]string, 0, len(req.GetCommands()))
+	for _, c := range req.GetCommands() {
+		commands = append(commands, commandInDir(workdir, c))
 	}
 
-	// Record command in state
+	done := s.trackCommand(id)
+	results, runErr := provider.RunCommandBatch(ctx, s.prov, id, commands, timeout, req.GetStopOnError())
+	done()
+	if runErr != nil && len(results) == 0 {
+		return nil, status.Errorf(codes.Internal, "run command batch: %v", runErr)
+	}
+
+	resp := &deerv1.RunCommandBatchResult{}
+	for i, result := range results {
+		cmd := &deerv1.RunCommandCommand{SandboxId: id, Command: req.GetCommands()[i], Actor: req.GetActor()}
+		run := commandRun{command: commands[i], workdir: workdir, timeout: timeout, batch: true}
+		start := time.Now().Add(-time.Duration(result.DurationMS) * time.Millisecond)
+		resp.Results = append(resp.Results, s.finishCommand(ctx, cmd, run, result, start))
+	}
+	// The commands that did run are kept, so the caller can tell how far
+	// the batch got.
+	if runErr != nil {
+		resp.Error = fmt.Sprintf("run command batch after %d of %d commands: %v", len(results), len(req.GetCommands()), runErr)
+	}
+	return resp, nil
+}
+
+// recordCommand stores a finished command in the sandbox's history.
+func (s *Server) recordCommand(ctx context.Context, sandboxID, actor, command, workdir string, result *provider.CommandResult) {
 	cmdID, _ := genid.GenerateRaw()
 	cmdRecord := &state.Command{
 		ID:         cmdID,
-		SandboxID:  id,
-		Command:    req.GetCommand(),
+		SandboxID:  sandboxID,
+		Command:    command,
+		Actor:      actor,
+		Workdir:    workdir,
 		Stdout:     result.Stdout,
 		Stderr:     result.Stderr,
 		ExitCode:   result.ExitCode,
@@ -636,20 +1188,60 @@ func (s *Server) RunCommand(ctx context.Context, req *deerv1.RunCommandCommand)
 		EndedAt:    time.Now().UTC(),
 	}
 	_ = s.store.CreateCommand(ctx, cmdRecord)
+}
 
-	s.logAudit(audit.TypeCommandExecuted, map[string]any{
-		"sandbox_id": id,
-		"command":    req.GetCommand(),
-		"exit_code":  result.ExitCode,
-	}, nil, time.Since(start).Milliseconds())
+// ListSandboxCommands returns the recorded command history of a sandbox,
+// oldest first. History is kept after the sandbox is destroyed.
+func (s *Server) ListSandboxCommands(ctx context.Context, req *deerv1.ListSandboxCommandsRequest) (*deerv1.ListSandboxCommandsResponse, error) {
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
 
-	return &deerv1.CommandResult{
-		SandboxId:  id,
-		Stdout:     result.Stdout,
-		Stderr:     result.Stderr,
-		ExitCode:   int32(result.ExitCode),
-		DurationMs: result.DurationMS,
-	}, nil
+	if after := req.GetAfterId(); after != "" {
+		cmds, err := s.store.ListSandboxCommandsAfter(ctx, id, after, req.GetActor(), int(req.GetLimit()))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "command %s not found in sandbox %s", after, id)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "list sandbox commands: %v", err)
+		}
+		return &deerv1.ListSandboxCommandsResponse{Commands: commandRecordsToProto(cmds)}, nil
+	}
+
+	// The store returns newest first.
+	cmds, err := s.store.ListSandboxCommands(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list sandbox commands: %v", err)
+	}
+	if actor := req.GetActor(); actor != "" {
+		cmds = slices.DeleteFunc(cmds, func(c *state.Command) bool { return c.Actor != actor })
+	}
+	if limit := int(req.GetLimit()); limit > 0 && len(cmds) > limit {
+		cmds = cmds[:limit]
+	}
+	slices.Reverse(cmds)
+	return &deerv1.ListSandboxCommandsResponse{Commands: commandRecordsToProto(cmds)}, nil
+}
+
+// commandRecordsToProto converts stored commands to history records.
+func commandRecordsToProto(cmds []*state.Command) []*deerv1.CommandRecord {
+	records := make([]*deerv1.CommandRecord, 0, len(cmds))
+	for _, c := range cmds {
+		records = append(records, &deerv1.CommandRecord{
+			Id:         c.ID,
+			Command:    c.Command,
+			Actor:      c.Actor,
+			Workdir:    c.Workdir,
+			Stdout:     c.Stdout,
+			Stderr:     c.Stderr,
+			ExitCode:   int32(c.ExitCode),
+			DurationMs: c.DurationMS,
+			StartedAt:  c.StartedAt.Format(time.RFC3339),
+			EndedAt:    c.EndedAt.Format(time.RFC3339),
+		})
+	}
+	return records
 }
 
 func (s *Server) CreateSnapshot(ctx context.Context, req *deerv1.SnapshotCommand) (*deerv1.SnapshotCreated, error) {
@@ -666,10 +1258,12 @@ func (s *Server) CreateSnapshot(ctx context.Context, req *deerv1.SnapshotCommand
 		name = fmt.Sprintf("snap-%d", time.Now().Unix())
 	}
 
+	contents := s.captureSnapshotContents(ctx, id, name)
 	result, err := s.prov.CreateSnapshot(ctx, id, name)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "create snapshot: %v", err)
 	}
+	s.recordSnapshot(ctx, id, result, contents)
 
 	s.logAudit(audit.TypeSnapshotCreated, map[string]any{
 		"sandbox_id":    id,
@@ -708,36 +1302,17 @@ func (s *Server) ListSourceVMs(ctx context.Context, req *deerv1.ListSourceVMsCom
 
 	// Query all configured source hosts
 	if len(s.cfg.SourceHosts) > 0 {
-		var allEntries []*deerv1.SourceVMListEntry
-		var lastErr error
-		for _, conn := range s.sourceHostConns() {
+		list, hostErr := s.listSourceHostVMs(ctx, s.sourceHostConns(), func(ctx context.Context, conn *deerv1.SourceHostConnection) ([]sourcevm.VMInfo, error) {
 			adhoc, err := s.adhocSourceVMManager(conn)
 			if err != nil {
-				lastErr = err
-				continue
-			}
-			vms, err := adhoc.ListVMs(ctx)
-			if err != nil {
-				lastErr = err
-				continue
+				return nil, err
 			}
-			s.vmHostMu.Lock()
-			for _, vm := range vms {
-				s.vmHostCache[vm.Name] = conn
-				allEntries = append(allEntries, &deerv1.SourceVMListEntry{
-					Name:      vm.Name,
-					State:     vm.State,
-					IpAddress: vm.IPAddress,
-					Prepared:  vm.Prepared,
-					Host:      conn.SshHost,
-				})
-			}
-			s.vmHostMu.Unlock()
-		}
-		if len(allEntries) == 0 && lastErr != nil {
-			return nil, status.Errorf(codes.Internal, "list source VMs: %v", lastErr)
+			return adhoc.ListVMs(ctx)
+		})
+		if len(list.GetVms()) == 0 && hostErr != nil && !list.GetCancelled() {
+			return nil, status.Errorf(codes.Internal, "list source VMs: %v", hostErr)
 		}
-		return &deerv1.SourceVMsList{Vms: allEntries}, nil
+		return list, nil
 	}
 
 	// Fall back to local provider
@@ -778,37 +1353,32 @@ func (s *Server) ValidateSourceVM(ctx context.Context, req *deerv1.ValidateSourc
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "create provider for host: %v", err)
 		}
-		result, err := adhoc.ValidateSourceVM(ctx, req.GetSourceVm())
+		adhocResult, err := adhoc.ValidateSourceVM(ctx, req.GetSourceVm())
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "validate source VM: %v", err)
 		}
-		return &deerv1.SourceVMValidation{
-			SourceVm:   result.VMName,
-			Valid:      result.Valid,
-			State:      result.State,
-			MacAddress: result.MACAddress,
-			IpAddress:  result.IPAddress,
-			HasNetwork: result.HasNetwork,
-			Warnings:   result.Warnings,
-			Errors:     result.Errors,
-		}, nil
+		result := provider.ValidationResult(*adhocResult)
+		s.checkHostMemory(ctx, &result)
+		return SourceVMValidationToProto(&result), nil
 	}
 
 	result, err := s.prov.ValidateSourceVM(ctx, req.GetSourceVm())
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "validate source VM: %v", err)
 	}
+	s.checkHostMemory(ctx, result)
+	return SourceVMValidationToProto(result), nil
+}
 
-	return &deerv1.SourceVMValidation{
-		SourceVm:   result.VMName,
-		Valid:      result.Valid,
-		State:      result.State,
-		MacAddress: result.MACAddress,
-		IpAddress:  result.IPAddress,
-		HasNetwork: result.HasNetwork,
-		Warnings:   result.Warnings,
-		Errors:     result.Errors,
-	}, nil
+// checkHostMemory warns in result when this host, where sandboxes from the
+// source VM are created, lacks the memory for a default-sized sandbox.
+func (s *Server) checkHostMemory(ctx context.Context, result *provider.ValidationResult) {
+	caps, err := s.prov.Capabilities(ctx)
+	if err != nil {
+		s.logger.Debug("read host capacity for validation", "error", err)
+		return
+	}
+	result.CheckHostMemory(caps, provider.DefaultSandboxMemMB)
 }
 
 func (s *Server) PrepareSourceVM(ctx context.Context, req *deerv1.PrepareSourceVMCommand) (*deerv1.SourceVMPrepared, error) {
@@ -830,7 +1400,7 @@ func (s *Server) PrepareSourceVM(ctx context.Context, req *deerv1.PrepareSourceV
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "create provider for host: %v", err)
 		}
-		result, err := adhoc.PrepareSourceVM(ctx, req.GetSourceVm(), req.GetSshUser(), req.GetSshKeyPath())
+		result, err := adhoc.PrepareSourceVM(ctx, req.GetSourceVm(), s.prepareUser(req.GetSshUser(), conn.GetSshHost()), req.GetSshKeyPath())
 		if err != nil {
 			return nil, status.Errorf(codes.Internal, "prepare source VM: %v", err)
 		}
@@ -847,7 +1417,7 @@ func (s *Server) PrepareSourceVM(ctx context.Context, req *deerv1.PrepareSourceV
 		}, nil
 	}
 
-	result, err := s.prov.PrepareSourceVM(ctx, req.GetSourceVm(), req.GetSshUser(), req.GetSshKeyPath())
+	result, err := s.prov.PrepareSourceVM(ctx, req.GetSourceVm(), s.prepareUser(req.GetSshUser(), ""), req.GetSshKeyPath())
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "prepare source VM: %v", err)
 	}
@@ -1160,6 +1730,7 @@ func (s *Server) ScanSourceHostKeys(ctx context.Context, _ *deerv1.ScanSourceHos
 
 // logAudit records an operation to the audit log with redaction.
 func (s *Server) logAudit(opType string, meta map[string]any, err error, durationMs int64) {
+	s.recordError(opType, err)
 	if s.auditLog == nil {
 		return
 	}
@@ -1169,17 +1740,61 @@ func (s *Server) logAudit(opType string, meta map[string]any, err error, duratio
 	s.auditLog.LogOperation(opType, meta, err, durationMs)
 }
 
-// sandboxToInfo converts a state.Sandbox to a proto SandboxInfo.
-func sandboxToInfo(sb *state.Sandbox) *deerv1.SandboxInfo {
+// attachActivity fills in the activity summary of each info from one
+// aggregate query over command history, limited to ids when given. A failed
+// query is logged and leaves the summaries unset.
+func (s *Server) attachActivity(ctx context.Context, infos []*deerv1.SandboxInfo, ids ...string) {
+	activity, err := s.store.ListSandboxActivity(ctx, ids...)
+	if err != nil {
+		s.logger.Warn("sandbox activity unavailable", "error", err)
+		return
+	}
+	for _, info := range infos {
+		a, ok := activity[info.GetSandboxId()]
+		if !ok {
+			continue
+		}
+		info.Activity = &deerv1.SandboxActivity{
+			CommandCount:   int32(a.CommandCount),
+			FailedCommands: int32(a.FailedCount),
+			TotalRuntimeMs: a.TotalRuntimeMS,
+		}
+		if !a.LastActivityAt.IsZero() {
+			info.Activity.LastActivityAt = a.LastActivityAt.Format(time.RFC3339)
+		}
+	}
+}
+
+// attachIdleStop sets when the janitor will stop sb if it stays idle. It is
+// left unset when idle stops are off or would not apply to sb, including
+// while a command is running in it.
+func (s *Server) attachIdleStop(ctx context.Context, info *deerv1.SandboxInfo, sb *state.Sandbox) {
+	if s.cfg == nil || s.cfg.Janitor.IdleTimeout <= 0 || sb.State != "RUNNING" || sb.Imported || s.CommandRunning(sb.ID) {
+		return
+	}
+	activity, err := s.store.ListSandboxActivity(ctx, sb.ID)
+	if err != nil {
+		s.logger.Warn("sandbox activity unavailable", "sandbox_id", sb.ID, "error", err)
+		return
+	}
+	info.IdleStopAt = sb.IdleSince(activity[sb.ID]).Add(s.cfg.Janitor.IdleTimeout).UTC().Format(time.RFC3339)
+}
+
+// sandboxToInfo converts a state.Sandbox on host hostID to a proto SandboxInfo.
+func sandboxToInfo(sb *state.Sandbox, hostID string) *deerv1.SandboxInfo {
 	return &deerv1.SandboxInfo{
-		SandboxId: sb.ID,
-		Name:      sb.Name,
-		State:     sb.State,
-		IpAddress: sb.IPAddress,
-		BaseImage: sb.BaseImage,
-		AgentId:   sb.AgentID,
-		Vcpus:     int32(sb.VCPUs),
-		MemoryMb:  int32(sb.MemoryMB),
-		CreatedAt: sb.CreatedAt.Format(time.RFC3339),
+		SandboxId:  sb.ID,
+		Name:       sb.Name,
+		State:      sb.State,
+		IpAddress:  sb.IPAddress,
+		MacAddress: sb.MACAddress,
+		HostId:     hostID,
+		BaseImage:  sb.BaseImage,
+		AgentId:    sb.AgentID,
+		Vcpus:      int32(sb.VCPUs),
+		MemoryMb:   int32(sb.MemoryMB),
+		CreatedAt:  sb.CreatedAt.Format(time.RFC3339),
+		Imported:   sb.Imported,
+		Workdir:    sb.Workdir,
 	}
 }
@@ -16,7 +16,9 @@ import (
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/telemetry"
 	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type fakeCreateSandboxProvider struct {
@@ -582,3 +584,26 @@ func TestCreateSandboxStream_ClampsKafkaBackedResources(t *testing.T) {
 		t.Fatalf("stored MemoryMB = %d, want %d", sb.MemoryMB, provider.KafkaBrokerMinMemoryMB)
 	}
 }
+
+func TestCreateSandbox_RejectsDuplicateNameAndID(t *testing.T) {
+	prov := &fakeCreateSandboxProvider{
+		createFn: func(context.Context, provider.CreateRequest) (*provider.SandboxResult, error) {
+			t.Fatal("provider should not be called for a duplicate sandbox")
+			return nil, nil
+		},
+	}
+	server := newTestCreateSandboxServer(t, prov, nil, &config.Config{})
+	if err := server.store.CreateSandbox(context.Background(), &state.Sandbox{ID: "sbx-existing", Name: "web", State: "RUNNING"}); err != nil {
+		t.Fatalf("CreateSandbox: %v", err)
+	}
+
+	_, err := server.CreateSandbox(context.Background(), &deerv1.CreateSandboxCommand{Name: "web", BaseImage: "ubuntu"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("duplicate name code = %v, want AlreadyExists (err=%v)", status.Code(err), err)
+	}
+
+	_, err = server.CreateSandbox(context.Background(), &deerv1.CreateSandboxCommand{SandboxId: "sbx-existing", BaseImage: "ubuntu"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("duplicate id code = %v, want AlreadyExists (err=%v)", status.Code(err), err)
+	}
+}
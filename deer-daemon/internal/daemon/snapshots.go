@@ -0,0 +1,395 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/audit"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/snapshotdiff"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+)
+
+// manifestTimeout bounds the filesystem walk behind a snapshot manifest.
+const manifestTimeout = 10 * time.Minute
+
+// diffIgnore returns the paths left out of snapshot manifests and diffs.
+func (s *Server) diffIgnore() []string {
+	if s.cfg != nil && len(s.cfg.Snapshot.DiffIgnore) > 0 {
+		return s.cfg.Snapshot.DiffIgnore
+	}
+	return snapshotdiff.DefaultIgnore
+}
+
+// captureManifest lists the sandbox's files over SSH. The walk is not
+// recorded in command history.
+func (s *Server) captureManifest(ctx context.Context, sandboxID string) (snapshotdiff.Manifest, error) {
+	result, err := provider.RunProbe(ctx, s.prov, sandboxID, snapshotdiff.Command(s.diffIgnore()), manifestTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("capture filesystem manifest: %w", err)
+	}
+	return snapshotdiff.ParseOutput(result.Stdout), nil
+}
+
+// capturePackages lists the sandbox's installed packages over SSH. Like the
+// manifest walk, it is not recorded in command history.
+func (s *Server) capturePackages(ctx context.Context, sandboxID string) (snapshotdiff.PackageList, error) {
+	result, err := provider.RunProbe(ctx, s.prov, sandboxID, snapshotdiff.PackagesCommand(), manifestTimeout)
+	if err != nil {
+		return snapshotdiff.PackageList{}, fmt.Errorf("list installed packages: %w", err)
+	}
+	return snapshotdiff.ParsePackages(result.Stdout), nil
+}
+
+// snapshotContents is what a snapshot records about the sandbox: when it
+// was taken and the encoded filesystem manifest and package list, either of
+// which is empty if it could not be captured.
+type snapshotContents struct {
+	takenAt  time.Time
+	manifest string
+	packages string
+}
+
+// captureSnapshotContents lists the sandbox's files and packages for a
+// snapshot about to be taken. CreateSnapshot calls it before the provider
+// snapshot and waits for it, so nothing written after the snapshot can end
+// up in its manifest. Failures are logged rather than returned; a snapshot
+// without a manifest just cannot be diffed.
+func (s *Server) captureSnapshotContents(ctx context.Context, sandboxID, name string) snapshotContents {
+	// The walk can take minutes, so it counts as a command in flight and
+	// the janitor does not stop the sandbox under it.
+	done := s.trackCommand(sandboxID)
+	defer done()
+
+	c := snapshotContents{takenAt: time.Now().UTC()}
+	if m, err := s.captureManifest(ctx, sandboxID); err != nil {
+		s.logger.Warn("snapshot manifest not captured", "sandbox_id", sandboxID, "snapshot", name, "error", err)
+	} else if c.manifest, err = m.Encode(); err != nil {
+		s.logger.Warn("snapshot manifest not stored", "sandbox_id", sandboxID, "snapshot", name, "error", err)
+	}
+	if pkgs, err := s.capturePackages(ctx, sandboxID); err != nil {
+		s.logger.Warn("snapshot package list not captured", "sandbox_id", sandboxID, "snapshot", name, "error", err)
+	} else if c.packages, err = pkgs.Encode(); err != nil {
+		s.logger.Warn("snapshot package list not stored", "sandbox_id", sandboxID, "snapshot", name, "error", err)
+	}
+	return c
+}
+
+// recordSnapshot stores a snapshot with the contents captured before it was
+// taken. The provider snapshot already exists, so a failure here is logged
+// rather than returned.
+func (s *Server) recordSnapshot(ctx context.Context, sandboxID string, result *provider.SnapshotResult, contents snapshotContents) {
+	snap := &state.Snapshot{
+		ID:        result.SnapshotID,
+		SandboxID: sandboxID,
+		Name:      result.SnapshotName,
+		Manifest:  contents.manifest,
+		Packages:  contents.packages,
+		CreatedAt: contents.takenAt,
+	}
+	if err := s.store.CreateSnapshot(ctx, snap); err != nil {
+		s.logger.Warn("record snapshot failed", "sandbox_id", sandboxID, "snapshot", result.SnapshotName, "error", err)
+	}
+}
+
+// loadManifest returns a stored snapshot and its decoded manifest.
+func (s *Server) loadManifest(ctx context.Context, sandboxID, snapshot string) (*state.Snapshot, snapshotdiff.Manifest, error) {
+	snap, err := s.store.GetSnapshot(ctx, sandboxID, snapshot)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.NotFound, "snapshot %s not found: %v", snapshot, err)
+	}
+	if snap.Manifest == "" {
+		return nil, nil, status.Errorf(codes.FailedPrecondition, "snapshot %s has no filesystem manifest", snapshot)
+	}
+	m, err := snapshotdiff.Decode(snap.Manifest)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "snapshot %s: %v", snapshot, err)
+	}
+	return snap, m, nil
+}
+
+// DiffSnapshots compares the files and packages recorded at from_snapshot
+// with to_snapshot, or with a fresh walk of the sandbox when to_snapshot is
+// empty, and lists the commands run in between. Packages are only compared
+// when both sides have a package list. Progress is streamed because the
+// walk can take minutes on a large filesystem.
+func (s *Server) DiffSnapshots(req *deerv1.DiffSnapshotsRequest, stream deerv1.DaemonService_DiffSnapshotsServer) error {
+	ctx := stream.Context()
+	id := req.GetSandboxId()
+	if id == "" {
+		return status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if req.GetFromSnapshot() == "" {
+		return status.Error(codes.InvalidArgument, "from_snapshot is required")
+	}
+	send := func(step string) error {
+		return stream.Send(&deerv1.DiffSnapshotsProgress{Step: step})
+	}
+
+	if err := send("Loading snapshot " + req.GetFromSnapshot()); err != nil {
+		return err
+	}
+	from, fromManifest, err := s.loadManifest(ctx, id, req.GetFromSnapshot())
+	if err != nil {
+		return err
+	}
+
+	fromPkgs, err := snapshotdiff.DecodePackages(from.Packages)
+	if err != nil {
+		return status.Errorf(codes.Internal, "snapshot %s: %v", req.GetFromSnapshot(), err)
+	}
+
+	var toManifest snapshotdiff.Manifest
+	var toPkgs snapshotdiff.PackageList
+	until := time.Now().UTC()
+	if to := req.GetToSnapshot(); to != "" {
+		if err := send("Loading snapshot " + to); err != nil {
+			return err
+		}
+		var toSnap *state.Snapshot
+		toSnap, toManifest, err = s.loadManifest(ctx, id, to)
+		if err != nil {
+			return err
+		}
+		until = toSnap.CreatedAt
+		if toPkgs, err = snapshotdiff.DecodePackages(toSnap.Packages); err != nil {
+			return status.Errorf(codes.Internal, "snapshot %s: %v", to, err)
+		}
+	} else {
+		if err := send("Walking the sandbox filesystem"); err != nil {
+			return err
+		}
+		toManifest, err = s.captureManifest(ctx, id)
+		if err != nil {
+			return status.Errorf(codes.Internal, "%v", err)
+		}
+		if fromPkgs.Manager != "" {
+			if err := send("Listing installed packages"); err != nil {
+				return err
+			}
+			if toPkgs, err = s.capturePackages(ctx, id); err != nil {
+				return status.Errorf(codes.Internal, "%v", err)
+			}
+		}
+	}
+
+	if err := send(fmt.Sprintf("Comparing %d and %d files", len(fromManifest), len(toManifest))); err != nil {
+		return err
+	}
+	result := snapshotdiff.Diff(fromManifest, toManifest, s.diffIgnore())
+	pkgsAdded, pkgsRemoved := snapshotdiff.DiffPackages(fromPkgs, toPkgs)
+
+	cmds, err := s.store.ListSandboxCommands(ctx, id)
+	if err != nil {
+		return status.Errorf(codes.Internal, "list sandbox commands: %v", err)
+	}
+	var ran []*deerv1.CommandRecord
+	// The store returns newest first.
+	for i := len(cmds) - 1; i >= 0; i-- {
+		c := cmds[i]
+		if c.StartedAt.Before(from.CreatedAt) || c.StartedAt.After(until) {
+			continue
+		}
+		ran = append(ran, &deerv1.CommandRecord{
+			Id:         c.ID,
+			Command:    c.Command,
+			Actor:      c.Actor,
+			ExitCode:   int32(c.ExitCode),
+			DurationMs: c.DurationMS,
+			StartedAt:  c.StartedAt.Format(time.RFC3339),
+			EndedAt:    c.EndedAt.Format(time.RFC3339),
+		})
+	}
+
+	return stream.Send(&deerv1.DiffSnapshotsProgress{
+		Step: "Done",
+		Diff: &deerv1.SnapshotDiff{
+			FilesAdded:      result.Added,
+			FilesModified:   result.Modified,
+			FilesRemoved:    result.Removed,
+			CommandsRun:     ran,
+			PackagesAdded:   snapshotPackagesToProto(pkgsAdded),
+			PackagesRemoved: snapshotPackagesToProto(pkgsRemoved),
+		},
+	})
+}
+
+// RestoreSnapshotFile returns one file as it was in a snapshot, or with
+// in_place writes it back into the running sandbox. The sandbox is locked
+// because the provider may mount the snapshot on the host while it works.
+func (s *Server) RestoreSnapshotFile(ctx context.Context, req *deerv1.RestoreSnapshotFileRequest) (*deerv1.SnapshotFileResult, error) {
+	start := time.Now()
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if req.GetSnapshot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "snapshot is required")
+	}
+	if !path.IsAbs(req.GetPath()) {
+		return nil, status.Errorf(codes.InvalidArgument, "path %q must be absolute", req.GetPath())
+	}
+	if _, err := s.store.GetSandbox(ctx, id); err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+	files, ok := s.prov.(provider.SnapshotFiles)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "this provider cannot read files from snapshots")
+	}
+
+	release, err := s.lockSandbox(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result := &deerv1.SnapshotFileResult{SandboxId: id, Snapshot: req.GetSnapshot(), Path: req.GetPath()}
+	meta := map[string]any{
+		"sandbox_id": id,
+		"snapshot":   req.GetSnapshot(),
+		"path":       req.GetPath(),
+	}
+	if req.GetInPlace() {
+		err := files.RestoreSnapshotFile(ctx, id, req.GetSnapshot(), req.GetPath())
+		s.logAudit(audit.TypeSnapshotFileRestore, meta, err, time.Since(start).Milliseconds())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "restore snapshot file: %v", err)
+		}
+		result.Restored = true
+		return result, nil
+	}
+
+	content, err := files.ReadSnapshotFile(ctx, id, req.GetSnapshot(), req.GetPath())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "read snapshot file: %v", err)
+	}
+	s.logAudit(audit.TypeFileRead, meta, nil, time.Since(start).Milliseconds())
+	result.Content = content
+	return result, nil
+}
+
+func snapshotPackagesToProto(pkgs []snapshotdiff.Package) []*deerv1.SnapshotPackage {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	out := make([]*deerv1.SnapshotPackage, 0, len(pkgs))
+	for _, p := range pkgs {
+		out = append(out, &deerv1.SnapshotPackage{Name: p.Name, Version: p.Version})
+	}
+	return out
+}
+
+// ListSnapshots returns a sandbox's recorded snapshots, oldest first. Archive
+// snapshots outlive their sandbox, so a destroyed sandbox still lists them.
+func (s *Server) ListSnapshots(ctx context.Context, req *deerv1.ListSnapshotsRequest) (*deerv1.ListSnapshotsResponse, error) {
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	snaps, err := s.store.ListSnapshots(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "list snapshots: %v", err)
+	}
+	resp := &deerv1.ListSnapshotsResponse{Snapshots: make([]*deerv1.SnapshotInfo, 0, len(snaps))}
+	for _, snap := range snaps {
+		resp.Snapshots = append(resp.Snapshots, &deerv1.SnapshotInfo{
+			Id:        snap.ID,
+			Name:      snap.Name,
+			Kind:      snap.Kind,
+			Ref:       snap.Ref,
+			CreatedAt: snap.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
+
+// DeleteSnapshot removes a snapshot from the backend (or its archive file)
+// and drops its record. A record whose backend snapshot is already gone, or
+// a backend snapshot the daemon never recorded, is still cleaned up; only a
+// snapshot missing from both is NotFound.
+func (s *Server) DeleteSnapshot(ctx context.Context, req *deerv1.DeleteSnapshotRequest) (*deerv1.SnapshotDeleted, error) {
+	start := time.Now()
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	if req.GetSnapshot() == "" {
+		return nil, status.Error(codes.InvalidArgument, "snapshot is required")
+	}
+
+	snap, err := s.store.GetSnapshot(ctx, id, req.GetSnapshot())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.Internal, "look up snapshot: %v", err)
+	}
+	name := req.GetSnapshot()
+	if snap != nil {
+		name = snap.Name
+	}
+
+	resp := &deerv1.SnapshotDeleted{SandboxId: id, Snapshot: name}
+	meta := map[string]any{"sandbox_id": id, "snapshot": name}
+	resp.BackendDeleted, err = s.deleteSnapshotBackend(ctx, id, name, snap)
+	if err != nil {
+		s.logAudit(audit.TypeSnapshotDeleted, meta, err, time.Since(start).Milliseconds())
+		return nil, status.Errorf(codes.Internal, "delete snapshot: %v", err)
+	}
+	if snap == nil && !resp.BackendDeleted {
+		return nil, status.Errorf(codes.NotFound, "snapshot %s not found", req.GetSnapshot())
+	}
+	if snap != nil {
+		if err := s.store.DeleteSnapshot(ctx, snap.ID); err != nil {
+			return nil, status.Errorf(codes.Internal, "delete snapshot record: %v", err)
+		}
+		resp.RecordDeleted = true
+	}
+
+	meta["record_deleted"] = resp.RecordDeleted
+	meta["backend_deleted"] = resp.BackendDeleted
+	s.logAudit(audit.TypeSnapshotDeleted, meta, nil, time.Since(start).Milliseconds())
+	return resp, nil
+}
+
+// deleteSnapshotBackend removes what a snapshot points at: the archive file
+// for an archive, otherwise the provider's snapshot when the provider keeps
+// any. It reports false, without an error, when there was nothing to remove.
+func (s *Server) deleteSnapshotBackend(ctx context.Context, sandboxID, name string, snap *state.Snapshot) (bool, error) {
+	if snap != nil && snap.Kind == state.SnapshotKindArchive {
+		if snap.Ref == "" {
+			return false, nil
+		}
+		if err := os.Remove(snap.Ref); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				s.logger.Warn("archive file already gone", "sandbox_id", sandboxID, "path", snap.Ref)
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	deleter, ok := s.prov.(provider.SnapshotDeleter)
+	if !ok {
+		return false, nil
+	}
+	err := deleter.DeleteSnapshot(ctx, sandboxID, name)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, provider.ErrSnapshotNotFound), errors.Is(err, provider.ErrSandboxNotFound):
+		s.logger.Warn("snapshot missing on backend", "sandbox_id", sandboxID, "snapshot", name, "error", err)
+		return false, nil
+	default:
+		return false, err
+	}
+}
@@ -0,0 +1,269 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// fakeSnapshotProvider snapshots instantly and answers every command with the
+// current manifest and package listing output.
+type fakeSnapshotProvider struct {
+	fakeCreateSandboxProvider
+	fs string
+}
+
+func (f *fakeSnapshotProvider) CreateSnapshot(_ context.Context, _ string, name string) (*provider.SnapshotResult, error) {
+	return &provider.SnapshotResult{SnapshotID: "SNP-" + name, SnapshotName: name}, nil
+}
+
+func (f *fakeSnapshotProvider) RunCommand(context.Context, string, string, time.Duration) (*provider.CommandResult, error) {
+	return &provider.CommandResult{Stdout: f.fs}, nil
+}
+
+type fakeDiffStream struct {
+	grpc.ServerStream
+	msgs []*deerv1.DiffSnapshotsProgress
+}
+
+func (f *fakeDiffStream) Send(msg *deerv1.DiffSnapshotsProgress) error {
+	f.msgs = append(f.msgs, msg)
+	return nil
+}
+
+func (f *fakeDiffStream) Context() context.Context { return context.Background() }
+
+func TestDiffSnapshots(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeSnapshotProvider{fs: "F\t3\t1.0\t/etc/a\nF\t3\t1.0\t/etc/b\n" +
+		"M\tdpkg\nP\tcurl\t8.5.0-1\nP\topenssl\t3.0.13-1\n"}
+	s.prov = prov
+
+	if _, err := s.CreateSnapshot(ctx, &deerv1.SnapshotCommand{SandboxId: "SBX-1", SnapshotName: "before"}); err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+	if err := s.store.CreateCommand(ctx, &state.Command{ID: "CMD-1", SandboxID: "SBX-1", Command: "vi /etc/a", StartedAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("CreateCommand: %v", err)
+	}
+	prov.fs = "F\t4\t2.0\t/etc/a\nF\t1\t2.0\t/etc/c\nF\t1\t2.0\t/var/log/syslog\n" +
+		"M\tdpkg\nP\tcurl\t8.5.0-1\nP\tnginx\t1.24.0-2\nP\topenssl\t3.0.13-2\n"
+
+	stream := &fakeDiffStream{}
+	if err := s.DiffSnapshots(&deerv1.DiffSnapshotsRequest{SandboxId: "SBX-1", FromSnapshot: "before"}, stream); err != nil {
+		t.Fatalf("DiffSnapshots: %v", err)
+	}
+	if len(stream.msgs) < 2 {
+		t.Fatalf("got %d messages, want progress then a result", len(stream.msgs))
+	}
+	diff := stream.msgs[len(stream.msgs)-1].GetDiff()
+	if diff == nil {
+		t.Fatal("last message has no diff")
+	}
+	got := strings.Join(diff.GetFilesAdded(), ",") + "|" + strings.Join(diff.GetFilesModified(), ",") + "|" + strings.Join(diff.GetFilesRemoved(), ",")
+	if got != "/etc/c|/etc/a|/etc/b" {
+		t.Errorf("added|modified|removed = %q, want /etc/c|/etc/a|/etc/b", got)
+	}
+	var added, removed []string
+	for _, p := range diff.GetPackagesAdded() {
+		added = append(added, p.GetName()+"="+p.GetVersion())
+	}
+	for _, p := range diff.GetPackagesRemoved() {
+		removed = append(removed, p.GetName()+"="+p.GetVersion())
+	}
+	if got := strings.Join(added, ",") + "|" + strings.Join(removed, ","); got != "nginx=1.24.0-2,openssl=3.0.13-2|openssl=3.0.13-1" {
+		t.Errorf("packages added|removed = %q", got)
+	}
+	if len(diff.GetCommandsRun()) != 1 || diff.GetCommandsRun()[0].GetCommand() != "vi /etc/a" {
+		t.Errorf("commands_run = %v, want the one command since the snapshot", diff.GetCommandsRun())
+	}
+}
+
+func TestDiffSnapshots_MissingSnapshot(t *testing.T) {
+	s := newLockTestServer(t)
+	err := s.DiffSnapshots(&deerv1.DiffSnapshotsRequest{SandboxId: "SBX-1", FromSnapshot: "nope"}, &fakeDiffStream{})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("err = %v, want NotFound", err)
+	}
+}
+
+type fakeSnapshotFilesProvider struct {
+	fakeCreateSandboxProvider
+	files    map[string]string // "snapshot:path" -> content
+	restored []string
+}
+
+func (f *fakeSnapshotFilesProvider) ReadSnapshotFile(_ context.Context, _, snapshot, path string) ([]byte, error) {
+	content, ok := f.files[snapshot+":"+path]
+	if !ok {
+		return nil, errors.New("no such file")
+	}
+	return []byte(content), nil
+}
+
+func (f *fakeSnapshotFilesProvider) RestoreSnapshotFile(_ context.Context, _, snapshot, path string) error {
+	f.restored = append(f.restored, snapshot+":"+path)
+	return nil
+}
+
+func TestRestoreSnapshotFile(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeSnapshotFilesProvider{files: map[string]string{"before:/etc/app.conf": "listen 80\n"}}
+	s.prov = prov
+
+	got, err := s.RestoreSnapshotFile(ctx, &deerv1.RestoreSnapshotFileRequest{SandboxId: "SBX-1", Snapshot: "before", Path: "/etc/app.conf"})
+	if err != nil {
+		t.Fatalf("RestoreSnapshotFile: %v", err)
+	}
+	if string(got.GetContent()) != "listen 80\n" || got.GetRestored() {
+		t.Errorf("result = %+v", got)
+	}
+
+	got, err = s.RestoreSnapshotFile(ctx, &deerv1.RestoreSnapshotFileRequest{SandboxId: "SBX-1", Snapshot: "before", Path: "/etc/app.conf", InPlace: true})
+	if err != nil {
+		t.Fatalf("RestoreSnapshotFile in place: %v", err)
+	}
+	if !got.GetRestored() || len(got.GetContent()) != 0 || len(prov.restored) != 1 {
+		t.Errorf("in-place result = %+v, restored = %v", got, prov.restored)
+	}
+
+	if _, err := s.RestoreSnapshotFile(ctx, &deerv1.RestoreSnapshotFileRequest{SandboxId: "SBX-1", Snapshot: "before", Path: "etc/app.conf"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("relative path err = %v, want InvalidArgument", err)
+	}
+
+	s.prov = &fakeCreateSandboxProvider{}
+	if _, err := s.RestoreSnapshotFile(ctx, &deerv1.RestoreSnapshotFileRequest{SandboxId: "SBX-1", Snapshot: "before", Path: "/etc/app.conf"}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("unsupported provider err = %v, want Unimplemented", err)
+	}
+}
+
+// fakeSnapshotDeleter keeps backend snapshots by name.
+type fakeSnapshotDeleter struct {
+	fakeCreateSandboxProvider
+	backend map[string]bool
+}
+
+func (f *fakeSnapshotDeleter) DeleteSnapshot(_ context.Context, _, name string) error {
+	if !f.backend[name] {
+		return fmt.Errorf("snapshot %q: %w", name, provider.ErrSnapshotNotFound)
+	}
+	delete(f.backend, name)
+	return nil
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeSnapshotDeleter{backend: map[string]bool{"both": true, "backend-only": true}}
+	s.prov = prov
+	for _, name := range []string{"both", "record-only"} {
+		if err := s.store.CreateSnapshot(ctx, &state.Snapshot{ID: "SNP-" + name, SandboxID: "SBX-1", Name: name, CreatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		snapshot        string
+		record, backend bool
+		wantCode        codes.Code
+	}{
+		{snapshot: "both", record: true, backend: true},
+		{snapshot: "record-only", record: true},
+		{snapshot: "backend-only", backend: true},
+		{snapshot: "nowhere", wantCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		got, err := s.DeleteSnapshot(ctx, &deerv1.DeleteSnapshotRequest{SandboxId: "SBX-1", Snapshot: tt.snapshot})
+		if status.Code(err) != tt.wantCode {
+			t.Errorf("%s: err = %v, want %v", tt.snapshot, err, tt.wantCode)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.GetRecordDeleted() != tt.record || got.GetBackendDeleted() != tt.backend {
+			t.Errorf("%s: record=%v backend=%v, want %v/%v", tt.snapshot, got.GetRecordDeleted(), got.GetBackendDeleted(), tt.record, tt.backend)
+		}
+	}
+
+	list, err := s.ListSnapshots(ctx, &deerv1.ListSnapshotsRequest{SandboxId: "SBX-1"})
+	if err != nil || len(list.GetSnapshots()) != 0 {
+		t.Errorf("snapshots left = %v, %v", list.GetSnapshots(), err)
+	}
+}
+
+func TestDeleteSnapshot_ArchiveRemovesFile(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	archive := filepath.Join(t.TempDir(), "archive-sbx-1.qcow2")
+	if err := os.WriteFile(archive, []byte("qcow"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.store.CreateSnapshot(ctx, &state.Snapshot{ID: "SNP-A", SandboxID: "SBX-1", Name: "archive-1", Kind: state.SnapshotKindArchive, Ref: archive, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.ListSnapshots(ctx, &deerv1.ListSnapshotsRequest{SandboxId: "SBX-1"})
+	if err != nil || len(list.GetSnapshots()) != 1 || list.GetSnapshots()[0].GetKind() != "archive" || list.GetSnapshots()[0].GetRef() != archive {
+		t.Fatalf("ListSnapshots = %v, %v", list.GetSnapshots(), err)
+	}
+
+	got, err := s.DeleteSnapshot(ctx, &deerv1.DeleteSnapshotRequest{SandboxId: "SBX-1", Snapshot: "SNP-A"})
+	if err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+	if !got.GetRecordDeleted() || !got.GetBackendDeleted() || got.GetSnapshot() != "archive-1" {
+		t.Errorf("result = %+v", got)
+	}
+	if _, err := os.Stat(archive); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("archive file still present: %v", err)
+	}
+}
+
+// laterWriteProvider adds a file to the sandbox as soon as the snapshot has
+// been taken, standing in for a command that runs right after it.
+type laterWriteProvider struct {
+	fakeSnapshotProvider
+}
+
+func (f *laterWriteProvider) CreateSnapshot(ctx context.Context, id, name string) (*provider.SnapshotResult, error) {
+	f.fs += "F\t3\t1.0\t/etc/later\n"
+	return f.fakeSnapshotProvider.CreateSnapshot(ctx, id, name)
+}
+
+func TestCreateSnapshot_ManifestExcludesLaterWrites(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &laterWriteProvider{fakeSnapshotProvider{fs: "F\t3\t1.0\t/etc/a\nM\tdpkg\nP\tcurl\t8.5.0-1\n"}}
+
+	if _, err := s.CreateSnapshot(ctx, &deerv1.SnapshotCommand{SandboxId: "SBX-1", SnapshotName: "before"}); err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+	snap, m, err := s.loadManifest(ctx, "SBX-1", "before")
+	if err != nil {
+		t.Fatalf("loadManifest: %v", err)
+	}
+	if _, ok := m["/etc/later"]; ok || len(m) != 1 {
+		t.Errorf("manifest = %v, want only /etc/a", m)
+	}
+	if snap.Packages == "" {
+		t.Error("expected the package list to be recorded with the snapshot")
+	}
+	if s.CommandRunning("SBX-1") {
+		t.Error("capture still counted as a command in flight after CreateSnapshot returned")
+	}
+}
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+)
+
+// GetSandboxStats samples what a sandbox is using right now: CPU, memory
+// and the space its disk takes on the host.
+func (s *Server) GetSandboxStats(ctx context.Context, req *deerv1.GetSandboxStatsRequest) (*deerv1.SandboxStats, error) {
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	reader, ok := s.prov.(provider.StatsReader)
+	if !ok {
+		return nil, status.Error(codes.Unimplemented, "the configured provider cannot report sandbox stats")
+	}
+
+	sb, err := s.store.GetSandbox(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+
+	stats, err := reader.SandboxStats(ctx, id)
+	if err != nil {
+		if errors.Is(err, provider.ErrSandboxNotFound) {
+			return nil, status.Errorf(codes.NotFound, "sandbox stats: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "sandbox stats: %v", err)
+	}
+	return &deerv1.SandboxStats{
+		SandboxId:          id,
+		State:              sb.State,
+		SampledAt:          time.Now().UTC().Format(time.RFC3339Nano),
+		Vcpus:              int32(sb.VCPUs),
+		CpuTimeMs:          stats.CPUTime.Milliseconds(),
+		CpuPercent:         stats.CPUPercent,
+		MemoryBytes:        stats.MemoryBytes,
+		MemoryLimitBytes:   stats.MemoryLimitBytes,
+		DiskAllocatedBytes: stats.DiskAllocatedBytes,
+		DiskCapacityBytes:  stats.DiskCapacityBytes,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+type fakeStatsProvider struct {
+	fakeCreateSandboxProvider
+	stats *provider.SandboxStats
+	err   error
+}
+
+func (f *fakeStatsProvider) SandboxStats(context.Context, string) (*provider.SandboxStats, error) {
+	return f.stats, f.err
+}
+
+func TestGetSandboxStats(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	sb, _ := s.store.GetSandbox(ctx, "SBX-1")
+	sb.VCPUs, sb.State = 2, "RUNNING"
+	if err := s.store.UpdateSandbox(ctx, sb); err != nil {
+		t.Fatalf("UpdateSandbox: %v", err)
+	}
+	s.prov = &fakeStatsProvider{stats: &provider.SandboxStats{
+		CPUTime:            90 * time.Second,
+		MemoryBytes:        700 << 20,
+		MemoryLimitBytes:   2048 << 20,
+		DiskAllocatedBytes: 1 << 30,
+	}}
+
+	got, err := s.GetSandboxStats(ctx, &deerv1.GetSandboxStatsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("GetSandboxStats: %v", err)
+	}
+	if got.GetCpuTimeMs() != 90000 || got.GetMemoryBytes() != 700<<20 || got.GetDiskAllocatedBytes() != 1<<30 {
+		t.Errorf("stats = %+v", got)
+	}
+	if got.GetVcpus() != 2 || got.GetState() != "RUNNING" || got.GetSampledAt() == "" {
+		t.Errorf("stats = %+v", got)
+	}
+
+	for _, tc := range []struct {
+		id   string
+		want codes.Code
+	}{
+		{"", codes.InvalidArgument},
+		{"SBX-missing", codes.NotFound},
+	} {
+		_, err := s.GetSandboxStats(ctx, &deerv1.GetSandboxStatsRequest{SandboxId: tc.id})
+		if status.Code(err) != tc.want {
+			t.Errorf("GetSandboxStats(%q) code = %v, want %v", tc.id, status.Code(err), tc.want)
+		}
+	}
+
+	s.prov = &fakeCreateSandboxProvider{}
+	_, err = s.GetSandboxStats(ctx, &deerv1.GetSandboxStatsRequest{SandboxId: "SBX-1"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("provider without stats code = %v, want Unimplemented", status.Code(err))
+	}
+}
@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/janitor"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+// maxRecentErrors bounds how many operation errors the server keeps for GetStatus.
+const maxRecentErrors = 20
+
+// recentError is an operation failure remembered for GetStatus.
+type recentError struct {
+	time    time.Time
+	source  string
+	message string
+}
+
+// SetJanitor attaches the janitor whose activity is reported by GetStatus.
+func (s *Server) SetJanitor(j *janitor.Janitor) {
+	s.janitor = j
+}
+
+// recordError remembers a failed operation so it can be surfaced by GetStatus.
+func (s *Server) recordError(source string, err error) {
+	if err == nil {
+		return
+	}
+	s.errMu.Lock()
+	defer s.errMu.Unlock()
+	s.recentErrors = append(s.recentErrors, recentError{
+		time:    time.Now().UTC(),
+		source:  source,
+		message: err.Error(),
+	})
+	if len(s.recentErrors) > maxRecentErrors {
+		s.recentErrors = s.recentErrors[len(s.recentErrors)-maxRecentErrors:]
+	}
+}
+
+func (s *Server) GetStatus(_ context.Context, _ *deerv1.GetStatusRequest) (*deerv1.DaemonStatusResponse, error) {
+	hostname, _ := os.Hostname()
+
+	resp := &deerv1.DaemonStatusResponse{
+		HostId:   s.hostID,
+		Hostname: hostname,
+		Version:  s.version,
+	}
+	if s.cfg != nil {
+		resp.Provider = s.cfg.Provider
+	}
+	if s.prov != nil {
+		resp.ActiveSandboxes = int32(s.prov.ActiveSandboxCount())
+	}
+	if !s.startedAt.IsZero() {
+		resp.StartedAt = s.startedAt.Format(time.RFC3339)
+		resp.UptimeSeconds = int64(time.Since(s.startedAt).Seconds())
+	}
+
+	var errs []recentError
+	s.errMu.Lock()
+	errs = append(errs, s.recentErrors...)
+	s.errMu.Unlock()
+
+	if s.janitor != nil {
+		js := s.janitor.Status()
+		if !js.LastRun.IsZero() {
+			resp.JanitorLastRun = js.LastRun.Format(time.RFC3339)
+		}
+		for _, e := range js.RecentErrors {
+			errs = append(errs, recentError{time: e.Time, source: "janitor", message: e.Message})
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].time.Before(errs[j].time) })
+	if len(errs) > maxRecentErrors {
+		errs = errs[len(errs)-maxRecentErrors:]
+	}
+	for _, e := range errs {
+		resp.RecentErrors = append(resp.RecentErrors, &deerv1.DaemonError{
+			Time:    e.time.Format(time.RFC3339),
+			Source:  e.source,
+			Message: e.message,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+func TestGetStatus(t *testing.T) {
+	s := &Server{
+		cfg:       &config.Config{Provider: "microvm"},
+		prov:      &fakeCreateSandboxProvider{},
+		hostID:    "host-1",
+		version:   "0.1.0",
+		startedAt: time.Now().UTC().Add(-90 * time.Second),
+	}
+	s.recordError("sandbox.destroy", errors.New("boom"))
+	s.recordError("sandbox.start", nil)
+
+	resp, err := s.GetStatus(context.Background(), &deerv1.GetStatusRequest{})
+	if err != nil {
+		t.Fatalf("GetStatus: %v", err)
+	}
+	if resp.GetHostId() != "host-1" || resp.GetVersion() != "0.1.0" || resp.GetProvider() != "microvm" {
+		t.Errorf("unexpected identity fields: %+v", resp)
+	}
+	if resp.GetUptimeSeconds() < 90 {
+		t.Errorf("uptime = %d, want >= 90", resp.GetUptimeSeconds())
+	}
+	if resp.GetJanitorLastRun() != "" {
+		t.Errorf("janitor_last_run = %q, want empty without a janitor", resp.GetJanitorLastRun())
+	}
+	if len(resp.GetRecentErrors()) != 1 {
+		t.Fatalf("got %d recent errors, want 1", len(resp.GetRecentErrors()))
+	}
+	if e := resp.GetRecentErrors()[0]; e.GetSource() != "sandbox.destroy" || e.GetMessage() != "boom" {
+		t.Errorf("unexpected recent error: %+v", e)
+	}
+}
+
+func TestRecordError_Bounded(t *testing.T) {
+	s := &Server{}
+	for i := 0; i < maxRecentErrors+5; i++ {
+		s.recordError("op", fmt.Errorf("err %d", i))
+	}
+	if len(s.recentErrors) != maxRecentErrors {
+		t.Fatalf("got %d errors, want %d", len(s.recentErrors), maxRecentErrors)
+	}
+	if got := s.recentErrors[0].message; got != "err 5" {
+		t.Errorf("oldest kept error = %q, want %q", got, "err 5")
+	}
+}
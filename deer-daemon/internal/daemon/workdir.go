@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"path"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/shellutil"
+)
+
+// workdirCheckTimeout bounds the command that checks a new default working
+// directory exists.
+const workdirCheckTimeout = 30 * time.Second
+
+// commandInDir prefixes command with a cd into dir that exits when dir is
+// missing, so no part of a compound command runs elsewhere. An empty dir
+// leaves command as it is.
+func commandInDir(dir, command string) string {
+	if dir == "" {
+		return command
+	}
+	return "cd " + shellutil.Quote(dir) + " || exit 1; " + command
+}
+
+// commandWorkdir returns the directory a command runs in: requested when
+// set, otherwise the sandbox's default. A sandbox the daemon has no record
+// of has no default.
+func (s *Server) commandWorkdir(ctx context.Context, sandboxID, requested string) (string, error) {
+	if requested != "" {
+		if !path.IsAbs(requested) {
+			return "", status.Errorf(codes.InvalidArgument, "workdir %q must be an absolute path", requested)
+		}
+		return path.Clean(requested), nil
+	}
+	sb, err := s.store.GetSandbox(ctx, sandboxID)
+	if err != nil {
+		return "", nil
+	}
+	return sb.Workdir, nil
+}
+
+// SetSandboxWorkdir sets the directory a sandbox's commands run in when they
+// do not name one. The directory must already exist in the sandbox; an
+// empty workdir clears the default.
+func (s *Server) SetSandboxWorkdir(ctx context.Context, req *deerv1.SetSandboxWorkdirRequest) (*deerv1.SandboxInfo, error) {
+	id := req.GetSandboxId()
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "sandbox_id is required")
+	}
+	workdir := req.GetWorkdir()
+	if workdir != "" && !path.IsAbs(workdir) {
+		return nil, status.Errorf(codes.InvalidArgument, "workdir %q must be an absolute path", workdir)
+	}
+	if workdir != "" {
+		workdir = path.Clean(workdir)
+	}
+
+	if _, err := s.store.GetSandbox(ctx, id); err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+
+	if workdir != "" {
+		result, err := s.prov.RunCommand(ctx, id, "test -d "+shellutil.Quote(workdir), workdirCheckTimeout)
+		if err != nil {
+			return nil, runCommandError(err)
+		}
+		if result.ExitCode != 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s is not a directory in sandbox %s", workdir, id)
+		}
+	}
+
+	// Only the workdir column is written: a lock, destroy or IP change that
+	// lands during the check above must not be overwritten.
+	if err := s.store.UpdateSandboxWorkdir(ctx, id, workdir); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.Errorf(codes.NotFound, "sandbox not found: %s", id)
+		}
+		return nil, status.Errorf(codes.Internal, "update sandbox: %v", err)
+	}
+	sb, err := s.store.GetSandbox(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "sandbox not found: %v", err)
+	}
+	return sandboxToInfo(sb, s.hostID), nil
+}
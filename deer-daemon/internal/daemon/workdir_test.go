@@ -0,0 +1,107 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	deerv1 "github.com/aspectrr/deer.sh/proto/gen/go/deer/v1"
+)
+
+func TestRunCommand_Workdir(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeCommandProvider{}
+	s.prov = prov
+
+	if _, err := s.RunCommand(ctx, &deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "make; make install", Workdir: "/src/it's"}); err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if want := `cd '/src/it'\''s' || exit 1; make; make install`; len(prov.ran) != 1 || prov.ran[0] != want {
+		t.Errorf("ran %q, want %q", prov.ran, want)
+	}
+	resp, err := s.ListSandboxCommands(ctx, &deerv1.ListSandboxCommandsRequest{SandboxId: "SBX-1"})
+	if err != nil {
+		t.Fatalf("ListSandboxCommands: %v", err)
+	}
+	if got := resp.GetCommands(); len(got) != 1 || got[0].GetCommand() != "make; make install" || got[0].GetWorkdir() != "/src/it's" {
+		t.Errorf("history = %v, want the command as given with its workdir", got)
+	}
+
+	_, err = s.RunCommand(ctx, &deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "make", Workdir: "src"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("relative workdir: err = %v, want InvalidArgument", err)
+	}
+}
+
+func TestSetSandboxWorkdir(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	prov := &fakeCommandProvider{codes: map[string]int{"test -d '/missing'": 1}}
+	s.prov = prov
+
+	info, err := s.SetSandboxWorkdir(ctx, &deerv1.SetSandboxWorkdirRequest{SandboxId: "SBX-1", Workdir: "/src/app/"})
+	if err != nil {
+		t.Fatalf("SetSandboxWorkdir: %v", err)
+	}
+	if info.GetWorkdir() != "/src/app" {
+		t.Errorf("workdir = %q, want /src/app", info.GetWorkdir())
+	}
+
+	prov.ran = nil
+	if _, err := s.RunCommand(ctx, &deerv1.RunCommandCommand{SandboxId: "SBX-1", Command: "make", Env: map[string]string{"A": "1"}}); err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if _, err := s.RunCommandBatch(ctx, &deerv1.RunCommandBatchCommand{SandboxId: "SBX-1", Commands: []string{"ls"}}); err != nil {
+		t.Fatalf("RunCommandBatch: %v", err)
+	}
+	want := []string{`export A='1'; cd '/src/app' || exit 1; make`, `cd '/src/app' || exit 1; ls`}
+	if len(prov.ran) != 2 || prov.ran[0] != want[0] || prov.ran[1] != want[1] {
+		t.Errorf("ran %q, want %q", prov.ran, want)
+	}
+
+	_, err = s.SetSandboxWorkdir(ctx, &deerv1.SetSandboxWorkdirRequest{SandboxId: "SBX-1", Workdir: "/missing"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("missing directory: err = %v, want FailedPrecondition", err)
+	}
+
+	info, err = s.SetSandboxWorkdir(ctx, &deerv1.SetSandboxWorkdirRequest{SandboxId: "SBX-1"})
+	if err != nil || info.GetWorkdir() != "" {
+		t.Errorf("clear: info = %+v, err = %v; want no workdir", info, err)
+	}
+}
+
+// lockDuringCheckProvider takes the sandbox's lock for another client while
+// the workdir check runs, as a concurrent start or destroy would.
+type lockDuringCheckProvider struct {
+	fakeCreateSandboxProvider
+	s *Server
+}
+
+func (f *lockDuringCheckProvider) RunCommand(ctx context.Context, id, _ string, _ time.Duration) (*provider.CommandResult, error) {
+	if err := f.s.store.AcquireSandboxLock(ctx, id, "alice", time.Minute); err != nil {
+		return nil, err
+	}
+	return &provider.CommandResult{}, nil
+}
+
+func TestSetSandboxWorkdir_KeepsConcurrentLock(t *testing.T) {
+	s := newLockTestServer(t)
+	ctx := context.Background()
+	s.prov = &lockDuringCheckProvider{s: s}
+
+	if _, err := s.SetSandboxWorkdir(ctx, &deerv1.SetSandboxWorkdirRequest{SandboxId: "SBX-1", Workdir: "/src"}); err != nil {
+		t.Fatalf("SetSandboxWorkdir: %v", err)
+	}
+	sb, err := s.store.GetSandbox(ctx, "SBX-1")
+	if err != nil {
+		t.Fatalf("GetSandbox: %v", err)
+	}
+	if sb.Workdir != "/src" || sb.LockedBy != "alice" {
+		t.Errorf("workdir = %q, locked_by = %q; want /src and alice", sb.Workdir, sb.LockedBy)
+	}
+}
@@ -0,0 +1,166 @@
+// Package hook implements operator-configured policy hooks that run before
+// sandbox lifecycle operations.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+)
+
+// defaultTimeout bounds a hook invocation when the config leaves it unset.
+const defaultTimeout = 10 * time.Second
+
+// CreateParams are the proposed sandbox parameters sent to a pre-create hook.
+type CreateParams struct {
+	SandboxID  string `json:"sandbox_id"`
+	Name       string `json:"name,omitempty"`
+	SourceVM   string `json:"source_vm,omitempty"`
+	BaseImage  string `json:"base_image,omitempty"`
+	VCPUs      int    `json:"vcpus"`
+	MemoryMB   int    `json:"memory_mb"`
+	TTLSeconds int    `json:"ttl_seconds,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+	Client     string `json:"client,omitempty"`
+	HostID     string `json:"host_id,omitempty"`
+}
+
+// Decision is a hook's verdict on a proposed create. VCPUs and MemoryMB, when
+// non-zero, replace the requested values (e.g. to cap memory).
+type Decision struct {
+	Allow    bool   `json:"allow"`
+	Reason   string `json:"reason,omitempty"`
+	VCPUs    int    `json:"vcpus,omitempty"`
+	MemoryMB int    `json:"memory_mb,omitempty"`
+}
+
+// Apply returns p with any resource overrides from the decision applied.
+func (d *Decision) Apply(p CreateParams) CreateParams {
+	if d == nil {
+		return p
+	}
+	if d.VCPUs > 0 {
+		p.VCPUs = d.VCPUs
+	}
+	if d.MemoryMB > 0 {
+		p.MemoryMB = d.MemoryMB
+	}
+	return p
+}
+
+// PreCreate is consulted before a sandbox is cloned.
+type PreCreate interface {
+	PreCreate(ctx context.Context, p CreateParams) (*Decision, error)
+}
+
+// NewPreCreate builds the hook described by cfg. It returns nil when neither
+// a URL nor a command is configured.
+func NewPreCreate(cfg config.PreCreateHookConfig) PreCreate {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	switch {
+	case cfg.URL != "":
+		return &httpHook{url: cfg.URL, client: &http.Client{Timeout: timeout}}
+	case cfg.Command != "":
+		return &commandHook{command: cfg.Command, timeout: timeout}
+	default:
+		return nil
+	}
+}
+
+// httpHook POSTs the params as JSON and expects a Decision in the response body.
+type httpHook struct {
+	url    string
+	client *http.Client
+}
+
+func (h *httpHook) PreCreate(ctx context.Context, p CreateParams) (*Decision, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("marshal hook request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build hook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call pre-create hook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("read hook response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("pre-create hook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return decodeDecision(data)
+}
+
+// commandHook runs a local command via sh -c with the params as JSON on stdin.
+// A zero exit with empty stdout allows the create; a non-zero exit denies it
+// with stderr as the reason.
+type commandHook struct {
+	command string
+	timeout time.Duration
+}
+
+func (h *commandHook) PreCreate(ctx context.Context, p CreateParams) (*Decision, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("marshal hook request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.command)
+	cmd.Stdin = bytes.NewReader(body)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait on grandchildren that inherited stdout after a timeout kill.
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("pre-create hook timed out after %s", h.timeout)
+		}
+		if _, ok := err.(*exec.ExitError); ok {
+			reason := strings.TrimSpace(stderr.String())
+			if reason == "" {
+				reason = err.Error()
+			}
+			return &Decision{Allow: false, Reason: reason}, nil
+		}
+		return nil, fmt.Errorf("run pre-create hook: %w", err)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		return &Decision{Allow: true}, nil
+	}
+	return decodeDecision(out)
+}
+
+func decodeDecision(data []byte) (*Decision, error) {
+	var d Decision
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parse hook decision: %w", err)
+	}
+	return &d, nil
+}
@@ -0,0 +1,90 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/config"
+)
+
+func TestNewPreCreate_Unconfigured(t *testing.T) {
+	if h := NewPreCreate(config.PreCreateHookConfig{}); h != nil {
+		t.Fatalf("expected nil hook, got %T", h)
+	}
+}
+
+func TestHTTPHook_CapsMemory(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p CreateParams
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if p.SourceVM != "ubuntu-base" || p.MemoryMB != 16384 {
+			t.Errorf("unexpected params: %+v", p)
+		}
+		_ = json.NewEncoder(w).Encode(Decision{Allow: true, MemoryMB: 8192, Reason: "capped at 8GB"})
+	}))
+	defer srv.Close()
+
+	h := NewPreCreate(config.PreCreateHookConfig{URL: srv.URL})
+	p := CreateParams{SandboxID: "sbx-1", SourceVM: "ubuntu-base", VCPUs: 2, MemoryMB: 16384}
+	d, err := h.PreCreate(context.Background(), p)
+	if err != nil {
+		t.Fatalf("PreCreate: %v", err)
+	}
+	if !d.Allow {
+		t.Fatal("expected allow")
+	}
+	got := d.Apply(p)
+	if got.MemoryMB != 8192 || got.VCPUs != 2 {
+		t.Errorf("Apply = %+v, want memory 8192 and vcpus 2", got)
+	}
+}
+
+func TestHTTPHook_ServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "policy engine down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	h := NewPreCreate(config.PreCreateHookConfig{URL: srv.URL})
+	if _, err := h.PreCreate(context.Background(), CreateParams{}); err == nil {
+		t.Fatal("expected error for non-2xx response")
+	}
+}
+
+func TestCommandHook(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		wantAllow  bool
+		wantReason string
+	}{
+		{name: "empty output allows", command: "cat >/dev/null", wantAllow: true},
+		{name: "json decision", command: `cat >/dev/null; echo '{"allow":false,"reason":"unapproved image"}'`, wantAllow: false, wantReason: "unapproved image"},
+		{name: "non-zero exit denies", command: "echo 'over memory limit' >&2; exit 1", wantAllow: false, wantReason: "over memory limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewPreCreate(config.PreCreateHookConfig{Command: tt.command, Timeout: 5 * time.Second})
+			d, err := h.PreCreate(context.Background(), CreateParams{SandboxID: "sbx-1"})
+			if err != nil {
+				t.Fatalf("PreCreate: %v", err)
+			}
+			if d.Allow != tt.wantAllow || d.Reason != tt.wantReason {
+				t.Errorf("decision = %+v, want allow=%v reason=%q", d, tt.wantAllow, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestCommandHook_Timeout(t *testing.T) {
+	h := NewPreCreate(config.PreCreateHookConfig{Command: "sleep 5", Timeout: 50 * time.Millisecond})
+	if _, err := h.PreCreate(context.Background(), CreateParams{}); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
@@ -1,23 +1,59 @@
-// Package janitor provides background cleanup of expired sandboxes on the host.
+// Package janitor provides background cleanup of expired sandboxes on the
+// host, and optionally stops sandboxes that have sat idle.
 package janitor
 
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/metrics"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
 )
 
 // DestroyFunc is called to destroy an expired sandbox.
 type DestroyFunc func(ctx context.Context, sandboxID string) error
 
+// StopFunc is called to stop an idle sandbox.
+type StopFunc func(ctx context.Context, sandboxID string) error
+
+// BusyFunc reports whether a sandbox has a command in flight. The command
+// history only shows finished commands, so without it a long command looks
+// idle.
+type BusyFunc func(sandboxID string) bool
+
+// maxRecentErrors bounds how many cleanup errors Status reports.
+const maxRecentErrors = 10
+
+// Error is a cleanup failure recorded by the janitor.
+type Error struct {
+	Time    time.Time
+	Message string
+}
+
+// Status is a snapshot of the janitor's most recent activity.
+type Status struct {
+	LastRun      time.Time // zero until the first cleanup pass completes
+	RecentErrors []Error   // oldest first
+}
+
 // Janitor periodically cleans up expired sandboxes.
 type Janitor struct {
 	store      *state.Store
 	destroyFn  DestroyFunc
 	logger     *slog.Logger
 	defaultTTL time.Duration
+
+	stopFn      StopFunc
+	idleTimeout time.Duration
+
+	metrics *metrics.Metrics // nil unless metrics are enabled
+
+	mu           sync.Mutex
+	busyFn       BusyFunc
+	lastRun      time.Time
+	recentErrors []Error
 }
 
 // New creates a new Janitor service.
@@ -33,11 +69,41 @@ func New(st *state.Store, destroyFn DestroyFunc, defaultTTL time.Duration, logge
 	}
 }
 
+// SetIdleStop makes each pass also stop running sandboxes that have been
+// idle for at least timeout, keeping their disks for a later start. A zero
+// timeout disables idle stops. Call before Start.
+func (j *Janitor) SetIdleStop(stop StopFunc, timeout time.Duration) {
+	j.stopFn = stop
+	j.idleTimeout = timeout
+}
+
+// SetDestroy replaces the function that destroys expired sandboxes, for a
+// destroy path that is only available after New. Call before Start.
+func (j *Janitor) SetDestroy(destroy DestroyFunc) {
+	j.destroyFn = destroy
+}
+
+// SetBusy makes idle stops skip sandboxes for which busy reports a command
+// in flight. It may be called at any time, including while the janitor
+// runs.
+func (j *Janitor) SetBusy(busy BusyFunc) {
+	j.mu.Lock()
+	j.busyFn = busy
+	j.mu.Unlock()
+}
+
+// SetMetrics makes the janitor count the sandboxes it destroys and stops
+// in m. Call before Start.
+func (j *Janitor) SetMetrics(m *metrics.Metrics) {
+	j.metrics = m
+}
+
 // Start runs the cleanup loop. It blocks until the context is cancelled.
 func (j *Janitor) Start(ctx context.Context, interval time.Duration) {
 	j.logger.Info("starting janitor",
 		"interval", interval,
 		"default_ttl", j.defaultTTL,
+		"idle_timeout", j.idleTimeout,
 	)
 
 	// Run once immediately
@@ -57,11 +123,44 @@ func (j *Janitor) Start(ctx context.Context, interval time.Duration) {
 	}
 }
 
-// cleanup finds and destroys all expired sandboxes.
+// Status returns the time of the last completed cleanup pass and any
+// recent cleanup errors.
+func (j *Janitor) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	errs := make([]Error, len(j.recentErrors))
+	copy(errs, j.recentErrors)
+	return Status{LastRun: j.lastRun, RecentErrors: errs}
+}
+
+// recordError appends an error to the bounded recent-errors list.
+func (j *Janitor) recordError(msg string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.recentErrors = append(j.recentErrors, Error{Time: time.Now().UTC(), Message: msg})
+	if len(j.recentErrors) > maxRecentErrors {
+		j.recentErrors = j.recentErrors[len(j.recentErrors)-maxRecentErrors:]
+	}
+}
+
+// cleanup destroys expired sandboxes and then stops idle ones.
 func (j *Janitor) cleanup(ctx context.Context) {
+	defer func() {
+		j.mu.Lock()
+		j.lastRun = time.Now().UTC()
+		j.mu.Unlock()
+	}()
+
+	j.destroyExpired(ctx)
+	j.stopIdle(ctx)
+}
+
+// destroyExpired finds and destroys all expired sandboxes.
+func (j *Janitor) destroyExpired(ctx context.Context) {
 	expired, err := j.store.ListExpiredSandboxes(ctx, j.defaultTTL)
 	if err != nil {
 		j.logger.Error("failed to list expired sandboxes", "error", err)
+		j.recordError("list expired sandboxes: " + err.Error())
 		return
 	}
 
@@ -85,8 +184,40 @@ func (j *Janitor) cleanup(ctx context.Context) {
 				"id", sb.ID,
 				"error", err,
 			)
+			j.recordError("destroy expired sandbox " + sb.ID + ": " + err.Error())
 		} else {
 			j.logger.Info("destroyed expired sandbox", "id", sb.ID)
+			j.metrics.JanitorReaped("destroy")
+		}
+	}
+}
+
+// stopIdle stops running sandboxes idle for longer than the idle timeout.
+func (j *Janitor) stopIdle(ctx context.Context) {
+	if j.stopFn == nil || j.idleTimeout <= 0 {
+		return
+	}
+	idle, err := j.store.ListIdleSandboxes(ctx, j.idleTimeout, time.Now().UTC())
+	if err != nil {
+		j.logger.Error("failed to list idle sandboxes", "error", err)
+		j.recordError("list idle sandboxes: " + err.Error())
+		return
+	}
+	j.mu.Lock()
+	busy := j.busyFn
+	j.mu.Unlock()
+	for _, sb := range idle {
+		if busy != nil && busy(sb.ID) {
+			j.logger.Debug("skipping idle stop, command in flight", "id", sb.ID)
+			continue
+		}
+		j.logger.Info("stopping idle sandbox", "id", sb.ID, "name", sb.Name, "idle_timeout", j.idleTimeout)
+		if err := j.stopFn(ctx, sb.ID); err != nil {
+			j.logger.Error("failed to stop idle sandbox", "id", sb.ID, "error", err)
+			j.recordError("stop idle sandbox " + sb.ID + ": " + err.Error())
+		} else {
+			j.logger.Info("stopped idle sandbox", "id", sb.ID)
+			j.metrics.JanitorReaped("stop")
 		}
 	}
 }
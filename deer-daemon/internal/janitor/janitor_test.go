@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/metrics"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/state"
 )
 
@@ -162,3 +164,123 @@ func TestJanitor_DestroyError(t *testing.T) {
 		t.Errorf("expected destroyFn to be called for both sandboxes, got calls: %v", calls)
 	}
 }
+
+func TestJanitor_StatusRecordsLastRunAndErrors(t *testing.T) {
+	st := newTestStore(t)
+	insertExpiredSandbox(t, st, "SBX-fail", 1, time.Now().UTC().Add(-11*time.Second))
+
+	destroyFn := func(_ context.Context, _ string) error {
+		return errors.New("simulated destroy failure")
+	}
+
+	j := New(st, destroyFn, 5*time.Minute, slog.Default())
+
+	if got := j.Status(); !got.LastRun.IsZero() || len(got.RecentErrors) != 0 {
+		t.Fatalf("expected empty status before first run, got %+v", got)
+	}
+
+	for i := 0; i < maxRecentErrors+2; i++ {
+		j.cleanup(context.Background())
+	}
+
+	got := j.Status()
+	if got.LastRun.IsZero() {
+		t.Error("expected LastRun to be set after cleanup")
+	}
+	if len(got.RecentErrors) != maxRecentErrors {
+		t.Fatalf("expected %d recent errors, got %d", maxRecentErrors, len(got.RecentErrors))
+	}
+	if msg := got.RecentErrors[0].Message; msg != "destroy expired sandbox SBX-fail: simulated destroy failure" {
+		t.Errorf("unexpected error message: %q", msg)
+	}
+}
+
+func TestJanitor_StopsIdleSandboxes(t *testing.T) {
+	st := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// Idle: created two hours ago, last command an hour ago.
+	insertExpiredSandbox(t, st, "SBX-idle", 0, now.Add(-2*time.Hour))
+	if err := st.CreateCommand(ctx, &state.Command{ID: "CMD-1", SandboxID: "SBX-idle", Command: "ls", StartedAt: now.Add(-time.Hour), EndedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("CreateCommand: %v", err)
+	}
+	// Busy: created two hours ago but ran a command a minute ago.
+	insertExpiredSandbox(t, st, "SBX-busy", 0, now.Add(-2*time.Hour))
+	if err := st.CreateCommand(ctx, &state.Command{ID: "CMD-2", SandboxID: "SBX-busy", Command: "ls", StartedAt: now.Add(-time.Minute), EndedAt: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("CreateCommand: %v", err)
+	}
+	// Fresh: no commands, created five minutes ago.
+	insertExpiredSandbox(t, st, "SBX-fresh", 0, now.Add(-5*time.Minute))
+
+	j := New(st, func(context.Context, string) error { return nil }, 0, slog.Default())
+	var stopped []string
+	j.SetIdleStop(func(_ context.Context, id string) error {
+		stopped = append(stopped, id)
+		return nil
+	}, 30*time.Minute)
+	j.cleanup(ctx)
+
+	if len(stopped) != 1 || stopped[0] != "SBX-idle" {
+		t.Errorf("stopped = %v, want only SBX-idle", stopped)
+	}
+}
+
+func TestJanitor_IdleStopSkipsRunningCommands(t *testing.T) {
+	st := newTestStore(t)
+	now := time.Now().UTC()
+	// Both look idle; SBX-long has a command that started before the
+	// timeout and is still going.
+	insertExpiredSandbox(t, st, "SBX-idle", 0, now.Add(-2*time.Hour))
+	insertExpiredSandbox(t, st, "SBX-long", 0, now.Add(-2*time.Hour))
+
+	j := New(st, func(context.Context, string) error { return nil }, 0, slog.Default())
+	var stopped []string
+	j.SetIdleStop(func(_ context.Context, id string) error {
+		stopped = append(stopped, id)
+		return nil
+	}, 30*time.Minute)
+	j.SetBusy(func(id string) bool { return id == "SBX-long" })
+	j.cleanup(context.Background())
+
+	if len(stopped) != 1 || stopped[0] != "SBX-idle" {
+		t.Errorf("stopped = %v, want only SBX-idle", stopped)
+	}
+}
+
+func TestJanitor_IdleStopDisabled(t *testing.T) {
+	st := newTestStore(t)
+	insertExpiredSandbox(t, st, "SBX-idle", 0, time.Now().UTC().Add(-48*time.Hour))
+
+	j := New(st, func(context.Context, string) error { return nil }, 0, slog.Default())
+	called := false
+	j.SetIdleStop(func(context.Context, string) error { called = true; return nil }, 0)
+	j.cleanup(context.Background())
+	if called {
+		t.Error("a zero idle timeout should not stop anything")
+	}
+}
+
+func TestJanitor_CountsReapsInMetrics(t *testing.T) {
+	st := newTestStore(t)
+	insertExpiredSandbox(t, st, "SBX-expired", 1, time.Now().UTC().Add(-11*time.Second))
+	insertExpiredSandbox(t, st, "SBX-failing", 1, time.Now().UTC().Add(-11*time.Second))
+
+	j := New(st, func(_ context.Context, id string) error {
+		if id == "SBX-failing" {
+			return errors.New("simulated destroy failure")
+		}
+		return nil
+	}, 5*time.Minute, slog.Default())
+	m := metrics.New()
+	j.SetMetrics(m)
+	j.cleanup(context.Background())
+
+	var out strings.Builder
+	if _, err := m.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	if want := `deer_janitor_reaps_total{action="destroy"} 1`; !strings.Contains(out.String(), want) {
+		t.Errorf("metrics missing %q:\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,210 @@
+// Package metrics exposes daemon counters, gauges and histograms in the
+// Prometheus text format. It implements only the small subset of the format
+// the daemon needs, so the daemon does not depend on a client library.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCommandBuckets are the upper bounds, in seconds, of the command
+// duration histogram: from quick reads to long package installs.
+var DefaultCommandBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Metrics holds the daemon's series. All methods are safe on a nil
+// *Metrics, so instrumented code works unchanged when metrics are disabled.
+type Metrics struct {
+	sandboxesCreated   *counter
+	sandboxesDestroyed *counter
+	janitorReaps       *counter
+	providerErrors     *counter
+	commandDuration    *histogram
+
+	mu     sync.Mutex
+	active func() int
+}
+
+// New returns an empty set of daemon metrics.
+func New() *Metrics {
+	return &Metrics{
+		sandboxesCreated:   newCounter("deer_sandboxes_created_total", "Sandboxes created by the provider."),
+		sandboxesDestroyed: newCounter("deer_sandboxes_destroyed_total", "Sandboxes destroyed by the provider."),
+		janitorReaps:       newCounter("deer_janitor_reaps_total", "Sandboxes the janitor destroyed for their TTL or stopped for being idle.", "action"),
+		providerErrors:     newCounter("deer_provider_errors_total", "Failed provider operations.", "op"),
+		commandDuration:    newHistogram("deer_command_duration_seconds", "Time taken by commands run in sandboxes.", DefaultCommandBuckets),
+	}
+}
+
+// SetActiveSandboxes sets the function sampled for the active sandbox gauge.
+func (m *Metrics) SetActiveSandboxes(fn func() int) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.active = fn
+	m.mu.Unlock()
+}
+
+// SandboxCreated counts a sandbox the provider created.
+func (m *Metrics) SandboxCreated() {
+	if m != nil {
+		m.sandboxesCreated.inc()
+	}
+}
+
+// SandboxDestroyed counts a sandbox the provider destroyed.
+func (m *Metrics) SandboxDestroyed() {
+	if m != nil {
+		m.sandboxesDestroyed.inc()
+	}
+}
+
+// JanitorReaped counts a sandbox the janitor acted on; action is "destroy"
+// for an expired TTL or "stop" for an idle sandbox.
+func (m *Metrics) JanitorReaped(action string) {
+	if m != nil {
+		m.janitorReaps.inc(action)
+	}
+}
+
+// ProviderError counts a failed provider operation such as "create".
+func (m *Metrics) ProviderError(op string) {
+	if m != nil {
+		m.providerErrors.inc(op)
+	}
+}
+
+// ObserveCommand records how long a sandbox command took.
+func (m *Metrics) ObserveCommand(d time.Duration) {
+	if m != nil {
+		m.commandDuration.observe(d.Seconds())
+	}
+}
+
+// WriteTo writes every series in the Prometheus text format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	m.sandboxesCreated.write(&b)
+	m.sandboxesDestroyed.write(&b)
+
+	m.mu.Lock()
+	active := m.active
+	m.mu.Unlock()
+	if active != nil {
+		fmt.Fprintf(&b, "# HELP deer_active_sandboxes Sandboxes the provider is currently running.\n")
+		fmt.Fprintf(&b, "# TYPE deer_active_sandboxes gauge\n")
+		fmt.Fprintf(&b, "deer_active_sandboxes %d\n", active())
+	}
+
+	m.janitorReaps.write(&b)
+	m.providerErrors.write(&b)
+	m.commandDuration.write(&b)
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the metrics at any path it is mounted on.
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = m.WriteTo(w)
+	})
+}
+
+// counter is a monotonically increasing value, optionally split by one
+// label.
+type counter struct {
+	name, help, label string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newCounter(name, help string, label ...string) *counter {
+	c := &counter{name: name, help: help, values: make(map[string]float64)}
+	if len(label) > 0 {
+		c.label = label[0]
+	}
+	return c
+}
+
+func (c *counter) inc(labelValue ...string) {
+	key := ""
+	if c.label != "" && len(labelValue) > 0 {
+		key = labelValue[0]
+	}
+	c.mu.Lock()
+	c.values[key]++
+	c.mu.Unlock()
+}
+
+func (c *counter) write(b *strings.Builder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if c.label == "" {
+		fmt.Fprintf(b, "%s %s\n", c.name, formatFloat(c.values[""]))
+		return
+	}
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s{%s=%q} %s\n", c.name, c.label, k, formatFloat(c.values[k]))
+	}
+}
+
+// histogram counts observations into cumulative buckets.
+type histogram struct {
+	name, help string
+	bounds     []float64
+
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    float64
+	total  uint64
+}
+
+func newHistogram(name, help string, bounds []float64) *histogram {
+	return &histogram{name: name, help: help, bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.total++
+	h.mu.Unlock()
+}
+
+func (h *histogram) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	var cum uint64
+	for i, le := range h.bounds {
+		cum += h.counts[i]
+		fmt.Fprintf(b, "%s_bucket{le=%q} %d\n", h.name, formatFloat(le), cum)
+	}
+	fmt.Fprintf(b, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.total)
+	fmt.Fprintf(b, "%s_sum %s\n", h.name, formatFloat(h.sum))
+	fmt.Fprintf(b, "%s_count %d\n", h.name, h.total)
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	m := New()
+	m.SandboxCreated()
+	m.SandboxCreated()
+	m.SandboxDestroyed()
+	m.JanitorReaped("destroy")
+	m.JanitorReaped("stop")
+	m.JanitorReaped("destroy")
+	m.ProviderError("create")
+	m.ObserveCommand(30 * time.Millisecond)
+	m.ObserveCommand(3 * time.Second)
+	m.ObserveCommand(10 * time.Minute)
+	m.SetActiveSandboxes(func() int { return 4 })
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	out := string(body)
+	for _, want := range []string{
+		"# TYPE deer_sandboxes_created_total counter",
+		"deer_sandboxes_created_total 2\n",
+		"deer_sandboxes_destroyed_total 1\n",
+		"deer_active_sandboxes 4\n",
+		`deer_janitor_reaps_total{action="destroy"} 2`,
+		`deer_janitor_reaps_total{action="stop"} 1`,
+		`deer_provider_errors_total{op="create"} 1`,
+		"# TYPE deer_command_duration_seconds histogram",
+		`deer_command_duration_seconds_bucket{le="0.05"} 1`,
+		`deer_command_duration_seconds_bucket{le="5"} 2`,
+		`deer_command_duration_seconds_bucket{le="300"} 2`,
+		`deer_command_duration_seconds_bucket{le="+Inf"} 3`,
+		"deer_command_duration_seconds_sum 603.03\n",
+		"deer_command_duration_seconds_count 3\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.SandboxCreated()
+	m.SandboxDestroyed()
+	m.JanitorReaped("stop")
+	m.ProviderError("create")
+	m.ObserveCommand(time.Second)
+	m.SetActiveSandboxes(func() int { return 1 })
+}
@@ -0,0 +1,97 @@
+package microvm
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// InjectMethod is how the daemon's SSH CA key and sandbox user are put into
+// a guest.
+type InjectMethod string
+
+const (
+	// InjectAuto inspects the base image to pick one of the methods below.
+	InjectAuto InjectMethod = "auto"
+	// InjectCloudInit attaches a NoCloud ISO that cloud-init applies at boot.
+	InjectCloudInit InjectMethod = "cloud-init"
+	// InjectVirtCustomize writes the key into the overlay disk before boot.
+	InjectVirtCustomize InjectMethod = "virt-customize"
+	// InjectNone leaves the guest alone, for pre-baked and Windows images.
+	InjectNone InjectMethod = "none"
+)
+
+var injectMethods = []InjectMethod{InjectAuto, InjectCloudInit, InjectVirtCustomize, InjectNone}
+
+// ParseInjectMethod validates an injection method name from config.
+func ParseInjectMethod(s string) (InjectMethod, error) {
+	m := InjectMethod(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(injectMethods, m) {
+		return "", fmt.Errorf("unknown SSH key inject method %q (want auto, cloud-init, virt-customize or none)", s)
+	}
+	return m, nil
+}
+
+// guestList lists a directory inside a disk image without booting it.
+var guestList = func(ctx context.Context, imagePath, dir string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "virt-ls", "-a", imagePath, dir).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("virt-ls %s: %w: %s", dir, err, strings.TrimSpace(string(out)))
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// DetectInjectMethod inspects a base image and returns the injection method
+// it supports, with the reason it was chosen: Windows images get none, images
+// with cloud-init installed get cloud-init, and other Linux images get
+// virt-customize. It needs libguestfs (virt-ls) on the host.
+func DetectInjectMethod(ctx context.Context, imagePath string) (InjectMethod, string, error) {
+	root, err := guestList(ctx, imagePath, "/")
+	if err != nil {
+		return "", "", fmt.Errorf("inspect image: %w", err)
+	}
+	if slices.ContainsFunc(root, func(name string) bool { return strings.EqualFold(name, "Windows") }) {
+		return InjectNone, "Windows guest", nil
+	}
+
+	etc, err := guestList(ctx, imagePath, "/etc")
+	if err != nil {
+		return "", "", fmt.Errorf("inspect image: %w", err)
+	}
+	if slices.Contains(etc, "cloud") {
+		return InjectCloudInit, "cloud-init installed", nil
+	}
+	return InjectVirtCustomize, "no cloud-init in image", nil
+}
+
+// virtCustomize runs virt-customize against a disk image.
+var virtCustomize = func(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "virt-customize", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("virt-customize: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// InjectSSHCA writes the same SSH setup the cloud-init user-data applies -
+// the sandbox user, its principals file, the CA key, and the sshd options
+// that trust it - directly into a sandbox's overlay disk.
+func InjectSSHCA(ctx context.Context, overlayPath, caPubKey string) error {
+	if strings.TrimSpace(caPubKey) == "" {
+		return fmt.Errorf("no SSH CA public key to inject")
+	}
+	return virtCustomize(ctx,
+		"-a", overlayPath,
+		"--no-network",
+		"--run-command", "id -u sandbox >/dev/null 2>&1 || useradd -m -s /bin/bash sandbox",
+		"--write", "/etc/sudoers.d/deer-sandbox:sandbox ALL=(ALL) NOPASSWD:ALL\n",
+		"--chmod", "0440:/etc/sudoers.d/deer-sandbox",
+		"--mkdir", "/etc/ssh/authorized_principals",
+		"--write", "/etc/ssh/authorized_principals/sandbox:sandbox\n",
+		"--write", "/etc/ssh/deer_ca.pub:"+strings.TrimSpace(caPubKey)+"\n",
+		"--run-command", "grep -q 'TrustedUserCAKeys /etc/ssh/deer_ca.pub' /etc/ssh/sshd_config || echo 'TrustedUserCAKeys /etc/ssh/deer_ca.pub' >> /etc/ssh/sshd_config",
+		"--run-command", "grep -q 'AuthorizedPrincipalsFile /etc/ssh/authorized_principals/%u' /etc/ssh/sshd_config || echo 'AuthorizedPrincipalsFile /etc/ssh/authorized_principals/%u' >> /etc/ssh/sshd_config",
+	)
+}
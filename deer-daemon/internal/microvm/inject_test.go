@@ -0,0 +1,118 @@
+package microvm
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseInjectMethod(t *testing.T) {
+	for _, s := range []string{"auto", "cloud-init", "virt-customize", "none", " Cloud-Init "} {
+		if _, err := ParseInjectMethod(s); err != nil {
+			t.Errorf("ParseInjectMethod(%q): %v", s, err)
+		}
+	}
+	if _, err := ParseInjectMethod("ignition"); err == nil {
+		t.Error("ParseInjectMethod(ignition) succeeded, want error")
+	}
+}
+
+// fakeGuest makes guestList report the given directory listings; a
+// directory missing from dirs fails like virt-ls does.
+func fakeGuest(t *testing.T, dirs map[string][]string) {
+	t.Helper()
+	prev := guestList
+	t.Cleanup(func() { guestList = prev })
+	guestList = func(_ context.Context, _, dir string) ([]string, error) {
+		entries, ok := dirs[dir]
+		if !ok {
+			return nil, fmt.Errorf("virt-ls %s: no such file or directory", dir)
+		}
+		return entries, nil
+	}
+}
+
+func TestDetectInjectMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		dirs    map[string][]string
+		want    InjectMethod
+		wantErr bool
+	}{
+		{
+			name: "cloud image",
+			dirs: map[string][]string{"/": {"bin", "etc", "usr"}, "/etc": {"cloud", "ssh", "passwd"}},
+			want: InjectCloudInit,
+		},
+		{
+			name: "custom image without cloud-init",
+			dirs: map[string][]string{"/": {"bin", "etc", "usr"}, "/etc": {"ssh", "passwd"}},
+			want: InjectVirtCustomize,
+		},
+		{
+			name: "windows",
+			dirs: map[string][]string{"/": {"Program Files", "Users", "Windows"}},
+			want: InjectNone,
+		},
+		{
+			name:    "unreadable image",
+			dirs:    map[string][]string{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGuest(t, tt.dirs)
+			got, reason, err := DetectInjectMethod(context.Background(), "/images/base.qcow2")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("DetectInjectMethod = %q, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectInjectMethod: %v", err)
+			}
+			if got != tt.want || reason == "" {
+				t.Errorf("DetectInjectMethod = %q (%q), want %q with a reason", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectSSHCA(t *testing.T) {
+	prev := virtCustomize
+	t.Cleanup(func() { virtCustomize = prev })
+	var got []string
+	virtCustomize = func(_ context.Context, args ...string) error {
+		got = args
+		return nil
+	}
+
+	if err := InjectSSHCA(context.Background(), "/work/sbx/disk.qcow2", "ssh-ed25519 AAAAC3Nz deer-ca\n"); err != nil {
+		t.Fatalf("InjectSSHCA: %v", err)
+	}
+	if len(got) < 2 || got[0] != "-a" || got[1] != "/work/sbx/disk.qcow2" {
+		t.Fatalf("args = %q, want the overlay first", got)
+	}
+	joined := strings.Join(got, "\n")
+	for _, want := range []string{
+		"/etc/ssh/deer_ca.pub:ssh-ed25519 AAAAC3Nz deer-ca\n",
+		"/etc/ssh/authorized_principals/sandbox:sandbox\n",
+		"TrustedUserCAKeys /etc/ssh/deer_ca.pub",
+		"useradd -m -s /bin/bash sandbox",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("args missing %q:\n%s", want, joined)
+		}
+	}
+	if !slices.Contains(got, "--no-network") {
+		t.Error("virt-customize should run without network")
+	}
+
+	if err := InjectSSHCA(context.Background(), "/work/sbx/disk.qcow2", ""); err == nil {
+		t.Error("InjectSSHCA with no CA key succeeded, want error")
+	}
+}
@@ -44,6 +44,32 @@ func CreateOverlay(ctx context.Context, baseImagePath, workDir, sandboxID string
 	return overlayPath, nil
 }
 
+// ExportOverlay flattens a sandbox's overlay and its backing image into a
+// standalone QCOW2 image at destPath. The image is written to a temporary
+// file and renamed into place so a failed export never leaves a partial
+// image at destPath. -U lets it read an overlay a running VM still holds.
+func ExportOverlay(ctx context.Context, workDir, sandboxID, destPath string) error {
+	overlayPath := filepath.Join(workDir, sandboxID, "disk.qcow2")
+	if _, err := os.Stat(overlayPath); err != nil {
+		return fmt.Errorf("sandbox overlay: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("create archive dir: %w", err)
+	}
+
+	tmpPath := destPath + ".partial"
+	cmd := exec.CommandContext(ctx, "qemu-img", "convert", "-U", "-O", "qcow2", overlayPath, tmpPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("qemu-img convert: %w: %s", err, string(output))
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("move archive into place: %w", err)
+	}
+	return nil
+}
+
 // RemoveOverlay removes the sandbox directory and all its contents (overlay, PID file, etc).
 func RemoveOverlay(workDir, sandboxID string) error {
 	sandboxDir := filepath.Join(workDir, sandboxID)
@@ -0,0 +1,82 @@
+package microvm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// procRoot is where process information is read from.
+var procRoot = "/proc"
+
+// clockTicks is the kernel's USER_HZ, the unit of CPU times in
+// /proc/<pid>/stat. It is 100 on every Linux architecture deer runs on.
+const clockTicks = 100
+
+// ProcessStats returns the CPU time a QEMU process has used and its
+// resident memory. It needs /proc, so it only works on Linux hosts.
+func ProcessStats(pid int) (cpuTime time.Duration, rssBytes int64, err error) {
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("read process stat: %w", err)
+	}
+	// The command name in field 2 may contain spaces, so count fields from
+	// the closing parenthesis. utime and stime are fields 14 and 15.
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("parse process stat: no command name")
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 13 {
+		return 0, 0, fmt.Errorf("parse process stat: %d fields", len(fields)+2)
+	}
+	var ticks int64
+	for _, f := range fields[11:13] {
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse process stat: %w", err)
+		}
+		ticks += n
+	}
+	cpuTime = time.Duration(ticks) * time.Second / clockTicks
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("read process status: %w", err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(status))
+	for sc.Scan() {
+		rest, ok := strings.CutPrefix(sc.Text(), "VmRSS:")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(rest), " kB"), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse VmRSS: %w", err)
+		}
+		rssBytes = kb * 1024
+		break
+	}
+	return cpuTime, rssBytes, nil
+}
+
+// DiskAllocation returns the space a sandbox's overlay takes on the host,
+// which for a sparse qcow2 is usually far less than its virtual size.
+func DiskAllocation(workDir, sandboxID string) (int64, error) {
+	info, err := os.Stat(filepath.Join(workDir, sandboxID, "disk.qcow2"))
+	if err != nil {
+		return 0, fmt.Errorf("stat overlay: %w", err)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Blocks * 512, nil
+	}
+	return info.Size(), nil
+}
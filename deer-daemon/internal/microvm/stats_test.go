@@ -0,0 +1,66 @@
+package microvm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProcessStats(t *testing.T) {
+	root := t.TempDir()
+	old := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = old })
+
+	dir := filepath.Join(root, "4242")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// utime=250 and stime=50 ticks; the command name contains spaces.
+	stat := "4242 (qemu-system x86) S 1 4242 4242 0 -1 4194560 100 0 0 0 250 50 0 0 20 0 3 0 100 0 0"
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	status := "Name:\tqemu-system-x86\nVmPeak:\t 3000000 kB\nVmRSS:\t  524288 kB\nThreads:\t3\n"
+	if err := os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cpu, rss, err := ProcessStats(4242)
+	if err != nil {
+		t.Fatalf("ProcessStats: %v", err)
+	}
+	if cpu != 3*time.Second {
+		t.Errorf("cpu = %v, want 3s", cpu)
+	}
+	if rss != 512<<20 {
+		t.Errorf("rss = %d, want %d", rss, 512<<20)
+	}
+
+	if _, _, err := ProcessStats(1); err == nil {
+		t.Error("expected an error for a missing process")
+	}
+}
+
+func TestDiskAllocation(t *testing.T) {
+	workDir := t.TempDir()
+	dir := filepath.Join(workDir, "sbx-1")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "disk.qcow2"), make([]byte, 64<<10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := DiskAllocation(workDir, "sbx-1")
+	if err != nil {
+		t.Fatalf("DiskAllocation: %v", err)
+	}
+	if n < 64<<10 {
+		t.Errorf("allocation = %d, want at least %d", n, 64<<10)
+	}
+	if _, err := DiskAllocation(workDir, "missing"); err == nil {
+		t.Error("expected an error for a missing overlay")
+	}
+}
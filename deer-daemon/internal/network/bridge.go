@@ -7,6 +7,7 @@ import (
 	"net"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var validBridge = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
@@ -16,6 +17,8 @@ type NetworkManager struct {
 	defaultBridge string
 	bridgeMap     map[string]string // libvirt network name -> local bridge name
 	dhcpMode      string
+	pollInterval  time.Duration
+	isolation     string // IsolationNone or IsolationBridgePrivate
 	logger        *slog.Logger
 }
 
@@ -31,6 +34,7 @@ func NewNetworkManager(defaultBridge string, bridgeMap map[string]string, dhcpMo
 		defaultBridge: defaultBridge,
 		bridgeMap:     bridgeMap,
 		dhcpMode:      dhcpMode,
+		pollInterval:  defaultIPPollInterval,
 		logger:        logger.With("component", "network"),
 	}
 }
@@ -65,6 +69,14 @@ func (n *NetworkManager) ResolveBridge(ctx context.Context, requestedNetwork str
 	return bridge, nil
 }
 
+// SetPollInterval sets how often IP discovery re-checks for a sandbox's
+// address. Non-positive values keep the default.
+func (n *NetworkManager) SetPollInterval(d time.Duration) {
+	if d > 0 {
+		n.pollInterval = d
+	}
+}
+
 // DHCPMode returns the configured DHCP mode.
 func (n *NetworkManager) DHCPMode() string {
 	return n.dhcpMode
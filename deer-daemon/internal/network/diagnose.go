@@ -0,0 +1,121 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	sysClassNet  = "/sys/class/net"
+	bridgeIPFunc = GetBridgeIP
+)
+
+// ipDiscoveryHints inspects the host for the usual reasons a VM never shows
+// up on its bridge: the bridge is missing or down, the VM's NIC is not
+// attached or not up, or nothing serves DHCP on the bridge. Hints are
+// ordered with the most fundamental problem first.
+func ipDiscoveryHints(bridge, dhcpMode string) []string {
+	var hints []string
+
+	if runtimeGOOS == "linux" {
+		brDir := filepath.Join(sysClassNet, filepath.Base(bridge))
+		if _, err := os.Stat(filepath.Join(brDir, "bridge")); err != nil {
+			return []string{fmt.Sprintf("bridge %s does not exist; check network.default_bridge and network.bridge_map", bridge)}
+		}
+		if readOperState(brDir) == "down" {
+			hints = append(hints, fmt.Sprintf("bridge %s is down; bring it up with: ip link set %s up", bridge, bridge))
+		}
+		ports, _ := os.ReadDir(filepath.Join(brDir, "brif"))
+		if len(ports) == 0 {
+			hints = append(hints, fmt.Sprintf("no interfaces are attached to bridge %s, so the VM's NIC is not connected to it", bridge))
+		} else {
+			var down []string
+			for _, port := range ports {
+				if readOperState(filepath.Join(sysClassNet, port.Name())) == "down" {
+					down = append(down, port.Name())
+				}
+			}
+			if len(down) == len(ports) {
+				hints = append(hints, fmt.Sprintf("every interface on bridge %s is down (%s); the VM's NIC never came up", bridge, strings.Join(down, ", ")))
+			}
+		}
+	}
+
+	if _, err := bridgeIPFunc(bridge); err != nil {
+		hints = append(hints, fmt.Sprintf("bridge %s has no IPv4 address, so no DHCP server on the host can serve it", bridge))
+	}
+
+	switch dhcpMode {
+	case "dnsmasq":
+		leaseFile := filepath.Join(deerDnsmasqDir, filepath.Base(bridge)+".leases")
+		if _, err := os.Stat(leaseFile); err != nil {
+			hints = append(hints, fmt.Sprintf("no dnsmasq lease file at %s; is dnsmasq running with a dhcp-range on %s?", leaseFile, bridge))
+		} else {
+			hints = append(hints, fmt.Sprintf("dnsmasq on %s handed out no lease for this VM; check the guest runs a DHCP client on its NIC", bridge))
+		}
+	default:
+		found, hasRange := libvirtDHCPRange(bridge)
+		switch {
+		case found && !hasRange:
+			hints = append(hints, fmt.Sprintf("the libvirt network on bridge %s has no DHCP range; add <dhcp><range .../></dhcp> to its definition", bridge))
+		case !found && dhcpMode == "libvirt":
+			hints = append(hints, fmt.Sprintf("no libvirt network serves bridge %s; check network.bridge_map or set network.dhcp_mode", bridge))
+		case !found:
+			hints = append(hints, fmt.Sprintf("no libvirt DHCP range found for bridge %s; make sure a DHCP server answers on it", bridge))
+		}
+	}
+
+	if len(hints) == 0 {
+		hints = append(hints, fmt.Sprintf("bridge %s looks healthy; the VM may not have got a lease: check the guest's network config", bridge))
+	}
+	return hints
+}
+
+// libvirtDHCPRange reports whether a libvirt network's dnsmasq config
+// binds bridge, and if so whether it sets a dhcp-range.
+func libvirtDHCPRange(bridge string) (found, hasRange bool) {
+	confs, _ := filepath.Glob(filepath.Join(libvirtDnsmasqDir, "*.conf"))
+	for _, path := range confs {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		var binds, ranged bool
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			switch {
+			case line == "interface="+bridge:
+				binds = true
+			case strings.HasPrefix(line, "dhcp-range="):
+				ranged = true
+			}
+		}
+		_ = f.Close()
+		if binds {
+			return true, ranged
+		}
+	}
+	return false, false
+}
+
+func readOperState(ifaceDir string) string {
+	data, err := os.ReadFile(filepath.Join(ifaceDir, "operstate"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// formatHints renders hints as indented lines to append to an error.
+func formatHints(hints []string) string {
+	var b strings.Builder
+	for _, h := range hints {
+		b.WriteString("\n  hint: ")
+		b.WriteString(h)
+	}
+	return b.String()
+}
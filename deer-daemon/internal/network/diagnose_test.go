@@ -0,0 +1,172 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeHostNet points the diagnostics at temporary sysfs and dnsmasq
+// directories and makes bridgeIP the bridge's address ("" for none).
+func fakeHostNet(t *testing.T, bridgeIP string) (sysDir, libvirtDir string) {
+	t.Helper()
+	prevSys, prevLibvirt, prevDeer, prevGOOS, prevIP := sysClassNet, libvirtDnsmasqDir, deerDnsmasqDir, runtimeGOOS, bridgeIPFunc
+	t.Cleanup(func() {
+		sysClassNet, libvirtDnsmasqDir, deerDnsmasqDir, runtimeGOOS, bridgeIPFunc = prevSys, prevLibvirt, prevDeer, prevGOOS, prevIP
+	})
+	sysClassNet = t.TempDir()
+	libvirtDnsmasqDir = t.TempDir()
+	deerDnsmasqDir = t.TempDir()
+	runtimeGOOS = "linux"
+	bridgeIPFunc = func(bridge string) (string, error) {
+		if bridgeIP == "" {
+			return "", fmt.Errorf("no IPv4 address on bridge %s", bridge)
+		}
+		return bridgeIP, nil
+	}
+	return sysClassNet, libvirtDnsmasqDir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addBridge creates a bridge in the fake sysfs with the given ports, each
+// "name=state".
+func addBridge(t *testing.T, sysDir, bridge, state string, ports ...string) {
+	t.Helper()
+	writeFile(t, filepath.Join(sysDir, bridge, "operstate"), state+"\n")
+	if err := os.MkdirAll(filepath.Join(sysDir, bridge, "bridge"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(sysDir, bridge, "brif"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range ports {
+		name, portState, _ := strings.Cut(p, "=")
+		writeFile(t, filepath.Join(sysDir, bridge, "brif", name), "")
+		writeFile(t, filepath.Join(sysDir, name, "operstate"), portState+"\n")
+	}
+}
+
+func TestIPDiscoveryHints(t *testing.T) {
+	tests := []struct {
+		name     string
+		bridgeIP string
+		mode     string
+		setup    func(t *testing.T, sysDir, libvirtDir string)
+		want     []string
+	}{
+		{
+			name:  "missing bridge",
+			mode:  "arp",
+			setup: func(*testing.T, string, string) {},
+			want:  []string{"bridge virbr0 does not exist"},
+		},
+		{
+			name:     "bridge down with no ports",
+			bridgeIP: "192.168.122.1",
+			mode:     "arp",
+			setup: func(t *testing.T, sysDir, libvirtDir string) {
+				addBridge(t, sysDir, "virbr0", "down")
+				writeFile(t, filepath.Join(libvirtDir, "default.conf"), "interface=virbr0\ndhcp-range=192.168.122.2,192.168.122.254\n")
+			},
+			want: []string{"bridge virbr0 is down", "no interfaces are attached"},
+		},
+		{
+			name:     "NIC down",
+			bridgeIP: "192.168.122.1",
+			mode:     "libvirt",
+			setup: func(t *testing.T, sysDir, libvirtDir string) {
+				addBridge(t, sysDir, "virbr0", "up", "fl-abc=down")
+				writeFile(t, filepath.Join(libvirtDir, "default.conf"), "interface=virbr0\ndhcp-range=192.168.122.2,192.168.122.254\n")
+			},
+			want: []string{"every interface on bridge virbr0 is down (fl-abc)"},
+		},
+		{
+			name:     "libvirt network without DHCP range",
+			bridgeIP: "192.168.122.1",
+			mode:     "libvirt",
+			setup: func(t *testing.T, sysDir, libvirtDir string) {
+				addBridge(t, sysDir, "virbr0", "up", "fl-abc=unknown")
+				writeFile(t, filepath.Join(libvirtDir, "default.conf"), "interface=virbr0\n")
+			},
+			want: []string{"has no DHCP range"},
+		},
+		{
+			name: "no address and no dnsmasq",
+			mode: "dnsmasq",
+			setup: func(t *testing.T, sysDir, _ string) {
+				addBridge(t, sysDir, "virbr0", "up", "fl-abc=up")
+			},
+			want: []string{"has no IPv4 address", "no dnsmasq lease file"},
+		},
+		{
+			name:     "healthy bridge",
+			bridgeIP: "192.168.122.1",
+			mode:     "libvirt",
+			setup: func(t *testing.T, sysDir, libvirtDir string) {
+				addBridge(t, sysDir, "virbr0", "up", "fl-abc=up")
+				writeFile(t, filepath.Join(libvirtDir, "default.conf"), "interface=virbr0\ndhcp-range=192.168.122.2,192.168.122.254\n")
+			},
+			want: []string{"looks healthy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sysDir, libvirtDir := fakeHostNet(t, tt.bridgeIP)
+			tt.setup(t, sysDir, libvirtDir)
+
+			hints := ipDiscoveryHints("virbr0", tt.mode)
+			if len(hints) != len(tt.want) {
+				t.Fatalf("hints = %q, want %d", hints, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(hints[i], want) {
+					t.Errorf("hints[%d] = %q, want it to contain %q", i, hints[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscoverIP_TimeoutIncludesHints(t *testing.T) {
+	fakeHostNet(t, "")
+
+	n := NewNetworkManager("virbr0", nil, "dnsmasq", slog.New(slog.DiscardHandler))
+	n.SetPollInterval(10 * time.Millisecond)
+
+	_, err := n.DiscoverIP(context.Background(), "52:54:00:aa:bb:cc", "virbr0", 30*time.Millisecond)
+	if !errors.Is(err, ErrIPDiscoveryTimeout) {
+		t.Fatalf("err = %v, want ErrIPDiscoveryTimeout", err)
+	}
+	if !strings.Contains(err.Error(), "hint: bridge virbr0 does not exist") {
+		t.Errorf("err = %q, want a missing-bridge hint", err)
+	}
+}
+
+func TestDiscoverIP_DnsmasqLease(t *testing.T) {
+	fakeHostNet(t, "192.168.122.1")
+	writeFile(t, filepath.Join(deerDnsmasqDir, "virbr0.leases"), "1700000000 52:54:00:aa:bb:cc 192.168.122.50 sbx *\n")
+
+	n := NewNetworkManager("virbr0", nil, "dnsmasq", slog.New(slog.DiscardHandler))
+	ip, err := n.DiscoverIP(context.Background(), "52:54:00:AA:BB:CC", "virbr0", time.Second)
+	if err != nil {
+		t.Fatalf("DiscoverIP: %v", err)
+	}
+	if ip != "192.168.122.50" {
+		t.Errorf("ip = %q, want 192.168.122.50", ip)
+	}
+}
@@ -3,6 +3,7 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -12,23 +13,51 @@ import (
 	"time"
 )
 
+// defaultIPPollInterval is how often IP discovery re-checks leases and
+// neighbour tables when no interval is configured.
+const defaultIPPollInterval = 2 * time.Second
+
+// ErrIPDiscoveryTimeout is returned, wrapped with diagnostics, when no IP
+// turns up for a MAC address before the discovery timeout.
+var ErrIPDiscoveryTimeout = errors.New("IP discovery timed out")
+
+// Paths read by IP discovery and its diagnostics; tests point them at
+// temporary directories.
+var (
+	libvirtDnsmasqDir = "/var/lib/libvirt/dnsmasq"
+	deerDnsmasqDir    = "/var/lib/deer/dnsmasq"
+)
+
 // DiscoverIP discovers the IP address assigned to a MAC address on a given bridge.
-// It uses the configured DHCP mode to determine the discovery strategy.
+// It uses the configured DHCP mode to determine the discovery strategy. On
+// timeout the error wraps ErrIPDiscoveryTimeout and lists hints about the
+// likely network misconfiguration.
 func (n *NetworkManager) DiscoverIP(ctx context.Context, macAddress, bridge string, timeout time.Duration) (string, error) {
-	switch n.dhcpMode {
+	mode := n.dhcpMode
+	if mode != "libvirt" && mode != "dnsmasq" {
+		mode = "arp"
+	}
+
+	var ip string
+	var err error
+	switch mode {
 	case "libvirt":
-		return discoverIPLibvirt(ctx, macAddress, bridge, timeout, n.logger)
-	case "arp":
-		return discoverIPARP(ctx, macAddress, bridge, timeout, n.logger)
+		ip, err = discoverIPLibvirt(ctx, macAddress, bridge, timeout, n.pollInterval, n.logger)
 	case "dnsmasq":
-		return discoverIPDnsmasq(ctx, macAddress, bridge, timeout, n.logger)
+		ip, err = discoverIPDnsmasq(ctx, macAddress, bridge, timeout, n.pollInterval, n.logger)
 	default:
-		return discoverIPARP(ctx, macAddress, bridge, timeout, n.logger)
+		ip, err = discoverIPARP(ctx, macAddress, bridge, timeout, n.pollInterval, n.logger)
+	}
+	if errors.Is(err, ErrIPDiscoveryTimeout) {
+		hints := ipDiscoveryHints(bridge, mode)
+		n.logger.Warn("IP discovery timed out", "mac", macAddress, "bridge", bridge, "dhcp_mode", mode, "timeout", timeout, "hints", hints)
+		return "", fmt.Errorf("%w for MAC %s on bridge %s after %s (%s mode)%s", ErrIPDiscoveryTimeout, macAddress, bridge, timeout, mode, formatHints(hints))
 	}
+	return ip, err
 }
 
 // discoverIPLibvirt reads libvirt dnsmasq lease files to find IP for a MAC.
-func discoverIPLibvirt(ctx context.Context, macAddress, bridge string, timeout time.Duration, logger *slog.Logger) (string, error) {
+func discoverIPLibvirt(ctx context.Context, macAddress, bridge string, timeout, pollInterval time.Duration, logger *slog.Logger) (string, error) {
 	mac := strings.ToLower(macAddress)
 	deadline := time.Now().Add(timeout)
 
@@ -37,14 +66,14 @@ func discoverIPLibvirt(ctx context.Context, macAddress, bridge string, timeout t
 
 	// Try common lease file locations
 	leaseFiles := []string{
-		"/var/lib/libvirt/dnsmasq/default.leases",
-		"/var/lib/libvirt/dnsmasq/virbr0.leases",
-		fmt.Sprintf("/var/lib/libvirt/dnsmasq/%s.leases", safeBridge),
+		filepath.Join(libvirtDnsmasqDir, "default.leases"),
+		filepath.Join(libvirtDnsmasqDir, "virbr0.leases"),
+		filepath.Join(libvirtDnsmasqDir, safeBridge+".leases"),
 	}
 	statusFiles := []string{
-		"/var/lib/libvirt/dnsmasq/default.status",
-		"/var/lib/libvirt/dnsmasq/virbr0.status",
-		fmt.Sprintf("/var/lib/libvirt/dnsmasq/%s.status", safeBridge),
+		filepath.Join(libvirtDnsmasqDir, "default.status"),
+		filepath.Join(libvirtDnsmasqDir, "virbr0.status"),
+		filepath.Join(libvirtDnsmasqDir, safeBridge+".status"),
 	}
 
 	for time.Now().Before(deadline) {
@@ -78,12 +107,12 @@ func discoverIPLibvirt(ctx context.Context, macAddress, bridge string, timeout t
 			}
 		}
 
-		if err := contextSleep(ctx, 2*time.Second); err != nil {
+		if err := contextSleep(ctx, pollInterval); err != nil {
 			return "", err
 		}
 	}
 
-	return "", fmt.Errorf("IP discovery timed out for MAC %s (libvirt mode)", macAddress)
+	return "", ErrIPDiscoveryTimeout
 }
 
 type libvirtStatusLease struct {
@@ -109,7 +138,7 @@ func readLibvirtStatusIP(path, mac string) (string, error) {
 }
 
 // discoverIPARP polls the ARP table to find IP for a MAC.
-func discoverIPARP(ctx context.Context, macAddress, bridge string, timeout time.Duration, logger *slog.Logger) (string, error) {
+func discoverIPARP(ctx context.Context, macAddress, bridge string, timeout, pollInterval time.Duration, logger *slog.Logger) (string, error) {
 	mac := strings.ToLower(macAddress)
 	// Normalize MAC to colon-free lowercase for comparison.
 	// macOS ARP collapses leading zeros: 52:54:00:4f:fb:3c -> 52:54:0:4f:fb:3c
@@ -165,12 +194,12 @@ func discoverIPARP(ctx context.Context, macAddress, bridge string, timeout time.
 			}
 		}
 
-		if err := contextSleep(ctx, 2*time.Second); err != nil {
+		if err := contextSleep(ctx, pollInterval); err != nil {
 			return "", err
 		}
 	}
 
-	return "", fmt.Errorf("IP discovery timed out for MAC %s (arp mode)", macAddress)
+	return "", ErrIPDiscoveryTimeout
 }
 
 // normalizeARPMac extracts the MAC from an arp line and returns it
@@ -200,13 +229,13 @@ func normalizeARPMac(line string) string {
 }
 
 // discoverIPDnsmasq reads local dnsmasq lease file for IP discovery.
-func discoverIPDnsmasq(ctx context.Context, macAddress, bridge string, timeout time.Duration, logger *slog.Logger) (string, error) {
+func discoverIPDnsmasq(ctx context.Context, macAddress, bridge string, timeout, pollInterval time.Duration, logger *slog.Logger) (string, error) {
 	mac := strings.ToLower(macAddress)
 	deadline := time.Now().Add(timeout)
 
 	// Sanitize bridge name to prevent path traversal.
 	safeBridge := filepath.Base(bridge)
-	leaseFile := fmt.Sprintf("/var/lib/deer/dnsmasq/%s.leases", safeBridge)
+	leaseFile := filepath.Join(deerDnsmasqDir, safeBridge+".leases")
 
 	for time.Now().Before(deadline) {
 		select {
@@ -226,12 +255,12 @@ func discoverIPDnsmasq(ctx context.Context, macAddress, bridge string, timeout t
 			}
 		}
 
-		if err := contextSleep(ctx, 2*time.Second); err != nil {
+		if err := contextSleep(ctx, pollInterval); err != nil {
 			return "", err
 		}
 	}
 
-	return "", fmt.Errorf("IP discovery timed out for MAC %s (dnsmasq mode)", macAddress)
+	return "", ErrIPDiscoveryTimeout
 }
 
 func contextSleep(ctx context.Context, d time.Duration) error {
@@ -0,0 +1,133 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Isolation modes for sandboxes sharing a bridge.
+const (
+	// IsolationNone leaves sandboxes on a bridge free to reach each other.
+	IsolationNone = "none"
+	// IsolationBridgePrivate lets sandboxes reach the gateway and anything
+	// beyond it, but not other sandboxes on the same bridge.
+	IsolationBridgePrivate = "bridge-private"
+)
+
+// tapPrefix starts every sandbox TAP name; see TAPName.
+const tapPrefix = "fl-"
+
+// SetIsolationMode sets how sandboxes on the same bridge are isolated from
+// each other: "none" (or empty, the default) or "bridge-private".
+func (n *NetworkManager) SetIsolationMode(mode string) error {
+	switch mode {
+	case "", IsolationNone:
+		n.isolation = IsolationNone
+	case IsolationBridgePrivate:
+		if runtimeGOOS != "linux" {
+			return fmt.Errorf("isolation_mode %q needs ebtables and iptables, which are Linux only", mode)
+		}
+		n.isolation = mode
+	default:
+		return fmt.Errorf("unknown isolation_mode %q: want %q or %q", mode, IsolationNone, IsolationBridgePrivate)
+	}
+	return nil
+}
+
+// IsolationMode returns the configured isolation mode.
+func (n *NetworkManager) IsolationMode() string {
+	if n.isolation == "" {
+		return IsolationNone
+	}
+	return n.isolation
+}
+
+// IsolateTAP applies the isolation mode to a sandbox's TAP on bridge. With
+// bridge-private it drops frames from the TAP to any other sandbox TAP, and
+// drops traffic the host would route from the bridge back into it, so a
+// sandbox cannot reach a neighbour by going through the gateway either.
+// Callers should treat an error as fatal: the sandbox would not be isolated.
+func (n *NetworkManager) IsolateTAP(ctx context.Context, tapName, bridge string) error {
+	if n.IsolationMode() != IsolationBridgePrivate {
+		return nil
+	}
+	hairpin := hairpinRule(bridge)
+	if err := runCmdFunc(ctx, "iptables", append([]string{"-C"}, hairpin...)...); err != nil {
+		if err := runCmdFunc(ctx, "iptables", append([]string{"-I"}, hairpin...)...); err != nil {
+			return fmt.Errorf("isolate bridge %s: %w", bridge, err)
+		}
+	}
+	// Delete before appending so a TAP name reused after a crash does not
+	// collect duplicate rules.
+	rule := tapRule(tapName)
+	_ = runCmdFunc(ctx, "ebtables", append([]string{"-D"}, rule...)...)
+	if err := runCmdFunc(ctx, "ebtables", append([]string{"-A"}, rule...)...); err != nil {
+		return fmt.Errorf("isolate tap %s: %w", tapName, err)
+	}
+	n.logger.Info("TAP isolated from other sandboxes", "tap", tapName, "bridge", bridge)
+	return nil
+}
+
+// ReleaseTAP removes the rules IsolateTAP added for a TAP. The per-bridge
+// rule stays, as other sandboxes may still be using the bridge.
+func (n *NetworkManager) ReleaseTAP(ctx context.Context, tapName string) error {
+	if n.IsolationMode() != IsolationBridgePrivate {
+		return nil
+	}
+	if err := runCmdFunc(ctx, "ebtables", append([]string{"-D"}, tapRule(tapName)...)...); err != nil {
+		return fmt.Errorf("release tap %s: %w", tapName, err)
+	}
+	return nil
+}
+
+// tapRule is the ebtables rule dropping frames from tapName to other
+// sandbox TAPs. The gateway is the bridge itself, which ebtables reaches
+// through INPUT rather than FORWARD, so it stays reachable.
+func tapRule(tapName string) []string {
+	return []string{"FORWARD", "-i", tapName, "-o", tapPrefix + "+", "-j", "DROP"}
+}
+
+// hairpinRule is the iptables rule dropping traffic routed from bridge back
+// into the same bridge.
+func hairpinRule(bridge string) []string {
+	return []string{"FORWARD", "-i", bridge, "-o", bridge, "-j", "DROP"}
+}
+
+// SandboxRunFunc runs a shell command in a sandbox and returns its exit code
+// and combined output.
+type SandboxRunFunc func(ctx context.Context, sandboxID, command string) (exitCode int, output string, err error)
+
+// IsolationCheck is the result of VerifyIsolation.
+type IsolationCheck struct {
+	From     string // sandbox the ping was sent from
+	To       string // sandbox the ping was sent to
+	TargetIP string
+	Blocked  bool // true when the ping got no reply
+	Output   string
+}
+
+// VerifyIsolation pings toIP, the address of sandbox to, from inside
+// sandbox from and reports whether the ping was blocked. Under
+// bridge-private a reply means isolation is broken. A ping that could not
+// run at all (no ping binary, bad address) is an error rather than a pass.
+func VerifyIsolation(ctx context.Context, run SandboxRunFunc, from, to, toIP string) (*IsolationCheck, error) {
+	if toIP == "" {
+		return nil, fmt.Errorf("sandbox %s has no IP address", to)
+	}
+	exitCode, output, err := run(ctx, from, "ping -c 1 -W 2 "+toIP)
+	if err != nil {
+		return nil, fmt.Errorf("ping %s from %s: %w", to, from, err)
+	}
+	check := &IsolationCheck{From: from, To: to, TargetIP: toIP, Output: strings.TrimSpace(output)}
+	switch exitCode {
+	case 0:
+		check.Blocked = false
+	case 1:
+		// ping exits 1 when it sent packets but got no reply.
+		check.Blocked = true
+	default:
+		return nil, fmt.Errorf("ping %s from %s exited %d: %s", to, from, exitCode, check.Output)
+	}
+	return check, nil
+}
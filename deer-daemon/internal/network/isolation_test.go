@@ -0,0 +1,147 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func stubRunCmd(t *testing.T, fail func(name string, args []string) error) *[][]string {
+	t.Helper()
+	prevGOOS, prevRun := runtimeGOOS, runCmdFunc
+	t.Cleanup(func() {
+		runtimeGOOS = prevGOOS
+		runCmdFunc = prevRun
+	})
+	runtimeGOOS = "linux"
+	var calls [][]string
+	runCmdFunc = func(_ context.Context, name string, args ...string) error {
+		calls = append(calls, append([]string{name}, args...))
+		if fail != nil {
+			return fail(name, args)
+		}
+		return nil
+	}
+	return &calls
+}
+
+func TestSetIsolationMode(t *testing.T) {
+	stubRunCmd(t, nil)
+	n := NewNetworkManager("br0", nil, "arp", nil)
+	if got := n.IsolationMode(); got != IsolationNone {
+		t.Errorf("default IsolationMode = %q, want none", got)
+	}
+	if err := n.SetIsolationMode("bridge-private"); err != nil {
+		t.Fatalf("SetIsolationMode(bridge-private): %v", err)
+	}
+	if err := n.SetIsolationMode("vlan"); err == nil {
+		t.Error("SetIsolationMode accepted an unknown mode")
+	}
+
+	runtimeGOOS = "darwin"
+	if err := n.SetIsolationMode("bridge-private"); err == nil {
+		t.Error("bridge-private should be refused off Linux")
+	}
+}
+
+func TestIsolateTAPNoneIsNoop(t *testing.T) {
+	calls := stubRunCmd(t, nil)
+	n := NewNetworkManager("br0", nil, "arp", nil)
+	if err := n.IsolateTAP(context.Background(), "fl-a", "br0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.ReleaseTAP(context.Background(), "fl-a"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("mode none ran %v", *calls)
+	}
+}
+
+func TestIsolateTAPBridgePrivate(t *testing.T) {
+	// The hairpin rule is missing, so the -C check fails and it is inserted.
+	calls := stubRunCmd(t, func(name string, args []string) error {
+		if name == "iptables" && args[0] == "-C" {
+			return errors.New("bad rule")
+		}
+		return nil
+	})
+	n := NewNetworkManager("br0", nil, "arp", nil)
+	if err := n.SetIsolationMode(IsolationBridgePrivate); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.IsolateTAP(context.Background(), "fl-a", "br0"); err != nil {
+		t.Fatalf("IsolateTAP: %v", err)
+	}
+	if err := n.ReleaseTAP(context.Background(), "fl-a"); err != nil {
+		t.Fatalf("ReleaseTAP: %v", err)
+	}
+
+	want := [][]string{
+		{"iptables", "-C", "FORWARD", "-i", "br0", "-o", "br0", "-j", "DROP"},
+		{"iptables", "-I", "FORWARD", "-i", "br0", "-o", "br0", "-j", "DROP"},
+		{"ebtables", "-D", "FORWARD", "-i", "fl-a", "-o", "fl-+", "-j", "DROP"},
+		{"ebtables", "-A", "FORWARD", "-i", "fl-a", "-o", "fl-+", "-j", "DROP"},
+		{"ebtables", "-D", "FORWARD", "-i", "fl-a", "-o", "fl-+", "-j", "DROP"},
+	}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Errorf("commands:\n got %v\nwant %v", *calls, want)
+	}
+}
+
+func TestIsolateTAPFailsClosed(t *testing.T) {
+	stubRunCmd(t, func(name string, args []string) error {
+		if name == "ebtables" && args[0] == "-A" {
+			return errors.New("ebtables: executable file not found")
+		}
+		return nil
+	})
+	n := NewNetworkManager("br0", nil, "arp", nil)
+	if err := n.SetIsolationMode(IsolationBridgePrivate); err != nil {
+		t.Fatal(err)
+	}
+	err := n.IsolateTAP(context.Background(), "fl-a", "br0")
+	if err == nil || !strings.Contains(err.Error(), "isolate tap fl-a") {
+		t.Errorf("IsolateTAP error = %v, want an isolate tap error", err)
+	}
+}
+
+func TestVerifyIsolation(t *testing.T) {
+	tests := []struct {
+		name        string
+		exitCode    int
+		runErr      error
+		wantBlocked bool
+		wantErr     bool
+	}{
+		{name: "reply means reachable", exitCode: 0},
+		{name: "no reply means blocked", exitCode: 1, wantBlocked: true},
+		{name: "ping missing is an error", exitCode: 127, wantErr: true},
+		{name: "ssh failure is an error", runErr: errors.New("connection refused"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCmd, gotSandbox string
+			run := func(_ context.Context, sandboxID, command string) (int, string, error) {
+				gotSandbox, gotCmd = sandboxID, command
+				return tt.exitCode, "ping output\n", tt.runErr
+			}
+			check, err := VerifyIsolation(context.Background(), run, "SBX-a", "SBX-b", "10.0.0.12")
+			if gotSandbox != "SBX-a" || gotCmd != "ping -c 1 -W 2 10.0.0.12" {
+				t.Errorf("ran %q in %s", gotCmd, gotSandbox)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && check.Blocked != tt.wantBlocked {
+				t.Errorf("Blocked = %v, want %v", check.Blocked, tt.wantBlocked)
+			}
+		})
+	}
+
+	if _, err := VerifyIsolation(context.Background(), nil, "SBX-a", "SBX-b", ""); err == nil {
+		t.Error("VerifyIsolation with no target IP should fail")
+	}
+}
@@ -50,7 +50,7 @@ func TAPName(sandboxID string) string {
 	if len(id) > 12 {
 		id = id[len(id)-12:]
 	}
-	return "fl-" + strings.ToLower(id)
+	return tapPrefix + strings.ToLower(id)
 }
 
 func runCmd(ctx context.Context, name string, args ...string) error {
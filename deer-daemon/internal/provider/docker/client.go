@@ -0,0 +1,336 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// errNotFound is returned (wrapped) when the engine answers 404.
+var errNotFound = errors.New("not found")
+
+// Client is a minimal HTTP client for the Docker Engine API. Podman serves
+// the same API on its compatibility socket.
+type Client struct {
+	baseURL    string
+	timeout    time.Duration
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the engine at cfg.Host, which must already
+// be validated.
+func NewClient(cfg Config) (*Client, error) {
+	u, err := url.Parse(cfg.Host)
+	if err != nil {
+		return nil, fmt.Errorf("parse docker host: %w", err)
+	}
+	transport := &http.Transport{}
+	baseURL := "http://" + u.Host
+	if u.Scheme == "unix" {
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		baseURL = "http://docker"
+	}
+	// No client-wide timeout: execs and pulls run as long as their context
+	// allows, and other calls are bounded by c.timeout in do.
+	return &Client{
+		baseURL:    baseURL,
+		timeout:    cfg.Timeout,
+		httpClient: &http.Client{Transport: transport},
+	}, nil
+}
+
+// request sends method path with an optional JSON body and returns the
+// response for the caller to read. Non-2xx responses are returned as errors.
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	apiURL := c.baseURL + path
+	if len(query) > 0 {
+		apiURL += "?" + query.Encode()
+	}
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
+		msg := apiMessage(resp.Body)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("API %s %s: %s: %w", method, path, msg, errNotFound)
+		}
+		return nil, fmt.Errorf("API %s %s returned %d: %s", method, path, resp.StatusCode, msg)
+	}
+	return resp, nil
+}
+
+// do sends a request bounded by the client timeout and decodes the JSON
+// response into out, if out is non-nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := c.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// apiMessage extracts the "message" field from an engine error body.
+func apiMessage(r io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(r, 64*1024))
+	var e struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &e) == nil && e.Message != "" {
+		return e.Message
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Ping checks that the engine is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+}
+
+// Info returns engine-wide information such as CPU count and memory.
+func (c *Client) Info(ctx context.Context) (*Info, error) {
+	var info Info
+	if err := c.do(ctx, http.MethodGet, "/info", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListImages returns the images stored by the engine, limited by filters
+// (such as "label" or "reference") when non-nil.
+func (c *Client) ListImages(ctx context.Context, filters map[string][]string) ([]ImageSummary, error) {
+	var query url.Values
+	if filters != nil {
+		data, _ := json.Marshal(filters)
+		query = url.Values{"filters": {string(data)}}
+	}
+	var images []ImageSummary
+	if err := c.do(ctx, http.MethodGet, "/images/json", query, nil, &images); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// RemoveImage deletes the image ref. An image still used by a container is
+// not removed and returns an error.
+func (c *Client) RemoveImage(ctx context.Context, ref string) error {
+	return c.do(ctx, http.MethodDelete, "/images/"+ref, nil, nil, nil)
+}
+
+// ImageExists reports whether ref is present locally.
+func (c *Client) ImageExists(ctx context.Context, ref string) (bool, error) {
+	err := c.do(ctx, http.MethodGet, "/images/"+ref+"/json", nil, nil, nil)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PullImage pulls ref, waiting until the pull finishes. The engine reports
+// pull failures inside the progress stream, not through the status code.
+func (c *Client) PullImage(ctx context.Context, ref string) error {
+	image, tag := splitImageRef(ref)
+	query := url.Values{"fromImage": {image}, "tag": {tag}}
+	resp, err := c.request(ctx, http.MethodPost, "/images/create", query, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("read pull progress: %w", err)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("pull %s: %s", ref, msg.Error)
+		}
+	}
+}
+
+// splitImageRef splits "repo:tag" into repo and tag, defaulting the tag to
+// "latest". A colon in a registry host ("host:5000/repo") is not a tag.
+func splitImageRef(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref, ""
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// ListContainers returns all containers, running or not, carrying label.
+func (c *Client) ListContainers(ctx context.Context, label string) ([]ContainerSummary, error) {
+	filters, _ := json.Marshal(map[string][]string{"label": {label}})
+	query := url.Values{"all": {"1"}, "filters": {string(filters)}}
+	var containers []ContainerSummary
+	if err := c.do(ctx, http.MethodGet, "/containers/json", query, nil, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// CreateContainer creates a container named name and returns its ID.
+func (c *Client) CreateContainer(ctx context.Context, name string, spec ContainerCreate) (string, error) {
+	var resp struct {
+		ID string `json:"Id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/containers/create", url.Values{"name": {name}}, spec, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// StartContainer starts a container. Starting a running container is not
+// an error.
+func (c *Client) StartContainer(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/containers/"+id+"/start", nil, nil, nil)
+}
+
+// StopContainer stops a container, killing it after grace.
+func (c *Client) StopContainer(ctx context.Context, id string, grace time.Duration) error {
+	query := url.Values{"t": {fmt.Sprintf("%d", int(grace.Seconds()))}}
+	return c.do(ctx, http.MethodPost, "/containers/"+id+"/stop", query, nil, nil)
+}
+
+// KillContainer kills a container immediately.
+func (c *Client) KillContainer(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/containers/"+id+"/kill", nil, nil, nil)
+}
+
+// RemoveContainer force-removes a container and its anonymous volumes.
+func (c *Client) RemoveContainer(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/containers/"+id, url.Values{"force": {"1"}, "v": {"1"}}, nil, nil)
+}
+
+// InspectContainer returns a container's current state and settings.
+func (c *Client) InspectContainer(ctx context.Context, id string) (*ContainerJSON, error) {
+	var ctr ContainerJSON
+	if err := c.do(ctx, http.MethodGet, "/containers/"+id+"/json", nil, nil, &ctr); err != nil {
+		return nil, err
+	}
+	return &ctr, nil
+}
+
+// Commit saves a container's filesystem as the image repo:tag and returns
+// the image ID. changes are Dockerfile instructions, such as LABEL, applied
+// to the image.
+func (c *Client) Commit(ctx context.Context, id, repo, tag string, changes ...string) (string, error) {
+	var resp struct {
+		ID string `json:"Id"`
+	}
+	query := url.Values{"container": {id}, "repo": {repo}, "tag": {tag}}
+	for _, change := range changes {
+		query.Add("changes", change)
+	}
+	if err := c.do(ctx, http.MethodPost, "/commit", query, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// Exec runs cmd in a running container, with env added to its environment,
+// and returns its exit code. Output is passed to onOutput as it arrives.
+// The exec runs until ctx is done; the engine has no call to stop an exec,
+// so a process that outlives ctx keeps running in the container until the
+// caller kills it.
+func (c *Client) Exec(ctx context.Context, id string, cmd, env []string, onOutput func(chunk []byte, isStderr bool)) (int, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	spec := ExecCreate{AttachStdout: true, AttachStderr: true, Cmd: cmd, Env: env}
+	if err := c.do(ctx, http.MethodPost, "/containers/"+id+"/exec", nil, spec, &created); err != nil {
+		return -1, err
+	}
+
+	resp, err := c.request(ctx, http.MethodPost, "/exec/"+created.ID+"/start", nil, map[string]bool{"Detach": false, "Tty": false})
+	if err != nil {
+		return -1, err
+	}
+	err = demux(resp.Body, onOutput)
+	_ = resp.Body.Close()
+	if err != nil {
+		if ctx.Err() != nil {
+			return -1, ctx.Err()
+		}
+		return -1, fmt.Errorf("read exec output: %w", err)
+	}
+
+	var inspect ExecInspect
+	if err := c.do(ctx, http.MethodGet, "/exec/"+created.ID+"/json", nil, nil, &inspect); err != nil {
+		return -1, err
+	}
+	return inspect.ExitCode, nil
+}
+
+// demux splits the engine's multiplexed exec stream into stdout and stderr
+// chunks. Each frame is an 8-byte header - stream type, three zero bytes,
+// and a big-endian payload length - followed by the payload.
+func demux(r io.Reader, onOutput func(chunk []byte, isStderr bool)) error {
+	br := bufio.NewReader(r)
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		size := binary.BigEndian.Uint32(header[4:])
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return err
+		}
+		switch header[0] {
+		case 1:
+			onOutput(payload, false)
+		case 2:
+			onOutput(payload, true)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// frame encodes payload as one frame of the engine's multiplexed stream.
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func testClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cfg := Config{Host: srv.URL, Timeout: 10 * time.Second}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+	return client
+}
+
+func TestDemux(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(frame(1, "hello "))
+	stream.Write(frame(2, "oops"))
+	stream.Write(frame(1, "world"))
+
+	var stdout, stderr bytes.Buffer
+	err := demux(&stream, func(chunk []byte, isStderr bool) {
+		if isStderr {
+			stderr.Write(chunk)
+		} else {
+			stdout.Write(chunk)
+		}
+	})
+	if err != nil {
+		t.Fatalf("demux() error: %v", err)
+	}
+	if stdout.String() != "hello world" || stderr.String() != "oops" {
+		t.Errorf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestDemux_TruncatedFrame(t *testing.T) {
+	data := frame(1, "hello")
+	err := demux(bytes.NewReader(data[:len(data)-2]), func([]byte, bool) {})
+	if err == nil {
+		t.Error("demux() of a truncated frame succeeded")
+	}
+}
+
+func TestSplitImageRef(t *testing.T) {
+	tests := []struct{ ref, image, tag string }{
+		{"ubuntu", "ubuntu", "latest"},
+		{"ubuntu:24.04", "ubuntu", "24.04"},
+		{"registry:5000/team/app", "registry:5000/team/app", "latest"},
+		{"registry:5000/team/app:v2", "registry:5000/team/app", "v2"},
+		{"alpine@sha256:abc", "alpine@sha256:abc", ""},
+	}
+	for _, tt := range tests {
+		image, tag := splitImageRef(tt.ref)
+		if image != tt.image || tag != tt.tag {
+			t.Errorf("splitImageRef(%q) = %q, %q; want %q, %q", tt.ref, image, tag, tt.image, tt.tag)
+		}
+	}
+}
+
+func TestClient_NotFound(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"No such container: abc"}`))
+	}))
+
+	_, err := client.InspectContainer(context.Background(), "abc")
+	if !errors.Is(err, errNotFound) {
+		t.Errorf("InspectContainer() error = %v, want errNotFound", err)
+	}
+	exists, err := client.ImageExists(context.Background(), "ubuntu")
+	if err != nil || exists {
+		t.Errorf("ImageExists() = %v, %v; want false, nil", exists, err)
+	}
+}
+
+func TestClient_PullImageError(t *testing.T) {
+	client := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fromImage") != "nosuch" || r.URL.Query().Get("tag") != "latest" {
+			t.Errorf("pull query = %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"status":"Pulling from library/nosuch"}` + "\n" + `{"error":"manifest unknown"}` + "\n"))
+	}))
+
+	err := client.PullImage(context.Background(), "nosuch")
+	if err == nil {
+		t.Fatal("PullImage() succeeded despite an error in the progress stream")
+	}
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultHost is the Docker Engine socket used when Config.Host is empty.
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// Config holds settings for running sandboxes as Docker or Podman
+// containers. Podman is used through its Docker-compatible API socket.
+type Config struct {
+	Host        string        `yaml:"host"`         // Engine API endpoint, e.g. "unix:///var/run/docker.sock", "unix:///run/podman/podman.sock", or "tcp://127.0.0.1:2375"
+	Network     string        `yaml:"network"`      // Network to attach sandboxes to (default: the engine's default network)
+	Timeout     time.Duration `yaml:"timeout"`      // Timeout for API calls other than image pulls and command execs
+	StopTimeout time.Duration `yaml:"stop_timeout"` // How long a graceful stop waits before the container is killed
+}
+
+// Validate checks the config and applies defaults.
+func (c *Config) Validate() error {
+	if c.Host == "" {
+		c.Host = DefaultHost
+	}
+	u, err := url.Parse(c.Host)
+	if err != nil {
+		return fmt.Errorf("docker host %q: %w", c.Host, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		if u.Path == "" {
+			return fmt.Errorf("docker host %q has no socket path", c.Host)
+		}
+	case "tcp", "http":
+		if u.Host == "" {
+			return fmt.Errorf("docker host %q has no address", c.Host)
+		}
+	default:
+		return fmt.Errorf("docker host %q: unsupported scheme %q (want unix, tcp, or http)", c.Host, u.Scheme)
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 2 * time.Minute
+	}
+	if c.StopTimeout == 0 {
+		c.StopTimeout = 10 * time.Second
+	}
+	return nil
+}
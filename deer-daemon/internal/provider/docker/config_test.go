@@ -0,0 +1,39 @@
+package docker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigValidate_Defaults(t *testing.T) {
+	var cfg Config
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if cfg.Host != DefaultHost {
+		t.Errorf("Host = %q, want %q", cfg.Host, DefaultHost)
+	}
+	if cfg.Timeout != 2*time.Minute || cfg.StopTimeout != 10*time.Second {
+		t.Errorf("Timeout = %s, StopTimeout = %s", cfg.Timeout, cfg.StopTimeout)
+	}
+}
+
+func TestConfigValidate_Hosts(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr bool
+	}{
+		{"unix:///run/podman/podman.sock", false},
+		{"tcp://127.0.0.1:2375", false},
+		{"http://docker.internal:2375", false},
+		{"unix://", true},
+		{"ssh://user@host", true},
+		{"tcp://", true},
+	}
+	for _, tt := range tests {
+		cfg := Config{Host: tt.host}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+// Package docker implements the SandboxProvider interface with Docker or
+// Podman containers. A sandbox is a container created from an image and
+// kept running; commands run through the engine's exec API rather than SSH,
+// so no virtualization or guest SSH server is needed.
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/id"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/shellutil"
+)
+
+// sandboxLabel marks containers created by deer and holds their sandbox ID,
+// so RecoverState can find them after a restart.
+const sandboxLabel = "sh.deer.sandbox-id"
+
+// snapshotRepo is the image repository snapshots are committed to; each
+// sandbox's snapshots are tagged "<sandbox-id>-<name>".
+const snapshotRepo = "deer-snapshot"
+
+// execMarker is set in the environment of each exec to an ID of its own, so
+// the exec's processes can be found and killed when it times out.
+const execMarker = "DEER_EXEC_ID"
+
+// killTimeout bounds the exec that kills a timed-out command's processes.
+const killTimeout = 10 * time.Second
+
+// keepAlive is the container's main process. The engine's init (Init: true)
+// reaps exec'd processes and forwards the stop signal.
+var keepAlive = []string{"sleep", "infinity"}
+
+// errSourceUnsupported is returned for source VM operations, which have no
+// counterpart when sandboxes are created from images.
+var errSourceUnsupported = errors.New("the docker provider has no source VMs; sandboxes are created from images")
+
+// Provider implements provider.SandboxProvider for Docker/Podman containers.
+type Provider struct {
+	client *Client
+	cfg    Config
+	logger *slog.Logger
+
+	mu sync.Mutex
+	// sandboxID -> container ID for active sandboxes.
+	sandboxes map[string]string
+}
+
+// New creates a new Docker provider.
+func New(cfg Config, logger *slog.Logger) (*Provider, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid docker config: %w", err)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		client:    client,
+		cfg:       cfg,
+		logger:    logger.With("provider", "docker"),
+		sandboxes: make(map[string]string),
+	}, nil
+}
+
+// CheckConfig verifies the engine is reachable so a wrong socket shows up at
+// startup rather than on the first create.
+func (p *Provider) CheckConfig(ctx context.Context) error {
+	if err := p.client.Ping(ctx); err != nil {
+		return fmt.Errorf("reach engine at %s: %w", p.cfg.Host, err)
+	}
+	return nil
+}
+
+// CreateSandbox creates and starts a container from req.BaseImage, or from
+// req.SourceVM taken as an image reference. The image is pulled if it is
+// not present.
+func (p *Provider) CreateSandbox(ctx context.Context, req provider.CreateRequest) (*provider.SandboxResult, error) {
+	image := req.BaseImage
+	if image == "" {
+		image = req.SourceVM
+	}
+	if image == "" {
+		return nil, fmt.Errorf("an image is required: set base_image or source_vm")
+	}
+	if req.WantsKafkaBroker() || req.WantsElasticsearchBroker() || len(req.DataSources) > 0 {
+		return nil, fmt.Errorf("the docker provider does not support data sources or brokers")
+	}
+
+	exists, err := p.client.ImageExists(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("inspect image %q: %w", image, err)
+	}
+	if !exists {
+		p.logger.Info("pulling image", "image", image)
+		if err := p.client.PullImage(ctx, image); err != nil {
+			return nil, err
+		}
+	}
+
+	spec := ContainerCreate{
+		Image:      image,
+		Hostname:   req.SandboxID,
+		Entrypoint: keepAlive,
+		Cmd:        []string{},
+		Labels:     map[string]string{sandboxLabel: req.SandboxID},
+		HostConfig: HostConfig{
+			NanoCPUs:    int64(req.VCPUs) * 1e9,
+			Memory:      int64(req.MemoryMB) * 1024 * 1024,
+			Init:        true,
+			NetworkMode: p.cfg.Network,
+		},
+	}
+	p.logger.Info("creating container", "sandbox_id", req.SandboxID, "image", image)
+	containerID, err := p.client.CreateContainer(ctx, req.SandboxID, spec)
+	if err != nil {
+		return nil, fmt.Errorf("create container: %w", err)
+	}
+	if err := p.client.StartContainer(ctx, containerID); err != nil {
+		if rmErr := p.client.RemoveContainer(context.Background(), containerID); rmErr != nil {
+			p.logger.Warn("remove unstarted container failed", "container", containerID, "error", rmErr)
+		}
+		return nil, fmt.Errorf("start container: %w", err)
+	}
+
+	p.mu.Lock()
+	p.sandboxes[req.SandboxID] = containerID
+	p.mu.Unlock()
+
+	return p.result(ctx, req.SandboxID, containerID)
+}
+
+func (p *Provider) DestroySandbox(ctx context.Context, sandboxID string) error {
+	containerID, err := p.getContainer(sandboxID)
+	if err != nil {
+		return err
+	}
+	err = p.client.RemoveContainer(ctx, containerID)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return fmt.Errorf("remove container: %w", err)
+	}
+
+	p.mu.Lock()
+	delete(p.sandboxes, sandboxID)
+	p.mu.Unlock()
+
+	p.removeSnapshotImages(ctx, sandboxID)
+
+	if err != nil {
+		return fmt.Errorf("container for %s: %w", sandboxID, provider.ErrSandboxNotFound)
+	}
+	return nil
+}
+
+func (p *Provider) StartSandbox(ctx context.Context, sandboxID string) (*provider.SandboxResult, error) {
+	containerID, err := p.getContainer(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.client.StartContainer(ctx, containerID); err != nil {
+		return nil, containerError("start container", sandboxID, err)
+	}
+	return p.result(ctx, sandboxID, containerID)
+}
+
+func (p *Provider) StopSandbox(ctx context.Context, sandboxID string, force bool) error {
+	containerID, err := p.getContainer(sandboxID)
+	if err != nil {
+		return err
+	}
+	if force {
+		err = p.client.KillContainer(ctx, containerID)
+	} else {
+		err = p.client.StopContainer(ctx, containerID, p.cfg.StopTimeout)
+	}
+	if err != nil {
+		return containerError("stop container", sandboxID, err)
+	}
+	return nil
+}
+
+func (p *Provider) GetSandboxIP(ctx context.Context, sandboxID string) (string, error) {
+	containerID, err := p.getContainer(sandboxID)
+	if err != nil {
+		return "", err
+	}
+	ctr, err := p.client.InspectContainer(ctx, containerID)
+	if err != nil {
+		return "", containerError("inspect container", sandboxID, err)
+	}
+	ip, _ := containerAddress(ctr)
+	if ip == "" {
+		return "", fmt.Errorf("container for %s has no IP address", sandboxID)
+	}
+	return ip, nil
+}
+
+// CreateSnapshot commits the container's filesystem to an image. Running
+// processes and memory are not captured.
+func (p *Provider) CreateSnapshot(ctx context.Context, sandboxID, name string) (*provider.SnapshotResult, error) {
+	containerID, err := p.getContainer(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	imageID, err := p.client.Commit(ctx, containerID, snapshotRepo, snapshotTag(sandboxID, name), "LABEL "+sandboxLabel+"="+sandboxID)
+	if err != nil {
+		return nil, containerError("commit container", sandboxID, err)
+	}
+	return &provider.SnapshotResult{SnapshotID: imageID, SnapshotName: name}, nil
+}
+
+// RunCommand runs command with sh -c through the engine's exec API.
+func (p *Provider) RunCommand(ctx context.Context, sandboxID, command string, timeout time.Duration) (*provider.CommandResult, error) {
+	var stdout, stderr bytes.Buffer
+	return p.exec(ctx, sandboxID, command, timeout, func(chunk []byte, isStderr bool) {
+		if isStderr {
+			stderr.Write(chunk)
+		} else {
+			stdout.Write(chunk)
+		}
+	}, &stdout, &stderr)
+}
+
+// RunCommandStreaming runs command like RunCommand and passes its output to
+// onOutput as it arrives. SSH options have no meaning for an exec and are
+// rejected.
+func (p *Provider) RunCommandStreaming(ctx context.Context, sandboxID, command string, timeout time.Duration, opts provider.CommandOptions, onOutput provider.OutputFunc) (*provider.CommandResult, error) {
+	if !opts.IsZero() {
+		return nil, provider.ErrCommandOptionsUnsupported
+	}
+	var stdout, stderr bytes.Buffer
+	return p.exec(ctx, sandboxID, command, timeout, func(chunk []byte, isStderr bool) {
+		if isStderr {
+			stderr.Write(chunk)
+		} else {
+			stdout.Write(chunk)
+		}
+		onOutput(chunk, isStderr)
+	}, &stdout, &stderr)
+}
+
+// exec runs command in the sandbox's container, passing output to onOutput,
+// and builds the result from the buffers onOutput fills.
+func (p *Provider) exec(ctx context.Context, sandboxID, command string, timeout time.Duration, onOutput func([]byte, bool), stdout, stderr *bytes.Buffer) (*provider.CommandResult, error) {
+	containerID, err := p.getContainer(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	execID, err := id.GenerateRaw()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	exitCode, err := p.client.Exec(ctx, containerID, []string{"sh", "-c", command}, []string{execMarker + "=" + execID}, onOutput)
+	if err != nil {
+		if ctx.Err() != nil {
+			p.killExec(containerID, execID)
+		}
+		return nil, containerError("exec in container", sandboxID, err)
+	}
+	return &provider.CommandResult{
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		ExitCode:   exitCode,
+		DurationMS: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// killExec kills every process in the container started by the exec with
+// execID: the command and anything it spawned, which inherit its
+// environment. Failures are logged; the command has already timed out.
+func (p *Provider) killExec(containerID, execID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), killTimeout)
+	defer cancel()
+	script := `for d in /proc/[0-9]*; do tr '\0' '\n' < "$d/environ" 2>/dev/null | grep -qx ` +
+		shellutil.Quote(execMarker+"="+execID) + ` && kill -KILL "${d#/proc/}" 2>/dev/null; done; true`
+	if _, err := p.client.Exec(ctx, containerID, []string{"sh", "-c", script}, nil, func([]byte, bool) {}); err != nil {
+		p.logger.Warn("kill timed-out exec failed", "container", containerID, "exec", execID, "error", err)
+	}
+}
+
+// removeSnapshotImages deletes the images committed by CreateSnapshot for a
+// destroyed sandbox. An image still used by another sandbox, such as a fork,
+// is kept and logged.
+func (p *Provider) removeSnapshotImages(ctx context.Context, sandboxID string) {
+	images, err := p.client.ListImages(ctx, map[string][]string{
+		"label":     {sandboxLabel + "=" + sandboxID},
+		"reference": {snapshotRepo},
+	})
+	if err != nil {
+		p.logger.Warn("list snapshot images failed", "sandbox_id", sandboxID, "error", err)
+		return
+	}
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			if !strings.HasPrefix(tag, snapshotRepo+":") {
+				continue
+			}
+			if err := p.client.RemoveImage(ctx, tag); err != nil {
+				p.logger.Warn("remove snapshot image failed", "sandbox_id", sandboxID, "image", tag, "error", err)
+			}
+		}
+	}
+}
+
+// ListTemplates lists the tagged local images, excluding snapshots.
+func (p *Provider) ListTemplates(ctx context.Context) ([]string, error) {
+	images, err := p.client.ListImages(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list images: %w", err)
+	}
+	var names []string
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			if tag == "<none>:<none>" || strings.HasPrefix(tag, snapshotRepo+":") {
+				continue
+			}
+			names = append(names, tag)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListSourceVMs lists local images, which play the part of source VMs.
+func (p *Provider) ListSourceVMs(ctx context.Context) ([]provider.SourceVMInfo, error) {
+	names, err := p.ListTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vms := make([]provider.SourceVMInfo, 0, len(names))
+	for _, name := range names {
+		vms = append(vms, provider.SourceVMInfo{Name: name, State: "image"})
+	}
+	return vms, nil
+}
+
+// ValidateSourceVM checks that vmName is a local image. A missing image is
+// only a warning, since CreateSandbox pulls it.
+func (p *Provider) ValidateSourceVM(ctx context.Context, vmName string) (*provider.ValidationResult, error) {
+	result := &provider.ValidationResult{VMName: vmName, HasNetwork: true}
+	exists, err := p.client.ImageExists(ctx, vmName)
+	switch {
+	case err != nil:
+		result.AddError(provider.IssueStatusUnavailable, fmt.Sprintf("could not inspect image: %v", err))
+	case !exists:
+		result.AddWarning(provider.IssueVMNotFound, fmt.Sprintf("image %q is not present locally; it will be pulled on the first create", vmName))
+		result.State = "absent"
+	default:
+		result.State = "image"
+	}
+	result.Valid = len(result.Errors) == 0
+	return result, nil
+}
+
+func (p *Provider) PrepareSourceVM(ctx context.Context, vmName, sshUser, sshKeyPath string) (*provider.PrepareResult, error) {
+	return nil, errSourceUnsupported
+}
+
+func (p *Provider) RunSourceCommand(ctx context.Context, vmName, command string, timeout time.Duration) (*provider.CommandResult, error) {
+	return nil, errSourceUnsupported
+}
+
+func (p *Provider) ReadSourceFile(ctx context.Context, vmName, path string) (string, error) {
+	return "", errSourceUnsupported
+}
+
+// Capabilities reports the engine's CPUs and memory. The engine does not
+// report free memory, so all of it is listed as available.
+func (p *Provider) Capabilities(ctx context.Context) (*provider.HostCapabilities, error) {
+	info, err := p.client.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get engine info: %w", err)
+	}
+	memMB := int(info.MemTotal / (1024 * 1024))
+	caps := &provider.HostCapabilities{
+		TotalCPUs:      info.NCPU,
+		AvailableCPUs:  info.NCPU,
+		TotalMemoryMB:  memMB,
+		AvailableMemMB: memMB,
+	}
+	caps.BaseImages, _ = p.ListTemplates(ctx)
+	return caps, nil
+}
+
+func (p *Provider) ActiveSandboxCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.sandboxes)
+}
+
+// RecoverState tracks every container carrying the sandbox label again.
+func (p *Provider) RecoverState(ctx context.Context) error {
+	containers, err := p.client.ListContainers(ctx, sandboxLabel)
+	if err != nil {
+		return fmt.Errorf("list containers for recovery: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ctr := range containers {
+		sandboxID := ctr.Labels[sandboxLabel]
+		if sandboxID == "" {
+			continue
+		}
+		p.sandboxes[sandboxID] = ctr.ID
+		p.logger.Info("recovered sandbox container", "sandbox_id", sandboxID, "container", ctr.ID, "state", ctr.State)
+	}
+	return nil
+}
+
+// --- Internal helpers ---
+
+// getContainer returns the container ID for a tracked sandbox.
+func (p *Provider) getContainer(sandboxID string) (string, error) {
+	p.mu.Lock()
+	containerID, ok := p.sandboxes[sandboxID]
+	p.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("sandbox %s not tracked: %w", sandboxID, provider.ErrSandboxNotFound)
+	}
+	return containerID, nil
+}
+
+// result inspects a container and describes it as a sandbox.
+func (p *Provider) result(ctx context.Context, sandboxID, containerID string) (*provider.SandboxResult, error) {
+	ctr, err := p.client.InspectContainer(ctx, containerID)
+	if err != nil {
+		return nil, containerError("inspect container", sandboxID, err)
+	}
+	ip, mac := containerAddress(ctr)
+	return &provider.SandboxResult{
+		SandboxID:  sandboxID,
+		Name:       strings.TrimPrefix(ctr.Name, "/"),
+		State:      sandboxState(ctr.State.Status),
+		IPAddress:  ip,
+		MACAddress: mac,
+		Bridge:     p.cfg.Network,
+		PID:        ctr.State.Pid,
+	}, nil
+}
+
+// containerError wraps an engine error, mapping a missing container to
+// provider.ErrSandboxNotFound.
+func containerError(op, sandboxID string, err error) error {
+	if errors.Is(err, errNotFound) {
+		return fmt.Errorf("%s for %s: %w", op, sandboxID, provider.ErrSandboxNotFound)
+	}
+	return fmt.Errorf("%s for %s: %w", op, sandboxID, err)
+}
+
+// sandboxState maps an engine container status to the canonical sandbox
+// state.
+func sandboxState(status string) string {
+	switch status {
+	case "running", "restarting":
+		return "RUNNING"
+	case "created", "paused", "exited", "stopped":
+		return "STOPPED"
+	default: // "dead", "removing", or unknown
+		return "ERROR"
+	}
+}
+
+// containerAddress returns the container's IP and MAC address, preferring
+// the legacy top-level fields and falling back to the first network with an
+// address.
+func containerAddress(ctr *ContainerJSON) (ip, mac string) {
+	if ctr.NetworkSettings.IPAddress != "" {
+		return ctr.NetworkSettings.IPAddress, ctr.NetworkSettings.MacAddress
+	}
+	names := make([]string, 0, len(ctr.NetworkSettings.Networks))
+	for name := range ctr.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if ep := ctr.NetworkSettings.Networks[name]; ep.IPAddress != "" {
+			return ep.IPAddress, ep.MacAddress
+		}
+	}
+	return "", ""
+}
+
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// snapshotTag builds a valid image tag (at most 128 characters of
+// [A-Za-z0-9_.-]) from a sandbox ID and snapshot name.
+func snapshotTag(sandboxID, name string) string {
+	tag := invalidTagChars.ReplaceAllString(sandboxID+"-"+name, "_")
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return tag
+}
@@ -0,0 +1,295 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+)
+
+// fakeEngine serves the parts of the Engine API the provider uses, for one
+// container "ctr-1".
+type fakeEngine struct {
+	mu            sync.Mutex
+	created       *ContainerCreate
+	state         string
+	pulled        bool
+	removed       bool
+	execs         []ExecCreate
+	images        []ImageSummary
+	imageQuery    string
+	removedImages []string
+
+	// hangFirstExec keeps exec-1 running until the client gives up.
+	hangFirstExec bool
+}
+
+func (f *fakeEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.hangFirstExec && r.URL.Path == "/exec/exec-1/start" {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	writeJSON := func(v any) { _ = json.NewEncoder(w).Encode(v) }
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/images/json":
+		f.imageQuery = r.URL.Query().Get("filters")
+		writeJSON(f.images)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/images/"):
+		f.removedImages = append(f.removedImages, strings.TrimPrefix(r.URL.Path, "/images/"))
+		writeJSON([]map[string]string{{"Untagged": r.URL.Path}})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/images/") && strings.HasSuffix(r.URL.Path, "/json"):
+		if !f.pulled {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(map[string]string{"Id": "sha256:img"})
+	case r.Method == http.MethodPost && r.URL.Path == "/images/create":
+		f.pulled = true
+		_, _ = w.Write([]byte(`{"status":"Downloaded newer image"}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/containers/create":
+		var spec ContainerCreate
+		_ = json.NewDecoder(r.Body).Decode(&spec)
+		f.created = &spec
+		f.state = "created"
+		writeJSON(map[string]string{"Id": "ctr-1"})
+	case r.Method == http.MethodPost && r.URL.Path == "/containers/ctr-1/start":
+		f.state = "running"
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && (r.URL.Path == "/containers/ctr-1/stop" || r.URL.Path == "/containers/ctr-1/kill"):
+		f.state = "exited"
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete && r.URL.Path == "/containers/ctr-1":
+		if f.removed {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.removed = true
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Path == "/containers/ctr-1/json":
+		ctr := ContainerJSON{ID: "ctr-1", Name: "/sbx-1"}
+		ctr.State.Status = f.state
+		ctr.State.Running = f.state == "running"
+		ctr.NetworkSettings.Networks = map[string]EndpointSetting{"bridge": {IPAddress: "172.17.0.2", MacAddress: "02:42:ac:11:00:02"}}
+		writeJSON(ctr)
+	case r.Method == http.MethodGet && r.URL.Path == "/containers/json":
+		writeJSON([]ContainerSummary{{ID: "ctr-1", State: "running", Labels: map[string]string{sandboxLabel: "sbx-1"}}})
+	case r.Method == http.MethodPost && r.URL.Path == "/containers/ctr-1/exec":
+		var spec ExecCreate
+		_ = json.NewDecoder(r.Body).Decode(&spec)
+		f.execs = append(f.execs, spec)
+		writeJSON(map[string]string{"Id": fmt.Sprintf("exec-%d", len(f.execs))})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/exec/") && strings.HasSuffix(r.URL.Path, "/start"):
+		_, _ = w.Write(frame(1, "nginx is running\n"))
+		_, _ = w.Write(frame(2, "warning: stale pid\n"))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/exec/") && strings.HasSuffix(r.URL.Path, "/json"):
+		writeJSON(ExecInspect{ExitCode: 3})
+	case r.Method == http.MethodPost && r.URL.Path == "/commit":
+		writeJSON(map[string]string{"Id": "sha256:snap"})
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"message":"unexpected ` + r.Method + " " + r.URL.Path + `"}`))
+	}
+}
+
+func testProvider(t *testing.T) (*Provider, *fakeEngine) {
+	t.Helper()
+	engine := &fakeEngine{}
+	srv := httptest.NewServer(engine)
+	t.Cleanup(srv.Close)
+	p, err := New(Config{Host: srv.URL, Timeout: 10 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	return p, engine
+}
+
+func TestCreateSandbox(t *testing.T) {
+	p, engine := testProvider(t)
+
+	res, err := p.CreateSandbox(context.Background(), provider.CreateRequest{
+		SandboxID: "sbx-1",
+		SourceVM:  "ubuntu:24.04",
+		VCPUs:     2,
+		MemoryMB:  512,
+	})
+	if err != nil {
+		t.Fatalf("CreateSandbox() error: %v", err)
+	}
+	if !engine.pulled {
+		t.Error("missing image was not pulled")
+	}
+	spec := engine.created
+	if spec.Image != "ubuntu:24.04" || spec.Labels[sandboxLabel] != "sbx-1" || !spec.HostConfig.Init {
+		t.Errorf("container spec = %+v", spec)
+	}
+	if spec.HostConfig.NanoCPUs != 2e9 || spec.HostConfig.Memory != 512*1024*1024 {
+		t.Errorf("resources = %d nanoCPUs, %d bytes", spec.HostConfig.NanoCPUs, spec.HostConfig.Memory)
+	}
+	if res.State != "RUNNING" || res.IPAddress != "172.17.0.2" || res.Name != "sbx-1" {
+		t.Errorf("result = %+v", res)
+	}
+	if p.ActiveSandboxCount() != 1 {
+		t.Errorf("ActiveSandboxCount() = %d, want 1", p.ActiveSandboxCount())
+	}
+}
+
+func TestCreateSandbox_RejectsBrokers(t *testing.T) {
+	p, _ := testProvider(t)
+
+	_, err := p.CreateSandbox(context.Background(), provider.CreateRequest{
+		SandboxID:   "sbx-1",
+		BaseImage:   "ubuntu",
+		KafkaBroker: &provider.KafkaBrokerConfig{},
+	})
+	if err == nil {
+		t.Error("CreateSandbox() with a Kafka broker succeeded")
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	p, _ := testProvider(t)
+	p.sandboxes["sbx-1"] = "ctr-1"
+
+	res, err := p.RunCommand(context.Background(), "sbx-1", "systemctl status nginx", time.Minute)
+	if err != nil {
+		t.Fatalf("RunCommand() error: %v", err)
+	}
+	if res.Stdout != "nginx is running\n" || res.Stderr != "warning: stale pid\n" || res.ExitCode != 3 {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestRunCommandStreaming(t *testing.T) {
+	p, _ := testProvider(t)
+	p.sandboxes["sbx-1"] = "ctr-1"
+
+	var chunks []string
+	res, err := provider.RunCommandStreaming(context.Background(), p, "sbx-1", "true", time.Minute, provider.CommandOptions{}, func(chunk []byte, isStderr bool) {
+		chunks = append(chunks, string(chunk))
+	})
+	if err != nil {
+		t.Fatalf("RunCommandStreaming() error: %v", err)
+	}
+	if len(chunks) != 2 || res.ExitCode != 3 {
+		t.Errorf("chunks = %q, exit = %d", chunks, res.ExitCode)
+	}
+
+	_, err = p.RunCommandStreaming(context.Background(), "sbx-1", "true", time.Minute, provider.CommandOptions{ForwardAgent: true}, func([]byte, bool) {})
+	if !errors.Is(err, provider.ErrCommandOptionsUnsupported) {
+		t.Errorf("RunCommandStreaming() with SSH options error = %v, want ErrCommandOptionsUnsupported", err)
+	}
+}
+
+func TestRunCommand_TimeoutKillsExec(t *testing.T) {
+	p, engine := testProvider(t)
+	p.sandboxes["sbx-1"] = "ctr-1"
+	engine.hangFirstExec = true
+
+	_, err := p.RunCommand(context.Background(), "sbx-1", "sleep 600", 100*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunCommand() error = %v, want DeadlineExceeded", err)
+	}
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	if len(engine.execs) != 2 {
+		t.Fatalf("execs = %d, want the command and a kill", len(engine.execs))
+	}
+	marker := engine.execs[0].Env
+	if len(marker) != 1 || !strings.HasPrefix(marker[0], execMarker+"=") {
+		t.Fatalf("command env = %q, want an exec marker", marker)
+	}
+	if kill := strings.Join(engine.execs[1].Cmd, " "); !strings.Contains(kill, marker[0]) || !strings.Contains(kill, "kill -KILL") {
+		t.Errorf("kill exec = %q, want it to kill processes carrying %s", kill, marker[0])
+	}
+}
+
+func TestDestroySandbox_RemovesSnapshotImages(t *testing.T) {
+	p, engine := testProvider(t)
+	p.sandboxes["sbx-1"] = "ctr-1"
+	engine.images = []ImageSummary{{ID: "sha256:snap", RepoTags: []string{"deer-snapshot:sbx-1-before", "other:latest"}}}
+
+	if err := p.DestroySandbox(context.Background(), "sbx-1"); err != nil {
+		t.Fatalf("DestroySandbox() error: %v", err)
+	}
+	if !strings.Contains(engine.imageQuery, sandboxLabel+"=sbx-1") {
+		t.Errorf("image filters = %s, want the sandbox label", engine.imageQuery)
+	}
+	if len(engine.removedImages) != 1 || engine.removedImages[0] != "deer-snapshot:sbx-1-before" {
+		t.Errorf("removed images = %q, want only the snapshot tag", engine.removedImages)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	p, engine := testProvider(t)
+	ctx := context.Background()
+	if err := p.RecoverState(ctx); err != nil {
+		t.Fatalf("RecoverState() error: %v", err)
+	}
+	engine.state = "running"
+
+	if err := p.StopSandbox(ctx, "sbx-1", false); err != nil {
+		t.Fatalf("StopSandbox() error: %v", err)
+	}
+	res, err := p.StartSandbox(ctx, "sbx-1")
+	if err != nil || res.State != "RUNNING" {
+		t.Fatalf("StartSandbox() = %+v, %v", res, err)
+	}
+	snap, err := p.CreateSnapshot(ctx, "sbx-1", "before upgrade")
+	if err != nil || snap.SnapshotID != "sha256:snap" {
+		t.Fatalf("CreateSnapshot() = %+v, %v", snap, err)
+	}
+	if err := p.DestroySandbox(ctx, "sbx-1"); err != nil {
+		t.Fatalf("DestroySandbox() error: %v", err)
+	}
+	if err := p.DestroySandbox(ctx, "sbx-1"); !errors.Is(err, provider.ErrSandboxNotFound) {
+		t.Errorf("second DestroySandbox() error = %v, want ErrSandboxNotFound", err)
+	}
+}
+
+func TestSandboxState(t *testing.T) {
+	tests := map[string]string{
+		"running":    "RUNNING",
+		"restarting": "RUNNING",
+		"created":    "STOPPED",
+		"paused":     "STOPPED",
+		"exited":     "STOPPED",
+		"stopped":    "STOPPED",
+		"dead":       "ERROR",
+		"removing":   "ERROR",
+		"bogus":      "ERROR",
+	}
+	for status, want := range tests {
+		if got := sandboxState(status); got != want {
+			t.Errorf("sandboxState(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestSnapshotTag(t *testing.T) {
+	if got := snapshotTag("sbx-1", "before upgrade/v2"); got != "sbx-1-before_upgrade_v2" {
+		t.Errorf("snapshotTag() = %q", got)
+	}
+	if got := snapshotTag("sbx-1", strings.Repeat("a", 200)); len(got) != 128 {
+		t.Errorf("snapshotTag() length = %d, want 128", len(got))
+	}
+}
+
+func TestSourceOperationsUnsupported(t *testing.T) {
+	p, _ := testProvider(t)
+	if _, err := p.RunSourceCommand(context.Background(), "ubuntu", "ls", 0); !errors.Is(err, errSourceUnsupported) {
+		t.Errorf("RunSourceCommand() error = %v", err)
+	}
+}
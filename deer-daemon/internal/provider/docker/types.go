@@ -0,0 +1,82 @@
+package docker
+
+// Info is the subset of GET /info the provider uses.
+type Info struct {
+	Name          string `json:"Name"`
+	ServerVersion string `json:"ServerVersion"`
+	NCPU          int    `json:"NCPU"`
+	MemTotal      int64  `json:"MemTotal"`
+}
+
+// ImageSummary is one entry from GET /images/json.
+type ImageSummary struct {
+	ID       string   `json:"Id"`
+	RepoTags []string `json:"RepoTags"`
+}
+
+// ContainerSummary is one entry from GET /containers/json.
+type ContainerSummary struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"` // "created", "running", "exited", ...
+	Labels map[string]string `json:"Labels"`
+}
+
+// ContainerJSON is the subset of GET /containers/{id}/json the provider uses.
+type ContainerJSON struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State struct {
+		Status  string `json:"Status"`
+		Running bool   `json:"Running"`
+		Pid     int    `json:"Pid"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	NetworkSettings struct {
+		IPAddress  string                     `json:"IPAddress"`
+		MacAddress string                     `json:"MacAddress"`
+		Networks   map[string]EndpointSetting `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// EndpointSetting is a container's attachment to one network.
+type EndpointSetting struct {
+	IPAddress  string `json:"IPAddress"`
+	MacAddress string `json:"MacAddress"`
+}
+
+// ContainerCreate is the body of POST /containers/create.
+type ContainerCreate struct {
+	Image      string            `json:"Image"`
+	Hostname   string            `json:"Hostname,omitempty"`
+	Entrypoint []string          `json:"Entrypoint"`
+	Cmd        []string          `json:"Cmd"`
+	Labels     map[string]string `json:"Labels,omitempty"`
+	HostConfig HostConfig        `json:"HostConfig"`
+}
+
+// HostConfig is the resource and network part of ContainerCreate.
+type HostConfig struct {
+	NanoCPUs    int64  `json:"NanoCpus,omitempty"`
+	Memory      int64  `json:"Memory,omitempty"`
+	Init        bool   `json:"Init"`
+	NetworkMode string `json:"NetworkMode,omitempty"`
+}
+
+// ExecCreate is the body of POST /containers/{id}/exec.
+type ExecCreate struct {
+	AttachStdout bool     `json:"AttachStdout"`
+	AttachStderr bool     `json:"AttachStderr"`
+	Cmd          []string `json:"Cmd"`
+	Env          []string `json:"Env,omitempty"`
+}
+
+// ExecInspect is the subset of GET /exec/{id}/json the provider uses.
+type ExecInspect struct {
+	Running  bool `json:"Running"`
+	ExitCode int  `json:"ExitCode"`
+}
@@ -13,6 +13,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
 )
 
 // Client is an HTTP client for the Proxmox VE LXC API.
@@ -193,6 +195,11 @@ func (c *Client) SetCTConfig(ctx context.Context, vmid int, params url.Values) e
 
 // CloneCT clones a container. Returns the UPID of the clone task.
 func (c *Client) CloneCT(ctx context.Context, sourceVMID, newVMID int, hostname string, full bool) (string, error) {
+	return c.cloneCT(ctx, sourceVMID, newVMID, hostname, full, "")
+}
+
+// cloneCT clones a container, as it was at snapshot snapname when set.
+func (c *Client) cloneCT(ctx context.Context, sourceVMID, newVMID int, hostname string, full bool, snapname string) (string, error) {
 	path := fmt.Sprintf("/nodes/%s/lxc/%d/clone", c.node, sourceVMID)
 	params := url.Values{
 		"newid":    {fmt.Sprintf("%d", newVMID)},
@@ -201,6 +208,9 @@ func (c *Client) CloneCT(ctx context.Context, sourceVMID, newVMID int, hostname
 	if full {
 		params.Set("full", "1")
 	}
+	if snapname != "" {
+		params.Set("snapname", snapname)
+	}
 
 	data, err := c.do(ctx, http.MethodPost, path, params)
 	if err != nil {
@@ -312,6 +322,45 @@ func (c *Client) CreateSnapshot(ctx context.Context, vmid int, name string) (str
 	return upid, nil
 }
 
+// ListSnapshots returns the names of a container's snapshots, leaving out
+// the "current" pseudo-snapshot Proxmox always reports.
+func (c *Client) ListSnapshots(ctx context.Context, vmid int) ([]string, error) {
+	path := fmt.Sprintf("/nodes/%s/lxc/%d/snapshot", c.node, vmid)
+	data, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot list: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Name != "current" {
+			names = append(names, e.Name)
+		}
+	}
+	return names, nil
+}
+
+// DeleteSnapshot deletes a container snapshot.
+func (c *Client) DeleteSnapshot(ctx context.Context, vmid int, name string) (string, error) {
+	path := fmt.Sprintf("/nodes/%s/lxc/%d/snapshot/%s", c.node, vmid, url.PathEscape(name))
+	data, err := c.do(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var upid string
+	if err := json.Unmarshal(data, &upid); err != nil {
+		return "", fmt.Errorf("unmarshal UPID: %w", err)
+	}
+	return upid, nil
+}
+
 // GetNodeStatus returns the resource status of the configured node.
 func (c *Client) GetNodeStatus(ctx context.Context) (*NodeStatus, error) {
 	path := fmt.Sprintf("/nodes/%s/status", c.node)
@@ -327,6 +376,21 @@ func (c *Client) GetNodeStatus(ctx context.Context) (*NodeStatus, error) {
 	return &status, nil
 }
 
+// ListStorage returns the storages available on the configured node.
+func (c *Client) ListStorage(ctx context.Context) ([]StorageInfo, error) {
+	path := fmt.Sprintf("/nodes/%s/storage", c.node)
+	data, err := c.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var storages []StorageInfo
+	if err := json.Unmarshal(data, &storages); err != nil {
+		return nil, fmt.Errorf("unmarshal storage list: %w", err)
+	}
+	return storages, nil
+}
+
 // GetTaskStatus returns the status of a task by UPID.
 func (c *Client) GetTaskStatus(ctx context.Context, upid string) (*TaskStatus, error) {
 	path := fmt.Sprintf("/nodes/%s/tasks/%s/status", c.node, url.PathEscape(upid))
@@ -342,32 +406,32 @@ func (c *Client) GetTaskStatus(ctx context.Context, upid string) (*TaskStatus, e
 	return &status, nil
 }
 
+// StopTask stops a running task by UPID.
+func (c *Client) StopTask(ctx context.Context, upid string) error {
+	path := fmt.Sprintf("/nodes/%s/tasks/%s", c.node, url.PathEscape(upid))
+	_, err := c.do(ctx, http.MethodDelete, path, nil)
+	return err
+}
+
 // WaitForTask polls a task until it completes or the context is cancelled.
 func (c *Client) WaitForTask(ctx context.Context, upid string) error {
 	if upid == "" {
 		return nil
 	}
 
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			status, err := c.GetTaskStatus(ctx, upid)
-			if err != nil {
-				return fmt.Errorf("check task status: %w", err)
-			}
-			if status.Status == "stopped" {
-				if status.ExitStatus != "OK" {
-					return fmt.Errorf("task failed with status: %s", status.ExitStatus)
-				}
-				return nil
-			}
+	return provider.WaitFor(ctx, "task "+upid, 0, func(ctx context.Context) (bool, error) {
+		status, err := c.GetTaskStatus(ctx, upid)
+		if err != nil {
+			return false, fmt.Errorf("check task status: %w", err)
 		}
-	}
+		if status.Status != "stopped" {
+			return false, nil
+		}
+		if status.ExitStatus != "OK" {
+			return false, fmt.Errorf("task failed with status: %s", status.ExitStatus)
+		}
+		return true, nil
+	})
 }
 
 // NextVMID finds the next available VMID in the configured range.
@@ -172,6 +172,23 @@ func TestCloneCT(t *testing.T) {
 	}
 }
 
+func TestCloneCT_FromSnapshot(t *testing.T) {
+	client, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if r.FormValue("snapname") != "before-upgrade" || r.FormValue("full") != "1" {
+			t.Errorf("snapname = %q, full = %q", r.FormValue("snapname"), r.FormValue("full"))
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(proxmoxResponse("UPID:pve:1"))
+	}))
+
+	if _, err := client.cloneCT(context.Background(), 100, 9001, "sbx-fork", true, "before-upgrade"); err != nil {
+		t.Fatalf("cloneCT() error: %v", err)
+	}
+}
+
 func TestStartCT(t *testing.T) {
 	expectedUPID := "UPID:pve:0001:0002:12345678:vzstart:100:user@pam:"
 
@@ -317,6 +334,47 @@ func TestCreateSnapshot(t *testing.T) {
 	}
 }
 
+func TestListSnapshots(t *testing.T) {
+	client, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/nodes/pve/lxc/100/snapshot") {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(proxmoxResponse([]map[string]any{{"name": "snap-1"}, {"name": "current"}}))
+	}))
+
+	names, err := client.ListSnapshots(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("ListSnapshots() error: %v", err)
+	}
+	if len(names) != 1 || names[0] != "snap-1" {
+		t.Errorf("names = %v, want [snap-1]", names)
+	}
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+	expectedUPID := "UPID:pve:0001:0002:12345678:vzdelsnapshot:100:user@pam:"
+
+	client, _ := testClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("expected DELETE, got %s", r.Method)
+		}
+		if !strings.HasSuffix(r.URL.Path, "/nodes/pve/lxc/100/snapshot/snap-1") {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(proxmoxResponse(expectedUPID))
+	}))
+
+	upid, err := client.DeleteSnapshot(context.Background(), 100, "snap-1")
+	if err != nil {
+		t.Fatalf("DeleteSnapshot() error: %v", err)
+	}
+	if upid != expectedUPID {
+		t.Errorf("UPID = %q, want %q", upid, expectedUPID)
+	}
+}
+
 func TestGetNodeStatus(t *testing.T) {
 	status := NodeStatus{
 		CPU:    0.25,
@@ -17,6 +17,10 @@ type Config struct {
 	VMIDEnd   int           `yaml:"vmid_end"`   // End of VMID range for sandboxes
 	VerifySSL bool          `yaml:"verify_ssl"` // Verify TLS certificates
 	Timeout   time.Duration `yaml:"timeout"`    // HTTP client timeout
+
+	// CloneTimeout bounds cloning the source CT, including waiting for the
+	// clone task. A clone still running then is stopped and removed.
+	CloneTimeout time.Duration `yaml:"clone_timeout"`
 }
 
 // Validate checks that required config fields are set and applies defaults.
@@ -45,6 +49,9 @@ func (c *Config) Validate() error {
 	if c.Timeout == 0 {
 		c.Timeout = 5 * time.Minute
 	}
+	if c.CloneTimeout == 0 {
+		c.CloneTimeout = 10 * time.Minute
+	}
 	if c.Bridge == "" {
 		c.Bridge = "vmbr0"
 	}
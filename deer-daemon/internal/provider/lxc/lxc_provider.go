@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/url"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +20,10 @@ import (
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
 )
 
+// cloneAbortTimeout bounds stopping a timed-out clone task and removing the
+// partial CT.
+const cloneAbortTimeout = time.Minute
+
 // Provider implements provider.SandboxProvider for Proxmox LXC containers.
 type Provider struct {
 	client   *Client
@@ -30,6 +35,9 @@ type Provider struct {
 	mu sync.Mutex
 	// sandboxID -> vmid mapping for active sandboxes.
 	sandboxes map[string]int
+
+	// runHost replaces exec for commands run on the Proxmox node (tests).
+	runHost func(ctx context.Context, name string, args ...string) ([]byte, error)
 }
 
 // New creates a new LXC provider.
@@ -57,7 +65,22 @@ func (p *Provider) CreateSandbox(ctx context.Context, req provider.CreateRequest
 	if err != nil {
 		return nil, fmt.Errorf("resolve source CT %q: %w", req.SourceVM, err)
 	}
+	return p.cloneAndStart(ctx, req, sourceVMID, "")
+}
 
+// CloneFromSnapshot full-clones the parent sandbox's CT as it was at
+// snapshotName into a new sandbox.
+func (p *Provider) CloneFromSnapshot(ctx context.Context, parentID, snapshotName string, req provider.CreateRequest) (*provider.SandboxResult, error) {
+	parentVMID, err := p.getVMID(parentID)
+	if err != nil {
+		return nil, err
+	}
+	return p.cloneAndStart(ctx, req, parentVMID, snapshotName)
+}
+
+// cloneAndStart clones sourceVMID (at snapname, if set) into a new CT,
+// configures and starts it, and tracks it as req.SandboxID.
+func (p *Provider) cloneAndStart(ctx context.Context, req provider.CreateRequest, sourceVMID int, snapname string) (*provider.SandboxResult, error) {
 	// Allocate next VMID
 	p.mu.Lock()
 	newVMID, err := p.client.NextVMID(ctx, p.cfg.VMIDStart, p.cfg.VMIDEnd)
@@ -66,26 +89,33 @@ func (p *Provider) CreateSandbox(ctx context.Context, req provider.CreateRequest
 		return nil, fmt.Errorf("allocate VMID: %w", err)
 	}
 
-	hostname := req.Name
-	if hostname == "" {
-		hostname = fmt.Sprintf("sbx-%s", req.SandboxID[:8])
-	}
+	hostname := ctHostname(req.SandboxID, req.Name)
 
 	p.logger.Info("cloning CT",
 		"source_vmid", sourceVMID,
+		"snapshot", snapname,
 		"new_vmid", newVMID,
 		"hostname", hostname,
 	)
 
 	// Clone the template
-	upid, err := p.client.CloneCT(ctx, sourceVMID, newVMID, hostname, true)
+	cloneCtx, cancelClone := context.WithTimeout(ctx, p.cfg.CloneTimeout)
+	defer cancelClone()
+	upid, err := p.client.cloneCT(cloneCtx, sourceVMID, newVMID, hostname, true, snapname)
 	p.mu.Unlock()
 	if err != nil {
 		return nil, fmt.Errorf("clone CT: %w", err)
 	}
 
-	if err := p.client.WaitForTask(ctx, upid); err != nil {
-		return nil, fmt.Errorf("wait for clone: %w", err)
+	if err := p.client.WaitForTask(cloneCtx, upid); err != nil {
+		if cloneCtx.Err() == nil {
+			return nil, fmt.Errorf("wait for clone: %w", err)
+		}
+		p.abortClone(upid, newVMID)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("clone CT %d cancelled: %w", newVMID, ctx.Err())
+		}
+		return nil, fmt.Errorf("clone CT %d from %d timed out after %s; the clone task was stopped and the partial CT removed", newVMID, sourceVMID, p.cfg.CloneTimeout)
 	}
 
 	// Configure the clone
@@ -165,7 +195,7 @@ func (p *Provider) DestroySandbox(ctx context.Context, sandboxID string) error {
 	p.mu.Unlock()
 
 	if !ok {
-		return fmt.Errorf("sandbox %s not tracked", sandboxID)
+		return fmt.Errorf("sandbox %s not tracked: %w", sandboxID, provider.ErrSandboxNotFound)
 	}
 
 	return p.cleanupCT(ctx, vmid)
@@ -185,6 +215,15 @@ func (p *Provider) StartSandbox(ctx context.Context, sandboxID string) (*provide
 	if err := p.client.WaitForTask(ctx, upid); err != nil {
 		return nil, fmt.Errorf("wait for start: %w", err)
 	}
+	if err := provider.WaitForState(ctx, fmt.Sprintf("CT %d to run", vmid), func(ctx context.Context) (string, error) {
+		st, err := p.client.GetCTStatus(ctx, vmid)
+		if err != nil {
+			return "", err
+		}
+		return st.Status, nil
+	}, "running", 30*time.Second); err != nil {
+		return nil, fmt.Errorf("wait for start: %w", err)
+	}
 
 	ip, _ := p.discoverIP(ctx, vmid, 30*time.Second)
 
@@ -248,6 +287,77 @@ func (p *Provider) CreateSnapshot(ctx context.Context, sandboxID, name string) (
 	}, nil
 }
 
+// DeleteSnapshot removes a container snapshot. It returns
+// provider.ErrSnapshotNotFound when the container has no snapshot by that
+// name, so the daemon can still drop a stale record.
+func (p *Provider) DeleteSnapshot(ctx context.Context, sandboxID, name string) error {
+	vmid, err := p.getVMID(sandboxID)
+	if err != nil {
+		return err
+	}
+
+	names, err := p.client.ListSnapshots(ctx, vmid)
+	if err != nil {
+		return fmt.Errorf("list snapshots: %w", err)
+	}
+	if !slices.Contains(names, name) {
+		return fmt.Errorf("CT %d snapshot %q: %w", vmid, name, provider.ErrSnapshotNotFound)
+	}
+
+	upid, err := p.client.DeleteSnapshot(ctx, vmid, name)
+	if err != nil {
+		return fmt.Errorf("delete snapshot: %w", err)
+	}
+	if err := p.client.WaitForTask(ctx, upid); err != nil {
+		return fmt.Errorf("wait for snapshot delete: %w", err)
+	}
+	return nil
+}
+
+// SetSandboxResources sets the CT's cores and memory. Zero leaves a value
+// unchanged.
+func (p *Provider) SetSandboxResources(ctx context.Context, sandboxID string, vcpus, memoryMB int) error {
+	vmid, err := p.getVMID(sandboxID)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if vcpus > 0 {
+		params.Set("cores", fmt.Sprintf("%d", vcpus))
+	}
+	if memoryMB > 0 {
+		params.Set("memory", fmt.Sprintf("%d", memoryMB))
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	if err := p.client.SetCTConfig(ctx, vmid, params); err != nil {
+		return fmt.Errorf("configure CT %d: %w", vmid, err)
+	}
+	return nil
+}
+
+// SandboxStats reports a CT's usage as Proxmox last sampled it. Proxmox
+// does not expose cumulative CPU time, so only CPUPercent is set.
+func (p *Provider) SandboxStats(ctx context.Context, sandboxID string) (*provider.SandboxStats, error) {
+	vmid, err := p.getVMID(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	st, err := p.client.GetCTStatus(ctx, vmid)
+	if err != nil {
+		return nil, fmt.Errorf("get CT %d status: %w", vmid, err)
+	}
+	return &provider.SandboxStats{
+		CPUPercent:         st.CPU * st.CPUs * 100,
+		MemoryBytes:        st.Mem,
+		MemoryLimitBytes:   st.MaxMem,
+		DiskAllocatedBytes: st.Disk,
+		DiskCapacityBytes:  st.MaxDisk,
+	}, nil
+}
+
 func (p *Provider) RunCommand(ctx context.Context, sandboxID, command string, timeout time.Duration) (*provider.CommandResult, error) {
 	vmid, err := p.getVMID(sandboxID)
 	if err != nil {
@@ -319,24 +429,24 @@ func (p *Provider) ValidateSourceVM(ctx context.Context, vmName string) (*provid
 
 	vmid, err := p.resolver.ResolveVMID(ctx, vmName)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("CT %q not found: %v", vmName, err))
+		result.AddError(provider.IssueVMNotFound, fmt.Sprintf("CT %q not found: %v", vmName, err))
 		return result, nil
 	}
 
 	status, err := p.client.GetCTStatus(ctx, vmid)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("failed to get CT status: %v", err))
+		result.AddError(provider.IssueStatusUnavailable, fmt.Sprintf("failed to get CT status: %v", err))
 		return result, nil
 	}
 	result.State = status.Status
 
 	cfg, err := p.client.GetCTConfig(ctx, vmid)
 	if err != nil {
-		result.Warnings = append(result.Warnings, fmt.Sprintf("could not read CT config: %v", err))
+		result.AddWarning(provider.IssueConfigUnreadable, fmt.Sprintf("could not read CT config: %v", err))
 	} else {
 		if cfg.Net0 == "" {
 			result.HasNetwork = false
-			result.Warnings = append(result.Warnings, "CT has no network interface (net0)")
+			result.AddWarning(provider.IssueNoNetwork, "CT has no network interface (net0)")
 		} else {
 			result.HasNetwork = true
 		}
@@ -347,7 +457,7 @@ func (p *Provider) ValidateSourceVM(ctx context.Context, vmName string) (*provid
 		if err == nil {
 			result.IPAddress = ip
 		} else {
-			result.Warnings = append(result.Warnings, "Could not determine IP address")
+			result.AddWarning(provider.IssueNoIPAddress, "Could not determine IP address")
 		}
 	}
 
@@ -475,6 +585,41 @@ func (p *Provider) Capabilities(ctx context.Context) (*provider.HostCapabilities
 	return caps, nil
 }
 
+// ImportSandbox registers an existing, non-template CT as a sandbox so the
+// lifecycle and command operations can target it by sandbox ID.
+func (p *Provider) ImportSandbox(ctx context.Context, sandboxID, vmName string) (*provider.SandboxResult, error) {
+	vmid, err := p.resolver.ResolveVMID(ctx, vmName)
+	if err != nil {
+		return nil, fmt.Errorf("CT %q: %w", vmName, provider.ErrSandboxNotFound)
+	}
+
+	st, err := p.client.GetCTStatus(ctx, vmid)
+	if err != nil {
+		return nil, fmt.Errorf("get CT status: %w", err)
+	}
+
+	p.mu.Lock()
+	for existing, id := range p.sandboxes {
+		if id == vmid && existing != sandboxID {
+			p.mu.Unlock()
+			return nil, fmt.Errorf("CT %q is already tracked as sandbox %s", vmName, existing)
+		}
+	}
+	p.sandboxes[sandboxID] = vmid
+	p.mu.Unlock()
+
+	result := &provider.SandboxResult{
+		SandboxID: sandboxID,
+		Name:      vmName,
+		State:     "STOPPED",
+	}
+	if st.Status == "running" {
+		result.State = "RUNNING"
+		result.IPAddress, _ = p.discoverIP(ctx, vmid, 10*time.Second)
+	}
+	return result, nil
+}
+
 func (p *Provider) ActiveSandboxCount() int {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -510,11 +655,29 @@ func (p *Provider) getVMID(sandboxID string) (int, error) {
 	vmid, ok := p.sandboxes[sandboxID]
 	p.mu.Unlock()
 	if !ok {
-		return 0, fmt.Errorf("sandbox %s not tracked", sandboxID)
+		return 0, fmt.Errorf("sandbox %s not tracked: %w", sandboxID, provider.ErrSandboxNotFound)
 	}
 	return vmid, nil
 }
 
+// abortClone stops an unfinished clone task and removes whatever it created.
+// It runs on a fresh context since the create's context is already done.
+func (p *Provider) abortClone(upid string, vmid int) {
+	ctx, cancel := context.WithTimeout(context.Background(), cloneAbortTimeout)
+	defer cancel()
+	if err := p.client.StopTask(ctx, upid); err != nil {
+		p.logger.Warn("stop clone task failed", "upid", upid, "error", err)
+	} else if err := p.client.WaitForTask(ctx, upid); err != nil {
+		// A stopped task ends with a non-OK status; only a timeout matters.
+		if ctx.Err() != nil {
+			p.logger.Warn("clone task did not stop", "upid", upid, "error", err)
+		}
+	}
+	if err := p.cleanupCT(ctx, vmid); err != nil {
+		p.logger.Warn("remove partial clone failed", "vmid", vmid, "error", err)
+	}
+}
+
 // cleanupCT stops and deletes a container.
 func (p *Provider) cleanupCT(ctx context.Context, vmid int) error {
 	// Check status first
@@ -549,38 +712,27 @@ func (p *Provider) cleanupCT(ctx context.Context, vmid int) error {
 
 // discoverIP polls the CT interfaces endpoint until an IPv4 address appears.
 func (p *Provider) discoverIP(ctx context.Context, vmid int, timeout time.Duration) (string, error) {
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for {
+	var found string
+	err := provider.WaitFor(ctx, fmt.Sprintf("IP of CT %d", vmid), timeout, func(ctx context.Context) (bool, error) {
 		ifaces, err := p.client.GetCTInterfaces(ctx, vmid)
-		if err == nil {
-			for _, iface := range ifaces {
-				if iface.Name == "lo" {
-					continue
-				}
-				if iface.Inet != "" {
-					// inet format: "10.0.0.5/24" - strip prefix
-					ipStr := strings.SplitN(iface.Inet, "/", 2)[0]
-					ip := net.ParseIP(ipStr)
-					if ip != nil && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
-						return ipStr, nil
-					}
-				}
-			}
-		}
-
-		if time.Now().After(deadline) {
-			return "", fmt.Errorf("timeout waiting for IP of CT %d", vmid)
+		if err != nil {
+			return false, nil
 		}
-
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		case <-ticker.C:
+		for _, iface := range ifaces {
+			if iface.Name == "lo" || iface.Inet == "" {
+				continue
+			}
+			// inet format: "10.0.0.5/24" - strip prefix
+			ipStr := strings.SplitN(iface.Inet, "/", 2)[0]
+			ip := net.ParseIP(ipStr)
+			if ip != nil && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+				found = ipStr
+				return true, nil
+			}
 		}
-	}
+		return false, nil
+	})
+	return found, err
 }
 
 // pctExec runs a command inside a container via pct exec.
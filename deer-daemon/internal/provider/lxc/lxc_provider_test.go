@@ -3,6 +3,7 @@ package lxc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -23,7 +24,13 @@ type mockProxmox struct {
 	ifaces     map[int][]CTInterface
 	taskQueue  map[string]TaskStatus
 	nodeStatus *NodeStatus
+	storages   []StorageInfo
 	cloneCount int
+
+	// slowClone leaves clone tasks running until they are stopped.
+	slowClone    bool
+	stoppedTasks []string
+	deletedCTs   []int
 }
 
 func newMockProxmox() *mockProxmox {
@@ -80,6 +87,10 @@ func (m *mockProxmox) handler() http.Handler {
 		case r.Method == http.MethodGet && strings.HasSuffix(path, "/lxc"):
 			m.respond(w, m.cts)
 
+		// Node storage list
+		case r.Method == http.MethodGet && strings.HasSuffix(path, "/storage"):
+			m.respond(w, m.storages)
+
 		// Node status
 		case r.Method == http.MethodGet && strings.HasSuffix(path, "/status") && !strings.Contains(path, "/lxc/"):
 			m.respond(w, m.nodeStatus)
@@ -105,6 +116,16 @@ func (m *mockProxmox) handler() http.Handler {
 
 		// CT config PUT
 		case r.Method == http.MethodPut && strings.HasSuffix(path, "/config"):
+			vmid := extractVMID(path)
+			_ = r.ParseForm()
+			c := m.configs[vmid]
+			if v := r.FormValue("cores"); v != "" {
+				_, _ = fmt.Sscanf(v, "%d", &c.Cores)
+			}
+			if v := r.FormValue("memory"); v != "" {
+				_, _ = fmt.Sscanf(v, "%d", &c.Memory)
+			}
+			m.configs[vmid] = c
 			m.respond(w, nil)
 
 		// Clone
@@ -113,6 +134,9 @@ func (m *mockProxmox) handler() http.Handler {
 			upid := fmt.Sprintf("UPID:pve:clone:%d", m.cloneCount)
 			// Mark task as immediately done
 			m.taskQueue[upid] = TaskStatus{Status: "stopped", ExitStatus: "OK"}
+			if m.slowClone {
+				m.taskQueue[upid] = TaskStatus{Status: "running"}
+			}
 			m.respond(w, upid)
 
 		// Start
@@ -141,9 +165,17 @@ func (m *mockProxmox) handler() http.Handler {
 			m.taskQueue[upid] = TaskStatus{Status: "stopped", ExitStatus: "OK"}
 			m.respond(w, upid)
 
+		// Stop task
+		case r.Method == http.MethodDelete && strings.Contains(path, "/tasks/"):
+			upid := path[strings.Index(path, "/tasks/")+len("/tasks/"):]
+			m.stoppedTasks = append(m.stoppedTasks, upid)
+			m.taskQueue[upid] = TaskStatus{Status: "stopped", ExitStatus: "interrupted by signal"}
+			m.respond(w, nil)
+
 		// Delete
 		case r.Method == http.MethodDelete && strings.Contains(path, "/lxc/"):
 			vmid := extractVMID(path)
+			m.deletedCTs = append(m.deletedCTs, vmid)
 			upid := fmt.Sprintf("UPID:pve:delete:%d", vmid)
 			m.taskQueue[upid] = TaskStatus{Status: "stopped", ExitStatus: "OK"}
 			m.respond(w, upid)
@@ -349,6 +381,9 @@ func TestProvider_DestroySandbox_NotTracked(t *testing.T) {
 	if !strings.Contains(err.Error(), "not tracked") {
 		t.Errorf("error = %q, want containing 'not tracked'", err.Error())
 	}
+	if !errors.Is(err, provider.ErrSandboxNotFound) {
+		t.Errorf("error = %v, want wrapping provider.ErrSandboxNotFound", err)
+	}
 }
 
 func TestProvider_DestroySandbox(t *testing.T) {
@@ -425,6 +460,9 @@ func TestProvider_ValidateSourceVM_NotFound(t *testing.T) {
 	if len(result.Errors) == 0 {
 		t.Error("expected at least one error")
 	}
+	if len(result.Issues) != 1 || result.Issues[0].Code != provider.IssueVMNotFound || result.Issues[0].Severity != provider.SeverityError {
+		t.Errorf("Issues = %+v, want one VM_NOT_FOUND error", result.Issues)
+	}
 }
 
 func TestProvider_ValidateSourceVM_NoNetwork(t *testing.T) {
@@ -447,6 +485,16 @@ func TestProvider_ValidateSourceVM_NoNetwork(t *testing.T) {
 	} else {
 		t.Error("expected HasNetwork=false for CT with empty Net0")
 	}
+
+	var found bool
+	for _, is := range result.Issues {
+		if is.Code == provider.IssueNoNetwork && is.Severity == provider.SeverityWarning {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Issues = %+v, want a NO_NETWORK_INTERFACE warning", result.Issues)
+	}
 }
 
 func TestProvider_GetSandboxIP_NotTracked(t *testing.T) {
@@ -533,6 +581,53 @@ func TestProvider_StopSandbox_Graceful(t *testing.T) {
 	}
 }
 
+func TestProvider_SetSandboxResources(t *testing.T) {
+	mock := newMockProxmox()
+	mock.configs[9001] = CTConfig{Cores: 2, Memory: 2048}
+	prov, _ := testProvider(t, mock)
+	prov.mu.Lock()
+	prov.sandboxes["test-sbx"] = 9001
+	prov.mu.Unlock()
+
+	if err := prov.SetSandboxResources(context.Background(), "test-sbx", 4, 0); err != nil {
+		t.Fatalf("SetSandboxResources() error: %v", err)
+	}
+	mock.mu.Lock()
+	got := mock.configs[9001]
+	mock.mu.Unlock()
+	if got.Cores != 4 || got.Memory != 2048 {
+		t.Errorf("config = cores %d memory %d, want cores 4 and memory unchanged", got.Cores, got.Memory)
+	}
+
+	err := prov.SetSandboxResources(context.Background(), "missing", 4, 4096)
+	if !errors.Is(err, provider.ErrSandboxNotFound) {
+		t.Errorf("untracked sandbox error = %v, want ErrSandboxNotFound", err)
+	}
+}
+
+func TestProvider_SandboxStats(t *testing.T) {
+	mock := newMockProxmox()
+	mock.statuses[9001] = CTStatus{VMID: 9001, Status: "running", CPU: 0.25, CPUs: 2, Mem: 512 << 20, MaxMem: 2048 << 20, Disk: 3 << 30, MaxDisk: 8 << 30}
+	prov, _ := testProvider(t, mock)
+	prov.mu.Lock()
+	prov.sandboxes["test-sbx"] = 9001
+	prov.mu.Unlock()
+
+	stats, err := prov.SandboxStats(context.Background(), "test-sbx")
+	if err != nil {
+		t.Fatalf("SandboxStats() error: %v", err)
+	}
+	want := provider.SandboxStats{CPUPercent: 50, MemoryBytes: 512 << 20, MemoryLimitBytes: 2048 << 20, DiskAllocatedBytes: 3 << 30, DiskCapacityBytes: 8 << 30}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+
+	_, err = prov.SandboxStats(context.Background(), "missing")
+	if !errors.Is(err, provider.ErrSandboxNotFound) {
+		t.Errorf("untracked sandbox error = %v, want ErrSandboxNotFound", err)
+	}
+}
+
 func TestProvider_CreateSnapshot(t *testing.T) {
 	mock := newMockProxmox()
 	prov, _ := testProvider(t, mock)
@@ -716,3 +811,78 @@ func TestProvider_RunSourceCommand_NotFound(t *testing.T) {
 		t.Fatal("expected error for nonexistent source")
 	}
 }
+
+func TestProvider_ImportSandbox(t *testing.T) {
+	mock := newMockProxmox()
+	mock.cts = []CTListEntry{
+		{VMID: 120, Name: "legacy-web", Status: "stopped"},
+	}
+	mock.statuses[120] = CTStatus{VMID: 120, Name: "legacy-web", Status: "stopped"}
+
+	prov, _ := testProvider(t, mock)
+
+	result, err := prov.ImportSandbox(context.Background(), "sbx-imp1", "legacy-web")
+	if err != nil {
+		t.Fatalf("ImportSandbox() error: %v", err)
+	}
+	if result.State != "STOPPED" || result.Name != "legacy-web" {
+		t.Errorf("result = %+v, want STOPPED legacy-web", result)
+	}
+	if vmid, err := prov.getVMID("sbx-imp1"); err != nil || vmid != 120 {
+		t.Errorf("getVMID = %d, %v; want 120", vmid, err)
+	}
+
+	// Re-importing under the same ID is allowed (restart reattach), a second ID is not.
+	if _, err := prov.ImportSandbox(context.Background(), "sbx-imp1", "legacy-web"); err != nil {
+		t.Errorf("re-import under same ID: %v", err)
+	}
+	if _, err := prov.ImportSandbox(context.Background(), "sbx-imp2", "legacy-web"); err == nil {
+		t.Error("expected error importing an already tracked CT under a new ID")
+	}
+}
+
+func TestProvider_ImportSandbox_NotFound(t *testing.T) {
+	mock := newMockProxmox()
+	prov, _ := testProvider(t, mock)
+
+	_, err := prov.ImportSandbox(context.Background(), "sbx-imp1", "missing")
+	if !errors.Is(err, provider.ErrSandboxNotFound) {
+		t.Fatalf("err = %v, want ErrSandboxNotFound", err)
+	}
+}
+
+func TestProvider_CreateSandbox_CloneTimeout(t *testing.T) {
+	mock := newMockProxmox()
+	mock.cts = []CTListEntry{
+		{VMID: 100, Name: "src", Template: 1, Status: "stopped"},
+	}
+	mock.statuses[9000] = CTStatus{VMID: 9000, Status: "stopped"}
+	mock.slowClone = true
+
+	prov, _ := testProvider(t, mock)
+	prov.cfg.CloneTimeout = 300 * time.Millisecond
+
+	start := time.Now()
+	_, err := prov.CreateSandbox(context.Background(), provider.CreateRequest{
+		SandboxID: "sbx-slow-clone",
+		SourceVM:  "src",
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("err = %v, want a clone timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("create took %s, want it bounded by the clone timeout", elapsed)
+	}
+
+	mock.mu.Lock()
+	defer mock.mu.Unlock()
+	if len(mock.stoppedTasks) != 1 || mock.stoppedTasks[0] != "UPID:pve:clone:1" {
+		t.Errorf("stopped tasks = %v, want the clone task", mock.stoppedTasks)
+	}
+	if len(mock.deletedCTs) != 1 || mock.deletedCTs[0] != 9000 {
+		t.Errorf("deleted CTs = %v, want the partial clone 9000", mock.deletedCTs)
+	}
+	if prov.ActiveSandboxCount() != 0 {
+		t.Errorf("ActiveSandboxCount = %d, want 0", prov.ActiveSandboxCount())
+	}
+}
@@ -0,0 +1,100 @@
+package lxc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+)
+
+// ctHostname is the hostname a sandbox's CT is cloned with: its name, or a
+// prefix of its ID when it has none.
+func ctHostname(sandboxID, name string) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("sbx-%s", sandboxID[:min(8, len(sandboxID))])
+}
+
+// ListOrphans reports non-template CTs in the sandbox VMID range that
+// belong to no live sandbox. These are left behind when a create fails
+// after cloning and its cleanup also fails, or when a destroy cannot delete
+// the CT. A CT belongs to a live sandbox when it is tracked under that
+// sandbox's ID or when its hostname matches one the sandbox would be given;
+// the latter covers creates still in progress, whose CREATING rows are live
+// before the CT is tracked.
+func (p *Provider) ListOrphans(ctx context.Context, live []provider.LiveSandbox) (*provider.OrphanReport, error) {
+	cts, err := p.client.ListCTs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list CTs: %w", err)
+	}
+
+	keys := make(map[string]bool, len(live)*3)
+	for _, sb := range live {
+		keys[sb.ID] = true
+		keys[ctHostname(sb.ID, sb.Name)] = true
+		if sb.Name != "" {
+			keys[sb.Name] = true
+		}
+	}
+	owned := make(map[int]bool)
+	p.mu.Lock()
+	for key, vmid := range p.sandboxes {
+		if keys[key] {
+			owned[vmid] = true
+		}
+	}
+	p.mu.Unlock()
+
+	report := &provider.OrphanReport{
+		PoolSize: p.cfg.VMIDEnd - p.cfg.VMIDStart + 1,
+		PoolFree: freeVMIDs(cts, p.cfg.VMIDStart, p.cfg.VMIDEnd),
+	}
+	for _, ct := range cts {
+		if !p.inRange(ct.VMID) || ct.Template != 0 || owned[ct.VMID] || keys[ct.Name] {
+			continue
+		}
+		report.Orphans = append(report.Orphans, provider.Orphan{
+			ID:     strconv.Itoa(ct.VMID),
+			Name:   ct.Name,
+			State:  ct.Status,
+			Reason: fmt.Sprintf("CT %d is in the sandbox VMID range but no sandbox owns it", ct.VMID),
+		})
+	}
+	return report, nil
+}
+
+// ReclaimOrphan stops and deletes an orphaned CT, freeing its VMID. It only
+// touches non-template CTs inside the sandbox VMID range.
+func (p *Provider) ReclaimOrphan(ctx context.Context, orphanID string) error {
+	vmid, err := strconv.Atoi(orphanID)
+	if err != nil {
+		return fmt.Errorf("invalid VMID %q", orphanID)
+	}
+	if !p.inRange(vmid) {
+		return fmt.Errorf("VMID %d is outside the sandbox range %d-%d", vmid, p.cfg.VMIDStart, p.cfg.VMIDEnd)
+	}
+	cfg, err := p.client.GetCTConfig(ctx, vmid)
+	if err != nil {
+		return fmt.Errorf("get CT config: %w", err)
+	}
+	if cfg.Template != 0 {
+		return fmt.Errorf("CT %d is a template", vmid)
+	}
+
+	// Drop any stale tracking, such as a leaked CT picked up by RecoverState.
+	p.mu.Lock()
+	for key, v := range p.sandboxes {
+		if v == vmid {
+			delete(p.sandboxes, key)
+		}
+	}
+	p.mu.Unlock()
+
+	return p.cleanupCT(ctx, vmid)
+}
+
+func (p *Provider) inRange(vmid int) bool {
+	return vmid >= p.cfg.VMIDStart && vmid <= p.cfg.VMIDEnd
+}
@@ -0,0 +1,67 @@
+package lxc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+)
+
+func TestListOrphans(t *testing.T) {
+	mock := newMockProxmox()
+	mock.cts = []CTListEntry{
+		{VMID: 100, Name: "web", Status: "running"},                // outside the range
+		{VMID: 9000, Name: "tmpl", Status: "stopped", Template: 1}, // template
+		{VMID: 9001, Name: "sbx-sbx-abcd", Status: "running"},      // tracked, live
+		{VMID: 9002, Name: "my-box", Status: "running"},            // live by name
+		{VMID: 9003, Name: "sbx-sbx-dead", Status: "stopped"},      // recovered, no sandbox
+		{VMID: 9004, Name: "half-made", Status: "stopped"},         // untracked leak
+		{VMID: 9005, Name: "sbx-sbx-wxyz", Status: "running"},      // create in progress
+	}
+	prov, _ := testProvider(t, mock)
+	prov.cfg.VMIDStart, prov.cfg.VMIDEnd = 9000, 9009
+	prov.sandboxes["sbx-abcdef"] = 9001
+	prov.sandboxes["sbx-sbx-dead"] = 9003
+
+	live := []provider.LiveSandbox{
+		{ID: "sbx-abcdef"},
+		{ID: "sbx-mine", Name: "my-box"},
+		{ID: "sbx-wxyz12"},
+	}
+	report, err := prov.ListOrphans(context.Background(), live)
+	if err != nil {
+		t.Fatalf("ListOrphans: %v", err)
+	}
+	var ids []string
+	for _, o := range report.Orphans {
+		ids = append(ids, o.ID)
+	}
+	if len(ids) != 2 || ids[0] != "9003" || ids[1] != "9004" {
+		t.Errorf("orphans = %v, want [9003 9004]", ids)
+	}
+	if report.PoolSize != 10 || report.PoolFree != 4 {
+		t.Errorf("pool = %d free of %d, want 4 of 10", report.PoolFree, report.PoolSize)
+	}
+}
+
+func TestReclaimOrphan(t *testing.T) {
+	mock := newMockProxmox()
+	mock.configs[9003] = CTConfig{}
+	mock.configs[9000] = CTConfig{Template: 1}
+	mock.statuses[9003] = CTStatus{Status: "stopped"}
+	prov, _ := testProvider(t, mock)
+	prov.sandboxes["sbx-sbx-dead"] = 9003
+
+	if err := prov.ReclaimOrphan(context.Background(), "9003"); err != nil {
+		t.Fatalf("ReclaimOrphan: %v", err)
+	}
+	if _, ok := prov.sandboxes["sbx-sbx-dead"]; ok {
+		t.Error("reclaimed CT is still tracked")
+	}
+
+	for _, id := range []string{"100", "9000", "abc"} {
+		if err := prov.ReclaimOrphan(context.Background(), id); err == nil {
+			t.Errorf("ReclaimOrphan(%s): expected an error", id)
+		}
+	}
+}
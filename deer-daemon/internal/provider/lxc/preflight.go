@@ -0,0 +1,82 @@
+package lxc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// vmidLowWater is the number of free VMIDs at or below which CheckConfig
+// warns that the sandbox range is nearly full.
+const vmidLowWater = 10
+
+// CheckConfig verifies the configuration against the node so mistakes show
+// up at startup rather than on the first create: the storage must exist and
+// hold container volumes, and the VMID range must have free IDs. A range
+// that is nearly full is logged as a warning.
+func (p *Provider) CheckConfig(ctx context.Context) error {
+	if p.cfg.Storage != "" {
+		storages, err := p.client.ListStorage(ctx)
+		if err != nil {
+			return fmt.Errorf("list storage on node %s: %w", p.cfg.Node, err)
+		}
+		if err := checkStorage(storages, p.cfg.Storage, p.cfg.Node); err != nil {
+			return err
+		}
+	}
+
+	cts, err := p.client.ListCTs(ctx)
+	if err != nil {
+		return fmt.Errorf("list CTs on node %s: %w", p.cfg.Node, err)
+	}
+	free := freeVMIDs(cts, p.cfg.VMIDStart, p.cfg.VMIDEnd)
+	size := p.cfg.VMIDEnd - p.cfg.VMIDStart + 1
+	if free == 0 {
+		return fmt.Errorf("lxc vmid range %d-%d is exhausted: all %d IDs are in use; widen vmid_start/vmid_end or remove unused CTs",
+			p.cfg.VMIDStart, p.cfg.VMIDEnd, size)
+	}
+	if free <= vmidLowWater {
+		p.logger.Warn("lxc vmid range nearly full",
+			"vmid_start", p.cfg.VMIDStart,
+			"vmid_end", p.cfg.VMIDEnd,
+			"free", free,
+		)
+	}
+	return nil
+}
+
+// checkStorage reports whether name is an enabled, active storage that can
+// hold container root filesystems.
+func checkStorage(storages []StorageInfo, name, node string) error {
+	for _, s := range storages {
+		if s.Storage != name {
+			continue
+		}
+		if !slices.Contains(strings.Split(s.Content, ","), "rootdir") {
+			return fmt.Errorf("lxc storage %q on node %s does not allow container volumes (content: %s); add \"rootdir\" to its content types", name, node, s.Content)
+		}
+		if s.Active == 0 {
+			return fmt.Errorf("lxc storage %q on node %s is not active", name, node)
+		}
+		return nil
+	}
+	names := make([]string, 0, len(storages))
+	for _, s := range storages {
+		names = append(names, s.Storage)
+	}
+	return fmt.Errorf("lxc storage %q not found on node %s (available: %s)", name, node, strings.Join(names, ", "))
+}
+
+// freeVMIDs counts the IDs in [start, end] not used by any CT.
+func freeVMIDs(cts []CTListEntry, start, end int) int {
+	used := 0
+	seen := make(map[int]bool, len(cts))
+	for _, ct := range cts {
+		if ct.VMID >= start && ct.VMID <= end && !seen[ct.VMID] {
+			seen[ct.VMID] = true
+			used++
+		}
+	}
+	return end - start + 1 - used
+}
@@ -0,0 +1,49 @@
+package lxc
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCheckConfig(t *testing.T) {
+	mock := newMockProxmox()
+	mock.storages = []StorageInfo{
+		{Storage: "local", Content: "iso,vztmpl,backup", Active: 1, Enabled: 1},
+		{Storage: "local-lvm", Content: "rootdir,images", Active: 1, Enabled: 1},
+		{Storage: "offline", Content: "rootdir", Active: 0, Enabled: 1},
+	}
+	prov, _ := testProvider(t, mock)
+	prov.cfg.Storage = "local-lvm"
+	prov.cfg.VMIDStart, prov.cfg.VMIDEnd = 9000, 9002
+
+	if err := prov.CheckConfig(context.Background()); err != nil {
+		t.Fatalf("CheckConfig: %v", err)
+	}
+
+	for storage, want := range map[string]string{
+		"missing": "not found",
+		"local":   "does not allow container volumes",
+		"offline": "not active",
+	} {
+		prov.cfg.Storage = storage
+		err := prov.CheckConfig(context.Background())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("storage %q: err = %v, want %q", storage, err, want)
+		}
+	}
+
+	prov.cfg.Storage = ""
+	mock.cts = []CTListEntry{{VMID: 100}, {VMID: 9000}, {VMID: 9001}, {VMID: 9002}}
+	err := prov.CheckConfig(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exhausted") {
+		t.Errorf("err = %v, want range exhausted", err)
+	}
+}
+
+func TestFreeVMIDs(t *testing.T) {
+	cts := []CTListEntry{{VMID: 99}, {VMID: 100}, {VMID: 105}, {VMID: 200}}
+	if got := freeVMIDs(cts, 100, 109); got != 8 {
+		t.Errorf("freeVMIDs = %d, want 8", got)
+	}
+}
@@ -0,0 +1,224 @@
+package lxc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/shared/multierr"
+)
+
+// snapshotCleanupTimeout bounds unmounting and deactivating a snapshot. It
+// runs on a fresh context so cleanup still happens when the request's
+// context has been cancelled.
+const snapshotCleanupTimeout = 30 * time.Second
+
+// maxSymlinkHops bounds symlink resolution inside a snapshot, as the kernel
+// does with ELOOP.
+const maxSymlinkHops = 40
+
+// ReadSnapshotFile reads path from a container snapshot. The snapshot's root
+// filesystem is opened read-only on the Proxmox node: through .zfs/snapshot
+// for ZFS subvolumes, or by activating and mounting the snapshot LV for
+// LVM-thin. Other storage types are not supported.
+func (p *Provider) ReadSnapshotFile(ctx context.Context, sandboxID, snapshotName, filePath string) ([]byte, error) {
+	vmid, err := p.getVMID(sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	err = p.withSnapshotRoot(ctx, vmid, snapshotName, func(root string) error {
+		hostPath, info, err := snapshotRegularFile(root, filePath)
+		if err != nil {
+			return err
+		}
+		if info.Size() > provider.MaxSnapshotFileSize {
+			return fmt.Errorf("%s is %d bytes, larger than the %d byte limit", filePath, info.Size(), provider.MaxSnapshotFileSize)
+		}
+		data, err = os.ReadFile(hostPath)
+		return err
+	})
+	return data, err
+}
+
+// RestoreSnapshotFile copies path from a container snapshot into the running
+// container with pct push, keeping the file's mode, owner, and group.
+func (p *Provider) RestoreSnapshotFile(ctx context.Context, sandboxID, snapshotName, filePath string) error {
+	vmid, err := p.getVMID(sandboxID)
+	if err != nil {
+		return err
+	}
+	return p.withSnapshotRoot(ctx, vmid, snapshotName, func(root string) error {
+		hostPath, info, err := snapshotRegularFile(root, filePath)
+		if err != nil {
+			return err
+		}
+		args := []string{"push", strconv.Itoa(vmid), hostPath, path.Clean(filePath),
+			"--perms", fmt.Sprintf("%04o", info.Mode().Perm())}
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			args = append(args, "--user", strconv.FormatUint(uint64(st.Uid), 10), "--group", strconv.FormatUint(uint64(st.Gid), 10))
+		}
+		if out, err := p.hostCommand(ctx, "pct", args...); err != nil {
+			return fmt.Errorf("pct push: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	})
+}
+
+// withSnapshotRoot calls fn with a host directory holding the snapshot's
+// root filesystem. Anything it mounts or activates is undone before it
+// returns, whether or not fn succeeds.
+func (p *Provider) withSnapshotRoot(ctx context.Context, vmid int, snapshotName string, fn func(root string) error) (err error) {
+	if snapshotName == "" || strings.ContainsAny(snapshotName, "/\x00") {
+		return fmt.Errorf("invalid snapshot name %q", snapshotName)
+	}
+	cfg, err := p.client.GetCTConfig(ctx, vmid)
+	if err != nil {
+		return fmt.Errorf("get CT config: %w", err)
+	}
+	volid, _, _ := strings.Cut(cfg.RootFS, ",")
+	if volid == "" {
+		return fmt.Errorf("CT %d has no rootfs volume", vmid)
+	}
+	out, err := p.hostCommand(ctx, "pvesm", "path", volid)
+	if err != nil {
+		return fmt.Errorf("pvesm path %s: %w: %s", volid, err, strings.TrimSpace(string(out)))
+	}
+	volPath := strings.TrimSpace(string(out))
+
+	info, err := os.Stat(volPath)
+	if err != nil {
+		return fmt.Errorf("stat rootfs %s: %w", volPath, err)
+	}
+
+	// ZFS subvolumes expose snapshots as read-only directories.
+	if info.IsDir() {
+		root := filepath.Join(volPath, ".zfs", "snapshot", snapshotName)
+		if _, err := os.Stat(root); err != nil {
+			return fmt.Errorf("snapshot %q not found on %s: %w", snapshotName, volid, err)
+		}
+		return fn(root)
+	}
+
+	// LVM-thin keeps each snapshot as an LV named snap_<volume>_<snapshot>
+	// that is skipped on activation unless asked for explicitly.
+	if info.Mode()&os.ModeDevice == 0 || !strings.HasPrefix(volPath, "/dev/") {
+		return fmt.Errorf("reading files from snapshots is not supported for rootfs %s", volid)
+	}
+	lv := filepath.Join(filepath.Dir(volPath), "snap_"+filepath.Base(volPath)+"_"+snapshotName)
+	if out, err := p.hostCommand(ctx, "lvchange", "-ay", "-K", lv); err != nil {
+		return fmt.Errorf("activate snapshot %s: %w: %s", lv, err, strings.TrimSpace(string(out)))
+	}
+	defer func() {
+		cctx, cancel := context.WithTimeout(context.Background(), snapshotCleanupTimeout)
+		defer cancel()
+		if out, cerr := p.hostCommand(cctx, "lvchange", "-an", lv); cerr != nil {
+			p.logger.Warn("deactivate snapshot LV failed", "lv", lv, "error", cerr, "output", strings.TrimSpace(string(out)))
+			err = multierr.Combine(err, fmt.Errorf("deactivate snapshot %s: %w", lv, cerr))
+		}
+	}()
+
+	mnt, err := os.MkdirTemp("", "deer-snapshot-")
+	if err != nil {
+		return fmt.Errorf("create mount point: %w", err)
+	}
+	defer func() { _ = os.Remove(mnt) }()
+
+	// noload skips ext4 journal replay, which a read-only mount would
+	// otherwise attempt; retry plainly for filesystems that reject it.
+	if _, err := p.hostCommand(ctx, "mount", "-o", "ro,noload", lv, mnt); err != nil {
+		if out, err := p.hostCommand(ctx, "mount", "-o", "ro", lv, mnt); err != nil {
+			return fmt.Errorf("mount snapshot %s: %w: %s", lv, err, strings.TrimSpace(string(out)))
+		}
+	}
+	defer func() {
+		cctx, cancel := context.WithTimeout(context.Background(), snapshotCleanupTimeout)
+		defer cancel()
+		if out, cerr := p.hostCommand(cctx, "umount", mnt); cerr != nil {
+			p.logger.Warn("unmount snapshot failed", "mount", mnt, "error", cerr, "output", strings.TrimSpace(string(out)))
+			err = multierr.Combine(err, fmt.Errorf("unmount snapshot %s: %w", mnt, cerr))
+		}
+	}()
+
+	return fn(mnt)
+}
+
+// hostCommand runs a command on the Proxmox node and returns its combined
+// output.
+func (p *Provider) hostCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if p.runHost != nil {
+		return p.runHost(ctx, name, args...)
+	}
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+// snapshotRegularFile resolves filePath inside root and checks that it is a
+// regular file.
+func snapshotRegularFile(root, filePath string) (string, os.FileInfo, error) {
+	hostPath, err := resolveInRoot(root, filePath)
+	if err != nil {
+		return "", nil, err
+	}
+	info, err := os.Lstat(hostPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s not found in snapshot: %w", filePath, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", nil, fmt.Errorf("%s is not a regular file in the snapshot", filePath)
+	}
+	return hostPath, info, nil
+}
+
+// resolveInRoot maps an absolute guest path to a host path under root,
+// following symlinks as the guest would see them. Absolute link targets are
+// taken relative to root and ".." stops at root, so links in the snapshot
+// can never point at files on the host.
+func resolveInRoot(root, guestPath string) (string, error) {
+	if !path.IsAbs(guestPath) {
+		return "", fmt.Errorf("path %q must be absolute", guestPath)
+	}
+	var resolved []string
+	pending := strings.Split(strings.Trim(path.Clean(guestPath), "/"), "/")
+	hops := 0
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) > 0 {
+				resolved = resolved[:len(resolved)-1]
+			}
+			continue
+		}
+		candidate := filepath.Join(append([]string{root}, append(resolved, part)...)...)
+		info, err := os.Lstat(candidate)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			// Missing components are left for the caller's Lstat to report.
+			resolved = append(resolved, part)
+			continue
+		}
+		hops++
+		if hops > maxSymlinkHops {
+			return "", fmt.Errorf("too many symlinks resolving %s", guestPath)
+		}
+		target, err := os.Readlink(candidate)
+		if err != nil {
+			return "", fmt.Errorf("read symlink %s: %w", candidate, err)
+		}
+		if path.IsAbs(target) {
+			resolved = nil
+		}
+		pending = append(strings.Split(target, "/"), pending...)
+	}
+	return filepath.Join(append([]string{root}, resolved...)...), nil
+}
@@ -0,0 +1,165 @@
+package lxc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeHost records commands run on the node and answers them from results,
+// keyed by the command name.
+type fakeHost struct {
+	calls   []string
+	results map[string]func(args []string) ([]byte, error)
+}
+
+func (f *fakeHost) run(_ context.Context, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	if fn, ok := f.results[name]; ok {
+		return fn(args)
+	}
+	return nil, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o640); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveInRoot(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "etc", "app.conf"), "x")
+	for link, target := range map[string]string{
+		"etc/abs":    "/etc/app.conf",
+		"etc/rel":    "app.conf",
+		"etc/escape": "../../../../etc/passwd",
+		"etc/loop":   "loop",
+	} {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := map[string]string{
+		"/etc/app.conf":       filepath.Join(root, "etc", "app.conf"),
+		"/etc/abs":            filepath.Join(root, "etc", "app.conf"),
+		"/etc/rel":            filepath.Join(root, "etc", "app.conf"),
+		"/etc/escape":         filepath.Join(root, "etc", "passwd"),
+		"/../../etc/app.conf": filepath.Join(root, "etc", "app.conf"),
+	}
+	for in, want := range cases {
+		got, err := resolveInRoot(root, in)
+		if err != nil {
+			t.Errorf("resolveInRoot(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("resolveInRoot(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if _, err := resolveInRoot(root, "/etc/loop"); err == nil {
+		t.Error("expected an error for a symlink loop")
+	}
+	if _, err := resolveInRoot(root, "etc/app.conf"); err == nil {
+		t.Error("expected an error for a relative path")
+	}
+}
+
+func zfsTestProvider(t *testing.T) (*Provider, *fakeHost, string) {
+	t.Helper()
+	mock := newMockProxmox()
+	mock.configs[9001] = CTConfig{RootFS: "local-zfs:subvol-9001-disk-0,size=8G"}
+	prov, _ := testProvider(t, mock)
+	prov.sandboxes["sbx-1"] = 9001
+
+	vol := t.TempDir()
+	host := &fakeHost{results: map[string]func([]string) ([]byte, error){
+		"pvesm": func([]string) ([]byte, error) { return []byte(vol + "\n"), nil },
+	}}
+	prov.runHost = host.run
+	return prov, host, vol
+}
+
+func TestReadSnapshotFile_ZFS(t *testing.T) {
+	prov, host, vol := zfsTestProvider(t)
+	writeFile(t, filepath.Join(vol, ".zfs", "snapshot", "before", "etc", "app.conf"), "listen 80\n")
+
+	data, err := prov.ReadSnapshotFile(context.Background(), "sbx-1", "before", "/etc/app.conf")
+	if err != nil {
+		t.Fatalf("ReadSnapshotFile: %v", err)
+	}
+	if string(data) != "listen 80\n" {
+		t.Errorf("data = %q", data)
+	}
+	if len(host.calls) != 1 || host.calls[0] != "pvesm path local-zfs:subvol-9001-disk-0" {
+		t.Errorf("host calls = %v", host.calls)
+	}
+
+	if _, err := prov.ReadSnapshotFile(context.Background(), "sbx-1", "missing", "/etc/app.conf"); err == nil {
+		t.Error("expected an error for a missing snapshot")
+	}
+	if _, err := prov.ReadSnapshotFile(context.Background(), "sbx-1", "before", "/etc"); err == nil {
+		t.Error("expected an error for a directory")
+	}
+}
+
+func TestRestoreSnapshotFile_ZFS(t *testing.T) {
+	prov, host, vol := zfsTestProvider(t)
+	src := filepath.Join(vol, ".zfs", "snapshot", "before", "etc", "app.conf")
+	writeFile(t, src, "listen 80\n")
+
+	if err := prov.RestoreSnapshotFile(context.Background(), "sbx-1", "before", "/etc/app.conf"); err != nil {
+		t.Fatalf("RestoreSnapshotFile: %v", err)
+	}
+	last := host.calls[len(host.calls)-1]
+	if !strings.HasPrefix(last, "pct push 9001 "+src+" /etc/app.conf --perms 0640 --user ") {
+		t.Errorf("push call = %q", last)
+	}
+}
+
+func TestWithSnapshotRoot_LVMCleansUpOnError(t *testing.T) {
+	mock := newMockProxmox()
+	mock.configs[9001] = CTConfig{RootFS: "local-lvm:vm-9001-disk-0,size=8G"}
+	prov, _ := testProvider(t, mock)
+
+	// /dev/null stands in for the LV's device node.
+	host := &fakeHost{results: map[string]func([]string) ([]byte, error){
+		"pvesm": func([]string) ([]byte, error) { return []byte("/dev/null\n"), nil },
+	}}
+	prov.runHost = host.run
+
+	fnErr := errors.New("read failed")
+	err := prov.withSnapshotRoot(context.Background(), 9001, "before", func(string) error { return fnErr })
+	if !errors.Is(err, fnErr) {
+		t.Fatalf("err = %v, want %v", err, fnErr)
+	}
+	joined := strings.Join(host.calls, "\n")
+	for _, want := range []string{
+		"lvchange -ay -K /dev/snap_null_before",
+		"mount -o ro,noload /dev/snap_null_before ",
+		"umount ",
+		"lvchange -an /dev/snap_null_before",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in calls:\n%s", want, joined)
+		}
+	}
+
+	// A failed mount still deactivates the snapshot LV.
+	host.calls = nil
+	host.results["mount"] = func([]string) ([]byte, error) { return []byte("bad fs"), errors.New("exit status 32") }
+	if err := prov.withSnapshotRoot(context.Background(), 9001, "before", func(string) error { return nil }); err == nil {
+		t.Fatal("expected mount error")
+	}
+	if last := host.calls[len(host.calls)-1]; last != "lvchange -an /dev/snap_null_before" {
+		t.Errorf("last call = %q, want the LV deactivated", last)
+	}
+}
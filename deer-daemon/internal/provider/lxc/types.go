@@ -15,12 +15,15 @@ type CTListEntry struct {
 
 // CTStatus represents the status of an LXC container.
 type CTStatus struct {
-	VMID   int     `json:"vmid"`
-	Name   string  `json:"name"`
-	Status string  `json:"status"` // "running", "stopped"
-	CPU    float64 `json:"cpu"`
-	MaxMem int64   `json:"maxmem"`
-	Mem    int64   `json:"mem"`
+	VMID    int     `json:"vmid"`
+	Name    string  `json:"name"`
+	Status  string  `json:"status"` // "running", "stopped"
+	CPU     float64 `json:"cpu"`    // fraction of CPUs in use
+	CPUs    float64 `json:"cpus"`   // CPUs allocated
+	MaxMem  int64   `json:"maxmem"`
+	Mem     int64   `json:"mem"`
+	MaxDisk int64   `json:"maxdisk"`
+	Disk    int64   `json:"disk"`
 }
 
 // CTConfig represents an LXC container's configuration.
@@ -30,6 +33,7 @@ type CTConfig struct {
 	Cores    int    `json:"cores"`
 	Net0     string `json:"net0,omitempty"`
 	RootFS   string `json:"rootfs,omitempty"`
+	Template int    `json:"template,omitempty"` // 1 if template
 }
 
 // CTInterface represents a network interface from the container.
@@ -64,6 +68,15 @@ type DiskStatus struct {
 	Available int64 `json:"avail"`
 }
 
+// StorageInfo is one entry from a node's storage list.
+type StorageInfo struct {
+	Storage string `json:"storage"`
+	Type    string `json:"type"`
+	Content string `json:"content"` // comma-separated, e.g. "rootdir,images"
+	Active  int    `json:"active"`
+	Enabled int    `json:"enabled"`
+}
+
 // TaskStatus represents the status of an asynchronous Proxmox task.
 type TaskStatus struct {
 	Status     string `json:"status"`               // "running", "stopped"
@@ -0,0 +1,131 @@
+package microvm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/microvm"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+)
+
+// injectSelector picks the SSH key injection method for each base image.
+// Auto-detection results are cached per image path.
+type injectSelector struct {
+	defaultMethod microvm.InjectMethod
+	perImage      map[string]microvm.InjectMethod
+	detect        func(ctx context.Context, imagePath string) (microvm.InjectMethod, string, error)
+
+	mu       sync.Mutex
+	detected map[string]microvm.InjectMethod
+}
+
+func newInjectSelector(def microvm.InjectMethod) *injectSelector {
+	return &injectSelector{
+		defaultMethod: def,
+		perImage:      make(map[string]microvm.InjectMethod),
+		detect:        microvm.DetectInjectMethod,
+		detected:      make(map[string]microvm.InjectMethod),
+	}
+}
+
+// SetInjectMethods configures SSH key injection. def applies to images with
+// no entry in perImage; "" means auto, or none when cloud-init is disabled.
+func (p *Provider) SetInjectMethods(def string, perImage map[string]string) error {
+	sel := newInjectSelector(defaultInjectMethod(p.disableCloudInit))
+	if def != "" {
+		m, err := microvm.ParseInjectMethod(def)
+		if err != nil {
+			return err
+		}
+		sel.defaultMethod = m
+	}
+	for image, method := range perImage {
+		m, err := microvm.ParseInjectMethod(method)
+		if err != nil {
+			return fmt.Errorf("image %s: %w", image, err)
+		}
+		sel.perImage[image] = m
+	}
+	p.inject = sel
+	return nil
+}
+
+// defaultInjectMethod is the method for images without an override when
+// none is configured: pre-baked images when cloud-init is disabled,
+// otherwise whatever each image supports.
+func defaultInjectMethod(disableCloudInit bool) microvm.InjectMethod {
+	if disableCloudInit {
+		return microvm.InjectNone
+	}
+	return microvm.InjectAuto
+}
+
+// fallbackInjectMethod is used when auto-detection cannot inspect an image.
+func (p *Provider) fallbackInjectMethod() microvm.InjectMethod {
+	if p.disableCloudInit {
+		return microvm.InjectNone
+	}
+	return microvm.InjectCloudInit
+}
+
+// injectMethod returns the injection method for a sandbox's base image:
+// a per-image override first, then the configured default, inspecting the
+// image when either is auto.
+func (p *Provider) injectMethod(ctx context.Context, sandboxID, baseImage, imagePath string) microvm.InjectMethod {
+	sel := p.inject
+	if sel == nil {
+		return p.fallbackInjectMethod()
+	}
+
+	method, source := sel.defaultMethod, "default"
+	if m, ok := sel.perImage[baseImage]; ok {
+		method, source = m, "image override"
+	}
+	if method != microvm.InjectAuto {
+		p.logger.Info("SSH key injection method", "sandbox_id", sandboxID, "image", baseImage, "method", method, "source", source)
+		return method
+	}
+
+	sel.mu.Lock()
+	defer sel.mu.Unlock()
+	if m, ok := sel.detected[imagePath]; ok {
+		p.logger.Info("SSH key injection method", "sandbox_id", sandboxID, "image", baseImage, "method", m, "source", "detected (cached)")
+		return m
+	}
+	m, reason, err := sel.detect(ctx, imagePath)
+	if err != nil {
+		m = p.fallbackInjectMethod()
+		p.logger.Warn("could not detect SSH key injection method, using fallback",
+			"sandbox_id", sandboxID, "image", baseImage, "method", m, "error", err)
+		return m
+	}
+	sel.detected[imagePath] = m
+	p.logger.Info("SSH key injection method", "sandbox_id", sandboxID, "image", baseImage, "method", m, "source", "detected", "reason", reason)
+	return m
+}
+
+// prepareGuest gets the daemon's SSH CA into a new sandbox using the
+// injection method chosen for its base image. It returns the cloud-init ISO
+// to attach, which is empty for every method but cloud-init.
+func (p *Provider) prepareGuest(ctx context.Context, req provider.CreateRequest, imagePath, overlayPath string) (string, error) {
+	switch p.injectMethod(ctx, req.SandboxID, req.BaseImage, imagePath) {
+	case microvm.InjectCloudInit:
+		iso, err := microvm.GenerateCloudInitISO(p.vmMgr.WorkDir(), req.SandboxID, microvm.CloudInitOptions{
+			CAPubKey:            p.caPubKey,
+			PhoneHomeURL:        p.phoneHomeURL(req.SandboxID),
+			KafkaBroker:         kafkaBrokerOptions(req),
+			ElasticsearchBroker: elasticsearchBrokerOptions(req),
+			RedpandaCacheURL:    p.redpandaCacheURL,
+		})
+		if err != nil {
+			return "", fmt.Errorf("generate cloud-init ISO: %w", err)
+		}
+		return iso, nil
+	case microvm.InjectVirtCustomize:
+		if err := microvm.InjectSSHCA(ctx, overlayPath, p.caPubKey); err != nil {
+			return "", fmt.Errorf("inject SSH CA: %w", err)
+		}
+	}
+	return "", nil
+}
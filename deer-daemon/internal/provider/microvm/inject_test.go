@@ -0,0 +1,81 @@
+package microvm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/microvm"
+)
+
+func TestInjectMethod(t *testing.T) {
+	detections := 0
+	detect := func(_ context.Context, imagePath string) (microvm.InjectMethod, string, error) {
+		detections++
+		switch imagePath {
+		case "/images/ubuntu.qcow2":
+			return microvm.InjectCloudInit, "cloud-init installed", nil
+		case "/images/custom.qcow2":
+			return microvm.InjectVirtCustomize, "no cloud-init in image", nil
+		}
+		return "", "", fmt.Errorf("virt-ls: not found")
+	}
+
+	tests := []struct {
+		name             string
+		disableCloudInit bool
+		def              string
+		perImage         map[string]string
+		image            string
+		want             microvm.InjectMethod
+	}{
+		{name: "auto detects cloud-init", image: "ubuntu", want: microvm.InjectCloudInit},
+		{name: "auto detects virt-customize", image: "custom", want: microvm.InjectVirtCustomize},
+		{name: "auto falls back to cloud-init", image: "broken", want: microvm.InjectCloudInit},
+		{name: "disable_cloudinit falls back to none", disableCloudInit: true, def: "auto", image: "broken", want: microvm.InjectNone},
+		{name: "disable_cloudinit defaults to none", disableCloudInit: true, image: "ubuntu", want: microvm.InjectNone},
+		{name: "configured default", def: "virt-customize", image: "ubuntu", want: microvm.InjectVirtCustomize},
+		{name: "image override wins", def: "cloud-init", perImage: map[string]string{"win2022": "none"}, image: "win2022", want: microvm.InjectNone},
+		{name: "image override can auto-detect", def: "none", perImage: map[string]string{"custom": "auto"}, image: "custom", want: microvm.InjectVirtCustomize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Provider{disableCloudInit: tt.disableCloudInit, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+			if err := p.SetInjectMethods(tt.def, tt.perImage); err != nil {
+				t.Fatalf("SetInjectMethods: %v", err)
+			}
+			p.inject.detect = detect
+			got := p.injectMethod(context.Background(), "sbx-1", tt.image, "/images/"+tt.image+".qcow2")
+			if got != tt.want {
+				t.Errorf("injectMethod = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("detection is cached per image", func(t *testing.T) {
+		p := &Provider{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+		if err := p.SetInjectMethods("", nil); err != nil {
+			t.Fatal(err)
+		}
+		p.inject.detect = detect
+		detections = 0
+		for range 3 {
+			p.injectMethod(context.Background(), "sbx-1", "ubuntu", "/images/ubuntu.qcow2")
+		}
+		if detections != 1 {
+			t.Errorf("detections = %d, want 1", detections)
+		}
+	})
+}
+
+func TestSetInjectMethods_RejectsUnknown(t *testing.T) {
+	p := &Provider{}
+	if err := p.SetInjectMethods("ignition", nil); err == nil {
+		t.Error("unknown default accepted")
+	}
+	if err := p.SetInjectMethods("", map[string]string{"ubuntu": "bogus"}); err == nil {
+		t.Error("unknown per-image method accepted")
+	}
+}
@@ -0,0 +1,51 @@
+package microvm
+
+import (
+	"sync"
+	"time"
+)
+
+// ipRefreshTimeout bounds rediscovery when a previously known IP can be
+// used as a fallback, so a stale cache entry never costs a full discovery.
+const ipRefreshTimeout = 5 * time.Second
+
+// ipCache remembers resolved sandbox IPs. An entry is kept until connecting
+// to it fails or the sandbox stops, so commands only rediscover an IP that
+// has actually moved. A nil *ipCache never caches.
+type ipCache struct {
+	mu      sync.Mutex
+	entries map[string]string // sandbox ID -> IP
+}
+
+func newIPCache() *ipCache {
+	return &ipCache{entries: make(map[string]string)}
+}
+
+// get returns the cached IP for sandboxID.
+func (c *ipCache) get(sandboxID string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ip, ok := c.entries[sandboxID]
+	return ip, ok
+}
+
+func (c *ipCache) put(sandboxID, ip string) {
+	if c == nil || ip == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[sandboxID] = ip
+}
+
+func (c *ipCache) invalidate(sandboxID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, sandboxID)
+}
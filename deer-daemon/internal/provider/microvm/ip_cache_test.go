@@ -0,0 +1,36 @@
+package microvm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIPCache_Invalidate(t *testing.T) {
+	c := newIPCache()
+	c.put("SBX-1", "10.0.0.5")
+	c.invalidate("SBX-1")
+	if _, ok := c.get("SBX-1"); ok {
+		t.Error("entry served after invalidate")
+	}
+}
+
+func TestResolveIP_UsesFreshCacheEntry(t *testing.T) {
+	// No VM manager: a cache hit must not need one.
+	p := &Provider{ips: newIPCache()}
+	p.ips.put("SBX-1", "10.0.0.5")
+
+	ip, err := p.resolveIP(context.Background(), "SBX-1")
+	if err != nil {
+		t.Fatalf("resolveIP: %v", err)
+	}
+	if ip != "10.0.0.5" {
+		t.Errorf("ip = %q, want 10.0.0.5", ip)
+	}
+
+	if err := p.StopSandbox(context.Background(), "SBX-1", false); err == nil {
+		t.Fatal("expected StopSandbox to fail without a VM manager")
+	}
+	if _, err := p.resolveIP(context.Background(), "SBX-1"); err == nil {
+		t.Error("resolveIP served a cached IP after StopSandbox")
+	}
+}
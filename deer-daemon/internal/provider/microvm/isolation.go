@@ -0,0 +1,58 @@
+package microvm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/network"
+)
+
+// isolationPingTimeout bounds the ping VerifyIsolation runs in a sandbox.
+const isolationPingTimeout = 30 * time.Second
+
+// createTAP creates a sandbox's TAP on bridge and applies the network
+// manager's isolation mode to it. A TAP that cannot be isolated is removed,
+// so the sandbox is never started on a shared bridge it should not reach.
+func (p *Provider) createTAP(ctx context.Context, sandboxID, bridge string) (string, error) {
+	tapName, err := network.CreateTAP(ctx, network.TAPName(sandboxID), bridge, p.logger)
+	if err != nil {
+		return "", fmt.Errorf("create TAP: %w", err)
+	}
+	if p.netMgr != nil {
+		if err := p.netMgr.IsolateTAP(ctx, tapName, bridge); err != nil {
+			_ = p.netMgr.ReleaseTAP(ctx, tapName)
+			_ = network.DestroyTAP(ctx, tapName)
+			return "", err
+		}
+	}
+	return tapName, nil
+}
+
+// destroyTAP removes a sandbox's TAP and any isolation rules for it.
+func (p *Provider) destroyTAP(ctx context.Context, tapName string) {
+	if p.netMgr != nil {
+		if err := p.netMgr.ReleaseTAP(ctx, tapName); err != nil {
+			p.logger.Warn("remove TAP isolation rules failed", "tap", tapName, "error", err)
+		}
+	}
+	_ = network.DestroyTAP(ctx, tapName)
+}
+
+// VerifyIsolation pings sandbox sbB from inside sandbox sbA and reports
+// whether the ping was blocked, as it should be with isolation_mode
+// bridge-private.
+func (p *Provider) VerifyIsolation(ctx context.Context, sbA, sbB string) (*network.IsolationCheck, error) {
+	ipB, err := p.GetSandboxIP(ctx, sbB)
+	if err != nil {
+		return nil, fmt.Errorf("get IP of %s: %w", sbB, err)
+	}
+	run := func(ctx context.Context, sandboxID, command string) (int, string, error) {
+		res, err := p.RunProbe(ctx, sandboxID, command, isolationPingTimeout)
+		if err != nil {
+			return 0, "", err
+		}
+		return res.ExitCode, res.Stdout + res.Stderr, nil
+	}
+	return network.VerifyIsolation(ctx, run, sbA, sbB, ipB)
+}
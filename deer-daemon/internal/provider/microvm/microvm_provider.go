@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
@@ -19,11 +20,13 @@ import (
 
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/id"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/image"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/metrics"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/microvm"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/network"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/sourcevm"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/sshkeys"
+	"github.com/aspectrr/deer.sh/shared/multierr"
 )
 
 // ReadinessWaiter can wait for a sandbox to signal readiness via phone_home.
@@ -52,8 +55,16 @@ type Provider struct {
 	readiness         ReadinessWaiter
 	redpandaCacheURL  string // local Redpanda tarball for faster boot
 	disableCloudInit  bool   // skip cloud-init for pre-baked images
+	inject            *injectSelector
 	socketVMNetClient string // macOS: path to socket_vmnet_client binary
 	socketVMNetPath   string // macOS: Unix socket path for socket_vmnet daemon
+	sshRetry          *sshRetryBackoff
+	retryBudget       *sshRetryBudget
+	probeBudget       *sshRetryBudget // separate budget for RunProbe
+	ips               *ipCache
+	metrics           *metrics.Metrics // nil unless metrics are enabled
+	hostKeyMode       string           // see SetHostKeyMode
+	knownHostsFile    string           // operator's known_hosts for strict mode
 	logger            *slog.Logger
 }
 
@@ -104,13 +115,62 @@ func New(
 		readiness:         readiness,
 		redpandaCacheURL:  redpandaCacheURL,
 		disableCloudInit:  disableCloudInit,
+		inject:            newInjectSelector(defaultInjectMethod(disableCloudInit)),
 		socketVMNetClient: socketVMNetClient,
 		socketVMNetPath:   socketVMNetPath,
+		sshRetry:          newSSHRetryBackoff(),
+		retryBudget:       newSSHRetryBudget(),
+		probeBudget:       newSSHRetryBudget(),
+		ips:               newIPCache(),
 		logger:            logger.With("provider", "microvm"),
 	}
 }
 
+// SetMetrics makes the provider count created and destroyed sandboxes,
+// failed operations and command durations in m.
+func (p *Provider) SetMetrics(m *metrics.Metrics) {
+	p.metrics = m
+	m.SetActiveSandboxes(p.ActiveSandboxCount)
+}
+
+// SetHostKeyMode sets how sandbox host keys are checked: "insecure" (or
+// empty, the default), "tofu", or "strict" with knownHostsFile.
+func (p *Provider) SetHostKeyMode(mode, knownHostsFile string) error {
+	if err := sshkeys.ValidateHostKeyMode(mode, knownHostsFile); err != nil {
+		return err
+	}
+	p.hostKeyMode = mode
+	p.knownHostsFile = knownHostsFile
+	return nil
+}
+
+// hostKeyArgs returns the ssh options checking the host key of the sandbox
+// creds are for.
+func (p *Provider) hostKeyArgs(creds *sshkeys.Credentials) []string {
+	return sshkeys.HostKeyArgs(p.hostKeyMode, p.knownHostsFile, creds)
+}
+
+// observe counts the outcome of a provider operation.
+func (p *Provider) observe(op string, err error) {
+	if err != nil {
+		p.metrics.ProviderError(op)
+		return
+	}
+	switch op {
+	case "create":
+		p.metrics.SandboxCreated()
+	case "destroy":
+		p.metrics.SandboxDestroyed()
+	}
+}
+
 func (p *Provider) CreateSandbox(ctx context.Context, req provider.CreateRequest) (*provider.SandboxResult, error) {
+	res, err := p.createSandbox(ctx, req)
+	p.observe("create", err)
+	return res, err
+}
+
+func (p *Provider) createSandbox(ctx context.Context, req provider.CreateRequest) (*provider.SandboxResult, error) {
 	if p.vmMgr == nil {
 		return nil, fmt.Errorf("microVM manager not available")
 	}
@@ -161,30 +221,23 @@ func (p *Provider) CreateSandbox(ctx context.Context, req provider.CreateRequest
 		return nil, fmt.Errorf("create overlay: %w", err)
 	}
 
-	// Generate cloud-init NoCloud ISO with catch-all DHCP config so the
-	// sandbox gets an IP regardless of the source VM's interface naming.
-	cloudInitISO, err := microvm.GenerateCloudInitISO(p.vmMgr.WorkDir(), req.SandboxID, microvm.CloudInitOptions{
-		CAPubKey:            p.caPubKey,
-		PhoneHomeURL:        p.phoneHomeURL(req.SandboxID),
-		KafkaBroker:         kafkaBrokerOptions(req),
-		ElasticsearchBroker: elasticsearchBrokerOptions(req),
-		RedpandaCacheURL:    p.redpandaCacheURL,
-		Disable:             p.disableCloudInit,
-	})
+	// Get the SSH CA into the guest. With cloud-init this is a NoCloud ISO
+	// with catch-all DHCP config so the sandbox gets an IP regardless of the
+	// source VM's interface naming.
+	cloudInitISO, err := p.prepareGuest(ctx, req, imagePath, overlayPath)
 	if err != nil {
 		_ = microvm.RemoveOverlay(p.vmMgr.WorkDir(), req.SandboxID)
-		return nil, fmt.Errorf("generate cloud-init ISO: %w", err)
+		return nil, err
 	}
 
 	// Generate MAC address; create TAP device unless using socket_vmnet
 	mac := microvm.GenerateMACAddress()
 	tapName := ""
 	if p.socketVMNetClient == "" {
-		tapName = network.TAPName(req.SandboxID)
-		tapName, err = network.CreateTAP(ctx, tapName, bridge, p.logger)
+		tapName, err = p.createTAP(ctx, req.SandboxID, bridge)
 		if err != nil {
 			_ = microvm.RemoveOverlay(p.vmMgr.WorkDir(), req.SandboxID)
-			return nil, fmt.Errorf("create TAP: %w", err)
+			return nil, err
 		}
 	}
 
@@ -214,7 +267,7 @@ func (p *Provider) CreateSandbox(ctx context.Context, req provider.CreateRequest
 		return nil, fmt.Errorf("launch microVM: %w", err)
 	}
 
-	return p.completeCreate(ctx, req, info, mac, bridge, tapName)
+	return p.completeCreate(ctx, req, info, mac, bridge, tapName, cloudInitISO != "")
 }
 
 // ProgressFunc is called to report sandbox creation progress.
@@ -222,6 +275,12 @@ type ProgressFunc func(step string, stepNum, total int)
 
 // CreateSandboxWithProgress creates a sandbox while reporting granular progress.
 func (p *Provider) CreateSandboxWithProgress(ctx context.Context, req provider.CreateRequest, progress ProgressFunc) (*provider.SandboxResult, error) {
+	res, err := p.createSandboxWithProgress(ctx, req, progress)
+	p.observe("create", err)
+	return res, err
+}
+
+func (p *Provider) createSandboxWithProgress(ctx context.Context, req provider.CreateRequest, progress ProgressFunc) (*provider.SandboxResult, error) {
 	if p.vmMgr == nil {
 		return nil, fmt.Errorf("microVM manager not available")
 	}
@@ -270,19 +329,12 @@ func (p *Provider) CreateSandboxWithProgress(ctx context.Context, req provider.C
 		return nil, fmt.Errorf("create overlay: %w", err)
 	}
 
-	// Step 3: Generate cloud-init
-	progress("Generating cloud-init", 3, totalSteps)
-	cloudInitISO, err := microvm.GenerateCloudInitISO(p.vmMgr.WorkDir(), req.SandboxID, microvm.CloudInitOptions{
-		CAPubKey:            p.caPubKey,
-		PhoneHomeURL:        p.phoneHomeURL(req.SandboxID),
-		KafkaBroker:         kafkaBrokerOptions(req),
-		ElasticsearchBroker: elasticsearchBrokerOptions(req),
-		RedpandaCacheURL:    p.redpandaCacheURL,
-		Disable:             p.disableCloudInit,
-	})
+	// Step 3: Inject the SSH CA (cloud-init or virt-customize)
+	progress("Preparing guest SSH access", 3, totalSteps)
+	cloudInitISO, err := p.prepareGuest(ctx, req, imagePath, overlayPath)
 	if err != nil {
 		_ = microvm.RemoveOverlay(p.vmMgr.WorkDir(), req.SandboxID)
-		return nil, fmt.Errorf("generate cloud-init ISO: %w", err)
+		return nil, err
 	}
 
 	// Step 4: Set up network (TAP or socket_vmnet)
@@ -290,11 +342,10 @@ func (p *Provider) CreateSandboxWithProgress(ctx context.Context, req provider.C
 	mac := microvm.GenerateMACAddress()
 	tapName := ""
 	if p.socketVMNetClient == "" {
-		tapName = network.TAPName(req.SandboxID)
-		tapName, err = network.CreateTAP(ctx, tapName, bridge, p.logger)
+		tapName, err = p.createTAP(ctx, req.SandboxID, bridge)
 		if err != nil {
 			_ = microvm.RemoveOverlay(p.vmMgr.WorkDir(), req.SandboxID)
-			return nil, fmt.Errorf("create TAP: %w", err)
+			return nil, err
 		}
 	}
 
@@ -328,16 +379,19 @@ func (p *Provider) CreateSandboxWithProgress(ctx context.Context, req provider.C
 	// Step 6: Discover IP
 	progress("Discovering IP address", 6, totalSteps)
 	progress("Waiting for cloud-init ready", 7, totalSteps)
-	return p.completeCreate(ctx, req, info, mac, bridge, tapName)
+	return p.completeCreate(ctx, req, info, mac, bridge, tapName, cloudInitISO != "")
 }
 
 func (p *Provider) DestroySandbox(ctx context.Context, sandboxID string) error {
+	p.ips.invalidate(sandboxID)
+	p.retryBudget.reset(sandboxID)
+	p.probeBudget.reset(sandboxID)
 	if p.vmMgr == nil {
 		return nil
 	}
 	info, err := p.vmMgr.Get(sandboxID)
 	if err == nil && info.TAPDevice != "" {
-		_ = network.DestroyTAP(ctx, info.TAPDevice)
+		p.destroyTAP(ctx, info.TAPDevice)
 	}
 	var destroyErr error
 	if err := p.vmMgr.Destroy(ctx, sandboxID); err != nil {
@@ -345,16 +399,64 @@ func (p *Provider) DestroySandbox(ctx context.Context, sandboxID string) error {
 		destroyErr = err
 	}
 	_ = microvm.RemoveOverlay(p.vmMgr.WorkDir(), sandboxID)
+	p.observe("destroy", destroyErr)
 	return destroyErr
 }
 
+// ArchiveSandbox flattens the sandbox's overlay into a standalone QCOW2 image
+// in destDir. When destDir is the base image directory, the archive can be
+// used as a base image for new sandboxes.
+func (p *Provider) ArchiveSandbox(ctx context.Context, sandboxID, destDir string) (*provider.ArchiveResult, error) {
+	if p.vmMgr == nil {
+		return nil, fmt.Errorf("microVM manager not available")
+	}
+	name := fmt.Sprintf("archive-%s-%s.qcow2", strings.ToLower(sandboxID), time.Now().UTC().Format("20060102-150405"))
+	dest := filepath.Join(destDir, name)
+	if err := microvm.ExportOverlay(ctx, p.vmMgr.WorkDir(), sandboxID, dest); err != nil {
+		return nil, err
+	}
+	result := &provider.ArchiveResult{Path: dest}
+	if fi, err := os.Stat(dest); err == nil {
+		result.SizeBytes = fi.Size()
+	}
+	return result, nil
+}
+
+// SandboxStats reports the QEMU process's CPU time and resident memory and
+// the overlay's allocation on the host. Process stats need a Linux host.
+func (p *Provider) SandboxStats(_ context.Context, sandboxID string) (*provider.SandboxStats, error) {
+	if p.vmMgr == nil {
+		return nil, fmt.Errorf("microVM manager not available")
+	}
+	info, err := p.vmMgr.Get(sandboxID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", provider.ErrSandboxNotFound, err)
+	}
+	stats := &provider.SandboxStats{MemoryLimitBytes: int64(info.MemoryMB) << 20}
+	if info.State == microvm.StateRunning {
+		stats.CPUTime, stats.MemoryBytes, err = microvm.ProcessStats(info.PID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stats.DiskAllocatedBytes, err = microvm.DiskAllocation(p.vmMgr.WorkDir(), sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
 func (p *Provider) StartSandbox(ctx context.Context, sandboxID string) (*provider.SandboxResult, error) {
+	p.ips.invalidate(sandboxID)
+	p.retryBudget.reset(sandboxID)
+	p.probeBudget.reset(sandboxID)
 	if p.vmMgr == nil {
 		return nil, fmt.Errorf("microVM manager not available")
 	}
 
 	info, err := p.vmMgr.Get(sandboxID)
 	if err != nil {
+		p.observe("start", err)
 		return nil, fmt.Errorf("get sandbox: %w", err)
 	}
 
@@ -374,10 +476,15 @@ func (p *Provider) StartSandbox(ctx context.Context, sandboxID string) (*provide
 }
 
 func (p *Provider) StopSandbox(ctx context.Context, sandboxID string, force bool) error {
+	p.ips.invalidate(sandboxID)
+	p.retryBudget.reset(sandboxID)
+	p.probeBudget.reset(sandboxID)
 	if p.vmMgr == nil {
 		return fmt.Errorf("microVM manager not available")
 	}
-	return p.vmMgr.Stop(ctx, sandboxID, force)
+	err := p.vmMgr.Stop(ctx, sandboxID, force)
+	p.observe("stop", err)
+	return err
 }
 
 func (p *Provider) GetSandboxIP(ctx context.Context, sandboxID string) (string, error) {
@@ -424,36 +531,46 @@ func (p *Provider) CreateSnapshot(_ context.Context, sandboxID, name string) (*p
 }
 
 func (p *Provider) RunCommand(ctx context.Context, sandboxID, command string, timeout time.Duration) (*provider.CommandResult, error) {
-	if p.vmMgr == nil {
-		return nil, fmt.Errorf("microVM manager not available")
-	}
+	return p.RunCommandWithOptions(ctx, sandboxID, command, timeout, provider.CommandOptions{})
+}
 
-	info, err := p.vmMgr.Get(sandboxID)
-	if err != nil {
-		return nil, fmt.Errorf("get sandbox: %w", err)
-	}
+// RunCommandWithOptions runs a command over SSH, optionally forwarding the
+// daemon's SSH agent and offering extra identities.
+func (p *Provider) RunCommandWithOptions(ctx context.Context, sandboxID, command string, timeout time.Duration, opts provider.CommandOptions) (*provider.CommandResult, error) {
+	return p.runCommand(ctx, sandboxID, command, timeout, opts, nil, p.retryBudget)
+}
 
-	ip := info.IPAddress
-	if ip == "" && p.netMgr != nil {
-		var discoverErr error
-		ip, discoverErr = p.netMgr.DiscoverIP(ctx, info.MACAddress, info.Bridge, p.resolvedIPDiscoveryTimeout())
-		if discoverErr != nil {
-			p.logger.Warn("IP discovery failed in RunCommand", "sandbox_id", sandboxID, "error", discoverErr)
-		}
-		if ip != "" {
-			p.vmMgr.SetIP(sandboxID, ip)
-		}
-	}
-	if ip == "" {
-		return nil, fmt.Errorf("unable to discover sandbox IP for SSH")
-	}
+// RunProbe runs a command the daemon issues on its own behalf, such as a
+// disk usage check, against a retry budget of its own, so probes neither
+// use up the budget of user commands nor are refused once it is spent.
+func (p *Provider) RunProbe(ctx context.Context, sandboxID, command string, timeout time.Duration) (*provider.CommandResult, error) {
+	return p.runCommand(ctx, sandboxID, command, timeout, provider.CommandOptions{}, nil, p.probeBudget)
+}
 
-	if p.keyMgr == nil {
-		return nil, fmt.Errorf("SSH key manager not available - cannot connect to sandbox")
+// RunCommandStreaming is RunCommandWithOptions with output passed to
+// onOutput as the command writes it. If the SSH connection has to be
+// retried, ssh's own diagnostics from the failed attempts may appear on
+// stderr before the command's output.
+func (p *Provider) RunCommandStreaming(ctx context.Context, sandboxID, command string, timeout time.Duration, opts provider.CommandOptions, onOutput provider.OutputFunc) (*provider.CommandResult, error) {
+	return p.runCommand(ctx, sandboxID, command, timeout, opts, onOutput, p.retryBudget)
+}
+
+func (p *Provider) runCommand(ctx context.Context, sandboxID, command string, timeout time.Duration, opts provider.CommandOptions, onOutput provider.OutputFunc, budget *sshRetryBudget) (*provider.CommandResult, error) {
+	res, err := p.runCommandAttempts(ctx, sandboxID, command, timeout, opts, onOutput, budget)
+	if err != nil {
+		p.observe("run_command", err)
+		return nil, err
 	}
-	creds, err := p.keyMgr.GetCredentials(ctx, sandboxID, "sandbox")
+	p.metrics.ObserveCommand(time.Duration(res.DurationMS) * time.Millisecond)
+	return res, nil
+}
+
+// runCommandAttempts runs a command, retrying while sshd comes up. Retries
+// are spent from budget.
+func (p *Provider) runCommandAttempts(ctx context.Context, sandboxID, command string, timeout time.Duration, opts provider.CommandOptions, onOutput provider.OutputFunc, budget *sshRetryBudget) (*provider.CommandResult, error) {
+	ip, creds, err := p.sshTarget(ctx, sandboxID)
 	if err != nil {
-		return nil, fmt.Errorf("get sandbox SSH credentials: %w", err)
+		return nil, err
 	}
 
 	if timeout == 0 {
@@ -462,24 +579,45 @@ func (p *Provider) RunCommand(ctx context.Context, sandboxID, command string, ti
 
 	// Retry loop: sshd may not be ready yet after IP is assigned.
 	const maxRetries = 6
-	const retryDelay = 5 * time.Second
+	backoff := p.sshRetry
+	if backoff == nil {
+		backoff = newSSHRetryBackoff()
+	}
 
 	start := time.Now()
 	var stdout, stderr string
 	var exitCode int
+	renewed := false
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		stdout, stderr, exitCode, err = runSSHCommand(ctx, ip, creds, command, timeout)
+		stdout, stderr, exitCode, err = runSSHCommandStreaming(ctx, ip, creds, p.hostKeyArgs(creds), command, timeout, opts, onOutput)
 		if err == nil {
 			break
 		}
+		errMsg := err.Error()
+
+		// A certificate that expired while cached or between retries is
+		// reissued once, and the command retried straight away.
+		if !renewed && certExpired(errMsg, creds, time.Now()) {
+			renewed = true
+			fresh, renewErr := p.keyMgr.RenewCredentials(ctx, sandboxID, creds.Username)
+			if renewErr != nil {
+				return nil, fmt.Errorf("run command: %w (renew expired SSH certificate: %v)", err, renewErr)
+			}
+			p.logger.Info("SSH certificate expired, renewed it and retrying",
+				"sandbox_id", sandboxID,
+				"valid_until", fresh.ValidUntil,
+			)
+			creds = fresh
+			continue
+		}
 
 		// Retry on transient errors: sshd not yet listening, or cert auth
 		// not yet configured (cloud-init still running).
-		errMsg := err.Error()
 		isTransient := strings.Contains(errMsg, "Connection refused") ||
 			strings.Contains(errMsg, "Connection reset") ||
 			strings.Contains(errMsg, "No route to host") ||
+			strings.Contains(errMsg, "Connection timed out") ||
 			strings.Contains(errMsg, "connection refused") ||
 			strings.Contains(errMsg, "connection reset") ||
 			strings.Contains(errMsg, "Permission denied") ||
@@ -489,21 +627,37 @@ func (p *Provider) RunCommand(ctx context.Context, sandboxID, command string, ti
 		if !isTransient || attempt == maxRetries {
 			return nil, fmt.Errorf("run command: %w", err)
 		}
+		if !budget.take(sandboxID, time.Now()) {
+			return nil, fmt.Errorf("run command: %w: over %d SSH retries in the last %s, last error: %v",
+				provider.ErrSandboxUnhealthy, budget.Limit, budget.Window, err)
+		}
 
+		// An unreachable address may mean the cached IP went stale (e.g. a
+		// new DHCP lease after restart); rediscover before the next attempt.
+		if ipUnreachable(errMsg) {
+			p.ips.invalidate(sandboxID)
+			if fresh, rerr := p.resolveIP(ctx, sandboxID); rerr == nil {
+				ip = fresh
+			}
+		}
+
+		delay := backoff.Delay(attempt)
 		p.logger.Info("SSH connection failed, retrying (sshd may still be starting)",
 			"sandbox_id", sandboxID,
 			"attempt", attempt+1,
 			"max_retries", maxRetries,
+			"delay", delay,
 			"error", err,
 		)
 
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-time.After(retryDelay):
+		case <-time.After(delay):
 		}
 	}
 
+	budget.reset(sandboxID)
 	return &provider.CommandResult{
 		Stdout:     stdout,
 		Stderr:     stderr,
@@ -512,6 +666,133 @@ func (p *Provider) RunCommand(ctx context.Context, sandboxID, command string, ti
 	}, nil
 }
 
+// sshTarget resolves a sandbox's IP and the credentials used to SSH into it.
+func (p *Provider) sshTarget(ctx context.Context, sandboxID string) (string, *sshkeys.Credentials, error) {
+	ip, err := p.resolveIP(ctx, sandboxID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if p.keyMgr == nil {
+		return "", nil, fmt.Errorf("SSH key manager not available - cannot connect to sandbox")
+	}
+	creds, err := p.keyMgr.GetCredentials(ctx, sandboxID, "sandbox")
+	if err != nil {
+		return "", nil, fmt.Errorf("get sandbox SSH credentials: %w", err)
+	}
+	return ip, creds, nil
+}
+
+// ipUnreachable reports whether an SSH failure means nothing answered at the
+// sandbox's IP, which may have moved since it was cached.
+func ipUnreachable(errMsg string) bool {
+	return strings.Contains(errMsg, "No route to host") || strings.Contains(errMsg, "Connection timed out")
+}
+
+// certExpired reports whether an SSH failure is the sandbox refusing an
+// expired certificate: ssh names the expiry, or authentication was denied
+// after the certificate's validity ended.
+func certExpired(errMsg string, creds *sshkeys.Credentials, now time.Time) bool {
+	lower := strings.ToLower(errMsg)
+	if strings.Contains(lower, "certificate expired") || strings.Contains(lower, "certificate invalid: expired") {
+		return true
+	}
+	return strings.Contains(errMsg, "Permission denied") && !creds.ValidUntil.IsZero() && !now.Before(creds.ValidUntil)
+}
+
+// resolveIP returns the cached sandbox IP. Without one it discovers the IP,
+// falling back to the last known address when discovery comes up empty, and
+// caches the result until a connection to it fails.
+func (p *Provider) resolveIP(ctx context.Context, sandboxID string) (string, error) {
+	if ip, ok := p.ips.get(sandboxID); ok {
+		return ip, nil
+	}
+	if p.vmMgr == nil {
+		return "", fmt.Errorf("microVM manager not available")
+	}
+
+	info, err := p.vmMgr.Get(sandboxID)
+	if err != nil {
+		return "", fmt.Errorf("get sandbox: %w", err)
+	}
+
+	ip := info.IPAddress
+	if p.netMgr != nil {
+		timeout := p.resolvedIPDiscoveryTimeout()
+		if ip != "" && timeout > ipRefreshTimeout {
+			timeout = ipRefreshTimeout
+		}
+		found, discoverErr := p.netMgr.DiscoverIP(ctx, info.MACAddress, info.Bridge, timeout)
+		if discoverErr != nil {
+			p.logger.Warn("IP discovery failed in RunCommand", "sandbox_id", sandboxID, "error", discoverErr)
+		}
+		if found != "" {
+			if found != ip {
+				p.vmMgr.SetIP(sandboxID, found)
+			}
+			ip = found
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("unable to discover sandbox IP for SSH")
+	}
+
+	p.ips.put(sandboxID, ip)
+	return ip, nil
+}
+
+// RunCommandBatch runs commands in order over a single SSH ControlMaster
+// connection, so only the first command pays for the handshake.
+func (p *Provider) RunCommandBatch(ctx context.Context, sandboxID string, commands []string, timeout time.Duration, stopOnError bool) ([]*provider.CommandResult, error) {
+	ip, creds, err := p.sshTarget(ctx, sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+
+	dir, err := os.MkdirTemp("", "deer-ssh-")
+	if err != nil {
+		return nil, fmt.Errorf("create control socket dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	controlPath := filepath.Join(dir, "cm")
+	control := []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + controlPath,
+		"-o", "ControlPersist=60",
+	}
+	defer func() {
+		exitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = exec.CommandContext(exitCtx, "ssh", "-o", "ControlPath="+controlPath, "-O", "exit",
+			fmt.Sprintf("%s@%s", creds.Username, ip)).Run()
+	}()
+
+	results := make([]*provider.CommandResult, 0, len(commands))
+	for _, command := range commands {
+		start := time.Now()
+		stdout, stderr, exitCode, err := runSSHCommand(ctx, ip, creds, p.hostKeyArgs(creds), command, timeout, provider.CommandOptions{}, control...)
+		if err != nil {
+			if ipUnreachable(err.Error()) {
+				p.ips.invalidate(sandboxID)
+			}
+			return results, fmt.Errorf("run command %q: %w", command, err)
+		}
+		results = append(results, &provider.CommandResult{
+			Stdout:     stdout,
+			Stderr:     stderr,
+			ExitCode:   exitCode,
+			DurationMS: time.Since(start).Milliseconds(),
+		})
+		if stopOnError && exitCode != 0 {
+			break
+		}
+	}
+	return results, nil
+}
+
 func (p *Provider) ListTemplates(_ context.Context) ([]string, error) {
 	if p.imgStore == nil {
 		return nil, nil
@@ -555,6 +836,7 @@ func (p *Provider) ValidateSourceVM(ctx context.Context, vmName string) (*provid
 		HasNetwork: result.HasNetwork,
 		Warnings:   result.Warnings,
 		Errors:     result.Errors,
+		Issues:     result.Issues,
 	}, nil
 }
 
@@ -696,7 +978,9 @@ func (p *Provider) applyReadinessIPFallback(sandboxID, discoveredIP string) stri
 	return discoveredIP
 }
 
-func (p *Provider) completeCreate(ctx context.Context, req provider.CreateRequest, info *microvm.SandboxInfo, mac, bridge, tapName string) (*provider.SandboxResult, error) {
+// completeCreate discovers a new sandbox's IP and, when waitReady is set
+// because cloud-init will phone home, waits for the guest to report ready.
+func (p *Provider) completeCreate(ctx context.Context, req provider.CreateRequest, info *microvm.SandboxInfo, mac, bridge, tapName string, waitReady bool) (*provider.SandboxResult, error) {
 	ip := ""
 	if p.netMgr != nil {
 		discoveredIP, err := p.netMgr.DiscoverIP(ctx, mac, bridge, p.resolvedIPDiscoveryTimeout())
@@ -707,12 +991,14 @@ func (p *Provider) completeCreate(ctx context.Context, req provider.CreateReques
 	}
 	ip = p.applyReadinessIPFallback(req.SandboxID, ip)
 
-	if err := p.waitForReadiness(ctx, req.SandboxID, info.PID); err != nil {
-		cleanupErr := p.cleanupFailedCreate(context.Background(), req.SandboxID, tapName)
-		if cleanupErr != nil {
-			return nil, fmt.Errorf("%w\ncleanup_error: %v\nhost_diagnostics:\n%s", err, cleanupErr, sandboxHostDiagnostics(p.vmMgr.WorkDir(), req.SandboxID, info.PID))
+	if waitReady {
+		if err := p.waitForReadiness(ctx, req.SandboxID, info.PID); err != nil {
+			cleanupErr := p.cleanupFailedCreate(context.Background(), req.SandboxID, tapName)
+			if cleanupErr != nil {
+				return nil, fmt.Errorf("%w\ncleanup_error: %v\nhost_diagnostics:\n%s", err, cleanupErr, sandboxHostDiagnostics(p.vmMgr.WorkDir(), req.SandboxID, info.PID))
+			}
+			return nil, fmt.Errorf("%w\nhost_diagnostics:\n%s", err, sandboxHostDiagnostics(p.vmMgr.WorkDir(), req.SandboxID, info.PID))
 		}
-		return nil, fmt.Errorf("%w\nhost_diagnostics:\n%s", err, sandboxHostDiagnostics(p.vmMgr.WorkDir(), req.SandboxID, info.PID))
 	}
 
 	ip = p.applyReadinessIPFallback(req.SandboxID, ip)
@@ -778,21 +1064,21 @@ func (p *Provider) sandboxRunning(sandboxID string, _ int) (bool, microvm.Sandbo
 }
 
 func (p *Provider) cleanupFailedCreate(ctx context.Context, sandboxID, tapName string) error {
-	var errs []string
+	var errs []error
 	if tapName != "" {
+		if p.netMgr != nil {
+			_ = p.netMgr.ReleaseTAP(ctx, tapName)
+		}
 		if err := network.DestroyTAP(ctx, tapName); err != nil {
-			errs = append(errs, fmt.Sprintf("destroy TAP %s: %v", tapName, err))
+			errs = append(errs, fmt.Errorf("destroy TAP %s: %w", tapName, err))
 		}
 	}
 	if p.vmMgr != nil {
 		if err := p.vmMgr.Destroy(ctx, sandboxID); err != nil {
-			errs = append(errs, fmt.Sprintf("destroy sandbox %s: %v", sandboxID, err))
+			errs = append(errs, fmt.Errorf("destroy sandbox %s: %w", sandboxID, err))
 		}
 	}
-	if len(errs) == 0 {
-		return nil
-	}
-	return fmt.Errorf("%s", strings.Join(errs, "; "))
+	return multierr.Combine(errs...)
 }
 
 func isReadinessTimeoutErr(err error) bool {
@@ -940,25 +1226,55 @@ func elasticsearchBrokerOptions(req provider.CreateRequest) microvm.Elasticsearc
 	return microvm.ElasticsearchBrokerOptions{}
 }
 
-// runSSHCommand executes a command on a sandbox via SSH using cert-based auth.
-func runSSHCommand(ctx context.Context, ip string, creds *sshkeys.Credentials, command string, timeout time.Duration) (stdout, stderr string, exitCode int, err error) {
-	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
-	sshArgs := []string{
-		"-i", creds.PrivateKeyPath,
-		"-o", "CertificateFile=" + creds.CertificatePath,
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "UserKnownHostsFile=/dev/null",
+// sshCommandArgs builds the ssh argv for a sandbox command. hostKey holds
+// the host key options from sshkeys.HostKeyArgs. Agent forwarding is set
+// explicitly either way so a permissive ~/.ssh/config on the daemon host
+// cannot turn it on implicitly.
+func sshCommandArgs(ip string, creds *sshkeys.Credentials, hostKey []string, command string, opts provider.CommandOptions, extra ...string) []string {
+	args := []string{"-i", creds.PrivateKeyPath}
+	for _, id := range opts.IdentityFiles {
+		args = append(args, "-i", id)
+	}
+	forward := "no"
+	if opts.ForwardAgent {
+		forward = "yes"
+	}
+	args = append(args,
+		"-o", "CertificateFile="+creds.CertificatePath,
+	)
+	args = append(args, hostKey...)
+	args = append(args,
 		"-o", "ConnectTimeout=10",
+		"-o", "ForwardAgent="+forward,
+	)
+	args = append(args, extra...)
+	args = append(args,
 		fmt.Sprintf("%s@%s", creds.Username, ip),
 		command,
-	}
+	)
+	return args
+}
+
+// runSSHCommand executes a command on a sandbox via SSH using cert-based auth.
+// extra ssh options are placed before the destination.
+func runSSHCommand(ctx context.Context, ip string, creds *sshkeys.Credentials, hostKey []string, command string, timeout time.Duration, opts provider.CommandOptions, extra ...string) (stdout, stderr string, exitCode int, err error) {
+	return runSSHCommandStreaming(ctx, ip, creds, hostKey, command, timeout, opts, nil, extra...)
+}
+
+// runSSHCommandStreaming is runSSHCommand that also passes output to
+// onOutput as it arrives, when onOutput is non-nil.
+func runSSHCommandStreaming(ctx context.Context, ip string, creds *sshkeys.Credentials, hostKey []string, command string, timeout time.Duration, opts provider.CommandOptions, onOutput provider.OutputFunc, extra ...string) (stdout, stderr string, exitCode int, err error) {
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	cmd := exec.CommandContext(cmdCtx, "ssh", sshArgs...)
+	cmd := exec.CommandContext(cmdCtx, "ssh", sshCommandArgs(ip, creds, hostKey, command, opts, extra...)...)
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
 	cmd.Stderr = &stderrBuf
+	if onOutput != nil {
+		cmd.Stdout = io.MultiWriter(&stdoutBuf, outputWriter{onOutput, false})
+		cmd.Stderr = io.MultiWriter(&stderrBuf, outputWriter{onOutput, true})
+	}
 
 	err = cmd.Run()
 	if err != nil {
@@ -978,3 +1294,14 @@ func runSSHCommand(ctx context.Context, ip string, creds *sshkeys.Credentials, c
 
 	return stdoutBuf.String(), stderrBuf.String(), 0, nil
 }
+
+// outputWriter forwards writes to an OutputFunc.
+type outputWriter struct {
+	fn       provider.OutputFunc
+	isStderr bool
+}
+
+func (w outputWriter) Write(b []byte) (int, error) {
+	w.fn(bytes.Clone(b), w.isStderr)
+	return len(b), nil
+}
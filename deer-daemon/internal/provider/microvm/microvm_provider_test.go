@@ -15,6 +15,7 @@ import (
 	microvminternal "github.com/aspectrr/deer.sh/deer-daemon/internal/microvm"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/network"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/sshkeys"
 )
 
 type stubReadinessWaiter struct {
@@ -169,7 +170,7 @@ func TestCompleteCreate_UsesReadyIPFallbackAfterPhoneHome(t *testing.T) {
 	result, err := p.completeCreate(context.Background(), provider.CreateRequest{
 		SandboxID: "sbx-123",
 		Name:      "sandbox",
-	}, &microvminternal.SandboxInfo{PID: 4321}, "52:54:00:12:34:56", "br0", "tap0")
+	}, &microvminternal.SandboxInfo{PID: 4321}, "52:54:00:12:34:56", "br0", "tap0", true)
 	if err != nil {
 		t.Fatalf("completeCreate: %v", err)
 	}
@@ -248,3 +249,93 @@ func TestKernelOOMDiagnosticsForPID_IgnoresOtherProcesses(t *testing.T) {
 		t.Fatalf("expected no diagnostics for unmatched pid, got %q", got)
 	}
 }
+
+func TestSSHCommandArgs_AgentForwardingOffByDefault(t *testing.T) {
+	creds := &sshkeys.Credentials{PrivateKeyPath: "/keys/sbx", CertificatePath: "/keys/sbx-cert.pub", Username: "sandbox"}
+
+	args := strings.Join(sshCommandArgs("10.0.0.5", creds, nil, "uptime", provider.CommandOptions{}), " ")
+	if !strings.Contains(args, "ForwardAgent=no") {
+		t.Errorf("default args should disable agent forwarding: %s", args)
+	}
+
+	args = strings.Join(sshCommandArgs("10.0.0.5", creds, nil, "uptime", provider.CommandOptions{
+		ForwardAgent:  true,
+		IdentityFiles: []string{"/home/op/.ssh/id_deploy"},
+	}), " ")
+	if !strings.Contains(args, "ForwardAgent=yes") {
+		t.Errorf("expected ForwardAgent=yes: %s", args)
+	}
+	if !strings.Contains(args, "-i /keys/sbx -i /home/op/.ssh/id_deploy") {
+		t.Errorf("expected sandbox key followed by extra identity: %s", args)
+	}
+	if !strings.HasSuffix(args, "sandbox@10.0.0.5 uptime") {
+		t.Errorf("expected destination and command last: %s", args)
+	}
+}
+
+func TestSSHCommandArgs_ExtraOptionsBeforeDestination(t *testing.T) {
+	creds := &sshkeys.Credentials{PrivateKeyPath: "/keys/sbx", CertificatePath: "/keys/sbx-cert.pub", Username: "sandbox"}
+
+	args := strings.Join(sshCommandArgs("10.0.0.5", creds, nil, "uptime", provider.CommandOptions{}, "-o", "ControlPath=/tmp/cm"), " ")
+	if !strings.HasSuffix(args, "-o ControlPath=/tmp/cm sandbox@10.0.0.5 uptime") {
+		t.Errorf("expected control options right before the destination: %s", args)
+	}
+}
+
+func TestSSHCommandArgs_HostKeyModes(t *testing.T) {
+	creds := &sshkeys.Credentials{PrivateKeyPath: "/keys/SBX-1/key", CertificatePath: "/keys/SBX-1/key-cert.pub", Username: "sandbox"}
+
+	tests := []struct {
+		mode, knownHosts string
+		want             string
+	}{
+		{"", "", "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"},
+		{"insecure", "", "-o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null"},
+		{"tofu", "", "-o StrictHostKeyChecking=accept-new -o UserKnownHostsFile=/keys/SBX-1/known_hosts"},
+		{"strict", "/etc/deer/known_hosts", "-o StrictHostKeyChecking=yes -o UserKnownHostsFile=/etc/deer/known_hosts"},
+	}
+	for _, tt := range tests {
+		p := &Provider{}
+		if err := p.SetHostKeyMode(tt.mode, tt.knownHosts); err != nil {
+			t.Fatalf("SetHostKeyMode(%q): %v", tt.mode, err)
+		}
+		args := strings.Join(sshCommandArgs("10.0.0.5", creds, p.hostKeyArgs(creds), "uptime", provider.CommandOptions{}), " ")
+		if !strings.Contains(args, tt.want) {
+			t.Errorf("mode %q: args %q do not contain %q", tt.mode, args, tt.want)
+		}
+	}
+
+	p := &Provider{}
+	if err := p.SetHostKeyMode("strict", ""); err == nil {
+		t.Error("strict mode without a known_hosts file should be rejected")
+	}
+	if err := p.SetHostKeyMode("pinned", ""); err == nil {
+		t.Error("unknown mode should be rejected")
+	}
+}
+
+func TestCertExpired(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	live := &sshkeys.Credentials{ValidUntil: now.Add(time.Minute)}
+	lapsed := &sshkeys.Credentials{ValidUntil: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name  string
+		msg   string
+		creds *sshkeys.Credentials
+		want  bool
+	}{
+		{"server says expired", "error: Certificate invalid: expired", live, true},
+		{"client says expired", "certificate expired", live, true},
+		{"denied after expiry", "sandbox@10.0.0.4: Permission denied (publickey).", lapsed, true},
+		{"denied while valid", "sandbox@10.0.0.4: Permission denied (publickey).", live, false},
+		{"unrelated failure", "Connection refused", lapsed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certExpired(tt.msg, tt.creds, now); got != tt.want {
+				t.Errorf("certExpired(%q) = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
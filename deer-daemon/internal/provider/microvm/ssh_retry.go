@@ -0,0 +1,113 @@
+package microvm
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	sshRetryInitialDelay = 2 * time.Second
+	sshRetryMaxDelay     = 15 * time.Second
+)
+
+// sshRetryBackoff computes the wait between SSH attempts while sshd in a new
+// sandbox comes up: Initial * 2^attempt, capped at Max.
+type sshRetryBackoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	// Jitter applies full jitter, waiting a random duration in [0, delay], so
+	// sandboxes created together don't hammer sshd in lockstep. Tests turn it
+	// off for deterministic delays.
+	Jitter bool
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newSSHRetryBackoff() *sshRetryBackoff {
+	return &sshRetryBackoff{
+		Initial: sshRetryInitialDelay,
+		Max:     sshRetryMaxDelay,
+		Jitter:  true,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Delay returns how long to wait before retry number attempt (0-based).
+func (b *sshRetryBackoff) Delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	if !b.Jitter || d <= 0 {
+		return d
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return time.Duration(b.rng.Int63n(int64(d) + 1))
+}
+
+// Defaults for sshRetryBudget: a sandbox that needs more than this many SSH
+// retries within the window is treated as broken rather than still booting.
+const (
+	sshRetryBudgetLimit  = 15
+	sshRetryBudgetWindow = 5 * time.Minute
+)
+
+// sshRetryBudget caps SSH retries per sandbox across commands. Each command
+// retries independently, so without a shared budget a sandbox whose sshd
+// never comes up costs every command the full backoff schedule. Once a
+// sandbox has used Limit retries within Window, further commands fail after
+// their first attempt until a command succeeds or the sandbox is restarted.
+type sshRetryBudget struct {
+	Limit  int
+	Window time.Duration
+
+	mu      sync.Mutex
+	retries map[string][]time.Time
+}
+
+func newSSHRetryBudget() *sshRetryBudget {
+	return &sshRetryBudget{
+		Limit:   sshRetryBudgetLimit,
+		Window:  sshRetryBudgetWindow,
+		retries: make(map[string][]time.Time),
+	}
+}
+
+// take spends one retry for id at now, reporting false (and spending
+// nothing) when the budget for the current window is used up. A nil budget
+// never runs out.
+func (b *sshRetryBudget) take(id string, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	recent := b.retries[id][:0]
+	for _, t := range b.retries[id] {
+		if now.Sub(t) < b.Window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= b.Limit {
+		b.retries[id] = recent
+		return false
+	}
+	b.retries[id] = append(recent, now)
+	return true
+}
+
+// reset restores id's full budget.
+func (b *sshRetryBudget) reset(id string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.retries, id)
+}
@@ -0,0 +1,72 @@
+package microvm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSSHRetryBackoff_Deterministic(t *testing.T) {
+	b := newSSHRetryBackoff()
+	b.Jitter = false
+
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 15 * time.Second, 15 * time.Second}
+	for attempt, w := range want {
+		if got := b.Delay(attempt); got != w {
+			t.Errorf("Delay(%d) = %s, want %s", attempt, got, w)
+		}
+	}
+}
+
+func TestSSHRetryBackoff_JitterWithinBounds(t *testing.T) {
+	b := newSSHRetryBackoff()
+
+	distinct := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		d := b.Delay(2)
+		if d < 0 || d > 8*time.Second {
+			t.Fatalf("Delay(2) = %s, want within [0, 8s]", d)
+		}
+		distinct[d] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("expected jittered delays to vary")
+	}
+}
+
+func TestSSHRetryBudget(t *testing.T) {
+	b := newSSHRetryBudget()
+	b.Limit, b.Window = 3, time.Minute
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if !b.take("SBX-1", now.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("retry %d refused within budget", i+1)
+		}
+	}
+	if b.take("SBX-1", now.Add(5*time.Second)) {
+		t.Error("expected the fourth retry within the window to be refused")
+	}
+	if !b.take("SBX-2", now.Add(5*time.Second)) {
+		t.Error("budgets should be per sandbox")
+	}
+
+	// Retries older than the window no longer count.
+	if !b.take("SBX-1", now.Add(time.Minute+time.Second)) {
+		t.Error("expected budget to recover once old retries leave the window")
+	}
+
+	b.reset("SBX-1")
+	for i := 0; i < 3; i++ {
+		if !b.take("SBX-1", now.Add(2*time.Minute)) {
+			t.Fatalf("retry %d refused after reset", i+1)
+		}
+	}
+}
+
+func TestSSHRetryBudget_Nil(t *testing.T) {
+	var b *sshRetryBudget
+	if !b.take("SBX-1", time.Now()) {
+		t.Error("a nil budget should never run out")
+	}
+	b.reset("SBX-1")
+}
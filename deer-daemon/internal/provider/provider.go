@@ -4,9 +4,24 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 )
 
+// ErrSandboxNotFound is returned (wrapped) by providers when the sandbox does
+// not exist on the backend, e.g. because it was destroyed out-of-band.
+var ErrSandboxNotFound = errors.New("sandbox not found")
+
+// ErrSnapshotNotFound is returned (wrapped) by SnapshotDeleter when the
+// backend has no snapshot by that name.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// ErrSandboxUnhealthy is returned (wrapped) by RunCommand when a sandbox has
+// used up its SSH retry budget, so callers fail fast instead of retrying
+// every command against a sandbox that is not coming back.
+var ErrSandboxUnhealthy = errors.New("sandbox appears unhealthy")
+
 type DataSourceType string
 
 const (
@@ -15,6 +30,10 @@ const (
 	DefaultSandboxVCPUs = 2
 	DefaultSandboxMemMB = 2048
 
+	// MinSandboxMemMB is the least memory a sandbox can be resized to; below
+	// it most guest images fail to boot.
+	MinSandboxMemMB = 256
+
 	KafkaBrokerMinVCPUs            = 2
 	KafkaBrokerMinMemoryMB         = 2048
 	ElasticsearchBrokerMinVCPUs    = 2
@@ -68,6 +87,228 @@ type SandboxProvider interface {
 	RecoverState(ctx context.Context) error
 }
 
+// CommandOptions adjusts how a single command connects to a sandbox.
+// The zero value matches plain RunCommand: no agent forwarding and only the
+// sandbox's own credentials.
+type CommandOptions struct {
+	// ForwardAgent forwards the daemon's SSH agent (SSH_AUTH_SOCK) into the
+	// sandbox for the duration of the command.
+	ForwardAgent bool
+	// IdentityFiles are extra private keys offered after the sandbox key.
+	IdentityFiles []string
+}
+
+// IsZero reports whether no options are set.
+func (o CommandOptions) IsZero() bool {
+	return !o.ForwardAgent && len(o.IdentityFiles) == 0
+}
+
+// OptionsCommandRunner is implemented by providers whose command runner can
+// honor CommandOptions (e.g. SSH-based providers).
+type OptionsCommandRunner interface {
+	RunCommandWithOptions(ctx context.Context, sandboxID, command string, timeout time.Duration, opts CommandOptions) (*CommandResult, error)
+}
+
+// ErrCommandOptionsUnsupported is returned by RunCommandWithOptions when
+// options are requested from a provider that cannot apply them.
+var ErrCommandOptionsUnsupported = errors.New("provider does not support SSH command options")
+
+// RunCommandWithOptions runs command on p, applying opts when set. Providers
+// that cannot honor non-zero options return ErrCommandOptionsUnsupported
+// rather than silently ignoring them.
+func RunCommandWithOptions(ctx context.Context, p SandboxProvider, sandboxID, command string, timeout time.Duration, opts CommandOptions) (*CommandResult, error) {
+	if opts.IsZero() {
+		return p.RunCommand(ctx, sandboxID, command, timeout)
+	}
+	r, ok := p.(OptionsCommandRunner)
+	if !ok {
+		return nil, ErrCommandOptionsUnsupported
+	}
+	return r.RunCommandWithOptions(ctx, sandboxID, command, timeout, opts)
+}
+
+// OutputFunc receives a chunk of command output as it is produced. It may be
+// called from more than one goroutine at once.
+type OutputFunc func(chunk []byte, isStderr bool)
+
+// StreamingCommandRunner is implemented by providers that can deliver
+// command output while the command is still running.
+type StreamingCommandRunner interface {
+	RunCommandStreaming(ctx context.Context, sandboxID, command string, timeout time.Duration, opts CommandOptions, onOutput OutputFunc) (*CommandResult, error)
+}
+
+// RunCommandStreaming runs command on p and passes its output to onOutput as
+// it arrives. Providers without StreamingCommandRunner run the command to
+// completion and pass stdout and stderr to onOutput once at the end.
+func RunCommandStreaming(ctx context.Context, p SandboxProvider, sandboxID, command string, timeout time.Duration, opts CommandOptions, onOutput OutputFunc) (*CommandResult, error) {
+	if r, ok := p.(StreamingCommandRunner); ok {
+		return r.RunCommandStreaming(ctx, sandboxID, command, timeout, opts, onOutput)
+	}
+	result, err := RunCommandWithOptions(ctx, p, sandboxID, command, timeout, opts)
+	if err != nil {
+		return nil, err
+	}
+	if result.Stdout != "" {
+		onOutput([]byte(result.Stdout), false)
+	}
+	if result.Stderr != "" {
+		onOutput([]byte(result.Stderr), true)
+	}
+	return result, nil
+}
+
+// ProbeRunner is implemented by providers that limit SSH retries per
+// sandbox. RunProbe runs a command the daemon issues on its own behalf
+// against a separate budget from user commands.
+type ProbeRunner interface {
+	RunProbe(ctx context.Context, sandboxID, command string, timeout time.Duration) (*CommandResult, error)
+}
+
+// RunProbe runs a daemon-issued command such as a disk usage check on p,
+// through ProbeRunner when p implements it and RunCommand otherwise.
+func RunProbe(ctx context.Context, p SandboxProvider, sandboxID, command string, timeout time.Duration) (*CommandResult, error) {
+	if r, ok := p.(ProbeRunner); ok {
+		return r.RunProbe(ctx, sandboxID, command, timeout)
+	}
+	return p.RunCommand(ctx, sandboxID, command, timeout)
+}
+
+// BatchCommandRunner is implemented by providers that can run several
+// commands over one connection.
+type BatchCommandRunner interface {
+	RunCommandBatch(ctx context.Context, sandboxID string, commands []string, timeout time.Duration, stopOnError bool) ([]*CommandResult, error)
+}
+
+// RunCommandBatch runs commands on p in order and returns a result for each
+// one that ran. With stopOnError it stops after the first non-zero exit. On
+// a transport error it returns the results so far along with the error.
+// Providers without BatchCommandRunner run each command separately.
+func RunCommandBatch(ctx context.Context, p SandboxProvider, sandboxID string, commands []string, timeout time.Duration, stopOnError bool) ([]*CommandResult, error) {
+	if r, ok := p.(BatchCommandRunner); ok {
+		return r.RunCommandBatch(ctx, sandboxID, commands, timeout, stopOnError)
+	}
+	results := make([]*CommandResult, 0, len(commands))
+	for _, command := range commands {
+		result, err := p.RunCommand(ctx, sandboxID, command, timeout)
+		if err != nil {
+			return results, fmt.Errorf("run command %q: %w", command, err)
+		}
+		results = append(results, result)
+		if stopOnError && result.ExitCode != 0 {
+			break
+		}
+	}
+	return results, nil
+}
+
+// Importer is implemented by providers that can adopt an existing VM or CT,
+// created outside deer, as a managed sandbox without cloning it.
+type Importer interface {
+	// ImportSandbox starts tracking vmName under sandboxID and reports its
+	// current state.
+	ImportSandbox(ctx context.Context, sandboxID, vmName string) (*SandboxResult, error)
+}
+
+// Archiver is implemented by providers that can export a sandbox's disk to a
+// standalone image that outlives the sandbox.
+type Archiver interface {
+	// ArchiveSandbox writes the sandbox's disk into destDir and returns where
+	// it went. The sandbox should be stopped first for a consistent image.
+	ArchiveSandbox(ctx context.Context, sandboxID, destDir string) (*ArchiveResult, error)
+}
+
+// ArchiveResult describes a sandbox disk written by ArchiveSandbox.
+type ArchiveResult struct {
+	Path      string
+	SizeBytes int64
+}
+
+// OrphanReclaimer is implemented by providers whose host resources, such as
+// Proxmox VMIDs, can outlive a create or destroy that failed part-way.
+type OrphanReclaimer interface {
+	// ListOrphans returns the provider's resources that belong to none of
+	// the live sandboxes, along with the state of its allocation pool.
+	ListOrphans(ctx context.Context, live []LiveSandbox) (*OrphanReport, error)
+	// ReclaimOrphan deletes a resource reported by ListOrphans.
+	ReclaimOrphan(ctx context.Context, orphanID string) error
+}
+
+// LiveSandbox identifies a sandbox the daemon still tracks.
+type LiveSandbox struct {
+	ID   string
+	Name string
+}
+
+// Orphan is a provider resource with no sandbox behind it.
+type Orphan struct {
+	ID     string // provider identifier, e.g. a VMID
+	Name   string
+	State  string
+	Reason string
+}
+
+// OrphanReport is the result of ListOrphans. PoolSize and PoolFree describe
+// the provider's allocation pool (e.g. a VMID range) and are zero when it
+// has none.
+type OrphanReport struct {
+	Orphans  []Orphan
+	PoolSize int
+	PoolFree int
+}
+
+// SnapshotFiles is implemented by providers that can reach a single file
+// inside a sandbox snapshot without reverting the sandbox.
+type SnapshotFiles interface {
+	// ReadSnapshotFile returns the contents of path as it was in the
+	// snapshot. path must be absolute and name a regular file.
+	ReadSnapshotFile(ctx context.Context, sandboxID, snapshotName, path string) ([]byte, error)
+	// RestoreSnapshotFile copies path from the snapshot back into the
+	// running sandbox, keeping its mode and ownership.
+	RestoreSnapshotFile(ctx context.Context, sandboxID, snapshotName, path string) error
+}
+
+// SnapshotDeleter is implemented by providers whose snapshots live on the
+// backend and must be removed there, not just forgotten by the daemon.
+type SnapshotDeleter interface {
+	DeleteSnapshot(ctx context.Context, sandboxID, name string) error
+}
+
+// Resizer is implemented by providers that can change a sandbox's vCPU and
+// memory allocation. The sandbox must be stopped; the new shape takes
+// effect on its next start.
+type Resizer interface {
+	SetSandboxResources(ctx context.Context, sandboxID string, vcpus, memoryMB int) error
+}
+
+// StatsReader is implemented by providers that can report what a sandbox is
+// actually using, as opposed to the shape it was created with.
+type StatsReader interface {
+	SandboxStats(ctx context.Context, sandboxID string) (*SandboxStats, error)
+}
+
+// SandboxStats is a sandbox's resource usage when it was sampled. Fields the
+// backend cannot measure are zero; CPU and memory are zero while the
+// sandbox is stopped.
+type SandboxStats struct {
+	CPUTime            time.Duration // cumulative, across all vCPUs
+	CPUPercent         float64       // of one vCPU, only when the backend samples it itself
+	MemoryBytes        int64
+	MemoryLimitBytes   int64
+	DiskAllocatedBytes int64
+	DiskCapacityBytes  int64
+}
+
+// SnapshotCloner is implemented by providers that can create a sandbox from
+// another sandbox's snapshot. req describes the new sandbox; its BaseImage
+// and SourceVM are ignored.
+type SnapshotCloner interface {
+	CloneFromSnapshot(ctx context.Context, parentID, snapshotName string, req CreateRequest) (*SandboxResult, error)
+}
+
+// MaxSnapshotFileSize is the largest file ReadSnapshotFile returns; it keeps
+// the reply under gRPC's default message limit.
+const MaxSnapshotFileSize = 3 << 20
+
 // CreateRequest holds parameters for creating a sandbox.
 type CreateRequest struct {
 	SandboxID           string
@@ -210,7 +451,37 @@ type SourceVMInfo struct {
 	Prepared  bool
 }
 
+// Validation issue codes. Codes are stable identifiers that clients can branch
+// on; the accompanying detail is free-form and may change.
+const (
+	IssueVMNotFound          = "VM_NOT_FOUND"
+	IssueVMNotRunning        = "VM_NOT_RUNNING"
+	IssueStatusUnavailable   = "STATUS_UNAVAILABLE"
+	IssueConfigUnreadable    = "CONFIG_UNREADABLE"
+	IssueNoNetwork           = "NO_NETWORK_INTERFACE"
+	IssueNoMACAddress        = "NO_MAC_ADDRESS"
+	IssueNoIPAddress         = "NO_IP_ADDRESS"
+	IssueReadonlySSHFailed   = "READONLY_SSH_FAILED"
+	IssueCredentialsNotFound = "CREDENTIALS_UNAVAILABLE"
+	IssueInsufficientMemory  = "INSUFFICIENT_MEMORY"
+)
+
+// Validation issue severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue is a machine-readable validation finding.
+type ValidationIssue struct {
+	Code     string `json:"code"`
+	Severity string `json:"severity"`
+	Detail   string `json:"detail"`
+}
+
 // ValidationResult contains the result of validating a source VM.
+// Warnings and Errors hold the human-readable messages; Issues carries the
+// same findings with codes.
 type ValidationResult struct {
 	VMName     string
 	Valid      bool
@@ -220,6 +491,28 @@ type ValidationResult struct {
 	HasNetwork bool
 	Warnings   []string
 	Errors     []string
+	Issues     []ValidationIssue
+}
+
+// AddError records an error both as a message and as a coded issue.
+func (r *ValidationResult) AddError(code, detail string) {
+	r.Errors = append(r.Errors, detail)
+	r.Issues = append(r.Issues, ValidationIssue{Code: code, Severity: SeverityError, Detail: detail})
+}
+
+// AddWarning records a warning both as a message and as a coded issue.
+func (r *ValidationResult) AddWarning(code, detail string) {
+	r.Warnings = append(r.Warnings, detail)
+	r.Issues = append(r.Issues, ValidationIssue{Code: code, Severity: SeverityWarning, Detail: detail})
+}
+
+// CheckHostMemory warns when caps shows less free memory than a sandbox of
+// memoryMB needs. A host that did not report its free memory is not flagged.
+func (r *ValidationResult) CheckHostMemory(caps *HostCapabilities, memoryMB int) {
+	if caps == nil || caps.AvailableMemMB <= 0 || caps.AvailableMemMB >= memoryMB {
+		return
+	}
+	r.AddWarning(IssueInsufficientMemory, fmt.Sprintf("host has %d MB of memory free, less than the %d MB a sandbox needs", caps.AvailableMemMB, memoryMB))
 }
 
 // HostCapabilities describes the resources and images available on this host.
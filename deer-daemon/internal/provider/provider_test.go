@@ -38,3 +38,45 @@ func TestNormalizeCreateRequestResources_LeavesNonKafkaRequestsAlone(t *testing.
 		t.Fatalf("MemoryMB = %d, want 512", req.MemoryMB)
 	}
 }
+
+func TestValidationResult_AddIssueKeepsStringsInSync(t *testing.T) {
+	var r ValidationResult
+	r.AddError(IssueVMNotRunning, "VM is not running")
+	r.AddWarning(IssueNoIPAddress, "Could not determine IP address")
+
+	if len(r.Errors) != 1 || r.Errors[0] != "VM is not running" {
+		t.Fatalf("Errors = %v", r.Errors)
+	}
+	if len(r.Warnings) != 1 || r.Warnings[0] != "Could not determine IP address" {
+		t.Fatalf("Warnings = %v", r.Warnings)
+	}
+	want := []ValidationIssue{
+		{Code: IssueVMNotRunning, Severity: SeverityError, Detail: "VM is not running"},
+		{Code: IssueNoIPAddress, Severity: SeverityWarning, Detail: "Could not determine IP address"},
+	}
+	if len(r.Issues) != len(want) {
+		t.Fatalf("Issues = %+v, want %+v", r.Issues, want)
+	}
+	for i := range want {
+		if r.Issues[i] != want[i] {
+			t.Errorf("Issues[%d] = %+v, want %+v", i, r.Issues[i], want[i])
+		}
+	}
+}
+
+func TestValidationResult_CheckHostMemory(t *testing.T) {
+	var r ValidationResult
+	r.CheckHostMemory(&HostCapabilities{AvailableMemMB: 4096}, 2048)
+	r.CheckHostMemory(&HostCapabilities{}, 2048) // free memory not reported
+	if len(r.Issues) != 0 {
+		t.Fatalf("Issues = %+v, want none", r.Issues)
+	}
+
+	r.CheckHostMemory(&HostCapabilities{AvailableMemMB: 1024}, 2048)
+	if len(r.Issues) != 1 || r.Issues[0].Code != IssueInsufficientMemory || r.Issues[0].Severity != SeverityWarning {
+		t.Fatalf("Issues = %+v, want one INSUFFICIENT_MEMORY warning", r.Issues)
+	}
+	if len(r.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want the same finding as a message", r.Warnings)
+	}
+}
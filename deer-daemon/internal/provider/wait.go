@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// waitInitialInterval is the first delay between polls; it doubles up to
+	// waitMaxInterval.
+	waitInitialInterval = 250 * time.Millisecond
+	waitMaxInterval     = 5 * time.Second
+)
+
+// TimeoutError is returned by WaitFor and WaitForState when the condition is
+// not met in time.
+type TimeoutError struct {
+	What    string        // what was being waited for
+	Timeout time.Duration // how long we waited
+	Last    string        // last observed state, if known
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Last != "" {
+		return fmt.Sprintf("timeout waiting for %s after %s (last state %q)", e.What, e.Timeout, e.Last)
+	}
+	return fmt.Sprintf("timeout waiting for %s after %s", e.What, e.Timeout)
+}
+
+// WaitFor calls check until it reports done, returns an error, ctx is done,
+// or timeout elapses. A zero timeout waits on ctx alone. The first check runs
+// immediately; later ones back off from 250ms to 5s.
+func WaitFor(ctx context.Context, what string, timeout time.Duration, check func(ctx context.Context) (done bool, err error)) error {
+	return waitFor(ctx, what, timeout, func(ctx context.Context) (bool, string, error) {
+		done, err := check(ctx)
+		return done, "", err
+	})
+}
+
+// WaitForState polls state until it reports target (compared case-insensitively).
+// Errors from state are treated as transient and polling continues; the
+// TimeoutError carries the last state seen.
+func WaitForState(ctx context.Context, what string, state func(ctx context.Context) (string, error), target string, timeout time.Duration) error {
+	return waitFor(ctx, what, timeout, func(ctx context.Context) (bool, string, error) {
+		s, err := state(ctx)
+		if err != nil {
+			return false, "", nil
+		}
+		return strings.EqualFold(s, target), s, nil
+	})
+}
+
+func waitFor(ctx context.Context, what string, timeout time.Duration, check func(ctx context.Context) (bool, string, error)) error {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	interval := waitInitialInterval
+	var last string
+
+	for {
+		done, observed, err := check(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if observed != "" {
+			last = observed
+		}
+
+		wait := interval
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return &TimeoutError{What: what, Timeout: timeout, Last: last}
+			}
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > waitMaxInterval {
+			interval = waitMaxInterval
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitFor_ImmediateSuccess(t *testing.T) {
+	calls := 0
+	err := WaitFor(context.Background(), "ready", time.Second, func(context.Context) (bool, error) {
+		calls++
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("WaitFor: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestWaitFor_SucceedsAfterPolls(t *testing.T) {
+	calls := 0
+	err := WaitFor(context.Background(), "ready", 5*time.Second, func(context.Context) (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil {
+		t.Fatalf("WaitFor: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestWaitFor_CheckErrorStops(t *testing.T) {
+	boom := errors.New("boom")
+	err := WaitFor(context.Background(), "ready", time.Second, func(context.Context) (bool, error) {
+		return false, boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+}
+
+func TestWaitFor_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := WaitFor(ctx, "ready", 0, func(context.Context) (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestWaitForState_Timeout(t *testing.T) {
+	err := WaitForState(context.Background(), "CT 100 to run", func(context.Context) (string, error) {
+		return "stopped", nil
+	}, "running", 300*time.Millisecond)
+
+	var te *TimeoutError
+	if !errors.As(err, &te) {
+		t.Fatalf("err = %v, want *TimeoutError", err)
+	}
+	if te.Last != "stopped" {
+		t.Errorf("Last = %q, want stopped", te.Last)
+	}
+	if te.What != "CT 100 to run" {
+		t.Errorf("What = %q", te.What)
+	}
+}
+
+func TestWaitForState_TransientErrors(t *testing.T) {
+	calls := 0
+	err := WaitForState(context.Background(), "vm", func(context.Context) (string, error) {
+		calls++
+		if calls < 2 {
+			return "", errors.New("not yet")
+		}
+		return "RUNNING", nil
+	}, "running", 5*time.Second)
+	if err != nil {
+		t.Fatalf("WaitForState: %v", err)
+	}
+}
@@ -74,7 +74,7 @@ func Prepare(ctx context.Context, sshRun SSHRunFunc, caPubKey string, onProgress
 		}
 	}
 
-	// Wrap sshRun to elevate all commands with sudo via base64 transport.
+	// Wrap sshRun to run all commands as root via base64 transport.
 	//
 	// Security context: Prepare runs during one-time source VM setup by a
 	// trusted operator (not by AI agents). The SSH session is authenticated
@@ -86,18 +86,17 @@ func Prepare(ctx context.Context, sshRun SSHRunFunc, caPubKey string, onProgress
 	// fragile and error-prone. Base64 encoding the command on the Go side
 	// and decoding on the VM side avoids all shell interpolation issues.
 	//
-	// The pattern is: echo <base64> | base64 -d | sudo bash
+	// The pattern is: echo <base64> | base64 -d | <rootShell>
 	//   - echo: emits the opaque base64 blob (no special chars to escape)
 	//   - base64 -d: decodes to the original command string
-	//   - sudo bash: executes with root privileges
+	//   - rootShell: bash as root, through sudo unless already root
 	//
 	// This wrapper is NOT used at runtime for agent commands. Agent commands
 	// go through RunWithCert which connects as the deer-readonly user
 	// directly - no sudo, no base64, no privilege escalation.
 	origRun := sshRun
 	sshRun = func(ctx context.Context, command string) (string, string, int, error) {
-		encoded := base64.StdEncoding.EncodeToString([]byte(command))
-		return origRun(ctx, fmt.Sprintf("echo %s | base64 -d | sudo bash", encoded))
+		return origRun(ctx, asRoot(command))
 	}
 
 	// 1. Install restricted shell script at /usr/local/bin/deer-readonly-shell
@@ -196,3 +195,15 @@ func Prepare(ctx context.Context, sshRun SSHRunFunc, caPubKey string, onProgress
 
 	return result, nil
 }
+
+// rootShell runs the script on its stdin as root: directly when the SSH user
+// is root, and through sudo otherwise, so preparation works both on images
+// with a root login and on cloud images that only have an admin user such as
+// ubuntu or ec2-user (which needs passwordless sudo).
+const rootShell = `if [ "$(id -u)" -eq 0 ]; then bash; else sudo bash; fi`
+
+// asRoot wraps command for the base64 transport described in Prepare.
+func asRoot(command string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(command))
+	return fmt.Sprintf("echo %s | base64 -d | %s", encoded, rootShell)
+}
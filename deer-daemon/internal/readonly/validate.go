@@ -11,3 +11,7 @@ import (
 func ValidateCommand(command string) error {
 	return readonly.ValidateCommand(command)
 }
+
+// Policy adjusts the default allowlist with operator-configured allow and
+// deny lists. Deny always wins; a nil Policy keeps the defaults.
+type Policy = readonly.Policy
@@ -0,0 +1,120 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces secrets scrubbed from command output. Unlike Redactor
+// tokens it cannot be restored: the secret is never stored.
+const Redacted = "[REDACTED]"
+
+// DefaultSecretEnvVars are environment variable names whose values are
+// scrubbed from command output, e.g. when a command runs env or set.
+var DefaultSecretEnvVars = []string{
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"GITHUB_TOKEN",
+	"GH_TOKEN",
+	"GITLAB_TOKEN",
+	"NPM_TOKEN",
+	"DOCKER_PASSWORD",
+	"PGPASSWORD",
+	"MYSQL_PWD",
+	"VAULT_TOKEN",
+}
+
+// OutputRule is a named pattern scrubbed from command output. When the
+// pattern has a capture group only the first group is replaced, so context
+// such as "Authorization: Bearer" stays readable.
+type OutputRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// defaultOutputRules are the secrets scrubbed from every command's output.
+func defaultOutputRules() []OutputRule {
+	return []OutputRule{
+		{Name: "private_key", Pattern: sshKeyRe},
+		{Name: "aws_access_key", Pattern: awsKeyRe},
+		{Name: "aws_secret_key", Pattern: regexp.MustCompile(`(?i)aws_secret_access_key\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`)},
+		{Name: "bearer_token", Pattern: regexp.MustCompile(`\bBearer\s+([A-Za-z0-9\-._~+/]+=*)`)},
+		{Name: "api_key", Pattern: regexp.MustCompile(`\b((?:sk|key)-[a-zA-Z0-9]{20,})\b`)},
+		{Name: "connection_string_password", Pattern: regexp.MustCompile(`\b(?:postgres|postgresql|mysql|mongodb|redis|amqp)://[^\s:/@]+:([^\s@]+)@`)},
+	}
+}
+
+// envVarRule matches NAME=value for the given variable names, capturing the
+// value.
+func envVarRule(names []string) (OutputRule, bool) {
+	if len(names) == 0 {
+		return OutputRule{}, false
+	}
+	quoted := make([]string, 0, len(names))
+	for _, n := range names {
+		quoted = append(quoted, regexp.QuoteMeta(n))
+	}
+	re := regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)=["']?([^\s"']+)`)
+	return OutputRule{Name: "env_var", Pattern: re}, true
+}
+
+// OutputScrubber removes secrets from command output before it is stored,
+// audited, or returned to a client.
+type OutputScrubber struct {
+	rules []OutputRule
+}
+
+// NewOutputScrubber builds a scrubber from the default rules, the given
+// extra patterns (name to regex), and the values of envVars in addition to
+// DefaultSecretEnvVars. An invalid pattern is an error.
+func NewOutputScrubber(patterns map[string]string, envVars []string) (*OutputScrubber, error) {
+	rules := defaultOutputRules()
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("output redaction pattern %q: %w", name, err)
+		}
+		rules = append(rules, OutputRule{Name: name, Pattern: re})
+	}
+	if r, ok := envVarRule(append(append([]string(nil), DefaultSecretEnvVars...), envVars...)); ok {
+		rules = append(rules, r)
+	}
+	return &OutputScrubber{rules: rules}, nil
+}
+
+// Scrub replaces every secret in text with Redacted and returns the result
+// along with the names of the rules that matched, in rule order.
+func (s *OutputScrubber) Scrub(text string) (string, []string) {
+	if s == nil || text == "" {
+		return text, nil
+	}
+	var fired []string
+	for _, r := range s.rules {
+		locs := r.Pattern.FindAllStringSubmatchIndex(text, -1)
+		if len(locs) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, loc := range locs {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			b.WriteString(text[last:start])
+			b.WriteString(Redacted)
+			last = end
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+		fired = append(fired, r.Name)
+	}
+	return text, fired
+}
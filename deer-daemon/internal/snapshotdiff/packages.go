@@ -0,0 +1,128 @@
+package snapshotdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Package is an installed package. Version includes the release, so an
+// upgrade shows up as the old version removed and the new one added.
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// PackageList is the package set recorded with a snapshot. Manager is "dpkg"
+// or "rpm", or empty when the guest has neither and packages were not listed.
+type PackageList struct {
+	Manager  string    `json:"manager,omitempty"`
+	Packages []Package `json:"packages,omitempty"`
+}
+
+// packagesScript picks dpkg or rpm from /etc/os-release, falling back to
+// whichever tool is installed, and prints "M\t<manager>" followed by one
+// "P\t<name>\t<version>" line per installed package.
+const packagesScript = `ids=; if [ -r /etc/os-release ]; then ids=$(. /etc/os-release; echo " $ID $ID_LIKE "); fi
+case "$ids" in
+  *" debian "*|*" ubuntu "*) m=dpkg ;;
+  *" rhel "*|*" fedora "*|*" centos "*|*" suse "*|*" opensuse "*|*" amzn "*) m=rpm ;;
+  *) m= ;;
+esac
+if [ -z "$m" ]; then
+  if command -v dpkg-query >/dev/null 2>&1; then m=dpkg; elif command -v rpm >/dev/null 2>&1; then m=rpm; fi
+fi
+case "$m" in
+  dpkg) printf 'M\tdpkg\n'; dpkg-query -W -f='${db:Status-Abbrev}|${binary:Package}|${Version}\n' 2>/dev/null | awk -F'|' '$1 ~ /^ii/ { printf "P\t%s\t%s\n", $2, $3 }' ;;
+  rpm) printf 'M\trpm\n'; rpm -qa --qf 'P\t%{NAME}\t%{VERSION}-%{RELEASE}\n' 2>/dev/null ;;
+esac
+true`
+
+// PackagesCommand returns the shell command that lists installed packages
+// for ParsePackages.
+func PackagesCommand() string {
+	return packagesScript
+}
+
+// ParsePackages builds a package list from the output of PackagesCommand.
+// Packages are sorted by name, then version.
+func ParsePackages(out string) PackageList {
+	var l PackageList
+	for _, line := range strings.Split(out, "\n") {
+		if m, ok := strings.CutPrefix(line, "M\t"); ok {
+			l.Manager = strings.TrimSpace(m)
+			continue
+		}
+		rest, ok := strings.CutPrefix(line, "P\t")
+		if !ok {
+			continue
+		}
+		name, version, _ := strings.Cut(rest, "\t")
+		if name == "" {
+			continue
+		}
+		l.Packages = append(l.Packages, Package{Name: name, Version: strings.TrimSpace(version)})
+	}
+	sortPackages(l.Packages)
+	return l
+}
+
+// Encode serializes the package list for storage.
+func (l PackageList) Encode() (string, error) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return "", fmt.Errorf("encode package list: %w", err)
+	}
+	return string(data), nil
+}
+
+// DecodePackages parses a package list produced by Encode. An empty string
+// decodes to an empty list with no manager.
+func DecodePackages(s string) (PackageList, error) {
+	var l PackageList
+	if s == "" {
+		return l, nil
+	}
+	if err := json.Unmarshal([]byte(s), &l); err != nil {
+		return l, fmt.Errorf("decode package list: %w", err)
+	}
+	return l, nil
+}
+
+// DiffPackages returns packages in to but not from (added) and in from but not
+// to (removed), matching on name and version. It returns nothing when either
+// side was not listed, since an unknown set cannot be compared.
+func DiffPackages(from, to PackageList) (added, removed []Package) {
+	if from.Manager == "" || to.Manager == "" {
+		return nil, nil
+	}
+	have := make(map[Package]bool, len(from.Packages))
+	for _, p := range from.Packages {
+		have[p] = true
+	}
+	want := make(map[Package]bool, len(to.Packages))
+	for _, p := range to.Packages {
+		want[p] = true
+		if !have[p] {
+			added = append(added, p)
+		}
+	}
+	for _, p := range from.Packages {
+		if !want[p] {
+			removed = append(removed, p)
+		}
+	}
+	sortPackages(added)
+	sortPackages(removed)
+	return added, removed
+}
+
+func sortPackages(pkgs []Package) {
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].Name != pkgs[j].Name {
+			return pkgs[i].Name < pkgs[j].Name
+		}
+		return pkgs[i].Version < pkgs[j].Version
+	})
+}
@@ -0,0 +1,68 @@
+package snapshotdiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePackages(t *testing.T) {
+	out := "M\tdpkg\n" +
+		"P\tnginx\t1.24.0-2ubuntu7\n" +
+		"P\tcurl\t8.5.0-2ubuntu10\n" +
+		"noise\n"
+	got := ParsePackages(out)
+	want := PackageList{Manager: "dpkg", Packages: []Package{
+		{Name: "curl", Version: "8.5.0-2ubuntu10"},
+		{Name: "nginx", Version: "1.24.0-2ubuntu7"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePackages = %+v, want %+v", got, want)
+	}
+	if got := ParsePackages(""); got.Manager != "" || len(got.Packages) != 0 {
+		t.Errorf("ParsePackages(\"\") = %+v, want empty", got)
+	}
+}
+
+func TestDiffPackages(t *testing.T) {
+	from := PackageList{Manager: "rpm", Packages: []Package{
+		{Name: "bash", Version: "5.1.8-6.el9"},
+		{Name: "openssl", Version: "3.0.7-24.el9"},
+		{Name: "telnet", Version: "0.17-85.el9"},
+	}}
+	to := PackageList{Manager: "rpm", Packages: []Package{
+		{Name: "bash", Version: "5.1.8-6.el9"},
+		{Name: "httpd", Version: "2.4.57-5.el9"},
+		{Name: "openssl", Version: "3.0.7-27.el9"},
+	}}
+	added, removed := DiffPackages(from, to)
+	wantAdded := []Package{{Name: "httpd", Version: "2.4.57-5.el9"}, {Name: "openssl", Version: "3.0.7-27.el9"}}
+	wantRemoved := []Package{{Name: "openssl", Version: "3.0.7-24.el9"}, {Name: "telnet", Version: "0.17-85.el9"}}
+	if !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("added = %+v, want %+v", added, wantAdded)
+	}
+	if !reflect.DeepEqual(removed, wantRemoved) {
+		t.Errorf("removed = %+v, want %+v", removed, wantRemoved)
+	}
+
+	if a, r := DiffPackages(PackageList{}, to); a != nil || r != nil {
+		t.Errorf("DiffPackages with an unlisted side = %v, %v, want nothing", a, r)
+	}
+}
+
+func TestPackageListEncodeRoundTrip(t *testing.T) {
+	l := PackageList{Manager: "dpkg", Packages: []Package{{Name: "vim", Version: "2:9.1.0016-1ubuntu7"}}}
+	s, err := l.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodePackages(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("round trip = %+v, want %+v", got, l)
+	}
+	if got, err := DecodePackages(""); err != nil || got.Manager != "" {
+		t.Errorf("DecodePackages(\"\") = %+v, %v", got, err)
+	}
+}
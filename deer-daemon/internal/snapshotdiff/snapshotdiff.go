@@ -0,0 +1,170 @@
+// Package snapshotdiff records a sandbox's filesystem as a manifest of
+// regular files, and its installed packages, and compares two recordings to
+// find added, modified, and removed files and packages.
+//
+// Sandbox snapshots do not keep disk images the daemon can mount, so the
+// manifest is captured over SSH when the snapshot is taken. Files up to
+// HashSizeLimit are hashed; larger files are compared by size and mtime.
+package snapshotdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/shellutil"
+)
+
+// DefaultIgnore lists volatile paths left out of manifests unless the daemon
+// config supplies its own list.
+var DefaultIgnore = []string{
+	"/proc",
+	"/sys",
+	"/dev",
+	"/run",
+	"/tmp",
+	"/var/tmp",
+	"/var/log",
+	"/var/cache",
+}
+
+// HashSizeLimit is the largest file, in bytes, whose contents are hashed.
+const HashSizeLimit = 1 << 20
+
+// Entry describes one regular file.
+type Entry struct {
+	Size  int64  `json:"s"`
+	MTime string `json:"m"`           // seconds since the epoch, as printed by find
+	Hash  string `json:"h,omitempty"` // sha256, empty for files over HashSizeLimit
+}
+
+// Manifest maps absolute paths to their entries.
+type Manifest map[string]Entry
+
+// Result lists changed paths, each sorted.
+type Result struct {
+	Added    []string
+	Modified []string
+	Removed  []string
+}
+
+// Command returns the shell command that prints a manifest of the sandbox
+// root filesystem, skipping ignore. It uses sudo when available so files
+// owned by other users are included; unreadable files are skipped.
+func Command(ignore []string) string {
+	return command("/", ignore)
+}
+
+func command(root string, ignore []string) string {
+	prune := ""
+	if len(ignore) > 0 {
+		parts := make([]string, 0, len(ignore))
+		for _, p := range ignore {
+			parts = append(parts, "-path "+shellutil.Quote(strings.TrimRight(p, "/")))
+		}
+		prune = `\( ` + strings.Join(parts, " -o ") + ` \) -prune -o `
+	}
+	find := "find " + shellutil.Quote(root) + " -xdev " + prune + "-type f"
+	script := fmt.Sprintf(
+		"%s -printf 'F\\t%%s\\t%%T@\\t%%p\\n'; %s -size -%dc -print0 | xargs -0 -r sha256sum",
+		find, find, HashSizeLimit+1)
+	return "if command -v sudo >/dev/null 2>&1 && sudo -n true 2>/dev/null; then S='sudo -n'; else S=; fi; " +
+		"$S sh -c " + shellutil.Quote(script) + " 2>/dev/null; true"
+}
+
+// ParseOutput builds a manifest from the output of Command.
+func ParseOutput(out string) Manifest {
+	m := make(Manifest)
+	hashes := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if rest, ok := strings.CutPrefix(line, "F\t"); ok {
+			fields := strings.SplitN(rest, "\t", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			size, err := strconv.ParseInt(fields[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			m[fields[2]] = Entry{Size: size, MTime: fields[1]}
+			continue
+		}
+		// sha256sum prints "<64 hex>  <path>".
+		if len(line) > 66 && line[64:66] == "  " {
+			hashes[line[66:]] = line[:64]
+		}
+	}
+	for path, h := range hashes {
+		if e, ok := m[path]; ok {
+			e.Hash = h
+			m[path] = e
+		}
+	}
+	return m
+}
+
+// Encode serializes the manifest for storage.
+func (m Manifest) Encode() (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("encode manifest: %w", err)
+	}
+	return string(data), nil
+}
+
+// Decode parses a manifest produced by Encode.
+func Decode(s string) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Diff compares two manifests, skipping paths under ignore. A file is
+// modified when both sides have a hash and they differ, or otherwise when its
+// size or mtime changed.
+func Diff(from, to Manifest, ignore []string) Result {
+	var r Result
+	for path, b := range to {
+		if Ignored(path, ignore) {
+			continue
+		}
+		a, ok := from[path]
+		switch {
+		case !ok:
+			r.Added = append(r.Added, path)
+		case a.Hash != "" && b.Hash != "":
+			if a.Hash != b.Hash {
+				r.Modified = append(r.Modified, path)
+			}
+		case a.Size != b.Size || a.MTime != b.MTime:
+			r.Modified = append(r.Modified, path)
+		}
+	}
+	for path := range from {
+		if _, ok := to[path]; !ok && !Ignored(path, ignore) {
+			r.Removed = append(r.Removed, path)
+		}
+	}
+	sort.Strings(r.Added)
+	sort.Strings(r.Modified)
+	sort.Strings(r.Removed)
+	return r
+}
+
+// Ignored reports whether path is one of ignore or below one of them.
+func Ignored(path string, ignore []string) bool {
+	for _, p := range ignore {
+		p = strings.TrimRight(p, "/")
+		if p == "" {
+			continue
+		}
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
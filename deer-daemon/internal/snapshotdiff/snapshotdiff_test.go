@@ -0,0 +1,123 @@
+package snapshotdiff
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const hashA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+func TestParseOutput(t *testing.T) {
+	out := "F\t12\t1700000000.5\t/etc/app.conf\n" +
+		"F\t5000000\t1700000001.0\t/usr/bin/big\n" +
+		hashA + "  /etc/app.conf\n" +
+		"garbage\n"
+	m := ParseOutput(out)
+	want := Manifest{
+		"/etc/app.conf": {Size: 12, MTime: "1700000000.5", Hash: hashA},
+		"/usr/bin/big":  {Size: 5000000, MTime: "1700000001.0"},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("ParseOutput = %v, want %v", m, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	from := Manifest{
+		"/etc/same":       {Size: 1, MTime: "1", Hash: "x"},
+		"/etc/touched":    {Size: 1, MTime: "1", Hash: "x"},
+		"/etc/edited":     {Size: 1, MTime: "1", Hash: "x"},
+		"/usr/big":        {Size: 9, MTime: "1"},
+		"/etc/gone":       {Size: 1, MTime: "1"},
+		"/var/log/syslog": {Size: 1, MTime: "1"},
+	}
+	to := Manifest{
+		"/etc/same":      {Size: 1, MTime: "1", Hash: "x"},
+		"/etc/touched":   {Size: 1, MTime: "2", Hash: "x"},
+		"/etc/edited":    {Size: 1, MTime: "1", Hash: "y"},
+		"/usr/big":       {Size: 10, MTime: "2"},
+		"/etc/new":       {Size: 1, MTime: "2"},
+		"/var/log/other": {Size: 1, MTime: "2"},
+	}
+	got := Diff(from, to, []string{"/var/log/"})
+	want := Result{
+		Added:    []string{"/etc/new"},
+		Modified: []string{"/etc/edited", "/usr/big"},
+		Removed:  []string{"/etc/gone"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff = %+v, want %+v", got, want)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	m := Manifest{"/etc/a": {Size: 3, MTime: "1.5", Hash: hashA}}
+	s, err := m.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, m) {
+		t.Errorf("round trip = %v, want %v", back, m)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	ignore := []string{"/proc", "/var/log"}
+	for path, want := range map[string]bool{
+		"/proc":          true,
+		"/proc/1/status": true,
+		"/processes":     false,
+		"/var/log/x":     true,
+		"/var/lib/x":     false,
+	} {
+		if got := Ignored(path, ignore); got != want {
+			t.Errorf("Ignored(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestCommand_CapturesTree(t *testing.T) {
+	for _, tool := range []string{"find", "sha256sum", "xargs"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"etc/app.conf":   "key=value\n",
+		"it's/file":      "x",
+		"skip/cache.bin": "junk",
+	} {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := exec.Command("sh", "-c", command(root, []string{filepath.Join(root, "skip")})).Output()
+	if err != nil {
+		t.Fatalf("run manifest command: %v", err)
+	}
+	m := ParseOutput(string(out))
+
+	conf, ok := m[filepath.Join(root, "etc/app.conf")]
+	if !ok || conf.Size != 10 || len(conf.Hash) != 64 {
+		t.Errorf("app.conf entry = %+v, %v; want size 10 with a hash", conf, ok)
+	}
+	if _, ok := m[filepath.Join(root, "it's/file")]; !ok {
+		t.Error("file under a quoted path missing from manifest")
+	}
+	if _, ok := m[filepath.Join(root, "skip/cache.bin")]; ok {
+		t.Error("ignored path included in manifest")
+	}
+}
@@ -2,10 +2,22 @@
 // sandboxes from remote production VMs.
 package snapshotpull
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // SnapshotBackend abstracts the mechanism for pulling a VM disk from a remote host.
 type SnapshotBackend interface {
 	// SnapshotAndPull transfers vmName's disk to destPath.
 	SnapshotAndPull(ctx context.Context, vmName string, destPath string) error
 }
+
+// ThrottleFunc wraps the download stream of a single transfer.
+type ThrottleFunc func(ctx context.Context, r io.Reader) io.Reader
+
+// ThrottledBackend is implemented by backends that stream the disk through
+// the daemon and can therefore rate-limit the transfer.
+type ThrottledBackend interface {
+	SetThrottle(fn ThrottleFunc)
+}
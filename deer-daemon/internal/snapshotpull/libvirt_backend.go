@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -20,6 +22,7 @@ type LibvirtBackend struct {
 	sshUser         string
 	sshIdentityFile string
 	virshURI        string
+	throttle        ThrottleFunc
 	logger          *slog.Logger
 }
 
@@ -89,7 +92,11 @@ func (b *LibvirtBackend) SnapshotAndPull(ctx context.Context, vmName string, des
 	b.refreshStoragePools(ctx)
 
 	b.logger.Info("downloading disk via vol-download", "vm", vmName, "src", diskPath, "dest", destPath)
-	if _, err := b.runVirshCmd(ctx, "vol-download", diskPath, destPath); err != nil {
+	if b.throttle != nil {
+		if err := b.downloadThrottled(ctx, diskPath, destPath); err != nil {
+			return fmt.Errorf("download disk: %w", err)
+		}
+	} else if _, err := b.runVirshCmd(ctx, "vol-download", diskPath, destPath); err != nil {
 		return fmt.Errorf("download disk: %w", err)
 	}
 
@@ -97,6 +104,39 @@ func (b *LibvirtBackend) SnapshotAndPull(ctx context.Context, vmName string, des
 	return nil
 }
 
+// SetThrottle rate-limits vol-download by streaming it through the daemon.
+func (b *LibvirtBackend) SetThrottle(fn ThrottleFunc) { b.throttle = fn }
+
+// downloadThrottled streams vol-download through stdout so the copy to
+// destPath can be rate limited.
+func (b *LibvirtBackend) downloadThrottled(ctx context.Context, diskPath, destPath string) error {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+
+	cmd := exec.CommandContext(ctx, "virsh", "-c", b.virshURI, "vol-download", diskPath, "/dev/stdout")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start virsh vol-download: %w", err)
+	}
+	_, copyErr := io.Copy(out, b.throttle(ctx, stdout))
+	if copyErr != nil {
+		// Unblock virsh if we stopped reading early.
+		_ = cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("virsh vol-download: %w: %s", err, stderr.String())
+	}
+	return copyErr
+}
+
 // findDiskPath uses virsh domblklist to find the primary disk path.
 func (b *LibvirtBackend) findDiskPath(ctx context.Context, vmName string) (string, error) {
 	out, err := b.runVirshCmd(ctx, "domblklist", vmName, "--details")
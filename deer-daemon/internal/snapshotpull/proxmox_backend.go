@@ -13,6 +13,8 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
 )
 
 // ProxmoxBackend snapshots and pulls a VM disk from a Proxmox VE host via its REST API.
@@ -22,6 +24,7 @@ type ProxmoxBackend struct {
 	secret     string
 	node       string
 	httpClient *http.Client
+	throttle   ThrottleFunc
 	logger     *slog.Logger
 }
 
@@ -48,6 +51,9 @@ func NewProxmoxBackend(host, tokenID, secret, node string, verifySSL bool, logge
 	}
 }
 
+// SetThrottle rate-limits the vzdump download.
+func (b *ProxmoxBackend) SetThrottle(fn ThrottleFunc) { b.throttle = fn }
+
 // SnapshotAndPull creates a snapshot on Proxmox, exports it via vzdump,
 // downloads the dump, converts to qcow2, and cleans up.
 func (b *ProxmoxBackend) SnapshotAndPull(ctx context.Context, vmName string, destPath string) error {
@@ -166,16 +172,10 @@ func (b *ProxmoxBackend) vzdump(ctx context.Context, vmid string) (string, error
 func (b *ProxmoxBackend) waitForTask(ctx context.Context, upid string) error {
 	path := fmt.Sprintf("/nodes/%s/tasks/%s/status", b.node, url.PathEscape(upid))
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(2 * time.Second):
-		}
-
+	return provider.WaitFor(ctx, "task "+upid, 0, func(ctx context.Context) (bool, error) {
 		data, err := b.apiGet(ctx, path)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		var status struct {
@@ -183,16 +183,17 @@ func (b *ProxmoxBackend) waitForTask(ctx context.Context, upid string) error {
 			Exitstatus string `json:"exitstatus"`
 		}
 		if err := json.Unmarshal(data, &status); err != nil {
-			return fmt.Errorf("parse task status: %w", err)
+			return false, fmt.Errorf("parse task status: %w", err)
 		}
 
-		if status.Status == "stopped" {
-			if status.Exitstatus != "OK" {
-				return fmt.Errorf("task failed: %s", status.Exitstatus)
-			}
-			return nil
+		if status.Status != "stopped" {
+			return false, nil
 		}
-	}
+		if status.Exitstatus != "OK" {
+			return false, fmt.Errorf("task failed: %s", status.Exitstatus)
+		}
+		return true, nil
+	})
 }
 
 // findLatestDump finds the most recent vzdump file for a VMID.
@@ -259,7 +260,11 @@ func (b *ProxmoxBackend) downloadFile(ctx context.Context, volid, localPath stri
 	}
 	defer func() { _ = out.Close() }()
 
-	_, err = io.Copy(out, resp.Body)
+	var body io.Reader = resp.Body
+	if b.throttle != nil {
+		body = b.throttle(ctx, body)
+	}
+	_, err = io.Copy(out, body)
 	return err
 }
 
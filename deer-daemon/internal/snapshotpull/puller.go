@@ -3,6 +3,7 @@ package snapshotpull
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"regexp"
@@ -45,6 +46,10 @@ type Puller struct {
 
 	mu       sync.Mutex
 	inflight map[string]*inflightEntry
+
+	// perTransferBPS caps each pull; total is shared by all concurrent pulls.
+	perTransferBPS int64
+	total          *Limiter
 }
 
 // NewPuller creates a new Puller.
@@ -60,6 +65,14 @@ func NewPuller(imgStore *image.Store, db *gorm.DB, logger *slog.Logger) *Puller
 	}
 }
 
+// SetDownloadLimits caps pull bandwidth in bytes per second, both for each
+// transfer and for all concurrent transfers combined. Zero means unlimited.
+// Only backends implementing ThrottledBackend are limited.
+func (p *Puller) SetDownloadLimits(perTransferBPS, totalBPS int64) {
+	p.perTransferBPS = perTransferBPS
+	p.total = NewLimiter(totalBPS)
+}
+
 // Pull pulls a VM snapshot image, using the cache when appropriate.
 // Concurrent pulls for the same image are deduplicated.
 //
@@ -124,6 +137,13 @@ func (p *Puller) doPull(ctx context.Context, imageName string, req PullRequest,
 
 	destPath := p.imgStore.BaseDir() + "/" + imageName + ".qcow2"
 
+	if tb, ok := backend.(ThrottledBackend); ok && (p.perTransferBPS > 0 || p.total != nil) {
+		perTransfer := NewLimiter(p.perTransferBPS)
+		tb.SetThrottle(func(ctx context.Context, r io.Reader) io.Reader {
+			return NewThrottledReader(ctx, r, perTransfer, p.total)
+		})
+	}
+
 	// Snapshot and pull
 	if err := backend.SnapshotAndPull(ctx, req.VMName, destPath); err != nil {
 		return nil, fmt.Errorf("snapshot and pull: %w", err)
@@ -1,8 +1,10 @@
 package snapshotpull
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -308,3 +310,41 @@ func TestPuller_CacheMissWhenFileDeleted(t *testing.T) {
 		t.Errorf("expected 2 backend calls, got %d", backend.callCount.Load())
 	}
 }
+
+// throttledMockBackend copies its payload through the installed throttle.
+type throttledMockBackend struct {
+	throttle ThrottleFunc
+}
+
+func (m *throttledMockBackend) SetThrottle(fn ThrottleFunc) { m.throttle = fn }
+
+func (m *throttledMockBackend) SnapshotAndPull(ctx context.Context, _ string, destPath string) error {
+	var r io.Reader = bytes.NewReader(bytes.Repeat([]byte("x"), 64*1024))
+	if m.throttle != nil {
+		r = m.throttle(ctx, r)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destPath, data, 0o644)
+}
+
+func TestPuller_AppliesDownloadLimits(t *testing.T) {
+	db := setupTestDB(t)
+	imgStore := setupTestImageStore(t)
+	puller := NewPuller(imgStore, db, nil)
+	puller.SetDownloadLimits(128*1024, 0)
+	backend := &throttledMockBackend{}
+
+	start := time.Now()
+	if _, err := puller.Pull(context.Background(), PullRequest{SourceHost: "host1", VMName: "vm1", SnapshotMode: "fresh"}, backend); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if backend.throttle == nil {
+		t.Fatal("expected the puller to install a throttle")
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("pull took %s, want about 500ms at the configured limit", elapsed)
+	}
+}
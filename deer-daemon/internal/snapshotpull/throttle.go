@@ -0,0 +1,97 @@
+package snapshotpull
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// throttleChunk bounds a single throttled read so waits stay short and even.
+const throttleChunk = 32 * 1024
+
+// Limiter is a token bucket measured in bytes per second. One Limiter can be
+// shared by several readers to cap their combined rate. A nil *Limiter never
+// waits.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	tokens float64 // may go negative while a reader is paying off a read
+	last   time.Time
+}
+
+// NewLimiter returns a limiter for bytesPerSec, or nil when it is not positive
+// (unlimited). The bucket starts empty and holds at most one second of data.
+func NewLimiter(bytesPerSec int64) *Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &Limiter{rate: float64(bytesPerSec), last: time.Now()}
+}
+
+// WaitN blocks until n bytes may pass or ctx is done.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttledReader delays reads from r so they stay within every limiter.
+type throttledReader struct {
+	ctx      context.Context
+	r        io.Reader
+	limiters []*Limiter
+}
+
+// NewThrottledReader wraps r so reads respect all non-nil limiters. It returns
+// r unchanged when none are set.
+func NewThrottledReader(ctx context.Context, r io.Reader, limiters ...*Limiter) io.Reader {
+	var active []*Limiter
+	for _, l := range limiters {
+		if l != nil {
+			active = append(active, l)
+		}
+	}
+	if len(active) == 0 {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, limiters: active}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+	n, err := t.r.Read(p)
+	for _, l := range t.limiters {
+		if werr := l.WaitN(t.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,68 @@
+package snapshotpull
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottledReader_CapsThroughput(t *testing.T) {
+	const rate = 256 * 1024
+	data := bytes.Repeat([]byte("x"), rate/2)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, NewThrottledReader(context.Background(), bytes.NewReader(data), NewLimiter(rate)))
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("copied %d bytes, want %d", n, len(data))
+	}
+	// Half a second of data at the limit; allow scheduling slack.
+	if elapsed < 400*time.Millisecond || elapsed > 1500*time.Millisecond {
+		t.Errorf("elapsed = %s, want about 500ms", elapsed)
+	}
+}
+
+func TestThrottledReader_SharedLimiterCapsAggregate(t *testing.T) {
+	const rate = 256 * 1024
+	total := NewLimiter(rate)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := bytes.NewReader(bytes.Repeat([]byte("x"), rate/4))
+			_, _ = io.Copy(io.Discard, NewThrottledReader(context.Background(), data, nil, total))
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if elapsed < 400*time.Millisecond || elapsed > 1500*time.Millisecond {
+		t.Errorf("elapsed = %s, want about 500ms for two transfers sharing the limit", elapsed)
+	}
+}
+
+func TestThrottledReader_Unlimited(t *testing.T) {
+	r := bytes.NewReader(nil)
+	if got := NewThrottledReader(context.Background(), r, nil, NewLimiter(0)); got != io.Reader(r) {
+		t.Error("expected the reader unchanged when no limit is set")
+	}
+}
+
+func TestThrottledReader_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	data := bytes.NewReader(bytes.Repeat([]byte("x"), 64*1024))
+	_, err := io.Copy(io.Discard, NewThrottledReader(ctx, data, NewLimiter(1024)))
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/aspectrr/deer.sh/deer-daemon/internal/provider"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/readonly"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/shellutil"
 	"github.com/aspectrr/deer.sh/deer-daemon/internal/sshkeys"
@@ -24,7 +27,8 @@ type VMInfo struct {
 	Prepared  bool   `json:"prepared"`
 }
 
-// ValidationResult contains the result of validating a source VM.
+// ValidationResult contains the result of validating a source VM. Its fields
+// match provider.ValidationResult, which the daemon converts it to.
 type ValidationResult struct {
 	VMName     string   `json:"vm_name"`
 	Valid      bool     `json:"valid"`
@@ -34,6 +38,18 @@ type ValidationResult struct {
 	HasNetwork bool     `json:"has_network"`
 	Warnings   []string `json:"warnings,omitempty"`
 	Errors     []string `json:"errors,omitempty"`
+
+	Issues []provider.ValidationIssue `json:"issues,omitempty"`
+}
+
+func (r *ValidationResult) addError(code, detail string) {
+	r.Errors = append(r.Errors, detail)
+	r.Issues = append(r.Issues, provider.ValidationIssue{Code: code, Severity: provider.SeverityError, Detail: detail})
+}
+
+func (r *ValidationResult) addWarning(code, detail string) {
+	r.Warnings = append(r.Warnings, detail)
+	r.Issues = append(r.Issues, provider.ValidationIssue{Code: code, Severity: provider.SeverityWarning, Detail: detail})
 }
 
 // PrepareResult contains the outcome of preparing a source VM.
@@ -58,9 +74,16 @@ type Manager struct {
 	proxyJump    string
 	identityFile string
 	caPubKey     string
+	authFile     string // libvirt auth file for SASL, exported as LIBVIRT_AUTH_FILE
+	readyTimeout time.Duration
+	policy       *readonly.Policy // nil checks against the built-in allowlist
 	logger       *slog.Logger
 }
 
+// defaultReadyTimeout bounds the wait for a source VM's IP and SSH when no
+// timeout is configured. It matches the default IP discovery timeout.
+const defaultReadyTimeout = 30 * time.Second
+
 // NewManager creates a source VM manager.
 func NewManager(libvirtURI, network string, keyMgr sshkeys.KeyProvider, sshUser, proxyJump, identityFile, caPubKey string, logger *slog.Logger) *Manager {
 	if logger == nil {
@@ -77,11 +100,49 @@ func NewManager(libvirtURI, network string, keyMgr sshkeys.KeyProvider, sshUser,
 		proxyJump:    proxyJump,
 		identityFile: identityFile,
 		caPubKey:     caPubKey,
+		readyTimeout: defaultReadyTimeout,
 		logger:       logger.With("component", "sourcevm"),
 	}
 }
 
+// SetReadyTimeout sets how long preparation waits for a source VM to report
+// an IP address and accept SSH. Non-positive values keep the default.
+func (m *Manager) SetReadyTimeout(d time.Duration) {
+	if d > 0 {
+		m.readyTimeout = d
+	}
+}
+
+// SetPolicy sets the read-only policy source commands are checked against.
+// A nil policy keeps the built-in allowlist.
+func (m *Manager) SetPolicy(p *readonly.Policy) {
+	m.policy = p
+}
+
+// SetSASLAuth writes a libvirt auth file holding user and password to path
+// (mode 0600) and makes virsh use it for SASL authentication.
+func (m *Manager) SetSASLAuth(path, user, password string) error {
+	if strings.ContainsAny(user+password, "\r\n") {
+		return fmt.Errorf("libvirt SASL credentials must not contain newlines")
+	}
+	content := fmt.Sprintf("[credentials-deer]\nauthname=%s\npassword=%s\n\n[auth-libvirt-default]\ncredentials=deer\n", user, password)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create libvirt auth dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("write libvirt auth file: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("chmod libvirt auth file: %w", err)
+	}
+	m.authFile = path
+	return nil
+}
+
 // ListVMs returns available source VMs (non-sandbox VMs visible to libvirt).
+// If ctx is cancelled part-way through, the VMs inspected so far are returned
+// together with the context error.
 func (m *Manager) ListVMs(ctx context.Context) ([]VMInfo, error) {
 	// Use virsh to list all VMs
 	output, err := m.virsh(ctx, "list", "--all", "--name")
@@ -95,6 +156,9 @@ func (m *Manager) ListVMs(ctx context.Context) ([]VMInfo, error) {
 		if name == "" || strings.HasPrefix(name, "sbx-") {
 			continue // Skip sandbox VMs
 		}
+		if err := ctx.Err(); err != nil {
+			return vms, err
+		}
 
 		state, _ := m.getVMState(ctx, name)
 		ip := ""
@@ -121,13 +185,13 @@ func (m *Manager) ValidateSourceVM(ctx context.Context, vmName string) (*Validat
 	// Check VM exists and get state
 	state, err := m.getVMState(ctx, vmName)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("VM not found: %v", err))
+		result.addError(provider.IssueVMNotFound, fmt.Sprintf("VM not found: %v", err))
 		return result, nil
 	}
 	result.State = state
 
 	if state != "running" {
-		result.Errors = append(result.Errors, "VM is not running")
+		result.addError(provider.IssueVMNotRunning, "VM is not running")
 		return result, nil
 	}
 
@@ -137,7 +201,7 @@ func (m *Manager) ValidateSourceVM(ctx context.Context, vmName string) (*Validat
 		result.MACAddress = mac
 		result.HasNetwork = true
 	} else {
-		result.Warnings = append(result.Warnings, "Could not determine MAC address")
+		result.addWarning(provider.IssueNoMACAddress, "Could not determine MAC address")
 	}
 
 	// Check IP
@@ -145,7 +209,7 @@ func (m *Manager) ValidateSourceVM(ctx context.Context, vmName string) (*Validat
 	if err == nil && ip != "" {
 		result.IPAddress = ip
 	} else {
-		result.Warnings = append(result.Warnings, "Could not determine IP address")
+		result.addWarning(provider.IssueNoIPAddress, "Could not determine IP address")
 	}
 
 	// Check if deer-readonly user exists by trying SSH
@@ -156,10 +220,10 @@ func (m *Manager) ValidateSourceVM(ctx context.Context, vmName string) (*Validat
 			if err == nil && exitCode == 0 {
 				result.Valid = true
 			} else {
-				result.Warnings = append(result.Warnings, "SSH as deer-readonly failed - VM may not be prepared")
+				result.addWarning(provider.IssueReadonlySSHFailed, "SSH as deer-readonly failed - VM may not be prepared")
 			}
 		} else {
-			result.Warnings = append(result.Warnings, "Could not get SSH credentials")
+			result.addWarning(provider.IssueCredentialsNotFound, "Could not get SSH credentials")
 		}
 	}
 
@@ -176,9 +240,9 @@ func (m *Manager) PrepareSourceVM(ctx context.Context, vmName, sshUser, sshKeyPa
 		sshUser = m.sshUser
 	}
 
-	ip, err := m.getVMIP(ctx, vmName)
+	ip, err := m.waitReady(ctx, vmName, m.getVMIP, m.sshKeyProbe(sshUser, sshKeyPath))
 	if err != nil {
-		return nil, fmt.Errorf("get VM IP: %w", err)
+		return nil, err
 	}
 
 	// Build SSH run function for the prepare flow
@@ -214,9 +278,9 @@ func (m *Manager) PrepareSourceVMWithCA(ctx context.Context, vmName, sshUser, ss
 		sshUser = m.sshUser
 	}
 
-	ip, err := m.getVMIP(ctx, vmName)
+	ip, err := m.waitReady(ctx, vmName, m.getVMIP, m.sshKeyProbe(sshUser, sshKeyPath))
 	if err != nil {
-		return nil, fmt.Errorf("get VM IP: %w", err)
+		return nil, err
 	}
 
 	sshRun := func(ctx context.Context, command string) (string, string, int, error) {
@@ -245,7 +309,7 @@ func (m *Manager) PrepareSourceVMWithCA(ctx context.Context, vmName, sshUser, ss
 // Two-layer validation: client-side allowlist + server-side restricted shell.
 func (m *Manager) RunSourceCommand(ctx context.Context, vmName, command string, timeout time.Duration) (stdout, stderr string, exitCode int, err error) {
 	// Client-side validation
-	if err := readonly.ValidateCommand(command); err != nil {
+	if err := m.policy.ValidateCommand(command); err != nil {
 		return "", "", 126, fmt.Errorf("command validation: %w", err)
 	}
 
@@ -291,6 +355,9 @@ func (m *Manager) ReadSourceFile(ctx context.Context, vmName, path string) (stri
 func (m *Manager) virsh(ctx context.Context, args ...string) (string, error) {
 	allArgs := append([]string{"-c", m.libvirtURI}, args...)
 	cmd := exec.CommandContext(ctx, "virsh", allArgs...)
+	if m.authFile != "" {
+		cmd.Env = append(os.Environ(), "LIBVIRT_AUTH_FILE="+m.authFile)
+	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -323,6 +390,43 @@ func (m *Manager) getVMMAC(ctx context.Context, vmName string) (string, error) {
 	return "", fmt.Errorf("no MAC address found")
 }
 
+// waitReady polls until vmName has an IP address and sshReady accepts it,
+// bounded by the ready timeout, and returns the IP. A VM that was just
+// started is prepared as soon as it can be reached rather than after a
+// fixed delay.
+func (m *Manager) waitReady(ctx context.Context, vmName string, vmIP func(context.Context, string) (string, error), sshReady func(ctx context.Context, ip string) bool) (string, error) {
+	var ip string
+	var lastErr error
+	err := provider.WaitFor(ctx, fmt.Sprintf("SSH on source VM %s", vmName), m.readyTimeout, func(ctx context.Context) (bool, error) {
+		addr, err := vmIP(ctx, vmName)
+		if err != nil {
+			lastErr = fmt.Errorf("get VM IP: %w", err)
+			return false, nil
+		}
+		ip = addr
+		if !sshReady(ctx, addr) {
+			lastErr = fmt.Errorf("SSH not accepting connections on %s", addr)
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		if lastErr != nil {
+			return "", fmt.Errorf("%w: %v", err, lastErr)
+		}
+		return "", err
+	}
+	return ip, nil
+}
+
+// sshKeyProbe reports whether ip accepts SSH as user with keyPath.
+func (m *Manager) sshKeyProbe(user, keyPath string) func(ctx context.Context, ip string) bool {
+	return func(ctx context.Context, ip string) bool {
+		_, _, exitCode, err := m.sshCmdWithKey(ctx, ip, user, keyPath, "true", 10*time.Second)
+		return err == nil && exitCode == 0
+	}
+}
+
 func (m *Manager) getVMIP(ctx context.Context, vmName string) (string, error) {
 	output, err := m.virsh(ctx, "domifaddr", vmName, "--source", "lease")
 	if err != nil {
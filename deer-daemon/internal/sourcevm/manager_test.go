@@ -0,0 +1,66 @@
+package sourcevm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitReady_ReturnsWhenSSHAccepts(t *testing.T) {
+	m := NewManager("", "", nil, "", "", "", "", nil)
+	m.SetReadyTimeout(time.Minute)
+
+	ipCalls, sshCalls := 0, 0
+	vmIP := func(context.Context, string) (string, error) {
+		ipCalls++
+		if ipCalls == 1 {
+			return "", errors.New("no IP address found")
+		}
+		return "192.168.122.10", nil
+	}
+	sshReady := func(_ context.Context, ip string) bool {
+		sshCalls++
+		return sshCalls == 2
+	}
+
+	start := time.Now()
+	ip, err := m.waitReady(context.Background(), "web-01", vmIP, sshReady)
+	if err != nil {
+		t.Fatalf("waitReady() error: %v", err)
+	}
+	if ip != "192.168.122.10" {
+		t.Errorf("ip = %q", ip)
+	}
+	if sshCalls != 2 {
+		t.Errorf("SSH probed %d times, want 2", sshCalls)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waitReady() took %s after SSH was ready", elapsed)
+	}
+}
+
+func TestWaitReady_Timeout(t *testing.T) {
+	m := NewManager("", "", nil, "", "", "", "", nil)
+	m.SetReadyTimeout(300 * time.Millisecond)
+
+	vmIP := func(context.Context, string) (string, error) { return "192.168.122.10", nil }
+	sshReady := func(context.Context, string) bool { return false }
+
+	_, err := m.waitReady(context.Background(), "web-01", vmIP, sshReady)
+	if err == nil {
+		t.Fatal("waitReady() succeeded though SSH never accepted")
+	}
+	if !strings.Contains(err.Error(), "SSH not accepting connections on 192.168.122.10") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestSetReadyTimeout_KeepsDefault(t *testing.T) {
+	m := NewManager("", "", nil, "", "", "", "", nil)
+	m.SetReadyTimeout(0)
+	if m.readyTimeout != defaultReadyTimeout {
+		t.Errorf("readyTimeout = %s, want %s", m.readyTimeout, defaultReadyTimeout)
+	}
+}
@@ -0,0 +1,66 @@
+package sshkeys
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Host key modes for SSH connections to sandboxes.
+const (
+	// HostKeyInsecure accepts any host key and records none. Sandboxes are
+	// ephemeral and reuse IPs, so this is the default.
+	HostKeyInsecure = "insecure"
+	// HostKeyTOFU records a sandbox's host key on first connect, in a
+	// known_hosts file of its own, and refuses a different key after that.
+	HostKeyTOFU = "tofu"
+	// HostKeyStrict only accepts host keys already in a known_hosts file the
+	// operator provides, such as one with an @cert-authority line for images
+	// whose host keys are signed.
+	HostKeyStrict = "strict"
+)
+
+// ValidateHostKeyMode checks mode, and that strict mode names its
+// known_hosts file. An empty mode is insecure.
+func ValidateHostKeyMode(mode, knownHostsFile string) error {
+	switch mode {
+	case "", HostKeyInsecure, HostKeyTOFU:
+		return nil
+	case HostKeyStrict:
+		if knownHostsFile == "" {
+			return fmt.Errorf("host_key_mode %q needs ssh.known_hosts_file", mode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown host_key_mode %q: want %q, %q or %q", mode, HostKeyInsecure, HostKeyTOFU, HostKeyStrict)
+	}
+}
+
+// KnownHostsPath returns the sandbox's own known_hosts file, kept beside its
+// keys so CleanupSandbox removes it and a later sandbox on the same IP starts
+// afresh.
+func (c *Credentials) KnownHostsPath() string {
+	return filepath.Join(filepath.Dir(c.PrivateKeyPath), "known_hosts")
+}
+
+// HostKeyArgs returns the ssh options that check a sandbox's host key under
+// mode. knownHostsFile is the operator's file for strict mode; tofu uses
+// creds.KnownHostsPath. An empty or unknown mode is insecure.
+func HostKeyArgs(mode, knownHostsFile string, creds *Credentials) []string {
+	switch mode {
+	case HostKeyTOFU:
+		return []string{
+			"-o", "StrictHostKeyChecking=accept-new",
+			"-o", "UserKnownHostsFile=" + creds.KnownHostsPath(),
+		}
+	case HostKeyStrict:
+		return []string{
+			"-o", "StrictHostKeyChecking=yes",
+			"-o", "UserKnownHostsFile=" + knownHostsFile,
+		}
+	default:
+		return []string{
+			"-o", "StrictHostKeyChecking=no",
+			"-o", "UserKnownHostsFile=/dev/null",
+		}
+	}
+}
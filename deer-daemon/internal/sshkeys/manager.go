@@ -29,6 +29,11 @@ type KeyProvider interface {
 	// Otherwise, new credentials are generated.
 	GetCredentials(ctx context.Context, sandboxID, username string) (*Credentials, error)
 
+	// RenewCredentials discards any cached credentials for a sandbox and
+	// issues a fresh key and certificate, whether or not the old ones
+	// have expired.
+	RenewCredentials(ctx context.Context, sandboxID, username string) (*Credentials, error)
+
 	// GetSourceVMCredentials returns read-only SSH credentials for a source/golden VM.
 	// Uses the "deer-readonly" principal instead of "sandbox".
 	GetSourceVMCredentials(ctx context.Context, sourceVMName string) (*Credentials, error)
@@ -194,6 +199,37 @@ func (m *KeyManager) GetCredentials(ctx context.Context, sandboxID, username str
 	return newCreds, nil
 }
 
+// RenewCredentials implements KeyProvider.
+func (m *KeyManager) RenewCredentials(ctx context.Context, sandboxID, username string) (*Credentials, error) {
+	if sandboxID == "" {
+		return nil, fmt.Errorf("sandboxID is required")
+	}
+	if username == "" {
+		username = m.cfg.DefaultUsername
+	}
+
+	lock := m.getSandboxLock(sandboxID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	m.logger.Info("renewing credentials",
+		"sandbox_id", sandboxID,
+		"username", username,
+		"ttl", m.cfg.CertificateTTL,
+	)
+
+	newCreds, err := m.generateCredentials(ctx, sandboxID, username)
+	if err != nil {
+		return nil, fmt.Errorf("renew credentials: %w", err)
+	}
+
+	m.mu.Lock()
+	m.credentials[m.cacheKey(sandboxID, username)] = newCreds
+	m.mu.Unlock()
+
+	return newCreds, nil
+}
+
 // CleanupSandbox implements KeyProvider.
 func (m *KeyManager) CleanupSandbox(ctx context.Context, sandboxID string) error {
 	if sandboxID == "" {
@@ -3,6 +3,7 @@ package state
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,8 @@ import (
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	genid "github.com/aspectrr/deer.sh/deer-daemon/internal/id"
 )
 
 // Sandbox represents a sandbox in local state.
@@ -28,9 +31,18 @@ type Sandbox struct {
 	VCPUs      int
 	MemoryMB   int
 	TTLSeconds int
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  *time.Time `gorm:"index"`
+	// Imported marks a sandbox adopted from an existing VM rather than cloned.
+	Imported bool
+	// Workdir is the directory commands run in when they do not name one;
+	// empty means the login directory.
+	Workdir string
+	// LockedBy and LockExpires form an advisory lock held while a mutating
+	// operation (start/stop/destroy/resize) is in flight.
+	LockedBy    string
+	LockExpires *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   *time.Time `gorm:"index"`
 }
 
 // CachedImage tracks a pulled snapshot image in the local cache.
@@ -45,9 +57,14 @@ type CachedImage struct {
 
 // Command represents a command execution record.
 type Command struct {
-	ID         string `gorm:"primaryKey"`
-	SandboxID  string `gorm:"index"`
-	Command    string
+	ID        string `gorm:"primaryKey"`
+	SandboxID string `gorm:"index"`
+	Command   string
+	// Actor is who initiated the command, e.g. human-cli or tui-agent.
+	Actor string `gorm:"index"`
+	// Workdir is the directory the command ran in; empty means the login
+	// directory.
+	Workdir    string
 	Stdout     string
 	Stderr     string
 	ExitCode   int
@@ -56,6 +73,25 @@ type Command struct {
 	EndedAt    time.Time
 }
 
+// Snapshot records a sandbox snapshot. Manifest holds the filesystem listing
+// taken at snapshot time (see package snapshotdiff); it is empty when the
+// listing could not be captured.
+type Snapshot struct {
+	ID        string `gorm:"primaryKey"`
+	SandboxID string `gorm:"index"`
+	Name      string `gorm:"index"`
+	// Kind is empty for a point-in-time manifest snapshot and "archive" for
+	// a disk archive taken before destroy, whose image path is in Ref.
+	Kind      string
+	Ref       string
+	Manifest  string
+	Packages  string
+	CreatedAt time.Time
+}
+
+// SnapshotKindArchive marks a Snapshot that is a disk archive.
+const SnapshotKindArchive = "archive"
+
 type KafkaCaptureConfig struct {
 	ID                 string   `gorm:"primaryKey"`
 	SourceVM           string   `gorm:"index"`
@@ -129,10 +165,14 @@ func NewStore(dbPath string) (*Store, error) {
 	sqlDB.SetMaxIdleConns(1)
 
 	// Auto-migrate tables
-	if err := db.AutoMigrate(&Sandbox{}, &Command{}, &CachedImage{}, &KafkaCaptureConfig{}, &SandboxKafkaStub{}); err != nil {
+	if err := db.AutoMigrate(&Sandbox{}, &Command{}, &CachedImage{}, &KafkaCaptureConfig{}, &SandboxKafkaStub{}, &Snapshot{}); err != nil {
 		return nil, fmt.Errorf("auto-migrate: %w", err)
 	}
 
+	// Names must be unique among live sandboxes. Databases that already hold
+	// duplicates can't take the index; CreateSandbox still checks in that case.
+	_ = db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_sandboxes_active_name ON sandboxes(name) WHERE deleted_at IS NULL AND name <> ''").Error
+
 	return &Store{db: db}, nil
 }
 
@@ -148,11 +188,56 @@ func (s *Store) Close() error {
 	return sqlDB.Close()
 }
 
-// CreateSandbox creates a new sandbox record.
+// ErrNameInUse is returned by CreateSandbox when a live sandbox already has
+// the requested name.
+var ErrNameInUse = errors.New("sandbox name already in use")
+
+// maxIDAttempts bounds NewSandboxID retries on collision.
+const maxIDAttempts = 5
+
+// CreateSandbox creates a new sandbox record. It returns ErrNameInUse if a
+// live sandbox already has the same non-empty name.
 func (s *Store) CreateSandbox(ctx context.Context, sb *Sandbox) error {
+	if sb.Name != "" {
+		inUse, err := s.SandboxNameInUse(ctx, sb.Name)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return fmt.Errorf("%q: %w", sb.Name, ErrNameInUse)
+		}
+	}
 	return s.db.WithContext(ctx).Create(sb).Error
 }
 
+// SandboxNameInUse reports whether a live (not deleted) sandbox has name.
+func (s *Store) SandboxNameInUse(ctx context.Context, name string) (bool, error) {
+	var n int64
+	err := s.db.WithContext(ctx).Model(&Sandbox{}).
+		Where("name = ? AND deleted_at IS NULL", name).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// NewSandboxID returns a fresh ID with the given prefix that is not used by
+// any sandbox row, including soft-deleted ones (which keep their primary key).
+func (s *Store) NewSandboxID(ctx context.Context, prefix string) (string, error) {
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		candidate, err := genid.Generate(prefix)
+		if err != nil {
+			return "", err
+		}
+		var n int64
+		if err := s.db.WithContext(ctx).Model(&Sandbox{}).Where("id = ?", candidate).Count(&n).Error; err != nil {
+			return "", fmt.Errorf("check sandbox ID: %w", err)
+		}
+		if n == 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("could not generate a unique sandbox ID after %d attempts", maxIDAttempts)
+}
+
 // GetSandbox retrieves a sandbox by ID.
 func (s *Store) GetSandbox(ctx context.Context, id string) (*Sandbox, error) {
 	var sb Sandbox
@@ -162,6 +247,33 @@ func (s *Store) GetSandbox(ctx context.Context, id string) (*Sandbox, error) {
 	return &sb, nil
 }
 
+// GetSandboxes retrieves the non-deleted sandboxes among ids with a single
+// IN query. Found sandboxes are returned in the order of ids; ids with no
+// such sandbox are listed in notFound.
+func (s *Store) GetSandboxes(ctx context.Context, ids []string) (found []*Sandbox, notFound []string, err error) {
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+	var rows []*Sandbox
+	if err := s.db.WithContext(ctx).Where("id IN ? AND deleted_at IS NULL", ids).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[string]*Sandbox, len(rows))
+	for _, sb := range rows {
+		byID[sb.ID] = sb
+	}
+
+	found = make([]*Sandbox, 0, len(byID))
+	for _, id := range ids {
+		if sb, ok := byID[id]; ok {
+			found = append(found, sb)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return found, notFound, nil
+}
+
 // ListSandboxes returns all non-deleted sandboxes.
 func (s *Store) ListSandboxes(ctx context.Context) ([]*Sandbox, error) {
 	var sandboxes []*Sandbox
@@ -171,9 +283,41 @@ func (s *Store) ListSandboxes(ctx context.Context) ([]*Sandbox, error) {
 	return sandboxes, nil
 }
 
-// UpdateSandbox updates a sandbox record.
+// UpdateSandbox updates a sandbox record. The advisory lock columns are left
+// out: callers write from an earlier read, and saving that copy would
+// release or restore a lock someone else took in the meantime. Locks change
+// only through AcquireSandboxLock and the release functions.
 func (s *Store) UpdateSandbox(ctx context.Context, sb *Sandbox) error {
-	return s.db.WithContext(ctx).Save(sb).Error
+	return s.db.WithContext(ctx).Omit("locked_by", "lock_expires").Save(sb).Error
+}
+
+// UpdateSandboxIP sets a sandbox's IP address without touching the rest of
+// the row, so it is safe alongside in-flight updates to other fields.
+func (s *Store) UpdateSandboxIP(ctx context.Context, id, ip string) error {
+	return s.db.WithContext(ctx).Model(&Sandbox{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]any{
+			"ip_address": ip,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+// UpdateSandboxWorkdir sets a sandbox's default workdir without touching the
+// rest of the row. Returns gorm.ErrRecordNotFound if no live sandbox has id.
+func (s *Store) UpdateSandboxWorkdir(ctx context.Context, id, workdir string) error {
+	res := s.db.WithContext(ctx).Model(&Sandbox{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]any{
+			"workdir":    workdir,
+			"updated_at": time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 // DeleteSandbox soft-deletes a sandbox.
@@ -186,14 +330,97 @@ func (s *Store) DeleteSandbox(ctx context.Context, id string) error {
 		}).Error
 }
 
+// LockedError is returned by AcquireSandboxLock when another owner holds
+// an unexpired lock on the sandbox.
+type LockedError struct {
+	SandboxID string
+	Holder    string
+	Expires   time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("sandbox %s is in use by %s (lock expires %s)", e.SandboxID, e.Holder, e.Expires.Format(time.RFC3339))
+}
+
+// AcquireSandboxLock takes the advisory lock on a sandbox for owner. The lock
+// is granted if it is free, expired, or already held by the same owner (which
+// refreshes the expiry). Returns a *LockedError on contention and
+// gorm.ErrRecordNotFound if the sandbox does not exist.
+func (s *Store) AcquireSandboxLock(ctx context.Context, id, owner string, ttl time.Duration) error {
+	now := time.Now().UTC()
+	expires := now.Add(ttl)
+	res := s.db.WithContext(ctx).Model(&Sandbox{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Where("locked_by = '' OR locked_by IS NULL OR locked_by = ? OR lock_expires IS NULL OR lock_expires < ?", owner, now).
+		Updates(map[string]any{
+			"locked_by":    owner,
+			"lock_expires": &expires,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	sb, err := s.GetSandbox(ctx, id)
+	if err != nil {
+		return err
+	}
+	lockErr := &LockedError{SandboxID: id, Holder: sb.LockedBy}
+	if sb.LockExpires != nil {
+		lockErr.Expires = *sb.LockExpires
+	}
+	return lockErr
+}
+
+// ReleaseSandboxLock drops the advisory lock on a sandbox if owner holds it.
+// Releasing a lock held by someone else, or no lock, is a no-op.
+func (s *Store) ReleaseSandboxLock(ctx context.Context, id, owner string) error {
+	return s.db.WithContext(ctx).Model(&Sandbox{}).
+		Where("id = ? AND locked_by = ?", id, owner).
+		Updates(map[string]any{
+			"locked_by":    "",
+			"lock_expires": nil,
+		}).Error
+}
+
+// ForceReleaseSandboxLock drops the advisory lock on a sandbox whoever holds
+// it and returns the holder, or "" if it was not locked. Returns
+// gorm.ErrRecordNotFound if the sandbox does not exist.
+func (s *Store) ForceReleaseSandboxLock(ctx context.Context, id string) (string, error) {
+	sb, err := s.GetSandbox(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if sb.LockedBy == "" {
+		return "", nil
+	}
+	err = s.db.WithContext(ctx).Model(&Sandbox{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"locked_by":    "",
+			"lock_expires": nil,
+		}).Error
+	return sb.LockedBy, err
+}
+
+// IsLocked reports whether err is a *LockedError.
+func IsLocked(err error) bool {
+	var lockErr *LockedError
+	return errors.As(err, &lockErr)
+}
+
 // ListExpiredSandboxes returns sandboxes past their TTL.
 func (s *Store) ListExpiredSandboxes(ctx context.Context, defaultTTL time.Duration) ([]*Sandbox, error) {
 	var sandboxes []*Sandbox
 	now := time.Now().UTC()
 
-	// Find sandboxes where TTL has expired
+	// Find sandboxes where TTL has expired. A CREATING row belongs to a
+	// create still in flight, or to startup reconciliation after a crash, so
+	// the janitor leaves it alone.
 	err := s.db.WithContext(ctx).
-		Where("deleted_at IS NULL AND state NOT IN (?, ?)", "DESTROYED", "ERROR").
+		Where("deleted_at IS NULL AND state NOT IN (?, ?, ?)", "DESTROYED", "ERROR", "CREATING").
 		Find(&sandboxes).Error
 	if err != nil {
 		return nil, err
@@ -201,6 +428,10 @@ func (s *Store) ListExpiredSandboxes(ctx context.Context, defaultTTL time.Durati
 
 	var expired []*Sandbox
 	for _, sb := range sandboxes {
+		// Imported VMs predate deer; only expire them on an explicit TTL.
+		if sb.Imported && sb.TTLSeconds == 0 {
+			continue
+		}
 		ttl := defaultTTL
 		if sb.TTLSeconds > 0 {
 			ttl = time.Duration(sb.TTLSeconds) * time.Second
@@ -213,6 +444,51 @@ func (s *Store) ListExpiredSandboxes(ctx context.Context, defaultTTL time.Durati
 	return expired, nil
 }
 
+// IdleSince returns when sb was last active: the later of its most recent
+// command (a, which may be nil) and its last state change, which covers
+// sandboxes that were just created or restarted and have not run anything.
+func (sb *Sandbox) IdleSince(a *SandboxActivity) time.Time {
+	since := sb.UpdatedAt
+	if since.Before(sb.CreatedAt) {
+		since = sb.CreatedAt
+	}
+	if a != nil && a.LastActivityAt.After(since) {
+		since = a.LastActivityAt
+	}
+	return since
+}
+
+// ListIdleSandboxes returns running sandboxes that have been idle (see
+// Sandbox.IdleSince) for at least idle as of now. Imported VMs are never
+// reported, as with TTL expiry.
+func (s *Store) ListIdleSandboxes(ctx context.Context, idle time.Duration, now time.Time) ([]*Sandbox, error) {
+	var sandboxes []*Sandbox
+	if err := s.db.WithContext(ctx).
+		Where("deleted_at IS NULL AND state = ? AND imported = ?", "RUNNING", false).
+		Find(&sandboxes).Error; err != nil {
+		return nil, err
+	}
+	if len(sandboxes) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(sandboxes))
+	for _, sb := range sandboxes {
+		ids = append(ids, sb.ID)
+	}
+	activity, err := s.ListSandboxActivity(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Sandbox
+	for _, sb := range sandboxes {
+		if now.Sub(sb.IdleSince(activity[sb.ID])) >= idle {
+			out = append(out, sb)
+		}
+	}
+	return out, nil
+}
+
 // CreateCommand creates a command execution record.
 func (s *Store) CreateCommand(ctx context.Context, cmd *Command) error {
 	return s.db.WithContext(ctx).Create(cmd).Error
@@ -227,6 +503,124 @@ func (s *Store) ListSandboxCommands(ctx context.Context, sandboxID string) ([]*C
 	return commands, nil
 }
 
+// ListSandboxCommandsAfter returns a sandbox's commands recorded after the
+// command afterID, oldest first, ordered by when they ended. A non-empty
+// actor keeps only that actor's commands, and a positive limit keeps the
+// oldest limit of them.
+func (s *Store) ListSandboxCommandsAfter(ctx context.Context, sandboxID, afterID, actor string, limit int) ([]*Command, error) {
+	var cursor Command
+	if err := s.db.WithContext(ctx).Where("sandbox_id = ? AND id = ?", sandboxID, afterID).First(&cursor).Error; err != nil {
+		return nil, err
+	}
+	q := s.db.WithContext(ctx).
+		Where("sandbox_id = ? AND (ended_at > ? OR (ended_at = ? AND id > ?))", sandboxID, cursor.EndedAt, cursor.EndedAt, cursor.ID)
+	if actor != "" {
+		q = q.Where("actor = ?", actor)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var commands []*Command
+	if err := q.Order("ended_at ASC, id ASC").Find(&commands).Error; err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// SandboxActivity summarizes a sandbox's command history.
+type SandboxActivity struct {
+	SandboxID      string
+	LastActivityAt time.Time // end of the most recent command
+	CommandCount   int
+	FailedCount    int // commands that exited non-zero
+	TotalRuntimeMS int64
+}
+
+// ListSandboxActivity aggregates command history per sandbox. With no IDs it
+// covers every sandbox. Sandboxes that never ran a command are absent from
+// the result.
+func (s *Store) ListSandboxActivity(ctx context.Context, sandboxIDs ...string) (map[string]*SandboxActivity, error) {
+	var rows []struct {
+		SandboxID    string
+		LastEnded    string
+		CommandCount int
+		FailedCount  int
+		TotalRuntime int64
+	}
+	q := s.db.WithContext(ctx).Model(&Command{}).
+		Select("sandbox_id, MAX(ended_at) AS last_ended, COUNT(*) AS command_count, " +
+			"SUM(CASE WHEN exit_code != 0 THEN 1 ELSE 0 END) AS failed_count, " +
+			"COALESCE(SUM(duration_ms), 0) AS total_runtime").
+		Group("sandbox_id")
+	if len(sandboxIDs) > 0 {
+		q = q.Where("sandbox_id IN ?", sandboxIDs)
+	}
+	if err := q.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*SandboxActivity, len(rows))
+	for _, r := range rows {
+		out[r.SandboxID] = &SandboxActivity{
+			SandboxID:      r.SandboxID,
+			LastActivityAt: parseStoredTime(r.LastEnded),
+			CommandCount:   r.CommandCount,
+			FailedCount:    r.FailedCount,
+			TotalRuntimeMS: r.TotalRuntime,
+		}
+	}
+	return out, nil
+}
+
+// parseStoredTime parses a timestamp as SQLite returns it from an aggregate,
+// where the driver hands back the stored text rather than a time.Time.
+func parseStoredTime(v string) time.Time {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05.999999999-07:00",
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999",
+	} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// CreateSnapshot records a snapshot.
+func (s *Store) CreateSnapshot(ctx context.Context, snap *Snapshot) error {
+	return s.db.WithContext(ctx).Create(snap).Error
+}
+
+// GetSnapshot returns a sandbox's snapshot by ID or name. When several share
+// a name, the newest wins.
+func (s *Store) GetSnapshot(ctx context.Context, sandboxID, idOrName string) (*Snapshot, error) {
+	var snap Snapshot
+	err := s.db.WithContext(ctx).
+		Where("sandbox_id = ? AND (id = ? OR name = ?)", sandboxID, idOrName, idOrName).
+		Order("created_at DESC").
+		First(&snap).Error
+	if err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// ListSnapshots returns a sandbox's snapshots, oldest first, without their
+// manifests or package lists.
+func (s *Store) ListSnapshots(ctx context.Context, sandboxID string) ([]*Snapshot, error) {
+	var snaps []*Snapshot
+	if err := s.db.WithContext(ctx).Omit("manifest", "packages").Where("sandbox_id = ?", sandboxID).Order("created_at ASC").Find(&snaps).Error; err != nil {
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// DeleteSnapshot removes a snapshot record by ID.
+func (s *Store) DeleteSnapshot(ctx context.Context, id string) error {
+	return s.db.WithContext(ctx).Where("id = ?", id).Delete(&Snapshot{}).Error
+}
+
 func (s *Store) UpsertKafkaCaptureConfig(ctx context.Context, cfg *KafkaCaptureConfig) error {
 	return s.db.WithContext(ctx).Save(cfg).Error
 }
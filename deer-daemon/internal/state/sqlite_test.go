@@ -2,6 +2,8 @@ package state
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -163,6 +165,40 @@ func TestListSandboxes(t *testing.T) {
 	}
 }
 
+func TestGetSandboxes(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"SBX-get1", "SBX-get2", "SBX-get3"} {
+		if err := store.CreateSandbox(ctx, &Sandbox{ID: id, Name: strings.ToLower(id), State: "RUNNING"}); err != nil {
+			t.Fatalf("CreateSandbox(%s) failed: %v", id, err)
+		}
+	}
+	if err := store.DeleteSandbox(ctx, "SBX-get2"); err != nil {
+		t.Fatalf("DeleteSandbox failed: %v", err)
+	}
+
+	found, notFound, err := store.GetSandboxes(ctx, []string{"SBX-get3", "SBX-missing", "SBX-get2", "SBX-get1"})
+	if err != nil {
+		t.Fatalf("GetSandboxes failed: %v", err)
+	}
+	var got []string
+	for _, sb := range found {
+		got = append(got, sb.ID)
+	}
+	if strings.Join(got, ",") != "SBX-get3,SBX-get1" {
+		t.Errorf("found = %v, want [SBX-get3 SBX-get1]", got)
+	}
+	if strings.Join(notFound, ",") != "SBX-missing,SBX-get2" {
+		t.Errorf("notFound = %v, want [SBX-missing SBX-get2]", notFound)
+	}
+
+	found, notFound, err = store.GetSandboxes(ctx, nil)
+	if err != nil || len(found) != 0 || len(notFound) != 0 {
+		t.Errorf("GetSandboxes(nil) = %v, %v, %v; want empty", found, notFound, err)
+	}
+}
+
 func TestUpdateSandbox(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -245,6 +281,64 @@ func TestDeleteSandbox(t *testing.T) {
 	}
 }
 
+func TestCreateSandbox_NameInUse(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.CreateSandbox(ctx, &Sandbox{ID: "SBX-a", Name: "web", State: "RUNNING"}); err != nil {
+		t.Fatalf("CreateSandbox failed: %v", err)
+	}
+	err := store.CreateSandbox(ctx, &Sandbox{ID: "SBX-b", Name: "web", State: "RUNNING"})
+	if !errors.Is(err, ErrNameInUse) {
+		t.Fatalf("duplicate name err = %v, want ErrNameInUse", err)
+	}
+
+	// Unnamed sandboxes never collide with each other.
+	for _, id := range []string{"SBX-c", "SBX-d"} {
+		if err := store.CreateSandbox(ctx, &Sandbox{ID: id, State: "RUNNING"}); err != nil {
+			t.Fatalf("CreateSandbox(%s) unnamed failed: %v", id, err)
+		}
+	}
+
+	// The name is free again once the holder is destroyed.
+	if err := store.DeleteSandbox(ctx, "SBX-a"); err != nil {
+		t.Fatalf("DeleteSandbox failed: %v", err)
+	}
+	inUse, err := store.SandboxNameInUse(ctx, "web")
+	if err != nil {
+		t.Fatalf("SandboxNameInUse failed: %v", err)
+	}
+	if inUse {
+		t.Error("name should be free after its sandbox is destroyed")
+	}
+	if err := store.CreateSandbox(ctx, &Sandbox{ID: "SBX-e", Name: "web", State: "RUNNING"}); err != nil {
+		t.Fatalf("reusing a destroyed sandbox's name failed: %v", err)
+	}
+}
+
+func TestNewSandboxID(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		id, err := store.NewSandboxID(ctx, "sbx-")
+		if err != nil {
+			t.Fatalf("NewSandboxID failed: %v", err)
+		}
+		if !strings.HasPrefix(id, "sbx-") {
+			t.Fatalf("id %q missing prefix", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %q", id)
+		}
+		seen[id] = true
+		if err := store.CreateSandbox(ctx, &Sandbox{ID: id, State: "RUNNING"}); err != nil {
+			t.Fatalf("CreateSandbox(%s) failed: %v", id, err)
+		}
+	}
+}
+
 func TestListExpiredSandboxes(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -286,7 +380,16 @@ func TestListExpiredSandboxes(t *testing.T) {
 		CreatedAt: now.Add(-10 * time.Minute),
 	}
 
-	for _, sb := range []*Sandbox{sb1, sb2, sb3, sb4} {
+	// Sandbox whose create is still in flight - should not appear.
+	sb5 := &Sandbox{
+		ID:         "SBX-creating",
+		Name:       "creating",
+		State:      "CREATING",
+		TTLSeconds: 60,
+		CreatedAt:  now.Add(-10 * time.Minute),
+	}
+
+	for _, sb := range []*Sandbox{sb1, sb2, sb3, sb4, sb5} {
 		if err := store.CreateSandbox(ctx, sb); err != nil {
 			t.Fatalf("CreateSandbox(%s) failed: %v", sb.ID, err)
 		}
@@ -315,6 +418,9 @@ func TestListExpiredSandboxes(t *testing.T) {
 	if ids["SBX-destroyed"] {
 		t.Error("SBX-destroyed should not appear (state=DESTROYED)")
 	}
+	if ids["SBX-creating"] {
+		t.Error("SBX-creating should not appear (state=CREATING)")
+	}
 
 	if len(expired) != 2 {
 		t.Errorf("expected 2 expired sandboxes, got %d", len(expired))
@@ -348,6 +454,29 @@ func TestListExpiredSandboxes_NoExpired(t *testing.T) {
 	}
 }
 
+func TestListExpiredSandboxes_ImportedIgnoresDefaultTTL(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	old := time.Now().UTC().Add(-2 * time.Hour)
+	for _, sb := range []*Sandbox{
+		{ID: "SBX-imp", Name: "legacy-web", State: "RUNNING", Imported: true, CreatedAt: old},
+		{ID: "SBX-imp-ttl", Name: "legacy-db", State: "RUNNING", Imported: true, TTLSeconds: 60, CreatedAt: old},
+	} {
+		if err := store.CreateSandbox(ctx, sb); err != nil {
+			t.Fatalf("CreateSandbox failed: %v", err)
+		}
+	}
+
+	expired, err := store.ListExpiredSandboxes(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("ListExpiredSandboxes failed: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != "SBX-imp-ttl" {
+		t.Errorf("expected only SBX-imp-ttl to expire, got %v", expired)
+	}
+}
+
 func TestCreateCommand_ListSandboxCommands(t *testing.T) {
 	store := newTestStore(t)
 	ctx := context.Background()
@@ -441,3 +570,195 @@ func TestCreateCommand_ListSandboxCommands(t *testing.T) {
 		t.Errorf("expected 0 commands for nonexistent sandbox, got %d", len(empty))
 	}
 }
+
+func TestAcquireSandboxLock(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.CreateSandbox(ctx, &Sandbox{ID: "SBX-lock", Name: "lock", State: "RUNNING"}); err != nil {
+		t.Fatalf("CreateSandbox failed: %v", err)
+	}
+
+	if err := store.AcquireSandboxLock(ctx, "SBX-lock", "alice", time.Minute); err != nil {
+		t.Fatalf("first acquire failed: %v", err)
+	}
+	// Same owner may re-acquire (refresh).
+	if err := store.AcquireSandboxLock(ctx, "SBX-lock", "alice", time.Minute); err != nil {
+		t.Fatalf("re-acquire by same owner failed: %v", err)
+	}
+
+	err := store.AcquireSandboxLock(ctx, "SBX-lock", "bob", time.Minute)
+	if !IsLocked(err) {
+		t.Fatalf("expected LockedError for second owner, got %v", err)
+	}
+	if lockErr := err.(*LockedError); lockErr.Holder != "alice" {
+		t.Errorf("Holder = %q, want %q", lockErr.Holder, "alice")
+	}
+
+	// Releasing someone else's lock is a no-op.
+	if err := store.ReleaseSandboxLock(ctx, "SBX-lock", "bob"); err != nil {
+		t.Fatalf("ReleaseSandboxLock failed: %v", err)
+	}
+	if err := store.AcquireSandboxLock(ctx, "SBX-lock", "bob", time.Minute); !IsLocked(err) {
+		t.Fatalf("expected lock to still be held by alice, got %v", err)
+	}
+
+	if err := store.ReleaseSandboxLock(ctx, "SBX-lock", "alice"); err != nil {
+		t.Fatalf("ReleaseSandboxLock failed: %v", err)
+	}
+	if err := store.AcquireSandboxLock(ctx, "SBX-lock", "bob", time.Minute); err != nil {
+		t.Fatalf("acquire after release failed: %v", err)
+	}
+}
+
+func TestAcquireSandboxLock_Expired(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.CreateSandbox(ctx, &Sandbox{ID: "SBX-exp", Name: "exp", State: "RUNNING"}); err != nil {
+		t.Fatalf("CreateSandbox failed: %v", err)
+	}
+	if err := store.AcquireSandboxLock(ctx, "SBX-exp", "alice", -time.Second); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+	if err := store.AcquireSandboxLock(ctx, "SBX-exp", "bob", time.Minute); err != nil {
+		t.Fatalf("expected expired lock to be taken over, got %v", err)
+	}
+
+	sb, err := store.GetSandbox(ctx, "SBX-exp")
+	if err != nil {
+		t.Fatalf("GetSandbox failed: %v", err)
+	}
+	if sb.LockedBy != "bob" {
+		t.Errorf("LockedBy = %q, want %q", sb.LockedBy, "bob")
+	}
+}
+
+func TestAcquireSandboxLock_NotFound(t *testing.T) {
+	store := newTestStore(t)
+
+	err := store.AcquireSandboxLock(context.Background(), "SBX-missing", "alice", time.Minute)
+	if err == nil || IsLocked(err) {
+		t.Fatalf("expected not-found error, got %v", err)
+	}
+}
+
+func TestUpdateSandbox_KeepsLock(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.CreateSandbox(ctx, &Sandbox{ID: "SBX-stale", Name: "stale", State: "RUNNING"}); err != nil {
+		t.Fatalf("CreateSandbox failed: %v", err)
+	}
+	stale, err := store.GetSandbox(ctx, "SBX-stale")
+	if err != nil {
+		t.Fatalf("GetSandbox failed: %v", err)
+	}
+	if err := store.AcquireSandboxLock(ctx, "SBX-stale", "alice", time.Minute); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	// Saving the copy read before the lock must not release it.
+	stale.State = "STOPPED"
+	if err := store.UpdateSandbox(ctx, stale); err != nil {
+		t.Fatalf("UpdateSandbox failed: %v", err)
+	}
+	got, err := store.GetSandbox(ctx, "SBX-stale")
+	if err != nil {
+		t.Fatalf("GetSandbox failed: %v", err)
+	}
+	if got.State != "STOPPED" || got.LockedBy != "alice" || got.LockExpires == nil {
+		t.Errorf("state = %q, locked_by = %q, lock_expires = %v; want STOPPED held by alice", got.State, got.LockedBy, got.LockExpires)
+	}
+
+	// Nor may a copy read while locked restore the lock after release.
+	if err := store.ReleaseSandboxLock(ctx, "SBX-stale", "alice"); err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if err := store.UpdateSandbox(ctx, got); err != nil {
+		t.Fatalf("UpdateSandbox failed: %v", err)
+	}
+	if err := store.AcquireSandboxLock(ctx, "SBX-stale", "bob", time.Minute); err != nil {
+		t.Errorf("acquire after release: %v", err)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	base := time.Now().UTC()
+
+	for i, snap := range []*Snapshot{
+		{ID: "SNP-1", SandboxID: "SBX-1", Name: "before", Manifest: "m1", Packages: "p1", CreatedAt: base},
+		{ID: "SNP-2", SandboxID: "SBX-1", Name: "after", Manifest: "m2", CreatedAt: base.Add(time.Minute)},
+		{ID: "SNP-3", SandboxID: "SBX-2", Name: "before", Manifest: "m3", CreatedAt: base},
+	} {
+		if err := store.CreateSnapshot(ctx, snap); err != nil {
+			t.Fatalf("CreateSnapshot(%d): %v", i, err)
+		}
+	}
+
+	byName, err := store.GetSnapshot(ctx, "SBX-1", "before")
+	if err != nil {
+		t.Fatalf("GetSnapshot by name: %v", err)
+	}
+	if byName.ID != "SNP-1" || byName.Manifest != "m1" || byName.Packages != "p1" {
+		t.Errorf("GetSnapshot(before) = %+v, want SNP-1 with its manifest", byName)
+	}
+	if _, err := store.GetSnapshot(ctx, "SBX-1", "SNP-2"); err != nil {
+		t.Errorf("GetSnapshot by ID: %v", err)
+	}
+	if _, err := store.GetSnapshot(ctx, "SBX-2", "SNP-2"); err == nil {
+		t.Error("GetSnapshot found another sandbox's snapshot")
+	}
+
+	list, err := store.ListSnapshots(ctx, "SBX-1")
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "SNP-1" || list[1].ID != "SNP-2" {
+		t.Fatalf("ListSnapshots = %v, want SNP-1, SNP-2", list)
+	}
+	if list[0].Manifest != "" || list[0].Packages != "" {
+		t.Error("ListSnapshots should not load manifests or package lists")
+	}
+}
+
+func TestListSandboxActivity(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, cmd := range []*Command{
+		{ID: "C1", SandboxID: "SBX-1", ExitCode: 0, DurationMS: 100, StartedAt: base, EndedAt: base.Add(time.Second)},
+		{ID: "C2", SandboxID: "SBX-1", ExitCode: 2, DurationMS: 250, StartedAt: base.Add(time.Minute), EndedAt: base.Add(2 * time.Minute)},
+		{ID: "C3", SandboxID: "SBX-2", ExitCode: 0, DurationMS: 5, StartedAt: base, EndedAt: base.Add(time.Second)},
+	} {
+		if err := store.CreateCommand(ctx, cmd); err != nil {
+			t.Fatalf("CreateCommand(%s): %v", cmd.ID, err)
+		}
+	}
+
+	all, err := store.ListSandboxActivity(ctx)
+	if err != nil {
+		t.Fatalf("ListSandboxActivity: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("got %d sandboxes, want 2", len(all))
+	}
+	a := all["SBX-1"]
+	if a.CommandCount != 2 || a.FailedCount != 1 || a.TotalRuntimeMS != 350 {
+		t.Errorf("SBX-1 activity = %+v", a)
+	}
+	if !a.LastActivityAt.Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("LastActivityAt = %v, want %v", a.LastActivityAt, base.Add(2*time.Minute))
+	}
+
+	one, err := store.ListSandboxActivity(ctx, "SBX-2", "SBX-none")
+	if err != nil {
+		t.Fatalf("ListSandboxActivity(ids): %v", err)
+	}
+	if len(one) != 1 || one["SBX-2"].CommandCount != 1 {
+		t.Errorf("filtered activity = %v", one)
+	}
+}
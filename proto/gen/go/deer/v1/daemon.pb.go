@@ -66,6 +66,105 @@ func (x *GetSandboxRequest) GetSandboxId() string {
 	return ""
 }
 
+// GetSandboxesRequest requests details for several sandboxes at once.
+type GetSandboxesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxIds    []string               `protobuf:"bytes,1,rep,name=sandbox_ids,json=sandboxIds,proto3" json:"sandbox_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSandboxesRequest) Reset() {
+	*x = GetSandboxesRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSandboxesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSandboxesRequest) ProtoMessage() {}
+
+func (x *GetSandboxesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSandboxesRequest.ProtoReflect.Descriptor instead.
+func (*GetSandboxesRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSandboxesRequest) GetSandboxIds() []string {
+	if x != nil {
+		return x.SandboxIds
+	}
+	return nil
+}
+
+// GetSandboxesResponse contains the requested sandboxes that exist, in
+// request order, and the IDs that matched no sandbox.
+type GetSandboxesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sandboxes     []*SandboxInfo         `protobuf:"bytes,1,rep,name=sandboxes,proto3" json:"sandboxes,omitempty"`
+	NotFound      []string               `protobuf:"bytes,2,rep,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSandboxesResponse) Reset() {
+	*x = GetSandboxesResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSandboxesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSandboxesResponse) ProtoMessage() {}
+
+func (x *GetSandboxesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSandboxesResponse.ProtoReflect.Descriptor instead.
+func (*GetSandboxesResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetSandboxesResponse) GetSandboxes() []*SandboxInfo {
+	if x != nil {
+		return x.Sandboxes
+	}
+	return nil
+}
+
+func (x *GetSandboxesResponse) GetNotFound() []string {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
 // SandboxInfo contains full details about a sandbox.
 type SandboxInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -78,13 +177,20 @@ type SandboxInfo struct {
 	Vcpus         int32                  `protobuf:"varint,7,opt,name=vcpus,proto3" json:"vcpus,omitempty"`
 	MemoryMb      int32                  `protobuf:"varint,8,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
 	CreatedAt     string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Imported      bool                   `protobuf:"varint,10,opt,name=imported,proto3" json:"imported,omitempty"` // registered from an existing VM rather than cloned
+	Activity      *SandboxActivity       `protobuf:"bytes,11,opt,name=activity,proto3" json:"activity,omitempty"`  // unset when no command has run
+	Disk          *SandboxDiskUsage      `protobuf:"bytes,12,opt,name=disk,proto3" json:"disk,omitempty"`          // unset until the root filesystem has been measured
+	MacAddress    string                 `protobuf:"bytes,13,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
+	HostId        string                 `protobuf:"bytes,14,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`               // daemon host the sandbox runs on
+	IdleStopAt    string                 `protobuf:"bytes,15,opt,name=idle_stop_at,json=idleStopAt,proto3" json:"idle_stop_at,omitempty"` // RFC3339; when the janitor will stop it if it stays idle, unset when idle stops are off
+	Workdir       string                 `protobuf:"bytes,16,opt,name=workdir,proto3" json:"workdir,omitempty"`                           // default directory commands run in; empty for the login directory
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SandboxInfo) Reset() {
 	*x = SandboxInfo{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[1]
+	mi := &file_deer_v1_daemon_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -96,7 +202,7 @@ func (x *SandboxInfo) String() string {
 func (*SandboxInfo) ProtoMessage() {}
 
 func (x *SandboxInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[1]
+	mi := &file_deer_v1_daemon_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -109,7 +215,7 @@ func (x *SandboxInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SandboxInfo.ProtoReflect.Descriptor instead.
 func (*SandboxInfo) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{1}
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *SandboxInfo) GetSandboxId() string {
@@ -175,67 +281,82 @@ func (x *SandboxInfo) GetCreatedAt() string {
 	return ""
 }
 
-// ListSandboxesRequest requests all sandboxes.
-type ListSandboxesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *SandboxInfo) GetImported() bool {
+	if x != nil {
+		return x.Imported
+	}
+	return false
 }
 
-func (x *ListSandboxesRequest) Reset() {
-	*x = ListSandboxesRequest{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[2]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *SandboxInfo) GetActivity() *SandboxActivity {
+	if x != nil {
+		return x.Activity
+	}
+	return nil
 }
 
-func (x *ListSandboxesRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *SandboxInfo) GetDisk() *SandboxDiskUsage {
+	if x != nil {
+		return x.Disk
+	}
+	return nil
 }
 
-func (*ListSandboxesRequest) ProtoMessage() {}
+func (x *SandboxInfo) GetMacAddress() string {
+	if x != nil {
+		return x.MacAddress
+	}
+	return ""
+}
 
-func (x *ListSandboxesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[2]
+func (x *SandboxInfo) GetHostId() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.HostId
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use ListSandboxesRequest.ProtoReflect.Descriptor instead.
-func (*ListSandboxesRequest) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{2}
+func (x *SandboxInfo) GetIdleStopAt() string {
+	if x != nil {
+		return x.IdleStopAt
+	}
+	return ""
 }
 
-// ListSandboxesResponse contains a list of sandboxes.
-type ListSandboxesResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Sandboxes     []*SandboxInfo         `protobuf:"bytes,1,rep,name=sandboxes,proto3" json:"sandboxes,omitempty"`
-	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *SandboxInfo) GetWorkdir() string {
+	if x != nil {
+		return x.Workdir
+	}
+	return ""
 }
 
-func (x *ListSandboxesResponse) Reset() {
-	*x = ListSandboxesResponse{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[3]
+// SandboxDiskUsage is the usage of a sandbox's root filesystem when it was
+// last measured.
+type SandboxDiskUsage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalBytes     int64                  `protobuf:"varint,1,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"`
+	UsedBytes      int64                  `protobuf:"varint,2,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`
+	AvailableBytes int64                  `protobuf:"varint,3,opt,name=available_bytes,json=availableBytes,proto3" json:"available_bytes,omitempty"`
+	CheckedAt      string                 `protobuf:"bytes,4,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"` // RFC3339
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SandboxDiskUsage) Reset() {
+	*x = SandboxDiskUsage{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListSandboxesResponse) String() string {
+func (x *SandboxDiskUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListSandboxesResponse) ProtoMessage() {}
+func (*SandboxDiskUsage) ProtoMessage() {}
 
-func (x *ListSandboxesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[3]
+func (x *SandboxDiskUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -246,93 +367,64 @@ func (x *ListSandboxesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListSandboxesResponse.ProtoReflect.Descriptor instead.
-func (*ListSandboxesResponse) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use SandboxDiskUsage.ProtoReflect.Descriptor instead.
+func (*SandboxDiskUsage) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ListSandboxesResponse) GetSandboxes() []*SandboxInfo {
+func (x *SandboxDiskUsage) GetTotalBytes() int64 {
 	if x != nil {
-		return x.Sandboxes
+		return x.TotalBytes
 	}
-	return nil
+	return 0
 }
 
-func (x *ListSandboxesResponse) GetCount() int32 {
+func (x *SandboxDiskUsage) GetUsedBytes() int64 {
 	if x != nil {
-		return x.Count
+		return x.UsedBytes
 	}
 	return 0
 }
 
-// GetHostInfoRequest requests host information.
-type GetHostInfoRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *GetHostInfoRequest) Reset() {
-	*x = GetHostInfoRequest{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *GetHostInfoRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*GetHostInfoRequest) ProtoMessage() {}
-
-func (x *GetHostInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[4]
+func (x *SandboxDiskUsage) GetAvailableBytes() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.AvailableBytes
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use GetHostInfoRequest.ProtoReflect.Descriptor instead.
-func (*GetHostInfoRequest) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{4}
+func (x *SandboxDiskUsage) GetCheckedAt() string {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return ""
 }
 
-// HostInfoResponse contains host resource and capability information.
-type HostInfoResponse struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	HostId            string                 `protobuf:"bytes,1,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
-	Hostname          string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Version           string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
-	TotalCpus         int32                  `protobuf:"varint,4,opt,name=total_cpus,json=totalCpus,proto3" json:"total_cpus,omitempty"`
-	TotalMemoryMb     int64                  `protobuf:"varint,5,opt,name=total_memory_mb,json=totalMemoryMb,proto3" json:"total_memory_mb,omitempty"`
-	ActiveSandboxes   int32                  `protobuf:"varint,6,opt,name=active_sandboxes,json=activeSandboxes,proto3" json:"active_sandboxes,omitempty"`
-	BaseImages        []string               `protobuf:"bytes,7,rep,name=base_images,json=baseImages,proto3" json:"base_images,omitempty"`
-	SshCaPubKey       string                 `protobuf:"bytes,8,opt,name=ssh_ca_pub_key,json=sshCaPubKey,proto3" json:"ssh_ca_pub_key,omitempty"`
-	SshIdentityPubKey string                 `protobuf:"bytes,9,opt,name=ssh_identity_pub_key,json=sshIdentityPubKey,proto3" json:"ssh_identity_pub_key,omitempty"`
-	SourceHosts       []*SourceHostInfo      `protobuf:"bytes,10,rep,name=source_hosts,json=sourceHosts,proto3" json:"source_hosts,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+// SandboxActivity summarizes a sandbox's command history.
+type SandboxActivity struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	LastActivityAt string                 `protobuf:"bytes,1,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"` // RFC3339 end of the latest command
+	CommandCount   int32                  `protobuf:"varint,2,opt,name=command_count,json=commandCount,proto3" json:"command_count,omitempty"`
+	FailedCommands int32                  `protobuf:"varint,3,opt,name=failed_commands,json=failedCommands,proto3" json:"failed_commands,omitempty"`
+	TotalRuntimeMs int64                  `protobuf:"varint,4,opt,name=total_runtime_ms,json=totalRuntimeMs,proto3" json:"total_runtime_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *HostInfoResponse) Reset() {
-	*x = HostInfoResponse{}
+func (x *SandboxActivity) Reset() {
+	*x = SandboxActivity{}
 	mi := &file_deer_v1_daemon_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HostInfoResponse) String() string {
+func (x *SandboxActivity) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostInfoResponse) ProtoMessage() {}
+func (*SandboxActivity) ProtoMessage() {}
 
-func (x *HostInfoResponse) ProtoReflect() protoreflect.Message {
+func (x *SandboxActivity) ProtoReflect() protoreflect.Message {
 	mi := &file_deer_v1_daemon_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -344,108 +436,131 @@ func (x *HostInfoResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostInfoResponse.ProtoReflect.Descriptor instead.
-func (*HostInfoResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SandboxActivity.ProtoReflect.Descriptor instead.
+func (*SandboxActivity) Descriptor() ([]byte, []int) {
 	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *HostInfoResponse) GetHostId() string {
+func (x *SandboxActivity) GetLastActivityAt() string {
 	if x != nil {
-		return x.HostId
+		return x.LastActivityAt
 	}
 	return ""
 }
 
-func (x *HostInfoResponse) GetHostname() string {
+func (x *SandboxActivity) GetCommandCount() int32 {
 	if x != nil {
-		return x.Hostname
+		return x.CommandCount
 	}
-	return ""
+	return 0
 }
 
-func (x *HostInfoResponse) GetVersion() string {
+func (x *SandboxActivity) GetFailedCommands() int32 {
 	if x != nil {
-		return x.Version
+		return x.FailedCommands
 	}
-	return ""
+	return 0
 }
 
-func (x *HostInfoResponse) GetTotalCpus() int32 {
+func (x *SandboxActivity) GetTotalRuntimeMs() int64 {
 	if x != nil {
-		return x.TotalCpus
+		return x.TotalRuntimeMs
 	}
 	return 0
 }
 
-func (x *HostInfoResponse) GetTotalMemoryMb() int64 {
-	if x != nil {
-		return x.TotalMemoryMb
-	}
-	return 0
+// ImportSandboxCommand registers an existing VM/CT as a managed sandbox
+// without cloning it.
+type ImportSandboxCommand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VmName        string                 `protobuf:"bytes,1,opt,name=vm_name,json=vmName,proto3" json:"vm_name,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"` // display name (defaults to vm_name)
+	AgentId       string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HostInfoResponse) GetActiveSandboxes() int32 {
-	if x != nil {
-		return x.ActiveSandboxes
-	}
-	return 0
+func (x *ImportSandboxCommand) Reset() {
+	*x = ImportSandboxCommand{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *HostInfoResponse) GetBaseImages() []string {
+func (x *ImportSandboxCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportSandboxCommand) ProtoMessage() {}
+
+func (x *ImportSandboxCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[6]
 	if x != nil {
-		return x.BaseImages
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *HostInfoResponse) GetSshCaPubKey() string {
+// Deprecated: Use ImportSandboxCommand.ProtoReflect.Descriptor instead.
+func (*ImportSandboxCommand) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ImportSandboxCommand) GetVmName() string {
 	if x != nil {
-		return x.SshCaPubKey
+		return x.VmName
 	}
 	return ""
 }
 
-func (x *HostInfoResponse) GetSshIdentityPubKey() string {
+func (x *ImportSandboxCommand) GetName() string {
 	if x != nil {
-		return x.SshIdentityPubKey
+		return x.Name
 	}
 	return ""
 }
 
-func (x *HostInfoResponse) GetSourceHosts() []*SourceHostInfo {
+func (x *ImportSandboxCommand) GetAgentId() string {
 	if x != nil {
-		return x.SourceHosts
+		return x.AgentId
 	}
-	return nil
+	return ""
 }
 
-// SourceHostInfo describes a source host the daemon is configured to use.
-// Returned in HostInfoResponse so the CLI can deploy the daemon's identity
-// key to these hosts during setup.
-type SourceHostInfo struct {
+// ForkSandboxCommand creates a new sandbox from one of an existing
+// sandbox's snapshots. Unset resources default to the parent's.
+type ForkSandboxCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
-	SshUser       string                 `protobuf:"bytes,2,opt,name=ssh_user,json=sshUser,proto3" json:"ssh_user,omitempty"`
-	SshPort       int32                  `protobuf:"varint,3,opt,name=ssh_port,json=sshPort,proto3" json:"ssh_port,omitempty"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Snapshot      string                 `protobuf:"bytes,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"` // snapshot name or ID
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Vcpus         int32                  `protobuf:"varint,4,opt,name=vcpus,proto3" json:"vcpus,omitempty"`
+	MemoryMb      int32                  `protobuf:"varint,5,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	AgentId       string                 `protobuf:"bytes,6,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	SshPublicKey  string                 `protobuf:"bytes,7,opt,name=ssh_public_key,json=sshPublicKey,proto3" json:"ssh_public_key,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SourceHostInfo) Reset() {
-	*x = SourceHostInfo{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[6]
+func (x *ForkSandboxCommand) Reset() {
+	*x = ForkSandboxCommand{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SourceHostInfo) String() string {
+func (x *ForkSandboxCommand) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SourceHostInfo) ProtoMessage() {}
+func (*ForkSandboxCommand) ProtoMessage() {}
 
-func (x *SourceHostInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[6]
+func (x *ForkSandboxCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -456,54 +571,101 @@ func (x *SourceHostInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SourceHostInfo.ProtoReflect.Descriptor instead.
-func (*SourceHostInfo) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use ForkSandboxCommand.ProtoReflect.Descriptor instead.
+func (*ForkSandboxCommand) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *SourceHostInfo) GetAddress() string {
+func (x *ForkSandboxCommand) GetSandboxId() string {
 	if x != nil {
-		return x.Address
+		return x.SandboxId
 	}
 	return ""
 }
 
-func (x *SourceHostInfo) GetSshUser() string {
+func (x *ForkSandboxCommand) GetSnapshot() string {
 	if x != nil {
-		return x.SshUser
+		return x.Snapshot
 	}
 	return ""
 }
 
-func (x *SourceHostInfo) GetSshPort() int32 {
+func (x *ForkSandboxCommand) GetName() string {
 	if x != nil {
-		return x.SshPort
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ForkSandboxCommand) GetVcpus() int32 {
+	if x != nil {
+		return x.Vcpus
 	}
 	return 0
 }
 
-// HealthRequest is an empty health check request.
-type HealthRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ForkSandboxCommand) GetMemoryMb() int32 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[7]
+func (x *ForkSandboxCommand) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ForkSandboxCommand) GetSshPublicKey() string {
+	if x != nil {
+		return x.SshPublicKey
+	}
+	return ""
+}
+
+// SandboxPlan is what CreateSandbox would do for the same command, worked
+// out without creating anything. Warnings list problems the create would
+// likely hit, such as asking for more memory than the host has free.
+type SandboxPlan struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Name      string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	HostId    string                 `protobuf:"bytes,3,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Hostname  string                 `protobuf:"bytes,4,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	SourceVm  string                 `protobuf:"bytes,5,opt,name=source_vm,json=sourceVm,proto3" json:"source_vm,omitempty"`
+	// source_host is the host the source VM was found on; empty when the
+	// base image is used directly.
+	SourceHost        string   `protobuf:"bytes,6,opt,name=source_host,json=sourceHost,proto3" json:"source_host,omitempty"`
+	SourceHostType    string   `protobuf:"bytes,7,opt,name=source_host_type,json=sourceHostType,proto3" json:"source_host_type,omitempty"`
+	Vcpus             int32    `protobuf:"varint,8,opt,name=vcpus,proto3" json:"vcpus,omitempty"`
+	MemoryMb          int32    `protobuf:"varint,9,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	AvailableCpus     int32    `protobuf:"varint,10,opt,name=available_cpus,json=availableCpus,proto3" json:"available_cpus,omitempty"`
+	AvailableMemoryMb int64    `protobuf:"varint,11,opt,name=available_memory_mb,json=availableMemoryMb,proto3" json:"available_memory_mb,omitempty"`
+	Warnings          []string `protobuf:"bytes,12,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// requires_approval is set when a pre-create hook decides whether the
+	// sandbox may be created.
+	RequiresApproval bool `protobuf:"varint,13,opt,name=requires_approval,json=requiresApproval,proto3" json:"requires_approval,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SandboxPlan) Reset() {
+	*x = SandboxPlan{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthRequest) String() string {
+func (x *SandboxPlan) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (*SandboxPlan) ProtoMessage() {}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[7]
+func (x *SandboxPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -514,34 +676,130 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use SandboxPlan.ProtoReflect.Descriptor instead.
+func (*SandboxPlan) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{8}
 }
 
-// HealthResponse indicates daemon health status.
-type HealthResponse struct {
+func (x *SandboxPlan) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SandboxPlan) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SandboxPlan) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *SandboxPlan) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *SandboxPlan) GetSourceVm() string {
+	if x != nil {
+		return x.SourceVm
+	}
+	return ""
+}
+
+func (x *SandboxPlan) GetSourceHost() string {
+	if x != nil {
+		return x.SourceHost
+	}
+	return ""
+}
+
+func (x *SandboxPlan) GetSourceHostType() string {
+	if x != nil {
+		return x.SourceHostType
+	}
+	return ""
+}
+
+func (x *SandboxPlan) GetVcpus() int32 {
+	if x != nil {
+		return x.Vcpus
+	}
+	return 0
+}
+
+func (x *SandboxPlan) GetMemoryMb() int32 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
+}
+
+func (x *SandboxPlan) GetAvailableCpus() int32 {
+	if x != nil {
+		return x.AvailableCpus
+	}
+	return 0
+}
+
+func (x *SandboxPlan) GetAvailableMemoryMb() int64 {
+	if x != nil {
+		return x.AvailableMemoryMb
+	}
+	return 0
+}
+
+func (x *SandboxPlan) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *SandboxPlan) GetRequiresApproval() bool {
+	if x != nil {
+		return x.RequiresApproval
+	}
+	return false
+}
+
+// ResizeSandboxCommand changes a sandbox's vCPUs and memory. Unset
+// resources are left as they are. A running sandbox is stopped for the
+// change and, unless leave_stopped is set, started again.
+type ResizeSandboxCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Vcpus         int32                  `protobuf:"varint,2,opt,name=vcpus,proto3" json:"vcpus,omitempty"`
+	MemoryMb      int32                  `protobuf:"varint,3,opt,name=memory_mb,json=memoryMb,proto3" json:"memory_mb,omitempty"`
+	LeaveStopped  bool                   `protobuf:"varint,4,opt,name=leave_stopped,json=leaveStopped,proto3" json:"leave_stopped,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[8]
+func (x *ResizeSandboxCommand) Reset() {
+	*x = ResizeSandboxCommand{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthResponse) String() string {
+func (x *ResizeSandboxCommand) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthResponse) ProtoMessage() {}
+func (*ResizeSandboxCommand) ProtoMessage() {}
 
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[8]
+func (x *ResizeSandboxCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -552,42 +810,65 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ResizeSandboxCommand.ProtoReflect.Descriptor instead.
+func (*ResizeSandboxCommand) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *HealthResponse) GetStatus() string {
+func (x *ResizeSandboxCommand) GetSandboxId() string {
 	if x != nil {
-		return x.Status
+		return x.SandboxId
 	}
 	return ""
 }
 
-// DiscoverHostsCommand requests the daemon to parse SSH config and probe hosts.
-type DiscoverHostsCommand struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// ssh_config_content is the raw SSH config text to parse.
-	SshConfigContent string `protobuf:"bytes,1,opt,name=ssh_config_content,json=sshConfigContent,proto3" json:"ssh_config_content,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+func (x *ResizeSandboxCommand) GetVcpus() int32 {
+	if x != nil {
+		return x.Vcpus
+	}
+	return 0
 }
 
-func (x *DiscoverHostsCommand) Reset() {
-	*x = DiscoverHostsCommand{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[9]
+func (x *ResizeSandboxCommand) GetMemoryMb() int32 {
+	if x != nil {
+		return x.MemoryMb
+	}
+	return 0
+}
+
+func (x *ResizeSandboxCommand) GetLeaveStopped() bool {
+	if x != nil {
+		return x.LeaveStopped
+	}
+	return false
+}
+
+// SetSandboxWorkdirRequest sets the directory a sandbox's commands run in
+// when they do not name one. An empty workdir goes back to the login
+// directory.
+type SetSandboxWorkdirRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Workdir       string                 `protobuf:"bytes,2,opt,name=workdir,proto3" json:"workdir,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSandboxWorkdirRequest) Reset() {
+	*x = SetSandboxWorkdirRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiscoverHostsCommand) String() string {
+func (x *SetSandboxWorkdirRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiscoverHostsCommand) ProtoMessage() {}
+func (*SetSandboxWorkdirRequest) ProtoMessage() {}
 
-func (x *DiscoverHostsCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[9]
+func (x *SetSandboxWorkdirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -598,50 +879,50 @@ func (x *DiscoverHostsCommand) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiscoverHostsCommand.ProtoReflect.Descriptor instead.
-func (*DiscoverHostsCommand) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use SetSandboxWorkdirRequest.ProtoReflect.Descriptor instead.
+func (*SetSandboxWorkdirRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *DiscoverHostsCommand) GetSshConfigContent() string {
+func (x *SetSandboxWorkdirRequest) GetSandboxId() string {
 	if x != nil {
-		return x.SshConfigContent
+		return x.SandboxId
 	}
 	return ""
 }
 
-// DiscoveredHost describes a single probed host.
-type DiscoveredHost struct {
+func (x *SetSandboxWorkdirRequest) GetWorkdir() string {
+	if x != nil {
+		return x.Workdir
+	}
+	return ""
+}
+
+// UnlockSandboxRequest clears a sandbox's advisory lock whoever holds it,
+// for when the holder died and the operator does not want to wait for the
+// lock to expire.
+type UnlockSandboxRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Hostname      string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	User          string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
-	Port          int32                  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
-	IdentityFile  string                 `protobuf:"bytes,5,opt,name=identity_file,json=identityFile,proto3" json:"identity_file,omitempty"`
-	Reachable     bool                   `protobuf:"varint,6,opt,name=reachable,proto3" json:"reachable,omitempty"`
-	HasLibvirt    bool                   `protobuf:"varint,7,opt,name=has_libvirt,json=hasLibvirt,proto3" json:"has_libvirt,omitempty"`
-	HasProxmox    bool                   `protobuf:"varint,8,opt,name=has_proxmox,json=hasProxmox,proto3" json:"has_proxmox,omitempty"`
-	Vms           []string               `protobuf:"bytes,9,rep,name=vms,proto3" json:"vms,omitempty"`
-	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiscoveredHost) Reset() {
-	*x = DiscoveredHost{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[10]
+func (x *UnlockSandboxRequest) Reset() {
+	*x = UnlockSandboxRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiscoveredHost) String() string {
+func (x *UnlockSandboxRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiscoveredHost) ProtoMessage() {}
+func (*UnlockSandboxRequest) ProtoMessage() {}
 
-func (x *DiscoveredHost) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[10]
+func (x *UnlockSandboxRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -652,104 +933,2812 @@ func (x *DiscoveredHost) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiscoveredHost.ProtoReflect.Descriptor instead.
-func (*DiscoveredHost) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use UnlockSandboxRequest.ProtoReflect.Descriptor instead.
+func (*UnlockSandboxRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *DiscoveredHost) GetName() string {
+func (x *UnlockSandboxRequest) GetSandboxId() string {
 	if x != nil {
-		return x.Name
+		return x.SandboxId
 	}
 	return ""
 }
 
-func (x *DiscoveredHost) GetHostname() string {
-	if x != nil {
-		return x.Hostname
-	}
-	return ""
+// SandboxUnlocked names the holder whose lock was cleared. locked_by is
+// empty when the sandbox was not locked.
+type SandboxUnlocked struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	LockedBy      string                 `protobuf:"bytes,2,opt,name=locked_by,json=lockedBy,proto3" json:"locked_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SandboxUnlocked) Reset() {
+	*x = SandboxUnlocked{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxUnlocked) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxUnlocked) ProtoMessage() {}
+
+func (x *SandboxUnlocked) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxUnlocked.ProtoReflect.Descriptor instead.
+func (*SandboxUnlocked) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SandboxUnlocked) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SandboxUnlocked) GetLockedBy() string {
+	if x != nil {
+		return x.LockedBy
+	}
+	return ""
+}
+
+// GetSandboxStatsRequest asks what a sandbox is using right now.
+type GetSandboxStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSandboxStatsRequest) Reset() {
+	*x = GetSandboxStatsRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSandboxStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSandboxStatsRequest) ProtoMessage() {}
+
+func (x *GetSandboxStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSandboxStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetSandboxStatsRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetSandboxStatsRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+// SandboxStats is a sandbox's resource usage when it was sampled, as opposed
+// to the shape it was created with. Fields the provider cannot measure are
+// zero, and CPU and memory are zero while the sandbox is stopped.
+type SandboxStats struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId          string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	State              string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	SampledAt          string                 `protobuf:"bytes,3,opt,name=sampled_at,json=sampledAt,proto3" json:"sampled_at,omitempty"` // RFC3339Nano
+	Vcpus              int32                  `protobuf:"varint,4,opt,name=vcpus,proto3" json:"vcpus,omitempty"`
+	CpuTimeMs          int64                  `protobuf:"varint,5,opt,name=cpu_time_ms,json=cpuTimeMs,proto3" json:"cpu_time_ms,omitempty"`   // cumulative across all vCPUs
+	CpuPercent         float64                `protobuf:"fixed64,6,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"` // of one vCPU, when the provider samples it itself
+	MemoryBytes        int64                  `protobuf:"varint,7,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	MemoryLimitBytes   int64                  `protobuf:"varint,8,opt,name=memory_limit_bytes,json=memoryLimitBytes,proto3" json:"memory_limit_bytes,omitempty"`
+	DiskAllocatedBytes int64                  `protobuf:"varint,9,opt,name=disk_allocated_bytes,json=diskAllocatedBytes,proto3" json:"disk_allocated_bytes,omitempty"` // space the sandbox's disk takes on the host
+	DiskCapacityBytes  int64                  `protobuf:"varint,10,opt,name=disk_capacity_bytes,json=diskCapacityBytes,proto3" json:"disk_capacity_bytes,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SandboxStats) Reset() {
+	*x = SandboxStats{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxStats) ProtoMessage() {}
+
+func (x *SandboxStats) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxStats.ProtoReflect.Descriptor instead.
+func (*SandboxStats) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SandboxStats) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SandboxStats) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SandboxStats) GetSampledAt() string {
+	if x != nil {
+		return x.SampledAt
+	}
+	return ""
+}
+
+func (x *SandboxStats) GetVcpus() int32 {
+	if x != nil {
+		return x.Vcpus
+	}
+	return 0
+}
+
+func (x *SandboxStats) GetCpuTimeMs() int64 {
+	if x != nil {
+		return x.CpuTimeMs
+	}
+	return 0
+}
+
+func (x *SandboxStats) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *SandboxStats) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *SandboxStats) GetMemoryLimitBytes() int64 {
+	if x != nil {
+		return x.MemoryLimitBytes
+	}
+	return 0
+}
+
+func (x *SandboxStats) GetDiskAllocatedBytes() int64 {
+	if x != nil {
+		return x.DiskAllocatedBytes
+	}
+	return 0
+}
+
+func (x *SandboxStats) GetDiskCapacityBytes() int64 {
+	if x != nil {
+		return x.DiskCapacityBytes
+	}
+	return 0
+}
+
+// ListSandboxesRequest requests all sandboxes.
+type ListSandboxesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSandboxesRequest) Reset() {
+	*x = ListSandboxesRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSandboxesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSandboxesRequest) ProtoMessage() {}
+
+func (x *ListSandboxesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSandboxesRequest.ProtoReflect.Descriptor instead.
+func (*ListSandboxesRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{15}
+}
+
+// ListSandboxesResponse contains a list of sandboxes.
+type ListSandboxesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sandboxes     []*SandboxInfo         `protobuf:"bytes,1,rep,name=sandboxes,proto3" json:"sandboxes,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSandboxesResponse) Reset() {
+	*x = ListSandboxesResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSandboxesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSandboxesResponse) ProtoMessage() {}
+
+func (x *ListSandboxesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSandboxesResponse.ProtoReflect.Descriptor instead.
+func (*ListSandboxesResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListSandboxesResponse) GetSandboxes() []*SandboxInfo {
+	if x != nil {
+		return x.Sandboxes
+	}
+	return nil
+}
+
+func (x *ListSandboxesResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// ListSandboxCommandsRequest requests the command history of a sandbox.
+type ListSandboxCommandsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Limit     int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // most recent N commands, 0 for all
+	Actor     string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`  // only commands run by this actor, empty for all
+	// after_id pages forward from a command already seen: only commands
+	// recorded after it are returned, and limit keeps the oldest N. Following
+	// clients pass the last ID they received.
+	AfterId       string `protobuf:"bytes,4,opt,name=after_id,json=afterId,proto3" json:"after_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSandboxCommandsRequest) Reset() {
+	*x = ListSandboxCommandsRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSandboxCommandsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSandboxCommandsRequest) ProtoMessage() {}
+
+func (x *ListSandboxCommandsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSandboxCommandsRequest.ProtoReflect.Descriptor instead.
+func (*ListSandboxCommandsRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListSandboxCommandsRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *ListSandboxCommandsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListSandboxCommandsRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ListSandboxCommandsRequest) GetAfterId() string {
+	if x != nil {
+		return x.AfterId
+	}
+	return ""
+}
+
+// ListSandboxCommandsResponse returns command history, oldest first.
+type ListSandboxCommandsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commands      []*CommandRecord       `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSandboxCommandsResponse) Reset() {
+	*x = ListSandboxCommandsResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSandboxCommandsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSandboxCommandsResponse) ProtoMessage() {}
+
+func (x *ListSandboxCommandsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSandboxCommandsResponse.ProtoReflect.Descriptor instead.
+func (*ListSandboxCommandsResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListSandboxCommandsResponse) GetCommands() []*CommandRecord {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+// RunCommandBatchCommand runs several commands in a sandbox in order, reusing
+// one SSH connection where the provider supports it.
+type RunCommandBatchCommand struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId      string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Commands       []string               `protobuf:"bytes,2,rep,name=commands,proto3" json:"commands,omitempty"`
+	TimeoutSeconds int32                  `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // per command
+	StopOnError    bool                   `protobuf:"varint,4,opt,name=stop_on_error,json=stopOnError,proto3" json:"stop_on_error,omitempty"`        // skip the remaining commands after a non-zero exit
+	Actor          string                 `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`                                          // who initiated the commands, see RunCommandCommand.actor
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RunCommandBatchCommand) Reset() {
+	*x = RunCommandBatchCommand{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunCommandBatchCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunCommandBatchCommand) ProtoMessage() {}
+
+func (x *RunCommandBatchCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunCommandBatchCommand.ProtoReflect.Descriptor instead.
+func (*RunCommandBatchCommand) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RunCommandBatchCommand) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *RunCommandBatchCommand) GetCommands() []string {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+func (x *RunCommandBatchCommand) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *RunCommandBatchCommand) GetStopOnError() bool {
+	if x != nil {
+		return x.StopOnError
+	}
+	return false
+}
+
+func (x *RunCommandBatchCommand) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+// RunCommandBatchResult holds one result per command that ran, in order.
+type RunCommandBatchResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Results []*CommandResult       `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// Set when a command could not be run at all (e.g. SSH failed), which ends
+	// the batch; results still holds the commands that ran before it.
+	Error         string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunCommandBatchResult) Reset() {
+	*x = RunCommandBatchResult{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunCommandBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunCommandBatchResult) ProtoMessage() {}
+
+func (x *RunCommandBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunCommandBatchResult.ProtoReflect.Descriptor instead.
+func (*RunCommandBatchResult) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RunCommandBatchResult) GetResults() []*CommandResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *RunCommandBatchResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// GetSandboxSSHTargetRequest asks how to reach a sandbox over SSH from the
+// daemon host, for interactive sessions the daemon does not proxy.
+type GetSandboxSSHTargetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSandboxSSHTargetRequest) Reset() {
+	*x = GetSandboxSSHTargetRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSandboxSSHTargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSandboxSSHTargetRequest) ProtoMessage() {}
+
+func (x *GetSandboxSSHTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSandboxSSHTargetRequest.ProtoReflect.Descriptor instead.
+func (*GetSandboxSSHTargetRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetSandboxSSHTargetRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+// SandboxSSHTarget describes an SSH destination for a sandbox. Key and
+// certificate paths are on the daemon host. host_key_options are the ssh
+// options that check the sandbox's host key under the daemon's
+// ssh.host_key_mode; any known_hosts file they name is on the daemon host.
+type SandboxSSHTarget struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId       string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	IpAddress       string                 `protobuf:"bytes,2,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	Username        string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`
+	PrivateKeyPath  string                 `protobuf:"bytes,4,opt,name=private_key_path,json=privateKeyPath,proto3" json:"private_key_path,omitempty"`
+	CertificatePath string                 `protobuf:"bytes,5,opt,name=certificate_path,json=certificatePath,proto3" json:"certificate_path,omitempty"`
+	HostKeyOptions  []string               `protobuf:"bytes,6,rep,name=host_key_options,json=hostKeyOptions,proto3" json:"host_key_options,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SandboxSSHTarget) Reset() {
+	*x = SandboxSSHTarget{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSSHTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSSHTarget) ProtoMessage() {}
+
+func (x *SandboxSSHTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSSHTarget.ProtoReflect.Descriptor instead.
+func (*SandboxSSHTarget) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SandboxSSHTarget) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SandboxSSHTarget) GetIpAddress() string {
+	if x != nil {
+		return x.IpAddress
+	}
+	return ""
+}
+
+func (x *SandboxSSHTarget) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SandboxSSHTarget) GetPrivateKeyPath() string {
+	if x != nil {
+		return x.PrivateKeyPath
+	}
+	return ""
+}
+
+func (x *SandboxSSHTarget) GetCertificatePath() string {
+	if x != nil {
+		return x.CertificatePath
+	}
+	return ""
+}
+
+func (x *SandboxSSHTarget) GetHostKeyOptions() []string {
+	if x != nil {
+		return x.HostKeyOptions
+	}
+	return nil
+}
+
+// RenewSandboxSSHCredentialsRequest asks the daemon to reissue the SSH key
+// and certificate it uses for a sandbox before the current one expires.
+type RenewSandboxSSHCredentialsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenewSandboxSSHCredentialsRequest) Reset() {
+	*x = RenewSandboxSSHCredentialsRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenewSandboxSSHCredentialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenewSandboxSSHCredentialsRequest) ProtoMessage() {}
+
+func (x *RenewSandboxSSHCredentialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenewSandboxSSHCredentialsRequest.ProtoReflect.Descriptor instead.
+func (*RenewSandboxSSHCredentialsRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RenewSandboxSSHCredentialsRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+// SandboxSSHCredentials describes a sandbox's freshly issued SSH
+// credentials. Paths are on the daemon host.
+type SandboxSSHCredentials struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId       string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Username        string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	PrivateKeyPath  string                 `protobuf:"bytes,3,opt,name=private_key_path,json=privateKeyPath,proto3" json:"private_key_path,omitempty"`
+	CertificatePath string                 `protobuf:"bytes,4,opt,name=certificate_path,json=certificatePath,proto3" json:"certificate_path,omitempty"`
+	ValidUntil      string                 `protobuf:"bytes,5,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"` // RFC3339
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SandboxSSHCredentials) Reset() {
+	*x = SandboxSSHCredentials{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSSHCredentials) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSSHCredentials) ProtoMessage() {}
+
+func (x *SandboxSSHCredentials) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSSHCredentials.ProtoReflect.Descriptor instead.
+func (*SandboxSSHCredentials) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SandboxSSHCredentials) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SandboxSSHCredentials) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *SandboxSSHCredentials) GetPrivateKeyPath() string {
+	if x != nil {
+		return x.PrivateKeyPath
+	}
+	return ""
+}
+
+func (x *SandboxSSHCredentials) GetCertificatePath() string {
+	if x != nil {
+		return x.CertificatePath
+	}
+	return ""
+}
+
+func (x *SandboxSSHCredentials) GetValidUntil() string {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return ""
+}
+
+// DiffSnapshotsRequest compares the filesystem recorded at one snapshot with
+// a later snapshot, or with the sandbox as it is now when to_snapshot is empty.
+// Snapshots are named by ID or name.
+type DiffSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	FromSnapshot  string                 `protobuf:"bytes,2,opt,name=from_snapshot,json=fromSnapshot,proto3" json:"from_snapshot,omitempty"`
+	ToSnapshot    string                 `protobuf:"bytes,3,opt,name=to_snapshot,json=toSnapshot,proto3" json:"to_snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffSnapshotsRequest) Reset() {
+	*x = DiffSnapshotsRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffSnapshotsRequest) ProtoMessage() {}
+
+func (x *DiffSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*DiffSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DiffSnapshotsRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *DiffSnapshotsRequest) GetFromSnapshot() string {
+	if x != nil {
+		return x.FromSnapshot
+	}
+	return ""
+}
+
+func (x *DiffSnapshotsRequest) GetToSnapshot() string {
+	if x != nil {
+		return x.ToSnapshot
+	}
+	return ""
+}
+
+// SnapshotDiff lists files and packages that changed between two snapshots
+// and the commands run in between. A package upgrade appears as the old
+// version removed and the new one added.
+type SnapshotDiff struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FilesAdded      []string               `protobuf:"bytes,1,rep,name=files_added,json=filesAdded,proto3" json:"files_added,omitempty"`
+	FilesModified   []string               `protobuf:"bytes,2,rep,name=files_modified,json=filesModified,proto3" json:"files_modified,omitempty"`
+	FilesRemoved    []string               `protobuf:"bytes,3,rep,name=files_removed,json=filesRemoved,proto3" json:"files_removed,omitempty"`
+	CommandsRun     []*CommandRecord       `protobuf:"bytes,4,rep,name=commands_run,json=commandsRun,proto3" json:"commands_run,omitempty"`
+	PackagesAdded   []*SnapshotPackage     `protobuf:"bytes,5,rep,name=packages_added,json=packagesAdded,proto3" json:"packages_added,omitempty"`
+	PackagesRemoved []*SnapshotPackage     `protobuf:"bytes,6,rep,name=packages_removed,json=packagesRemoved,proto3" json:"packages_removed,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SnapshotDiff) Reset() {
+	*x = SnapshotDiff{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotDiff) ProtoMessage() {}
+
+func (x *SnapshotDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotDiff.ProtoReflect.Descriptor instead.
+func (*SnapshotDiff) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SnapshotDiff) GetFilesAdded() []string {
+	if x != nil {
+		return x.FilesAdded
+	}
+	return nil
+}
+
+func (x *SnapshotDiff) GetFilesModified() []string {
+	if x != nil {
+		return x.FilesModified
+	}
+	return nil
+}
+
+func (x *SnapshotDiff) GetFilesRemoved() []string {
+	if x != nil {
+		return x.FilesRemoved
+	}
+	return nil
+}
+
+func (x *SnapshotDiff) GetCommandsRun() []*CommandRecord {
+	if x != nil {
+		return x.CommandsRun
+	}
+	return nil
+}
+
+func (x *SnapshotDiff) GetPackagesAdded() []*SnapshotPackage {
+	if x != nil {
+		return x.PackagesAdded
+	}
+	return nil
+}
+
+func (x *SnapshotDiff) GetPackagesRemoved() []*SnapshotPackage {
+	if x != nil {
+		return x.PackagesRemoved
+	}
+	return nil
+}
+
+// RestoreSnapshotFile reads one file from a snapshot. With in_place the file
+// is written back into the running sandbox instead of being returned.
+type RestoreSnapshotFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Snapshot      string                 `protobuf:"bytes,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	InPlace       bool                   `protobuf:"varint,4,opt,name=in_place,json=inPlace,proto3" json:"in_place,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreSnapshotFileRequest) Reset() {
+	*x = RestoreSnapshotFileRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreSnapshotFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreSnapshotFileRequest) ProtoMessage() {}
+
+func (x *RestoreSnapshotFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreSnapshotFileRequest.ProtoReflect.Descriptor instead.
+func (*RestoreSnapshotFileRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RestoreSnapshotFileRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *RestoreSnapshotFileRequest) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+func (x *RestoreSnapshotFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *RestoreSnapshotFileRequest) GetInPlace() bool {
+	if x != nil {
+		return x.InPlace
+	}
+	return false
+}
+
+type SnapshotFileResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Snapshot      string                 `protobuf:"bytes,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Content       []byte                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"` // empty when restored in place
+	Restored      bool                   `protobuf:"varint,5,opt,name=restored,proto3" json:"restored,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotFileResult) Reset() {
+	*x = SnapshotFileResult{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotFileResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotFileResult) ProtoMessage() {}
+
+func (x *SnapshotFileResult) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotFileResult.ProtoReflect.Descriptor instead.
+func (*SnapshotFileResult) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SnapshotFileResult) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SnapshotFileResult) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+func (x *SnapshotFileResult) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SnapshotFileResult) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *SnapshotFileResult) GetRestored() bool {
+	if x != nil {
+		return x.Restored
+	}
+	return false
+}
+
+type ListSnapshotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsRequest) Reset() {
+	*x = ListSnapshotsRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsRequest) ProtoMessage() {}
+
+func (x *ListSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ListSnapshotsRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+// SnapshotInfo is a recorded snapshot. kind is empty for a point-in-time
+// snapshot and "archive" for a disk archive, whose file is in ref.
+type SnapshotInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Ref           string                 `protobuf:"bytes,4,opt,name=ref,proto3" json:"ref,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC3339
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotInfo) Reset() {
+	*x = SnapshotInfo{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotInfo) ProtoMessage() {}
+
+func (x *SnapshotInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotInfo.ProtoReflect.Descriptor instead.
+func (*SnapshotInfo) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SnapshotInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetRef() string {
+	if x != nil {
+		return x.Ref
+	}
+	return ""
+}
+
+func (x *SnapshotInfo) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ListSnapshotsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Snapshots     []*SnapshotInfo        `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSnapshotsResponse) Reset() {
+	*x = ListSnapshotsResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsResponse) ProtoMessage() {}
+
+func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListSnapshotsResponse) GetSnapshots() []*SnapshotInfo {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+// DeleteSnapshotRequest names a snapshot by ID or name.
+type DeleteSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Snapshot      string                 `protobuf:"bytes,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSnapshotRequest) Reset() {
+	*x = DeleteSnapshotRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSnapshotRequest) ProtoMessage() {}
+
+func (x *DeleteSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DeleteSnapshotRequest) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *DeleteSnapshotRequest) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+// SnapshotDeleted reports which halves of a snapshot were removed: the
+// daemon's record and the backend snapshot (or archive file). Either may
+// already have been missing.
+type SnapshotDeleted struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId      string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Snapshot       string                 `protobuf:"bytes,2,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
+	RecordDeleted  bool                   `protobuf:"varint,3,opt,name=record_deleted,json=recordDeleted,proto3" json:"record_deleted,omitempty"`
+	BackendDeleted bool                   `protobuf:"varint,4,opt,name=backend_deleted,json=backendDeleted,proto3" json:"backend_deleted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SnapshotDeleted) Reset() {
+	*x = SnapshotDeleted{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotDeleted) ProtoMessage() {}
+
+func (x *SnapshotDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotDeleted.ProtoReflect.Descriptor instead.
+func (*SnapshotDeleted) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SnapshotDeleted) GetSandboxId() string {
+	if x != nil {
+		return x.SandboxId
+	}
+	return ""
+}
+
+func (x *SnapshotDeleted) GetSnapshot() string {
+	if x != nil {
+		return x.Snapshot
+	}
+	return ""
+}
+
+func (x *SnapshotDeleted) GetRecordDeleted() bool {
+	if x != nil {
+		return x.RecordDeleted
+	}
+	return false
+}
+
+func (x *SnapshotDeleted) GetBackendDeleted() bool {
+	if x != nil {
+		return x.BackendDeleted
+	}
+	return false
+}
+
+// SnapshotPackage is an installed package and its version.
+type SnapshotPackage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotPackage) Reset() {
+	*x = SnapshotPackage{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotPackage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotPackage) ProtoMessage() {}
+
+func (x *SnapshotPackage) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotPackage.ProtoReflect.Descriptor instead.
+func (*SnapshotPackage) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SnapshotPackage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SnapshotPackage) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// DiffSnapshotsProgress reports a step of a diff; the last message carries
+// the result.
+type DiffSnapshotsProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Step          string                 `protobuf:"bytes,1,opt,name=step,proto3" json:"step,omitempty"`
+	Diff          *SnapshotDiff          `protobuf:"bytes,2,opt,name=diff,proto3" json:"diff,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffSnapshotsProgress) Reset() {
+	*x = DiffSnapshotsProgress{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffSnapshotsProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffSnapshotsProgress) ProtoMessage() {}
+
+func (x *DiffSnapshotsProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffSnapshotsProgress.ProtoReflect.Descriptor instead.
+func (*DiffSnapshotsProgress) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DiffSnapshotsProgress) GetStep() string {
+	if x != nil {
+		return x.Step
+	}
+	return ""
+}
+
+func (x *DiffSnapshotsProgress) GetDiff() *SnapshotDiff {
+	if x != nil {
+		return x.Diff
+	}
+	return nil
+}
+
+// CommandRecord is a command previously run in a sandbox.
+type CommandRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Command       string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Stdout        string                 `protobuf:"bytes,3,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr        string                 `protobuf:"bytes,4,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,5,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	StartedAt     string                 `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	EndedAt       string                 `protobuf:"bytes,8,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	Actor         string                 `protobuf:"bytes,9,opt,name=actor,proto3" json:"actor,omitempty"`      // who initiated the command; empty for records from before actors were kept
+	Workdir       string                 `protobuf:"bytes,10,opt,name=workdir,proto3" json:"workdir,omitempty"` // directory the command ran in; empty for the login directory
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandRecord) Reset() {
+	*x = CommandRecord{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandRecord) ProtoMessage() {}
+
+func (x *CommandRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandRecord.ProtoReflect.Descriptor instead.
+func (*CommandRecord) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *CommandRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetStdout() string {
+	if x != nil {
+		return x.Stdout
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetStderr() string {
+	if x != nil {
+		return x.Stderr
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *CommandRecord) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *CommandRecord) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetEndedAt() string {
+	if x != nil {
+		return x.EndedAt
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *CommandRecord) GetWorkdir() string {
+	if x != nil {
+		return x.Workdir
+	}
+	return ""
+}
+
+// GetHostInfoRequest requests host information.
+type GetHostInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHostInfoRequest) Reset() {
+	*x = GetHostInfoRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHostInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHostInfoRequest) ProtoMessage() {}
+
+func (x *GetHostInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHostInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetHostInfoRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{37}
+}
+
+// HostInfoResponse contains host resource and capability information.
+type HostInfoResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	HostId            string                 `protobuf:"bytes,1,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Hostname          string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Version           string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	TotalCpus         int32                  `protobuf:"varint,4,opt,name=total_cpus,json=totalCpus,proto3" json:"total_cpus,omitempty"`
+	TotalMemoryMb     int64                  `protobuf:"varint,5,opt,name=total_memory_mb,json=totalMemoryMb,proto3" json:"total_memory_mb,omitempty"`
+	ActiveSandboxes   int32                  `protobuf:"varint,6,opt,name=active_sandboxes,json=activeSandboxes,proto3" json:"active_sandboxes,omitempty"`
+	BaseImages        []string               `protobuf:"bytes,7,rep,name=base_images,json=baseImages,proto3" json:"base_images,omitempty"`
+	SshCaPubKey       string                 `protobuf:"bytes,8,opt,name=ssh_ca_pub_key,json=sshCaPubKey,proto3" json:"ssh_ca_pub_key,omitempty"`
+	SshIdentityPubKey string                 `protobuf:"bytes,9,opt,name=ssh_identity_pub_key,json=sshIdentityPubKey,proto3" json:"ssh_identity_pub_key,omitempty"`
+	SourceHosts       []*SourceHostInfo      `protobuf:"bytes,10,rep,name=source_hosts,json=sourceHosts,proto3" json:"source_hosts,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HostInfoResponse) Reset() {
+	*x = HostInfoResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostInfoResponse) ProtoMessage() {}
+
+func (x *HostInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostInfoResponse.ProtoReflect.Descriptor instead.
+func (*HostInfoResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *HostInfoResponse) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *HostInfoResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *HostInfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HostInfoResponse) GetTotalCpus() int32 {
+	if x != nil {
+		return x.TotalCpus
+	}
+	return 0
+}
+
+func (x *HostInfoResponse) GetTotalMemoryMb() int64 {
+	if x != nil {
+		return x.TotalMemoryMb
+	}
+	return 0
+}
+
+func (x *HostInfoResponse) GetActiveSandboxes() int32 {
+	if x != nil {
+		return x.ActiveSandboxes
+	}
+	return 0
+}
+
+func (x *HostInfoResponse) GetBaseImages() []string {
+	if x != nil {
+		return x.BaseImages
+	}
+	return nil
+}
+
+func (x *HostInfoResponse) GetSshCaPubKey() string {
+	if x != nil {
+		return x.SshCaPubKey
+	}
+	return ""
+}
+
+func (x *HostInfoResponse) GetSshIdentityPubKey() string {
+	if x != nil {
+		return x.SshIdentityPubKey
+	}
+	return ""
+}
+
+func (x *HostInfoResponse) GetSourceHosts() []*SourceHostInfo {
+	if x != nil {
+		return x.SourceHosts
+	}
+	return nil
+}
+
+// SourceHostInfo describes a source host the daemon is configured to use.
+// Returned in HostInfoResponse so the CLI can deploy the daemon's identity
+// key to these hosts during setup.
+type SourceHostInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	SshUser       string                 `protobuf:"bytes,2,opt,name=ssh_user,json=sshUser,proto3" json:"ssh_user,omitempty"`
+	SshPort       int32                  `protobuf:"varint,3,opt,name=ssh_port,json=sshPort,proto3" json:"ssh_port,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceHostInfo) Reset() {
+	*x = SourceHostInfo{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceHostInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceHostInfo) ProtoMessage() {}
+
+func (x *SourceHostInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceHostInfo.ProtoReflect.Descriptor instead.
+func (*SourceHostInfo) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SourceHostInfo) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *SourceHostInfo) GetSshUser() string {
+	if x != nil {
+		return x.SshUser
+	}
+	return ""
+}
+
+func (x *SourceHostInfo) GetSshPort() int32 {
+	if x != nil {
+		return x.SshPort
+	}
+	return 0
+}
+
+// HealthRequest is an empty health check request.
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{40}
+}
+
+// HealthResponse indicates daemon health status.
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *HealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// GetStatusRequest requests the daemon's self-reported runtime status.
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{42}
+}
+
+// DaemonStatusResponse describes what a running daemon is managing right now.
+type DaemonStatusResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	HostId          string                 `protobuf:"bytes,1,opt,name=host_id,json=hostId,proto3" json:"host_id,omitempty"`
+	Hostname        string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Version         string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Provider        string                 `protobuf:"bytes,4,opt,name=provider,proto3" json:"provider,omitempty"`
+	ActiveSandboxes int32                  `protobuf:"varint,5,opt,name=active_sandboxes,json=activeSandboxes,proto3" json:"active_sandboxes,omitempty"`
+	StartedAt       string                 `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	UptimeSeconds   int64                  `protobuf:"varint,7,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	// janitor_last_run is empty if the janitor has not completed a pass yet.
+	JanitorLastRun string `protobuf:"bytes,8,opt,name=janitor_last_run,json=janitorLastRun,proto3" json:"janitor_last_run,omitempty"`
+	// recent_errors holds the most recent operation and janitor errors, oldest first.
+	RecentErrors  []*DaemonError `protobuf:"bytes,9,rep,name=recent_errors,json=recentErrors,proto3" json:"recent_errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DaemonStatusResponse) Reset() {
+	*x = DaemonStatusResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DaemonStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DaemonStatusResponse) ProtoMessage() {}
+
+func (x *DaemonStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DaemonStatusResponse.ProtoReflect.Descriptor instead.
+func (*DaemonStatusResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *DaemonStatusResponse) GetHostId() string {
+	if x != nil {
+		return x.HostId
+	}
+	return ""
+}
+
+func (x *DaemonStatusResponse) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *DaemonStatusResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DaemonStatusResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *DaemonStatusResponse) GetActiveSandboxes() int32 {
+	if x != nil {
+		return x.ActiveSandboxes
+	}
+	return 0
+}
+
+func (x *DaemonStatusResponse) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *DaemonStatusResponse) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *DaemonStatusResponse) GetJanitorLastRun() string {
+	if x != nil {
+		return x.JanitorLastRun
+	}
+	return ""
+}
+
+func (x *DaemonStatusResponse) GetRecentErrors() []*DaemonError {
+	if x != nil {
+		return x.RecentErrors
+	}
+	return nil
+}
+
+// DaemonError is a single error recorded by the daemon.
+type DaemonError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          string                 `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DaemonError) Reset() {
+	*x = DaemonError{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DaemonError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DaemonError) ProtoMessage() {}
+
+func (x *DaemonError) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DaemonError.ProtoReflect.Descriptor instead.
+func (*DaemonError) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *DaemonError) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *DaemonError) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *DaemonError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// DiscoverHostsCommand requests the daemon to parse SSH config and probe hosts.
+type DiscoverHostsCommand struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ssh_config_content is the raw SSH config text to parse.
+	SshConfigContent string `protobuf:"bytes,1,opt,name=ssh_config_content,json=sshConfigContent,proto3" json:"ssh_config_content,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DiscoverHostsCommand) Reset() {
+	*x = DiscoverHostsCommand{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiscoverHostsCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiscoverHostsCommand) ProtoMessage() {}
+
+func (x *DiscoverHostsCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiscoverHostsCommand.ProtoReflect.Descriptor instead.
+func (*DiscoverHostsCommand) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *DiscoverHostsCommand) GetSshConfigContent() string {
+	if x != nil {
+		return x.SshConfigContent
+	}
+	return ""
+}
+
+// DiscoveredHost describes a single probed host.
+type DiscoveredHost struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Hostname      string                 `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	User          string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Port          int32                  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	IdentityFile  string                 `protobuf:"bytes,5,opt,name=identity_file,json=identityFile,proto3" json:"identity_file,omitempty"`
+	Reachable     bool                   `protobuf:"varint,6,opt,name=reachable,proto3" json:"reachable,omitempty"`
+	HasLibvirt    bool                   `protobuf:"varint,7,opt,name=has_libvirt,json=hasLibvirt,proto3" json:"has_libvirt,omitempty"`
+	HasProxmox    bool                   `protobuf:"varint,8,opt,name=has_proxmox,json=hasProxmox,proto3" json:"has_proxmox,omitempty"`
+	Vms           []string               `protobuf:"bytes,9,rep,name=vms,proto3" json:"vms,omitempty"`
+	Error         string                 `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiscoveredHost) Reset() {
+	*x = DiscoveredHost{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiscoveredHost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiscoveredHost) ProtoMessage() {}
+
+func (x *DiscoveredHost) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiscoveredHost.ProtoReflect.Descriptor instead.
+func (*DiscoveredHost) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *DiscoveredHost) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DiscoveredHost) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *DiscoveredHost) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *DiscoveredHost) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *DiscoveredHost) GetIdentityFile() string {
+	if x != nil {
+		return x.IdentityFile
+	}
+	return ""
+}
+
+func (x *DiscoveredHost) GetReachable() bool {
+	if x != nil {
+		return x.Reachable
+	}
+	return false
+}
+
+func (x *DiscoveredHost) GetHasLibvirt() bool {
+	if x != nil {
+		return x.HasLibvirt
+	}
+	return false
+}
+
+func (x *DiscoveredHost) GetHasProxmox() bool {
+	if x != nil {
+		return x.HasProxmox
+	}
+	return false
+}
+
+func (x *DiscoveredHost) GetVms() []string {
+	if x != nil {
+		return x.Vms
+	}
+	return nil
+}
+
+func (x *DiscoveredHost) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// DiscoverHostsResult is the response containing all probed hosts.
+type DiscoverHostsResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hosts         []*DiscoveredHost      `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiscoverHostsResult) Reset() {
+	*x = DiscoverHostsResult{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiscoverHostsResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiscoverHostsResult) ProtoMessage() {}
+
+func (x *DiscoverHostsResult) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiscoverHostsResult.ProtoReflect.Descriptor instead.
+func (*DiscoverHostsResult) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *DiscoverHostsResult) GetHosts() []*DiscoveredHost {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+// DoctorCheckRequest requests daemon-side health checks.
+type DoctorCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DoctorCheckRequest) Reset() {
+	*x = DoctorCheckRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DoctorCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DoctorCheckRequest) ProtoMessage() {}
+
+func (x *DoctorCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DoctorCheckRequest.ProtoReflect.Descriptor instead.
+func (*DoctorCheckRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{48}
+}
+
+// DoctorCheckResult holds the outcome of a single doctor check.
+type DoctorCheckResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
+	Passed        bool                   `protobuf:"varint,3,opt,name=passed,proto3" json:"passed,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	FixCmd        string                 `protobuf:"bytes,5,opt,name=fix_cmd,json=fixCmd,proto3" json:"fix_cmd,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DoctorCheckResult) Reset() {
+	*x = DoctorCheckResult{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DoctorCheckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DoctorCheckResult) ProtoMessage() {}
+
+func (x *DoctorCheckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DoctorCheckResult.ProtoReflect.Descriptor instead.
+func (*DoctorCheckResult) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *DoctorCheckResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DoctorCheckResult) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *DoctorCheckResult) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+func (x *DoctorCheckResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *DoctorCheckResult) GetFixCmd() string {
+	if x != nil {
+		return x.FixCmd
+	}
+	return ""
+}
+
+// DoctorCheckResponse contains the results of all doctor checks.
+type DoctorCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*DoctorCheckResult   `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DoctorCheckResponse) Reset() {
+	*x = DoctorCheckResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DoctorCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DoctorCheckResponse) ProtoMessage() {}
+
+func (x *DoctorCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DoctorCheckResponse.ProtoReflect.Descriptor instead.
+func (*DoctorCheckResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *DoctorCheckResponse) GetResults() []*DoctorCheckResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// ScanSourceHostKeysRequest requests the daemon to scan and trust SSH host keys
+// for all configured source hosts.
+type ScanSourceHostKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanSourceHostKeysRequest) Reset() {
+	*x = ScanSourceHostKeysRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanSourceHostKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanSourceHostKeysRequest) ProtoMessage() {}
+
+func (x *ScanSourceHostKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanSourceHostKeysRequest.ProtoReflect.Descriptor instead.
+func (*ScanSourceHostKeysRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{51}
+}
+
+// ScanSourceHostKeysResult holds the outcome of scanning a single source host's key.
+type ScanSourceHostKeysResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanSourceHostKeysResult) Reset() {
+	*x = ScanSourceHostKeysResult{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanSourceHostKeysResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanSourceHostKeysResult) ProtoMessage() {}
+
+func (x *ScanSourceHostKeysResult) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanSourceHostKeysResult.ProtoReflect.Descriptor instead.
+func (*ScanSourceHostKeysResult) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ScanSourceHostKeysResult) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ScanSourceHostKeysResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ScanSourceHostKeysResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// ScanSourceHostKeysResponse contains the results of scanning all source host keys.
+type ScanSourceHostKeysResponse struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	Results       []*ScanSourceHostKeysResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanSourceHostKeysResponse) Reset() {
+	*x = ScanSourceHostKeysResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanSourceHostKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanSourceHostKeysResponse) ProtoMessage() {}
+
+func (x *ScanSourceHostKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanSourceHostKeysResponse.ProtoReflect.Descriptor instead.
+func (*ScanSourceHostKeysResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ScanSourceHostKeysResponse) GetResults() []*ScanSourceHostKeysResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// ListOrphansRequest asks for provider resources (e.g. Proxmox CTs) that no
+// sandbox owns. Listing never deletes anything; see ReclaimOrphans.
+type ListOrphansRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrphansRequest) Reset() {
+	*x = ListOrphansRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrphansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrphansRequest) ProtoMessage() {}
+
+func (x *ListOrphansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrphansRequest.ProtoReflect.Descriptor instead.
+func (*ListOrphansRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{54}
+}
+
+// OrphanResource is one leaked provider resource.
+type OrphanResource struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // provider identifier, e.g. a VMID
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Reclaimed     bool                   `protobuf:"varint,5,opt,name=reclaimed,proto3" json:"reclaimed,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"` // set when reclaiming failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrphanResource) Reset() {
+	*x = OrphanResource{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrphanResource) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *DiscoveredHost) GetUser() string {
+func (*OrphanResource) ProtoMessage() {}
+
+func (x *OrphanResource) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[55]
 	if x != nil {
-		return x.User
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *DiscoveredHost) GetPort() int32 {
-	if x != nil {
-		return x.Port
-	}
-	return 0
+// Deprecated: Use OrphanResource.ProtoReflect.Descriptor instead.
+func (*OrphanResource) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{55}
 }
 
-func (x *DiscoveredHost) GetIdentityFile() string {
+func (x *OrphanResource) GetId() string {
 	if x != nil {
-		return x.IdentityFile
+		return x.Id
 	}
 	return ""
 }
 
-func (x *DiscoveredHost) GetReachable() bool {
+func (x *OrphanResource) GetName() string {
 	if x != nil {
-		return x.Reachable
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *DiscoveredHost) GetHasLibvirt() bool {
+func (x *OrphanResource) GetState() string {
 	if x != nil {
-		return x.HasLibvirt
+		return x.State
 	}
-	return false
+	return ""
 }
 
-func (x *DiscoveredHost) GetHasProxmox() bool {
+func (x *OrphanResource) GetReason() string {
 	if x != nil {
-		return x.HasProxmox
+		return x.Reason
 	}
-	return false
+	return ""
 }
 
-func (x *DiscoveredHost) GetVms() []string {
+func (x *OrphanResource) GetReclaimed() bool {
 	if x != nil {
-		return x.Vms
+		return x.Reclaimed
 	}
-	return nil
+	return false
 }
 
-func (x *DiscoveredHost) GetError() string {
+func (x *OrphanResource) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-// DiscoverHostsResult is the response containing all probed hosts.
-type DiscoverHostsResult struct {
+// ListOrphansResponse lists orphans and the provider's allocation pool.
+// pool_size is zero for providers without one.
+type ListOrphansResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Hosts         []*DiscoveredHost      `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Orphans       []*OrphanResource      `protobuf:"bytes,1,rep,name=orphans,proto3" json:"orphans,omitempty"`
+	PoolSize      int32                  `protobuf:"varint,2,opt,name=pool_size,json=poolSize,proto3" json:"pool_size,omitempty"`
+	PoolFree      int32                  `protobuf:"varint,3,opt,name=pool_free,json=poolFree,proto3" json:"pool_free,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DiscoverHostsResult) Reset() {
-	*x = DiscoverHostsResult{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[11]
+func (x *ListOrphansResponse) Reset() {
+	*x = ListOrphansResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DiscoverHostsResult) String() string {
+func (x *ListOrphansResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DiscoverHostsResult) ProtoMessage() {}
+func (*ListOrphansResponse) ProtoMessage() {}
 
-func (x *DiscoverHostsResult) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[11]
+func (x *ListOrphansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -760,40 +3749,56 @@ func (x *DiscoverHostsResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DiscoverHostsResult.ProtoReflect.Descriptor instead.
-func (*DiscoverHostsResult) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use ListOrphansResponse.ProtoReflect.Descriptor instead.
+func (*ListOrphansResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{56}
 }
 
-func (x *DiscoverHostsResult) GetHosts() []*DiscoveredHost {
+func (x *ListOrphansResponse) GetOrphans() []*OrphanResource {
 	if x != nil {
-		return x.Hosts
+		return x.Orphans
 	}
 	return nil
 }
 
-// DoctorCheckRequest requests daemon-side health checks.
-type DoctorCheckRequest struct {
+func (x *ListOrphansResponse) GetPoolSize() int32 {
+	if x != nil {
+		return x.PoolSize
+	}
+	return 0
+}
+
+func (x *ListOrphansResponse) GetPoolFree() int32 {
+	if x != nil {
+		return x.PoolFree
+	}
+	return 0
+}
+
+// ReclaimOrphansRequest deletes the named orphans, as listed by ListOrphans.
+// An id that is no longer an orphan is left alone and reported as an error.
+type ReclaimOrphansRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DoctorCheckRequest) Reset() {
-	*x = DoctorCheckRequest{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[12]
+func (x *ReclaimOrphansRequest) Reset() {
+	*x = ReclaimOrphansRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DoctorCheckRequest) String() string {
+func (x *ReclaimOrphansRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DoctorCheckRequest) ProtoMessage() {}
+func (*ReclaimOrphansRequest) ProtoMessage() {}
 
-func (x *DoctorCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[12]
+func (x *ReclaimOrphansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -804,38 +3809,44 @@ func (x *DoctorCheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DoctorCheckRequest.ProtoReflect.Descriptor instead.
-func (*DoctorCheckRequest) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use ReclaimOrphansRequest.ProtoReflect.Descriptor instead.
+func (*ReclaimOrphansRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{57}
 }
 
-// DoctorCheckResult holds the outcome of a single doctor check.
-type DoctorCheckResult struct {
+func (x *ReclaimOrphansRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// ReclaimOrphansResponse has one entry per requested id, with reclaimed or
+// error set, and the provider's allocation pool afterwards.
+type ReclaimOrphansResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Category      string                 `protobuf:"bytes,2,opt,name=category,proto3" json:"category,omitempty"`
-	Passed        bool                   `protobuf:"varint,3,opt,name=passed,proto3" json:"passed,omitempty"`
-	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
-	FixCmd        string                 `protobuf:"bytes,5,opt,name=fix_cmd,json=fixCmd,proto3" json:"fix_cmd,omitempty"`
+	Orphans       []*OrphanResource      `protobuf:"bytes,1,rep,name=orphans,proto3" json:"orphans,omitempty"`
+	PoolSize      int32                  `protobuf:"varint,2,opt,name=pool_size,json=poolSize,proto3" json:"pool_size,omitempty"`
+	PoolFree      int32                  `protobuf:"varint,3,opt,name=pool_free,json=poolFree,proto3" json:"pool_free,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DoctorCheckResult) Reset() {
-	*x = DoctorCheckResult{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[13]
+func (x *ReclaimOrphansResponse) Reset() {
+	*x = ReclaimOrphansResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DoctorCheckResult) String() string {
+func (x *ReclaimOrphansResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DoctorCheckResult) ProtoMessage() {}
+func (*ReclaimOrphansResponse) ProtoMessage() {}
 
-func (x *DoctorCheckResult) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[13]
+func (x *ReclaimOrphansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -846,69 +3857,56 @@ func (x *DoctorCheckResult) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DoctorCheckResult.ProtoReflect.Descriptor instead.
-func (*DoctorCheckResult) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *DoctorCheckResult) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *DoctorCheckResult) GetCategory() string {
-	if x != nil {
-		return x.Category
-	}
-	return ""
+// Deprecated: Use ReclaimOrphansResponse.ProtoReflect.Descriptor instead.
+func (*ReclaimOrphansResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{58}
 }
 
-func (x *DoctorCheckResult) GetPassed() bool {
+func (x *ReclaimOrphansResponse) GetOrphans() []*OrphanResource {
 	if x != nil {
-		return x.Passed
+		return x.Orphans
 	}
-	return false
+	return nil
 }
 
-func (x *DoctorCheckResult) GetMessage() string {
+func (x *ReclaimOrphansResponse) GetPoolSize() int32 {
 	if x != nil {
-		return x.Message
+		return x.PoolSize
 	}
-	return ""
+	return 0
 }
 
-func (x *DoctorCheckResult) GetFixCmd() string {
+func (x *ReclaimOrphansResponse) GetPoolFree() int32 {
 	if x != nil {
-		return x.FixCmd
+		return x.PoolFree
 	}
-	return ""
+	return 0
 }
 
-// DoctorCheckResponse contains the results of all doctor checks.
-type DoctorCheckResponse struct {
+// RefreshSandboxIPsRequest asks the daemon to rediscover sandbox IPs and
+// store them. An empty sandbox_ids refreshes every running sandbox.
+type RefreshSandboxIPsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Results       []*DoctorCheckResult   `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	SandboxIds    []string               `protobuf:"bytes,1,rep,name=sandbox_ids,json=sandboxIds,proto3" json:"sandbox_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DoctorCheckResponse) Reset() {
-	*x = DoctorCheckResponse{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[14]
+func (x *RefreshSandboxIPsRequest) Reset() {
+	*x = RefreshSandboxIPsRequest{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DoctorCheckResponse) String() string {
+func (x *RefreshSandboxIPsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DoctorCheckResponse) ProtoMessage() {}
+func (*RefreshSandboxIPsRequest) ProtoMessage() {}
 
-func (x *DoctorCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[14]
+func (x *RefreshSandboxIPsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -919,41 +3917,45 @@ func (x *DoctorCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DoctorCheckResponse.ProtoReflect.Descriptor instead.
-func (*DoctorCheckResponse) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use RefreshSandboxIPsRequest.ProtoReflect.Descriptor instead.
+func (*RefreshSandboxIPsRequest) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{59}
 }
 
-func (x *DoctorCheckResponse) GetResults() []*DoctorCheckResult {
+func (x *RefreshSandboxIPsRequest) GetSandboxIds() []string {
 	if x != nil {
-		return x.Results
+		return x.SandboxIds
 	}
 	return nil
 }
 
-// ScanSourceHostKeysRequest requests the daemon to scan and trust SSH host keys
-// for all configured source hosts.
-type ScanSourceHostKeysRequest struct {
+// SandboxIPResult is the outcome of rediscovering one sandbox's IP.
+type SandboxIPResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	IpAddress     string                 `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	PreviousIp    string                 `protobuf:"bytes,4,opt,name=previous_ip,json=previousIp,proto3" json:"previous_ip,omitempty"` // stored IP before the refresh, if any
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`                             // set when discovery failed or the IP conflicts
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ScanSourceHostKeysRequest) Reset() {
-	*x = ScanSourceHostKeysRequest{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[15]
+func (x *SandboxIPResult) Reset() {
+	*x = SandboxIPResult{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ScanSourceHostKeysRequest) String() string {
+func (x *SandboxIPResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ScanSourceHostKeysRequest) ProtoMessage() {}
+func (*SandboxIPResult) ProtoMessage() {}
 
-func (x *ScanSourceHostKeysRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[15]
+func (x *SandboxIPResult) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -964,95 +3966,69 @@ func (x *ScanSourceHostKeysRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ScanSourceHostKeysRequest.ProtoReflect.Descriptor instead.
-func (*ScanSourceHostKeysRequest) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{15}
-}
-
-// ScanSourceHostKeysResult holds the outcome of scanning a single source host's key.
-type ScanSourceHostKeysResult struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ScanSourceHostKeysResult) Reset() {
-	*x = ScanSourceHostKeysResult{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[16]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *ScanSourceHostKeysResult) String() string {
-	return protoimpl.X.MessageStringOf(x)
+// Deprecated: Use SandboxIPResult.ProtoReflect.Descriptor instead.
+func (*SandboxIPResult) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{60}
 }
 
-func (*ScanSourceHostKeysResult) ProtoMessage() {}
-
-func (x *ScanSourceHostKeysResult) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[16]
+func (x *SandboxIPResult) GetSandboxId() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.SandboxId
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use ScanSourceHostKeysResult.ProtoReflect.Descriptor instead.
-func (*ScanSourceHostKeysResult) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{16}
+func (x *SandboxIPResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-func (x *ScanSourceHostKeysResult) GetAddress() string {
+func (x *SandboxIPResult) GetIpAddress() string {
 	if x != nil {
-		return x.Address
+		return x.IpAddress
 	}
 	return ""
 }
 
-func (x *ScanSourceHostKeysResult) GetSuccess() bool {
+func (x *SandboxIPResult) GetPreviousIp() string {
 	if x != nil {
-		return x.Success
+		return x.PreviousIp
 	}
-	return false
+	return ""
 }
 
-func (x *ScanSourceHostKeysResult) GetError() string {
+func (x *SandboxIPResult) GetError() string {
 	if x != nil {
 		return x.Error
 	}
 	return ""
 }
 
-// ScanSourceHostKeysResponse contains the results of scanning all source host keys.
-type ScanSourceHostKeysResponse struct {
-	state         protoimpl.MessageState      `protogen:"open.v1"`
-	Results       []*ScanSourceHostKeysResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+// RefreshSandboxIPsResponse lists one result per sandbox refreshed.
+type RefreshSandboxIPsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SandboxIPResult     `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ScanSourceHostKeysResponse) Reset() {
-	*x = ScanSourceHostKeysResponse{}
-	mi := &file_deer_v1_daemon_proto_msgTypes[17]
+func (x *RefreshSandboxIPsResponse) Reset() {
+	*x = RefreshSandboxIPsResponse{}
+	mi := &file_deer_v1_daemon_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ScanSourceHostKeysResponse) String() string {
+func (x *RefreshSandboxIPsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ScanSourceHostKeysResponse) ProtoMessage() {}
+func (*RefreshSandboxIPsResponse) ProtoMessage() {}
 
-func (x *ScanSourceHostKeysResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_daemon_proto_msgTypes[17]
+func (x *RefreshSandboxIPsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_daemon_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1063,12 +4039,12 @@ func (x *ScanSourceHostKeysResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ScanSourceHostKeysResponse.ProtoReflect.Descriptor instead.
-func (*ScanSourceHostKeysResponse) Descriptor() ([]byte, []int) {
-	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use RefreshSandboxIPsResponse.ProtoReflect.Descriptor instead.
+func (*RefreshSandboxIPsResponse) Descriptor() ([]byte, []int) {
+	return file_deer_v1_daemon_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *ScanSourceHostKeysResponse) GetResults() []*ScanSourceHostKeysResult {
+func (x *RefreshSandboxIPsResponse) GetResults() []*SandboxIPResult {
 	if x != nil {
 		return x.Results
 	}
@@ -1082,7 +4058,13 @@ const file_deer_v1_daemon_proto_rawDesc = "" +
 	"\x14deer/v1/daemon.proto\x12\adeer.v1\x1a\x15deer/v1/sandbox.proto\x1a\x14deer/v1/source.proto\x1a\x12deer/v1/host.proto\"2\n" +
 	"\x11GetSandboxRequest\x12\x1d\n" +
 	"\n" +
-	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"\x81\x02\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"6\n" +
+	"\x13GetSandboxesRequest\x12\x1f\n" +
+	"\vsandbox_ids\x18\x01 \x03(\tR\n" +
+	"sandboxIds\"g\n" +
+	"\x14GetSandboxesResponse\x122\n" +
+	"\tsandboxes\x18\x01 \x03(\v2\x14.deer.v1.SandboxInfoR\tsandboxes\x12\x1b\n" +
+	"\tnot_found\x18\x02 \x03(\tR\bnotFound\"\xf8\x03\n" +
 	"\vSandboxInfo\x12\x1d\n" +
 	"\n" +
 	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x12\n" +
@@ -1096,11 +4078,209 @@ const file_deer_v1_daemon_proto_rawDesc = "" +
 	"\x05vcpus\x18\a \x01(\x05R\x05vcpus\x12\x1b\n" +
 	"\tmemory_mb\x18\b \x01(\x05R\bmemoryMb\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\t \x01(\tR\tcreatedAt\"\x16\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1a\n" +
+	"\bimported\x18\n" +
+	" \x01(\bR\bimported\x124\n" +
+	"\bactivity\x18\v \x01(\v2\x18.deer.v1.SandboxActivityR\bactivity\x12-\n" +
+	"\x04disk\x18\f \x01(\v2\x19.deer.v1.SandboxDiskUsageR\x04disk\x12\x1f\n" +
+	"\vmac_address\x18\r \x01(\tR\n" +
+	"macAddress\x12\x17\n" +
+	"\ahost_id\x18\x0e \x01(\tR\x06hostId\x12 \n" +
+	"\fidle_stop_at\x18\x0f \x01(\tR\n" +
+	"idleStopAt\x12\x18\n" +
+	"\aworkdir\x18\x10 \x01(\tR\aworkdir\"\x9a\x01\n" +
+	"\x10SandboxDiskUsage\x12\x1f\n" +
+	"\vtotal_bytes\x18\x01 \x01(\x03R\n" +
+	"totalBytes\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x02 \x01(\x03R\tusedBytes\x12'\n" +
+	"\x0favailable_bytes\x18\x03 \x01(\x03R\x0eavailableBytes\x12\x1d\n" +
+	"\n" +
+	"checked_at\x18\x04 \x01(\tR\tcheckedAt\"\xb3\x01\n" +
+	"\x0fSandboxActivity\x12(\n" +
+	"\x10last_activity_at\x18\x01 \x01(\tR\x0elastActivityAt\x12#\n" +
+	"\rcommand_count\x18\x02 \x01(\x05R\fcommandCount\x12'\n" +
+	"\x0ffailed_commands\x18\x03 \x01(\x05R\x0efailedCommands\x12(\n" +
+	"\x10total_runtime_ms\x18\x04 \x01(\x03R\x0etotalRuntimeMs\"^\n" +
+	"\x14ImportSandboxCommand\x12\x17\n" +
+	"\avm_name\x18\x01 \x01(\tR\x06vmName\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\"\xd7\x01\n" +
+	"\x12ForkSandboxCommand\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\tR\bsnapshot\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x14\n" +
+	"\x05vcpus\x18\x04 \x01(\x05R\x05vcpus\x12\x1b\n" +
+	"\tmemory_mb\x18\x05 \x01(\x05R\bmemoryMb\x12\x19\n" +
+	"\bagent_id\x18\x06 \x01(\tR\aagentId\x12$\n" +
+	"\x0essh_public_key\x18\a \x01(\tR\fsshPublicKey\"\xb0\x03\n" +
+	"\vSandboxPlan\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x17\n" +
+	"\ahost_id\x18\x03 \x01(\tR\x06hostId\x12\x1a\n" +
+	"\bhostname\x18\x04 \x01(\tR\bhostname\x12\x1b\n" +
+	"\tsource_vm\x18\x05 \x01(\tR\bsourceVm\x12\x1f\n" +
+	"\vsource_host\x18\x06 \x01(\tR\n" +
+	"sourceHost\x12(\n" +
+	"\x10source_host_type\x18\a \x01(\tR\x0esourceHostType\x12\x14\n" +
+	"\x05vcpus\x18\b \x01(\x05R\x05vcpus\x12\x1b\n" +
+	"\tmemory_mb\x18\t \x01(\x05R\bmemoryMb\x12%\n" +
+	"\x0eavailable_cpus\x18\n" +
+	" \x01(\x05R\ravailableCpus\x12.\n" +
+	"\x13available_memory_mb\x18\v \x01(\x03R\x11availableMemoryMb\x12\x1a\n" +
+	"\bwarnings\x18\f \x03(\tR\bwarnings\x12+\n" +
+	"\x11requires_approval\x18\r \x01(\bR\x10requiresApproval\"\x8d\x01\n" +
+	"\x14ResizeSandboxCommand\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x14\n" +
+	"\x05vcpus\x18\x02 \x01(\x05R\x05vcpus\x12\x1b\n" +
+	"\tmemory_mb\x18\x03 \x01(\x05R\bmemoryMb\x12#\n" +
+	"\rleave_stopped\x18\x04 \x01(\bR\fleaveStopped\"S\n" +
+	"\x18SetSandboxWorkdirRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x18\n" +
+	"\aworkdir\x18\x02 \x01(\tR\aworkdir\"5\n" +
+	"\x14UnlockSandboxRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"M\n" +
+	"\x0fSandboxUnlocked\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1b\n" +
+	"\tlocked_by\x18\x02 \x01(\tR\blockedBy\"7\n" +
+	"\x16GetSandboxStatsRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"\xec\x02\n" +
+	"\fSandboxStats\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1d\n" +
+	"\n" +
+	"sampled_at\x18\x03 \x01(\tR\tsampledAt\x12\x14\n" +
+	"\x05vcpus\x18\x04 \x01(\x05R\x05vcpus\x12\x1e\n" +
+	"\vcpu_time_ms\x18\x05 \x01(\x03R\tcpuTimeMs\x12\x1f\n" +
+	"\vcpu_percent\x18\x06 \x01(\x01R\n" +
+	"cpuPercent\x12!\n" +
+	"\fmemory_bytes\x18\a \x01(\x03R\vmemoryBytes\x12,\n" +
+	"\x12memory_limit_bytes\x18\b \x01(\x03R\x10memoryLimitBytes\x120\n" +
+	"\x14disk_allocated_bytes\x18\t \x01(\x03R\x12diskAllocatedBytes\x12.\n" +
+	"\x13disk_capacity_bytes\x18\n" +
+	" \x01(\x03R\x11diskCapacityBytes\"\x16\n" +
 	"\x14ListSandboxesRequest\"a\n" +
 	"\x15ListSandboxesResponse\x122\n" +
 	"\tsandboxes\x18\x01 \x03(\v2\x14.deer.v1.SandboxInfoR\tsandboxes\x12\x14\n" +
-	"\x05count\x18\x02 \x01(\x05R\x05count\"\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\x82\x01\n" +
+	"\x1aListSandboxCommandsRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x19\n" +
+	"\bafter_id\x18\x04 \x01(\tR\aafterId\"Q\n" +
+	"\x1bListSandboxCommandsResponse\x122\n" +
+	"\bcommands\x18\x01 \x03(\v2\x16.deer.v1.CommandRecordR\bcommands\"\xb6\x01\n" +
+	"\x16RunCommandBatchCommand\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1a\n" +
+	"\bcommands\x18\x02 \x03(\tR\bcommands\x12'\n" +
+	"\x0ftimeout_seconds\x18\x03 \x01(\x05R\x0etimeoutSeconds\x12\"\n" +
+	"\rstop_on_error\x18\x04 \x01(\bR\vstopOnError\x12\x14\n" +
+	"\x05actor\x18\x05 \x01(\tR\x05actor\"_\n" +
+	"\x15RunCommandBatchResult\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.deer.v1.CommandResultR\aresults\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\";\n" +
+	"\x1aGetSandboxSSHTargetRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"\xeb\x01\n" +
+	"\x10SandboxSSHTarget\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x02 \x01(\tR\tipAddress\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12(\n" +
+	"\x10private_key_path\x18\x04 \x01(\tR\x0eprivateKeyPath\x12)\n" +
+	"\x10certificate_path\x18\x05 \x01(\tR\x0fcertificatePath\x12(\n" +
+	"\x10host_key_options\x18\x06 \x03(\tR\x0ehostKeyOptions\"B\n" +
+	"!RenewSandboxSSHCredentialsRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"\xc8\x01\n" +
+	"\x15SandboxSSHCredentials\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12(\n" +
+	"\x10private_key_path\x18\x03 \x01(\tR\x0eprivateKeyPath\x12)\n" +
+	"\x10certificate_path\x18\x04 \x01(\tR\x0fcertificatePath\x12\x1f\n" +
+	"\vvalid_until\x18\x05 \x01(\tR\n" +
+	"validUntil\"{\n" +
+	"\x14DiffSnapshotsRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12#\n" +
+	"\rfrom_snapshot\x18\x02 \x01(\tR\ffromSnapshot\x12\x1f\n" +
+	"\vto_snapshot\x18\x03 \x01(\tR\n" +
+	"toSnapshot\"\xbc\x02\n" +
+	"\fSnapshotDiff\x12\x1f\n" +
+	"\vfiles_added\x18\x01 \x03(\tR\n" +
+	"filesAdded\x12%\n" +
+	"\x0efiles_modified\x18\x02 \x03(\tR\rfilesModified\x12#\n" +
+	"\rfiles_removed\x18\x03 \x03(\tR\ffilesRemoved\x129\n" +
+	"\fcommands_run\x18\x04 \x03(\v2\x16.deer.v1.CommandRecordR\vcommandsRun\x12?\n" +
+	"\x0epackages_added\x18\x05 \x03(\v2\x18.deer.v1.SnapshotPackageR\rpackagesAdded\x12C\n" +
+	"\x10packages_removed\x18\x06 \x03(\v2\x18.deer.v1.SnapshotPackageR\x0fpackagesRemoved\"\x86\x01\n" +
+	"\x1aRestoreSnapshotFileRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\tR\bsnapshot\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x19\n" +
+	"\bin_place\x18\x04 \x01(\bR\ainPlace\"\x99\x01\n" +
+	"\x12SnapshotFileResult\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\tR\bsnapshot\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\fR\acontent\x12\x1a\n" +
+	"\brestored\x18\x05 \x01(\bR\brestored\"5\n" +
+	"\x14ListSnapshotsRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"w\n" +
+	"\fSnapshotInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x10\n" +
+	"\x03ref\x18\x04 \x01(\tR\x03ref\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\tR\tcreatedAt\"L\n" +
+	"\x15ListSnapshotsResponse\x123\n" +
+	"\tsnapshots\x18\x01 \x03(\v2\x15.deer.v1.SnapshotInfoR\tsnapshots\"R\n" +
+	"\x15DeleteSnapshotRequest\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\tR\bsnapshot\"\x9c\x01\n" +
+	"\x0fSnapshotDeleted\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x1a\n" +
+	"\bsnapshot\x18\x02 \x01(\tR\bsnapshot\x12%\n" +
+	"\x0erecord_deleted\x18\x03 \x01(\bR\rrecordDeleted\x12'\n" +
+	"\x0fbackend_deleted\x18\x04 \x01(\bR\x0ebackendDeleted\"?\n" +
+	"\x0fSnapshotPackage\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"V\n" +
+	"\x15DiffSnapshotsProgress\x12\x12\n" +
+	"\x04step\x18\x01 \x01(\tR\x04step\x12)\n" +
+	"\x04diff\x18\x02 \x01(\v2\x15.deer.v1.SnapshotDiffR\x04diff\"\x91\x02\n" +
+	"\rCommandRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acommand\x18\x02 \x01(\tR\acommand\x12\x16\n" +
+	"\x06stdout\x18\x03 \x01(\tR\x06stdout\x12\x16\n" +
+	"\x06stderr\x18\x04 \x01(\tR\x06stderr\x12\x1b\n" +
+	"\texit_code\x18\x05 \x01(\x05R\bexitCode\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x03R\n" +
+	"durationMs\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\a \x01(\tR\tstartedAt\x12\x19\n" +
+	"\bended_at\x18\b \x01(\tR\aendedAt\x12\x14\n" +
+	"\x05actor\x18\t \x01(\tR\x05actor\x12\x18\n" +
+	"\aworkdir\x18\n" +
+	" \x01(\tR\aworkdir\"\x14\n" +
 	"\x12GetHostInfoRequest\"\x86\x03\n" +
 	"\x10HostInfoResponse\x12\x17\n" +
 	"\ahost_id\x18\x01 \x01(\tR\x06hostId\x12\x1a\n" +
@@ -1122,7 +4302,23 @@ const file_deer_v1_daemon_proto_rawDesc = "" +
 	"\bssh_port\x18\x03 \x01(\x05R\asshPort\"\x0f\n" +
 	"\rHealthRequest\"(\n" +
 	"\x0eHealthResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"D\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\x12\n" +
+	"\x10GetStatusRequest\"\xd7\x02\n" +
+	"\x14DaemonStatusResponse\x12\x17\n" +
+	"\ahost_id\x18\x01 \x01(\tR\x06hostId\x12\x1a\n" +
+	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1a\n" +
+	"\bprovider\x18\x04 \x01(\tR\bprovider\x12)\n" +
+	"\x10active_sandboxes\x18\x05 \x01(\x05R\x0factiveSandboxes\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\tR\tstartedAt\x12%\n" +
+	"\x0euptime_seconds\x18\a \x01(\x03R\ruptimeSeconds\x12(\n" +
+	"\x10janitor_last_run\x18\b \x01(\tR\x0ejanitorLastRun\x129\n" +
+	"\rrecent_errors\x18\t \x03(\v2\x14.deer.v1.DaemonErrorR\frecentErrors\"S\n" +
+	"\vDaemonError\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\tR\x04time\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"D\n" +
 	"\x14DiscoverHostsCommand\x12,\n" +
 	"\x12ssh_config_content\x18\x01 \x01(\tR\x10sshConfigContent\"\x95\x02\n" +
 	"\x0eDiscoveredHost\x12\x12\n" +
@@ -1156,16 +4352,56 @@ const file_deer_v1_daemon_proto_rawDesc = "" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x14\n" +
 	"\x05error\x18\x03 \x01(\tR\x05error\"Y\n" +
 	"\x1aScanSourceHostKeysResponse\x12;\n" +
-	"\aresults\x18\x01 \x03(\v2!.deer.v1.ScanSourceHostKeysResultR\aresults2\xe3\x0f\n" +
+	"\aresults\x18\x01 \x03(\v2!.deer.v1.ScanSourceHostKeysResultR\aresults\"#\n" +
+	"\x12ListOrphansRequestJ\x04\b\x01\x10\x02R\areclaim\"\x96\x01\n" +
+	"\x0eOrphanResource\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x1c\n" +
+	"\treclaimed\x18\x05 \x01(\bR\treclaimed\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\x82\x01\n" +
+	"\x13ListOrphansResponse\x121\n" +
+	"\aorphans\x18\x01 \x03(\v2\x17.deer.v1.OrphanResourceR\aorphans\x12\x1b\n" +
+	"\tpool_size\x18\x02 \x01(\x05R\bpoolSize\x12\x1b\n" +
+	"\tpool_free\x18\x03 \x01(\x05R\bpoolFree\")\n" +
+	"\x15ReclaimOrphansRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"\x85\x01\n" +
+	"\x16ReclaimOrphansResponse\x121\n" +
+	"\aorphans\x18\x01 \x03(\v2\x17.deer.v1.OrphanResourceR\aorphans\x12\x1b\n" +
+	"\tpool_size\x18\x02 \x01(\x05R\bpoolSize\x12\x1b\n" +
+	"\tpool_free\x18\x03 \x01(\x05R\bpoolFree\";\n" +
+	"\x18RefreshSandboxIPsRequest\x12\x1f\n" +
+	"\vsandbox_ids\x18\x01 \x03(\tR\n" +
+	"sandboxIds\"\x9a\x01\n" +
+	"\x0fSandboxIPResult\x12\x1d\n" +
+	"\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x03 \x01(\tR\tipAddress\x12\x1f\n" +
+	"\vprevious_ip\x18\x04 \x01(\tR\n" +
+	"previousIp\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"O\n" +
+	"\x19RefreshSandboxIPsResponse\x122\n" +
+	"\aresults\x18\x01 \x03(\v2\x18.deer.v1.SandboxIPResultR\aresults2\xed\x1c\n" +
 	"\rDaemonService\x12G\n" +
 	"\rCreateSandbox\x12\x1d.deer.v1.CreateSandboxCommand\x1a\x17.deer.v1.SandboxCreated\x12P\n" +
-	"\x13CreateSandboxStream\x12\x1d.deer.v1.CreateSandboxCommand\x1a\x18.deer.v1.SandboxProgress0\x01\x12>\n" +
+	"\x13CreateSandboxStream\x12\x1d.deer.v1.CreateSandboxCommand\x1a\x18.deer.v1.SandboxProgress0\x01\x12B\n" +
+	"\vPlanSandbox\x12\x1d.deer.v1.CreateSandboxCommand\x1a\x14.deer.v1.SandboxPlan\x12>\n" +
 	"\n" +
-	"GetSandbox\x12\x1a.deer.v1.GetSandboxRequest\x1a\x14.deer.v1.SandboxInfo\x12N\n" +
+	"GetSandbox\x12\x1a.deer.v1.GetSandboxRequest\x1a\x14.deer.v1.SandboxInfo\x12K\n" +
+	"\fGetSandboxes\x12\x1c.deer.v1.GetSandboxesRequest\x1a\x1d.deer.v1.GetSandboxesResponse\x12N\n" +
 	"\rListSandboxes\x12\x1d.deer.v1.ListSandboxesRequest\x1a\x1e.deer.v1.ListSandboxesResponse\x12K\n" +
 	"\x0eDestroySandbox\x12\x1e.deer.v1.DestroySandboxCommand\x1a\x19.deer.v1.SandboxDestroyed\x12E\n" +
 	"\fStartSandbox\x12\x1c.deer.v1.StartSandboxCommand\x1a\x17.deer.v1.SandboxStarted\x12C\n" +
-	"\vStopSandbox\x12\x1b.deer.v1.StopSandboxCommand\x1a\x17.deer.v1.SandboxStopped\x12f\n" +
+	"\vStopSandbox\x12\x1b.deer.v1.StopSandboxCommand\x1a\x17.deer.v1.SandboxStopped\x12D\n" +
+	"\rImportSandbox\x12\x1d.deer.v1.ImportSandboxCommand\x1a\x14.deer.v1.SandboxInfo\x12C\n" +
+	"\vForkSandbox\x12\x1b.deer.v1.ForkSandboxCommand\x1a\x17.deer.v1.SandboxCreated\x12D\n" +
+	"\rResizeSandbox\x12\x1d.deer.v1.ResizeSandboxCommand\x1a\x14.deer.v1.SandboxInfo\x12L\n" +
+	"\x11SetSandboxWorkdir\x12!.deer.v1.SetSandboxWorkdirRequest\x1a\x14.deer.v1.SandboxInfo\x12H\n" +
+	"\rUnlockSandbox\x12\x1d.deer.v1.UnlockSandboxRequest\x1a\x18.deer.v1.SandboxUnlocked\x12I\n" +
+	"\x0fGetSandboxStats\x12\x1f.deer.v1.GetSandboxStatsRequest\x1a\x15.deer.v1.SandboxStats\x12f\n" +
 	"\x15ListSandboxKafkaStubs\x12%.deer.v1.ListSandboxKafkaStubsCommand\x1a&.deer.v1.ListSandboxKafkaStubsResponse\x12Y\n" +
 	"\x13GetSandboxKafkaStub\x12#.deer.v1.GetSandboxKafkaStubCommand\x1a\x1d.deer.v1.SandboxKafkaStubInfo\x12]\n" +
 	"\x15StartSandboxKafkaStub\x12%.deer.v1.StartSandboxKafkaStubCommand\x1a\x1d.deer.v1.SandboxKafkaStubInfo\x12[\n" +
@@ -1173,18 +4409,31 @@ const file_deer_v1_daemon_proto_rawDesc = "" +
 	"\x17RestartSandboxKafkaStub\x12'.deer.v1.RestartSandboxKafkaStubCommand\x1a\x1d.deer.v1.SandboxKafkaStubInfo\x12`\n" +
 	"\x15GetKafkaCaptureStatus\x12\".deer.v1.KafkaCaptureStatusRequest\x1a#.deer.v1.KafkaCaptureStatusResponse\x12@\n" +
 	"\n" +
-	"RunCommand\x12\x1a.deer.v1.RunCommandCommand\x1a\x16.deer.v1.CommandResult\x12D\n" +
-	"\x0eCreateSnapshot\x12\x18.deer.v1.SnapshotCommand\x1a\x18.deer.v1.SnapshotCreated\x12F\n" +
+	"RunCommand\x12\x1a.deer.v1.RunCommandCommand\x1a\x16.deer.v1.CommandResult\x12E\n" +
+	"\rStreamCommand\x12\x1a.deer.v1.RunCommandCommand\x1a\x16.deer.v1.CommandOutput0\x01\x12`\n" +
+	"\x13ListSandboxCommands\x12#.deer.v1.ListSandboxCommandsRequest\x1a$.deer.v1.ListSandboxCommandsResponse\x12R\n" +
+	"\x0fRunCommandBatch\x12\x1f.deer.v1.RunCommandBatchCommand\x1a\x1e.deer.v1.RunCommandBatchResult\x12U\n" +
+	"\x13GetSandboxSSHTarget\x12#.deer.v1.GetSandboxSSHTargetRequest\x1a\x19.deer.v1.SandboxSSHTarget\x12h\n" +
+	"\x1aRenewSandboxSSHCredentials\x12*.deer.v1.RenewSandboxSSHCredentialsRequest\x1a\x1e.deer.v1.SandboxSSHCredentials\x12D\n" +
+	"\x0eCreateSnapshot\x12\x18.deer.v1.SnapshotCommand\x1a\x18.deer.v1.SnapshotCreated\x12P\n" +
+	"\rDiffSnapshots\x12\x1d.deer.v1.DiffSnapshotsRequest\x1a\x1e.deer.v1.DiffSnapshotsProgress0\x01\x12W\n" +
+	"\x13RestoreSnapshotFile\x12#.deer.v1.RestoreSnapshotFileRequest\x1a\x1b.deer.v1.SnapshotFileResult\x12N\n" +
+	"\rListSnapshots\x12\x1d.deer.v1.ListSnapshotsRequest\x1a\x1e.deer.v1.ListSnapshotsResponse\x12J\n" +
+	"\x0eDeleteSnapshot\x12\x1e.deer.v1.DeleteSnapshotRequest\x1a\x18.deer.v1.SnapshotDeleted\x12F\n" +
 	"\rListSourceVMs\x12\x1d.deer.v1.ListSourceVMsCommand\x1a\x16.deer.v1.SourceVMsList\x12Q\n" +
 	"\x10ValidateSourceVM\x12 .deer.v1.ValidateSourceVMCommand\x1a\x1b.deer.v1.SourceVMValidation\x12M\n" +
 	"\x0fPrepareSourceVM\x12\x1f.deer.v1.PrepareSourceVMCommand\x1a\x19.deer.v1.SourceVMPrepared\x12R\n" +
 	"\x10RunSourceCommand\x12 .deer.v1.RunSourceCommandCommand\x1a\x1c.deer.v1.SourceCommandResult\x12K\n" +
 	"\x0eReadSourceFile\x12\x1e.deer.v1.ReadSourceFileCommand\x1a\x19.deer.v1.SourceFileResult\x12E\n" +
 	"\vGetHostInfo\x12\x1b.deer.v1.GetHostInfoRequest\x1a\x19.deer.v1.HostInfoResponse\x129\n" +
-	"\x06Health\x12\x16.deer.v1.HealthRequest\x1a\x17.deer.v1.HealthResponse\x12L\n" +
+	"\x06Health\x12\x16.deer.v1.HealthRequest\x1a\x17.deer.v1.HealthResponse\x12E\n" +
+	"\tGetStatus\x12\x19.deer.v1.GetStatusRequest\x1a\x1d.deer.v1.DaemonStatusResponse\x12L\n" +
 	"\rDiscoverHosts\x12\x1d.deer.v1.DiscoverHostsCommand\x1a\x1c.deer.v1.DiscoverHostsResult\x12H\n" +
 	"\vDoctorCheck\x12\x1b.deer.v1.DoctorCheckRequest\x1a\x1c.deer.v1.DoctorCheckResponse\x12]\n" +
-	"\x12ScanSourceHostKeys\x12\".deer.v1.ScanSourceHostKeysRequest\x1a#.deer.v1.ScanSourceHostKeysResponseB9Z7github.com/aspectrr/deer.sh/proto/gen/go/deer/v1;deerv1b\x06proto3"
+	"\x12ScanSourceHostKeys\x12\".deer.v1.ScanSourceHostKeysRequest\x1a#.deer.v1.ScanSourceHostKeysResponse\x12H\n" +
+	"\vListOrphans\x12\x1b.deer.v1.ListOrphansRequest\x1a\x1c.deer.v1.ListOrphansResponse\x12Q\n" +
+	"\x0eReclaimOrphans\x12\x1e.deer.v1.ReclaimOrphansRequest\x1a\x1f.deer.v1.ReclaimOrphansResponse\x12Z\n" +
+	"\x11RefreshSandboxIPs\x12!.deer.v1.RefreshSandboxIPsRequest\x1a\".deer.v1.RefreshSandboxIPsResponseB9Z7github.com/aspectrr/deer.sh/proto/gen/go/deer/v1;deerv1b\x06proto3"
 
 var (
 	file_deer_v1_daemon_proto_rawDescOnce sync.Once
@@ -1198,120 +4447,221 @@ func file_deer_v1_daemon_proto_rawDescGZIP() []byte {
 	return file_deer_v1_daemon_proto_rawDescData
 }
 
-var file_deer_v1_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_deer_v1_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 62)
 var file_deer_v1_daemon_proto_goTypes = []any{
-	(*GetSandboxRequest)(nil),              // 0: deer.v1.GetSandboxRequest
-	(*SandboxInfo)(nil),                    // 1: deer.v1.SandboxInfo
-	(*ListSandboxesRequest)(nil),           // 2: deer.v1.ListSandboxesRequest
-	(*ListSandboxesResponse)(nil),          // 3: deer.v1.ListSandboxesResponse
-	(*GetHostInfoRequest)(nil),             // 4: deer.v1.GetHostInfoRequest
-	(*HostInfoResponse)(nil),               // 5: deer.v1.HostInfoResponse
-	(*SourceHostInfo)(nil),                 // 6: deer.v1.SourceHostInfo
-	(*HealthRequest)(nil),                  // 7: deer.v1.HealthRequest
-	(*HealthResponse)(nil),                 // 8: deer.v1.HealthResponse
-	(*DiscoverHostsCommand)(nil),           // 9: deer.v1.DiscoverHostsCommand
-	(*DiscoveredHost)(nil),                 // 10: deer.v1.DiscoveredHost
-	(*DiscoverHostsResult)(nil),            // 11: deer.v1.DiscoverHostsResult
-	(*DoctorCheckRequest)(nil),             // 12: deer.v1.DoctorCheckRequest
-	(*DoctorCheckResult)(nil),              // 13: deer.v1.DoctorCheckResult
-	(*DoctorCheckResponse)(nil),            // 14: deer.v1.DoctorCheckResponse
-	(*ScanSourceHostKeysRequest)(nil),      // 15: deer.v1.ScanSourceHostKeysRequest
-	(*ScanSourceHostKeysResult)(nil),       // 16: deer.v1.ScanSourceHostKeysResult
-	(*ScanSourceHostKeysResponse)(nil),     // 17: deer.v1.ScanSourceHostKeysResponse
-	(*CreateSandboxCommand)(nil),           // 18: deer.v1.CreateSandboxCommand
-	(*DestroySandboxCommand)(nil),          // 19: deer.v1.DestroySandboxCommand
-	(*StartSandboxCommand)(nil),            // 20: deer.v1.StartSandboxCommand
-	(*StopSandboxCommand)(nil),             // 21: deer.v1.StopSandboxCommand
-	(*ListSandboxKafkaStubsCommand)(nil),   // 22: deer.v1.ListSandboxKafkaStubsCommand
-	(*GetSandboxKafkaStubCommand)(nil),     // 23: deer.v1.GetSandboxKafkaStubCommand
-	(*StartSandboxKafkaStubCommand)(nil),   // 24: deer.v1.StartSandboxKafkaStubCommand
-	(*StopSandboxKafkaStubCommand)(nil),    // 25: deer.v1.StopSandboxKafkaStubCommand
-	(*RestartSandboxKafkaStubCommand)(nil), // 26: deer.v1.RestartSandboxKafkaStubCommand
-	(*KafkaCaptureStatusRequest)(nil),      // 27: deer.v1.KafkaCaptureStatusRequest
-	(*RunCommandCommand)(nil),              // 28: deer.v1.RunCommandCommand
-	(*SnapshotCommand)(nil),                // 29: deer.v1.SnapshotCommand
-	(*ListSourceVMsCommand)(nil),           // 30: deer.v1.ListSourceVMsCommand
-	(*ValidateSourceVMCommand)(nil),        // 31: deer.v1.ValidateSourceVMCommand
-	(*PrepareSourceVMCommand)(nil),         // 32: deer.v1.PrepareSourceVMCommand
-	(*RunSourceCommandCommand)(nil),        // 33: deer.v1.RunSourceCommandCommand
-	(*ReadSourceFileCommand)(nil),          // 34: deer.v1.ReadSourceFileCommand
-	(*SandboxCreated)(nil),                 // 35: deer.v1.SandboxCreated
-	(*SandboxProgress)(nil),                // 36: deer.v1.SandboxProgress
-	(*SandboxDestroyed)(nil),               // 37: deer.v1.SandboxDestroyed
-	(*SandboxStarted)(nil),                 // 38: deer.v1.SandboxStarted
-	(*SandboxStopped)(nil),                 // 39: deer.v1.SandboxStopped
-	(*ListSandboxKafkaStubsResponse)(nil),  // 40: deer.v1.ListSandboxKafkaStubsResponse
-	(*SandboxKafkaStubInfo)(nil),           // 41: deer.v1.SandboxKafkaStubInfo
-	(*KafkaCaptureStatusResponse)(nil),     // 42: deer.v1.KafkaCaptureStatusResponse
-	(*CommandResult)(nil),                  // 43: deer.v1.CommandResult
-	(*SnapshotCreated)(nil),                // 44: deer.v1.SnapshotCreated
-	(*SourceVMsList)(nil),                  // 45: deer.v1.SourceVMsList
-	(*SourceVMValidation)(nil),             // 46: deer.v1.SourceVMValidation
-	(*SourceVMPrepared)(nil),               // 47: deer.v1.SourceVMPrepared
-	(*SourceCommandResult)(nil),            // 48: deer.v1.SourceCommandResult
-	(*SourceFileResult)(nil),               // 49: deer.v1.SourceFileResult
+	(*GetSandboxRequest)(nil),                 // 0: deer.v1.GetSandboxRequest
+	(*GetSandboxesRequest)(nil),               // 1: deer.v1.GetSandboxesRequest
+	(*GetSandboxesResponse)(nil),              // 2: deer.v1.GetSandboxesResponse
+	(*SandboxInfo)(nil),                       // 3: deer.v1.SandboxInfo
+	(*SandboxDiskUsage)(nil),                  // 4: deer.v1.SandboxDiskUsage
+	(*SandboxActivity)(nil),                   // 5: deer.v1.SandboxActivity
+	(*ImportSandboxCommand)(nil),              // 6: deer.v1.ImportSandboxCommand
+	(*ForkSandboxCommand)(nil),                // 7: deer.v1.ForkSandboxCommand
+	(*SandboxPlan)(nil),                       // 8: deer.v1.SandboxPlan
+	(*ResizeSandboxCommand)(nil),              // 9: deer.v1.ResizeSandboxCommand
+	(*SetSandboxWorkdirRequest)(nil),          // 10: deer.v1.SetSandboxWorkdirRequest
+	(*UnlockSandboxRequest)(nil),              // 11: deer.v1.UnlockSandboxRequest
+	(*SandboxUnlocked)(nil),                   // 12: deer.v1.SandboxUnlocked
+	(*GetSandboxStatsRequest)(nil),            // 13: deer.v1.GetSandboxStatsRequest
+	(*SandboxStats)(nil),                      // 14: deer.v1.SandboxStats
+	(*ListSandboxesRequest)(nil),              // 15: deer.v1.ListSandboxesRequest
+	(*ListSandboxesResponse)(nil),             // 16: deer.v1.ListSandboxesResponse
+	(*ListSandboxCommandsRequest)(nil),        // 17: deer.v1.ListSandboxCommandsRequest
+	(*ListSandboxCommandsResponse)(nil),       // 18: deer.v1.ListSandboxCommandsResponse
+	(*RunCommandBatchCommand)(nil),            // 19: deer.v1.RunCommandBatchCommand
+	(*RunCommandBatchResult)(nil),             // 20: deer.v1.RunCommandBatchResult
+	(*GetSandboxSSHTargetRequest)(nil),        // 21: deer.v1.GetSandboxSSHTargetRequest
+	(*SandboxSSHTarget)(nil),                  // 22: deer.v1.SandboxSSHTarget
+	(*RenewSandboxSSHCredentialsRequest)(nil), // 23: deer.v1.RenewSandboxSSHCredentialsRequest
+	(*SandboxSSHCredentials)(nil),             // 24: deer.v1.SandboxSSHCredentials
+	(*DiffSnapshotsRequest)(nil),              // 25: deer.v1.DiffSnapshotsRequest
+	(*SnapshotDiff)(nil),                      // 26: deer.v1.SnapshotDiff
+	(*RestoreSnapshotFileRequest)(nil),        // 27: deer.v1.RestoreSnapshotFileRequest
+	(*SnapshotFileResult)(nil),                // 28: deer.v1.SnapshotFileResult
+	(*ListSnapshotsRequest)(nil),              // 29: deer.v1.ListSnapshotsRequest
+	(*SnapshotInfo)(nil),                      // 30: deer.v1.SnapshotInfo
+	(*ListSnapshotsResponse)(nil),             // 31: deer.v1.ListSnapshotsResponse
+	(*DeleteSnapshotRequest)(nil),             // 32: deer.v1.DeleteSnapshotRequest
+	(*SnapshotDeleted)(nil),                   // 33: deer.v1.SnapshotDeleted
+	(*SnapshotPackage)(nil),                   // 34: deer.v1.SnapshotPackage
+	(*DiffSnapshotsProgress)(nil),             // 35: deer.v1.DiffSnapshotsProgress
+	(*CommandRecord)(nil),                     // 36: deer.v1.CommandRecord
+	(*GetHostInfoRequest)(nil),                // 37: deer.v1.GetHostInfoRequest
+	(*HostInfoResponse)(nil),                  // 38: deer.v1.HostInfoResponse
+	(*SourceHostInfo)(nil),                    // 39: deer.v1.SourceHostInfo
+	(*HealthRequest)(nil),                     // 40: deer.v1.HealthRequest
+	(*HealthResponse)(nil),                    // 41: deer.v1.HealthResponse
+	(*GetStatusRequest)(nil),                  // 42: deer.v1.GetStatusRequest
+	(*DaemonStatusResponse)(nil),              // 43: deer.v1.DaemonStatusResponse
+	(*DaemonError)(nil),                       // 44: deer.v1.DaemonError
+	(*DiscoverHostsCommand)(nil),              // 45: deer.v1.DiscoverHostsCommand
+	(*DiscoveredHost)(nil),                    // 46: deer.v1.DiscoveredHost
+	(*DiscoverHostsResult)(nil),               // 47: deer.v1.DiscoverHostsResult
+	(*DoctorCheckRequest)(nil),                // 48: deer.v1.DoctorCheckRequest
+	(*DoctorCheckResult)(nil),                 // 49: deer.v1.DoctorCheckResult
+	(*DoctorCheckResponse)(nil),               // 50: deer.v1.DoctorCheckResponse
+	(*ScanSourceHostKeysRequest)(nil),         // 51: deer.v1.ScanSourceHostKeysRequest
+	(*ScanSourceHostKeysResult)(nil),          // 52: deer.v1.ScanSourceHostKeysResult
+	(*ScanSourceHostKeysResponse)(nil),        // 53: deer.v1.ScanSourceHostKeysResponse
+	(*ListOrphansRequest)(nil),                // 54: deer.v1.ListOrphansRequest
+	(*OrphanResource)(nil),                    // 55: deer.v1.OrphanResource
+	(*ListOrphansResponse)(nil),               // 56: deer.v1.ListOrphansResponse
+	(*ReclaimOrphansRequest)(nil),             // 57: deer.v1.ReclaimOrphansRequest
+	(*ReclaimOrphansResponse)(nil),            // 58: deer.v1.ReclaimOrphansResponse
+	(*RefreshSandboxIPsRequest)(nil),          // 59: deer.v1.RefreshSandboxIPsRequest
+	(*SandboxIPResult)(nil),                   // 60: deer.v1.SandboxIPResult
+	(*RefreshSandboxIPsResponse)(nil),         // 61: deer.v1.RefreshSandboxIPsResponse
+	(*CommandResult)(nil),                     // 62: deer.v1.CommandResult
+	(*CreateSandboxCommand)(nil),              // 63: deer.v1.CreateSandboxCommand
+	(*DestroySandboxCommand)(nil),             // 64: deer.v1.DestroySandboxCommand
+	(*StartSandboxCommand)(nil),               // 65: deer.v1.StartSandboxCommand
+	(*StopSandboxCommand)(nil),                // 66: deer.v1.StopSandboxCommand
+	(*ListSandboxKafkaStubsCommand)(nil),      // 67: deer.v1.ListSandboxKafkaStubsCommand
+	(*GetSandboxKafkaStubCommand)(nil),        // 68: deer.v1.GetSandboxKafkaStubCommand
+	(*StartSandboxKafkaStubCommand)(nil),      // 69: deer.v1.StartSandboxKafkaStubCommand
+	(*StopSandboxKafkaStubCommand)(nil),       // 70: deer.v1.StopSandboxKafkaStubCommand
+	(*RestartSandboxKafkaStubCommand)(nil),    // 71: deer.v1.RestartSandboxKafkaStubCommand
+	(*KafkaCaptureStatusRequest)(nil),         // 72: deer.v1.KafkaCaptureStatusRequest
+	(*RunCommandCommand)(nil),                 // 73: deer.v1.RunCommandCommand
+	(*SnapshotCommand)(nil),                   // 74: deer.v1.SnapshotCommand
+	(*ListSourceVMsCommand)(nil),              // 75: deer.v1.ListSourceVMsCommand
+	(*ValidateSourceVMCommand)(nil),           // 76: deer.v1.ValidateSourceVMCommand
+	(*PrepareSourceVMCommand)(nil),            // 77: deer.v1.PrepareSourceVMCommand
+	(*RunSourceCommandCommand)(nil),           // 78: deer.v1.RunSourceCommandCommand
+	(*ReadSourceFileCommand)(nil),             // 79: deer.v1.ReadSourceFileCommand
+	(*SandboxCreated)(nil),                    // 80: deer.v1.SandboxCreated
+	(*SandboxProgress)(nil),                   // 81: deer.v1.SandboxProgress
+	(*SandboxDestroyed)(nil),                  // 82: deer.v1.SandboxDestroyed
+	(*SandboxStarted)(nil),                    // 83: deer.v1.SandboxStarted
+	(*SandboxStopped)(nil),                    // 84: deer.v1.SandboxStopped
+	(*ListSandboxKafkaStubsResponse)(nil),     // 85: deer.v1.ListSandboxKafkaStubsResponse
+	(*SandboxKafkaStubInfo)(nil),              // 86: deer.v1.SandboxKafkaStubInfo
+	(*KafkaCaptureStatusResponse)(nil),        // 87: deer.v1.KafkaCaptureStatusResponse
+	(*CommandOutput)(nil),                     // 88: deer.v1.CommandOutput
+	(*SnapshotCreated)(nil),                   // 89: deer.v1.SnapshotCreated
+	(*SourceVMsList)(nil),                     // 90: deer.v1.SourceVMsList
+	(*SourceVMValidation)(nil),                // 91: deer.v1.SourceVMValidation
+	(*SourceVMPrepared)(nil),                  // 92: deer.v1.SourceVMPrepared
+	(*SourceCommandResult)(nil),               // 93: deer.v1.SourceCommandResult
+	(*SourceFileResult)(nil),                  // 94: deer.v1.SourceFileResult
 }
 var file_deer_v1_daemon_proto_depIdxs = []int32{
-	1,  // 0: deer.v1.ListSandboxesResponse.sandboxes:type_name -> deer.v1.SandboxInfo
-	6,  // 1: deer.v1.HostInfoResponse.source_hosts:type_name -> deer.v1.SourceHostInfo
-	10, // 2: deer.v1.DiscoverHostsResult.hosts:type_name -> deer.v1.DiscoveredHost
-	13, // 3: deer.v1.DoctorCheckResponse.results:type_name -> deer.v1.DoctorCheckResult
-	16, // 4: deer.v1.ScanSourceHostKeysResponse.results:type_name -> deer.v1.ScanSourceHostKeysResult
-	18, // 5: deer.v1.DaemonService.CreateSandbox:input_type -> deer.v1.CreateSandboxCommand
-	18, // 6: deer.v1.DaemonService.CreateSandboxStream:input_type -> deer.v1.CreateSandboxCommand
-	0,  // 7: deer.v1.DaemonService.GetSandbox:input_type -> deer.v1.GetSandboxRequest
-	2,  // 8: deer.v1.DaemonService.ListSandboxes:input_type -> deer.v1.ListSandboxesRequest
-	19, // 9: deer.v1.DaemonService.DestroySandbox:input_type -> deer.v1.DestroySandboxCommand
-	20, // 10: deer.v1.DaemonService.StartSandbox:input_type -> deer.v1.StartSandboxCommand
-	21, // 11: deer.v1.DaemonService.StopSandbox:input_type -> deer.v1.StopSandboxCommand
-	22, // 12: deer.v1.DaemonService.ListSandboxKafkaStubs:input_type -> deer.v1.ListSandboxKafkaStubsCommand
-	23, // 13: deer.v1.DaemonService.GetSandboxKafkaStub:input_type -> deer.v1.GetSandboxKafkaStubCommand
-	24, // 14: deer.v1.DaemonService.StartSandboxKafkaStub:input_type -> deer.v1.StartSandboxKafkaStubCommand
-	25, // 15: deer.v1.DaemonService.StopSandboxKafkaStub:input_type -> deer.v1.StopSandboxKafkaStubCommand
-	26, // 16: deer.v1.DaemonService.RestartSandboxKafkaStub:input_type -> deer.v1.RestartSandboxKafkaStubCommand
-	27, // 17: deer.v1.DaemonService.GetKafkaCaptureStatus:input_type -> deer.v1.KafkaCaptureStatusRequest
-	28, // 18: deer.v1.DaemonService.RunCommand:input_type -> deer.v1.RunCommandCommand
-	29, // 19: deer.v1.DaemonService.CreateSnapshot:input_type -> deer.v1.SnapshotCommand
-	30, // 20: deer.v1.DaemonService.ListSourceVMs:input_type -> deer.v1.ListSourceVMsCommand
-	31, // 21: deer.v1.DaemonService.ValidateSourceVM:input_type -> deer.v1.ValidateSourceVMCommand
-	32, // 22: deer.v1.DaemonService.PrepareSourceVM:input_type -> deer.v1.PrepareSourceVMCommand
-	33, // 23: deer.v1.DaemonService.RunSourceCommand:input_type -> deer.v1.RunSourceCommandCommand
-	34, // 24: deer.v1.DaemonService.ReadSourceFile:input_type -> deer.v1.ReadSourceFileCommand
-	4,  // 25: deer.v1.DaemonService.GetHostInfo:input_type -> deer.v1.GetHostInfoRequest
-	7,  // 26: deer.v1.DaemonService.Health:input_type -> deer.v1.HealthRequest
-	9,  // 27: deer.v1.DaemonService.DiscoverHosts:input_type -> deer.v1.DiscoverHostsCommand
-	12, // 28: deer.v1.DaemonService.DoctorCheck:input_type -> deer.v1.DoctorCheckRequest
-	15, // 29: deer.v1.DaemonService.ScanSourceHostKeys:input_type -> deer.v1.ScanSourceHostKeysRequest
-	35, // 30: deer.v1.DaemonService.CreateSandbox:output_type -> deer.v1.SandboxCreated
-	36, // 31: deer.v1.DaemonService.CreateSandboxStream:output_type -> deer.v1.SandboxProgress
-	1,  // 32: deer.v1.DaemonService.GetSandbox:output_type -> deer.v1.SandboxInfo
-	3,  // 33: deer.v1.DaemonService.ListSandboxes:output_type -> deer.v1.ListSandboxesResponse
-	37, // 34: deer.v1.DaemonService.DestroySandbox:output_type -> deer.v1.SandboxDestroyed
-	38, // 35: deer.v1.DaemonService.StartSandbox:output_type -> deer.v1.SandboxStarted
-	39, // 36: deer.v1.DaemonService.StopSandbox:output_type -> deer.v1.SandboxStopped
-	40, // 37: deer.v1.DaemonService.ListSandboxKafkaStubs:output_type -> deer.v1.ListSandboxKafkaStubsResponse
-	41, // 38: deer.v1.DaemonService.GetSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
-	41, // 39: deer.v1.DaemonService.StartSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
-	41, // 40: deer.v1.DaemonService.StopSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
-	41, // 41: deer.v1.DaemonService.RestartSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
-	42, // 42: deer.v1.DaemonService.GetKafkaCaptureStatus:output_type -> deer.v1.KafkaCaptureStatusResponse
-	43, // 43: deer.v1.DaemonService.RunCommand:output_type -> deer.v1.CommandResult
-	44, // 44: deer.v1.DaemonService.CreateSnapshot:output_type -> deer.v1.SnapshotCreated
-	45, // 45: deer.v1.DaemonService.ListSourceVMs:output_type -> deer.v1.SourceVMsList
-	46, // 46: deer.v1.DaemonService.ValidateSourceVM:output_type -> deer.v1.SourceVMValidation
-	47, // 47: deer.v1.DaemonService.PrepareSourceVM:output_type -> deer.v1.SourceVMPrepared
-	48, // 48: deer.v1.DaemonService.RunSourceCommand:output_type -> deer.v1.SourceCommandResult
-	49, // 49: deer.v1.DaemonService.ReadSourceFile:output_type -> deer.v1.SourceFileResult
-	5,  // 50: deer.v1.DaemonService.GetHostInfo:output_type -> deer.v1.HostInfoResponse
-	8,  // 51: deer.v1.DaemonService.Health:output_type -> deer.v1.HealthResponse
-	11, // 52: deer.v1.DaemonService.DiscoverHosts:output_type -> deer.v1.DiscoverHostsResult
-	14, // 53: deer.v1.DaemonService.DoctorCheck:output_type -> deer.v1.DoctorCheckResponse
-	17, // 54: deer.v1.DaemonService.ScanSourceHostKeys:output_type -> deer.v1.ScanSourceHostKeysResponse
-	30, // [30:55] is the sub-list for method output_type
-	5,  // [5:30] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	3,  // 0: deer.v1.GetSandboxesResponse.sandboxes:type_name -> deer.v1.SandboxInfo
+	5,  // 1: deer.v1.SandboxInfo.activity:type_name -> deer.v1.SandboxActivity
+	4,  // 2: deer.v1.SandboxInfo.disk:type_name -> deer.v1.SandboxDiskUsage
+	3,  // 3: deer.v1.ListSandboxesResponse.sandboxes:type_name -> deer.v1.SandboxInfo
+	36, // 4: deer.v1.ListSandboxCommandsResponse.commands:type_name -> deer.v1.CommandRecord
+	62, // 5: deer.v1.RunCommandBatchResult.results:type_name -> deer.v1.CommandResult
+	36, // 6: deer.v1.SnapshotDiff.commands_run:type_name -> deer.v1.CommandRecord
+	34, // 7: deer.v1.SnapshotDiff.packages_added:type_name -> deer.v1.SnapshotPackage
+	34, // 8: deer.v1.SnapshotDiff.packages_removed:type_name -> deer.v1.SnapshotPackage
+	30, // 9: deer.v1.ListSnapshotsResponse.snapshots:type_name -> deer.v1.SnapshotInfo
+	26, // 10: deer.v1.DiffSnapshotsProgress.diff:type_name -> deer.v1.SnapshotDiff
+	39, // 11: deer.v1.HostInfoResponse.source_hosts:type_name -> deer.v1.SourceHostInfo
+	44, // 12: deer.v1.DaemonStatusResponse.recent_errors:type_name -> deer.v1.DaemonError
+	46, // 13: deer.v1.DiscoverHostsResult.hosts:type_name -> deer.v1.DiscoveredHost
+	49, // 14: deer.v1.DoctorCheckResponse.results:type_name -> deer.v1.DoctorCheckResult
+	52, // 15: deer.v1.ScanSourceHostKeysResponse.results:type_name -> deer.v1.ScanSourceHostKeysResult
+	55, // 16: deer.v1.ListOrphansResponse.orphans:type_name -> deer.v1.OrphanResource
+	55, // 17: deer.v1.ReclaimOrphansResponse.orphans:type_name -> deer.v1.OrphanResource
+	60, // 18: deer.v1.RefreshSandboxIPsResponse.results:type_name -> deer.v1.SandboxIPResult
+	63, // 19: deer.v1.DaemonService.CreateSandbox:input_type -> deer.v1.CreateSandboxCommand
+	63, // 20: deer.v1.DaemonService.CreateSandboxStream:input_type -> deer.v1.CreateSandboxCommand
+	63, // 21: deer.v1.DaemonService.PlanSandbox:input_type -> deer.v1.CreateSandboxCommand
+	0,  // 22: deer.v1.DaemonService.GetSandbox:input_type -> deer.v1.GetSandboxRequest
+	1,  // 23: deer.v1.DaemonService.GetSandboxes:input_type -> deer.v1.GetSandboxesRequest
+	15, // 24: deer.v1.DaemonService.ListSandboxes:input_type -> deer.v1.ListSandboxesRequest
+	64, // 25: deer.v1.DaemonService.DestroySandbox:input_type -> deer.v1.DestroySandboxCommand
+	65, // 26: deer.v1.DaemonService.StartSandbox:input_type -> deer.v1.StartSandboxCommand
+	66, // 27: deer.v1.DaemonService.StopSandbox:input_type -> deer.v1.StopSandboxCommand
+	6,  // 28: deer.v1.DaemonService.ImportSandbox:input_type -> deer.v1.ImportSandboxCommand
+	7,  // 29: deer.v1.DaemonService.ForkSandbox:input_type -> deer.v1.ForkSandboxCommand
+	9,  // 30: deer.v1.DaemonService.ResizeSandbox:input_type -> deer.v1.ResizeSandboxCommand
+	10, // 31: deer.v1.DaemonService.SetSandboxWorkdir:input_type -> deer.v1.SetSandboxWorkdirRequest
+	11, // 32: deer.v1.DaemonService.UnlockSandbox:input_type -> deer.v1.UnlockSandboxRequest
+	13, // 33: deer.v1.DaemonService.GetSandboxStats:input_type -> deer.v1.GetSandboxStatsRequest
+	67, // 34: deer.v1.DaemonService.ListSandboxKafkaStubs:input_type -> deer.v1.ListSandboxKafkaStubsCommand
+	68, // 35: deer.v1.DaemonService.GetSandboxKafkaStub:input_type -> deer.v1.GetSandboxKafkaStubCommand
+	69, // 36: deer.v1.DaemonService.StartSandboxKafkaStub:input_type -> deer.v1.StartSandboxKafkaStubCommand
+	70, // 37: deer.v1.DaemonService.StopSandboxKafkaStub:input_type -> deer.v1.StopSandboxKafkaStubCommand
+	71, // 38: deer.v1.DaemonService.RestartSandboxKafkaStub:input_type -> deer.v1.RestartSandboxKafkaStubCommand
+	72, // 39: deer.v1.DaemonService.GetKafkaCaptureStatus:input_type -> deer.v1.KafkaCaptureStatusRequest
+	73, // 40: deer.v1.DaemonService.RunCommand:input_type -> deer.v1.RunCommandCommand
+	73, // 41: deer.v1.DaemonService.StreamCommand:input_type -> deer.v1.RunCommandCommand
+	17, // 42: deer.v1.DaemonService.ListSandboxCommands:input_type -> deer.v1.ListSandboxCommandsRequest
+	19, // 43: deer.v1.DaemonService.RunCommandBatch:input_type -> deer.v1.RunCommandBatchCommand
+	21, // 44: deer.v1.DaemonService.GetSandboxSSHTarget:input_type -> deer.v1.GetSandboxSSHTargetRequest
+	23, // 45: deer.v1.DaemonService.RenewSandboxSSHCredentials:input_type -> deer.v1.RenewSandboxSSHCredentialsRequest
+	74, // 46: deer.v1.DaemonService.CreateSnapshot:input_type -> deer.v1.SnapshotCommand
+	25, // 47: deer.v1.DaemonService.DiffSnapshots:input_type -> deer.v1.DiffSnapshotsRequest
+	27, // 48: deer.v1.DaemonService.RestoreSnapshotFile:input_type -> deer.v1.RestoreSnapshotFileRequest
+	29, // 49: deer.v1.DaemonService.ListSnapshots:input_type -> deer.v1.ListSnapshotsRequest
+	32, // 50: deer.v1.DaemonService.DeleteSnapshot:input_type -> deer.v1.DeleteSnapshotRequest
+	75, // 51: deer.v1.DaemonService.ListSourceVMs:input_type -> deer.v1.ListSourceVMsCommand
+	76, // 52: deer.v1.DaemonService.ValidateSourceVM:input_type -> deer.v1.ValidateSourceVMCommand
+	77, // 53: deer.v1.DaemonService.PrepareSourceVM:input_type -> deer.v1.PrepareSourceVMCommand
+	78, // 54: deer.v1.DaemonService.RunSourceCommand:input_type -> deer.v1.RunSourceCommandCommand
+	79, // 55: deer.v1.DaemonService.ReadSourceFile:input_type -> deer.v1.ReadSourceFileCommand
+	37, // 56: deer.v1.DaemonService.GetHostInfo:input_type -> deer.v1.GetHostInfoRequest
+	40, // 57: deer.v1.DaemonService.Health:input_type -> deer.v1.HealthRequest
+	42, // 58: deer.v1.DaemonService.GetStatus:input_type -> deer.v1.GetStatusRequest
+	45, // 59: deer.v1.DaemonService.DiscoverHosts:input_type -> deer.v1.DiscoverHostsCommand
+	48, // 60: deer.v1.DaemonService.DoctorCheck:input_type -> deer.v1.DoctorCheckRequest
+	51, // 61: deer.v1.DaemonService.ScanSourceHostKeys:input_type -> deer.v1.ScanSourceHostKeysRequest
+	54, // 62: deer.v1.DaemonService.ListOrphans:input_type -> deer.v1.ListOrphansRequest
+	57, // 63: deer.v1.DaemonService.ReclaimOrphans:input_type -> deer.v1.ReclaimOrphansRequest
+	59, // 64: deer.v1.DaemonService.RefreshSandboxIPs:input_type -> deer.v1.RefreshSandboxIPsRequest
+	80, // 65: deer.v1.DaemonService.CreateSandbox:output_type -> deer.v1.SandboxCreated
+	81, // 66: deer.v1.DaemonService.CreateSandboxStream:output_type -> deer.v1.SandboxProgress
+	8,  // 67: deer.v1.DaemonService.PlanSandbox:output_type -> deer.v1.SandboxPlan
+	3,  // 68: deer.v1.DaemonService.GetSandbox:output_type -> deer.v1.SandboxInfo
+	2,  // 69: deer.v1.DaemonService.GetSandboxes:output_type -> deer.v1.GetSandboxesResponse
+	16, // 70: deer.v1.DaemonService.ListSandboxes:output_type -> deer.v1.ListSandboxesResponse
+	82, // 71: deer.v1.DaemonService.DestroySandbox:output_type -> deer.v1.SandboxDestroyed
+	83, // 72: deer.v1.DaemonService.StartSandbox:output_type -> deer.v1.SandboxStarted
+	84, // 73: deer.v1.DaemonService.StopSandbox:output_type -> deer.v1.SandboxStopped
+	3,  // 74: deer.v1.DaemonService.ImportSandbox:output_type -> deer.v1.SandboxInfo
+	80, // 75: deer.v1.DaemonService.ForkSandbox:output_type -> deer.v1.SandboxCreated
+	3,  // 76: deer.v1.DaemonService.ResizeSandbox:output_type -> deer.v1.SandboxInfo
+	3,  // 77: deer.v1.DaemonService.SetSandboxWorkdir:output_type -> deer.v1.SandboxInfo
+	12, // 78: deer.v1.DaemonService.UnlockSandbox:output_type -> deer.v1.SandboxUnlocked
+	14, // 79: deer.v1.DaemonService.GetSandboxStats:output_type -> deer.v1.SandboxStats
+	85, // 80: deer.v1.DaemonService.ListSandboxKafkaStubs:output_type -> deer.v1.ListSandboxKafkaStubsResponse
+	86, // 81: deer.v1.DaemonService.GetSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
+	86, // 82: deer.v1.DaemonService.StartSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
+	86, // 83: deer.v1.DaemonService.StopSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
+	86, // 84: deer.v1.DaemonService.RestartSandboxKafkaStub:output_type -> deer.v1.SandboxKafkaStubInfo
+	87, // 85: deer.v1.DaemonService.GetKafkaCaptureStatus:output_type -> deer.v1.KafkaCaptureStatusResponse
+	62, // 86: deer.v1.DaemonService.RunCommand:output_type -> deer.v1.CommandResult
+	88, // 87: deer.v1.DaemonService.StreamCommand:output_type -> deer.v1.CommandOutput
+	18, // 88: deer.v1.DaemonService.ListSandboxCommands:output_type -> deer.v1.ListSandboxCommandsResponse
+	20, // 89: deer.v1.DaemonService.RunCommandBatch:output_type -> deer.v1.RunCommandBatchResult
+	22, // 90: deer.v1.DaemonService.GetSandboxSSHTarget:output_type -> deer.v1.SandboxSSHTarget
+	24, // 91: deer.v1.DaemonService.RenewSandboxSSHCredentials:output_type -> deer.v1.SandboxSSHCredentials
+	89, // 92: deer.v1.DaemonService.CreateSnapshot:output_type -> deer.v1.SnapshotCreated
+	35, // 93: deer.v1.DaemonService.DiffSnapshots:output_type -> deer.v1.DiffSnapshotsProgress
+	28, // 94: deer.v1.DaemonService.RestoreSnapshotFile:output_type -> deer.v1.SnapshotFileResult
+	31, // 95: deer.v1.DaemonService.ListSnapshots:output_type -> deer.v1.ListSnapshotsResponse
+	33, // 96: deer.v1.DaemonService.DeleteSnapshot:output_type -> deer.v1.SnapshotDeleted
+	90, // 97: deer.v1.DaemonService.ListSourceVMs:output_type -> deer.v1.SourceVMsList
+	91, // 98: deer.v1.DaemonService.ValidateSourceVM:output_type -> deer.v1.SourceVMValidation
+	92, // 99: deer.v1.DaemonService.PrepareSourceVM:output_type -> deer.v1.SourceVMPrepared
+	93, // 100: deer.v1.DaemonService.RunSourceCommand:output_type -> deer.v1.SourceCommandResult
+	94, // 101: deer.v1.DaemonService.ReadSourceFile:output_type -> deer.v1.SourceFileResult
+	38, // 102: deer.v1.DaemonService.GetHostInfo:output_type -> deer.v1.HostInfoResponse
+	41, // 103: deer.v1.DaemonService.Health:output_type -> deer.v1.HealthResponse
+	43, // 104: deer.v1.DaemonService.GetStatus:output_type -> deer.v1.DaemonStatusResponse
+	47, // 105: deer.v1.DaemonService.DiscoverHosts:output_type -> deer.v1.DiscoverHostsResult
+	50, // 106: deer.v1.DaemonService.DoctorCheck:output_type -> deer.v1.DoctorCheckResponse
+	53, // 107: deer.v1.DaemonService.ScanSourceHostKeys:output_type -> deer.v1.ScanSourceHostKeysResponse
+	56, // 108: deer.v1.DaemonService.ListOrphans:output_type -> deer.v1.ListOrphansResponse
+	58, // 109: deer.v1.DaemonService.ReclaimOrphans:output_type -> deer.v1.ReclaimOrphansResponse
+	61, // 110: deer.v1.DaemonService.RefreshSandboxIPs:output_type -> deer.v1.RefreshSandboxIPsResponse
+	65, // [65:111] is the sub-list for method output_type
+	19, // [19:65] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_deer_v1_daemon_proto_init() }
@@ -1328,7 +4678,7 @@ func file_deer_v1_daemon_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_deer_v1_daemon_proto_rawDesc), len(file_deer_v1_daemon_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   18,
+			NumMessages:   62,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,31 +19,52 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DaemonService_CreateSandbox_FullMethodName           = "/deer.v1.DaemonService/CreateSandbox"
-	DaemonService_CreateSandboxStream_FullMethodName     = "/deer.v1.DaemonService/CreateSandboxStream"
-	DaemonService_GetSandbox_FullMethodName              = "/deer.v1.DaemonService/GetSandbox"
-	DaemonService_ListSandboxes_FullMethodName           = "/deer.v1.DaemonService/ListSandboxes"
-	DaemonService_DestroySandbox_FullMethodName          = "/deer.v1.DaemonService/DestroySandbox"
-	DaemonService_StartSandbox_FullMethodName            = "/deer.v1.DaemonService/StartSandbox"
-	DaemonService_StopSandbox_FullMethodName             = "/deer.v1.DaemonService/StopSandbox"
-	DaemonService_ListSandboxKafkaStubs_FullMethodName   = "/deer.v1.DaemonService/ListSandboxKafkaStubs"
-	DaemonService_GetSandboxKafkaStub_FullMethodName     = "/deer.v1.DaemonService/GetSandboxKafkaStub"
-	DaemonService_StartSandboxKafkaStub_FullMethodName   = "/deer.v1.DaemonService/StartSandboxKafkaStub"
-	DaemonService_StopSandboxKafkaStub_FullMethodName    = "/deer.v1.DaemonService/StopSandboxKafkaStub"
-	DaemonService_RestartSandboxKafkaStub_FullMethodName = "/deer.v1.DaemonService/RestartSandboxKafkaStub"
-	DaemonService_GetKafkaCaptureStatus_FullMethodName   = "/deer.v1.DaemonService/GetKafkaCaptureStatus"
-	DaemonService_RunCommand_FullMethodName              = "/deer.v1.DaemonService/RunCommand"
-	DaemonService_CreateSnapshot_FullMethodName          = "/deer.v1.DaemonService/CreateSnapshot"
-	DaemonService_ListSourceVMs_FullMethodName           = "/deer.v1.DaemonService/ListSourceVMs"
-	DaemonService_ValidateSourceVM_FullMethodName        = "/deer.v1.DaemonService/ValidateSourceVM"
-	DaemonService_PrepareSourceVM_FullMethodName         = "/deer.v1.DaemonService/PrepareSourceVM"
-	DaemonService_RunSourceCommand_FullMethodName        = "/deer.v1.DaemonService/RunSourceCommand"
-	DaemonService_ReadSourceFile_FullMethodName          = "/deer.v1.DaemonService/ReadSourceFile"
-	DaemonService_GetHostInfo_FullMethodName             = "/deer.v1.DaemonService/GetHostInfo"
-	DaemonService_Health_FullMethodName                  = "/deer.v1.DaemonService/Health"
-	DaemonService_DiscoverHosts_FullMethodName           = "/deer.v1.DaemonService/DiscoverHosts"
-	DaemonService_DoctorCheck_FullMethodName             = "/deer.v1.DaemonService/DoctorCheck"
-	DaemonService_ScanSourceHostKeys_FullMethodName      = "/deer.v1.DaemonService/ScanSourceHostKeys"
+	DaemonService_CreateSandbox_FullMethodName              = "/deer.v1.DaemonService/CreateSandbox"
+	DaemonService_CreateSandboxStream_FullMethodName        = "/deer.v1.DaemonService/CreateSandboxStream"
+	DaemonService_PlanSandbox_FullMethodName                = "/deer.v1.DaemonService/PlanSandbox"
+	DaemonService_GetSandbox_FullMethodName                 = "/deer.v1.DaemonService/GetSandbox"
+	DaemonService_GetSandboxes_FullMethodName               = "/deer.v1.DaemonService/GetSandboxes"
+	DaemonService_ListSandboxes_FullMethodName              = "/deer.v1.DaemonService/ListSandboxes"
+	DaemonService_DestroySandbox_FullMethodName             = "/deer.v1.DaemonService/DestroySandbox"
+	DaemonService_StartSandbox_FullMethodName               = "/deer.v1.DaemonService/StartSandbox"
+	DaemonService_StopSandbox_FullMethodName                = "/deer.v1.DaemonService/StopSandbox"
+	DaemonService_ImportSandbox_FullMethodName              = "/deer.v1.DaemonService/ImportSandbox"
+	DaemonService_ForkSandbox_FullMethodName                = "/deer.v1.DaemonService/ForkSandbox"
+	DaemonService_ResizeSandbox_FullMethodName              = "/deer.v1.DaemonService/ResizeSandbox"
+	DaemonService_SetSandboxWorkdir_FullMethodName          = "/deer.v1.DaemonService/SetSandboxWorkdir"
+	DaemonService_UnlockSandbox_FullMethodName              = "/deer.v1.DaemonService/UnlockSandbox"
+	DaemonService_GetSandboxStats_FullMethodName            = "/deer.v1.DaemonService/GetSandboxStats"
+	DaemonService_ListSandboxKafkaStubs_FullMethodName      = "/deer.v1.DaemonService/ListSandboxKafkaStubs"
+	DaemonService_GetSandboxKafkaStub_FullMethodName        = "/deer.v1.DaemonService/GetSandboxKafkaStub"
+	DaemonService_StartSandboxKafkaStub_FullMethodName      = "/deer.v1.DaemonService/StartSandboxKafkaStub"
+	DaemonService_StopSandboxKafkaStub_FullMethodName       = "/deer.v1.DaemonService/StopSandboxKafkaStub"
+	DaemonService_RestartSandboxKafkaStub_FullMethodName    = "/deer.v1.DaemonService/RestartSandboxKafkaStub"
+	DaemonService_GetKafkaCaptureStatus_FullMethodName      = "/deer.v1.DaemonService/GetKafkaCaptureStatus"
+	DaemonService_RunCommand_FullMethodName                 = "/deer.v1.DaemonService/RunCommand"
+	DaemonService_StreamCommand_FullMethodName              = "/deer.v1.DaemonService/StreamCommand"
+	DaemonService_ListSandboxCommands_FullMethodName        = "/deer.v1.DaemonService/ListSandboxCommands"
+	DaemonService_RunCommandBatch_FullMethodName            = "/deer.v1.DaemonService/RunCommandBatch"
+	DaemonService_GetSandboxSSHTarget_FullMethodName        = "/deer.v1.DaemonService/GetSandboxSSHTarget"
+	DaemonService_RenewSandboxSSHCredentials_FullMethodName = "/deer.v1.DaemonService/RenewSandboxSSHCredentials"
+	DaemonService_CreateSnapshot_FullMethodName             = "/deer.v1.DaemonService/CreateSnapshot"
+	DaemonService_DiffSnapshots_FullMethodName              = "/deer.v1.DaemonService/DiffSnapshots"
+	DaemonService_RestoreSnapshotFile_FullMethodName        = "/deer.v1.DaemonService/RestoreSnapshotFile"
+	DaemonService_ListSnapshots_FullMethodName              = "/deer.v1.DaemonService/ListSnapshots"
+	DaemonService_DeleteSnapshot_FullMethodName             = "/deer.v1.DaemonService/DeleteSnapshot"
+	DaemonService_ListSourceVMs_FullMethodName              = "/deer.v1.DaemonService/ListSourceVMs"
+	DaemonService_ValidateSourceVM_FullMethodName           = "/deer.v1.DaemonService/ValidateSourceVM"
+	DaemonService_PrepareSourceVM_FullMethodName            = "/deer.v1.DaemonService/PrepareSourceVM"
+	DaemonService_RunSourceCommand_FullMethodName           = "/deer.v1.DaemonService/RunSourceCommand"
+	DaemonService_ReadSourceFile_FullMethodName             = "/deer.v1.DaemonService/ReadSourceFile"
+	DaemonService_GetHostInfo_FullMethodName                = "/deer.v1.DaemonService/GetHostInfo"
+	DaemonService_Health_FullMethodName                     = "/deer.v1.DaemonService/Health"
+	DaemonService_GetStatus_FullMethodName                  = "/deer.v1.DaemonService/GetStatus"
+	DaemonService_DiscoverHosts_FullMethodName              = "/deer.v1.DaemonService/DiscoverHosts"
+	DaemonService_DoctorCheck_FullMethodName                = "/deer.v1.DaemonService/DoctorCheck"
+	DaemonService_ScanSourceHostKeys_FullMethodName         = "/deer.v1.DaemonService/ScanSourceHostKeys"
+	DaemonService_ListOrphans_FullMethodName                = "/deer.v1.DaemonService/ListOrphans"
+	DaemonService_ReclaimOrphans_FullMethodName             = "/deer.v1.DaemonService/ReclaimOrphans"
+	DaemonService_RefreshSandboxIPs_FullMethodName          = "/deer.v1.DaemonService/RefreshSandboxIPs"
 )
 
 // DaemonServiceClient is the client API for DaemonService service.
@@ -57,11 +78,19 @@ type DaemonServiceClient interface {
 	// Sandbox lifecycle
 	CreateSandbox(ctx context.Context, in *CreateSandboxCommand, opts ...grpc.CallOption) (*SandboxCreated, error)
 	CreateSandboxStream(ctx context.Context, in *CreateSandboxCommand, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SandboxProgress], error)
+	PlanSandbox(ctx context.Context, in *CreateSandboxCommand, opts ...grpc.CallOption) (*SandboxPlan, error)
 	GetSandbox(ctx context.Context, in *GetSandboxRequest, opts ...grpc.CallOption) (*SandboxInfo, error)
+	GetSandboxes(ctx context.Context, in *GetSandboxesRequest, opts ...grpc.CallOption) (*GetSandboxesResponse, error)
 	ListSandboxes(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error)
 	DestroySandbox(ctx context.Context, in *DestroySandboxCommand, opts ...grpc.CallOption) (*SandboxDestroyed, error)
 	StartSandbox(ctx context.Context, in *StartSandboxCommand, opts ...grpc.CallOption) (*SandboxStarted, error)
 	StopSandbox(ctx context.Context, in *StopSandboxCommand, opts ...grpc.CallOption) (*SandboxStopped, error)
+	ImportSandbox(ctx context.Context, in *ImportSandboxCommand, opts ...grpc.CallOption) (*SandboxInfo, error)
+	ForkSandbox(ctx context.Context, in *ForkSandboxCommand, opts ...grpc.CallOption) (*SandboxCreated, error)
+	ResizeSandbox(ctx context.Context, in *ResizeSandboxCommand, opts ...grpc.CallOption) (*SandboxInfo, error)
+	SetSandboxWorkdir(ctx context.Context, in *SetSandboxWorkdirRequest, opts ...grpc.CallOption) (*SandboxInfo, error)
+	UnlockSandbox(ctx context.Context, in *UnlockSandboxRequest, opts ...grpc.CallOption) (*SandboxUnlocked, error)
+	GetSandboxStats(ctx context.Context, in *GetSandboxStatsRequest, opts ...grpc.CallOption) (*SandboxStats, error)
 	ListSandboxKafkaStubs(ctx context.Context, in *ListSandboxKafkaStubsCommand, opts ...grpc.CallOption) (*ListSandboxKafkaStubsResponse, error)
 	GetSandboxKafkaStub(ctx context.Context, in *GetSandboxKafkaStubCommand, opts ...grpc.CallOption) (*SandboxKafkaStubInfo, error)
 	StartSandboxKafkaStub(ctx context.Context, in *StartSandboxKafkaStubCommand, opts ...grpc.CallOption) (*SandboxKafkaStubInfo, error)
@@ -70,8 +99,17 @@ type DaemonServiceClient interface {
 	GetKafkaCaptureStatus(ctx context.Context, in *KafkaCaptureStatusRequest, opts ...grpc.CallOption) (*KafkaCaptureStatusResponse, error)
 	// Command execution
 	RunCommand(ctx context.Context, in *RunCommandCommand, opts ...grpc.CallOption) (*CommandResult, error)
+	StreamCommand(ctx context.Context, in *RunCommandCommand, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CommandOutput], error)
+	ListSandboxCommands(ctx context.Context, in *ListSandboxCommandsRequest, opts ...grpc.CallOption) (*ListSandboxCommandsResponse, error)
+	RunCommandBatch(ctx context.Context, in *RunCommandBatchCommand, opts ...grpc.CallOption) (*RunCommandBatchResult, error)
+	GetSandboxSSHTarget(ctx context.Context, in *GetSandboxSSHTargetRequest, opts ...grpc.CallOption) (*SandboxSSHTarget, error)
+	RenewSandboxSSHCredentials(ctx context.Context, in *RenewSandboxSSHCredentialsRequest, opts ...grpc.CallOption) (*SandboxSSHCredentials, error)
 	// Snapshots
 	CreateSnapshot(ctx context.Context, in *SnapshotCommand, opts ...grpc.CallOption) (*SnapshotCreated, error)
+	DiffSnapshots(ctx context.Context, in *DiffSnapshotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DiffSnapshotsProgress], error)
+	RestoreSnapshotFile(ctx context.Context, in *RestoreSnapshotFileRequest, opts ...grpc.CallOption) (*SnapshotFileResult, error)
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*SnapshotDeleted, error)
 	// Source VM operations
 	ListSourceVMs(ctx context.Context, in *ListSourceVMsCommand, opts ...grpc.CallOption) (*SourceVMsList, error)
 	ValidateSourceVM(ctx context.Context, in *ValidateSourceVMCommand, opts ...grpc.CallOption) (*SourceVMValidation, error)
@@ -81,12 +119,18 @@ type DaemonServiceClient interface {
 	// Host info
 	GetHostInfo(ctx context.Context, in *GetHostInfoRequest, opts ...grpc.CallOption) (*HostInfoResponse, error)
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*DaemonStatusResponse, error)
 	// Host discovery
 	DiscoverHosts(ctx context.Context, in *DiscoverHostsCommand, opts ...grpc.CallOption) (*DiscoverHostsResult, error)
 	// Doctor checks
 	DoctorCheck(ctx context.Context, in *DoctorCheckRequest, opts ...grpc.CallOption) (*DoctorCheckResponse, error)
 	// Source host key scanning
 	ScanSourceHostKeys(ctx context.Context, in *ScanSourceHostKeysRequest, opts ...grpc.CallOption) (*ScanSourceHostKeysResponse, error)
+	// Leaked provider resources
+	ListOrphans(ctx context.Context, in *ListOrphansRequest, opts ...grpc.CallOption) (*ListOrphansResponse, error)
+	ReclaimOrphans(ctx context.Context, in *ReclaimOrphansRequest, opts ...grpc.CallOption) (*ReclaimOrphansResponse, error)
+	// IP discovery
+	RefreshSandboxIPs(ctx context.Context, in *RefreshSandboxIPsRequest, opts ...grpc.CallOption) (*RefreshSandboxIPsResponse, error)
 }
 
 type daemonServiceClient struct {
@@ -126,6 +170,16 @@ func (c *daemonServiceClient) CreateSandboxStream(ctx context.Context, in *Creat
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DaemonService_CreateSandboxStreamClient = grpc.ServerStreamingClient[SandboxProgress]
 
+func (c *daemonServiceClient) PlanSandbox(ctx context.Context, in *CreateSandboxCommand, opts ...grpc.CallOption) (*SandboxPlan, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxPlan)
+	err := c.cc.Invoke(ctx, DaemonService_PlanSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) GetSandbox(ctx context.Context, in *GetSandboxRequest, opts ...grpc.CallOption) (*SandboxInfo, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SandboxInfo)
@@ -136,6 +190,16 @@ func (c *daemonServiceClient) GetSandbox(ctx context.Context, in *GetSandboxRequ
 	return out, nil
 }
 
+func (c *daemonServiceClient) GetSandboxes(ctx context.Context, in *GetSandboxesRequest, opts ...grpc.CallOption) (*GetSandboxesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSandboxesResponse)
+	err := c.cc.Invoke(ctx, DaemonService_GetSandboxes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) ListSandboxes(ctx context.Context, in *ListSandboxesRequest, opts ...grpc.CallOption) (*ListSandboxesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListSandboxesResponse)
@@ -176,6 +240,66 @@ func (c *daemonServiceClient) StopSandbox(ctx context.Context, in *StopSandboxCo
 	return out, nil
 }
 
+func (c *daemonServiceClient) ImportSandbox(ctx context.Context, in *ImportSandboxCommand, opts ...grpc.CallOption) (*SandboxInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxInfo)
+	err := c.cc.Invoke(ctx, DaemonService_ImportSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ForkSandbox(ctx context.Context, in *ForkSandboxCommand, opts ...grpc.CallOption) (*SandboxCreated, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxCreated)
+	err := c.cc.Invoke(ctx, DaemonService_ForkSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ResizeSandbox(ctx context.Context, in *ResizeSandboxCommand, opts ...grpc.CallOption) (*SandboxInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxInfo)
+	err := c.cc.Invoke(ctx, DaemonService_ResizeSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) SetSandboxWorkdir(ctx context.Context, in *SetSandboxWorkdirRequest, opts ...grpc.CallOption) (*SandboxInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxInfo)
+	err := c.cc.Invoke(ctx, DaemonService_SetSandboxWorkdir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) UnlockSandbox(ctx context.Context, in *UnlockSandboxRequest, opts ...grpc.CallOption) (*SandboxUnlocked, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxUnlocked)
+	err := c.cc.Invoke(ctx, DaemonService_UnlockSandbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) GetSandboxStats(ctx context.Context, in *GetSandboxStatsRequest, opts ...grpc.CallOption) (*SandboxStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxStats)
+	err := c.cc.Invoke(ctx, DaemonService_GetSandboxStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) ListSandboxKafkaStubs(ctx context.Context, in *ListSandboxKafkaStubsCommand, opts ...grpc.CallOption) (*ListSandboxKafkaStubsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ListSandboxKafkaStubsResponse)
@@ -246,6 +370,65 @@ func (c *daemonServiceClient) RunCommand(ctx context.Context, in *RunCommandComm
 	return out, nil
 }
 
+func (c *daemonServiceClient) StreamCommand(ctx context.Context, in *RunCommandCommand, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CommandOutput], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DaemonService_ServiceDesc.Streams[1], DaemonService_StreamCommand_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RunCommandCommand, CommandOutput]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DaemonService_StreamCommandClient = grpc.ServerStreamingClient[CommandOutput]
+
+func (c *daemonServiceClient) ListSandboxCommands(ctx context.Context, in *ListSandboxCommandsRequest, opts ...grpc.CallOption) (*ListSandboxCommandsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSandboxCommandsResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ListSandboxCommands_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) RunCommandBatch(ctx context.Context, in *RunCommandBatchCommand, opts ...grpc.CallOption) (*RunCommandBatchResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunCommandBatchResult)
+	err := c.cc.Invoke(ctx, DaemonService_RunCommandBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) GetSandboxSSHTarget(ctx context.Context, in *GetSandboxSSHTargetRequest, opts ...grpc.CallOption) (*SandboxSSHTarget, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxSSHTarget)
+	err := c.cc.Invoke(ctx, DaemonService_GetSandboxSSHTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) RenewSandboxSSHCredentials(ctx context.Context, in *RenewSandboxSSHCredentialsRequest, opts ...grpc.CallOption) (*SandboxSSHCredentials, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxSSHCredentials)
+	err := c.cc.Invoke(ctx, DaemonService_RenewSandboxSSHCredentials_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) CreateSnapshot(ctx context.Context, in *SnapshotCommand, opts ...grpc.CallOption) (*SnapshotCreated, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SnapshotCreated)
@@ -256,6 +439,55 @@ func (c *daemonServiceClient) CreateSnapshot(ctx context.Context, in *SnapshotCo
 	return out, nil
 }
 
+func (c *daemonServiceClient) DiffSnapshots(ctx context.Context, in *DiffSnapshotsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DiffSnapshotsProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DaemonService_ServiceDesc.Streams[2], DaemonService_DiffSnapshots_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DiffSnapshotsRequest, DiffSnapshotsProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DaemonService_DiffSnapshotsClient = grpc.ServerStreamingClient[DiffSnapshotsProgress]
+
+func (c *daemonServiceClient) RestoreSnapshotFile(ctx context.Context, in *RestoreSnapshotFileRequest, opts ...grpc.CallOption) (*SnapshotFileResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotFileResult)
+	err := c.cc.Invoke(ctx, DaemonService_RestoreSnapshotFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ListSnapshots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) DeleteSnapshot(ctx context.Context, in *DeleteSnapshotRequest, opts ...grpc.CallOption) (*SnapshotDeleted, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SnapshotDeleted)
+	err := c.cc.Invoke(ctx, DaemonService_DeleteSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) ListSourceVMs(ctx context.Context, in *ListSourceVMsCommand, opts ...grpc.CallOption) (*SourceVMsList, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SourceVMsList)
@@ -326,6 +558,16 @@ func (c *daemonServiceClient) Health(ctx context.Context, in *HealthRequest, opt
 	return out, nil
 }
 
+func (c *daemonServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*DaemonStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DaemonStatusResponse)
+	err := c.cc.Invoke(ctx, DaemonService_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *daemonServiceClient) DiscoverHosts(ctx context.Context, in *DiscoverHostsCommand, opts ...grpc.CallOption) (*DiscoverHostsResult, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DiscoverHostsResult)
@@ -356,6 +598,36 @@ func (c *daemonServiceClient) ScanSourceHostKeys(ctx context.Context, in *ScanSo
 	return out, nil
 }
 
+func (c *daemonServiceClient) ListOrphans(ctx context.Context, in *ListOrphansRequest, opts ...grpc.CallOption) (*ListOrphansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOrphansResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ListOrphans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) ReclaimOrphans(ctx context.Context, in *ReclaimOrphansRequest, opts ...grpc.CallOption) (*ReclaimOrphansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReclaimOrphansResponse)
+	err := c.cc.Invoke(ctx, DaemonService_ReclaimOrphans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *daemonServiceClient) RefreshSandboxIPs(ctx context.Context, in *RefreshSandboxIPsRequest, opts ...grpc.CallOption) (*RefreshSandboxIPsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshSandboxIPsResponse)
+	err := c.cc.Invoke(ctx, DaemonService_RefreshSandboxIPs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DaemonServiceServer is the server API for DaemonService service.
 // All implementations must embed UnimplementedDaemonServiceServer
 // for forward compatibility.
@@ -367,11 +639,19 @@ type DaemonServiceServer interface {
 	// Sandbox lifecycle
 	CreateSandbox(context.Context, *CreateSandboxCommand) (*SandboxCreated, error)
 	CreateSandboxStream(*CreateSandboxCommand, grpc.ServerStreamingServer[SandboxProgress]) error
+	PlanSandbox(context.Context, *CreateSandboxCommand) (*SandboxPlan, error)
 	GetSandbox(context.Context, *GetSandboxRequest) (*SandboxInfo, error)
+	GetSandboxes(context.Context, *GetSandboxesRequest) (*GetSandboxesResponse, error)
 	ListSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error)
 	DestroySandbox(context.Context, *DestroySandboxCommand) (*SandboxDestroyed, error)
 	StartSandbox(context.Context, *StartSandboxCommand) (*SandboxStarted, error)
 	StopSandbox(context.Context, *StopSandboxCommand) (*SandboxStopped, error)
+	ImportSandbox(context.Context, *ImportSandboxCommand) (*SandboxInfo, error)
+	ForkSandbox(context.Context, *ForkSandboxCommand) (*SandboxCreated, error)
+	ResizeSandbox(context.Context, *ResizeSandboxCommand) (*SandboxInfo, error)
+	SetSandboxWorkdir(context.Context, *SetSandboxWorkdirRequest) (*SandboxInfo, error)
+	UnlockSandbox(context.Context, *UnlockSandboxRequest) (*SandboxUnlocked, error)
+	GetSandboxStats(context.Context, *GetSandboxStatsRequest) (*SandboxStats, error)
 	ListSandboxKafkaStubs(context.Context, *ListSandboxKafkaStubsCommand) (*ListSandboxKafkaStubsResponse, error)
 	GetSandboxKafkaStub(context.Context, *GetSandboxKafkaStubCommand) (*SandboxKafkaStubInfo, error)
 	StartSandboxKafkaStub(context.Context, *StartSandboxKafkaStubCommand) (*SandboxKafkaStubInfo, error)
@@ -380,8 +660,17 @@ type DaemonServiceServer interface {
 	GetKafkaCaptureStatus(context.Context, *KafkaCaptureStatusRequest) (*KafkaCaptureStatusResponse, error)
 	// Command execution
 	RunCommand(context.Context, *RunCommandCommand) (*CommandResult, error)
+	StreamCommand(*RunCommandCommand, grpc.ServerStreamingServer[CommandOutput]) error
+	ListSandboxCommands(context.Context, *ListSandboxCommandsRequest) (*ListSandboxCommandsResponse, error)
+	RunCommandBatch(context.Context, *RunCommandBatchCommand) (*RunCommandBatchResult, error)
+	GetSandboxSSHTarget(context.Context, *GetSandboxSSHTargetRequest) (*SandboxSSHTarget, error)
+	RenewSandboxSSHCredentials(context.Context, *RenewSandboxSSHCredentialsRequest) (*SandboxSSHCredentials, error)
 	// Snapshots
 	CreateSnapshot(context.Context, *SnapshotCommand) (*SnapshotCreated, error)
+	DiffSnapshots(*DiffSnapshotsRequest, grpc.ServerStreamingServer[DiffSnapshotsProgress]) error
+	RestoreSnapshotFile(context.Context, *RestoreSnapshotFileRequest) (*SnapshotFileResult, error)
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
+	DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*SnapshotDeleted, error)
 	// Source VM operations
 	ListSourceVMs(context.Context, *ListSourceVMsCommand) (*SourceVMsList, error)
 	ValidateSourceVM(context.Context, *ValidateSourceVMCommand) (*SourceVMValidation, error)
@@ -391,12 +680,18 @@ type DaemonServiceServer interface {
 	// Host info
 	GetHostInfo(context.Context, *GetHostInfoRequest) (*HostInfoResponse, error)
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	GetStatus(context.Context, *GetStatusRequest) (*DaemonStatusResponse, error)
 	// Host discovery
 	DiscoverHosts(context.Context, *DiscoverHostsCommand) (*DiscoverHostsResult, error)
 	// Doctor checks
 	DoctorCheck(context.Context, *DoctorCheckRequest) (*DoctorCheckResponse, error)
 	// Source host key scanning
 	ScanSourceHostKeys(context.Context, *ScanSourceHostKeysRequest) (*ScanSourceHostKeysResponse, error)
+	// Leaked provider resources
+	ListOrphans(context.Context, *ListOrphansRequest) (*ListOrphansResponse, error)
+	ReclaimOrphans(context.Context, *ReclaimOrphansRequest) (*ReclaimOrphansResponse, error)
+	// IP discovery
+	RefreshSandboxIPs(context.Context, *RefreshSandboxIPsRequest) (*RefreshSandboxIPsResponse, error)
 	mustEmbedUnimplementedDaemonServiceServer()
 }
 
@@ -413,9 +708,15 @@ func (UnimplementedDaemonServiceServer) CreateSandbox(context.Context, *CreateSa
 func (UnimplementedDaemonServiceServer) CreateSandboxStream(*CreateSandboxCommand, grpc.ServerStreamingServer[SandboxProgress]) error {
 	return status.Error(codes.Unimplemented, "method CreateSandboxStream not implemented")
 }
+func (UnimplementedDaemonServiceServer) PlanSandbox(context.Context, *CreateSandboxCommand) (*SandboxPlan, error) {
+	return nil, status.Error(codes.Unimplemented, "method PlanSandbox not implemented")
+}
 func (UnimplementedDaemonServiceServer) GetSandbox(context.Context, *GetSandboxRequest) (*SandboxInfo, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetSandbox not implemented")
 }
+func (UnimplementedDaemonServiceServer) GetSandboxes(context.Context, *GetSandboxesRequest) (*GetSandboxesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSandboxes not implemented")
+}
 func (UnimplementedDaemonServiceServer) ListSandboxes(context.Context, *ListSandboxesRequest) (*ListSandboxesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListSandboxes not implemented")
 }
@@ -428,6 +729,24 @@ func (UnimplementedDaemonServiceServer) StartSandbox(context.Context, *StartSand
 func (UnimplementedDaemonServiceServer) StopSandbox(context.Context, *StopSandboxCommand) (*SandboxStopped, error) {
 	return nil, status.Error(codes.Unimplemented, "method StopSandbox not implemented")
 }
+func (UnimplementedDaemonServiceServer) ImportSandbox(context.Context, *ImportSandboxCommand) (*SandboxInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method ImportSandbox not implemented")
+}
+func (UnimplementedDaemonServiceServer) ForkSandbox(context.Context, *ForkSandboxCommand) (*SandboxCreated, error) {
+	return nil, status.Error(codes.Unimplemented, "method ForkSandbox not implemented")
+}
+func (UnimplementedDaemonServiceServer) ResizeSandbox(context.Context, *ResizeSandboxCommand) (*SandboxInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResizeSandbox not implemented")
+}
+func (UnimplementedDaemonServiceServer) SetSandboxWorkdir(context.Context, *SetSandboxWorkdirRequest) (*SandboxInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetSandboxWorkdir not implemented")
+}
+func (UnimplementedDaemonServiceServer) UnlockSandbox(context.Context, *UnlockSandboxRequest) (*SandboxUnlocked, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnlockSandbox not implemented")
+}
+func (UnimplementedDaemonServiceServer) GetSandboxStats(context.Context, *GetSandboxStatsRequest) (*SandboxStats, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSandboxStats not implemented")
+}
 func (UnimplementedDaemonServiceServer) ListSandboxKafkaStubs(context.Context, *ListSandboxKafkaStubsCommand) (*ListSandboxKafkaStubsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListSandboxKafkaStubs not implemented")
 }
@@ -449,9 +768,36 @@ func (UnimplementedDaemonServiceServer) GetKafkaCaptureStatus(context.Context, *
 func (UnimplementedDaemonServiceServer) RunCommand(context.Context, *RunCommandCommand) (*CommandResult, error) {
 	return nil, status.Error(codes.Unimplemented, "method RunCommand not implemented")
 }
+func (UnimplementedDaemonServiceServer) StreamCommand(*RunCommandCommand, grpc.ServerStreamingServer[CommandOutput]) error {
+	return status.Error(codes.Unimplemented, "method StreamCommand not implemented")
+}
+func (UnimplementedDaemonServiceServer) ListSandboxCommands(context.Context, *ListSandboxCommandsRequest) (*ListSandboxCommandsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSandboxCommands not implemented")
+}
+func (UnimplementedDaemonServiceServer) RunCommandBatch(context.Context, *RunCommandBatchCommand) (*RunCommandBatchResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method RunCommandBatch not implemented")
+}
+func (UnimplementedDaemonServiceServer) GetSandboxSSHTarget(context.Context, *GetSandboxSSHTargetRequest) (*SandboxSSHTarget, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSandboxSSHTarget not implemented")
+}
+func (UnimplementedDaemonServiceServer) RenewSandboxSSHCredentials(context.Context, *RenewSandboxSSHCredentialsRequest) (*SandboxSSHCredentials, error) {
+	return nil, status.Error(codes.Unimplemented, "method RenewSandboxSSHCredentials not implemented")
+}
 func (UnimplementedDaemonServiceServer) CreateSnapshot(context.Context, *SnapshotCommand) (*SnapshotCreated, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateSnapshot not implemented")
 }
+func (UnimplementedDaemonServiceServer) DiffSnapshots(*DiffSnapshotsRequest, grpc.ServerStreamingServer[DiffSnapshotsProgress]) error {
+	return status.Error(codes.Unimplemented, "method DiffSnapshots not implemented")
+}
+func (UnimplementedDaemonServiceServer) RestoreSnapshotFile(context.Context, *RestoreSnapshotFileRequest) (*SnapshotFileResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestoreSnapshotFile not implemented")
+}
+func (UnimplementedDaemonServiceServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedDaemonServiceServer) DeleteSnapshot(context.Context, *DeleteSnapshotRequest) (*SnapshotDeleted, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteSnapshot not implemented")
+}
 func (UnimplementedDaemonServiceServer) ListSourceVMs(context.Context, *ListSourceVMsCommand) (*SourceVMsList, error) {
 	return nil, status.Error(codes.Unimplemented, "method ListSourceVMs not implemented")
 }
@@ -473,6 +819,9 @@ func (UnimplementedDaemonServiceServer) GetHostInfo(context.Context, *GetHostInf
 func (UnimplementedDaemonServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
 }
+func (UnimplementedDaemonServiceServer) GetStatus(context.Context, *GetStatusRequest) (*DaemonStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetStatus not implemented")
+}
 func (UnimplementedDaemonServiceServer) DiscoverHosts(context.Context, *DiscoverHostsCommand) (*DiscoverHostsResult, error) {
 	return nil, status.Error(codes.Unimplemented, "method DiscoverHosts not implemented")
 }
@@ -482,6 +831,15 @@ func (UnimplementedDaemonServiceServer) DoctorCheck(context.Context, *DoctorChec
 func (UnimplementedDaemonServiceServer) ScanSourceHostKeys(context.Context, *ScanSourceHostKeysRequest) (*ScanSourceHostKeysResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ScanSourceHostKeys not implemented")
 }
+func (UnimplementedDaemonServiceServer) ListOrphans(context.Context, *ListOrphansRequest) (*ListOrphansResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListOrphans not implemented")
+}
+func (UnimplementedDaemonServiceServer) ReclaimOrphans(context.Context, *ReclaimOrphansRequest) (*ReclaimOrphansResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReclaimOrphans not implemented")
+}
+func (UnimplementedDaemonServiceServer) RefreshSandboxIPs(context.Context, *RefreshSandboxIPsRequest) (*RefreshSandboxIPsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RefreshSandboxIPs not implemented")
+}
 func (UnimplementedDaemonServiceServer) mustEmbedUnimplementedDaemonServiceServer() {}
 func (UnimplementedDaemonServiceServer) testEmbeddedByValue()                       {}
 
@@ -532,6 +890,24 @@ func _DaemonService_CreateSandboxStream_Handler(srv interface{}, stream grpc.Ser
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DaemonService_CreateSandboxStreamServer = grpc.ServerStreamingServer[SandboxProgress]
 
+func _DaemonService_PlanSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSandboxCommand)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).PlanSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_PlanSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).PlanSandbox(ctx, req.(*CreateSandboxCommand))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_GetSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetSandboxRequest)
 	if err := dec(in); err != nil {
@@ -550,6 +926,24 @@ func _DaemonService_GetSandbox_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_GetSandboxes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSandboxesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).GetSandboxes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_GetSandboxes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).GetSandboxes(ctx, req.(*GetSandboxesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_ListSandboxes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListSandboxesRequest)
 	if err := dec(in); err != nil {
@@ -622,6 +1016,114 @@ func _DaemonService_StopSandbox_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_ImportSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportSandboxCommand)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ImportSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ImportSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ImportSandbox(ctx, req.(*ImportSandboxCommand))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ForkSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForkSandboxCommand)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ForkSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ForkSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ForkSandbox(ctx, req.(*ForkSandboxCommand))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ResizeSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResizeSandboxCommand)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ResizeSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ResizeSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ResizeSandbox(ctx, req.(*ResizeSandboxCommand))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_SetSandboxWorkdir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSandboxWorkdirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).SetSandboxWorkdir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_SetSandboxWorkdir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).SetSandboxWorkdir(ctx, req.(*SetSandboxWorkdirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_UnlockSandbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockSandboxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).UnlockSandbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_UnlockSandbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).UnlockSandbox(ctx, req.(*UnlockSandboxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_GetSandboxStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSandboxStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).GetSandboxStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_GetSandboxStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).GetSandboxStats(ctx, req.(*GetSandboxStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_ListSandboxKafkaStubs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListSandboxKafkaStubsCommand)
 	if err := dec(in); err != nil {
@@ -748,6 +1250,89 @@ func _DaemonService_RunCommand_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_StreamCommand_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunCommandCommand)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServiceServer).StreamCommand(m, &grpc.GenericServerStream[RunCommandCommand, CommandOutput]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DaemonService_StreamCommandServer = grpc.ServerStreamingServer[CommandOutput]
+
+func _DaemonService_ListSandboxCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSandboxCommandsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListSandboxCommands(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ListSandboxCommands_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListSandboxCommands(ctx, req.(*ListSandboxCommandsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_RunCommandBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunCommandBatchCommand)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).RunCommandBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_RunCommandBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).RunCommandBatch(ctx, req.(*RunCommandBatchCommand))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_GetSandboxSSHTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSandboxSSHTargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).GetSandboxSSHTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_GetSandboxSSHTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).GetSandboxSSHTarget(ctx, req.(*GetSandboxSSHTargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_RenewSandboxSSHCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewSandboxSSHCredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).RenewSandboxSSHCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_RenewSandboxSSHCredentials_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).RenewSandboxSSHCredentials(ctx, req.(*RenewSandboxSSHCredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SnapshotCommand)
 	if err := dec(in); err != nil {
@@ -766,6 +1351,71 @@ func _DaemonService_CreateSnapshot_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_DiffSnapshots_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DiffSnapshotsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServiceServer).DiffSnapshots(m, &grpc.GenericServerStream[DiffSnapshotsRequest, DiffSnapshotsProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DaemonService_DiffSnapshotsServer = grpc.ServerStreamingServer[DiffSnapshotsProgress]
+
+func _DaemonService_RestoreSnapshotFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSnapshotFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).RestoreSnapshotFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_RestoreSnapshotFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).RestoreSnapshotFile(ctx, req.(*RestoreSnapshotFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_DeleteSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).DeleteSnapshot(ctx, req.(*DeleteSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_ListSourceVMs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListSourceVMsCommand)
 	if err := dec(in); err != nil {
@@ -892,6 +1542,24 @@ func _DaemonService_Health_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DaemonService_DiscoverHosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DiscoverHostsCommand)
 	if err := dec(in); err != nil {
@@ -946,6 +1614,60 @@ func _DaemonService_ScanSourceHostKeys_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_ListOrphans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrphansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ListOrphans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ListOrphans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ListOrphans(ctx, req.(*ListOrphansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_ReclaimOrphans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReclaimOrphansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).ReclaimOrphans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_ReclaimOrphans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).ReclaimOrphans(ctx, req.(*ReclaimOrphansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DaemonService_RefreshSandboxIPs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshSandboxIPsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DaemonServiceServer).RefreshSandboxIPs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DaemonService_RefreshSandboxIPs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DaemonServiceServer).RefreshSandboxIPs(ctx, req.(*RefreshSandboxIPsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DaemonService_ServiceDesc is the grpc.ServiceDesc for DaemonService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -957,10 +1679,18 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateSandbox",
 			Handler:    _DaemonService_CreateSandbox_Handler,
 		},
+		{
+			MethodName: "PlanSandbox",
+			Handler:    _DaemonService_PlanSandbox_Handler,
+		},
 		{
 			MethodName: "GetSandbox",
 			Handler:    _DaemonService_GetSandbox_Handler,
 		},
+		{
+			MethodName: "GetSandboxes",
+			Handler:    _DaemonService_GetSandboxes_Handler,
+		},
 		{
 			MethodName: "ListSandboxes",
 			Handler:    _DaemonService_ListSandboxes_Handler,
@@ -977,6 +1707,30 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopSandbox",
 			Handler:    _DaemonService_StopSandbox_Handler,
 		},
+		{
+			MethodName: "ImportSandbox",
+			Handler:    _DaemonService_ImportSandbox_Handler,
+		},
+		{
+			MethodName: "ForkSandbox",
+			Handler:    _DaemonService_ForkSandbox_Handler,
+		},
+		{
+			MethodName: "ResizeSandbox",
+			Handler:    _DaemonService_ResizeSandbox_Handler,
+		},
+		{
+			MethodName: "SetSandboxWorkdir",
+			Handler:    _DaemonService_SetSandboxWorkdir_Handler,
+		},
+		{
+			MethodName: "UnlockSandbox",
+			Handler:    _DaemonService_UnlockSandbox_Handler,
+		},
+		{
+			MethodName: "GetSandboxStats",
+			Handler:    _DaemonService_GetSandboxStats_Handler,
+		},
 		{
 			MethodName: "ListSandboxKafkaStubs",
 			Handler:    _DaemonService_ListSandboxKafkaStubs_Handler,
@@ -1005,10 +1759,38 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RunCommand",
 			Handler:    _DaemonService_RunCommand_Handler,
 		},
+		{
+			MethodName: "ListSandboxCommands",
+			Handler:    _DaemonService_ListSandboxCommands_Handler,
+		},
+		{
+			MethodName: "RunCommandBatch",
+			Handler:    _DaemonService_RunCommandBatch_Handler,
+		},
+		{
+			MethodName: "GetSandboxSSHTarget",
+			Handler:    _DaemonService_GetSandboxSSHTarget_Handler,
+		},
+		{
+			MethodName: "RenewSandboxSSHCredentials",
+			Handler:    _DaemonService_RenewSandboxSSHCredentials_Handler,
+		},
 		{
 			MethodName: "CreateSnapshot",
 			Handler:    _DaemonService_CreateSnapshot_Handler,
 		},
+		{
+			MethodName: "RestoreSnapshotFile",
+			Handler:    _DaemonService_RestoreSnapshotFile_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _DaemonService_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _DaemonService_DeleteSnapshot_Handler,
+		},
 		{
 			MethodName: "ListSourceVMs",
 			Handler:    _DaemonService_ListSourceVMs_Handler,
@@ -1037,6 +1819,10 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Health",
 			Handler:    _DaemonService_Health_Handler,
 		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _DaemonService_GetStatus_Handler,
+		},
 		{
 			MethodName: "DiscoverHosts",
 			Handler:    _DaemonService_DiscoverHosts_Handler,
@@ -1049,6 +1835,18 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ScanSourceHostKeys",
 			Handler:    _DaemonService_ScanSourceHostKeys_Handler,
 		},
+		{
+			MethodName: "ListOrphans",
+			Handler:    _DaemonService_ListOrphans_Handler,
+		},
+		{
+			MethodName: "ReclaimOrphans",
+			Handler:    _DaemonService_ReclaimOrphans_Handler,
+		},
+		{
+			MethodName: "RefreshSandboxIPs",
+			Handler:    _DaemonService_RefreshSandboxIPs_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1056,6 +1854,16 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _DaemonService_CreateSandboxStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamCommand",
+			Handler:       _DaemonService_StreamCommand_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DiffSnapshots",
+			Handler:       _DaemonService_DiffSnapshots_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "deer/v1/daemon.proto",
 }
@@ -1003,10 +1003,14 @@ func (x *SandboxCreated) GetKafkaStubs() []*SandboxKafkaStubInfo {
 
 // DestroySandboxCommand instructs the host to destroy a sandbox.
 type DestroySandboxCommand struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SandboxId string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	Archive   bool                   `protobuf:"varint,2,opt,name=archive,proto3" json:"archive,omitempty"` // export the sandbox's disk before destroying it
+	// confirm_imported allows destroying a sandbox adopted with ImportVM,
+	// which deletes the VM it was imported from.
+	ConfirmImported bool `protobuf:"varint,3,opt,name=confirm_imported,json=confirmImported,proto3" json:"confirm_imported,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *DestroySandboxCommand) Reset() {
@@ -1046,10 +1050,26 @@ func (x *DestroySandboxCommand) GetSandboxId() string {
 	return ""
 }
 
+func (x *DestroySandboxCommand) GetArchive() bool {
+	if x != nil {
+		return x.Archive
+	}
+	return false
+}
+
+func (x *DestroySandboxCommand) GetConfirmImported() bool {
+	if x != nil {
+		return x.ConfirmImported
+	}
+	return false
+}
+
 // SandboxDestroyed confirms a sandbox has been destroyed.
 type SandboxDestroyed struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SandboxId     string                 `protobuf:"bytes,1,opt,name=sandbox_id,json=sandboxId,proto3" json:"sandbox_id,omitempty"`
+	ArchivePath   string                 `protobuf:"bytes,2,opt,name=archive_path,json=archivePath,proto3" json:"archive_path,omitempty"`    // set when the disk was archived first
+	ArchiveImage  string                 `protobuf:"bytes,3,opt,name=archive_image,json=archiveImage,proto3" json:"archive_image,omitempty"` // base image name of the archive, when usable as one
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1091,6 +1111,20 @@ func (x *SandboxDestroyed) GetSandboxId() string {
 	return ""
 }
 
+func (x *SandboxDestroyed) GetArchivePath() string {
+	if x != nil {
+		return x.ArchivePath
+	}
+	return ""
+}
+
+func (x *SandboxDestroyed) GetArchiveImage() string {
+	if x != nil {
+		return x.ArchiveImage
+	}
+	return ""
+}
+
 // StartSandboxCommand instructs the host to start a stopped sandbox.
 type StartSandboxCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1379,8 +1413,19 @@ type RunCommandCommand struct {
 	Command        string                 `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
 	TimeoutSeconds int32                  `protobuf:"varint,3,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
 	Env            map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+	ForwardAgent   bool                   `protobuf:"varint,5,opt,name=forward_agent,json=forwardAgent,proto3" json:"forward_agent,omitempty"`   // forward the daemon's SSH agent (off by default)
+	IdentityFiles  []string               `protobuf:"bytes,6,rep,name=identity_files,json=identityFiles,proto3" json:"identity_files,omitempty"` // extra identities, must be allowed by the daemon
+	Workdir        string                 `protobuf:"bytes,7,opt,name=workdir,proto3" json:"workdir,omitempty"`                                  // absolute directory to run in; empty uses the sandbox's default
+	// actor says who initiated the command: human-cli, tui-agent, mcp-client,
+	// or playbook. It is recorded in command history and the audit log.
+	Actor string `protobuf:"bytes,8,opt,name=actor,proto3" json:"actor,omitempty"`
+	// unrecorded runs a read deer makes on the caller's behalf, such as a
+	// process environment or a file's contents: its output is neither saved
+	// in command history nor redacted, and it does not count as activity for
+	// idle stops. The command itself is still audited.
+	Unrecorded    bool `protobuf:"varint,9,opt,name=unrecorded,proto3" json:"unrecorded,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RunCommandCommand) Reset() {
@@ -1441,6 +1486,41 @@ func (x *RunCommandCommand) GetEnv() map[string]string {
 	return nil
 }
 
+func (x *RunCommandCommand) GetForwardAgent() bool {
+	if x != nil {
+		return x.ForwardAgent
+	}
+	return false
+}
+
+func (x *RunCommandCommand) GetIdentityFiles() []string {
+	if x != nil {
+		return x.IdentityFiles
+	}
+	return nil
+}
+
+func (x *RunCommandCommand) GetWorkdir() string {
+	if x != nil {
+		return x.Workdir
+	}
+	return ""
+}
+
+func (x *RunCommandCommand) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *RunCommandCommand) GetUnrecorded() bool {
+	if x != nil {
+		return x.Unrecorded
+	}
+	return false
+}
+
 // CommandResult returns the output of a command execution.
 type CommandResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1518,6 +1598,68 @@ func (x *CommandResult) GetDurationMs() int64 {
 	return 0
 }
 
+// CommandOutput is one message of a StreamCommand stream. Chunks of output
+// arrive as the command runs; the last message carries the result.
+type CommandOutput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	IsStderr      bool                   `protobuf:"varint,2,opt,name=is_stderr,json=isStderr,proto3" json:"is_stderr,omitempty"`
+	Result        *CommandResult         `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandOutput) Reset() {
+	*x = CommandOutput{}
+	mi := &file_deer_v1_sandbox_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandOutput) ProtoMessage() {}
+
+func (x *CommandOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_sandbox_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandOutput.ProtoReflect.Descriptor instead.
+func (*CommandOutput) Descriptor() ([]byte, []int) {
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CommandOutput) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *CommandOutput) GetIsStderr() bool {
+	if x != nil {
+		return x.IsStderr
+	}
+	return false
+}
+
+func (x *CommandOutput) GetResult() *CommandResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
 // SnapshotCommand instructs the host to snapshot a sandbox.
 type SnapshotCommand struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1529,7 +1671,7 @@ type SnapshotCommand struct {
 
 func (x *SnapshotCommand) Reset() {
 	*x = SnapshotCommand{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[16]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1541,7 +1683,7 @@ func (x *SnapshotCommand) String() string {
 func (*SnapshotCommand) ProtoMessage() {}
 
 func (x *SnapshotCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[16]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1554,7 +1696,7 @@ func (x *SnapshotCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnapshotCommand.ProtoReflect.Descriptor instead.
 func (*SnapshotCommand) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{16}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *SnapshotCommand) GetSandboxId() string {
@@ -1583,7 +1725,7 @@ type SnapshotCreated struct {
 
 func (x *SnapshotCreated) Reset() {
 	*x = SnapshotCreated{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[17]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1595,7 +1737,7 @@ func (x *SnapshotCreated) String() string {
 func (*SnapshotCreated) ProtoMessage() {}
 
 func (x *SnapshotCreated) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[17]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1608,7 +1750,7 @@ func (x *SnapshotCreated) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnapshotCreated.ProtoReflect.Descriptor instead.
 func (*SnapshotCreated) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{17}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *SnapshotCreated) GetSandboxId() string {
@@ -1648,7 +1790,7 @@ type SandboxProgress struct {
 
 func (x *SandboxProgress) Reset() {
 	*x = SandboxProgress{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[18]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1660,7 +1802,7 @@ func (x *SandboxProgress) String() string {
 func (*SandboxProgress) ProtoMessage() {}
 
 func (x *SandboxProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[18]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1673,7 +1815,7 @@ func (x *SandboxProgress) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SandboxProgress.ProtoReflect.Descriptor instead.
 func (*SandboxProgress) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{18}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *SandboxProgress) GetSandboxId() string {
@@ -1734,7 +1876,7 @@ type ListSandboxKafkaStubsCommand struct {
 
 func (x *ListSandboxKafkaStubsCommand) Reset() {
 	*x = ListSandboxKafkaStubsCommand{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[19]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1746,7 +1888,7 @@ func (x *ListSandboxKafkaStubsCommand) String() string {
 func (*ListSandboxKafkaStubsCommand) ProtoMessage() {}
 
 func (x *ListSandboxKafkaStubsCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[19]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1759,7 +1901,7 @@ func (x *ListSandboxKafkaStubsCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListSandboxKafkaStubsCommand.ProtoReflect.Descriptor instead.
 func (*ListSandboxKafkaStubsCommand) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{19}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *ListSandboxKafkaStubsCommand) GetSandboxId() string {
@@ -1778,7 +1920,7 @@ type ListSandboxKafkaStubsResponse struct {
 
 func (x *ListSandboxKafkaStubsResponse) Reset() {
 	*x = ListSandboxKafkaStubsResponse{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[20]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1790,7 +1932,7 @@ func (x *ListSandboxKafkaStubsResponse) String() string {
 func (*ListSandboxKafkaStubsResponse) ProtoMessage() {}
 
 func (x *ListSandboxKafkaStubsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[20]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1803,7 +1945,7 @@ func (x *ListSandboxKafkaStubsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListSandboxKafkaStubsResponse.ProtoReflect.Descriptor instead.
 func (*ListSandboxKafkaStubsResponse) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{20}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *ListSandboxKafkaStubsResponse) GetStubs() []*SandboxKafkaStubInfo {
@@ -1823,7 +1965,7 @@ type GetSandboxKafkaStubCommand struct {
 
 func (x *GetSandboxKafkaStubCommand) Reset() {
 	*x = GetSandboxKafkaStubCommand{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[21]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1835,7 +1977,7 @@ func (x *GetSandboxKafkaStubCommand) String() string {
 func (*GetSandboxKafkaStubCommand) ProtoMessage() {}
 
 func (x *GetSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[21]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1848,7 +1990,7 @@ func (x *GetSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetSandboxKafkaStubCommand.ProtoReflect.Descriptor instead.
 func (*GetSandboxKafkaStubCommand) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{21}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *GetSandboxKafkaStubCommand) GetSandboxId() string {
@@ -1875,7 +2017,7 @@ type StartSandboxKafkaStubCommand struct {
 
 func (x *StartSandboxKafkaStubCommand) Reset() {
 	*x = StartSandboxKafkaStubCommand{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[22]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1887,7 +2029,7 @@ func (x *StartSandboxKafkaStubCommand) String() string {
 func (*StartSandboxKafkaStubCommand) ProtoMessage() {}
 
 func (x *StartSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[22]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1900,7 +2042,7 @@ func (x *StartSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StartSandboxKafkaStubCommand.ProtoReflect.Descriptor instead.
 func (*StartSandboxKafkaStubCommand) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{22}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *StartSandboxKafkaStubCommand) GetSandboxId() string {
@@ -1927,7 +2069,7 @@ type StopSandboxKafkaStubCommand struct {
 
 func (x *StopSandboxKafkaStubCommand) Reset() {
 	*x = StopSandboxKafkaStubCommand{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[23]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1939,7 +2081,7 @@ func (x *StopSandboxKafkaStubCommand) String() string {
 func (*StopSandboxKafkaStubCommand) ProtoMessage() {}
 
 func (x *StopSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[23]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1952,7 +2094,7 @@ func (x *StopSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StopSandboxKafkaStubCommand.ProtoReflect.Descriptor instead.
 func (*StopSandboxKafkaStubCommand) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{23}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *StopSandboxKafkaStubCommand) GetSandboxId() string {
@@ -1979,7 +2121,7 @@ type RestartSandboxKafkaStubCommand struct {
 
 func (x *RestartSandboxKafkaStubCommand) Reset() {
 	*x = RestartSandboxKafkaStubCommand{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[24]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1991,7 +2133,7 @@ func (x *RestartSandboxKafkaStubCommand) String() string {
 func (*RestartSandboxKafkaStubCommand) ProtoMessage() {}
 
 func (x *RestartSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[24]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2004,7 +2146,7 @@ func (x *RestartSandboxKafkaStubCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestartSandboxKafkaStubCommand.ProtoReflect.Descriptor instead.
 func (*RestartSandboxKafkaStubCommand) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{24}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *RestartSandboxKafkaStubCommand) GetSandboxId() string {
@@ -2030,7 +2172,7 @@ type KafkaCaptureStatusRequest struct {
 
 func (x *KafkaCaptureStatusRequest) Reset() {
 	*x = KafkaCaptureStatusRequest{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[25]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2042,7 +2184,7 @@ func (x *KafkaCaptureStatusRequest) String() string {
 func (*KafkaCaptureStatusRequest) ProtoMessage() {}
 
 func (x *KafkaCaptureStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[25]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2055,7 +2197,7 @@ func (x *KafkaCaptureStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KafkaCaptureStatusRequest.ProtoReflect.Descriptor instead.
 func (*KafkaCaptureStatusRequest) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{25}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *KafkaCaptureStatusRequest) GetCaptureConfigIds() []string {
@@ -2082,7 +2224,7 @@ type KafkaCaptureStatus struct {
 
 func (x *KafkaCaptureStatus) Reset() {
 	*x = KafkaCaptureStatus{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[26]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2094,7 +2236,7 @@ func (x *KafkaCaptureStatus) String() string {
 func (*KafkaCaptureStatus) ProtoMessage() {}
 
 func (x *KafkaCaptureStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[26]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2107,7 +2249,7 @@ func (x *KafkaCaptureStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KafkaCaptureStatus.ProtoReflect.Descriptor instead.
 func (*KafkaCaptureStatus) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{26}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *KafkaCaptureStatus) GetCaptureConfigId() string {
@@ -2182,7 +2324,7 @@ type KafkaCaptureStatusResponse struct {
 
 func (x *KafkaCaptureStatusResponse) Reset() {
 	*x = KafkaCaptureStatusResponse{}
-	mi := &file_deer_v1_sandbox_proto_msgTypes[27]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2194,7 +2336,7 @@ func (x *KafkaCaptureStatusResponse) String() string {
 func (*KafkaCaptureStatusResponse) ProtoMessage() {}
 
 func (x *KafkaCaptureStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_deer_v1_sandbox_proto_msgTypes[27]
+	mi := &file_deer_v1_sandbox_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2207,7 +2349,7 @@ func (x *KafkaCaptureStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use KafkaCaptureStatusResponse.ProtoReflect.Descriptor instead.
 func (*KafkaCaptureStatusResponse) Descriptor() ([]byte, []int) {
-	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{27}
+	return file_deer_v1_sandbox_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *KafkaCaptureStatusResponse) GetStatuses() []*KafkaCaptureStatus {
@@ -2312,13 +2454,17 @@ const file_deer_v1_sandbox_proto_rawDesc = "" +
 	"\x06bridge\x18\x06 \x01(\tR\x06bridge\x12\x10\n" +
 	"\x03pid\x18\a \x01(\x05R\x03pid\x12>\n" +
 	"\vkafka_stubs\x18\b \x03(\v2\x1d.deer.v1.SandboxKafkaStubInfoR\n" +
-	"kafkaStubs\"6\n" +
+	"kafkaStubs\"{\n" +
 	"\x15DestroySandboxCommand\x12\x1d\n" +
 	"\n" +
-	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"1\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x18\n" +
+	"\aarchive\x18\x02 \x01(\bR\aarchive\x12)\n" +
+	"\x10confirm_imported\x18\x03 \x01(\bR\x0fconfirmImported\"y\n" +
 	"\x10SandboxDestroyed\x12\x1d\n" +
 	"\n" +
-	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"4\n" +
+	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12!\n" +
+	"\farchive_path\x18\x02 \x01(\tR\varchivePath\x12#\n" +
+	"\rarchive_image\x18\x03 \x01(\tR\farchiveImage\"4\n" +
 	"\x13StartSandboxCommand\x12\x1d\n" +
 	"\n" +
 	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\"d\n" +
@@ -2341,13 +2487,20 @@ const file_deer_v1_sandbox_proto_rawDesc = "" +
 	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12%\n" +
 	"\x0eprevious_state\x18\x02 \x01(\tR\rpreviousState\x12\x1b\n" +
 	"\tnew_state\x18\x03 \x01(\tR\bnewState\x12\x16\n" +
-	"\x06reason\x18\x04 \x01(\tR\x06reason\"\xe4\x01\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"\x80\x03\n" +
 	"\x11RunCommandCommand\x12\x1d\n" +
 	"\n" +
 	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12\x18\n" +
 	"\acommand\x18\x02 \x01(\tR\acommand\x12'\n" +
 	"\x0ftimeout_seconds\x18\x03 \x01(\x05R\x0etimeoutSeconds\x125\n" +
-	"\x03env\x18\x04 \x03(\v2#.deer.v1.RunCommandCommand.EnvEntryR\x03env\x1a6\n" +
+	"\x03env\x18\x04 \x03(\v2#.deer.v1.RunCommandCommand.EnvEntryR\x03env\x12#\n" +
+	"\rforward_agent\x18\x05 \x01(\bR\fforwardAgent\x12%\n" +
+	"\x0eidentity_files\x18\x06 \x03(\tR\ridentityFiles\x12\x18\n" +
+	"\aworkdir\x18\a \x01(\tR\aworkdir\x12\x14\n" +
+	"\x05actor\x18\b \x01(\tR\x05actor\x12\x1e\n" +
+	"\n" +
+	"unrecorded\x18\t \x01(\bR\n" +
+	"unrecorded\x1a6\n" +
 	"\bEnvEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9c\x01\n" +
@@ -2358,7 +2511,11 @@ const file_deer_v1_sandbox_proto_rawDesc = "" +
 	"\x06stderr\x18\x03 \x01(\tR\x06stderr\x12\x1b\n" +
 	"\texit_code\x18\x04 \x01(\x05R\bexitCode\x12\x1f\n" +
 	"\vduration_ms\x18\x05 \x01(\x03R\n" +
-	"durationMs\"U\n" +
+	"durationMs\"r\n" +
+	"\rCommandOutput\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\x1b\n" +
+	"\tis_stderr\x18\x02 \x01(\bR\bisStderr\x12.\n" +
+	"\x06result\x18\x03 \x01(\v2\x16.deer.v1.CommandResultR\x06result\"U\n" +
 	"\x0fSnapshotCommand\x12\x1d\n" +
 	"\n" +
 	"sandbox_id\x18\x01 \x01(\tR\tsandboxId\x12#\n" +
@@ -2441,7 +2598,7 @@ func file_deer_v1_sandbox_proto_rawDescGZIP() []byte {
 }
 
 var file_deer_v1_sandbox_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_deer_v1_sandbox_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_deer_v1_sandbox_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_deer_v1_sandbox_proto_goTypes = []any{
 	(SnapshotMode)(0),                      // 0: deer.v1.SnapshotMode
 	(DataSourceType)(0),                    // 1: deer.v1.DataSourceType
@@ -2462,19 +2619,20 @@ var file_deer_v1_sandbox_proto_goTypes = []any{
 	(*SandboxStateChanged)(nil),            // 16: deer.v1.SandboxStateChanged
 	(*RunCommandCommand)(nil),              // 17: deer.v1.RunCommandCommand
 	(*CommandResult)(nil),                  // 18: deer.v1.CommandResult
-	(*SnapshotCommand)(nil),                // 19: deer.v1.SnapshotCommand
-	(*SnapshotCreated)(nil),                // 20: deer.v1.SnapshotCreated
-	(*SandboxProgress)(nil),                // 21: deer.v1.SandboxProgress
-	(*ListSandboxKafkaStubsCommand)(nil),   // 22: deer.v1.ListSandboxKafkaStubsCommand
-	(*ListSandboxKafkaStubsResponse)(nil),  // 23: deer.v1.ListSandboxKafkaStubsResponse
-	(*GetSandboxKafkaStubCommand)(nil),     // 24: deer.v1.GetSandboxKafkaStubCommand
-	(*StartSandboxKafkaStubCommand)(nil),   // 25: deer.v1.StartSandboxKafkaStubCommand
-	(*StopSandboxKafkaStubCommand)(nil),    // 26: deer.v1.StopSandboxKafkaStubCommand
-	(*RestartSandboxKafkaStubCommand)(nil), // 27: deer.v1.RestartSandboxKafkaStubCommand
-	(*KafkaCaptureStatusRequest)(nil),      // 28: deer.v1.KafkaCaptureStatusRequest
-	(*KafkaCaptureStatus)(nil),             // 29: deer.v1.KafkaCaptureStatus
-	(*KafkaCaptureStatusResponse)(nil),     // 30: deer.v1.KafkaCaptureStatusResponse
-	nil,                                    // 31: deer.v1.RunCommandCommand.EnvEntry
+	(*CommandOutput)(nil),                  // 19: deer.v1.CommandOutput
+	(*SnapshotCommand)(nil),                // 20: deer.v1.SnapshotCommand
+	(*SnapshotCreated)(nil),                // 21: deer.v1.SnapshotCreated
+	(*SandboxProgress)(nil),                // 22: deer.v1.SandboxProgress
+	(*ListSandboxKafkaStubsCommand)(nil),   // 23: deer.v1.ListSandboxKafkaStubsCommand
+	(*ListSandboxKafkaStubsResponse)(nil),  // 24: deer.v1.ListSandboxKafkaStubsResponse
+	(*GetSandboxKafkaStubCommand)(nil),     // 25: deer.v1.GetSandboxKafkaStubCommand
+	(*StartSandboxKafkaStubCommand)(nil),   // 26: deer.v1.StartSandboxKafkaStubCommand
+	(*StopSandboxKafkaStubCommand)(nil),    // 27: deer.v1.StopSandboxKafkaStubCommand
+	(*RestartSandboxKafkaStubCommand)(nil), // 28: deer.v1.RestartSandboxKafkaStubCommand
+	(*KafkaCaptureStatusRequest)(nil),      // 29: deer.v1.KafkaCaptureStatusRequest
+	(*KafkaCaptureStatus)(nil),             // 30: deer.v1.KafkaCaptureStatus
+	(*KafkaCaptureStatusResponse)(nil),     // 31: deer.v1.KafkaCaptureStatusResponse
+	nil,                                    // 32: deer.v1.RunCommandCommand.EnvEntry
 }
 var file_deer_v1_sandbox_proto_depIdxs = []int32{
 	4,  // 0: deer.v1.KafkaDataSourceAttachment.capture_config:type_name -> deer.v1.KafkaCaptureConfigBinding
@@ -2486,15 +2644,16 @@ var file_deer_v1_sandbox_proto_depIdxs = []int32{
 	4,  // 6: deer.v1.CreateSandboxCommand.kafka_capture_configs:type_name -> deer.v1.KafkaCaptureConfigBinding
 	6,  // 7: deer.v1.CreateSandboxCommand.data_sources:type_name -> deer.v1.DataSourceAttachment
 	7,  // 8: deer.v1.SandboxCreated.kafka_stubs:type_name -> deer.v1.SandboxKafkaStubInfo
-	31, // 9: deer.v1.RunCommandCommand.env:type_name -> deer.v1.RunCommandCommand.EnvEntry
-	9,  // 10: deer.v1.SandboxProgress.result:type_name -> deer.v1.SandboxCreated
-	7,  // 11: deer.v1.ListSandboxKafkaStubsResponse.stubs:type_name -> deer.v1.SandboxKafkaStubInfo
-	29, // 12: deer.v1.KafkaCaptureStatusResponse.statuses:type_name -> deer.v1.KafkaCaptureStatus
-	13, // [13:13] is the sub-list for method output_type
-	13, // [13:13] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	32, // 9: deer.v1.RunCommandCommand.env:type_name -> deer.v1.RunCommandCommand.EnvEntry
+	18, // 10: deer.v1.CommandOutput.result:type_name -> deer.v1.CommandResult
+	9,  // 11: deer.v1.SandboxProgress.result:type_name -> deer.v1.SandboxCreated
+	7,  // 12: deer.v1.ListSandboxKafkaStubsResponse.stubs:type_name -> deer.v1.SandboxKafkaStubInfo
+	30, // 13: deer.v1.KafkaCaptureStatusResponse.statuses:type_name -> deer.v1.KafkaCaptureStatus
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
 }
 
 func init() { file_deer_v1_sandbox_proto_init() }
@@ -2511,7 +2670,7 @@ func file_deer_v1_sandbox_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_deer_v1_sandbox_proto_rawDesc), len(file_deer_v1_sandbox_proto_rawDesc)),
 			NumEnums:      3,
-			NumMessages:   29,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
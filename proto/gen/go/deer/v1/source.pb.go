@@ -508,8 +508,11 @@ func (x *ListSourceVMsCommand) GetSourceHostConnection() *SourceHostConnection {
 
 // SourceVMsList returns the list of source VMs on a host.
 type SourceVMsList struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Vms           []*SourceVMListEntry   `protobuf:"bytes,1,rep,name=vms,proto3" json:"vms,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Vms   []*SourceVMListEntry   `protobuf:"bytes,1,rep,name=vms,proto3" json:"vms,omitempty"`
+	// True when the listing was cut short by cancellation; vms holds only the
+	// hosts and VMs gathered before that point.
+	Cancelled     bool `protobuf:"varint,2,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -551,6 +554,13 @@ func (x *SourceVMsList) GetVms() []*SourceVMListEntry {
 	return nil
 }
 
+func (x *SourceVMsList) GetCancelled() bool {
+	if x != nil {
+		return x.Cancelled
+	}
+	return false
+}
+
 type SourceVMListEntry struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -683,15 +693,17 @@ func (x *ValidateSourceVMCommand) GetSourceHostConnection() *SourceHostConnectio
 
 // SourceVMValidation returns the validation result for a source VM.
 type SourceVMValidation struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SourceVm      string                 `protobuf:"bytes,1,opt,name=source_vm,json=sourceVm,proto3" json:"source_vm,omitempty"`
-	Valid         bool                   `protobuf:"varint,2,opt,name=valid,proto3" json:"valid,omitempty"`
-	State         string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
-	MacAddress    string                 `protobuf:"bytes,4,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
-	IpAddress     string                 `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
-	HasNetwork    bool                   `protobuf:"varint,6,opt,name=has_network,json=hasNetwork,proto3" json:"has_network,omitempty"`
-	Warnings      []string               `protobuf:"bytes,7,rep,name=warnings,proto3" json:"warnings,omitempty"`
-	Errors        []string               `protobuf:"bytes,8,rep,name=errors,proto3" json:"errors,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SourceVm   string                 `protobuf:"bytes,1,opt,name=source_vm,json=sourceVm,proto3" json:"source_vm,omitempty"`
+	Valid      bool                   `protobuf:"varint,2,opt,name=valid,proto3" json:"valid,omitempty"`
+	State      string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	MacAddress string                 `protobuf:"bytes,4,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
+	IpAddress  string                 `protobuf:"bytes,5,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
+	HasNetwork bool                   `protobuf:"varint,6,opt,name=has_network,json=hasNetwork,proto3" json:"has_network,omitempty"`
+	Warnings   []string               `protobuf:"bytes,7,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Errors     []string               `protobuf:"bytes,8,rep,name=errors,proto3" json:"errors,omitempty"`
+	// issues carries the warnings and errors above with stable codes.
+	Issues        []*ValidationIssue `protobuf:"bytes,9,rep,name=issues,proto3" json:"issues,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -782,6 +794,76 @@ func (x *SourceVMValidation) GetErrors() []string {
 	return nil
 }
 
+func (x *SourceVMValidation) GetIssues() []*ValidationIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+// ValidationIssue is a coded validation finding.
+type ValidationIssue struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// code is a stable identifier such as "VM_NOT_RUNNING".
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// severity is "error" or "warning".
+	Severity      string `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"`
+	Detail        string `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidationIssue) Reset() {
+	*x = ValidationIssue{}
+	mi := &file_deer_v1_source_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidationIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationIssue) ProtoMessage() {}
+
+func (x *ValidationIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_deer_v1_source_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationIssue.ProtoReflect.Descriptor instead.
+func (*ValidationIssue) Descriptor() ([]byte, []int) {
+	return file_deer_v1_source_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ValidationIssue) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ValidationIssue) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *ValidationIssue) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
 var File_deer_v1_source_proto protoreflect.FileDescriptor
 
 const file_deer_v1_source_proto_rawDesc = "" +
@@ -823,9 +905,10 @@ const file_deer_v1_source_proto_rawDesc = "" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\"k\n" +
 	"\x14ListSourceVMsCommand\x12S\n" +
-	"\x16source_host_connection\x18\x01 \x01(\v2\x1d.deer.v1.SourceHostConnectionR\x14sourceHostConnection\"=\n" +
+	"\x16source_host_connection\x18\x01 \x01(\v2\x1d.deer.v1.SourceHostConnectionR\x14sourceHostConnection\"[\n" +
 	"\rSourceVMsList\x12,\n" +
-	"\x03vms\x18\x01 \x03(\v2\x1a.deer.v1.SourceVMListEntryR\x03vms\"\x8c\x01\n" +
+	"\x03vms\x18\x01 \x03(\v2\x1a.deer.v1.SourceVMListEntryR\x03vms\x12\x1c\n" +
+	"\tcancelled\x18\x02 \x01(\bR\tcancelled\"\x8c\x01\n" +
 	"\x11SourceVMListEntry\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1d\n" +
@@ -835,7 +918,7 @@ const file_deer_v1_source_proto_rawDesc = "" +
 	"\x04host\x18\x05 \x01(\tR\x04host\"\x8b\x01\n" +
 	"\x17ValidateSourceVMCommand\x12\x1b\n" +
 	"\tsource_vm\x18\x01 \x01(\tR\bsourceVm\x12S\n" +
-	"\x16source_host_connection\x18\x02 \x01(\v2\x1d.deer.v1.SourceHostConnectionR\x14sourceHostConnection\"\xf2\x01\n" +
+	"\x16source_host_connection\x18\x02 \x01(\v2\x1d.deer.v1.SourceHostConnectionR\x14sourceHostConnection\"\xa4\x02\n" +
 	"\x12SourceVMValidation\x12\x1b\n" +
 	"\tsource_vm\x18\x01 \x01(\tR\bsourceVm\x12\x14\n" +
 	"\x05valid\x18\x02 \x01(\bR\x05valid\x12\x14\n" +
@@ -847,7 +930,12 @@ const file_deer_v1_source_proto_rawDesc = "" +
 	"\vhas_network\x18\x06 \x01(\bR\n" +
 	"hasNetwork\x12\x1a\n" +
 	"\bwarnings\x18\a \x03(\tR\bwarnings\x12\x16\n" +
-	"\x06errors\x18\b \x03(\tR\x06errorsB9Z7github.com/aspectrr/deer.sh/proto/gen/go/deer/v1;deerv1b\x06proto3"
+	"\x06errors\x18\b \x03(\tR\x06errors\x120\n" +
+	"\x06issues\x18\t \x03(\v2\x18.deer.v1.ValidationIssueR\x06issues\"Y\n" +
+	"\x0fValidationIssue\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x16\n" +
+	"\x06detail\x18\x03 \x01(\tR\x06detailB9Z7github.com/aspectrr/deer.sh/proto/gen/go/deer/v1;deerv1b\x06proto3"
 
 var (
 	file_deer_v1_source_proto_rawDescOnce sync.Once
@@ -861,7 +949,7 @@ func file_deer_v1_source_proto_rawDescGZIP() []byte {
 	return file_deer_v1_source_proto_rawDescData
 }
 
-var file_deer_v1_source_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_deer_v1_source_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_deer_v1_source_proto_goTypes = []any{
 	(*PrepareSourceVMCommand)(nil),  // 0: deer.v1.PrepareSourceVMCommand
 	(*SourceVMPrepared)(nil),        // 1: deer.v1.SourceVMPrepared
@@ -874,20 +962,22 @@ var file_deer_v1_source_proto_goTypes = []any{
 	(*SourceVMListEntry)(nil),       // 8: deer.v1.SourceVMListEntry
 	(*ValidateSourceVMCommand)(nil), // 9: deer.v1.ValidateSourceVMCommand
 	(*SourceVMValidation)(nil),      // 10: deer.v1.SourceVMValidation
-	(*SourceHostConnection)(nil),    // 11: deer.v1.SourceHostConnection
+	(*ValidationIssue)(nil),         // 11: deer.v1.ValidationIssue
+	(*SourceHostConnection)(nil),    // 12: deer.v1.SourceHostConnection
 }
 var file_deer_v1_source_proto_depIdxs = []int32{
-	11, // 0: deer.v1.PrepareSourceVMCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
-	11, // 1: deer.v1.RunSourceCommandCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
-	11, // 2: deer.v1.ReadSourceFileCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
-	11, // 3: deer.v1.ListSourceVMsCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
+	12, // 0: deer.v1.PrepareSourceVMCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
+	12, // 1: deer.v1.RunSourceCommandCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
+	12, // 2: deer.v1.ReadSourceFileCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
+	12, // 3: deer.v1.ListSourceVMsCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
 	8,  // 4: deer.v1.SourceVMsList.vms:type_name -> deer.v1.SourceVMListEntry
-	11, // 5: deer.v1.ValidateSourceVMCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
-	6,  // [6:6] is the sub-list for method output_type
-	6,  // [6:6] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	12, // 5: deer.v1.ValidateSourceVMCommand.source_host_connection:type_name -> deer.v1.SourceHostConnection
+	11, // 6: deer.v1.SourceVMValidation.issues:type_name -> deer.v1.ValidationIssue
+	7,  // [7:7] is the sub-list for method output_type
+	7,  // [7:7] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_deer_v1_source_proto_init() }
@@ -902,7 +992,7 @@ func file_deer_v1_source_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_deer_v1_source_proto_rawDesc), len(file_deer_v1_source_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
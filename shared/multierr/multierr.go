@@ -0,0 +1,68 @@
+// Package multierr aggregates independent failures, such as the steps of a
+// cleanup or the hosts of a fan-out, into one error. Unlike joining their
+// messages into a string, the result still answers errors.Is and errors.As
+// for each failure, and unlike errors.Join it renders on a single line so it
+// fits log fields and gRPC status messages.
+package multierr
+
+import "strings"
+
+// Error holds two or more failures. Use Combine to build one.
+type Error struct {
+	errs []error
+}
+
+// Combine returns nil when every err is nil, the error itself when exactly
+// one is non-nil, and an *Error holding the non-nil ones otherwise. Nested
+// *Errors are flattened.
+func Combine(errs ...error) error {
+	var out []error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if m, ok := err.(*Error); ok {
+			out = append(out, m.errs...)
+			continue
+		}
+		out = append(out, err)
+	}
+	switch len(out) {
+	case 0:
+		return nil
+	case 1:
+		return out[0]
+	}
+	return &Error{errs: out}
+}
+
+// Errors returns the individual failures.
+func (e *Error) Errors() []error {
+	return e.errs
+}
+
+// Error lists the failures separated by "; ".
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap lets errors.Is and errors.As see each failure.
+func (e *Error) Unwrap() []error {
+	return e.errs
+}
+
+// Errors returns the failures err aggregates: those of an *Error, err alone
+// otherwise, or nil for a nil err.
+func Errors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if m, ok := err.(*Error); ok {
+		return m.errs
+	}
+	return []error{err}
+}
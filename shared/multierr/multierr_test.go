@@ -0,0 +1,39 @@
+package multierr
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestCombine(t *testing.T) {
+	if err := Combine(nil, nil); err != nil {
+		t.Errorf("Combine(nil, nil) = %v, want nil", err)
+	}
+
+	single := errors.New("only")
+	if err := Combine(nil, single); err != single {
+		t.Errorf("Combine with one error = %v, want it unwrapped", err)
+	}
+
+	a := fmt.Errorf("destroy sandbox: %w", fs.ErrNotExist)
+	b := errors.New("delete sandbox state: database is locked")
+	c := errors.New("destroy TAP tap0: busy")
+	err := Combine(a, nil, Combine(b, c))
+	if got, want := err.Error(), "destroy sandbox: file does not exist; delete sandbox state: database is locked; destroy TAP tap0: busy"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, fs.ErrNotExist) || !errors.Is(err, c) {
+		t.Error("errors.Is should see each aggregated error")
+	}
+	if n := len(Errors(err)); n != 3 {
+		t.Errorf("Errors() has %d entries, want 3 after flattening", n)
+	}
+
+	wrapped := fmt.Errorf("cleanup: %w", err)
+	var m *Error
+	if !errors.As(wrapped, &m) || len(m.Errors()) != 3 {
+		t.Errorf("errors.As through a wrap = %v", m)
+	}
+}
@@ -65,6 +65,12 @@ var blockedFlags = map[string][]string{
 	"curl": {"-X", "--request", "-d", "--data", "--data-raw", "--data-binary", "--data-urlencode", "-F", "--form", "-T", "--upload-file", "-o", "--output", "-O", "--remote-name", "-K", "--config", "-x", "--proxy", "--proxy-anyauth", "--proxy-basic", "--proxy-digest", "--proxy-negotiate", "--proxy-ntlm", "--proxy-header", "--proxy-insecure", "--proxy-key", "--proxy-user", "--proxy-pass", "--proxy1.0", "--preproxy"},
 }
 
+// fileWriters are commands whose purpose is writing to files. They are
+// refused with a specific error unless a Policy allows them.
+var fileWriters = map[string]bool{
+	"tee": true,
+}
+
 // commandArgValidators maps commands to functions that validate their arguments
 // within a pipeline segment. For example, xargs can invoke arbitrary commands
 // so we validate that the first non-flag argument (if any) is in the allowlist.
@@ -191,8 +197,8 @@ func validateCommand(command string, allowed map[string]bool) error {
 		return err
 	}
 
-	if ContainsUnquotedRedirection(command) {
-		return fmt.Errorf("output redirection is not allowed in read-only mode")
+	if op := RedirectionOperator(command); op != "" {
+		return fmt.Errorf("output redirection %q is not allowed in read-only mode", op)
 	}
 
 	segments := SplitPipeline(command)
@@ -209,6 +215,9 @@ func validateCommand(command string, allowed map[string]bool) error {
 		}
 
 		if !allowed[baseCmd] {
+			if fileWriters[baseCmd] {
+				return fmt.Errorf("command %q writes its input to files and is not allowed in read-only mode", baseCmd)
+			}
 			return fmt.Errorf("command %q is not allowed in read-only mode", baseCmd)
 		}
 
@@ -265,7 +274,7 @@ func CheckDangerousMetacharacters(s string) error {
 				return fmt.Errorf("backtick command substitution is not allowed in read-only mode")
 			}
 			if (ch == '<' || ch == '>') && i+1 < len(runes) && runes[i+1] == '(' {
-				return fmt.Errorf("process substitution is not allowed in read-only mode")
+				return fmt.Errorf("process substitution %q is not allowed in read-only mode", string(ch)+"(")
 			}
 			if ch == '\n' || ch == '\r' {
 				return fmt.Errorf("newline characters are not allowed in read-only mode")
@@ -278,22 +287,50 @@ func CheckDangerousMetacharacters(s string) error {
 
 // ContainsUnquotedRedirection detects > or >> outside of quotes.
 func ContainsUnquotedRedirection(s string) bool {
+	return RedirectionOperator(s) != ""
+}
+
+// RedirectionOperator returns the first output redirection operator outside
+// of quotes, such as ">", ">>", "2>", "&>" or ">|", or "" if there is none.
+// A file descriptor number directly before the operator is included.
+func RedirectionOperator(s string) string {
 	inSingle := false
 	inDouble := false
 	prev := rune(0)
 
-	for _, ch := range s {
+	runes := []rune(s)
+	for i, ch := range runes {
 		switch {
 		case ch == '\'' && !inDouble && prev != '\\':
 			inSingle = !inSingle
 		case ch == '"' && !inSingle && prev != '\\':
 			inDouble = !inDouble
 		case ch == '>' && !inSingle && !inDouble:
-			return true
+			start := i
+			if i > 0 && (runes[i-1] == '&' || runes[i-1] == '<') {
+				start = i - 1
+			} else {
+				for start > 0 && runes[start-1] >= '0' && runes[start-1] <= '9' {
+					start--
+				}
+				if start > 0 && runes[start-1] != ' ' && runes[start-1] != '\t' {
+					start = i
+				}
+			}
+			end := i + 1
+			if end < len(runes) && (runes[end] == '>' || runes[end] == '|' || runes[end] == '&') {
+				end++
+			}
+			if runes[end-1] == '&' {
+				for end < len(runes) && runes[end] >= '0' && runes[end] <= '9' {
+					end++
+				}
+			}
+			return string(runes[start:end])
 		}
 		prev = ch
 	}
-	return false
+	return ""
 }
 
 // SplitPipeline splits a command string on unquoted pipe characters.
@@ -461,30 +498,39 @@ func SubcommandRestrictions() map[string]map[string]bool {
 // ValidateCommandWithExtra checks that every command in a pipeline is allowed,
 // using both the default allowlist and extra user-configured commands.
 func ValidateCommandWithExtra(command string, extraAllowed []string) error {
-	command = strings.TrimSpace(command)
-	if command == "" {
-		return fmt.Errorf("empty command")
-	}
+	return (&Policy{Allow: extraAllowed}).ValidateCommand(command)
+}
 
-	if err := CheckDangerousMetacharacters(command); err != nil {
-		return err
-	}
+// Policy adjusts the default allowlist for one deployment. Allow adds
+// commands to it and Deny removes commands from it, built-in or added.
+// Deny always wins. A nil Policy is the default allowlist.
+type Policy struct {
+	Allow []string `yaml:"allow" json:"allow,omitempty"`
+	Deny  []string `yaml:"deny" json:"deny,omitempty"`
+}
 
-	if ContainsUnquotedRedirection(command) {
-		return fmt.Errorf("output redirection is not allowed in read-only mode")
+// ValidateCommand checks that every command in a pipeline is allowed under
+// the policy. Denied commands are also refused as xargs targets.
+func (p *Policy) ValidateCommand(command string) error {
+	if p == nil {
+		return ValidateCommand(command)
 	}
+	return validateCommand(command, p.allowed())
+}
 
-	// Build merged allowlist
-	merged := make(map[string]bool, len(allowedCommands)+len(extraAllowed))
+// allowed returns the default allowlist merged with the policy.
+func (p *Policy) allowed() map[string]bool {
+	merged := make(map[string]bool, len(allowedCommands)+len(p.Allow))
 	for k, v := range allowedCommands {
 		merged[k] = v
 	}
-	for _, cmd := range extraAllowed {
-		cmd = strings.TrimSpace(cmd)
-		if cmd != "" {
+	for _, cmd := range p.Allow {
+		if cmd = strings.TrimSpace(cmd); cmd != "" {
 			merged[cmd] = true
 		}
 	}
-
-	return validateCommand(command, merged)
+	for _, cmd := range p.Deny {
+		delete(merged, strings.TrimSpace(cmd))
+	}
+	return merged
 }